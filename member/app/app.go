@@ -5,15 +5,25 @@ import (
 	"fmt"
 	"log"
 	"member/config"
+	"member/internal/activity"
+	"member/internal/address"
 	"member/internal/consts"
 	"member/internal/controllers"
 	"member/internal/entities"
 	"member/internal/middlewares"
+	"member/internal/notifier"
+	"member/internal/outbox"
+	"member/internal/payment"
 
 	"member/internal/repo"
 
 	"member/internal/repo/driver"
+	"member/internal/scheduler"
+	"member/internal/tenant"
+	"member/internal/totp"
 	"member/internal/usecases"
+	"member/internal/verification"
+	"member/utilities"
 	"net/http"
 	"os"
 	"os/signal"
@@ -73,7 +83,11 @@ func Run() {
 	}
 
 	// Initialize the router
-	router := initRouter()
+	router, err := initRouter(cfg)
+	if err != nil {
+		log.Fatalf("unable to initialize the router: %s", err.Error())
+		return
+	}
 	if !cfg.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -82,8 +96,6 @@ func Run() {
 
 	// Middleware initialization
 	m := middlewares.NewMiddlewares(cfg, pgsqlDB)
-	api.Use(m.PartnerID())
-	//api.Use(m.JwtMiddleware())
 
 	// Additional middleware specific to the API group
 	api.Use(middleware.LogMiddleware(map[string]interface{}{}))
@@ -111,38 +123,148 @@ func Run() {
 		},
 	))
 
+	// Partner resolution, authentication and route authorization
+	api.Use(m.PartnerID())
+	api.Use(m.JwtMiddleware())
+
 	// Initialize user-related components
 	{
+		// Apply the password policy to new passwords
+		passwordPolicy, err := utilities.NewPasswordPolicy(cfg.PasswordPolicy)
+		if err != nil {
+			log.Fatalf("unable to load the password policy: %s", err.Error())
+		}
+		utilities.SetPasswordPolicy(passwordPolicy)
+		// Load the per-country rules billing addresses are normalized and validated with
+		addressRules, err := address.LoadFile(cfg.AddressRulesFile)
+		if err != nil {
+			log.Fatalf("unable to load the address rules: %s", err.Error())
+		}
 		// Initialize the repository
 		memberRepo := repo.NewMemberRepo(pgsqlDB, cfg)
+		// Initialize the notifier used to reach members
+		memberNotifier := notifier.NewNotifier(cfg)
+		// Initialize the payment gateways. The fake gateway is only registered, and stands in for
+		// unknown gateways, when it is explicitly enabled in test environments.
+		paymentGateways := payment.NewRegistry()
+		if cfg.Payment.FakeGateway {
+			fakeGateway := payment.NewFakeGateway()
+			paymentGateways = payment.NewRegistry(fakeGateway).WithFallback(fakeGateway)
+		}
+		// Paid plans cannot be checked out or renewed without a gateway, such requests are refused
+		// as not supported while the rest of the service keeps running
+		if paymentGateways.Empty() {
+			hasPaidPlans, err := memberRepo.HasPaidSubscriptionPlans(context.Background())
+			if err != nil {
+				log.Errorf("unable to check the subscription plans: %s", err.Error())
+			} else if hasPaidPlans {
+				log.Warnf("paid subscription plans exist but no payment gateway is configured, their checkouts and renewals will be refused")
+			}
+		}
+		// Initialize the activity log recorder
+		activityRecorder := activity.NewRecorder(cfg)
+		// Initialize the signer of the email verification tokens
+		verificationSecret := cfg.EmailVerification.Secret
+		if verificationSecret == "" {
+			verificationSecret = cfg.JwtKey
+		}
+		emailVerifier := verification.NewSigner(verificationSecret, cfg.EmailVerification.TTL)
+		// Initialize the two-factor authenticator and the signer of the login challenges. A key
+		// derived from the JWT key keeps challenges and verification tokens from standing in for each other.
+		twoFactor := totp.NewAuthenticator(cfg.TwoFactor.Issuer, cfg.TwoFactor.Skew)
+		challengeSecret := cfg.TwoFactor.Secret
+		if challengeSecret == "" {
+			challengeSecret = consts.TwoFactor + ":" + cfg.JwtKey
+		}
+		loginChallenges := verification.NewSigner(challengeSecret, cfg.TwoFactor.ChallengeTTL)
 		// Initialize use cases
-		memberUseCases := usecases.NewMemberUseCases(memberRepo)
+		memberUseCases := usecases.NewMemberUseCases(memberRepo, memberNotifier, paymentGateways, activityRecorder, emailVerifier,
+			twoFactor, loginChallenges, addressRules)
 		// Initialize controllers
 		memberControllers := controllers.NewMemberController(api, memberUseCases)
 		// Initialize the routes
 		memberControllers.InitRoutes()
+
+		// Start the background jobs, they stop when the service exits
+		if cfg.Scheduler.Enabled {
+			// The jobs process the members of every partner
+			jobCtx, cancelJobs := context.WithCancel(tenant.AllPartners(context.Background()))
+			defer cancelJobs()
+			backgroundJobs := []scheduler.Job{{
+				Name:     "subscription-lifecycle",
+				Interval: cfg.Scheduler.LifecycleInterval,
+				Run:      memberUseCases.ProcessSubscriptionLifecycle,
+			}, {
+				Name:     "member-erasure",
+				Interval: cfg.Scheduler.ErasureInterval,
+				Run: func(ctx context.Context) error {
+					return memberUseCases.ProcessMemberErasures(ctx, cfg.Scheduler.ErasureBatchSize)
+				},
+			}, {
+				Name:     "member-import",
+				Interval: cfg.Scheduler.ImportInterval,
+				Run: func(ctx context.Context) error {
+					return memberUseCases.ProcessMemberImports(ctx, cfg.Scheduler.ImportBatchSize, cfg.Scheduler.ImportLease)
+				},
+			}, {
+				Name:     "quota-reservations",
+				Interval: cfg.Scheduler.QuotaInterval,
+				Run:      memberUseCases.ExpireQuotaReservations,
+			}, {
+				Name:     "subscription-renewal",
+				Interval: cfg.Scheduler.RenewalInterval,
+				Run: func(ctx context.Context) error {
+					return memberUseCases.ProcessSubscriptionRenewals(ctx, cfg.Scheduler.RenewalBatchSize, cfg.Scheduler.RenewalLease, cfg.Scheduler.RenewalDunning)
+				},
+			}}
+			// Publish the domain events written to the outbox
+			if cfg.Outbox.Enabled {
+				eventQueue, err := outbox.NewQueue(cfg.Outbox)
+				if err != nil {
+					log.Fatalf("unable to connect to the %s event queue: %s", cfg.Outbox.Provider, err.Error())
+					return
+				}
+				defer eventQueue.Close()
+				backgroundJobs = append(backgroundJobs, scheduler.Job{
+					Name:     "outbox-relay",
+					Interval: cfg.Outbox.Interval,
+					Run:      outbox.NewRelay(memberRepo, eventQueue, cfg.Outbox).Publish,
+				})
+			}
+			jobs, err := scheduler.New(backgroundJobs...)
+			if err != nil {
+				log.Fatalf("unable to schedule the background jobs: %s", err.Error())
+				return
+			}
+			jobs.Start(jobCtx)
+		}
 	}
 	// Run the application
 	launch(cfg, router)
 }
 
 // initRouter initializes the Gin router.
-func initRouter() *gin.Engine {
+func initRouter(cfg *entities.EnvConfig) (*gin.Engine, error) {
 	router := gin.Default()
 	gin.SetMode(gin.DebugMode)
 
+	// Login throttling is keyed on the client IP, only trusted callers may forward it
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+
 	// CORS settings
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"PUT", "PATCH", "POST", "DELETE", "GET", "OPTIONS"},
-		AllowHeaders:     []string{"Origin"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "partner_id", consts.IfMatch},
+		ExposeHeaders:    []string{"Content-Length", consts.ETag},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	// Add common middlewares here
-	return router
+	return router, nil
 }
 
 // launch starts the HTTP server.
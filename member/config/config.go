@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"member/internal/entities"
+	"member/internal/hashing"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -28,5 +29,9 @@ func LoadConfig(appName string) (*entities.EnvConfig, error) {
 		return nil, err
 	}
 
+	if !hashing.Supported(cfg.PasswordHash.Algorithm) {
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", cfg.PasswordHash.Algorithm)
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,79 @@
+package activity
+
+import (
+	"context"
+	"member/internal/entities"
+	"sync"
+
+	"gitlab.com/tuneverse/toolkit/core/activitylog"
+	"gitlab.com/tuneverse/toolkit/core/logger"
+	"gitlab.com/tuneverse/toolkit/models"
+)
+
+// Recorder records what happened to a member in the activity log.
+type Recorder interface {
+	// Record adds the activity to the member's activity log.
+	Record(ctx context.Context, activity models.ActivityLog) error
+}
+
+// NewRecorder returns the recorder configured for the environment. Activities are sent to the
+// activity log service when its URL is configured and reachable, otherwise they are only logged.
+func NewRecorder(cfg *entities.EnvConfig) Recorder {
+	if cfg.ActivityLogURL == "" {
+		return LogRecorder{}
+	}
+	client, err := activitylog.Init(cfg.ActivityLogURL)
+	if err != nil {
+		logger.Log().Errorf("Activity log service unavailable, activities are only logged: %s", err.Error())
+		return LogRecorder{}
+	}
+	return &ServiceRecorder{
+		client: client,
+	}
+}
+
+// ServiceRecorder sends activities to the activity log service.
+type ServiceRecorder struct {
+	client *activitylog.ActivityLogOptions
+}
+
+// Record sends the activity to the activity log service.
+func (s *ServiceRecorder) Record(ctx context.Context, activity models.ActivityLog) error {
+	_, err := s.client.Log(activity)
+	return err
+}
+
+// LogRecorder writes activities to the service log.
+type LogRecorder struct{}
+
+// Record writes the activity to the service log.
+func (LogRecorder) Record(ctx context.Context, activity models.ActivityLog) error {
+	logger.Log().WithContext(ctx).Infof("Activity %s of member %s: %v", activity.Action, activity.MemberID, activity.Data)
+	return nil
+}
+
+// MemoryRecorder keeps recorded activities in memory. It is meant for tests.
+type MemoryRecorder struct {
+	mu         sync.Mutex
+	activities []models.ActivityLog
+}
+
+// NewMemoryRecorder creates an empty in-memory recorder.
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{}
+}
+
+// Record keeps the activity.
+func (m *MemoryRecorder) Record(ctx context.Context, activity models.ActivityLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activities = append(m.activities, activity)
+	return nil
+}
+
+// Activities returns the activities recorded so far.
+func (m *MemoryRecorder) Activities() []models.ActivityLog {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.ActivityLog(nil), m.activities...)
+}
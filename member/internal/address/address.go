@@ -0,0 +1,167 @@
+// Package address normalizes and validates billing addresses with per-country rules: the format
+// of the postal codes, whether a subdivision (state, province) is required and how postal codes
+// are spaced. The rules are loaded from a JSON data file, a built-in file covers the common
+// countries and countries without rules fall back to a permissive default.
+package address
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"member/internal/consts"
+	"member/internal/entities"
+	"os"
+	"regexp"
+	"strings"
+
+	"gitlab.com/tuneverse/toolkit/utils"
+)
+
+// MaxPostalCodeLength is the longest postal code accepted for any country.
+const MaxPostalCodeLength = 10
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Rule describes the addresses of a country.
+type Rule struct {
+	// PostalCode is the pattern normalized postal codes must match, any postal code is
+	// accepted when empty.
+	PostalCode string `json:"postal_code"`
+	// PostalCodeOptional is set for countries without postal codes everywhere.
+	PostalCodeOptional bool `json:"postal_code_optional"`
+	// PostalCodeSplit is the length of the trailing part of the postal code separated by a
+	// space, like the inward code of UK postcodes. Postal codes are not respaced when 0.
+	PostalCodeSplit int `json:"postal_code_split"`
+	// SubdivisionRequired is set for countries whose addresses need a state or province.
+	SubdivisionRequired bool `json:"subdivision_required"`
+
+	pattern *regexp.Regexp
+}
+
+// Rules holds the address rules of every country.
+type Rules struct {
+	fallback  Rule
+	countries map[string]Rule
+}
+
+// rulesFile is the layout of the rules data file.
+type rulesFile struct {
+	Default   Rule            `json:"default"`
+	Countries map[string]Rule `json:"countries"`
+}
+
+// Default returns the built-in rules.
+func Default() *Rules {
+	rules, err := Load(bytes.NewReader(defaultRules))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in address rules: %s", err))
+	}
+	return rules
+}
+
+// LoadFile reads the rules from a data file, the built-in rules are returned when path is empty.
+func LoadFile(path string) (*Rules, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening address rules: %w", err)
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// Load reads the rules from JSON. Countries are keyed by their ISO 3166-1 alpha-2 code.
+func Load(r io.Reader) (*Rules, error) {
+	var file rulesFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("reading address rules: %w", err)
+	}
+
+	fallback, err := compile("default", file.Default)
+	if err != nil {
+		return nil, err
+	}
+	rules := &Rules{fallback: fallback, countries: make(map[string]Rule, len(file.Countries))}
+	for country, rule := range file.Countries {
+		if rule, err = compile(country, rule); err != nil {
+			return nil, err
+		}
+		rules.countries[strings.ToUpper(country)] = rule
+	}
+	return rules, nil
+}
+
+// compile prepares the postal code pattern of a rule.
+func compile(name string, rule Rule) (Rule, error) {
+	if rule.PostalCodeSplit < 0 {
+		return rule, fmt.Errorf("address rule %s: negative postal code split", name)
+	}
+	if rule.PostalCode == "" {
+		return rule, nil
+	}
+	pattern, err := regexp.Compile(rule.PostalCode)
+	if err != nil {
+		return rule, fmt.Errorf("address rule %s: invalid postal code pattern: %w", name, err)
+	}
+	rule.pattern = pattern
+	return rule, nil
+}
+
+// Rule returns the rule of the country, or the default rule for countries without one.
+func (r *Rules) Rule(country string) Rule {
+	if rule, ok := r.countries[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return rule
+	}
+	return r.fallback
+}
+
+// Normalize returns the address in its canonical form: whitespace is trimmed and collapsed,
+// country and state codes are uppercased and the postal code is uppercased and spaced the way
+// the country writes it. Addresses only differing in these details normalize to the same value.
+func (r *Rules) Normalize(billingAddress entities.BillingAddress) entities.BillingAddress {
+	billingAddress.Address = strings.Join(strings.Fields(billingAddress.Address), " ")
+	billingAddress.Country = strings.ToUpper(strings.TrimSpace(billingAddress.Country))
+	billingAddress.State = strings.ToUpper(strings.TrimSpace(billingAddress.State))
+	billingAddress.Zipcode = r.Rule(billingAddress.Country).normalizePostalCode(billingAddress.Zipcode)
+	return billingAddress
+}
+
+// normalizePostalCode uppercases the postal code and spaces it according to the rule.
+func (rule Rule) normalizePostalCode(postalCode string) string {
+	postalCode = strings.ToUpper(strings.Join(strings.Fields(postalCode), " "))
+	if rule.PostalCodeSplit == 0 {
+		return postalCode
+	}
+	compact := strings.ReplaceAll(postalCode, " ", "")
+	if len(compact) <= rule.PostalCodeSplit {
+		return compact
+	}
+	split := len(compact) - rule.PostalCodeSplit
+	return compact[:split] + " " + compact[split:]
+}
+
+// Validate checks a normalized address against the rule of its country and adds the validation
+// errors to fieldsMap. The existence of the country and the state is left to the caller.
+func (r *Rules) Validate(billingAddress entities.BillingAddress, fieldsMap map[string][]string) {
+	rule := r.Rule(billingAddress.Country)
+
+	switch {
+	case billingAddress.Zipcode == "":
+		if !rule.PostalCodeOptional {
+			utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.Required)
+		}
+	case len(billingAddress.Zipcode) > MaxPostalCodeLength:
+		utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.ZipFormat)
+	case rule.pattern != nil && !rule.pattern.MatchString(billingAddress.Zipcode):
+		utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.Invalid)
+	}
+
+	if rule.SubdivisionRequired && billingAddress.State == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.State, consts.Required)
+	}
+}
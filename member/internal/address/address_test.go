@@ -0,0 +1,94 @@
+package address
+
+import (
+	"member/internal/consts"
+	"member/internal/entities"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalize checks addresses differing only in whitespace, casing and postal code spacing
+// normalize to the same value.
+func TestNormalize(t *testing.T) {
+	rules := Default()
+
+	tests := []struct {
+		in   entities.BillingAddress
+		want entities.BillingAddress
+	}{
+		{
+			in:   entities.BillingAddress{Address: "  10 Downing   Street ", Zipcode: "sw1a2aa", Country: "gb"},
+			want: entities.BillingAddress{Address: "10 Downing Street", Zipcode: "SW1A 2AA", Country: "GB"},
+		},
+		{
+			in:   entities.BillingAddress{Address: "1 Main St", Zipcode: " k1a  0b1", Country: "CA", State: " on"},
+			want: entities.BillingAddress{Address: "1 Main St", Zipcode: "K1A 0B1", Country: "CA", State: "ON"},
+		},
+		{
+			in:   entities.BillingAddress{Address: "MG Road", Zipcode: " 682001 ", Country: "in", State: "kl", Primary: true},
+			want: entities.BillingAddress{Address: "MG Road", Zipcode: "682001", Country: "IN", State: "KL", Primary: true},
+		},
+		{
+			in:   entities.BillingAddress{Address: "Damrak 1", Zipcode: "1012lg", Country: "NL"},
+			want: entities.BillingAddress{Address: "Damrak 1", Zipcode: "1012 LG", Country: "NL"},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, rules.Normalize(test.in))
+	}
+}
+
+// TestValidate checks postal code formats and required subdivisions per country.
+func TestValidate(t *testing.T) {
+	rules := Default()
+
+	valid := []entities.BillingAddress{
+		{Zipcode: "SW1A 2AA", Country: "GB"},
+		{Zipcode: "94105-1804", Country: "US", State: "CA"},
+		{Zipcode: "682001", Country: "IN", State: "KL"},
+		{Country: "HK"},
+		{Zipcode: "AB-1234", Country: "XK"},
+	}
+	for _, billingAddress := range valid {
+		fieldsMap := map[string][]string{}
+		rules.Validate(rules.Normalize(billingAddress), fieldsMap)
+		assert.Empty(t, fieldsMap, billingAddress)
+	}
+
+	fieldsMap := map[string][]string{}
+	rules.Validate(rules.Normalize(entities.BillingAddress{Zipcode: "9410", Country: "US"}), fieldsMap)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Zipcode])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.State])
+
+	fieldsMap = map[string][]string{}
+	rules.Validate(entities.BillingAddress{Country: "DE"}, fieldsMap)
+	assert.Equal(t, map[string][]string{consts.Zipcode: {consts.Required}}, fieldsMap)
+
+	fieldsMap = map[string][]string{}
+	rules.Validate(entities.BillingAddress{Zipcode: "12345678901", Country: "XK"}, fieldsMap)
+	assert.Equal(t, map[string][]string{consts.Zipcode: {consts.ZipFormat}}, fieldsMap)
+}
+
+// TestLoad checks custom rules replace the built-in ones and invalid files are rejected.
+func TestLoad(t *testing.T) {
+	rules, err := Load(strings.NewReader(`{
+		"default": {"postal_code": "^\\d+$"},
+		"countries": {"de": {"postal_code": "^\\d{5}$", "subdivision_required": true}}
+	}`))
+	require.NoError(t, err)
+	assert.True(t, rules.Rule("DE").SubdivisionRequired)
+
+	fieldsMap := map[string][]string{}
+	rules.Validate(entities.BillingAddress{Zipcode: "123", Country: "FR"}, fieldsMap)
+	assert.Empty(t, fieldsMap)
+	rules.Validate(entities.BillingAddress{Zipcode: "AB1", Country: "FR"}, fieldsMap)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Zipcode])
+
+	_, err = Load(strings.NewReader(`{"countries": {"DE": {"postal_code": "("}}}`))
+	assert.Error(t, err)
+	_, err = Load(strings.NewReader(`not json`))
+	assert.Error(t, err)
+}
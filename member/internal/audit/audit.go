@@ -0,0 +1,70 @@
+// Package audit describes the changes recorded in the audit trail of members: who changed which
+// fields from which value to which, in which request. Secrets are recorded as changed without
+// their values.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/gin-gonic/gin"
+	tkconsts "gitlab.com/tuneverse/toolkit/consts"
+)
+
+// Redacted replaces the values of secrets in the audit trail.
+const Redacted = "[REDACTED]"
+
+// secrets are the fields whose values are never recorded.
+var secrets = map[string]bool{
+	"password":           true,
+	"reset_password_key": true,
+}
+
+// Changes collects the field changes of an audited operation.
+type Changes []entities.AuditChange
+
+// Set records the change of a field when the new value differs from the old one. Values of
+// secrets are redacted.
+func (changes Changes) Set(field string, old, new any) Changes {
+	if fmt.Sprint(old) == fmt.Sprint(new) {
+		return changes
+	}
+	if secrets[field] {
+		return changes.Redact(field)
+	}
+	return append(changes, entities.AuditChange{Field: field, Old: old, New: new})
+}
+
+// Redact records the change of a secret whose values cannot be compared, like hashed passwords.
+func (changes Changes) Redact(field string) Changes {
+	return append(changes, entities.AuditChange{Field: field, Old: Redacted, New: Redacted})
+}
+
+// Actor returns the member acting in ctx, empty for requests without a logged in member like
+// password resets, and for background jobs.
+func Actor(ctx context.Context) string {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		return ginCtx.GetString(consts.ContextMemberID)
+	}
+	actor, _ := ctx.Value(consts.ContextMemberID).(string)
+	return actor
+}
+
+// RequestID returns the ID the toolkit log middleware gave the request of ctx, empty outside
+// of requests. A gin context is looked up through its request context.
+func RequestID(ctx context.Context) string {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return ""
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	fields, ok := ctx.Value(tkconsts.LogData).(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	requestID, _ := fields[tkconsts.ContextRequestID].(string)
+	return requestID
+}
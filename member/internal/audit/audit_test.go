@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"member/internal/consts"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	tkconsts "gitlab.com/tuneverse/toolkit/consts"
+)
+
+// TestChanges checks unchanged fields are skipped and secrets are recorded without their values.
+func TestChanges(t *testing.T) {
+	changes := Changes{}.
+		Set("firstname", "Jon", "John").
+		Set("lastname", "Doe", "Doe").
+		Set("is_primary_billing", false, true).
+		Set("password", "old-hash", "new-hash").
+		Redact("reset_password_key")
+
+	assert.Equal(t, Changes{
+		{Field: "firstname", Old: "Jon", New: "John"},
+		{Field: "is_primary_billing", Old: false, New: true},
+		{Field: "password", Old: Redacted, New: Redacted},
+		{Field: "reset_password_key", Old: Redacted, New: Redacted},
+	}, changes)
+}
+
+// TestActorAndRequestID checks the actor and request ID are read from gin and plain contexts.
+func TestActorAndRequestID(t *testing.T) {
+	assert.Empty(t, Actor(context.Background()))
+	assert.Empty(t, RequestID(context.Background()))
+
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("PATCH", "/api/v1/members", nil)
+	assert.Empty(t, Actor(ginCtx))
+	assert.Empty(t, RequestID(ginCtx))
+
+	ginCtx.Set(consts.ContextMemberID, "admin-1")
+	logData := map[string]interface{}{tkconsts.ContextRequestID: "req-1"}
+	ginCtx.Request = ginCtx.Request.WithContext(context.WithValue(ginCtx.Request.Context(), tkconsts.LogData, logData))
+	assert.Equal(t, "admin-1", Actor(ginCtx))
+	assert.Equal(t, "req-1", RequestID(ginCtx))
+	assert.Equal(t, "req-1", RequestID(ginCtx.Request.Context()))
+}
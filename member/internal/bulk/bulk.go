@@ -0,0 +1,179 @@
+// Package bulk reads the members of a bulk import and writes the member list export.
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"member/internal/consts"
+	"member/internal/entities"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for formats other than CSV and NDJSON.
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	// ErrNoRows is returned when an upload holds no member.
+	ErrNoRows = errors.New("import has no rows")
+	// ErrTooManyRows is returned when an upload holds more members than allowed.
+	ErrTooManyRows = errors.New("import has too many rows")
+)
+
+// RowError reports a row of an upload that could not be read. Rows are numbered from 1,
+// the CSV header not included.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ReadMembers reads up to maxRows members from an upload in the given format. A CSV upload
+// starts with a header naming its columns after the JSON fields of a member, an NDJSON upload
+// holds one member object per line.
+func ReadMembers(r io.Reader, format string, maxRows int) ([]entities.Member, error) {
+	var (
+		members []entities.Member
+		err     error
+	)
+	switch format {
+	case consts.ImportFormatCSV:
+		members, err = readCSV(r, maxRows)
+	case consts.ImportFormatNDJSON:
+		members, err = readNDJSON(r, maxRows)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, ErrNoRows
+	}
+	return members, nil
+}
+
+// readCSV reads the members of a CSV upload.
+func readCSV(r io.Reader, maxRows int) ([]entities.Member, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrNoRows
+	}
+	if err != nil {
+		return nil, err
+	}
+	var probe entities.Member
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if _, ok := memberText(&probe)[column]; !ok {
+			if _, ok := memberFlags(&probe)[column]; !ok {
+				return nil, fmt.Errorf("unknown column %q", column)
+			}
+		}
+		header[i] = column
+	}
+
+	var members []entities.Member
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return members, nil
+		}
+		row := len(members) + 1
+		if err != nil {
+			return nil, &RowError{Row: row, Err: err}
+		}
+		if row > maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		var member entities.Member
+		text, flags := memberText(&member), memberFlags(&member)
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if field, ok := text[header[i]]; ok {
+				*field = value
+				continue
+			}
+			if value == "" {
+				continue
+			}
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, &RowError{Row: row, Err: fmt.Errorf("column %s: %w", header[i], err)}
+			}
+			*flags[header[i]] = flag
+		}
+		members = append(members, member)
+	}
+}
+
+// readNDJSON reads the members of an NDJSON upload. Blank lines are skipped.
+func readNDJSON(r io.Reader, maxRows int) ([]entities.Member, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var members []entities.Member
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		row := len(members) + 1
+		if row > maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		var member entities.Member
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&member); err != nil {
+			return nil, &RowError{Row: row, Err: err}
+		}
+		members = append(members, member)
+	}
+	return members, scanner.Err()
+}
+
+// memberText maps the CSV columns of a member to its text fields.
+func memberText(member *entities.Member) map[string]*string {
+	return map[string]*string{
+		"title":     &member.Title,
+		"firstname": &member.FirstName,
+		"lastname":  &member.LastName,
+		"email":     &member.Email,
+		"gender":    &member.Gender,
+		"language":  &member.Language,
+		"country":   &member.Country,
+		"state":     &member.State,
+		"address1":  &member.Address1,
+		"address2":  &member.Address2,
+		"city":      &member.City,
+		"zip":       &member.Zipcode,
+		"phone":     &member.Phone,
+		"password":  &member.Password,
+		"provider":  &member.Provider,
+	}
+}
+
+// memberFlags maps the CSV columns of a member to its boolean fields.
+func memberFlags(member *entities.Member) map[string]*bool {
+	return map[string]*bool{
+		"terms_condition_checked": &member.TermsConditionChecked,
+		"paying_tax":              &member.PayingTax,
+	}
+}
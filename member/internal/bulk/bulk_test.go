@@ -0,0 +1,84 @@
+package bulk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadMembersCSV checks columns are matched by name and flags are parsed.
+func TestReadMembersCSV(t *testing.T) {
+	upload := "\ufeffEmail,firstname,lastname,country,phone,paying_tax,terms_condition_checked\n" +
+		"john@example.com,John,Doe,IN,+919876543210,true,1\n" +
+		"jane@example.com, Jane ,Roe,US,,,\n"
+
+	members, err := ReadMembers(strings.NewReader(upload), consts.ImportFormatCSV, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, entities.Member{
+		Email: "john@example.com", FirstName: "John", LastName: "Doe", Country: "IN",
+		Phone: "+919876543210", PayingTax: true, TermsConditionChecked: true,
+	}, members[0])
+	assert.Equal(t, "Jane", members[1].FirstName)
+	assert.False(t, members[1].PayingTax)
+}
+
+// TestReadMembersNDJSON checks one member is read per line and blank lines are skipped.
+func TestReadMembersNDJSON(t *testing.T) {
+	upload := `{"email":"john@example.com","firstname":"John","paying_tax":true}` + "\n\n" +
+		`{"email":"jane@example.com","provider":"internal"}` + "\n"
+
+	members, err := ReadMembers(strings.NewReader(upload), consts.ImportFormatNDJSON, 10)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.True(t, members[0].PayingTax)
+	assert.Equal(t, consts.ProviderInternal, members[1].Provider)
+}
+
+// TestReadMembersErrors checks malformed uploads are rejected with the failing row.
+func TestReadMembersErrors(t *testing.T) {
+	_, err := ReadMembers(strings.NewReader("email,nickname\n"), consts.ImportFormatCSV, 10)
+	assert.ErrorContains(t, err, `unknown column "nickname"`)
+
+	_, err = ReadMembers(strings.NewReader("email,paying_tax\na@example.com,yes please\n"), consts.ImportFormatCSV, 10)
+	var rowErr *RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 1, rowErr.Row)
+
+	_, err = ReadMembers(strings.NewReader("{\"email\":\"a@example.com\"}\n{\"nickname\":\"a\"}\n"), consts.ImportFormatNDJSON, 10)
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 2, rowErr.Row)
+
+	_, err = ReadMembers(strings.NewReader("email\na@example.com\nb@example.com\n"), consts.ImportFormatCSV, 1)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	_, err = ReadMembers(strings.NewReader("email\n"), consts.ImportFormatCSV, 10)
+	assert.ErrorIs(t, err, ErrNoRows)
+
+	_, err = ReadMembers(strings.NewReader(""), "xml", 10)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+// TestMemberWriter checks the header is written once across batches.
+func TestMemberWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := NewMemberWriter(&out)
+	memberID := uuid.MustParse("614608f2-6538-4733-aded-96f902007254")
+
+	require.NoError(t, writer.Write([]entities.ViewMembers{{
+		MemberId: memberID, Name: "John Doe", Email: "john@example.com", Role: entities.Role{Name: "artist"},
+		Country: entities.Country{Code: "IN", Name: "India"}, Active: true, TrackCount: 3,
+	}}))
+	require.NoError(t, writer.Write([]entities.ViewMembers{{MemberId: memberID, Name: "Jane, Roe"}}))
+
+	assert.Equal(t, "member_id,name,email,gender,role,partner,country_code,country,active,album_count,track_count,artist_count\n"+
+		"614608f2-6538-4733-aded-96f902007254,John Doe,john@example.com,,artist,,IN,India,true,0,3,0\n"+
+		"614608f2-6538-4733-aded-96f902007254,\"Jane, Roe\",,,,,,,false,0,0,0\n", out.String())
+}
@@ -0,0 +1,59 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"io"
+	"member/internal/entities"
+	"strconv"
+)
+
+// exportHeader is the header row of the member list export.
+var exportHeader = []string{
+	"member_id", "name", "email", "gender", "role", "partner", "country_code", "country",
+	"active", "album_count", "track_count", "artist_count",
+}
+
+// MemberWriter writes the member list export as CSV. The header is written with the first
+// members, and every batch is flushed to the underlying writer so the export can be streamed.
+type MemberWriter struct {
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+// NewMemberWriter returns a writer of the member list export to w.
+func NewMemberWriter(w io.Writer) *MemberWriter {
+	return &MemberWriter{
+		csv: csv.NewWriter(w),
+	}
+}
+
+// Write writes a batch of members and flushes it.
+func (w *MemberWriter) Write(members []entities.ViewMembers) error {
+	if !w.headerWritten {
+		if err := w.csv.Write(exportHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	for _, member := range members {
+		err := w.csv.Write([]string{
+			member.MemberId.String(),
+			member.Name,
+			member.Email,
+			member.Gender,
+			member.Role.Name,
+			member.PartnerName,
+			member.Country.Code,
+			member.Country.Name,
+			strconv.FormatBool(member.Active),
+			strconv.Itoa(member.AlbumCount),
+			strconv.Itoa(member.TrackCount),
+			strconv.Itoa(member.ArtistCount),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
@@ -38,6 +38,40 @@ const ExpiryTime = 180
 // ContextEndPoints represent
 const ContextEndPoints = "context-endpoints"
 
+// Context keys populated from the JWT claims by the authentication middleware.
+const (
+	ContextMemberID    = "memberId"
+	ContextRoles       = "roles"
+	ContextMemberType  = "memberType"
+	ContextPartnerName = "partnerName"
+	ContextMemberEmail = "email"
+)
+
+// Roles that are allowed to manage any member.
+const (
+	RoleAdmin        = "admin"
+	RolePartnerAdmin = "partner_admin"
+)
+
+// Notification events, each one maps to a template of the notifier.
+const (
+	EventPasswordReset         = "password_reset"
+	EventMemberRegistered      = "member_registered"
+	EventEmailVerification     = "email_verification"
+	EventSubscriptionCheckout  = "subscription_checkout"
+	EventSubscriptionRenewed   = "subscription_renewed"
+	EventSubscriptionCancelled = "subscription_cancelled"
+	EventSubscriptionWarning   = "subscription_warning"
+	EventSubscriptionInGrace   = "subscription_in_grace"
+	EventSubscriptionExpired   = "subscription_expired"
+
+	EventSubscriptionPlanChanged   = "subscription_plan_changed"
+	EventSubscriptionRenewalFailed = "subscription_renewal_failed"
+
+	// DefaultLanguage is used when a notification has no template in the member's language.
+	DefaultLanguage = "en"
+)
+
 // KeyNames
 const (
 	//Parse Error indicates some error in the json data trying to parse
@@ -252,8 +286,9 @@ const (
 	Key                   = "key"
 	StoresExists          = "exists"
 	DefaultCountry        = ""
-	SuccessfullyInitiated = "Successfully Initiated Password Reset by Generating Key"
+	SuccessfullyInitiated = "Password reset initiated, the reset key has been sent to the registered email"
 	Match                 = "failedmatch"
+	Locked                = "locked"
 	// SubscriptionPlanRenewableDuration represents whether the subscription plan is renewable within certain duration.
 	SubscriptionPlanRenewableDuration = "can_renewable_within"
 	LimitReached                      = "limit"
@@ -277,6 +312,11 @@ const (
 	InvalidFormat  = "invalid_format"
 	NoPayin        = "no_payin"
 	NoDetails      = "no_details"
+	NotSupported   = "not_supported"
+	RefundFailed   = "refund_failed"
+	Unknown        = "unknown"
+
+	CurrencyMismatch = "currency_mismatch"
 )
 
 // SuccessfullyCheckedout is a constant representing a success message for checking out a subscription plan.
@@ -288,6 +328,156 @@ const SuccessfullyCancelled = "Successfully cancelled  subscription plan"
 // SuccessfullyCheckedout is a constant representing a success message for checking out a subscription plan.
 const SuccessfullyRenewed = "Successfully Renewed existing Subscription"
 
+// SuccessfullyChangedPlan is a constant representing a success message for switching a member subscription to another plan.
+const SuccessfullyChangedPlan = "Successfully changed subscription plan"
+
+// SuccessfullyListedInvoices is a constant representing a success message for listing the invoices of a member.
+const SuccessfullyListedInvoices = "Invoices listed successfully"
+
+// SuccessfullyRetrievedInvoice is a constant representing a success message for retrieving an invoice.
+const SuccessfullyRetrievedInvoice = "Invoice retrieved successfully"
+
+// Invoice fields
+const (
+	InvoiceID     = "invoice_id"
+	InvoiceFormat = "format"
+
+	// InvoiceFormatHTML renders the invoice as an HTML document.
+	InvoiceFormatHTML = "html"
+)
+
+// SuccessfullyProcessedWebhook is a constant representing a success message for a received payment webhook event.
+const SuccessfullyProcessedWebhook = "Payment event processed"
+
+// Member subscription statuses managed by the subscription lifecycle job.
+const (
+	SubscriptionStatusActive  = "active"
+	SubscriptionStatusWarning = "warning"
+	SubscriptionStatusInGrace = "in_grace"
+	SubscriptionStatusExpired = "expired"
+
+	SubscriptionStatusProcessing    = "processing"
+	SubscriptionStatusPaymentFailed = "payment_failed"
+)
+
+// Payment statuses reported by the payment gateways.
+const (
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusRefunded   = "refunded"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusPending    = "pending"
+	PaymentStatusDisputed   = "disputed"
+)
+
+// Payment events sent by the payment gateways to the webhook endpoint.
+const (
+	PaymentEventSucceeded = "payment.succeeded"
+	PaymentEventFailed    = "payment.failed"
+	PaymentEventRefunded  = "payment.refunded"
+	PaymentEventDisputed  = "payment.disputed"
+)
+
+// PaymentSignatureHeader carries the HMAC signature of a payment webhook payload.
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// Payment webhook fields
+const (
+	EventID          = "id"
+	EventType        = "type"
+	GatewayPaymentID = "payment_id"
+)
+
+// Sources of the status changes recorded in member_subscription_event.
+const (
+	SubscriptionEventSourceLifecycle      = "lifecycle"
+	SubscriptionEventSourcePaymentWebhook = "payment_webhook"
+)
+
+// Kinds of recorded subscription payments.
+const (
+	PaymentKindCheckout   = "checkout"
+	PaymentKindRenewal    = "renewal"
+	PaymentKindRefund     = "refund"
+	PaymentKindPlanChange = "plan_change"
+)
+
+// Kinds of subscription ledger entries.
+const (
+	LedgerKindPlanChange    = "plan_change"
+	LedgerKindPayment       = "payment"
+	LedgerKindRenewalCredit = "renewal_credit"
+	LedgerKindRefund        = "refund"
+)
+
+// Domain events written to the outbox and published to other services.
+const (
+	DomainEventMemberRegistered        = "member.registered"
+	DomainEventMemberDeleted           = "member.deleted"
+	DomainEventMemberErased            = "member.erased"
+	DomainEventSubscriptionCheckedOut  = "member_subscription.checked_out"
+	DomainEventSubscriptionRenewed     = "member_subscription.renewed"
+	DomainEventSubscriptionCancelled   = "member_subscription.cancelled"
+	DomainEventSubscriptionPlanChanged = "member_subscription.plan_changed"
+	// DomainEventSubscriptionStatusChanged is written for the status moves without an event of their own,
+	// like activations, failed payments and the lifecycle sweep.
+	DomainEventSubscriptionStatusChanged = "member_subscription.status_changed"
+	DomainEventBillingAddressAdded       = "billing_address.added"
+	DomainEventBillingAddressUpdated     = "billing_address.updated"
+	DomainEventBillingAddressDeleted     = "billing_address.deleted"
+)
+
+// DomainEventSchemaVersions holds the schema version of the data of each domain event. The version of an
+// event must be bumped when a field of its data is removed, renamed or changes meaning.
+var DomainEventSchemaVersions = map[string]int{
+	DomainEventMemberRegistered:          1,
+	DomainEventMemberDeleted:             1,
+	DomainEventMemberErased:              1,
+	DomainEventSubscriptionCheckedOut:    1,
+	DomainEventSubscriptionRenewed:       1,
+	DomainEventSubscriptionCancelled:     1,
+	DomainEventSubscriptionPlanChanged:   1,
+	DomainEventSubscriptionStatusChanged: 1,
+	DomainEventBillingAddressAdded:       1,
+	DomainEventBillingAddressUpdated:     1,
+	DomainEventBillingAddressDeleted:     1,
+}
+
+// Aggregates the domain events are about.
+const (
+	AggregateMember             = "member"
+	AggregateMemberSubscription = "member_subscription"
+)
+
+// Activities recorded in the activity log.
+const (
+	ActivityMemberDataExported     = "member_data_exported"
+	ActivityMemberErasureRequested = "member_erasure_requested"
+	ActivityMemberErased           = "member_erased"
+	ActivityMemberUnlocked         = "member_unlocked"
+	ActivityTwoFactorEnabled       = "two_factor_enabled"
+	ActivityTwoFactorDisabled      = "two_factor_disabled"
+)
+
+// ErasedEmailDomain is the domain of the placeholder email given to erased members.
+const ErasedEmailDomain = "erased.invalid"
+
+// MemberExportFileName is the name of the data export archive, formatted with the member ID.
+const MemberExportFileName = "member-%s-export.zip"
+
+// SuccessfullyRequestedErasure is a constant representing a success message for a member erasure request.
+const SuccessfullyRequestedErasure = "Member erasure requested successfully"
+
+// Queue providers the outbox relay publishes to.
+const (
+	QueueProviderRabbitMQ = "rabbitmq"
+	QueueProviderSQS      = "sqs"
+)
+
+// SubscriptionLifecycleLockID is the postgres advisory lock key held while sweeping
+// subscriptions, so only one replica moves subscriptions at a time.
+const SubscriptionLifecycleLockID = 72100401
+
 // SubscriptionID of the plan
 const SubscriptionID = "subscription_id"
 
@@ -299,6 +489,20 @@ const DummyPaymentGatewayURL = "https://dummy-payment-gateway.com/pay"
 
 var ErrInParsing = errors.New("Error occured while parsing")
 
+// Errors returned while checking a password reset key.
+var (
+	ErrResetKeyMismatch = errors.New("reset key does not match")
+	ErrResetKeyExpired  = errors.New("timeout: reset key has expired, please try resetting again")
+	ErrResetKeyInvalid  = errors.New("reset key is invalid or already used")
+)
+
+// ErrPaymentEventOutOfOrder is returned for a payment webhook event that would move its payment
+// backwards, like a late captured event of a refunded payment.
+var ErrPaymentEventOutOfOrder = errors.New("payment event does not apply to the payment status")
+
+// ResetKeyBytes is the number of random bytes of a password reset key, which is sent hex encoded.
+const ResetKeyBytes = 32
+
 const (
 	// Page represents the page parameter for pagination.
 	Page = "page"
@@ -366,3 +570,305 @@ const (
 	// MaxAllowedLimit specifies the maximum allowed limit for pagination.
 	MaxAllowedLimit = 25
 )
+
+// Member listing
+const (
+	// SuccessfullyListedMembers is the success message of the member listing.
+	SuccessfullyListedMembers = "Successfully Listed All Member Details"
+	// MemberSortCreatedOn and MemberSortEmail are the keys the member listing can be sorted on.
+	MemberSortCreatedOn = "created_on"
+	MemberSortEmail     = "email"
+	// OrderAsc and OrderDesc are the sort orders of the member listing.
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	// Validation keys of the member listing parameters.
+	Cursor      = "cursor"
+	Sort        = "sort"
+	Order       = "order"
+	Role        = "role"
+	Partner     = "partner"
+	CreatedFrom = "created_from"
+	CreatedTo   = "created_to"
+)
+
+// Bulk member import and export
+const (
+	// ImportFormatCSV and ImportFormatNDJSON are the accepted formats of a bulk member import.
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+	// MaxImportRows is the maximum number of members in one bulk import.
+	MaxImportRows = 5000
+	// MaxImportSize is the maximum size in bytes of a bulk import upload.
+	MaxImportSize = 10 << 20
+	// ExportBatchSize is the number of members read per query by the streaming export.
+	ExportBatchSize = 500
+	// MemberListExportFileName is the file name of the member list export.
+	MemberListExportFileName = "members.csv"
+
+	// Statuses of a bulk import and of its rows.
+	ImportStatusPending    = "pending"
+	ImportStatusProcessing = "processing"
+	ImportStatusCompleted  = "completed"
+	ImportRowImported      = "imported"
+	ImportRowFailed        = "failed"
+
+	SuccessfullyQueuedImport  = "Member import queued successfully"
+	SuccessfullyFetchedImport = "Member import fetched successfully"
+
+	// Validation keys of the bulk import.
+	File     = "file"
+	ImportID = "import_id"
+	// Row and Rejected report import rows that passed validation but could not be stored.
+	Row      = "row"
+	Rejected = "rejected"
+)
+
+// ErrMemberRejected is returned when a new member cannot be stored, registering it again fails
+// the same way.
+var ErrMemberRejected = errors.New("the member cannot be registered")
+
+// Email verification
+const (
+	SuccessfullyVerifiedEmail      = "Email verified successfully"
+	SuccessfullyResentVerification = "A verification email has been sent if the address belongs to an unverified member"
+	SuccessfullyFetchedPolicy      = "Email verification policy fetched successfully"
+	SuccessfullyUpdatedPolicy      = "Email verification policy updated successfully"
+
+	// Validation keys and values of the email verification.
+	Token       = "token"
+	NotVerified = "not_verified"
+)
+
+// Login lockout
+const (
+	// LockoutScopeMember and LockoutScopeIP are the subjects failed logins and reset key checks are counted for.
+	LockoutScopeMember = "member"
+	LockoutScopeIP     = "ip"
+
+	SuccessfullyUnlockedMember = "Member unlocked successfully"
+)
+
+// Two-factor authentication
+const (
+	SuccessfullyEnrolledTwoFactor = "Two-factor enrollment started, verify a code of the authenticator app to enable it"
+	SuccessfullyEnabledTwoFactor  = "Two-factor authentication enabled successfully, store the recovery codes safely"
+	SuccessfullyDisabledTwoFactor = "Two-factor authentication disabled successfully"
+
+	// Validation keys and values of the two-factor authentication.
+	TwoFactor      = "two_factor"
+	Code           = "code"
+	ChallengeToken = "challenge_token"
+	Enabled        = "enabled"
+	NotEnrolled    = "not_enrolled"
+)
+
+// Password policy
+const (
+	// Breached is the validation value of passwords found in the breached password list.
+	Breached = "breached"
+)
+
+// Optimistic concurrency control
+const (
+	// IfMatch and ETag are the headers carrying the version of a member or billing address.
+	IfMatch = "If-Match"
+	ETag    = "ETag"
+
+	// Validation key and value of updates made against an outdated version.
+	Version = "version"
+	Stale   = "stale"
+)
+
+// ErrVersionMismatch is returned when a member or billing address was changed since the
+// version the update was made against.
+var ErrVersionMismatch = errors.New("the version does not match, the resource was modified")
+
+// Audit trail
+const (
+	// Actions recorded in the audit trail of a member.
+	AuditMemberUpdated         = "member_updated"
+	AuditMemberDeleted         = "member_deleted"
+	AuditBillingAddressUpdated = "billing_address_updated"
+	AuditBillingAddressDeleted = "billing_address_deleted"
+	AuditStoresAdded           = "stores_added"
+	AuditStoreUpdated          = "store_updated"
+	AuditStoresRemoved         = "stores_removed"
+	AuditStoresReordered       = "stores_reordered"
+	AuditPasswordUpdated       = "password_updated"
+
+	// Action is the validation key of the audit action filter.
+	Action = "action"
+
+	// SuccessfullyListedAudit is the success message of the audit trail listing.
+	SuccessfullyListedAudit = "Audit trail listed successfully"
+)
+
+// AuditActions lists the actions the audit trail can be filtered on.
+var AuditActions = []string{
+	AuditMemberUpdated,
+	AuditMemberDeleted,
+	AuditBillingAddressUpdated,
+	AuditBillingAddressDeleted,
+	AuditStoresAdded,
+	AuditStoreUpdated,
+	AuditStoresRemoved,
+	AuditStoresReordered,
+	AuditPasswordUpdated,
+}
+
+// Member stores
+const (
+	// Validation keys of the member store endpoints.
+	StoreID   = "store_id"
+	Stores    = "stores"
+	MemberIDs = "member_ids"
+
+	// Actions of the bulk member store endpoint.
+	BulkStoresApply  = "apply"
+	BulkStoresRemove = "remove"
+
+	// Statuses of the members of a bulk member store request.
+	BulkStoresApplied   = "applied"
+	BulkStoresRemoved   = "removed"
+	BulkStoresUnchanged = "unchanged"
+	BulkStoresFailed    = "failed"
+
+	// MaxBulkStoreMembers is the maximum number of members in one bulk member store request.
+	MaxBulkStoreMembers = 500
+
+	// MaxStoreNameLength is the maximum length of the custom name of a member store.
+	MaxStoreNameLength = 100
+
+	SuccessfullyListedMemberStores   = "Member stores listed successfully"
+	SuccessfullyUpdatedMemberStore   = "Member store updated successfully"
+	SuccessfullyRemovedMemberStore   = "Member store removed successfully"
+	SuccessfullyReorderedMemberStore = "Member stores reordered successfully"
+	SuccessfullyListedPartnerStores  = "Partner stores listed successfully"
+	SuccessfullyAppliedBulkStores    = "Bulk member stores processed successfully"
+)
+
+// Subscription quotas
+const (
+	// Resources limited by subscription plans.
+	QuotaProducts = "products"
+	QuotaTracks   = "tracks"
+	QuotaArtists  = "artists"
+
+	// Statuses of quota reservations. Reserved capacity is held until the reservation is
+	// committed, once the item is created, released, when the creation is rolled back, or
+	// expires.
+	QuotaReserved  = "reserved"
+	QuotaCommitted = "committed"
+	QuotaReleased  = "released"
+	QuotaExpired   = "expired"
+
+	// Validation keys of the quota endpoints.
+	Resource             = "resource"
+	Quantity             = "quantity"
+	TTLSeconds           = "ttl_seconds"
+	ReservationID        = "reservation_id"
+	MemberSubscriptionID = "member_subscription_id"
+	Closed               = "closed"
+
+	// DefaultQuotaReservationSeconds and MaxQuotaReservationSeconds bound the time reserved
+	// capacity is held.
+	DefaultQuotaReservationSeconds = 300
+	MaxQuotaReservationSeconds     = 3600
+
+	SuccessfullyFetchedQuota   = "Subscription quota fetched successfully"
+	SuccessfullyCheckedQuota   = "Subscription quota checked successfully"
+	SuccessfullyReservedQuota  = "Subscription quota reserved successfully"
+	SuccessfullyCommittedQuota = "Subscription quota reservation committed successfully"
+	SuccessfullyReleasedQuota  = "Subscription quota reservation released successfully"
+)
+
+// QuotaOpenStatuses lists the statuses of subscriptions items can be added to.
+var QuotaOpenStatuses = []string{SubscriptionStatusActive, SubscriptionStatusWarning, SubscriptionStatusInGrace}
+
+var (
+	// ErrQuotaExceeded is returned when a reservation does not fit in the capacity left on the subscription.
+	ErrQuotaExceeded = errors.New("the subscription quota is exceeded")
+	// ErrSubscriptionClosed is returned when capacity is reserved on a subscription items cannot be added to.
+	ErrSubscriptionClosed = errors.New("items cannot be added to the subscription in its status")
+)
+
+// Subscription plan catalogue
+const (
+	// Validation keys of the plan catalogue endpoints.
+	PlanID                   = "plan_id"
+	SKU                      = "sku"
+	SubscriptionDurationID   = "subscription_duration_id"
+	CanRenewableWithin       = "can_renewable_within"
+	SubscriptionLimitPerYear = "subscription_limit_per_year"
+	ProductCount             = "product_count"
+	TrackCount               = "track_count"
+	ArtistCount              = "artist_count"
+	Prices                   = "prices"
+	Retired                  = "retired"
+
+	// MaxPlanNameLength is the maximum length of the name and SKU of a subscription plan.
+	MaxPlanNameLength = 100
+	// MaxPlanGraceWeeks is the maximum number of weeks a plan can be renewed within after expiring.
+	MaxPlanGraceWeeks = 52
+
+	SuccessfullyCreatedPlan   = "Subscription plan created successfully"
+	SuccessfullyVersionedPlan = "Subscription plan version published successfully"
+	SuccessfullyRetiredPlan   = "Subscription plan retired successfully"
+)
+
+// ErrPlanRetired is returned when a version is published for a retired subscription plan.
+var ErrPlanRetired = errors.New("the subscription plan is retired")
+
+// ErrPlanPriceNotFound is returned when a subscription plan has no price in the requested currency.
+var ErrPlanPriceNotFound = errors.New("the subscription plan has no price in the currency")
+
+// Subscription auto-renewal
+const (
+	// Outcomes of automatic renewal attempts. Pending payments are completed by the payment webhook.
+	RenewalSucceeded = "succeeded"
+	RenewalPending   = "pending"
+	RenewalFailed    = "failed"
+
+	// NotRecurring is returned when auto-renewal is enabled for a free or one-time subscription.
+	NotRecurring = "not_recurring"
+
+	SuccessfullyUpdatedAutoRenewal    = "Subscription auto-renewal updated successfully"
+	SuccessfullyListedRenewalAttempts = "Subscription renewal attempts listed successfully"
+)
+
+// RenewableStatuses lists the statuses of subscriptions the renewal job charges.
+var RenewableStatuses = []string{SubscriptionStatusActive, SubscriptionStatusWarning, SubscriptionStatusInGrace}
+
+// Promo codes
+const (
+	// Types of promo code discounts: a percentage of the plan price or a fixed amount off it.
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+
+	// Validation keys of promo codes.
+	PromoCode               = "promo_code"
+	PromoCodeID             = "promo_code_id"
+	DiscountType            = "discount_type"
+	DiscountValue           = "discount_value"
+	CurrencyID              = "currency_id"
+	PartnerIDs              = "partner_ids"
+	PlanIDs                 = "plan_ids"
+	ValidUntil              = "valid_until"
+	MaxRedemptions          = "max_redemptions"
+	MaxRedemptionsPerMember = "max_redemptions_per_member"
+
+	// MaxPromoCodeLength is the maximum length of a promo code.
+	MaxPromoCodeLength = 32
+
+	SuccessfullyCreatedPromoCode       = "Promo code created successfully"
+	SuccessfullyListedPromoCodes       = "Promo codes listed successfully"
+	SuccessfullyListedPromoRedemptions = "Promo code redemptions listed successfully"
+)
+
+// DiscountTypes lists the supported promo code discount types.
+var DiscountTypes = []string{DiscountPercentage, DiscountFixed}
+
+// ErrPromoCodeLimitReached is returned when a checkout redeems a promo code past its total or
+// per-member redemption limit.
+var ErrPromoCodeLimitReached = errors.New("the promo code redemption limit is reached")
@@ -1,12 +1,21 @@
 package controllers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"member/internal/consts"
 	constant "member/internal/consts"
 	"member/internal/entities"
+	"member/internal/etag"
+	"member/internal/export"
+	"member/internal/invoice"
+	"member/internal/payment"
 	"member/internal/usecases"
 
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -69,6 +78,21 @@ func (member *MemberController) InitRoutes() {
 	member.router.POST("/:version/members", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "RegisterMember")
 	})
+	member.router.GET("/:version/members/:member_id/stores", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListMemberStores")
+	})
+	member.router.PUT("/:version/members/:member_id/stores/order", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ReorderMemberStores")
+	})
+	member.router.PATCH("/:version/members/:member_id/stores/:store_id", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "UpdateMemberStore")
+	})
+	member.router.DELETE("/:version/members/:member_id/stores/:store_id", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "RemoveMemberStore")
+	})
+	member.router.POST("/:version/members/stores/bulk", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "BulkMemberStores")
+	})
 	member.router.POST("/:version/members/:member_id/stores", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "AddMemberStores")
 	})
@@ -90,15 +114,120 @@ func (member *MemberController) InitRoutes() {
 	member.router.PATCH("/:version/members/:member_id/subscriptions/renewal", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "SubscriptionRenewal")
 	})
+	member.router.PATCH("/:version/members/:member_id/subscriptions/auto-renewal", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "SubscriptionAutoRenewal")
+	})
+	member.router.GET("/:version/members/:member_id/subscriptions/:member_subscription_id/renewal-attempts", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListRenewalAttempts")
+	})
 	member.router.PATCH("/:version/members/:member_id/subscriptions/cancel", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "SubscriptionCancellation")
 	})
 	member.router.PATCH("/:version/members/:member_id/subscriptions/product-switch", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "SubscriptionProductSwitch")
 	})
+	member.router.PATCH("/:version/members/:member_id/subscriptions/plan-change", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "SubscriptionPlanChange")
+	})
 	member.router.GET("/:version/members/:member_id/subscriptions", func(ctx *gin.Context) {
 		version.RenderHandler(ctx, member, "ViewAllSubscriptions")
 	})
+	member.router.GET("/:version/members/:member_id/invoices", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListInvoices")
+	})
+	member.router.GET("/:version/members/:member_id/invoices/:invoice_id", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "GetInvoice")
+	})
+	member.router.GET("/:version/members/:member_id/subscriptions/:member_subscription_id/quota", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "GetSubscriptionQuota")
+	})
+	member.router.POST("/:version/members/:member_id/subscriptions/:member_subscription_id/quota/check", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "CheckQuota")
+	})
+	member.router.POST("/:version/members/:member_id/subscriptions/:member_subscription_id/quota/reservations", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ReserveQuota")
+	})
+	member.router.POST("/:version/members/:member_id/subscriptions/:member_subscription_id/quota/reservations/:reservation_id/commit", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "CommitQuotaReservation")
+	})
+	member.router.DELETE("/:version/members/:member_id/subscriptions/:member_subscription_id/quota/reservations/:reservation_id", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ReleaseQuotaReservation")
+	})
+	member.router.GET("/:version/members/:member_id/audit", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListMemberAudit")
+	})
+	member.router.GET("/:version/members/:member_id/export", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ExportMember")
+	})
+	member.router.POST("/:version/members/:member_id/erasure", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "RequestMemberErasure")
+	})
+	member.router.POST("/:version/members/:member_id/unlock", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "UnlockMember")
+	})
+	member.router.POST("/:version/members/:member_id/two-factor", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "EnrollTwoFactor")
+	})
+	member.router.POST("/:version/members/:member_id/two-factor/verify", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "EnableTwoFactor")
+	})
+	member.router.DELETE("/:version/members/:member_id/two-factor", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "DisableTwoFactor")
+	})
+	member.router.POST("/:version/members/oauth/two-factor", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "CompleteTwoFactorChallenge")
+	})
+	member.router.POST("/:version/members/bulk", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ImportMembers")
+	})
+	member.router.GET("/:version/members/bulk/:import_id", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "GetMemberImport")
+	})
+	member.router.GET("/:version/members/export", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ExportMembers")
+	})
+	member.router.POST("/:version/members/verify-email", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "VerifyEmail")
+	})
+	member.router.POST("/:version/members/verify-email/resend", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ResendEmailVerification")
+	})
+	member.router.GET("/:version/partners/:partner_id/email-verification-policy", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "EmailVerificationPolicy")
+	})
+	member.router.PUT("/:version/partners/:partner_id/email-verification-policy", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "EmailVerificationPolicy")
+	})
+	member.router.GET("/:version/plans", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListSubscriptionPlans")
+	})
+	member.router.POST("/:version/plans", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "CreateSubscriptionPlan")
+	})
+	member.router.GET("/:version/plans/:plan_id/versions", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "GetSubscriptionPlanVersions")
+	})
+	member.router.POST("/:version/plans/:plan_id/versions", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "AddSubscriptionPlanVersion")
+	})
+	member.router.POST("/:version/plans/:plan_id/retire", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "RetireSubscriptionPlan")
+	})
+	member.router.GET("/:version/promo-codes", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListPromoCodes")
+	})
+	member.router.POST("/:version/promo-codes", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "CreatePromoCode")
+	})
+	member.router.GET("/:version/promo-codes/:promo_code_id/redemptions", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "ListPromoCodeRedemptions")
+	})
+	member.router.GET("/:version/partners/:partner_id/stores", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "PartnerStores")
+	})
+	member.router.POST("/:version/payments/webhooks/:gateway", func(ctx *gin.Context) {
+		version.RenderHandler(ctx, member, "PaymentWebhook")
+	})
 }
 
 // HealthHandler handles health check requests and responds with the server's health status.
@@ -322,30 +451,15 @@ func (member *MemberController) AddMemberStores(ctx *gin.Context) {
 	})
 }
 
-// UpdateBillingAddress handles updating a billing address for a member.
-// It handles updating a billing address for a member.
-// It performs the following steps:
-//   - Validates the endpoint and method.
-//   - Parses the member_id from the URL parameters.
-//   - Binds the JSON request body to the  struct.
-
-//   - Handles validation errors and internal server errors.
-//   - Logs relevant information about the process.
-//
-// Parameters:
-//
-//	@ctx (*gin.Context): The Gin context for handling the HTTP request.
-func (member *MemberController) UpdateBillingAddress(ctx *gin.Context) {
-	// Retrieve and preprocess request details
+// ListMemberStores lists the stores of a member in the member's order.
+func (member *MemberController) ListMemberStores(ctx *gin.Context) {
 	method := strings.ToLower(ctx.Request.Method)
-	endpointUrl := ctx.FullPath()
-
-	// Check if the endpoint exists in the context
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
-	// Check if the endpoint exists, if not, respond with a validation error.
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update billing address failed ,endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx).Errorf("List member stores failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -353,131 +467,69 @@ func (member *MemberController) UpdateBillingAddress(ctx *gin.Context) {
 		})
 		return
 	}
-	// Get the contextError map to handle error responses.
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Updating Billing Address failed, Failed to fetch error values from context")
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List member stores failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Extract member_id from the URL parameters (UUID parsing)
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
-	memberBillingStr := ctx.Param("billing_address_id")
-	memberBillingID, err := uuid.Parse(memberBillingStr)
-	// If parsing fails, log an error and return an appropriate JSON response
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, invalid member_id: %s", err.Error())
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-
-		if hasError {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("List member stores failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
-	}
-
-	// Bind the JSON request body to the entities package's BillingAddress struct
-	var updatedBillingAddress entities.BillingAddress
-	if err := ctx.ShouldBindJSON(&updatedBillingAddress); err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, invalid JSON data: %s", err.Error())
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
 		return
 	}
 
-	// Call the use case to update the billing address
-	fieldsMap, err := member.useCases.UpdateBillingAddress(ctx, memberID, memberBillingID, updatedBillingAddress)
-
+	stores, validationErrors, err := member.useCases.ListMemberStores(ctx, memberID)
 	if err != nil {
-		if err.Error() == "Member does not exist" {
-			// Member not exist
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, member not exist: %s", err.Error())
-			val, hasError, errorCode := utils.ParseFields(ctx, consts.NotFoundErr, "", contextError, "", "")
-			if !hasError {
-				ctx.JSON(int(errorCode), val)
-				return
-			}
-		} else {
-			// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed : Internal server error %s", err.Error())
-			val, _, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		logger.Log().WithContext(ctx).Errorf("List member stores failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	if len(fieldsMap) > 0 {
-		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx).Errorf("Update billing address failed, err = %s", fields)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-
-		if hasError {
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List member stores failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Billing address updated successfully
-	logger.Log().WithContext(ctx.Request.Context()).Info("Update BillingAddress: Billing address updated successfully")
-
-	// Data updated successfully
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": consts.SuccessUpdated,
-	})
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedMemberStores, "data": stores})
 }
 
-// ChangePassword handles requests to change a member's password.
-//
-// This function handles password change requests and expects the following parameters:
-//   - member_id (UUID): The unique identifier of the member whose password needs to be changed.
-//   - NewPassword (string): The new password to set for the member.
-//   - CurrentPassword (string): The current password to validate the change.
-//
-// It performs the following steps:
-//   - Validates the endpoint and method.
-//   - Parses the member_id from the URL parameters.
-//   - Binds the JSON request body to the passwordChangeRequest struct.
-//   - Calls the ChangePassword use case with the memberID and new password.
-//   - Handles validation errors and internal server errors.
-//   - Logs relevant information about the process.
-//
-// Parameters:
-//
-//	@ctx (*gin.Context): The Gin context for handling the HTTP request.
-//
-// HTTP Request:
-//
-//	POST /members/:member_id/change-password
-//
-// JSON Request Body:
-//
-//	{
-//	  "NewPassword": "string",
-//	  "CurrentPassword": "string"
-//	}
-//
-// Returns:
-//   - If successful, it responds with HTTP status 201 (Created) and a success message.
-//   - If any errors occur during parsing, binding, or processing, it responds with an appropriate error message and status.
-func (member *MemberController) ChangePassword(ctx *gin.Context) {
-	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
-
-	methods := ctx.Request.Method
-	method := strings.ToLower(methods)
+// UpdateMemberStore renames or (de)activates a store of a member.
+func (member *MemberController) UpdateMemberStore(ctx *gin.Context) {
+	var update entities.MemberStoreUpdate
+	if err := ctx.BindJSON(&update); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Update member store failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
 
-	// Get the full endpoint URL of the request.
+	method := strings.ToLower(ctx.Request.Method)
 	endpointURL := ctx.FullPath()
-
-	// Get the contextEndpoints map to check if the endpoint exists.
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-
-	// Get the endpoint details based on the URL and HTTP method.
 	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists, if not, respond with a validation error.
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("ChangePassword failed, validation error, endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx).Errorf("Update member store failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -486,109 +538,130 @@ func (member *MemberController) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	// Get the contextError map to handle error responses.
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Change Password failed, Failed to fetch error values from context")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Update member store failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Parse the member_id from the URL parameters.
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update member store failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	// Check for errors in member_id parsing.
+	validationErrors, err := member.useCases.UpdateMemberStore(ctx, memberID, ctx.Param("store_id"), update)
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password changing failed, invalid member_id: %s", err.Error())
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Update member store failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Bind the JSON request body to the passwordChangeRequest struct.
-	var passwordChangeRequest entities.PasswordChangeRequest
-	if err := ctx.BindJSON(&passwordChangeRequest); err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password change failed, invalid JSON data: %s", err.Error())
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Update member store failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyUpdatedMemberStore})
+}
+
+// RemoveMemberStore removes a store from a member.
+func (member *MemberController) RemoveMemberStore(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Remove member store failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":  "Invalid JSON data",
-			"errors": err.Error(),
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
 		})
 		return
 	}
 
-	// Call the ChangePassword use case with the memberID and new password.
-	fieldsMap, err := member.useCases.ChangePassword(ctx, memberID, passwordChangeRequest.Key, passwordChangeRequest.NewPassword, passwordChangeRequest.CurrentPassword)
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Remove member store failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
 
-	// Handle errors during password change.
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password change failed: %s", err.Error())
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Remove member store failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Handle validation errors.
-	if len(fieldsMap) > 0 {
-		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx).Errorf("Password Changing failed, validation error, err = %s", fields)
-		val, hasValue, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		// Check if the ParseFields function returned a value (hasValue).
-		if hasValue {
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("ChangePassword failed,: %s", val)
+	validationErrors, err := member.useCases.RemoveMemberStore(ctx, memberID, ctx.Param("store_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Remove member store failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Log password change completion.
-	logger.Log().WithContext(ctx.Request.Context()).Info("Password change: Successfull")
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Remove member store failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	// Respond with success status.
-	ctx.JSON(http.StatusCreated, gin.H{
-		"message": consts.Success,
-	})
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyRemovedMemberStore})
 }
 
-// ResetPassword is a controller function responsible for initiating the password reset process for a member.
-//
-// Inputs:
-// - ctx: The Gin context containing information about the HTTP request and response.
-// Functionality:
-// 1. Retrieves the HTTP request method and endpoint URL.,. Validates the endpoint's existence.
-// 2. Parses the member ID from the URL parameters and validates its format.
-// 3. Binds the incoming JSON request body to the `entities.ResetPassword` struct to fetch the email. Calls the `InitiatePasswordReset` use case to generate a reset key for the member based on the provided email.
-// 4. Handles any errors or validation issues that may arise during the process., Logs the outcome of the password reset process, whether successful or with errors.
-//
-// Outputs:
-// - JSON response containing the generated reset key if the process is successful.
-// - Appropriate error responses if any validation fails or if there's an internal server error.
-//
-// HTTP Method: POST (since the function initiates a password reset which is typically achieved via a POST request).
-//
-// Error Handling:
-// The function handles potential errors such as invalid member IDs, invalid JSON data, and any errors returned during the password reset process.
-func (member *MemberController) ResetPassword(ctx *gin.Context) {
-	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
-	methods := ctx.Request.Method
-	method := strings.ToLower(methods)
+// ReorderMemberStores orders the stores of a member, the request lists every store of the
+// member in the new order.
+func (member *MemberController) ReorderMemberStores(ctx *gin.Context) {
+	var order entities.MemberStoreOrder
+	if err := ctx.BindJSON(&order); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Reorder member stores failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
 
-	// Get the full endpoint URL of the request.
+	method := strings.ToLower(ctx.Request.Method)
 	endpointURL := ctx.FullPath()
-
-	// Get the contextEndpoints map to check if the endpoint exists.
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-
-	// Get the endpoint details based on the URL and HTTP method.
 	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
-	_ = endpoint
-	// Check if the endpoint exists, if not, respond with a validation error.
+
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Password failed, validation error, endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx).Errorf("Reorder member stores failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -597,105 +670,197 @@ func (member *MemberController) ResetPassword(ctx *gin.Context) {
 		return
 	}
 
-	// Get the contextError map to handle error responses.
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Reset Password failed, Failed to fetch error values from context")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Reorder member stores failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Parse the member_id from the URL parameters.
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reorder member stores failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	// Check for errors in member_id parsing.
+	validationErrors, err := member.useCases.ReorderMemberStores(ctx, memberID, order)
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Password  failed, invalid member_id: %s", err.Error())
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Reorder member stores failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Bind the JSON request body to the passwordChangeRequest struct.
-	var initiatePasswordReset entities.ResetPassword
-	if err := ctx.BindJSON(&initiatePasswordReset); err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password Reset  failed, invalid JSON data: %s", err.Error())
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Reorder member stores failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyReorderedMemberStore})
+}
 
+// PartnerStores lists the catalogue of stores a partner offers to its members.
+func (member *MemberController) PartnerStores(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Partner stores failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error":  "Invalid JSON data",
-			"errors": err.Error(),
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
 		})
 		return
 	}
 
-	// Call the ChangePassword use case with the memberID and new password.
-	key, fieldsMap, err := member.useCases.InitiatePasswordReset(ctx, memberID, initiatePasswordReset.Email)
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Partner stores failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
 
-	// Handle errors during password change.
+	partnerID, err := uuid.Parse(ctx.Param("partner_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Partner stores failed: Invalid partner_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.PartnerID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	stores, validationErrors, err := member.useCases.GetPartnerStores(ctx, partnerID)
 	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Partner stores failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password reset failed: %s", err.Error())
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasError {
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Partner stores failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
-	// Handle validation errors.
-	if len(fieldsMap) > 0 {
-		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx).Errorf("Password Reset Initiation failed, validation error, err = %s", fields)
-		val, hasValue, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		// Check if the ParseFields function returned a value (hasValue).
-		if hasValue {
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Initiation failed,: %s", val)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedPartnerStores, "data": stores})
+}
+
+// BulkMemberStores applies stores to, or removes stores from, many members at once and reports
+// the outcome per member.
+func (member *MemberController) BulkMemberStores(ctx *gin.Context) {
+	var request entities.BulkMemberStores
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Bulk member stores failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Bulk member stores failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Bulk member stores failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	results, validationErrors, err := member.useCases.BulkMemberStores(ctx, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Bulk member stores failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	// Log password change completion.
-	logger.Log().WithContext(ctx.Request.Context()).Info("Password reset: Initiated Succesfully")
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Bulk member stores failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	// Respond with success status.
-	ctx.JSON(http.StatusCreated, gin.H{
-		"Key":     key,
-		"message": consts.SuccessfullyInitiated,
-	})
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyAppliedBulkStores, "data": results})
 }
 
-// GetAllBillingAddresses handles the HTTP request to add a billing address to a member's account.
-// It validates the endpoint and method, parses the member_id from the URL parameters,
-// and binds the JSON request body to the GetAllBillingAddresses struct.
-// It then calls the AddBillingAddress use case with the extracted memberID and billingAddressRequest.
-// It handles validation errors, "No record found" errors, and internal server errors.
-// Finally, it responds with a success message if the billing address is added successfully.
+// UpdateBillingAddress handles updating a billing address for a member.
+// It handles updating a billing address for a member.
+// It performs the following steps:
+//   - Validates the endpoint and method.
+//   - Parses the member_id from the URL parameters.
+//   - Binds the JSON request body to the  struct.
+
+//   - Handles validation errors and internal server errors.
+//   - Logs relevant information about the process.
 //
 // Parameters:
 //
 //	@ctx (*gin.Context): The Gin context for handling the HTTP request.
-//
-// Returns:
-//   - If successful, it responds with HTTP status 200 (OK) and a success message.
-//   - If any errors occur during validation, "No record found" errors, or processing, it responds with an appropriate error message and status.
-
-func (controller *MemberController) GetAllBillingAddresses(ctx *gin.Context) {
-	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
-	methods := ctx.Request.Method
-	method := strings.ToLower(methods)
+func (member *MemberController) UpdateBillingAddress(ctx *gin.Context) {
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
 	endpointUrl := ctx.FullPath()
 
+	// Check if the endpoint exists in the context
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-
-	// Attempt to retrieve the specific endpoint associated with the current request
 	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
-	_ = endpoint
-
-	// If the endpoint does not exist, log an error and return a 400 Bad Request response
+	// Check if the endpoint exists, if not, respond with a validation error.
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Viewing Billing Address Failed, validation error, endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update billing address failed ,endpoint does not exist in the database.")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -703,105 +868,2799 @@ func (controller *MemberController) GetAllBillingAddresses(ctx *gin.Context) {
 		})
 		return
 	}
-
 	// Get the contextError map to handle error responses.
 	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
 	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Viewing all Billing Address failed:Failed to load Context errors")
+		logger.Log().WithContext(ctx.Request.Context()).Error("Updating Billing Address failed, Failed to fetch error values from context")
+		return
+	}
+
+	// Updates made against an outdated version of the billing address are refused
+	expectedVersion, isValidVersion := etag.Parse(ctx.GetHeader(consts.IfMatch))
+	if !isValidVersion {
+		logger.Log().WithContext(ctx).Errorf("Update BillingAddress failed: If-Match does not match")
+		member.preconditionFailed(ctx, contextError, endpoint, method)
 		return
 	}
 
+	// Extract member_id from the URL parameters (UUID parsing)
 	memberIDStr := ctx.Param("member_id")
 	memberID, err := uuid.Parse(memberIDStr)
+	memberBillingStr := ctx.Param("billing_address_id")
+	memberBillingID, err := uuid.Parse(memberBillingStr)
 	// If parsing fails, log an error and return an appropriate JSON response
-
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Invalid member_id: %s", err.Error())
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, invalid member_id: %s", err.Error())
 		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+
 		if hasError {
 			ctx.JSON(int(errorCode), val)
 			return
 		}
 	}
-	params := entities.Params{}
+
+	// Bind the JSON request body to the entities package's BillingAddress struct
+	var updatedBillingAddress entities.BillingAddress
+	if err := ctx.ShouldBindJSON(&updatedBillingAddress); err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, invalid JSON data: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	// Call the use case to update the billing address
+	fieldsMap, err := member.useCases.UpdateBillingAddress(ctx, memberID, memberBillingID, updatedBillingAddress, expectedVersion)
+
+	if errors.Is(err, consts.ErrVersionMismatch) {
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
+	if err != nil {
+		if err.Error() == "Member does not exist" {
+			// Member not exist
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed, member not exist: %s", err.Error())
+			val, hasError, errorCode := utils.ParseFields(ctx, consts.NotFoundErr, "", contextError, "", "")
+			if !hasError {
+				ctx.JSON(int(errorCode), val)
+				return
+			}
+		} else {
+			// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("Update BillingAddress failed : Internal server error %s", err.Error())
+			val, _, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx).Errorf("Update billing address failed, err = %s", fields)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Billing address updated successfully
+	logger.Log().WithContext(ctx.Request.Context()).Info("Update BillingAddress: Billing address updated successfully")
+
+	// Data updated successfully
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": consts.SuccessUpdated,
+	})
+}
+
+// ChangePassword handles requests to change a member's password.
+//
+// This function handles password change requests and expects the following parameters:
+//   - member_id (UUID): The unique identifier of the member whose password needs to be changed.
+//   - Key (string): The reset key emailed to the member.
+//   - NewPassword (string): The new password to set for the member.
+//   - CurrentPassword (string): The current password, optional since the key proves the member.
+//
+// It performs the following steps:
+//   - Validates the endpoint and method.
+//   - Parses the member_id from the URL parameters.
+//   - Binds the JSON request body to the passwordChangeRequest struct.
+//   - Calls the ChangePassword use case with the memberID and new password.
+//   - Handles validation errors and internal server errors.
+//   - Logs relevant information about the process.
+//
+// Parameters:
+//
+//	@ctx (*gin.Context): The Gin context for handling the HTTP request.
+//
+// HTTP Request:
+//
+//	POST /members/:member_id/change-password
+//
+// JSON Request Body:
+//
+//	{
+//	  "NewPassword": "string",
+//	  "CurrentPassword": "string"
+//	}
+//
+// Returns:
+//   - If successful, it responds with HTTP status 201 (Created) and a success message.
+//   - If any errors occur during parsing, binding, or processing, it responds with an appropriate error message and status.
+func (member *MemberController) ChangePassword(ctx *gin.Context) {
+	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
+
+	methods := ctx.Request.Method
+	method := strings.ToLower(methods)
+
+	// Get the full endpoint URL of the request.
+	endpointURL := ctx.FullPath()
+
+	// Get the contextEndpoints map to check if the endpoint exists.
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	// Get the endpoint details based on the URL and HTTP method.
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("ChangePassword failed, validation error, endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("Change Password failed, Failed to fetch error values from context")
+		return
+	}
+
+	// Parse the member_id from the URL parameters.
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+
+	// Check for errors in member_id parsing.
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password changing failed, invalid member_id: %s", err.Error())
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Bind the JSON request body to the passwordChangeRequest struct.
+	var passwordChangeRequest entities.PasswordChangeRequest
+	if err := ctx.BindJSON(&passwordChangeRequest); err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password change failed, invalid JSON data: %s", err.Error())
+
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid JSON data",
+			"errors": err.Error(),
+		})
+		return
+	}
+
+	// Call the ChangePassword use case with the memberID and new password.
+	fieldsMap, err := member.useCases.ChangePassword(ctx, memberID, passwordChangeRequest.Key, passwordChangeRequest.NewPassword, passwordChangeRequest.CurrentPassword)
+
+	// Handle errors during password change.
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password change failed: %s", err.Error())
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Handle validation errors.
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx).Errorf("Password Changing failed, validation error, err = %s", fields)
+		val, hasValue, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		// Check if the ParseFields function returned a value (hasValue).
+		if hasValue {
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("ChangePassword failed,: %s", val)
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Log password change completion.
+	logger.Log().WithContext(ctx.Request.Context()).Info("Password change: Successfull")
+
+	// Respond with success status.
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": consts.Success,
+	})
+}
+
+// ResetPassword is a controller function responsible for initiating the password reset process for a member.
+//
+// Inputs:
+// - ctx: The Gin context containing information about the HTTP request and response.
+// Functionality:
+// 1. Retrieves the HTTP request method and endpoint URL.,. Validates the endpoint's existence.
+// 2. Parses the member ID from the URL parameters and validates its format.
+// 3. Binds the incoming JSON request body to the `entities.ResetPassword` struct to fetch the email. Calls the `InitiatePasswordReset` use case to generate a reset key for the member based on the provided email.
+// 4. Handles any errors or validation issues that may arise during the process., Logs the outcome of the password reset process, whether successful or with errors.
+//
+// Outputs:
+// - JSON response acknowledging that the reset was initiated. The reset key itself is sent to the member's email.
+// - Appropriate error responses if any validation fails or if there's an internal server error.
+//
+// HTTP Method: POST (since the function initiates a password reset which is typically achieved via a POST request).
+//
+// Error Handling:
+// The function handles potential errors such as invalid member IDs, invalid JSON data, and any errors returned during the password reset process.
+func (member *MemberController) ResetPassword(ctx *gin.Context) {
+	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
+	methods := ctx.Request.Method
+	method := strings.ToLower(methods)
+
+	// Get the full endpoint URL of the request.
+	endpointURL := ctx.FullPath()
+
+	// Get the contextEndpoints map to check if the endpoint exists.
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	// Get the endpoint details based on the URL and HTTP method.
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+	_ = endpoint
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Password failed, validation error, endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("Reset Password failed, Failed to fetch error values from context")
+		return
+	}
+
+	// Parse the member_id from the URL parameters.
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+
+	// Check for errors in member_id parsing.
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Password  failed, invalid member_id: %s", err.Error())
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Bind the JSON request body to the passwordChangeRequest struct.
+	var initiatePasswordReset entities.ResetPassword
+	if err := ctx.BindJSON(&initiatePasswordReset); err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password Reset  failed, invalid JSON data: %s", err.Error())
+
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid JSON data",
+			"errors": err.Error(),
+		})
+		return
+	}
+
+	// Call the ChangePassword use case with the memberID and new password.
+	fieldsMap, err := member.useCases.InitiatePasswordReset(ctx, memberID, initiatePasswordReset.Email)
+
+	// Handle errors during password change.
+	if err != nil {
+
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Password reset failed: %s", err.Error())
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// Handle validation errors.
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx).Errorf("Password Reset Initiation failed, validation error, err = %s", fields)
+		val, hasValue, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		// Check if the ParseFields function returned a value (hasValue).
+		if hasValue {
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("Reset Initiation failed,: %s", val)
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// Log password change completion.
+	logger.Log().WithContext(ctx.Request.Context()).Info("Password reset: Initiated Succesfully")
+
+	// Respond with success status, the key is delivered by email only.
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": consts.SuccessfullyInitiated,
+	})
+}
+
+// VerifyEmail verifies the email address of a member with the token sent to it on registration.
+func (member *MemberController) VerifyEmail(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	var request entities.VerifyEmail
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	validationErrors, err := member.useCases.VerifyEmail(ctx, request.Token)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyVerifiedEmail})
+}
+
+// ResendEmailVerification sends a new verification token to an unverified member of the partner
+// named in the partner_id header. The response is the same whether or not a token was sent.
+func (member *MemberController) ResendEmailVerification(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	var request entities.ResendEmailVerification
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	validationErrors, err := member.useCases.ResendEmailVerification(ctx, ctx.GetString(consts.ContextPartnerID), request.Email)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": consts.SuccessfullyResentVerification})
+}
+
+// EmailVerificationPolicy returns, on GET, or replaces, on PUT, the actions the partner blocks
+// until the member's email address is verified.
+func (member *MemberController) EmailVerificationPolicy(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Email verification policy failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Email verification policy failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	partnerID, err := uuid.Parse(ctx.Param("partner_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Email verification policy failed: Invalid partner_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.PartnerID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	var (
+		policy           entities.EmailVerificationPolicy
+		validationErrors map[string][]string
+		message          = consts.SuccessfullyFetchedPolicy
+	)
+	if ctx.Request.Method == http.MethodPut {
+		if err := ctx.BindJSON(&policy); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Email verification policy failed, Invalid JSON data, err=%s", err.Error())
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid JSON data",
+			})
+			return
+		}
+		validationErrors, err = member.useCases.UpdateEmailVerificationPolicy(ctx, partnerID, policy)
+		message = consts.SuccessfullyUpdatedPolicy
+	} else {
+		policy, validationErrors, err = member.useCases.GetEmailVerificationPolicy(ctx, partnerID)
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Email verification policy failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Email verification policy failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": message, "data": policy})
+}
+
+// GetAllBillingAddresses handles the HTTP request to add a billing address to a member's account.
+// It validates the endpoint and method, parses the member_id from the URL parameters,
+// and binds the JSON request body to the GetAllBillingAddresses struct.
+// It then calls the AddBillingAddress use case with the extracted memberID and billingAddressRequest.
+// It handles validation errors, "No record found" errors, and internal server errors.
+// Finally, it responds with a success message if the billing address is added successfully.
+//
+// Parameters:
+//
+//	@ctx (*gin.Context): The Gin context for handling the HTTP request.
+//
+// Returns:
+//   - If successful, it responds with HTTP status 200 (OK) and a success message.
+//   - If any errors occur during validation, "No record found" errors, or processing, it responds with an appropriate error message and status.
+
+func (controller *MemberController) GetAllBillingAddresses(ctx *gin.Context) {
+	// Get the HTTP request method (GET, POST, etc.) and convert it to lowercase.
+	methods := ctx.Request.Method
+	method := strings.ToLower(methods)
+	endpointUrl := ctx.FullPath()
+
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	// Attempt to retrieve the specific endpoint associated with the current request
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	_ = endpoint
+
+	// If the endpoint does not exist, log an error and return a 400 Bad Request response
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Viewing Billing Address Failed, validation error, endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("Viewing all Billing Address failed:Failed to load Context errors")
+		return
+	}
+
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+	// If parsing fails, log an error and return an appropriate JSON response
+
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Invalid member_id: %s", err.Error())
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	params := entities.Params{}
+	if page, exists := ctx.GetQuery("page"); !exists || page == "" {
+		params.Page = consts.DefaultPage
+	}
+	if limit, exists := ctx.GetQuery("limit"); !exists || limit == "" {
+		params.Limit = consts.DefaultLimit
+	}
+	// Call the GetAllBillingAddresses use case with the extracted memberID
+	fieldsMap, billingAddresses, _, err := controller.useCases.GetAllBillingAddresses(ctx, memberID, params)
+
+	//Checks the length of fieldMap for checking is there any validation error reported or not.
+	if len(fieldsMap) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Viewing All Billing Addresses Failed")
+		fields := utils.FieldMapping(fieldsMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasError {
+			logger.Log().WithContext(ctx).Errorf("Viewing Billing Address")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	if err != nil {
+		// Check if the error message contains "No record found"
+		if strings.Contains(err.Error(), "No record found") {
+			// Handle the "No record found" error
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Failed to retrieve billing address, err=%s", err.Error())
+			val, hasError, errorCode := utils.ParseFields(ctx, consts.NotFoundErr, "", contextError, "", "")
+			if hasError {
+				ctx.JSON(int(errorCode), val)
+				return
+			}
+		} else {
+			// Handle other errors, e.g., internal server error
+			logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Failed to retrieve billing address, err=%s", err.Error())
+			val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+			if hasError {
+				ctx.JSON(int(errorCode), val)
+				return
+			}
+		}
+	}
+	if len(billingAddresses) == 0 {
+		ctx.JSON(http.StatusOK, gin.H{"message": "No Billing details to show",
+			"data": billingAddresses})
+		return
+	}
+	// Send the response as JSON with a success message
+
+	ctx.JSON(http.StatusOK, entities.Response{
+		BillingAddress: billingAddresses,
+		Message:        consts.SuccessfullyListed,
+	})
+
+	// Log the success message
+	logger.Log().WithContext(ctx.Request.Context()).Info("View All Billing Address: Billing Address Listed successfully")
+}
+
+// RegisterMember is for registraion of member
+// This endpoint expects a JSON payload representing the member's registration data.
+// Request JSON Body:
+//
+//	-Member: The JSON object containing member details.
+//
+// Response:
+//   - If successful, it returns a JSON response with status code 201 (Created).
+//   - If there is an error in the request (e.g., invalid JSON) or processing, it returns an
+//     error JSON response with the appropriate status code and error message.
+func (member *MemberController) RegisterMember(ctx *gin.Context) {
+	ctxt := ctx.Request.Context()
+	methods := ctx.Request.Method
+	method := strings.ToLower(methods)
+	endpointUrl := ctx.FullPath()
+
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists in the context
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Member registration failed,Invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("Member registration failed:Failed to load Context errors")
+		return
+	}
+
+	var reqIn entities.Member
+	if err := ctx.BindJSON(&reqIn); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Member registration failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+	// Retrieve PartnerID from the Gin context
+	partnerID, exists := ctx.Get("partner_id")
+
+	if !exists {
+		// Handle the case where PartnerID is not found in the context
+		logger.Log().WithContext(ctx).Error(" ")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+	// Convert the PartnerID to a string
+	partnerIDStr, ok := partnerID.(string)
+	if !ok || len(partnerIDStr) == 0 {
+		// Log the error
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+
+		// Return an error response
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to set PartnerID in context",
+			"errors":    nil,
+		})
+		return
+	}
+
+	//Call the RegisterMember
+	fieldMap, err := member.useCases.RegisterMember(ctxt, reqIn, contextError, partnerIDStr, endpoint, method)
+
+	//Checks the length of fieldMap for checking is there any validation error reported or not.
+	if len(fieldMap) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Member registration failed: validation error")
+		// to build the field format.
+		fields := utils.FieldMapping(fieldMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
+			fields, contextError, endpoint, method)
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	if err != nil {
+		//For logging error message
+		logger.Log().WithContext(ctx).Errorf("Member registration failed: database error err=%s", err.Error())
+		//Retrieve error based on the api's requirement from the errors which are stored in context
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
+			"", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, consts.SuccessfullyRegistered)
+
+	// Log the success message
+	logger.Log().WithContext(ctx).Info("Member Registration: Member Registered successfully")
+}
+
+// UpdateMember is for registraion of member
+// This endpoint expects a JSON payload representing the member's data updation.
+// Request JSON Body:
+//
+//	-Member: The JSON object containing member details.
+//
+// Response:
+//   - If successful, it returns a JSON response with status code 201 (Created).
+//   - If there is an error in the request (e.g., invalid JSON) or processing, it returns an
+//     error JSON response with the appropriate status code and error message.
+func (member *MemberController) UpdateMember(ctx *gin.Context) {
+	var memberID uuid.UUID
+
+	methods := ctx.Request.Method
+	method := strings.ToLower(methods)
+	endpointUrl := ctx.FullPath()
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists in the context
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Member profile updation failed,Invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("Member profile updation  failed:Failed to load Context errors")
+		return
+	}
+
+	// Updates made against an outdated version of the member are refused
+	expectedVersion, isValidVersion := etag.Parse(ctx.GetHeader(consts.IfMatch))
+	if !isValidVersion {
+		logger.Log().WithContext(ctx).Errorf("Member profile updation failed: If-Match does not match")
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
+
+	var args entities.Member
+	if err := ctx.BindJSON(&args); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Member profile updation failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	//Call the RegisterMember
+	fieldMap, err := member.useCases.UpdateMember(ctx, memberID, args, expectedVersion)
+	if errors.Is(err, consts.ErrVersionMismatch) {
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
+
+	//Checks the length of fieldMap for checking is there any validation error reported or not.
+	if len(fieldMap) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Member profile updation failed: validation error")
+		fields := utils.FieldMapping(fieldMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasError {
+			logger.Log().WithContext(ctx).Errorf("Member profile updation failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	if err != nil {
+		//For logging error message
+		logger.Log().WithContext(ctx).Errorf("Member profileupdation  failed: database error err=%s", err.Error())
+		//Retrieve error based on the api's requirement from the errors which are stored in context
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
+			"", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, consts.SuccessfullyUpdated)
+	logger.Log().WithContext(ctx).Info("Member Profile Updated: Profile successfully Updated ")
+}
+
+// ViewMemberProfile retrieves a member's profile based on the provided member_id.
+// Parameters:
+//
+//	- ctx (gin.Context): The Gin context for handling the HTTP request.
+//	- memberID: The unique identifier of the member to retrieve.
+//
+// Returns:
+//  - memberProfile: The member's profile information.
+//	- error: An error, if any, during the database operation.
+
+func (member *MemberController) ViewMemberProfile(ctx *gin.Context) {
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+	ctxt := ctx.Request.Context()
+
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists in the context
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("View Member Profile:Invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("View Member Profile failed:Failed to load Context errors")
+		return
+	}
+
+	// Extract member_id from the URL parameters (UUID parsing)
+	fieldsMap := map[string][]string{}
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("View Member Profile failed-invalid member_id: err=%s", err.Error())
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
+		fields := utils.FieldMapping(fieldsMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
+			fields, contextError, endpoint, method)
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	fieldMap, memberProfile, err := member.useCases.ViewMemberProfile(ctx, ctxt, memberID, contextError, endpoint, method)
+
+	//Checks the length of fieldMap for checking is there any validation error reported or not.
+	if len(fieldMap) != 0 {
+		logger.Log().WithContext(ctx).Errorf("View Member Profile failed:  validation error")
+		// to build the field format.
+		fields := utils.FieldMapping(fieldMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
+			fields, contextError, endpoint, method)
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	//Checks any database error reported or not.
+	if err != nil {
+		//For logging error message
+		logger.Log().WithContext(ctx).Errorf("View Member Profile failed: err=%s", err.Error())
+		//Retrieve error based on the api's requirement from the errors which are stored in context
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
+			"", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	ctx.Header(consts.ETag, etag.Format(memberProfile.Version))
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member profile details retreived successfully", "data": memberProfile})
+	// Log the success message
+	logger.Log().WithContext(ctx).Info("View Member Profile: Member profile details retreived successfully")
+}
+
+// ViewMembers retrieves a list of members based on the provided query parameters.
+// Requests are paged by offset unless they ask for cursor paging, see usesCursorPaging,
+// in which case they are served by the cursor-paginated ListMembers.
+//
+// Parameters:
+//   - ctx (gin.Context): The Gin context for handling the HTTP request.
+//
+// Returns:
+//   - data ([]entities.Member): A list of member data matching the query parameters.
+//   - error: An error, if any, during the database operation.
+
+func (member *MemberController) ViewMembers(ctx *gin.Context) {
+	if usesCursorPaging(ctx) {
+		member.ListMembers(ctx)
+		return
+	}
+
+	// ctxt := ctx.Request.Context()
+	_, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	// Check if the endpoint exists in the context
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("View Members failed:Invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("View Members failed:Failed to load Context errors")
+		return
+	}
+
+	params := entities.Params{}
+
+	// Check and set default values if any of the headers are empty
+	// Check if the header is empty AND there is no corresponding query parameter
+	if status, exists := ctx.GetQuery("status"); !exists || status == "" {
+		params.Status = consts.DefaultStatus
+	} else {
+		params.Status = status
+	}
+
 	if page, exists := ctx.GetQuery("page"); !exists || page == "" {
 		params.Page = consts.DefaultPage
 	}
-	if limit, exists := ctx.GetQuery("limit"); !exists || limit == "" {
-		params.Limit = consts.DefaultLimit
+	if limit, exists := ctx.GetQuery("limit"); !exists || limit == "" {
+		params.Limit = consts.DefaultLimit
+	}
+	if country, exists := ctx.GetQuery("country"); !exists || country == "" {
+		params.Country = consts.DefaultCountry
+	}
+
+	if gender, exists := ctx.GetQuery("gender"); !exists || gender == "" {
+		params.Gender = consts.DefaultGender
+	} else {
+		gender = strings.ToUpper(gender)
+		if gender != "M" && gender != "F" {
+			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
+				Status:  "failure",
+				Code:    http.StatusBadRequest,
+				Message: "Invalid gender parameter",
+				Errors:  nil,
+			})
+			return
+		}
+		params.Gender = gender
+	}
+
+	if sortBy, exists := ctx.GetQuery("sort"); !exists || sortBy == "" {
+		params.SortBy = consts.DefaultSortBy
+		params.Order = consts.DefaultOrder
+	} else {
+		// Check if the sortby parameter is provided and set the value accordingly
+		switch sortBy {
+		case "firstname":
+			params.SortBy = "firstname"
+			params.Order = "ASC"
+		case "lastname":
+			params.SortBy = "lastname"
+			params.Order = "ASC"
+		case "created_on":
+			params.SortBy = "created_on"
+		case "email":
+			params.SortBy = "email"
+		default:
+			// If an invalid value is provided, set the default to "created_on"
+			params.SortBy = consts.DefaultSortBy
+			params.Order = consts.DefaultOrder
+			// If an invalid value is provided, return a Bad Request response
+			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
+				Status:  "failure",
+				Code:    http.StatusBadRequest,
+				Message: "Invalid sort parameter",
+				Errors:  nil,
+			})
+			return
+		}
+	}
+
+	if order, exists := ctx.GetQuery("order"); exists {
+		switch order {
+		case "asc":
+			params.Order = "ASC"
+		case "desc":
+			params.Order = "DESC"
+		default:
+			params.Order = "ASC"
+		}
+	} else {
+		// If "order" is not present, default to sorting by asc of firstname
+		params.Order = "ASC"
+
+	}
+
+	if partner, exists := ctx.GetQuery("partner"); !exists || partner == "" {
+		params.Partner = consts.DefaultPartner
+	}
+	if role, exists := ctx.GetQuery("role"); !exists || role == "" {
+		params.Role = consts.DefaultRole
+	}
+	if search, exists := ctx.GetQuery("search"); !exists || search == "" {
+		params.Search = consts.DefaultSearch
+	}
+
+	// Call ViewMembers to retrieve member data
+	membersData, metaData, err := member.useCases.ViewMembers(ctx, params)
+
+	if err != nil {
+		// Check if the error message matches the predefined constant error
+		if err.Error() == "invalid param value" {
+			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
+				Status:  "failure",
+				Code:    http.StatusBadRequest,
+				Message: "invalid parameter value",
+				Errors:  nil,
+			})
+			return
+		}
+	}
+	if err != nil {
+		// Log the error message
+		logger.Log().WithContext(ctx).Errorf("View members failed: err=%s", err.Error())
+
+		// Check if the error message matches the predefined constant error
+		if err.Error() == "Exceeds maximum record limit" {
+			ctx.JSON(http.StatusTooManyRequests, entities.FailureResponse{
+				Status:  "failure",
+				Code:    http.StatusTooManyRequests,
+				Message: "Exceeds maximum record limit",
+				Errors:  nil,
+			})
+			return
+		}
+
+		// Retrieve error based on the API's requirement from the errors stored in context
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	if len(membersData) == 0 {
+		ctx.JSON(http.StatusOK, entities.MemberResponse{
+			Status:  "success",
+			Code:    200,
+			Message: "successfully retreived all member details",
+			DataResp: entities.DataResponse{
+				Metadata: metaData,
+				// Data:     membersData,
+			},
+		})
+	} else {
+		ctx.JSON(http.StatusOK, entities.MemberResponse{
+			Status:  "success",
+			Code:    200,
+			Message: "Successfully Listed All Member Details",
+			DataResp: entities.DataResponse{
+				Metadata: metaData,
+				Data:     membersData,
+			},
+		})
+	}
+
+	logger.Log().WithContext(ctx).Info("View members: Members details retreived successfully")
+}
+
+// GetBasicMemberDetailsByEmail retrieves the basic details of a member based on their email.
+//
+// Parameters:
+//   - ctx (gin.Context): The Gin context for handling the HTTP request.
+//
+// Returns:
+//   - None: The function sends a JSON response with the member's basic details.
+func (member *MemberController) GetBasicMemberDetailsByEmail(ctx *gin.Context) {
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+	//ctxt := ctx.Request.Context()
+
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists in the context
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("View Basic Member Details:Invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Error("View Basic Member Details failed:Failed to load Context errors")
+		return
+	}
+
+	// Retrieve PartnerID from the Gin context
+	partnerID, exists := ctx.Get("partner_id")
+
+	if !exists {
+		// Handle the case where PartnerID is not found in the context
+		logger.Log().WithContext(ctx).Error("Failed to get PartnerID from context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+	// Convert the PartnerID to a string
+	partnerIDStr, ok := partnerID.(string)
+
+	if len(partnerIDStr) == 0 {
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to set PartnerID in context",
+			"errors":    nil,
+		})
+		return
+
+	}
+	if !ok {
+		// Handle the case where PartnerID is not in the expected format (uuid)
+		logger.Log().WithContext(ctx).Error("PartnerID is not in the expected format (uuid)")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "PartnerID is not in the expected format (uuid)",
+			"errors":    nil,
+		})
+		return
+	}
+
+	var reqIn entities.MemberPayload
+	if err := ctx.BindJSON(&reqIn); err != nil {
+		logger.Log().WithContext(ctx).Errorf("View failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	fieldMap, basicMemberData, err := member.useCases.GetBasicMemberDetailsByEmail(ctx, partnerIDStr, reqIn, contextError, endpoint, method)
+
+	if err != nil {
+		// For logging error message
+		logger.Log().WithContext(ctx).Errorf("View Basic Member Details failed: err=%s", err.Error())
+		// Retrieve error based on the API's requirement from the errors stored in context
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
+			"", contextError, "", "")
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	if len(fieldMap) != 0 {
+		logger.Log().WithContext(ctx).Errorf("View Basic Member Details failed:  validation error")
+		// Build the field format.
+		fields := utils.FieldMapping(fieldMap)
+		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
+			fields, contextError, endpoint, method)
+		if hasError {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member basic details retrieved successfully", "data": basicMemberData, "partnerId": partnerIDStr})
+	// Log the success message
+	logger.Log().WithContext(ctx).Info("View Basic Member Details: Member basic details retrieved successfully")
+}
+
+// SubscriptionCheckout handles the process of checking out a subscription for a member.
+// This function performs the following steps:
+//  1. Extracts the memberID from the URL.
+//  2. Deserializes the JSON request body into a CheckoutSubscription object.
+//  3. Performs any necessary validation on the checkoutData.
+//  4. Calls the HandleSubscriptionCheckout use case function.
+//  5. Responds with appropriate JSON results based on success or failure.
+//
+// Parameters:
+//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
+func (member *MemberController) SubscriptionCheckout(ctx *gin.Context) {
+
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+
+	// Check if the endpoint exists in the context
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout failed , endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout failed,Failed to load context errors")
+		return
+	}
+
+	// Extract memberID from the URL
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+	// Retrieve PartnerID from the Gin context
+	partnerID, exists := ctx.Get("partner_id")
+
+	if !exists {
+		// Handle the case where PartnerID is not found in the context
+		logger.Log().WithContext(ctx).Error(" ")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+	// Convert the PartnerID to a string
+	partnerIDStr, ok := partnerID.(string)
+	if !ok || len(partnerIDStr) == 0 {
+		// Log the error
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+
+		// Return an error response
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to set PartnerID in context",
+			"errors":    nil,
+		})
+		return
+	}
+	// If parsing fails, log an error and return an appropriate JSON response
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout  failed, invalid member_id: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription checkout/purchase  failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// Deserialize the JSON request body into a CheckoutSubscription object
+	var checkoutData entities.CheckoutSubscription
+	if err := ctx.BindJSON(&checkoutData); err != nil {
+		// Respond with an error message if JSON data is invalid
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout  failed, invalid JSON data: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+	// Call the HandleSubscriptionCheckout use case function
+	fieldsMap, err := member.useCases.HandleSubscriptionCheckout(ctx, memberID, checkoutData, partnerIDStr)
+
+	if err != nil {
+		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Checkout  failed, failed to checkout subscription: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+		return
+	}
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Checkout  failed, failed to checkout subscription")
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasVal {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// If successful, return a JSON response indicating success
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": consts.SuccessfullyCheckedout,
+	})
+}
+
+// SubscriptionRenewal handles the process of checking out a subscription for a member.
+// This function performs the following steps:
+//  1. Extracts the memberID from the URL.
+//  2. Deserializes the JSON request body into a CheckoutSubscription object(same for renewal also).
+//  3. Performs any necessary validation on the plan renewal
+//  4. Calls the HandleSubscriptionRenewal use case function.
+//  5. Responds with appropriate JSON results based on success or failure.
+//
+// Parameters:
+//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
+func (member *MemberController) SubscriptionRenewal(ctx *gin.Context) {
+
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+
+	// Check if the endpoint exists in the context
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal failed , endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal failed,Failed to load context errors")
+		return
+	}
+	// Extract memberID from the URL
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+	// Retrieve PartnerID from the Gin context
+	partnerID, exists := ctx.Get("partner_id")
+
+	if !exists {
+		// Handle the case where PartnerID is not found in the context
+		logger.Log().WithContext(ctx).Error(" ")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+	// Convert the PartnerID to a string
+	partnerIDStr, ok := partnerID.(string)
+	if !ok || len(partnerIDStr) == 0 {
+		// Log the error
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+
+		// Return an error response
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to set PartnerID in context",
+			"errors":    nil,
+		})
+		return
+	}
+	// If parsing fails, log an error and return an appropriate JSON response
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal   failed, invalid member_id: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription renewal  failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// Deserialize the JSON request body into a CheckoutSubscription object
+	var checkoutData entities.SubscriptionRenewal
+	if err := ctx.BindJSON(&checkoutData); err != nil {
+		// Respond with an error message if JSON data is invalid
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal  failed, invalid JSON data: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+	// Call the HandleSubscriptionCheckout use case function
+	fieldsMap, err := member.useCases.HandleSubscriptionRenewal(ctx, memberID, checkoutData, partnerIDStr)
+
+	if err != nil {
+		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Renewal  failed, failed to renew subscription: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription checkout/purchase  failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Renewal  failed, failed to renew subscription")
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription renewal failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	// If successful, return a JSON response indicating success
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": consts.SuccessfullyRenewed,
+	})
+}
+
+// SubscriptionAutoRenewal opts a member subscription in or out of automatic renewal. Opted in
+// subscriptions are charged on the payment gateway ahead of their expiration date.
+func (member *MemberController) SubscriptionAutoRenewal(ctx *gin.Context) {
+	var request entities.AutoRenewal
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Subscription auto-renewal failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Subscription auto-renewal failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Subscription auto-renewal failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription auto-renewal failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	validationErrors, err := member.useCases.SetAutoRenewal(ctx, memberID, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Subscription auto-renewal failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Subscription auto-renewal failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyUpdatedAutoRenewal})
+}
+
+// ListRenewalAttempts lists the automatic renewal attempts of a member subscription and their outcome.
+func (member *MemberController) ListRenewalAttempts(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List renewal attempts failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List renewal attempts failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("List renewal attempts failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	attempts, validationErrors, err := member.useCases.GetRenewalAttempts(ctx, memberID, ctx.Param("member_subscription_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List renewal attempts failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List renewal attempts failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedRenewalAttempts, "data": attempts})
+}
+
+// SubscriptionCancellation handles the process of canceling a subscription for a member.
+// This function performs the following steps:
+//  1. Extracts the memberID from the URL.
+//  2. Deserializes the JSON request body into a CancelSubscription object.
+//  3. Performs any necessary validation on the plan cancellation.
+//  4. Calls the HandleSubscriptionCancellation use case function.
+//  5. Responds with appropriate JSON results based on success or failure.
+//
+// Parameters:
+//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
+func (member *MemberController) SubscriptionCancellation(ctx *gin.Context) {
+
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+
+	// Check if the endpoint exists in the context
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed,Failed to load context errors")
+		return
+	}
+
+	// Extract memberID from the URL
+	memberIDStr := ctx.Param("member_id")
+	memberID, err := uuid.Parse(memberIDStr)
+	// Retrieve PartnerID from the Gin context
+	partnerID, exists := ctx.Get("partner_id")
+
+	if !exists {
+		// Handle the case where PartnerID is not found in the context
+		logger.Log().WithContext(ctx).Error(" ")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+	// Convert the PartnerID to a string
+	partnerIDStr, ok := partnerID.(string)
+	if !ok || len(partnerIDStr) == 0 {
+		// Log the error
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+
+		// Return an error response
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to set PartnerID in context",
+			"errors":    nil,
+		})
+		return
+	}
+	// If parsing fails, log an error and return an appropriate JSON response
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, invalid member_id: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// Deserialize the JSON request body into a cancellationData object
+	var cancellationData entities.CancelSubscription
+	if err := ctx.BindJSON(&cancellationData); err != nil {
+		// Respond with an error message if JSON data is invalid
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, invalid JSON data: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+	// Call the HandleSubscriptionCancellation use case function
+	fieldsMap, err := member.useCases.HandleSubscriptionCancellation(ctx, memberID, cancellationData, partnerIDStr)
+
+	if err != nil {
+		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Cancellation failed, failed to cancel subscription: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed:Internal Server Error ")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Cancellation failed, failed to cancel subscription")
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasVal {
+			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed,Validations Erros")
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	// If successful, return a JSON response indicating success
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": consts.SuccessfullyCancelled,
+	})
+
+}
+
+// SubscriptionPlanChange handles the upgrade or downgrade of a member subscription to another plan.
+// The prorated adjustment recorded in the subscription ledger is returned.
+func (member *MemberController) SubscriptionPlanChange(ctx *gin.Context) {
+
+	// Retrieve and preprocess request details
+	method := strings.ToLower(ctx.Request.Method)
+	endpointUrl := ctx.FullPath()
+
+	// Check if the endpoint exists in the context
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+
+	// Check if the endpoint exists, if not, respond with a validation error.
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed, endpoint does not exist in the database.")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Get the contextError map to handle error responses.
+	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !errVal {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed, Failed to load context errors")
+		return
+	}
+
+	// Extract memberID from the URL
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed, invalid member_id: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	partnerIDStr := ctx.GetString(consts.ContextPartnerID)
+	if len(partnerIDStr) == 0 {
+		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"errorCode": http.StatusInternalServerError,
+			"message":   "Failed to get PartnerID from context",
+			"errors":    nil,
+		})
+		return
+	}
+
+	// Deserialize the JSON request body into a planChange object
+	var planChange entities.SubscriptionPlanChange
+	if err := ctx.BindJSON(&planChange); err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed, invalid JSON data: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	entry, fieldsMap, err := member.useCases.HandleSubscriptionPlanChange(ctx, memberID, planChange, partnerIDStr)
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed: %s", err.Error())
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if hasVal {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+	if len(fieldsMap) > 0 {
+		fields := utils.FieldMapping(fieldsMap)
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription plan change failed, validation errors")
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if hasVal {
+			ctx.JSON(int(errorCode), val)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": consts.SuccessfullyChangedPlan,
+		"data":    entry,
+	})
+}
+
+// ListInvoices lists the invoices issued to a member, newest first, using the page and limit query parameters.
+func (member *MemberController) ListInvoices(ctx *gin.Context) {
+	var reqParam entities.ReqParams
+	if err := ctx.BindQuery(&reqParam); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List invoices failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List invoices failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("List invoices failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	invoices, metadata, validationErrors, err := member.useCases.ListInvoices(ctx, memberID, reqParam)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List invoices failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List invoices failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entities.InvoiceListResponse{
+		Code:     constant.StatusOk,
+		Message:  consts.SuccessfullyListedInvoices,
+		Metadata: metadata,
+		Data:     invoices,
+	})
+}
+
+// ListMemberAudit lists the audit trail of a member: the changes made to the member, who made
+// them and in which request. The trail can be filtered on the action.
+func (member *MemberController) ListMemberAudit(ctx *gin.Context) {
+	var params entities.AuditParams
+	if err := ctx.BindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List member audit failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List member audit failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("List member audit failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	entries, metadata, validationErrors, err := member.useCases.ListMemberAudit(ctx, memberID, params)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List member audit failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List member audit failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entities.AuditListResponse{
+		Code:     constant.StatusOk,
+		Message:  consts.SuccessfullyListedAudit,
+		Metadata: metadata,
+		Data:     entries,
+	})
+}
+
+// ListSubscriptionPlans lists the latest version of every plan of the catalogue. With retired=true
+// it lists the retired plans instead.
+func (member *MemberController) ListSubscriptionPlans(ctx *gin.Context) {
+	var params entities.SubscriptionPlanParams
+	if err := ctx.BindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	_, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List subscription plans failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List subscription plans failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	plans, err := member.useCases.ListSubscriptionPlans(ctx, params)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List subscription plans failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedPlans, "data": plans})
+}
+
+// GetSubscriptionPlanVersions lists the versions of a plan of the catalogue, the latest first.
+func (member *MemberController) GetSubscriptionPlanVersions(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Get subscription plan versions failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Get subscription plan versions failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	versions, validationErrors, err := member.useCases.GetSubscriptionPlanVersions(ctx, ctx.Param("plan_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Get subscription plan versions failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Get subscription plan versions failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedPlans, "data": versions})
+}
+
+// CreateSubscriptionPlan adds a plan to the catalogue with its prices per currency, duration,
+// renewal terms and product, track and artist limits.
+func (member *MemberController) CreateSubscriptionPlan(ctx *gin.Context) {
+	var request entities.SubscriptionPlanRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Create subscription plan failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Create subscription plan failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Create subscription plan failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	plan, validationErrors, err := member.useCases.CreateSubscriptionPlan(ctx, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Create subscription plan failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Create subscription plan failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": consts.SuccessfullyCreatedPlan, "data": plan})
+}
+
+// AddSubscriptionPlanVersion publishes new terms of a plan as a new version. Member subscriptions
+// keep the version they bought.
+func (member *MemberController) AddSubscriptionPlanVersion(ctx *gin.Context) {
+	var request entities.SubscriptionPlanRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Add subscription plan version failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Add subscription plan version failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Add subscription plan version failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	plan, validationErrors, err := member.useCases.AddSubscriptionPlanVersion(ctx, ctx.Param("plan_id"), request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Add subscription plan version failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Add subscription plan version failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": consts.SuccessfullyVersionedPlan, "data": plan})
+}
+
+// RetireSubscriptionPlan withdraws a plan from the catalogue, it can no longer be bought.
+func (member *MemberController) RetireSubscriptionPlan(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Retire subscription plan failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Retire subscription plan failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	validationErrors, err := member.useCases.RetireSubscriptionPlan(ctx, ctx.Param("plan_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Retire subscription plan failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Retire subscription plan failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyRetiredPlan})
+}
+
+// ListPromoCodes lists the promo codes with their redemptions so far and the total discount they
+// were given.
+func (member *MemberController) ListPromoCodes(ctx *gin.Context) {
+	_, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List promo codes failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List promo codes failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	promoCodes, err := member.useCases.ListPromoCodes(ctx)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List promo codes failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyListedPromoCodes, "data": promoCodes})
+}
+
+// CreatePromoCode adds a percentage or fixed amount promo code, optionally scoped to partners and
+// plans, valid for a period and limited in redemptions.
+func (member *MemberController) CreatePromoCode(ctx *gin.Context) {
+	var request entities.PromoCodeRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Create promo code failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Create promo code failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Create promo code failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	promoCode, validationErrors, err := member.useCases.CreatePromoCode(ctx, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Create promo code failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Create promo code failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": consts.SuccessfullyCreatedPromoCode, "data": promoCode})
+}
+
+// ListPromoCodeRedemptions reports the redemptions of a promo code: the member, subscription and
+// discount of each checkout that redeemed it.
+func (member *MemberController) ListPromoCodeRedemptions(ctx *gin.Context) {
+	var params entities.PromoRedemptionParams
+	if err := ctx.BindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List promo code redemptions failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List promo code redemptions failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	redemptions, metadata, validationErrors, err := member.useCases.ListPromoCodeRedemptions(ctx, ctx.Param("promo_code_id"), params)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("List promo code redemptions failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List promo code redemptions failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entities.PromoRedemptionListResponse{
+		Code:     constant.StatusOk,
+		Message:  consts.SuccessfullyListedPromoRedemptions,
+		Metadata: metadata,
+		Data:     redemptions,
+	})
+}
+
+// GetSubscriptionQuota returns the limits, usage and pending reservations of a member subscription.
+func (member *MemberController) GetSubscriptionQuota(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Get subscription quota failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Get subscription quota failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Get subscription quota failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	subscriptionQuota, validationErrors, err := member.useCases.GetSubscriptionQuota(ctx, memberID, ctx.Param("member_subscription_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Get subscription quota failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Get subscription quota failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyFetchedQuota, "data": subscriptionQuota})
+}
+
+// CheckQuota answers whether a number of products, tracks or artists can be added under a member
+// subscription. Services creating them call ReserveQuota to hold the capacity.
+func (member *MemberController) CheckQuota(ctx *gin.Context) {
+	var request entities.QuotaRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Check quota failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Check quota failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Check quota failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Check quota failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	check, validationErrors, err := member.useCases.CheckQuota(ctx, memberID, ctx.Param("member_subscription_id"), request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Check quota failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Check quota failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyCheckedQuota, "data": check})
+}
+
+// ReserveQuota holds capacity of a member subscription for products, tracks or artists about to be
+// created. The reservation is committed once they are created and released when their creation is
+// rolled back.
+func (member *MemberController) ReserveQuota(ctx *gin.Context) {
+	var request entities.QuotaRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Reserve quota failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
+
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Reserve quota failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Reserve quota failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Reserve quota failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	reservation, validationErrors, err := member.useCases.ReserveQuota(ctx, memberID, ctx.Param("member_subscription_id"), request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Reserve quota failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Reserve quota failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": consts.SuccessfullyReservedQuota, "data": reservation})
+}
+
+// CommitQuotaReservation confirms the items of a quota reservation were created.
+func (member *MemberController) CommitQuotaReservation(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Commit quota reservation failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Commit quota reservation failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Commit quota reservation failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	validationErrors, err := member.useCases.CommitQuotaReservation(ctx, memberID, ctx.Param("member_subscription_id"), ctx.Param("reservation_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Commit quota reservation failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Commit quota reservation failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyCommittedQuota})
+}
+
+// ReleaseQuotaReservation gives the capacity of a quota reservation back.
+func (member *MemberController) ReleaseQuotaReservation(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Release quota reservation failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Release quota reservation failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Release quota reservation failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	validationErrors, err := member.useCases.ReleaseQuotaReservation(ctx, memberID, ctx.Param("member_subscription_id"), ctx.Param("reservation_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Release quota reservation failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Release quota reservation failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyReleasedQuota})
+}
+
+// usesCursorPaging reports whether a member listing request asks for cursor paging, that is
+// it carries a cursor, or a limit without a page. Requests without paging parameters keep
+// the offset pagination and its default first page.
+func usesCursorPaging(ctx *gin.Context) bool {
+	if _, hasCursor := ctx.GetQuery("cursor"); hasCursor {
+		return true
+	}
+	_, hasPage := ctx.GetQuery("page")
+	_, hasLimit := ctx.GetQuery("limit")
+	return hasLimit && !hasPage
+}
+
+// ListMembers lists members page by page in keyset order. Each page carries the opaque
+// cursor of the next one, so pages do not shift while members are added or removed.
+// Members can be filtered on active state, roles, countries, partner, creation time and a search term.
+func (member *MemberController) ListMembers(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("List members failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
+	}
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("List members failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
 	}
-	// Call the GetAllBillingAddresses use case with the extracted memberID
-	fieldsMap, billingAddresses, _, err := controller.useCases.GetAllBillingAddresses(ctx, memberID, params)
 
-	//Checks the length of fieldMap for checking is there any validation error reported or not.
-	if len(fieldsMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("Viewing All Billing Addresses Failed")
-		fields := utils.FieldMapping(fieldsMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		if hasError {
-			logger.Log().WithContext(ctx).Errorf("Viewing Billing Address")
+	// The limit is the only query parameter that is not a string
+	var params entities.MemberListParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		logger.Log().WithContext(ctx).Errorf("List members failed, invalid query: %s", err.Error())
+		validationErrors := map[string][]string{}
+		utils.AppendValuesToMap(validationErrors, consts.Limit, consts.Invalid)
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
+
+	members, metadata, validationErrors, err := member.useCases.ListMembers(ctx, params)
 	if err != nil {
-		// Check if the error message contains "No record found"
-		if strings.Contains(err.Error(), "No record found") {
-			// Handle the "No record found" error
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Failed to retrieve billing address, err=%s", err.Error())
-			val, hasError, errorCode := utils.ParseFields(ctx, consts.NotFoundErr, "", contextError, "", "")
-			if hasError {
-				ctx.JSON(int(errorCode), val)
-				return
-			}
-		} else {
-			// Handle other errors, e.g., internal server error
-			logger.Log().WithContext(ctx.Request.Context()).Errorf("View All Billing Address: Failed to retrieve billing address, err=%s", err.Error())
-			val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-			if hasError {
-				ctx.JSON(int(errorCode), val)
-				return
-			}
+		logger.Log().WithContext(ctx).Errorf("List members failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
 		}
+		return
 	}
-	if len(billingAddresses) == 0 {
-		ctx.JSON(http.StatusOK, gin.H{"message": "No Billing details to show",
-			"data": billingAddresses})
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("List members failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
 		return
 	}
-	// Send the response as JSON with a success message
 
-	ctx.JSON(http.StatusOK, entities.Response{
-		BillingAddress: billingAddresses,
-		Message:        consts.SuccessfullyListed,
+	ctx.JSON(http.StatusOK, entities.MemberListResponse{
+		Code:     constant.StatusOk,
+		Message:  consts.SuccessfullyListedMembers,
+		Metadata: metadata,
+		Data:     members,
 	})
-
-	// Log the success message
-	logger.Log().WithContext(ctx.Request.Context()).Info("View All Billing Address: Billing Address Listed successfully")
 }
 
-// RegisterMember is for registraion of member
-// This endpoint expects a JSON payload representing the member's registration data.
-// Request JSON Body:
-//
-//	-Member: The JSON object containing member details.
-//
-// Response:
-//   - If successful, it returns a JSON response with status code 201 (Created).
-//   - If there is an error in the request (e.g., invalid JSON) or processing, it returns an
-//     error JSON response with the appropriate status code and error message.
-func (member *MemberController) RegisterMember(ctx *gin.Context) {
-	ctxt := ctx.Request.Context()
-	methods := ctx.Request.Method
-	method := strings.ToLower(methods)
-	endpointUrl := ctx.FullPath()
-
+// GetInvoice returns an invoice issued to a member. The invoice is rendered as an HTML
+// document when the format query parameter is html, and returned as JSON otherwise.
+func (member *MemberController) GetInvoice(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists in the context
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx).Errorf("Member registration failed,Invalid endpoint")
+		logger.Log().WithContext(ctx).Errorf("Get invoice failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -810,107 +3669,146 @@ func (member *MemberController) RegisterMember(ctx *gin.Context) {
 		return
 	}
 
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Member registration failed:Failed to load Context errors")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Get invoice failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	var reqIn entities.Member
-	if err := ctx.BindJSON(&reqIn); err != nil {
-		logger.Log().WithContext(ctx).Errorf("Member registration failed, Invalid JSON data, err=%s", err.Error())
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Get invoice failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
 		return
 	}
-	// Retrieve PartnerID from the Gin context
-	partnerID, exists := ctx.Get("partner_id")
 
-	if !exists {
-		// Handle the case where PartnerID is not found in the context
-		logger.Log().WithContext(ctx).Error(" ")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to get PartnerID from context",
+	memberInvoice, validationErrors, err := member.useCases.GetInvoice(ctx, memberID, ctx.Param("invoice_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Get invoice failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Get invoice failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if ctx.Query(consts.InvoiceFormat) == consts.InvoiceFormatHTML {
+		var document bytes.Buffer
+		if err := invoice.RenderHTML(&document, memberInvoice); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Get invoice failed: %s", err.Error())
+			val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+			if errVal {
+				ctx.JSON(int(errorCode), val)
+			}
+			return
+		}
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", document.Bytes())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyRetrievedInvoice, "data": memberInvoice})
+}
+
+// ExportMember returns the data held about a member as a zip archive of JSON documents.
+func (member *MemberController) ExportMember(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Export member failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
 			"errors":    nil,
 		})
 		return
 	}
-	// Convert the PartnerID to a string
-	partnerIDStr, ok := partnerID.(string)
-	if !ok || len(partnerIDStr) == 0 {
-		// Log the error
-		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
 
-		// Return an error response
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to set PartnerID in context",
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Export member failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
 			"errors":    nil,
 		})
 		return
 	}
 
-	//Call the RegisterMember
-	fieldMap, err := member.useCases.RegisterMember(ctxt, reqIn, contextError, partnerIDStr, endpoint, method)
-
-	//Checks the length of fieldMap for checking is there any validation error reported or not.
-	if len(fieldMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("Member registration failed: validation error")
-		// to build the field format.
-		fields := utils.FieldMapping(fieldMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
-			fields, contextError, endpoint, method)
-		if hasError {
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Export member failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
+	memberExport, validationErrors, err := member.useCases.ExportMember(ctx, memberID)
 	if err != nil {
-		//For logging error message
-		logger.Log().WithContext(ctx).Errorf("Member registration failed: database error err=%s", err.Error())
-		//Retrieve error based on the api's requirement from the errors which are stored in context
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
-			"", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Export member failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	ctx.JSON(http.StatusCreated, consts.SuccessfullyRegistered)
-
-	// Log the success message
-	logger.Log().WithContext(ctx).Info("Member Registration: Member Registered successfully")
-}
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Export member failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-// UpdateMember is for registraion of member
-// This endpoint expects a JSON payload representing the member's data updation.
-// Request JSON Body:
-//
-//	-Member: The JSON object containing member details.
-//
-// Response:
-//   - If successful, it returns a JSON response with status code 201 (Created).
-//   - If there is an error in the request (e.g., invalid JSON) or processing, it returns an
-//     error JSON response with the appropriate status code and error message.
-func (member *MemberController) UpdateMember(ctx *gin.Context) {
-	var memberID uuid.UUID
+	var archive bytes.Buffer
+	if err := export.WriteArchive(&archive, memberExport); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Export member failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	methods := ctx.Request.Method
-	method := strings.ToLower(methods)
-	endpointUrl := ctx.FullPath()
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fmt.Sprintf(consts.MemberExportFileName, memberID)))
+	ctx.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
 
+// RequestMemberErasure queues the erasure of a member's personal data. The data is anonymized
+// by the erasure job, so the request is accepted rather than completed.
+func (member *MemberController) RequestMemberErasure(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists in the context
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx).Errorf("Member profile updation failed,Invalid endpoint")
+		logger.Log().WithContext(ctx).Errorf("Request member erasure failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -919,74 +3817,60 @@ func (member *MemberController) UpdateMember(ctx *gin.Context) {
 		return
 	}
 
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("Member profile updation  failed:Failed to load Context errors")
-		return
-	}
-
-	var args entities.Member
-	if err := ctx.BindJSON(&args); err != nil {
-		logger.Log().WithContext(ctx).Errorf("Member profile updation failed, Invalid JSON data, err=%s", err.Error())
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Request member erasure failed, err = %s", consts.ContextErr)
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
 		})
 		return
 	}
 
-	//Call the RegisterMember
-	fieldMap, err := member.useCases.UpdateMember(ctx, memberID, args)
-
-	//Checks the length of fieldMap for checking is there any validation error reported or not.
-	if len(fieldMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("Member profile updation failed: validation error")
-		fields := utils.FieldMapping(fieldMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		if hasError {
-			logger.Log().WithContext(ctx).Errorf("Member profile updation failed")
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Request member erasure failed: Invalid member_id: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
+	validationErrors, err := member.useCases.RequestMemberErasure(ctx, memberID)
 	if err != nil {
-		//For logging error message
-		logger.Log().WithContext(ctx).Errorf("Member profileupdation  failed: database error err=%s", err.Error())
-		//Retrieve error based on the api's requirement from the errors which are stored in context
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
-			"", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Request member erasure failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	ctx.JSON(http.StatusOK, consts.SuccessfullyUpdated)
-	logger.Log().WithContext(ctx).Info("Member Profile Updated: Profile successfully Updated ")
-}
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Request member erasure failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-// ViewMemberProfile retrieves a member's profile based on the provided member_id.
-// Parameters:
-//
-//	- ctx (gin.Context): The Gin context for handling the HTTP request.
-//	- memberID: The unique identifier of the member to retrieve.
-//
-// Returns:
-//  - memberProfile: The member's profile information.
-//	- error: An error, if any, during the database operation.
+	ctx.JSON(http.StatusAccepted, gin.H{"message": consts.SuccessfullyRequestedErasure})
+}
 
-func (member *MemberController) ViewMemberProfile(ctx *gin.Context) {
-	// Retrieve and preprocess request details
+// UnlockMember lifts the login lockout of a member, admins use it for members locked out by
+// failed password logins before the lockout expires.
+func (member *MemberController) UnlockMember(ctx *gin.Context) {
 	method := strings.ToLower(ctx.Request.Method)
-	endpointUrl := ctx.FullPath()
-	ctxt := ctx.Request.Context()
-
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists in the context
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx).Errorf("View Member Profile:Invalid endpoint")
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -995,78 +3879,61 @@ func (member *MemberController) ViewMemberProfile(ctx *gin.Context) {
 		return
 	}
 
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("View Member Profile failed:Failed to load Context errors")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Extract member_id from the URL parameters (UUID parsing)
-	fieldsMap := map[string][]string{}
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
-
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("View Member Profile failed-invalid member_id: err=%s", err.Error())
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
-		fields := utils.FieldMapping(fieldsMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
-			fields, contextError, endpoint, method)
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed: Invalid member_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.MemberID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	fieldMap, memberProfile, err := member.useCases.ViewMemberProfile(ctx, ctxt, memberID, contextError, endpoint, method)
-
-	//Checks the length of fieldMap for checking is there any validation error reported or not.
-	if len(fieldMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("View Member Profile failed:  validation error")
-		// to build the field format.
-		fields := utils.FieldMapping(fieldMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
-			fields, contextError, endpoint, method)
-		if hasError {
+	validationErrors, err := member.useCases.UnlockMember(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	//Checks any database error reported or not.
-	if err != nil {
-		//For logging error message
-		logger.Log().WithContext(ctx).Errorf("View Member Profile failed: err=%s", err.Error())
-		//Retrieve error based on the api's requirement from the errors which are stored in context
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
-			"", contextError, "", "")
-		if hasError {
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Member profile details retreived successfully", "data": memberProfile})
-	// Log the success message
-	logger.Log().WithContext(ctx).Info("View Member Profile: Member profile details retreived successfully")
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyUnlockedMember})
 }
 
-// ViewMembers retrieves a list of members based on the provided query parameters.
-//
-// Parameters:
-//   - ctx (gin.Context): The Gin context for handling the HTTP request.
-//
-// Returns:
-//   - data ([]entities.Member): A list of member data matching the query parameters.
-//   - error: An error, if any, during the database operation.
-
-func (member *MemberController) ViewMembers(ctx *gin.Context) {
-	// ctxt := ctx.Request.Context()
-	_, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+// EnrollTwoFactor starts the two-factor enrollment of a member and returns the secret along with
+// the otpauth URI to add it to an authenticator app.
+func (member *MemberController) EnrollTwoFactor(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists in the context
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx).Errorf("View Members failed:Invalid endpoint")
+		logger.Log().WithContext(ctx).Errorf("Enroll two-factor failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -1075,186 +3942,133 @@ func (member *MemberController) ViewMembers(ctx *gin.Context) {
 		return
 	}
 
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("View Members failed:Failed to load Context errors")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Enroll two-factor failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	params := entities.Params{}
-
-	// Check and set default values if any of the headers are empty
-	// Check if the header is empty AND there is no corresponding query parameter
-	if status, exists := ctx.GetQuery("status"); !exists || status == "" {
-		params.Status = consts.DefaultStatus
-	} else {
-		params.Status = status
-	}
-
-	if page, exists := ctx.GetQuery("page"); !exists || page == "" {
-		params.Page = consts.DefaultPage
-	}
-	if limit, exists := ctx.GetQuery("limit"); !exists || limit == "" {
-		params.Limit = consts.DefaultLimit
-	}
-	if country, exists := ctx.GetQuery("country"); !exists || country == "" {
-		params.Country = consts.DefaultCountry
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enroll two-factor failed: Invalid member_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.MemberID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
 	}
 
-	if gender, exists := ctx.GetQuery("gender"); !exists || gender == "" {
-		params.Gender = consts.DefaultGender
-	} else {
-		gender = strings.ToUpper(gender)
-		if gender != "M" && gender != "F" {
-			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
-				Status:  "failure",
-				Code:    http.StatusBadRequest,
-				Message: "Invalid gender parameter",
-				Errors:  nil,
-			})
-			return
+	validationErrors, enrollment, err := member.useCases.EnrollTwoFactor(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enroll two-factor failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
 		}
-		params.Gender = gender
+		return
 	}
 
-	if sortBy, exists := ctx.GetQuery("sort"); !exists || sortBy == "" {
-		params.SortBy = consts.DefaultSortBy
-		params.Order = consts.DefaultOrder
-	} else {
-		// Check if the sortby parameter is provided and set the value accordingly
-		switch sortBy {
-		case "firstname":
-			params.SortBy = "firstname"
-			params.Order = "ASC"
-		case "lastname":
-			params.SortBy = "lastname"
-			params.Order = "ASC"
-		case "created_on":
-			params.SortBy = "created_on"
-		case "email":
-			params.SortBy = "email"
-		default:
-			// If an invalid value is provided, set the default to "created_on"
-			params.SortBy = consts.DefaultSortBy
-			params.Order = consts.DefaultOrder
-			// If an invalid value is provided, return a Bad Request response
-			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
-				Status:  "failure",
-				Code:    http.StatusBadRequest,
-				Message: "Invalid sort parameter",
-				Errors:  nil,
-			})
-			return
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Enroll two-factor failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
 		}
+		return
 	}
 
-	if order, exists := ctx.GetQuery("order"); exists {
-		switch order {
-		case "asc":
-			params.Order = "ASC"
-		case "desc":
-			params.Order = "DESC"
-		default:
-			params.Order = "ASC"
-		}
-	} else {
-		// If "order" is not present, default to sorting by asc of firstname
-		params.Order = "ASC"
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyEnrolledTwoFactor, "data": enrollment})
+}
 
-	}
+// EnableTwoFactor enables the pending two-factor authentication of a member with the first code
+// of the authenticator app and returns the recovery codes.
+func (member *MemberController) EnableTwoFactor(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	if partner, exists := ctx.GetQuery("partner"); !exists || partner == "" {
-		params.Partner = consts.DefaultPartner
-	}
-	if role, exists := ctx.GetQuery("role"); !exists || role == "" {
-		params.Role = consts.DefaultRole
-	}
-	if search, exists := ctx.GetQuery("search"); !exists || search == "" {
-		params.Search = consts.DefaultSearch
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
+			"errors":    nil,
+		})
+		return
 	}
 
-	// Call ViewMembers to retrieve member data
-	membersData, metaData, err := member.useCases.ViewMembers(ctx, params)
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
+		return
+	}
 
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
 	if err != nil {
-		// Check if the error message matches the predefined constant error
-		if err.Error() == "invalid param value" {
-			ctx.JSON(http.StatusBadRequest, entities.FailureResponse{
-				Status:  "failure",
-				Code:    http.StatusBadRequest,
-				Message: "invalid parameter value",
-				Errors:  nil,
-			})
-			return
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed: Invalid member_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.MemberID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
 		}
+		return
 	}
-	if err != nil {
-		// Log the error message
-		logger.Log().WithContext(ctx).Errorf("View members failed: err=%s", err.Error())
 
-		// Check if the error message matches the predefined constant error
-		if err.Error() == "Exceeds maximum record limit" {
-			ctx.JSON(http.StatusTooManyRequests, entities.FailureResponse{
-				Status:  "failure",
-				Code:    http.StatusTooManyRequests,
-				Message: "Exceeds maximum record limit",
-				Errors:  nil,
-			})
-			return
-		}
+	var request entities.TwoFactorCode
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
+		})
+		return
+	}
 
-		// Retrieve error based on the API's requirement from the errors stored in context
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasError {
+	validationErrors, recoveryCodes, err := member.useCases.EnableTwoFactor(ctx, memberID, request.Code)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	if len(membersData) == 0 {
-		ctx.JSON(http.StatusOK, entities.MemberResponse{
-			Status:  "success",
-			Code:    200,
-			Message: "successfully retreived all member details",
-			DataResp: entities.DataResponse{
-				Metadata: metaData,
-				// Data:     membersData,
-			},
-		})
-	} else {
-		ctx.JSON(http.StatusOK, entities.MemberResponse{
-			Status:  "success",
-			Code:    200,
-			Message: "Successfully Listed All Member Details",
-			DataResp: entities.DataResponse{
-				Metadata: metaData,
-				Data:     membersData,
-			},
-		})
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
 	}
 
-	logger.Log().WithContext(ctx).Info("View members: Members details retreived successfully")
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyEnabledTwoFactor, "data": recoveryCodes})
 }
 
-// GetBasicMemberDetailsByEmail retrieves the basic details of a member based on their email.
-//
-// Parameters:
-//   - ctx (gin.Context): The Gin context for handling the HTTP request.
-//
-// Returns:
-//   - None: The function sends a JSON response with the member's basic details.
-func (member *MemberController) GetBasicMemberDetailsByEmail(ctx *gin.Context) {
-	// Retrieve and preprocess request details
+// DisableTwoFactor disables the two-factor authentication of a member. The body carries a code
+// of the authenticator app or a recovery code, admins may omit it for other members.
+func (member *MemberController) DisableTwoFactor(ctx *gin.Context) {
 	method := strings.ToLower(ctx.Request.Method)
-	endpointUrl := ctx.FullPath()
-	//ctxt := ctx.Request.Context()
-
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists in the context
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx).Errorf("View Basic Member Details:Invalid endpoint")
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -1263,112 +4077,134 @@ func (member *MemberController) GetBasicMemberDetailsByEmail(ctx *gin.Context) {
 		return
 	}
 
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Error("View Basic Member Details failed:Failed to load Context errors")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Retrieve PartnerID from the Gin context
-	partnerID, exists := ctx.Get("partner_id")
+	memberID, err := uuid.Parse(ctx.Param("member_id"))
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed: Invalid member_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.MemberID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
 
-	if !exists {
-		// Handle the case where PartnerID is not found in the context
-		logger.Log().WithContext(ctx).Error("Failed to get PartnerID from context")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to get PartnerID from context",
-			"errors":    nil,
+	var request entities.TwoFactorCode
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed, Invalid JSON data, err=%s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid JSON data",
 		})
 		return
 	}
-	// Convert the PartnerID to a string
-	partnerIDStr, ok := partnerID.(string)
 
-	if len(partnerIDStr) == 0 {
-		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to set PartnerID in context",
+	validationErrors, err := member.useCases.DisableTwoFactor(ctx, memberID, request.Code)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": consts.SuccessfullyDisabledTwoFactor})
+}
+
+// CompleteTwoFactorChallenge completes the login challenge returned by GetBasicMemberDetailsByEmail
+// and responds with the basic member details, like a login without two-factor authentication.
+func (member *MemberController) CompleteTwoFactorChallenge(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
 			"errors":    nil,
 		})
 		return
-
 	}
-	if !ok {
-		// Handle the case where PartnerID is not in the expected format (uuid)
-		logger.Log().WithContext(ctx).Error("PartnerID is not in the expected format (uuid)")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "PartnerID is not in the expected format (uuid)",
+
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
 			"errors":    nil,
 		})
 		return
 	}
 
-	var reqIn entities.MemberPayload
-	if err := ctx.BindJSON(&reqIn); err != nil {
-		logger.Log().WithContext(ctx).Errorf("View failed, Invalid JSON data, err=%s", err.Error())
+	partnerID := ctx.GetString(consts.ContextPartnerID)
+
+	var request entities.TwoFactorChallenge
+	if err := ctx.BindJSON(&request); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, Invalid JSON data, err=%s", err.Error())
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid JSON data",
 		})
 		return
 	}
 
-	fieldMap, basicMemberData, err := member.useCases.GetBasicMemberDetailsByEmail(ctx, partnerIDStr, reqIn, contextError, endpoint, method)
-
+	validationErrors, basicMemberData, err := member.useCases.CompleteTwoFactorChallenge(ctx, partnerID, request)
 	if err != nil {
-		// For logging error message
-		logger.Log().WithContext(ctx).Errorf("View Basic Member Details failed: err=%s", err.Error())
-		// Retrieve error based on the API's requirement from the errors stored in context
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.InternalServerErr,
-			"", contextError, "", "")
-		if hasError {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	if len(fieldMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("View Basic Member Details failed:  validation error")
-		// Build the field format.
-		fields := utils.FieldMapping(fieldMap)
-		val, hasError, errorCode := utils.ParseFields(ctx, consts.ValidationErr,
-			fields, contextError, endpoint, method)
-		if hasError {
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Member basic details retrieved successfully", "data": basicMemberData, "partnerId": partnerIDStr})
-	// Log the success message
-	logger.Log().WithContext(ctx).Info("View Basic Member Details: Member basic details retrieved successfully")
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member basic details retrieved successfully", "data": basicMemberData, "partnerId": basicMemberData.PartnerID})
 }
 
-// SubscriptionCheckout handles the process of checking out a subscription for a member.
-// This function performs the following steps:
-//  1. Extracts the memberID from the URL.
-//  2. Deserializes the JSON request body into a CheckoutSubscription object.
-//  3. Performs any necessary validation on the checkoutData.
-//  4. Calls the HandleSubscriptionCheckout use case function.
-//  5. Responds with appropriate JSON results based on success or failure.
-//
-// Parameters:
-//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
-func (member *MemberController) SubscriptionCheckout(ctx *gin.Context) {
-
-	// Retrieve and preprocess request details
+// ImportMembers queues a bulk import of members for the partner of the request. The members
+// are uploaded as CSV or NDJSON, either as the file field of a multipart form or as the request
+// body, and are validated and registered by the import job.
+func (member *MemberController) ImportMembers(ctx *gin.Context) {
 	method := strings.ToLower(ctx.Request.Method)
-	endpointUrl := ctx.FullPath()
-
-	// Check if the endpoint exists in the context
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists, if not, respond with a validation error.
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout failed , endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx).Errorf("Import members failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -1377,114 +4213,192 @@ func (member *MemberController) SubscriptionCheckout(ctx *gin.Context) {
 		return
 	}
 
-	// Get the contextError map to handle error responses.
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout failed,Failed to load context errors")
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Import members failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
+			"errors":    nil,
+		})
 		return
 	}
 
-	// Extract memberID from the URL
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
-	// Retrieve PartnerID from the Gin context
-	partnerID, exists := ctx.Get("partner_id")
+	upload, format, err := importUpload(ctx)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Import members failed, invalid upload: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.File: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+	defer upload.Close()
+
+	memberImport, validationErrors, err := member.useCases.ImportMembers(ctx, format, upload)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Import members failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Import members failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
+			ctx.JSON(int(errorCode), val)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, entities.MemberImportResponse{
+		Code:    http.StatusAccepted,
+		Message: consts.SuccessfullyQueuedImport,
+		Data:    memberImport,
+	})
+}
+
+// importUpload returns the upload of a bulk import and its format. The format query parameter
+// takes precedence over the format derived from the file name and the content type.
+func importUpload(ctx *gin.Context) (io.ReadCloser, string, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, consts.MaxImportSize)
+
+	format := strings.ToLower(ctx.Query("format"))
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		header, err := ctx.FormFile(consts.File)
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		if format == "" {
+			format = importFormat(path.Ext(header.Filename), header.Header.Get("Content-Type"))
+		}
+		return file, format, nil
+	}
+
+	if format == "" {
+		format = importFormat("", ctx.ContentType())
+	}
+	return ctx.Request.Body, format, nil
+}
+
+// importFormat returns the import format of a file extension or content type, or an
+// empty string when neither is known.
+func importFormat(extension, contentType string) string {
+	switch strings.ToLower(extension) {
+	case ".csv":
+		return consts.ImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return consts.ImportFormatNDJSON
+	}
+	switch strings.ToLower(contentType) {
+	case "text/csv":
+		return consts.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return consts.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// preconditionFailed responds 412 Precondition Failed to updates and deletions whose If-Match
+// header does not match the current version, with the localized error of the version field.
+func (member *MemberController) preconditionFailed(ctx *gin.Context, contextError map[string]any, endpoint, method string) {
+	fieldsMap := map[string][]string{}
+	utils.AppendValuesToMap(fieldsMap, consts.Version, consts.Stale)
+	val, _, _ := utils.ParseFields(ctx, consts.ValidationErr, utils.FieldMapping(fieldsMap), contextError, endpoint, method)
+	ctx.JSON(http.StatusPreconditionFailed, val)
+}
 
-	if !exists {
-		// Handle the case where PartnerID is not found in the context
-		logger.Log().WithContext(ctx).Error(" ")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to get PartnerID from context",
+// GetMemberImport returns the progress of a bulk import with the validation errors of
+// each row that was not imported.
+func (member *MemberController) GetMemberImport(ctx *gin.Context) {
+	method := strings.ToLower(ctx.Request.Method)
+	endpointURL := ctx.FullPath()
+	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
+
+	if !isEndpointExists {
+		logger.Log().WithContext(ctx).Errorf("Get member import failed: invalid endpoint")
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.EndpointErr,
 			"errors":    nil,
 		})
 		return
 	}
-	// Convert the PartnerID to a string
-	partnerIDStr, ok := partnerID.(string)
-	if !ok || len(partnerIDStr) == 0 {
-		// Log the error
-		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
 
-		// Return an error response
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to set PartnerID in context",
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Get member import failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
 			"errors":    nil,
 		})
 		return
 	}
-	// If parsing fails, log an error and return an appropriate JSON response
+
+	importID, err := uuid.Parse(ctx.Param("import_id"))
 	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout  failed, invalid member_id: %s", err.Error())
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription checkout/purchase  failed")
+		logger.Log().WithContext(ctx).Errorf("Get member import failed: Invalid import_id: %s", err.Error())
+		fields := utils.FieldMapping(map[string][]string{consts.ImportID: {consts.Invalid}})
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
-	}
-	// Deserialize the JSON request body into a CheckoutSubscription object
-	var checkoutData entities.CheckoutSubscription
-	if err := ctx.BindJSON(&checkoutData); err != nil {
-		// Respond with an error message if JSON data is invalid
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription checkout  failed, invalid JSON data: %s", err.Error())
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
 		return
 	}
-	// Call the HandleSubscriptionCheckout use case function
-	fieldsMap, err := member.useCases.HandleSubscriptionCheckout(ctx, memberID, checkoutData, partnerIDStr)
 
+	memberImport, validationErrors, err := member.useCases.GetMemberImport(ctx, importID)
 	if err != nil {
-		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Checkout  failed, failed to checkout subscription: %s", err.Error())
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasVal {
+		logger.Log().WithContext(ctx).Errorf("Get member import failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
 		return
 	}
-	if len(fieldsMap) > 0 {
-		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Checkout  failed, failed to checkout subscription")
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		if hasVal {
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Get member import failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
-	// If successful, return a JSON response indicating success
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": consts.SuccessfullyCheckedout,
+
+	ctx.JSON(http.StatusOK, entities.MemberImportResponse{
+		Code:    constant.StatusOk,
+		Message: consts.SuccessfullyFetchedImport,
+		Data:    memberImport,
 	})
 }
 
-// SubscriptionRenewal handles the process of checking out a subscription for a member.
-// This function performs the following steps:
-//  1. Extracts the memberID from the URL.
-//  2. Deserializes the JSON request body into a CheckoutSubscription object(same for renewal also).
-//  3. Performs any necessary validation on the plan renewal
-//  4. Calls the HandleSubscriptionRenewal use case function.
-//  5. Responds with appropriate JSON results based on success or failure.
-//
-// Parameters:
-//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
-func (member *MemberController) SubscriptionRenewal(ctx *gin.Context) {
+// ExportMembers streams the members matching the listing filters as a CSV attachment.
+func (member *MemberController) ExportMembers(ctx *gin.Context) {
+	var params entities.MemberListParams
+	if err := ctx.BindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, err)
+		return
+	}
 
-	// Retrieve and preprocess request details
 	method := strings.ToLower(ctx.Request.Method)
-	endpointUrl := ctx.FullPath()
-
-	// Check if the endpoint exists in the context
+	endpointURL := ctx.FullPath()
 	contextEndpoints, isEndpointExists := utils.GetContext[models.ResponseData](ctx, consts.ContextEndPoints)
-	endpoint := utils.GetEndPoints(contextEndpoints, endpointUrl, method)
+	endpoint := utils.GetEndPoints(contextEndpoints, endpointURL, method)
 
-	// Check if the endpoint exists, if not, respond with a validation error.
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal failed , endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx).Errorf("Export members failed: invalid endpoint")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -1493,103 +4407,72 @@ func (member *MemberController) SubscriptionRenewal(ctx *gin.Context) {
 		return
 	}
 
-	// Get the contextError map to handle error responses.
-	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
-	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal failed,Failed to load context errors")
-		return
-	}
-	// Extract memberID from the URL
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
-	// Retrieve PartnerID from the Gin context
-	partnerID, exists := ctx.Get("partner_id")
-
-	if !exists {
-		// Handle the case where PartnerID is not found in the context
-		logger.Log().WithContext(ctx).Error(" ")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to get PartnerID from context",
+	contextError, isErrorExists := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if !isErrorExists {
+		logger.Log().WithContext(ctx).Errorf("Export members failed, err = %s", consts.ContextErr)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"errorCode": http.StatusBadRequest,
+			"message":   consts.ContextErr,
 			"errors":    nil,
 		})
 		return
 	}
-	// Convert the PartnerID to a string
-	partnerIDStr, ok := partnerID.(string)
-	if !ok || len(partnerIDStr) == 0 {
-		// Log the error
-		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
 
-		// Return an error response
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to set PartnerID in context",
-			"errors":    nil,
-		})
-		return
-	}
-	// If parsing fails, log an error and return an appropriate JSON response
-	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal   failed, invalid member_id: %s", err.Error())
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription renewal  failed")
-			ctx.JSON(int(errorCode), val)
-			return
+	response := &csvResponse{ctx: ctx, fileName: consts.MemberListExportFileName}
+	validationErrors, err := member.useCases.ExportMembers(ctx, params, response)
+	if response.started {
+		// Part of the export was sent, an error can only cut it short.
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Export members interrupted: %s", err.Error())
+			ctx.Abort()
 		}
-	}
-	// Deserialize the JSON request body into a CheckoutSubscription object
-	var checkoutData entities.SubscriptionRenewal
-	if err := ctx.BindJSON(&checkoutData); err != nil {
-		// Respond with an error message if JSON data is invalid
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription renewal  failed, invalid JSON data: %s", err.Error())
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
 		return
 	}
-	// Call the HandleSubscriptionCheckout use case function
-	fieldsMap, err := member.useCases.HandleSubscriptionRenewal(ctx, memberID, checkoutData, partnerIDStr)
 
 	if err != nil {
-		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Renewal  failed, failed to renew subscription: %s", err.Error())
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
-		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription checkout/purchase  failed")
+		logger.Log().WithContext(ctx).Errorf("Export members failed: %s", err.Error())
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
-	if len(fieldsMap) > 0 {
-		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Renewal  failed, failed to renew subscription")
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
-		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription renewal failed")
+
+	if len(validationErrors) != 0 {
+		logger.Log().WithContext(ctx).Errorf("Export members failed: validation error")
+		fields := utils.FieldMapping(validationErrors)
+		val, errVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
+		if errVal {
 			ctx.JSON(int(errorCode), val)
-			return
 		}
+		return
 	}
+}
 
-	// If successful, return a JSON response indicating success
-	ctx.JSON(http.StatusCreated, gin.H{
-		"message": consts.SuccessfullyRenewed,
-	})
+// csvResponse streams a CSV attachment. The response headers are sent with the first write,
+// so errors found before anything is written can still be answered as JSON.
+type csvResponse struct {
+	ctx      *gin.Context
+	fileName string
+	started  bool
 }
 
-// SubscriptionCancellation handles the process of canceling a subscription for a member.
-// This function performs the following steps:
-//  1. Extracts the memberID from the URL.
-//  2. Deserializes the JSON request body into a CancelSubscription object.
-//  3. Performs any necessary validation on the plan cancellation.
-//  4. Calls the HandleSubscriptionCancellation use case function.
-//  5. Responds with appropriate JSON results based on success or failure.
-//
-// Parameters:
-//   - ctx (*gin.Context): The Gin context for handling the HTTP request.
-func (member *MemberController) SubscriptionCancellation(ctx *gin.Context) {
+func (r *csvResponse) Write(p []byte) (int, error) {
+	if !r.started {
+		r.ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		r.ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, r.fileName))
+		r.ctx.Status(http.StatusOK)
+		r.started = true
+	}
+	n, err := r.ctx.Writer.Write(p)
+	r.ctx.Writer.Flush()
+	return n, err
+}
+
+// PaymentWebhook handles the payment events sent by a payment gateway.
+// The partner is taken from the partner_id header and the payload must be signed with the
+// partner's gateway secret in the X-Payment-Signature header.
+func (member *MemberController) PaymentWebhook(ctx *gin.Context) {
 
 	// Retrieve and preprocess request details
 	method := strings.ToLower(ctx.Request.Method)
@@ -1601,7 +4484,7 @@ func (member *MemberController) SubscriptionCancellation(ctx *gin.Context) {
 
 	// Check if the endpoint exists, if not, respond with a validation error.
 	if !isEndpointExists {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, endpoint does not exist in the database.")
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed, endpoint does not exist in the database.")
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"errorCode": http.StatusBadRequest,
 			"message":   consts.EndpointErr,
@@ -1613,88 +4496,53 @@ func (member *MemberController) SubscriptionCancellation(ctx *gin.Context) {
 	// Get the contextError map to handle error responses.
 	contextError, errVal := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
 	if !errVal {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed,Failed to load context errors")
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed, Failed to load context errors")
 		return
 	}
 
-	// Extract memberID from the URL
-	memberIDStr := ctx.Param("member_id")
-	memberID, err := uuid.Parse(memberIDStr)
-	// Retrieve PartnerID from the Gin context
-	partnerID, exists := ctx.Get("partner_id")
+	partnerID := ctx.GetString(consts.ContextPartnerID)
 
-	if !exists {
-		// Handle the case where PartnerID is not found in the context
-		logger.Log().WithContext(ctx).Error(" ")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to get PartnerID from context",
-			"errors":    nil,
+	// The signature is computed over the raw body.
+	payload, err := ctx.GetRawData()
+	if err != nil {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed, reading payload: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid payload",
 		})
 		return
 	}
-	// Convert the PartnerID to a string
-	partnerIDStr, ok := partnerID.(string)
-	if !ok || len(partnerIDStr) == 0 {
-		// Log the error
-		logger.Log().WithContext(ctx).Error("Failed to Extract PartnerID from Context")
 
-		// Return an error response
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"errorCode": http.StatusInternalServerError,
-			"message":   "Failed to set PartnerID in context",
-			"errors":    nil,
-		})
-		return
-	}
-	// If parsing fails, log an error and return an appropriate JSON response
-	if err != nil {
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, invalid member_id: %s", err.Error())
-		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
+	fieldsMap, err := member.useCases.HandlePaymentWebhook(ctx, ctx.Param("gateway"), partnerID, payload,
+		ctx.GetHeader(consts.PaymentSignatureHeader))
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed, invalid signature")
+		val, hasVal, errorCode := utils.ParseFields(ctx, consts.UnauthorisedErr, "", contextError, "", "")
 		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed")
 			ctx.JSON(int(errorCode), val)
 			return
 		}
 	}
-	// Deserialize the JSON request body into a cancellationData object
-	var cancellationData entities.CancelSubscription
-	if err := ctx.BindJSON(&cancellationData); err != nil {
-		// Respond with an error message if JSON data is invalid
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Subscription cancellation failed, invalid JSON data: %s", err.Error())
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid JSON data",
-		})
-		return
-	}
-	// Call the HandleSubscriptionCancellation use case function
-	fieldsMap, err := member.useCases.HandleSubscriptionCancellation(ctx, memberID, cancellationData, partnerIDStr)
-
 	if err != nil {
-		// If an error occurs during the use case execution, log the error and return a 500 Internal Server Error response
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Cancellation failed, failed to cancel subscription: %s", err.Error())
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed: %s", err.Error())
 		val, hasVal, errorCode := utils.ParseFields(ctx, consts.InternalServerErr, "", contextError, "", "")
 		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed:Internal Server Error ")
 			ctx.JSON(int(errorCode), val)
 			return
 		}
 	}
 	if len(fieldsMap) > 0 {
 		fields := utils.FieldMapping(fieldsMap)
-		logger.Log().WithContext(ctx.Request.Context()).Errorf("Cancellation failed, failed to cancel subscription")
+		logger.Log().WithContext(ctx.Request.Context()).Errorf("Payment webhook failed, validation errors")
 		val, hasVal, errorCode := utils.ParseFields(ctx, consts.ValidationErr, fields, contextError, endpoint, method)
 		if hasVal {
-			logger.Log().WithContext(ctx.Request.Context()).Error("Subscription cancellation failed,Validations Erros")
 			ctx.JSON(int(errorCode), val)
 			return
 		}
 	}
-	// If successful, return a JSON response indicating success
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": consts.SuccessfullyCancelled,
+		"message": consts.SuccessfullyProcessedWebhook,
 	})
-
 }
 
 // SubscriptionProductSwitch handles the product switch between subscription plans choosed by a member.
@@ -1967,6 +4815,14 @@ func (member *MemberController) DeleteBillingAddress(ctx *gin.Context) {
 		return
 	}
 
+	// Deletions made against an outdated version of the billing address are refused
+	expectedVersion, isValidVersion := etag.Parse(ctx.GetHeader(consts.IfMatch))
+	if !isValidVersion {
+		logger.Log().WithContext(ctx).Errorf("Delete BillingAddress failed: If-Match does not match")
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
+
 	// Extract member_id from the URL parameters (UUID parsing)
 	memberIDStr := ctx.Param("member_id")
 	memberID, err := uuid.Parse(memberIDStr)
@@ -1984,7 +4840,11 @@ func (member *MemberController) DeleteBillingAddress(ctx *gin.Context) {
 	}
 
 	// Call the use case to update the billing address
-	fieldsMap, err := member.useCases.DeleteBillingAddress(ctx, memberID, memberBillingID)
+	fieldsMap, err := member.useCases.DeleteBillingAddress(ctx, memberID, memberBillingID, expectedVersion)
+	if errors.Is(err, consts.ErrVersionMismatch) {
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
 
 	if len(fieldsMap) > 0 {
 		fields := utils.FieldMapping(fieldsMap)
@@ -2033,9 +4893,21 @@ func (member *MemberController) DeleteMember(ctx *gin.Context) {
 		return
 	}
 
+	// Deletions made against an outdated version of the member are refused
+	expectedVersion, isValidVersion := etag.Parse(ctx.GetHeader(consts.IfMatch))
+	if !isValidVersion {
+		logger.Log().WithContext(ctx).Errorf("Member Deletion failed: If-Match does not match")
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
+
 	memberID := ctx.Param("member_id")
 	//Call the RegisterMember
-	fieldMap, err := member.useCases.DeleteMember(ctx, memberID)
+	fieldMap, err := member.useCases.DeleteMember(ctx, memberID, expectedVersion)
+	if errors.Is(err, consts.ErrVersionMismatch) {
+		member.preconditionFailed(ctx, contextError, endpoint, method)
+		return
+	}
 	if err != nil {
 		//For logging error message
 		logger.Log().WithContext(ctx).Errorf("Member registration failed: database error err=%s", err.Error())
@@ -0,0 +1,34 @@
+// Package cursor encodes the position of keyset-paginated listings into opaque
+// tokens handed out to clients.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalid is returned when a token was not produced by Encode.
+var ErrInvalid = errors.New("invalid cursor")
+
+// Encode returns the opaque token of the position v.
+func Encode(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode reads the position of the token into v. It returns ErrInvalid when the
+// token is malformed.
+func Decode(token string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalid
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
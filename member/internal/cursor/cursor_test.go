@@ -0,0 +1,30 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type position struct {
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// TestEncodeDecode checks positions survive the round trip and malformed tokens are rejected.
+func TestEncodeDecode(t *testing.T) {
+	want := position{Value: "2024-03-01T10:00:00.123456Z", ID: uuid.New()}
+
+	token, err := Encode(want)
+	require.NoError(t, err)
+	assert.NotContains(t, token, want.Value)
+
+	var got position
+	require.NoError(t, Decode(token, &got))
+	assert.Equal(t, want, got)
+
+	assert.ErrorIs(t, Decode("not a cursor!", &got), ErrInvalid)
+	assert.ErrorIs(t, Decode("bm90IGpzb24", &got), ErrInvalid)
+}
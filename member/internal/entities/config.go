@@ -1,18 +1,36 @@
 package entities
 
+import "time"
+
 // EnvConfig represents the configuration structure for the application.
 type EnvConfig struct {
-	Debug                  bool     `default:"true" split_words:"true"`  // Flag indicating debug mode (default: true)
-	Port                   int      `default:"8039" split_words:"true"`  // Port for server to listen on (default: 8080)
-	Db                     Database `split_words:"true"`                 // Database configuration
-	AcceptedVersions       []string `required:"true" split_words:"true"` // List of accepted API versions (required)
-	MigrationPath          string   `split_words:"true"`                 // Path to migration files
-	LocalisationServiceURL string   `split_words:"true"`                 // URL of the localization service
-	EndpointURL            string   `split_words:"true"`                 // URL of the endpoint service
-	LoggerServiceURL       string   `envconfig:"LOGGER_SERVICE_URL"`
-	LoggerSecret           string   `envconfig:"LOGGER_SECRET"`
-	JwtKey                 string   `split_words:"true"`
-	DecryptionKey          string   `split_words:"true"`
+	Debug                  bool                    `default:"true" split_words:"true"`  // Flag indicating debug mode (default: true)
+	Port                   int                     `default:"8039" split_words:"true"`  // Port for server to listen on (default: 8080)
+	Db                     Database                `split_words:"true"`                 // Database configuration
+	AcceptedVersions       []string                `required:"true" split_words:"true"` // List of accepted API versions (required)
+	MigrationPath          string                  `split_words:"true"`                 // Path to migration files
+	LocalisationServiceURL string                  `split_words:"true"`                 // URL of the localization service
+	EndpointURL            string                  `split_words:"true"`                 // URL of the endpoint service
+	LoggerServiceURL       string                  `envconfig:"LOGGER_SERVICE_URL"`
+	LoggerSecret           string                  `envconfig:"LOGGER_SECRET"`
+	JwtKey                 string                  `split_words:"true"`
+	DecryptionKey          string                  `split_words:"true"`
+	Smtp                   SMTPConfig              `split_words:"true"`                                 // SMTP server used to notify members
+	NotificationFile       string                  `default:"log/notifications.log" split_words:"true"` // File receiving notifications when no SMTP server is configured
+	PasswordReset          PasswordResetConfig     `split_words:"true"`                                 // Password reset key settings
+	EmailVerification      EmailVerificationConfig `split_words:"true"`                                 // Email verification token settings
+	Login                  LoginConfig             `split_words:"true"`                                 // Password login throttling settings
+	PasswordHash           PasswordHashConfig      `split_words:"true"`                                 // Password hashing algorithm and parameters
+	PasswordPolicy         PasswordPolicyConfig    `split_words:"true"`                                 // Rules new passwords have to satisfy
+	AddressRulesFile       string                  `split_words:"true"`                                 // File of per-country billing address rules, the built-in rules are used when empty
+	TwoFactor              TwoFactorConfig         `split_words:"true"`                                 // Two-factor authentication settings
+	Scheduler              SchedulerConfig         `split_words:"true"`                                 // Background job settings
+	Payment                PaymentConfig           `split_words:"true"`                                 // Payment gateway settings
+	Outbox                 OutboxConfig            `split_words:"true"`                                 // Domain event relay settings
+	ActivityLogURL         string                  `split_words:"true"`                                 // URL of the activity log service
+	// Addresses or CIDRs of the proxies and services, like the oauth service, whose X-Forwarded-For
+	// header carries the end user's IP. Client IPs are taken from the connection when empty.
+	TrustedProxies []string `split_words:"true"`
 }
 
 // Database represents the configuration for the database connection.
@@ -26,3 +44,104 @@ type Database struct {
 	MaxActive int    // Maximum number of active connections
 	MaxIdle   int    // Maximum number of idle connections
 }
+
+// EmailVerificationConfig represents the settings of the email verification tokens.
+type EmailVerificationConfig struct {
+	Secret string        // Key signing the tokens, the JWT key is used when empty
+	TTL    time.Duration `default:"48h"` // Validity of a verification token
+}
+
+// PasswordHashConfig represents the algorithm and parameters new password hashes are produced
+// with. Stored hashes of other algorithms or parameters are upgraded on the next login.
+type PasswordHashConfig struct {
+	Algorithm         string `default:"argon2id"`                 // argon2id or bcrypt
+	Argon2Memory      uint32 `default:"65536" split_words:"true"` // Memory of argon2id in KiB
+	Argon2Iterations  uint32 `default:"3" split_words:"true"`     // Passes of argon2id over the memory
+	Argon2Parallelism uint8  `default:"2" split_words:"true"`     // Threads of argon2id
+	BcryptCost        int    `default:"12" split_words:"true"`    // Cost of bcrypt
+}
+
+// PasswordPolicyConfig represents the rules new passwords have to satisfy.
+type PasswordPolicyConfig struct {
+	MinLength             int    `default:"8" split_words:"true"`     // Minimum number of characters
+	MaxLength             int    `default:"72" split_words:"true"`    // Maximum number of bytes, bcrypt ignores longer passwords
+	RequireUppercase      bool   `default:"true" split_words:"true"`  // At least one uppercase letter
+	RequireLowercase      bool   `default:"true" split_words:"true"`  // At least one lowercase letter
+	RequireDigit          bool   `default:"false" split_words:"true"` // At least one digit
+	RequireSpecial        bool   `default:"true" split_words:"true"`  // At least one special character
+	BreachedPasswordsFile string `split_words:"true"`                 // File of known breached passwords, one per line
+}
+
+// TwoFactorConfig represents the settings of the TOTP two-factor authentication.
+type TwoFactorConfig struct {
+	Issuer       string        `default:"Tuneverse" split_words:"true"` // Service name shown in the authenticator apps
+	Skew         int           `default:"1" split_words:"true"`         // Periods before and after the current one whose codes are accepted
+	ChallengeTTL time.Duration `default:"5m" split_words:"true"`        // Time a member has to complete the login challenge
+	Secret       string        `split_words:"true"`                     // Key signing the challenge tokens, derived from the JWT key when empty
+}
+
+// LoginConfig represents the throttling of failed password logins.
+type LoginConfig struct {
+	MaxMemberAttempts int           `default:"5" split_words:"true"`   // Failed logins of a member that lock the member out
+	MaxIPAttempts     int           `default:"50" split_words:"true"`  // Failed logins from a client IP that lock the IP out
+	AttemptWindow     time.Duration `default:"15m" split_words:"true"` // Window in which failed logins are counted
+	BaseDelay         time.Duration `default:"1s" split_words:"true"`  // Wait after the first failed login, doubled with every further failure
+	MaxDelay          time.Duration `default:"30s" split_words:"true"` // Longest wait between failed logins
+	LockoutDuration   time.Duration `default:"15m" split_words:"true"` // Time a lockout lasts unless an admin unlocks it
+}
+
+// PasswordResetConfig represents the settings of the password reset keys.
+type PasswordResetConfig struct {
+	TTL               time.Duration `default:"50m"`                    // Validity of a reset key
+	MaxMemberAttempts int           `default:"5" split_words:"true"`   // Failed key checks allowed per member within the lockout window
+	MaxIPAttempts     int           `default:"20" split_words:"true"`  // Failed key checks allowed per client IP within the lockout window
+	LockoutWindow     time.Duration `default:"15m" split_words:"true"` // Window in which failed key checks are counted
+}
+
+// SchedulerConfig represents the settings of the background jobs.
+type SchedulerConfig struct {
+	Enabled            bool          `default:"true"`                    // Run the background jobs in this replica
+	LifecycleInterval  time.Duration `default:"5m" split_words:"true"`   // Interval of the subscription lifecycle sweep
+	WarningPeriod      time.Duration `default:"168h" split_words:"true"` // Time before expiration in which a subscription is in warning
+	LifecycleBatchSize int           `default:"500" split_words:"true"`  // Maximum subscriptions moved per state and sweep
+	ErasureInterval    time.Duration `default:"1h" split_words:"true"`   // Interval of the member erasure job
+	ErasureBatchSize   int           `default:"50" split_words:"true"`   // Maximum members erased per run
+	ImportInterval     time.Duration `default:"1m" split_words:"true"`   // Interval of the bulk member import job
+	ImportBatchSize    int           `default:"5" split_words:"true"`    // Maximum imports processed per run
+	ImportLease        time.Duration `default:"30m" split_words:"true"`  // Time after which an interrupted import is claimed again
+	QuotaInterval      time.Duration `default:"5m" split_words:"true"`   // Interval of the quota reservation expiry job
+	RenewalInterval    time.Duration `default:"15m" split_words:"true"`  // Interval of the subscription auto-renewal job
+	RenewalLead        time.Duration `default:"72h" split_words:"true"`  // Time before expiration at which auto-renewals are charged
+	RenewalBatchSize   int           `default:"50" split_words:"true"`   // Maximum subscriptions renewed per run
+	RenewalLease       time.Duration `default:"30m" split_words:"true"`  // Time after which an interrupted renewal is attempted again
+	// Delays after expiration at which failed renewals are retried within the grace period
+	RenewalDunning []time.Duration `default:"24h,72h,168h" split_words:"true"`
+}
+
+// PaymentConfig represents the payment gateway settings.
+type PaymentConfig struct {
+	FakeGateway bool `default:"false" split_words:"true"` // Charge every partner gateway on the deterministic fake gateway
+}
+
+// OutboxConfig represents the settings of the relay publishing the outbox events to the queue.
+type OutboxConfig struct {
+	Enabled   bool          `default:"false"`                            // Publish the outbox events from this replica
+	Provider  string        `default:"rabbitmq"`                         // Queue provider, rabbitmq or sqs
+	QueueName string        `default:"member-events" split_words:"true"` // Name of the queue receiving the events
+	URL       string        // RabbitMQ connection URL
+	Region    string        // AWS region of the SQS queue
+	Interval  time.Duration `default:"10s"`                    // Interval between two relay runs
+	BatchSize int           `default:"100" split_words:"true"` // Events claimed at once
+	Lease     time.Duration `default:"1m"`                     // Time other replicas leave a claimed event alone
+	RetryBase time.Duration `default:"5s" split_words:"true"`  // Delay before the first retry of a failed event
+	RetryMax  time.Duration `default:"1h" split_words:"true"`  // Maximum delay between two retries
+}
+
+// SMTPConfig represents the configuration of the SMTP server used for member notifications.
+type SMTPConfig struct {
+	Host     string // SMTP host, notifications are written to a file when empty
+	Port     int    `default:"587"` // SMTP port
+	Username string // SMTP username
+	Password string // SMTP password
+	From     string // Sender address of the notifications
+}
@@ -29,15 +29,18 @@ type Member struct {
 	PayingTax             bool   `json:"paying_tax"`
 	Password              string `json:"password,omitempty"`
 	Provider              string `json:"provider,omitempty"`
+	PasswordHash          string `json:"-"` // Hash of the password, set instead of Password by bulk imports
 }
 
 // BillingAddress struct to hold details
 type BillingAddress struct {
+	ID      string `json:"id,omitempty"`
 	Address string `json:"address" `
 	Zipcode string `json:"zipcode" `
 	Country string `json:"country"  `
 	State   string `json:"state" `
 	Primary bool   `json:"primary"`
+	Version int64  `json:"version,omitempty"` // Sent back in If-Match to update or delete the address
 }
 
 // BillingAddressResponse response struct to hold details
@@ -52,6 +55,187 @@ type PasswordChangeRequest struct {
 	NewPassword     string `json:"new_password"`
 }
 
+// SubscriptionTransition represents a member subscription moved from one status to another
+// by the subscription lifecycle job.
+type SubscriptionTransition struct {
+	MemberSubscriptionID uuid.UUID
+	MemberID             uuid.UUID
+	CustomName           string
+	FromStatus           string
+	ToStatus             string
+	ExpirationDate       time.Time
+	GraceEnd             time.Time
+}
+
+// OutboxEvent is a domain event written to the outbox in the transaction of the change it describes.
+// It is published as is by the outbox relay, consumers decode Data according to Type and SchemaVersion.
+type OutboxEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Data          json.RawMessage `json:"data"`
+	OccurredOn    time.Time       `json:"occurred_on"`
+	Attempts      int             `json:"-"`
+}
+
+// MemberEventData is the data of the member.registered and member.deleted events.
+type MemberEventData struct {
+	MemberID  uuid.UUID `json:"member_id"`
+	PartnerID string    `json:"partner_id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+}
+
+// MemberSubscriptionEventData is the data of the member_subscription events.
+type MemberSubscriptionEventData struct {
+	MemberSubscriptionID   string     `json:"member_subscription_id"`
+	MemberID               uuid.UUID  `json:"member_id"`
+	SubscriptionID         string     `json:"subscription_id,omitempty"`
+	PreviousSubscriptionID string     `json:"previous_subscription_id,omitempty"`
+	Status                 string     `json:"status"`
+	PreviousStatus         string     `json:"previous_status,omitempty"`
+	ExpirationDate         *time.Time `json:"expiration_date,omitempty"`
+}
+
+// BillingAddressEventData is the data of the billing_address events.
+type BillingAddressEventData struct {
+	MemberID         uuid.UUID `json:"member_id"`
+	BillingAddressID uuid.UUID `json:"billing_address_id"`
+	Country          string    `json:"country,omitempty"`
+	State            string    `json:"state,omitempty"`
+	Primary          bool      `json:"primary"`
+}
+
+// MemberExport is the data held about a member, handed over to the member on request.
+type MemberExport struct {
+	MemberID         uuid.UUID                  `json:"member_id"`
+	ExportedOn       time.Time                  `json:"exported_on"`
+	Profile          MemberExportProfile        `json:"profile"`
+	BillingAddresses []BillingAddress           `json:"billing_addresses"`
+	Subscriptions    []MemberExportSubscription `json:"subscriptions"`
+	Stores           []MemberExportStore        `json:"stores"`
+	RefreshTokens    []MemberExportRefreshToken `json:"refresh_tokens"`
+}
+
+// MemberExportProfile is the profile part of a member data export.
+type MemberExportProfile struct {
+	Title           string `json:"title"`
+	FirstName       string `json:"firstname"`
+	LastName        string `json:"lastname"`
+	Gender          string `json:"gender"`
+	Email           string `json:"email"`
+	Phone           string `json:"phone"`
+	Address1        string `json:"address1"`
+	Address2        string `json:"address2"`
+	City            string `json:"city"`
+	State           string `json:"state"`
+	Country         string `json:"country"`
+	Zipcode         string `json:"zipcode"`
+	Language        string `json:"language"`
+	PayingTax       bool   `json:"paying_tax"`
+	EmailSubscribed bool   `json:"email_subscribed"`
+	PartnerID       string `json:"partner_id"`
+}
+
+// MemberExportSubscription is a subscription of the member in a data export.
+type MemberExportSubscription struct {
+	MemberSubscriptionID string     `json:"member_subscription_id"`
+	SubscriptionID       string     `json:"subscription_id"`
+	PlanName             string     `json:"plan_name"`
+	CustomName           string     `json:"custom_name"`
+	Status               string     `json:"status"`
+	ExpirationDate       time.Time  `json:"expiration_date"`
+	RenewedOn            *time.Time `json:"renewed_on,omitempty"`
+}
+
+// MemberExportStore is a store of the member in a data export.
+type MemberExportStore struct {
+	StoreID    uuid.UUID `json:"store_id"`
+	CustomName string    `json:"custom_name"`
+	IsActive   bool      `json:"is_active"`
+}
+
+// MemberExportRefreshToken is a login session of the member in a data export. The tokens
+// themselves are secrets and are left out.
+type MemberExportRefreshToken struct {
+	PartnerID string    `json:"partner_id"`
+	Revoked   bool      `json:"revoked"`
+	CreatedOn time.Time `json:"created_on"`
+}
+
+// MemberErasureRequest is a pending request to erase the personal data of a member.
+type MemberErasureRequest struct {
+	ID          uuid.UUID
+	MemberID    uuid.UUID
+	RequestedBy string
+	RequestedOn time.Time
+}
+
+// MemberImport is a bulk member import and its progress. Errors lists the rows that
+// were not imported.
+type MemberImport struct {
+	ID           uuid.UUID              `json:"import_id"`
+	PartnerID    string                 `json:"partner_id"`
+	Format       string                 `json:"format"`
+	Status       string                 `json:"status"`
+	TotalRows    int                    `json:"total_rows"`
+	ImportedRows int                    `json:"imported_rows"`
+	FailedRows   int                    `json:"failed_rows"`
+	RequestedBy  string                 `json:"-"`
+	CreatedOn    time.Time              `json:"created_on"`
+	StartedOn    *time.Time             `json:"started_on,omitempty"`
+	CompletedOn  *time.Time             `json:"completed_on,omitempty"`
+	Errors       []MemberImportRowError `json:"errors"`
+}
+
+// MemberImportRow is a row of a bulk import waiting to be imported. Rows are numbered from 1
+// in the order of the upload. The password is checked against the password policy and hashed
+// at upload, the row keeps the hash and the policy errors only.
+type MemberImportRow struct {
+	RowNumber      int
+	Member         Member
+	PasswordErrors map[string][]string
+}
+
+// MemberImportRowError lists the validation errors of a row that was not imported.
+type MemberImportRowError struct {
+	RowNumber int                 `json:"row"`
+	Email     string              `json:"email"`
+	Errors    map[string][]string `json:"errors"`
+}
+
+// MemberImportResponse is the response of the bulk import endpoints.
+type MemberImportResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    MemberImport `json:"data"`
+}
+
+// Notification represents a message to be delivered to a member.
+// Event selects the template and Data holds the values used to render it.
+type Notification struct {
+	Event    string
+	To       string
+	Language string
+	Data     map[string]interface{}
+}
+
+// Message is a rendered notification ready to be delivered.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MemberContact holds the details required to notify a member.
+type MemberContact struct {
+	Name     string
+	Email    string
+	Language string
+}
+
 // ErrorResponse represents an error response that can be sent back to clients in JSON format.
 type ErrorResponse struct {
 	Message   string                 `json:"message"`
@@ -64,6 +248,16 @@ type MemberProfile struct {
 	MemberDetails        Member           `json:"member_details"`
 	MemberBillingAddress []BillingAddress `json:"member_billing_address"`
 	EmailSubscribed      bool             `json:"email_subscribed"`
+	LoginLockout         *LoginLockout    `json:"login_lockout,omitempty"` // Only shown to admins
+	Version              int64            `json:"-"`                       // Returned as the ETag
+}
+
+// LoginLockout is the throttling state of the password logins of a member or a client IP.
+type LoginLockout struct {
+	Failures      int        `json:"failed_attempts"`
+	LastFailedAt  *time.Time `json:"last_failed_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
 }
 
 // Params represents a set of parameters that can be used for querying members.
@@ -83,6 +277,85 @@ type ResetPassword struct {
 	Email string `json:"email"`
 }
 
+// VerifyEmail represents a request to verify a member's email address with the token
+// sent to it.
+type VerifyEmail struct {
+	Token string `json:"token"`
+}
+
+// ResendEmailVerification represents a request to send a new verification token to the
+// email address of a member.
+type ResendEmailVerification struct {
+	Email string `json:"email"`
+}
+
+// EmailVerificationPolicy holds the actions a partner blocks until the member's email
+// address is verified.
+type EmailVerificationPolicy struct {
+	BlockLogin    bool `json:"block_login"`
+	BlockCheckout bool `json:"block_checkout"`
+}
+
+// EmailVerification is the verification state of a member's email address along with the
+// policy of the member's partner.
+type EmailVerification struct {
+	MemberID   uuid.UUID
+	Name       string
+	Email      string
+	Language   string
+	VerifiedAt *time.Time
+	Policy     EmailVerificationPolicy
+}
+
+// BlocksLogin reports whether the member may not log in until the email is verified.
+func (v EmailVerification) BlocksLogin() bool {
+	return v.VerifiedAt == nil && v.Policy.BlockLogin
+}
+
+// BlocksCheckout reports whether the member may not check out a subscription until the
+// email is verified.
+func (v EmailVerification) BlocksCheckout() bool {
+	return v.VerifiedAt == nil && v.Policy.BlockCheckout
+}
+
+// TwoFactor is the two-factor authentication state of a member. EnabledAt is nil while the
+// enrollment waits for the first code.
+type TwoFactor struct {
+	MemberID     uuid.UUID
+	Email        string
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+}
+
+// Enabled reports whether logins of the member require a second factor.
+func (t TwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// TwoFactorEnrollment holds the secret of a pending enrollment, the URI is usually shown as a QR code.
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorCode represents a request carrying an authenticator app code or a recovery code.
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorRecoveryCodes holds the recovery codes, they are only shown once.
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorChallenge represents a request completing the login challenge with the code of the
+// member's authenticator app or a recovery code.
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
 // Role represents information about a specific role with an ID and name.
 type Role struct {
 	Id   int
@@ -110,6 +383,61 @@ type ViewMembers struct {
 	ArtistCount int
 }
 
+// MemberListParams are the query parameters of the cursor-paginated member listing.
+// Roles and countries may be repeated or given as comma separated lists.
+type MemberListParams struct {
+	Cursor      string   `form:"cursor"`
+	Limit       int32    `form:"limit"`
+	Sort        string   `form:"sort"`
+	Order       string   `form:"order"`
+	Active      string   `form:"active"`
+	Roles       []string `form:"role"`
+	Countries   []string `form:"country"`
+	Partner     string   `form:"partner"`
+	Search      string   `form:"search"`
+	CreatedFrom string   `form:"created_from"`
+	CreatedTo   string   `form:"created_to"`
+}
+
+// MemberListFilter is the validated form of MemberListParams.
+type MemberListFilter struct {
+	Limit       int32
+	Sort        string
+	Order       string
+	After       *MemberCursor // position of the last member of the previous page
+	Active      *bool
+	Roles       []int
+	Countries   []string
+	Partner     uuid.UUID
+	Search      string
+	CreatedFrom time.Time // inclusive, zero when open
+	CreatedTo   time.Time // exclusive, zero when open
+}
+
+// MemberCursor is the position of a member in the listing order. It is handed out
+// to clients as an opaque cursor.
+type MemberCursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// MemberListMetaData describes a page of the cursor-paginated member listing.
+type MemberListMetaData struct {
+	PerPage    int32  `json:"per_page"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// MemberListResponse is a page of the cursor-paginated member listing.
+type MemberListResponse struct {
+	Code     int                `json:"code"`
+	Message  string             `json:"message"`
+	Metadata MemberListMetaData `json:"metadata"`
+	Data     []ViewMembers      `json:"data"`
+}
+
 // BasicMemberData represents basic member details.
 type BasicMemberData struct {
 	MemberID    uuid.UUID `json:"member_id"`
@@ -120,6 +448,11 @@ type BasicMemberData struct {
 	Email       string    `json:"member_email"`
 	MemberType  string    `json:"member_type"`
 	MemberRoles []string  `json:"member_roles"`
+	// The login challenge of members with two-factor authentication, it replaces the member
+	// details until it is completed.
+	TwoFactorRequired  bool       `json:"two_factor_required,omitempty"`
+	ChallengeToken     string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
 }
 
 // MemberPayload represents essential information about a member.
@@ -141,6 +474,15 @@ type JwtValidateResponse struct {
 	ErrorMsg    string   `json:"errorms"`
 }
 
+// RoutePolicy describes the authorization rules for a route.
+type RoutePolicy struct {
+	Method string   // HTTP method of the route
+	Path   string   // gin route template, e.g. /api/:version/members/:member_id
+	Public bool     // route can be called without a token
+	Roles  []string // roles or member types allowed to access any member
+	Self   bool     // member named in :member_id may access its own resource
+}
+
 type Claims struct {
 	MemberName  string
 	MemberID    string
@@ -164,6 +506,7 @@ type MemberByID struct {
 	City      sql.NullString
 	Address1  sql.NullString
 	Address2  sql.NullString
+	Version   int64
 }
 
 // CheckoutSubscription represents the data structure for a subscription checkout request.
@@ -171,6 +514,10 @@ type CheckoutSubscription struct {
 	SubscriptionID   string `json:"subscription_id"`    // SubscriptionID is the unique identifier for the subscription.
 	PaymentGatewayID int    `json:"payment_gateway_id"` // PaymentGatewayID is the ID of the payment gateway to use for the subscription payment.
 	CustomName       string `json:"custom_name"`        //CustomName is the alternative name for the subscribed plan
+	PromoCode        string `json:"promo_code"`         // PromoCode is the optional promo code applied to the plan price.
+
+	// Discount is the discount of the promo code, set once the code is validated.
+	Discount *PromoDiscount `json:"-"`
 }
 
 // SubscriptionRenewal represents the data structure for a subscription renewal request.
@@ -180,6 +527,40 @@ type SubscriptionRenewal struct {
 
 }
 
+// AutoRenewal opts a member subscription in or out of automatic renewal. Renewals are charged on
+// the payment gateway, which is required to opt in.
+type AutoRenewal struct {
+	MemberSubscriptionID string `json:"member_subscription_id"`
+	Enabled              bool   `json:"enabled"`
+	PaymentGatewayID     int    `json:"payment_gateway_id"`
+}
+
+// RenewalCandidate is a member subscription due for an automatic renewal attempt. Failures counts
+// the failed attempts since the subscription was last renewed.
+type RenewalCandidate struct {
+	MemberSubscriptionID string
+	SubscriptionID       string
+	MemberID             uuid.UUID
+	PartnerID            uuid.UUID
+	CustomName           string
+	PaymentGatewayID     int
+	ExpirationDate       time.Time
+	Failures             int
+}
+
+// RenewalAttempt is an automatic renewal attempt of a member subscription and its outcome. Failed
+// attempts are retried on NextAttemptOn, when set.
+type RenewalAttempt struct {
+	ID                   uuid.UUID  `json:"id"`
+	MemberSubscriptionID string     `json:"member_subscription_id"`
+	MemberID             uuid.UUID  `json:"member_id"`
+	Attempt              int        `json:"attempt"`
+	Status               string     `json:"status"`
+	FailureReason        string     `json:"failure_reason,omitempty"`
+	NextAttemptOn        *time.Time `json:"next_attempt_on,omitempty"`
+	CreatedOn            time.Time  `json:"created_on"`
+}
+
 // CancelSubscription structure
 type CancelSubscription struct {
 	MemberSubscriptionID string `json:"member_subscription_id"` // SubscriptionID is the unique identifier for the subscription.
@@ -192,17 +573,223 @@ type SwitchSubscriptions struct {
 	ProductReferenceID    string `json:"product_reference_id"`
 }
 
+// SubscriptionPlanChange represents the data structure for switching a member subscription to another subscription plan.
+type SubscriptionPlanChange struct {
+	MemberSubscriptionID string `json:"member_subscription_id"` // MemberSubscriptionID is the member subscription to switch.
+	NewSubscriptionID    string `json:"new_subscription_id"`    // NewSubscriptionID is the subscription plan to switch to.
+	PaymentGatewayID     int    `json:"payment_gateway_id"`     // PaymentGatewayID charges upgrades, downgrades need none.
+}
+
+// SubscriptionPlanTerms holds the price, duration and limits of a subscription plan.
+type SubscriptionPlanTerms struct {
+	SubscriptionID  string
+	PlanID          uuid.UUID
+	Name            string
+	Amount          float64
+	TaxPercentage   float64
+	CurrencyID      int
+	Currency        string // ISO code of CurrencyID, empty when it has none
+	DurationWeeks   int
+	MaximumProducts int
+	MaximumTracks   int
+	MaximumArtists  int
+	IsActive        bool
+}
+
+// MemberSubscriptionState holds the plan, status, expiration date and usage of a member subscription.
+type MemberSubscriptionState struct {
+	MemberSubscriptionID string
+	SubscriptionID       string
+	CustomName           string
+	Status               string
+	ExpirationDate       time.Time
+	ProductsAdded        int
+	TracksAdded          int
+	ArtistsAdded         int
+}
+
+// QuotaUsage is the usage of a resource limited by the plan of a member subscription. Used
+// counts the items added, Reserved the capacity held by pending reservations.
+type QuotaUsage struct {
+	Resource  string `json:"resource"`
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Reserved  int    `json:"reserved"`
+	Available int    `json:"available"`
+}
+
+// SubscriptionQuota is the usage of the resources of a member subscription.
+type SubscriptionQuota struct {
+	MemberSubscriptionID string       `json:"member_subscription_id"`
+	Status               string       `json:"status"`
+	Usage                []QuotaUsage `json:"usage"`
+}
+
+// QuotaRequest asks whether Quantity more items of a resource can be added to a member
+// subscription, or reserves the capacity for them for TTLSeconds.
+type QuotaRequest struct {
+	Resource   string `json:"resource"`
+	Quantity   int    `json:"quantity"`
+	TTLSeconds int    `json:"ttl_seconds"`
+}
+
+// QuotaCheck answers a QuotaRequest.
+type QuotaCheck struct {
+	Resource  string `json:"resource"`
+	Quantity  int    `json:"quantity"`
+	Available int    `json:"available"`
+	Allowed   bool   `json:"allowed"`
+}
+
+// QuotaReservation holds capacity of a member subscription while an item is created.
+type QuotaReservation struct {
+	ID                   uuid.UUID `json:"id"`
+	MemberID             uuid.UUID `json:"member_id"`
+	MemberSubscriptionID string    `json:"member_subscription_id"`
+	Resource             string    `json:"resource"`
+	Quantity             int       `json:"quantity"`
+	Status               string    `json:"status"`
+	ExpiresOn            time.Time `json:"expires_on"`
+	CreatedOn            time.Time `json:"created_on"`
+}
+
+// PlanPrice is the price of a subscription plan in one currency.
+type PlanPrice struct {
+	CurrencyID    int     `json:"currency_id"`
+	Amount        float64 `json:"amount"`
+	TaxPercentage float64 `json:"tax_percentage"`
+}
+
+// SubscriptionPlanRequest holds the terms of a new subscription plan or of a new version of one.
+// The first price is the default price of the plan.
+type SubscriptionPlanRequest struct {
+	Name                     string      `json:"name"`
+	SKU                      string      `json:"sku"`
+	SubscriptionDurationID   int         `json:"subscription_duration_id"`
+	IsOneTimeSubscription    bool        `json:"is_one_time_subscription"`
+	IsFreeSubscription       bool        `json:"is_free_subscription"`
+	IsCancellationEnabled    bool        `json:"is_cancellation_enabled"`
+	CanRenewableWithin       int         `json:"can_renewable_within"`
+	SubscriptionLimitPerYear int         `json:"subscription_limit_per_year"`
+	ProductCount             int         `json:"product_count"`
+	TrackCount               int         `json:"track_count"`
+	ArtistCount              int         `json:"artist_count"`
+	MaxTracksPerProduct      int         `json:"max_tracks_per_product"`
+	MaxArtistsPerProduct     int         `json:"max_artists_per_product"`
+	Prices                   []PlanPrice `json:"prices"`
+}
+
+// SubscriptionPlan is a version of a plan of the catalogue. Versions of a plan share the PlanID,
+// ID identifies the version member subscriptions reference. Only the latest version of a plan
+// that is not retired is active and can be bought.
+type SubscriptionPlan struct {
+	ID       uuid.UUID `json:"id"`
+	PlanID   uuid.UUID `json:"plan_id"`
+	Version  int       `json:"version"`
+	Duration string    `json:"duration"`
+	SubscriptionPlanRequest
+	IsActive  bool       `json:"is_active"`
+	RetiredOn *time.Time `json:"retired_on,omitempty"`
+	CreatedOn time.Time  `json:"created_on"`
+}
+
+// SubscriptionPlanParams filters the listing of the plan catalogue.
+type SubscriptionPlanParams struct {
+	Retired bool `form:"retired"`
+}
+
+// PromoCodeRequest holds the terms of a new promo code. Percentage codes take DiscountValue percent
+// off the plan price, fixed codes take DiscountValue off in the currency of CurrencyID. Codes without
+// partners or plans apply to every partner or plan of the catalogue, PlanIDs are catalogue plan IDs
+// so the code applies to every version of the plans. Zero limits do not limit redemptions.
+type PromoCodeRequest struct {
+	Code                    string     `json:"code"`
+	DiscountType            string     `json:"discount_type"`
+	DiscountValue           float64    `json:"discount_value"`
+	CurrencyID              int        `json:"currency_id,omitempty"`
+	PartnerIDs              []string   `json:"partner_ids"`
+	PlanIDs                 []string   `json:"plan_ids"`
+	ValidFrom               *time.Time `json:"valid_from,omitempty"`
+	ValidUntil              *time.Time `json:"valid_until,omitempty"`
+	MaxRedemptions          int        `json:"max_redemptions"`
+	MaxRedemptionsPerMember int        `json:"max_redemptions_per_member"`
+}
+
+// PromoCode is a promo code with its redemptions so far and the total discount they were given.
+// Redemptions of checkouts whose payment failed are not counted.
+type PromoCode struct {
+	ID uuid.UUID `json:"id"`
+	PromoCodeRequest
+	IsActive      bool      `json:"is_active"`
+	Redemptions   int       `json:"redemptions"`
+	DiscountTotal float64   `json:"discount_total"`
+	CreatedOn     time.Time `json:"created_on"`
+}
+
+// PromoDiscount is the discount a promo code takes off the price of a checkout.
+type PromoDiscount struct {
+	PromoCodeID uuid.UUID
+	Code        string
+	Amount      float64
+}
+
+// PromoRedemption is the redemption of a promo code by a member subscription checkout.
+type PromoRedemption struct {
+	ID                   uuid.UUID `json:"id"`
+	PromoCodeID          uuid.UUID `json:"promo_code_id"`
+	MemberID             uuid.UUID `json:"member_id"`
+	MemberSubscriptionID string    `json:"member_subscription_id"`
+	SubscriptionStatus   string    `json:"subscription_status"`
+	DiscountAmount       float64   `json:"discount_amount"`
+	RedeemedOn           time.Time `json:"redeemed_on"`
+}
+
+// PromoRedemptionParams paginates the redemptions of a promo code.
+type PromoRedemptionParams struct {
+	Page  int32 `form:"page"`
+	Limit int32 `form:"limit"`
+}
+
+// PromoRedemptionListResponse is the paginated list of redemptions of a promo code.
+type PromoRedemptionListResponse struct {
+	Code     int               `json:"code"`
+	Message  string            `json:"message"`
+	Metadata interface{}       `json:"metadata"`
+	Data     []PromoRedemption `json:"data"`
+}
+
+// SubscriptionLedgerEntry is a money adjustment made on a member subscription. Credit is the unused value
+// of the previous plan, Charge the value of the new plan for the same remaining time, and Amount their
+// difference: positive amounts are owed by the member, negative amounts are credited to the member. Payments
+// settle the amount owed for an upgrade, renewals use up the credit of downgrades, and a refund credits the
+// unused value of the plan and charges it back as the refunded amount, leaving nothing owed.
+type SubscriptionLedgerEntry struct {
+	ID                   uuid.UUID `json:"id"`
+	MemberSubscriptionID string    `json:"-"`
+	MemberID             uuid.UUID `json:"-"`
+	Kind                 string    `json:"kind"`
+	FromSubscriptionID   string    `json:"from_subscription_id"`
+	ToSubscriptionID     string    `json:"to_subscription_id"`
+	Credit               float64   `json:"credit"`
+	Charge               float64   `json:"charge"`
+	Amount               float64   `json:"amount"`
+	CurrencyID           int       `json:"currency_id"`
+	CreatedOn            time.Time `json:"created_on"`
+}
+
 // ListAllSubscriptions represents the ListAllSubscriptions entity.
 type ListAllSubscriptions struct {
-	ID                  uuid.UUID           `json:"id,omitempty"`
-	CustomNameJSON      json.RawMessage     `json:"custom_name,omitempty"`
-	CustomName          NullableString      `json:"-"`
-	Status              string              `json:"status,omitempty"`
-	ExpirationDate      string              `json:"expiration_date"`
-	ProductsAdded       int                 `json:"products_added"`
-	TracksAdded         int                 `json:"tracks_added"`
-	ArtistsAdded        int                 `json:"artists_added"`
-	SubscriptionDetails SubscriptionDetails `json:"subscription_details"`
+	ID                  uuid.UUID                 `json:"id,omitempty"`
+	CustomNameJSON      json.RawMessage           `json:"custom_name,omitempty"`
+	CustomName          NullableString            `json:"-"`
+	Status              string                    `json:"status,omitempty"`
+	ExpirationDate      string                    `json:"expiration_date"`
+	ProductsAdded       int                       `json:"products_added"`
+	TracksAdded         int                       `json:"tracks_added"`
+	ArtistsAdded        int                       `json:"artists_added"`
+	SubscriptionDetails SubscriptionDetails       `json:"subscription_details"`
+	Balance             float64                   `json:"balance"`          // Sum of the ledger amounts
+	Ledger              []SubscriptionLedgerEntry `json:"ledger,omitempty"` // Plan change, payment, renewal credit and refund adjustments, newest first
 }
 
 // SubscriptionDetails represents subscription entity
@@ -226,6 +813,50 @@ type MetaData struct {
 }
 
 // SuccessResponse represents success data for an entity.
+// InvoiceListResponse is the paginated list of a member's invoices.
+type InvoiceListResponse struct {
+	Code     int         `json:"code"`
+	Message  string      `json:"message"`
+	Metadata interface{} `json:"metadata"`
+	Data     []Invoice   `json:"data"`
+}
+
+// AuditChange is the change of a member field recorded in the audit trail.
+type AuditChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditEntry is an operation recorded in the audit trail of a member. ResourceID identifies
+// the changed resource of the member, like a billing address. ActorID is empty for operations
+// without a logged in member, like password resets.
+type AuditEntry struct {
+	ID         uuid.UUID     `json:"id"`
+	MemberID   uuid.UUID     `json:"member_id"`
+	ResourceID string        `json:"resource_id,omitempty"`
+	ActorID    string        `json:"actor_id,omitempty"`
+	Action     string        `json:"action"`
+	Changes    []AuditChange `json:"changes"`
+	RequestID  string        `json:"request_id,omitempty"`
+	CreatedOn  time.Time     `json:"created_on"`
+}
+
+// AuditParams are the query parameters of the audit trail listing.
+type AuditParams struct {
+	Page   int32  `form:"page"`
+	Limit  int32  `form:"limit"`
+	Action string `form:"action"`
+}
+
+// AuditListResponse is the paginated audit trail of a member.
+type AuditListResponse struct {
+	Code     int          `json:"code"`
+	Message  string       `json:"message"`
+	Metadata interface{}  `json:"metadata"`
+	Data     []AuditEntry `json:"data"`
+}
+
 type SuccessResponse struct {
 	Code     int                    `json:"code"`
 	Message  string                 `json:"message"`
@@ -255,6 +886,49 @@ type SubscriptionStatusDetails struct {
 	IsWithinGraceDuration bool
 	Err                   error
 }
+
+// MemberStore is a store a member distributes to. Stores are listed in the order of Position.
+type MemberStore struct {
+	StoreID    uuid.UUID `json:"store_id"`
+	Name       string    `json:"name"`
+	CustomName string    `json:"custom_name"`
+	IsActive   bool      `json:"is_active"`
+	Position   int       `json:"position"`
+}
+
+// MemberStoreUpdate is the partial update of a member store, nil fields are left as they are.
+type MemberStoreUpdate struct {
+	CustomName *string `json:"custom_name"`
+	IsActive   *bool   `json:"is_active"`
+}
+
+// MemberStoreOrder lists every store of a member in the new order.
+type MemberStoreOrder struct {
+	Stores []uuid.UUID `json:"stores"`
+}
+
+// PartnerStore is a store of the catalogue of stores a partner offers to its members.
+type PartnerStore struct {
+	StoreID    uuid.UUID `json:"store_id"`
+	Name       string    `json:"name"`
+	CustomName string    `json:"custom_name"`
+	IsActive   bool      `json:"is_active"`
+}
+
+// BulkMemberStores applies stores to, or removes stores from, many members at once.
+type BulkMemberStores struct {
+	Action    string   `json:"action"`
+	MemberIDs []string `json:"member_ids"`
+	Stores    []string `json:"stores"`
+}
+
+// BulkMemberStoresResult is the outcome of a bulk member store request for one member.
+type BulkMemberStoresResult struct {
+	MemberID string              `json:"member_id"`
+	Status   string              `json:"status"`
+	Errors   map[string][]string `json:"errors,omitempty"`
+}
+
 type AddMemberStores struct {
 	Storelist []string `json:"stores"`
 }
@@ -275,6 +949,95 @@ type PaymentGatewayDetails struct {
 	DefaultPayinCurrency  string   `json:"default_payin_currency"`
 	DefaultPayoutCurrency string   `json:"default_payout_currency"`
 }
+
+// PaymentRequest represents an amount charged, or refunded, on a payment gateway.
+type PaymentRequest struct {
+	Reference   string                // Member subscription the payment is for
+	Amount      float64               // Amount including tax
+	Currency    string                // ISO currency code
+	Credentials PaymentGatewayDetails // Partner credentials for the gateway
+}
+
+// PaymentResult represents the outcome of a payment gateway operation.
+type PaymentResult struct {
+	ID            string
+	Status        string
+	Amount        float64
+	Currency      string
+	FailureReason string
+}
+
+// SubscriptionPayment represents a payment operation recorded for a member subscription.
+type SubscriptionPayment struct {
+	ID                   uuid.UUID
+	MemberSubscriptionID string
+	MemberID             uuid.UUID
+	PaymentGatewayID     int
+	GatewayPaymentID     string
+	Kind                 string
+	Amount               float64
+	Currency             string
+	Status               string
+	FailureReason        string
+}
+
+// BillingProfile holds the member details printed on invoices.
+type BillingProfile struct {
+	Name           string
+	Email          string
+	PayingTax      bool
+	BillingAddress *BillingAddress // Primary billing address, nil when the member has none
+}
+
+// InvoiceLine is a line item of an invoice.
+type InvoiceLine struct {
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitAmount  float64 `json:"unit_amount"`
+	Amount      float64 `json:"amount"`
+}
+
+// Invoice is the record of what a member was billed for a subscription checkout or renewal. The billing
+// name and address are copied from the member when the invoice is issued.
+type Invoice struct {
+	ID                   uuid.UUID      `json:"id"`
+	Number               string         `json:"number"`
+	MemberID             uuid.UUID      `json:"member_id"`
+	MemberSubscriptionID string         `json:"member_subscription_id"`
+	PaymentID            uuid.UUID      `json:"-"`
+	Kind                 string         `json:"kind"`
+	Currency             string         `json:"currency"`
+	Lines                []InvoiceLine  `json:"lines"`
+	Subtotal             float64        `json:"subtotal"`
+	TaxPercentage        float64        `json:"tax_percentage"`
+	TaxAmount            float64        `json:"tax_amount"`
+	Total                float64        `json:"total"`
+	BillingName          string         `json:"billing_name"`
+	BillingEmail         string         `json:"billing_email"`
+	BillingAddress       BillingAddress `json:"billing_address"`
+	IssuedOn             time.Time      `json:"issued_on"`
+	VoidedOn             *time.Time     `json:"voided_on,omitempty"` // Set when the invoiced payment failed
+}
+
+// PaymentWebhookEvent is a notification sent by a payment gateway about a payment made
+// for a member subscription. PaymentID is the gateway's ID of the charged payment.
+type PaymentWebhookEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	PaymentID string `json:"payment_id"`
+	Reason    string `json:"reason"`
+}
+
+// PaymentStateChange describes how a payment webhook event changes the payment and its member subscription.
+type PaymentStateChange struct {
+	PaymentStatus       string
+	FromPaymentStatuses []string // Payment statuses the change applies to, payments only move forward
+	SubscriptionStatus  string   // New subscription status, empty to leave the subscription as is
+	FromStatuses        []string // Subscription statuses the change applies to
+	Renew               bool     // Extend the expiration date, for captured renewals
+	RetryRenewal        bool     // Schedule the next automatic renewal attempt, for failed renewals
+}
+
 type MemberResponse struct {
 	Status   string       `json:"status"`
 	Code     int          `json:"code"`
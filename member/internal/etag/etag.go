@@ -0,0 +1,32 @@
+// Package etag converts row versions into entity tags and back, for the optimistic concurrency
+// control of updates: clients send the ETag of the representation they edited in the If-Match
+// header and updates of a row changed in the meantime are refused.
+package etag
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format returns the strong entity tag of a row version.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Parse returns the version of an If-Match header. An empty header and "*" return 0, any
+// version matches. It returns false when the header cannot match a version, like weak or
+// foreign tags: If-Match only matches with the strong comparison.
+func Parse(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,26 @@
+package etag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParse checks the If-Match header round trips the versions of Format and that tags
+// which cannot match a version are rejected.
+func TestParse(t *testing.T) {
+	version, ok := Parse(Format(42))
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), version)
+
+	for _, header := range []string{"", " ", "*"} {
+		version, ok = Parse(header)
+		assert.True(t, ok, header)
+		assert.Zero(t, version, header)
+	}
+
+	for _, header := range []string{`W/"42"`, `42`, `"abc"`, `"0"`, `"-1"`, `"1", "2"`, `"`} {
+		_, ok = Parse(header)
+		assert.False(t, ok, header)
+	}
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"member/internal/entities"
+)
+
+// WriteArchive writes a member data export as a zip archive holding one JSON document per kind of
+// data, plus export.json with the whole export for tools reading a single file.
+func WriteArchive(w io.Writer, export entities.MemberExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"export.json", export},
+		{"profile.json", export.Profile},
+		{"billing_addresses.json", export.BillingAddresses},
+		{"subscriptions.json", export.Subscriptions},
+		{"stores.json", export.Stores},
+		{"refresh_tokens.json", export.RefreshTokens},
+	}
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedOn,
+		})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
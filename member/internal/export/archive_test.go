@@ -0,0 +1,59 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"member/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteArchive checks that every part of the export is readable back from the archive.
+func TestWriteArchive(t *testing.T) {
+	export := entities.MemberExport{
+		MemberID:   uuid.New(),
+		ExportedOn: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Profile:    entities.MemberExportProfile{FirstName: "John", Email: "john.doe@example.com"},
+		BillingAddresses: []entities.BillingAddress{
+			{Address: "1 Main Street", Zipcode: "10001", Country: "US", Primary: true},
+		},
+		Subscriptions: []entities.MemberExportSubscription{},
+		Stores:        []entities.MemberExportStore{{StoreID: uuid.New(), CustomName: "Spotify", IsActive: true}},
+		RefreshTokens: []entities.MemberExportRefreshToken{{PartnerID: uuid.NewString(), Revoked: true}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteArchive(&buf, export))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], err = io.ReadAll(reader)
+		require.NoError(t, err)
+		reader.Close()
+	}
+	assert.Len(t, files, 6)
+
+	var whole entities.MemberExport
+	require.NoError(t, json.Unmarshal(files["export.json"], &whole))
+	assert.Equal(t, export.MemberID, whole.MemberID)
+
+	var profile entities.MemberExportProfile
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, "john.doe@example.com", profile.Email)
+
+	assert.JSONEq(t, `[]`, string(files["subscriptions.json"]))
+	assert.Contains(t, string(files["billing_addresses.json"]), "1 Main Street")
+	assert.Contains(t, string(files["refresh_tokens.json"]), `"revoked": true`)
+}
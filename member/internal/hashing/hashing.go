@@ -0,0 +1,174 @@
+// Package hashing hashes member passwords. Hashes are self-describing: the algorithm and its
+// parameters are encoded in the stored value, so passwords hashed with an older algorithm or
+// weaker parameters keep verifying and can be upgraded when the member next logs in.
+package hashing
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"member/internal/entities"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported hashing algorithms.
+const (
+	Argon2id = "argon2id"
+	Bcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// legacyHashLength is the length of the unsalted hex encoded MD5 hashes stored before
+	// the algorithm was encoded in the hash.
+	legacyHashLength = 32
+)
+
+// ErrUnknownHash is returned when a stored hash was not produced by a supported algorithm.
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password with the configured algorithm.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash and whether the hash
+	// should be replaced by a new Hash of the password, because it was produced by another
+	// algorithm or with other parameters than the configured ones.
+	Verify(password, encoded string) (match bool, rehash bool, err error)
+}
+
+// Supported reports whether algorithm can be configured.
+func Supported(algorithm string) bool {
+	return algorithm == Argon2id || algorithm == Bcrypt
+}
+
+// argon2Params are the cost parameters of an argon2id hash.
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// hasher is the PasswordHasher for the configured algorithm.
+type hasher struct {
+	algorithm  string
+	argon2     argon2Params
+	bcryptCost int
+}
+
+// NewPasswordHasher returns a hasher producing hashes with the configured algorithm and
+// verifying hashes of every supported algorithm. Unknown algorithms fall back to argon2id,
+// the configuration loader rejects them.
+func NewPasswordHasher(cfg entities.PasswordHashConfig) PasswordHasher {
+	algorithm := cfg.Algorithm
+	if !Supported(algorithm) {
+		algorithm = Argon2id
+	}
+	return &hasher{
+		algorithm: algorithm,
+		argon2: argon2Params{
+			memory:      cfg.Argon2Memory,
+			iterations:  cfg.Argon2Iterations,
+			parallelism: cfg.Argon2Parallelism,
+		},
+		bcryptCost: cfg.BcryptCost,
+	}
+}
+
+// Hash returns the encoded hash of the password.
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, h.argon2.iterations, h.argon2.memory, h.argon2.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.argon2.memory, h.argon2.iterations,
+		h.argon2.parallelism, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks the password against an argon2id, bcrypt or legacy MD5 hash.
+func (h *hasher) Verify(password, encoded string) (bool, bool, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		params, salt, key, err := decodeArgon2(encoded)
+		if err != nil {
+			return false, false, err
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false, nil
+		}
+		return true, h.algorithm != Argon2id || params != h.argon2, nil
+
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		if err != nil {
+			return false, false, err
+		}
+		return true, h.algorithm != Bcrypt || cost != h.bcryptCost, nil
+
+	case len(encoded) == legacyHashLength && isHex(encoded):
+		sum := md5.Sum([]byte(password))
+		if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(strings.ToLower(encoded))) != 1 {
+			return false, false, nil
+		}
+		return true, true, nil
+	}
+	return false, false, ErrUnknownHash
+}
+
+// decodeArgon2 parses a hash in the $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key> format.
+func decodeArgon2(encoded string) (argon2Params, []byte, []byte, error) {
+	var (
+		params  argon2Params
+		version int
+	)
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHash
+	}
+	return params, salt, key, nil
+}
+
+// isHex reports whether s only holds hexadecimal digits.
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
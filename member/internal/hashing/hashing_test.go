@@ -0,0 +1,93 @@
+package hashing
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"member/internal/entities"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig uses cheap parameters to keep the tests fast.
+var testConfig = entities.PasswordHashConfig{
+	Algorithm:         Argon2id,
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	BcryptCost:        4,
+}
+
+// TestHashAndVerify checks each algorithm verifies its own hashes and that hashes of the
+// configured algorithm and parameters need no rehash.
+func TestHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{Argon2id, Bcrypt} {
+		cfg := testConfig
+		cfg.Algorithm = algorithm
+		hasher := NewPasswordHasher(cfg)
+
+		hash, err := hasher.Hash("Secret@123")
+		require.NoError(t, err, algorithm)
+		assert.Contains(t, hash, "$", algorithm)
+
+		match, rehash, err := hasher.Verify("Secret@123", hash)
+		require.NoError(t, err, algorithm)
+		assert.True(t, match, algorithm)
+		assert.False(t, rehash, algorithm)
+
+		match, _, err = hasher.Verify("Secret@124", hash)
+		require.NoError(t, err, algorithm)
+		assert.False(t, match, algorithm)
+
+		other, err := hasher.Hash("Secret@123")
+		require.NoError(t, err, algorithm)
+		assert.NotEqual(t, hash, other, "hashes are salted")
+	}
+}
+
+// TestVerifyRehash checks hashes of another algorithm, other parameters or the legacy
+// scheme still verify and are flagged for a rehash.
+func TestVerifyRehash(t *testing.T) {
+	argon := NewPasswordHasher(testConfig)
+	bcryptCfg := testConfig
+	bcryptCfg.Algorithm = Bcrypt
+	bcryptHasher := NewPasswordHasher(bcryptCfg)
+
+	bcryptHash, err := bcryptHasher.Hash("Secret@123")
+	require.NoError(t, err)
+	match, rehash, err := argon.Verify("Secret@123", bcryptHash)
+	require.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	stronger := testConfig
+	stronger.Argon2Iterations = 2
+	argonHash, err := argon.Hash("Secret@123")
+	require.NoError(t, err)
+	match, rehash, err = NewPasswordHasher(stronger).Verify("Secret@123", argonHash)
+	require.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	sum := md5.Sum([]byte("Secret@123"))
+	legacy := hex.EncodeToString(sum[:])
+	match, rehash, err = argon.Verify("Secret@123", legacy)
+	require.NoError(t, err)
+	assert.True(t, match)
+	assert.True(t, rehash)
+
+	match, _, err = argon.Verify("Secret@124", legacy)
+	require.NoError(t, err)
+	assert.False(t, match)
+}
+
+// TestVerifyUnknownHash checks malformed hashes are rejected.
+func TestVerifyUnknownHash(t *testing.T) {
+	hasher := NewPasswordHasher(testConfig)
+	for _, encoded := range []string{"", "plain", "$argon2id$v=19$m=1024$salt$key", strings.Repeat("z", 32)} {
+		_, _, err := hasher.Verify("Secret@123", encoded)
+		assert.ErrorIs(t, err, ErrUnknownHash, encoded)
+	}
+}
@@ -0,0 +1,30 @@
+package invoice
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"member/internal/entities"
+)
+
+//go:embed templates/invoice.html
+var templateFS embed.FS
+
+// invoiceTemplate renders a single invoice as a printable HTML document.
+var invoiceTemplate = template.Must(template.New("invoice.html").Funcs(template.FuncMap{
+	"money": func(amount float64, currency string) string {
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	},
+	"date": func(invoice entities.Invoice) string {
+		return invoice.IssuedOn.Format("2006-01-02")
+	},
+}).ParseFS(templateFS, "templates/invoice.html"))
+
+// RenderHTML writes the invoice as an HTML document to w.
+func RenderHTML(w io.Writer, invoice entities.Invoice) error {
+	if err := invoiceTemplate.Execute(w, invoice); err != nil {
+		return fmt.Errorf("rendering invoice %s: %w", invoice.Number, err)
+	}
+	return nil
+}
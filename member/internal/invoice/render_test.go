@@ -0,0 +1,52 @@
+package invoice
+
+import (
+	"bytes"
+	"member/internal/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTML(t *testing.T) {
+	invoice := entities.Invoice{
+		Number:   "INV-00000042",
+		Currency: "USD",
+		Lines: []entities.InvoiceLine{
+			{Description: "Gold <yearly> subscription", Quantity: 1, UnitAmount: 100, Amount: 100},
+		},
+		Subtotal:      100,
+		TaxPercentage: 10,
+		TaxAmount:     10,
+		Total:         110,
+		BillingName:   "John Doe",
+		BillingEmail:  "john.doe@example.com",
+		BillingAddress: entities.BillingAddress{
+			Address: "1 Main Street",
+			Zipcode: "12345",
+			Country: "US",
+			State:   "CA",
+			Primary: true,
+		},
+		IssuedOn: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, RenderHTML(&out, invoice))
+
+	html := out.String()
+	assert.Contains(t, html, "Invoice INV-00000042")
+	assert.Contains(t, html, "Issued on 2024-03-01")
+	assert.Contains(t, html, "1 Main Street")
+	assert.Contains(t, html, "Gold &lt;yearly&gt; subscription")
+	assert.Contains(t, html, "110.00 USD")
+	assert.NotContains(t, html, "voided on")
+
+	voidedOn := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	invoice.VoidedOn = &voidedOn
+	out.Reset()
+	require.NoError(t, RenderHTML(&out, invoice))
+	assert.Contains(t, out.String(), "voided on 2024-03-02")
+}
@@ -0,0 +1,58 @@
+// Package lockout throttles failed login attempts. Every failure delays the next attempt
+// exponentially and too many failures within the attempt window lock the account or the
+// client IP for a fixed duration.
+package lockout
+
+import (
+	"member/internal/entities"
+	"time"
+)
+
+// Policy describes how failed attempts are throttled.
+type Policy struct {
+	// MaxFailures is the number of failures within Window that triggers a lockout.
+	MaxFailures int
+	// Window is the period in which failures are counted. A failure after a quiet
+	// Window starts counting again.
+	Window time.Duration
+	// BaseDelay is the delay after the first failure, it doubles with every further
+	// failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Duration is the time a lockout lasts.
+	Duration time.Duration
+}
+
+// Throttle returns the state after the given number of failures within Window, the last one
+// at now.
+func (p Policy) Throttle(failures int, now time.Time) entities.LoginLockout {
+	nextAttempt := now.Add(p.delay(failures))
+	state := entities.LoginLockout{Failures: failures, LastFailedAt: &now, NextAttemptAt: &nextAttempt}
+	if p.MaxFailures > 0 && failures >= p.MaxFailures {
+		lockedUntil := now.Add(p.Duration)
+		state.LockedUntil = &lockedUntil
+	}
+	return state
+}
+
+// delay returns the wait imposed after the given number of consecutive failures.
+func (p Policy) delay(failures int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// RetryAt returns the time before which no attempt is allowed. It returns false when an
+// attempt is allowed at now.
+func RetryAt(state entities.LoginLockout, now time.Time) (time.Time, bool) {
+	var retryAt time.Time
+	if state.LockedUntil != nil && state.LockedUntil.After(retryAt) {
+		retryAt = *state.LockedUntil
+	}
+	if state.NextAttemptAt != nil && state.NextAttemptAt.After(retryAt) {
+		retryAt = *state.NextAttemptAt
+	}
+	return retryAt, retryAt.After(now)
+}
@@ -0,0 +1,56 @@
+package lockout
+
+import (
+	"member/internal/entities"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicyThrottle checks the delays grow with every failure and the lockout starts at the
+// limit.
+func TestPolicyThrottle(t *testing.T) {
+	policy := Policy{
+		MaxFailures: 4,
+		Window:      15 * time.Minute,
+		BaseDelay:   time.Second,
+		MaxDelay:    5 * time.Second,
+		Duration:    30 * time.Minute,
+	}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	for i, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		state := policy.Throttle(i+1, now)
+		assert.Equal(t, i+1, state.Failures)
+		require.NotNil(t, state.LastFailedAt)
+		assert.Equal(t, now, *state.LastFailedAt)
+		require.NotNil(t, state.NextAttemptAt)
+		assert.Equal(t, now.Add(delay), *state.NextAttemptAt)
+		assert.Nil(t, state.LockedUntil)
+	}
+
+	state := policy.Throttle(4, now)
+	assert.Equal(t, 4, state.Failures)
+	assert.Equal(t, now.Add(5*time.Second), *state.NextAttemptAt)
+	require.NotNil(t, state.LockedUntil)
+	assert.Equal(t, now.Add(30*time.Minute), *state.LockedUntil)
+}
+
+// TestRetryAt checks attempts are refused until both the delay and the lockout are over.
+func TestRetryAt(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	_, blocked := RetryAt(entities.LoginLockout{}, now)
+	assert.False(t, blocked)
+
+	nextAttempt := now.Add(time.Second)
+	lockedUntil := now.Add(time.Minute)
+	retryAt, blocked := RetryAt(entities.LoginLockout{NextAttemptAt: &nextAttempt, LockedUntil: &lockedUntil}, now)
+	assert.True(t, blocked)
+	assert.Equal(t, lockedUntil, retryAt)
+
+	_, blocked = RetryAt(entities.LoginLockout{NextAttemptAt: &nextAttempt, LockedUntil: &lockedUntil}, lockedUntil)
+	assert.False(t, blocked)
+}
@@ -2,10 +2,19 @@ package middlewares
 
 import (
 	"database/sql"
+	"errors"
 	"member/internal/consts"
 	"member/internal/entities"
+	"member/internal/repo"
+	"member/internal/tenant"
+	"member/utilities"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gitlab.com/tuneverse/toolkit/core/logger"
+	"gitlab.com/tuneverse/toolkit/utils"
 )
 
 // Middlewares structure for storing middleware values
@@ -14,6 +23,59 @@ type Middlewares struct {
 	Repo *sql.DB
 }
 
+// adminRoles lists the roles that are allowed to act on any member.
+var adminRoles = []string{consts.RoleAdmin, consts.RolePartnerAdmin}
+
+// platformAdminRoles lists the roles that are allowed to change data shared by every partner,
+// such as the plan catalogue.
+var platformAdminRoles = []string{consts.RoleAdmin}
+
+// routePolicies is the declarative list of per-route authorization rules.
+// Routes are matched on the HTTP method and the gin route template. Any route
+// that is not listed here falls back to defaultRoutePolicy.
+var routePolicies = []entities.RoutePolicy{
+	{Method: http.MethodGet, Path: "/api/:version/health", Public: true},
+	{Method: http.MethodPost, Path: "/api/:version/members", Public: true},
+	{Method: http.MethodGet, Path: "/api/:version/members/oauth", Public: true},
+	// Email verification is proven by the token, resending requires no login so unverified
+	// members blocked from logging in can still ask for a new token.
+	{Method: http.MethodPost, Path: "/api/:version/members/verify-email", Public: true},
+	{Method: http.MethodPost, Path: "/api/:version/members/verify-email/resend", Public: true},
+	// Members who forgot their password ask for a reset key by email and prove it when changing it.
+	{Method: http.MethodGet, Path: "/api/:version/members/:member_id/reset-password", Public: true},
+	{Method: http.MethodPatch, Path: "/api/:version/members/:member_id/change-password", Public: true},
+	// The login challenge is proven by the challenge token and the second factor.
+	{Method: http.MethodPost, Path: "/api/:version/members/oauth/two-factor", Public: true},
+	// Only members themselves enroll an authenticator app, admins may disable it for them.
+	{Method: http.MethodPost, Path: "/api/:version/members/:member_id/two-factor", Self: true},
+	{Method: http.MethodPost, Path: "/api/:version/members/:member_id/two-factor/verify", Self: true},
+	// Payment gateways authenticate with the webhook signature.
+	{Method: http.MethodPost, Path: "/api/:version/payments/webhooks/:gateway", Public: true},
+	{Method: http.MethodGet, Path: "/api/:version/members", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/members/export", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/members/:member_id/unlock", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/members/:member_id/audit", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/members/bulk", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/members/stores/bulk", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/partners/:partner_id/stores", Roles: adminRoles},
+	// Plans are sold by every partner, only platform admins change the catalogue.
+	{Method: http.MethodGet, Path: "/api/:version/plans", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/plans", Roles: platformAdminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/plans/:plan_id/versions", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/plans/:plan_id/versions", Roles: platformAdminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/plans/:plan_id/retire", Roles: platformAdminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/promo-codes", Roles: adminRoles},
+	{Method: http.MethodPost, Path: "/api/:version/promo-codes", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/promo-codes/:promo_code_id/redemptions", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/members/bulk/:import_id", Roles: adminRoles},
+	{Method: http.MethodGet, Path: "/api/:version/partners/:partner_id/email-verification-policy", Roles: adminRoles},
+	{Method: http.MethodPut, Path: "/api/:version/partners/:partner_id/email-verification-policy", Roles: adminRoles},
+}
+
+// defaultRoutePolicy applies to every authenticated route without an explicit entry:
+// admins may act on any member, ordinary members only on their own :member_id.
+var defaultRoutePolicy = entities.RoutePolicy{Roles: adminRoles, Self: true}
+
 // NewMiddlewares
 func NewMiddlewares(cfg *entities.EnvConfig, repo *sql.DB) *Middlewares {
 	return &Middlewares{
@@ -22,50 +84,195 @@ func NewMiddlewares(cfg *entities.EnvConfig, repo *sql.DB) *Middlewares {
 	}
 }
 
-// Middleware function to get Partner ID from the header and store it in the context
+// PartnerID validates the partner ID in the partner_id header and scopes the request to it.
+// Requests naming an unknown partner are rejected. The JwtMiddleware rescopes authenticated
+// requests to the partner of their token.
 func (m Middlewares) PartnerID() gin.HandlerFunc {
+	memberRepo := repo.NewMemberRepo(m.Repo, m.Cfg)
+
 	return func(c *gin.Context) {
 		// Extract partner ID from the header.
-		partnerID := c.GetHeader("partner_id")
+		partnerID := strings.TrimSpace(c.GetHeader("partner_id"))
+		if partnerID != "" {
+			id, err := uuid.Parse(partnerID)
+			if err != nil {
+				logger.Log().WithContext(c).Errorf("PartnerID failed, invalid partner id %q", partnerID)
+				abortWithError(c, http.StatusBadRequest, consts.ValidationErr)
+				return
+			}
+			exists, err := memberRepo.CheckPartnerIDExists(c, id.String())
+			if err != nil {
+				logger.Log().WithContext(c).Errorf("PartnerID failed, CheckPartnerIDExists failed: %s", err.Error())
+				abortWithError(c, http.StatusInternalServerError, consts.InternalServerErr)
+				return
+			}
+			if !exists {
+				logger.Log().WithContext(c).Errorf("PartnerID failed, partner %s does not exist", id)
+				abortWithError(c, http.StatusBadRequest, consts.ValidationErr)
+				return
+			}
+			tenant.Scope(c, id)
+		}
 		// Set partner ID in the context.
 		c.Set(consts.ContextPartnerID, partnerID)
 		c.Next()
 	}
 }
 
-// func (m Middlewares) JwtMiddleware() gin.HandlerFunc {
-// 	return func(ctx *gin.Context) {
-
-// 		skipUrl := "/api/v1.0/members"
-// 		path := ctx.Request.URL.Path
-// 		method := ctx.Request.Method
-
-// 		// Check if the path and method match the conditions to skip middleware
-// 		if path == skipUrl && (method == "POST" || method == "GET") {
-// 			ctx.Next()
-// 			return
-// 		}
-// 		token := ctx.Request.Header.Get("Authorization")
-// 		isValid := utilities.ValidateJwtToken(token, m.Cfg.JwtKey)
-
-// 		_ = utilities.ValidateJwtToken(token, "jwtkey")
-
-// 		repo := repo.NewMemberRepo(m.Repo)
-// 		_, err := repo.Middleware(context.Background(), token)
-// 		if isValid.Valid && err == nil {
-// 			ctx.Set("memberId", isValid.MemberID)
-
-// 			ctx.Set("partner_id", isValid.PartnerID)
-// 			ctx.Set("roles", isValid.Roles)
-// 			ctx.Set("memberType", isValid.MemberType)
-// 			ctx.Set("partnerName", isValid.PartnerName)
-// 			ctx.Set("email", isValid.MemberEmail)
-
-// 			ctx.Next()
-
-// 		} // else {
-// 		// 	ctx.JSON(http.StatusUnauthorized, gin.H{"message": "invalid"})
-// 		// 	return
-// 		// }
-// 	}
-// }
+// JwtMiddleware authenticates the request using the bearer token in the Authorization header
+// and enforces the route policy for the matched route.
+//
+// The token signature is verified with the configured JWT key and the token must still be
+// active (not revoked) in the refresh_token table. On success the token claims are stored
+// in the context for the handlers.
+func (m Middlewares) JwtMiddleware() gin.HandlerFunc {
+	memberRepo := repo.NewMemberRepo(m.Repo, m.Cfg)
+
+	return func(ctx *gin.Context) {
+		policy := routePolicyFor(ctx.Request.Method, ctx.FullPath())
+
+		// Public routes do not need a token.
+		if policy.Public {
+			ctx.Next()
+			return
+		}
+
+		token := strings.TrimSpace(strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer "))
+		claims := utilities.ValidateJwtToken(token, m.Cfg.JwtKey)
+		if !claims.Valid {
+			logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, invalid token: %s", claims.ErrorMsg)
+			abortWithError(ctx, http.StatusUnauthorized, consts.UnauthorisedErr)
+			return
+		}
+
+		// Check the token has not been revoked.
+		_, err := memberRepo.Middleware(ctx, token)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, token revoked or unknown")
+				abortWithError(ctx, http.StatusUnauthorized, consts.UnauthorisedErr)
+				return
+			}
+			logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, revocation lookup failed: %s", err.Error())
+			abortWithError(ctx, http.StatusInternalServerError, consts.InternalServerErr)
+			return
+		}
+
+		partnerID, ok := resolvePartner(claims, ctx.GetString(consts.ContextPartnerID))
+		if !ok {
+			logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, member %s of partner %s may not act on partner %s",
+				claims.MemberID, claims.PartnerID, ctx.GetString(consts.ContextPartnerID))
+			abortWithError(ctx, http.StatusForbidden, consts.ForbiddenErr)
+			return
+		}
+		if partnerID != "" {
+			id, err := uuid.Parse(partnerID)
+			if err != nil {
+				logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, invalid partner id %q in token", partnerID)
+				abortWithError(ctx, http.StatusUnauthorized, consts.UnauthorisedErr)
+				return
+			}
+			tenant.Scope(ctx, id)
+		} else {
+			// Platform admins without a partner header act across partners.
+			tenant.ScopeAll(ctx)
+			partnerID = claims.PartnerID
+		}
+
+		ctx.Set(consts.ContextMemberID, claims.MemberID)
+		ctx.Set(consts.ContextPartnerID, partnerID)
+		ctx.Set(consts.ContextRoles, claims.Roles)
+		ctx.Set(consts.ContextMemberType, claims.MemberType)
+		ctx.Set(consts.ContextPartnerName, claims.PartnerName)
+		ctx.Set(consts.ContextMemberEmail, claims.MemberEmail)
+
+		if !isAuthorized(policy, claims, ctx.Param("member_id")) {
+			logger.Log().WithContext(ctx).Errorf("JwtMiddleware failed, member %s is not allowed to access %s %s",
+				claims.MemberID, ctx.Request.Method, ctx.FullPath())
+			abortWithError(ctx, http.StatusForbidden, consts.ForbiddenErr)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// routePolicyFor returns the policy registered for the method and route template,
+// or the default policy when the route is not listed.
+func routePolicyFor(method, path string) entities.RoutePolicy {
+	for _, policy := range routePolicies {
+		if policy.Method == method && policy.Path == path {
+			return policy
+		}
+	}
+	return defaultRoutePolicy
+}
+
+// isAuthorized reports whether the caller described by claims satisfies the policy.
+// A caller holding one of the policy roles is always allowed. Otherwise, when the policy
+// allows self access, the caller must be the member named in the route.
+func isAuthorized(policy entities.RoutePolicy, claims entities.JwtValidateResponse, memberID string) bool {
+	for _, role := range policy.Roles {
+		if hasRole(claims, role) {
+			return true
+		}
+	}
+
+	if policy.Self {
+		// Routes without a member in the path only need a valid token.
+		if memberID == "" {
+			return true
+		}
+		return strings.EqualFold(claims.MemberID, memberID)
+	}
+
+	return false
+}
+
+// resolvePartner returns the partner an authenticated request is scoped to. Callers are bound
+// to the partner of their token and may not name another partner in the header. Platform admins
+// act on the partner named in the header, or across partners without one, in which case the
+// returned partner is empty. ok is false when the request would cross partners.
+func resolvePartner(claims entities.JwtValidateResponse, headerPartnerID string) (string, bool) {
+	if hasRole(claims, consts.RoleAdmin) {
+		return headerPartnerID, true
+	}
+	if claims.PartnerID == "" {
+		return "", false
+	}
+	if headerPartnerID != "" && !strings.EqualFold(headerPartnerID, claims.PartnerID) {
+		return "", false
+	}
+	return claims.PartnerID, true
+}
+
+// hasRole reports whether the caller holds the role, either as member type or as access role.
+func hasRole(claims entities.JwtValidateResponse, role string) bool {
+	if claims.MemberType == role {
+		return true
+	}
+	for _, claimRole := range claims.Roles {
+		if claimRole == role {
+			return true
+		}
+	}
+	return false
+}
+
+// abortWithError stops the request with the localized error for errType,
+// falling back to a plain response when the error codes are not loaded.
+func abortWithError(ctx *gin.Context, status int, errType string) {
+	contextError, ok := utils.GetContext[map[string]any](ctx, consts.ContextErrorResponses)
+	if ok {
+		val, hasError, errorCode := utils.ParseFields(ctx, errType, "", contextError, "", "")
+		if hasError {
+			ctx.AbortWithStatusJSON(int(errorCode), val)
+			return
+		}
+	}
+	ctx.AbortWithStatusJSON(status, gin.H{
+		"errorCode": status,
+		"message":   http.StatusText(status),
+		"errors":    nil,
+	})
+}
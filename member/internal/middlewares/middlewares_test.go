@@ -0,0 +1,96 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRoutePolicyFor checks that listed routes resolve to their policy and
+// unlisted routes fall back to the default policy.
+func TestRoutePolicyFor(t *testing.T) {
+	assert.True(t, routePolicyFor(http.MethodPost, "/api/:version/members").Public)
+	assert.True(t, routePolicyFor(http.MethodGet, "/api/:version/members/oauth").Public)
+	assert.True(t, routePolicyFor(http.MethodPost, "/api/:version/payments/webhooks/:gateway").Public)
+	assert.True(t, routePolicyFor(http.MethodGet, "/api/:version/members/:member_id/reset-password").Public)
+	assert.True(t, routePolicyFor(http.MethodPatch, "/api/:version/members/:member_id/change-password").Public)
+	assert.False(t, routePolicyFor(http.MethodGet, "/api/:version/members").Public)
+	assert.Equal(t, defaultRoutePolicy, routePolicyFor(http.MethodDelete, "/api/:version/members/:member_id"))
+}
+
+// TestIsAuthorized checks the role and ownership rules.
+func TestIsAuthorized(t *testing.T) {
+	memberID := "614608f2-6538-4733-aded-96f902007254"
+	otherID := "0b9ad0a4-8e0c-4e6b-9d1e-2f1d1f1c7a10"
+
+	member := entities.JwtValidateResponse{Valid: true, MemberID: memberID, MemberType: "member"}
+	admin := entities.JwtValidateResponse{Valid: true, MemberID: otherID, Roles: []string{consts.RoleAdmin}}
+	partnerAdmin := entities.JwtValidateResponse{Valid: true, MemberID: otherID, Roles: []string{consts.RolePartnerAdmin}}
+
+	listPolicy := routePolicyFor(http.MethodGet, "/api/:version/members")
+	retirePolicy := routePolicyFor(http.MethodPost, "/api/:version/plans/:plan_id/retire")
+
+	tests := []struct {
+		name     string
+		policy   entities.RoutePolicy
+		claims   entities.JwtValidateResponse
+		memberID string
+		want     bool
+	}{
+		{"member updates own profile", defaultRoutePolicy, member, memberID, true},
+		{"member updates other profile", defaultRoutePolicy, member, otherID, false},
+		{"admin updates other profile", defaultRoutePolicy, admin, memberID, true},
+		{"member lists members", listPolicy, member, "", false},
+		{"admin lists members", listPolicy, admin, "", true},
+		{"partner admin lists members", listPolicy, partnerAdmin, "", true},
+		{"admin retires a plan", retirePolicy, admin, "", true},
+		{"partner admin retires a plan", retirePolicy, partnerAdmin, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isAuthorized(tt.policy, tt.claims, tt.memberID))
+		})
+	}
+}
+
+// TestResolvePartner checks callers are bound to the partner of their token and
+// cannot reach another partner through the partner_id header.
+func TestResolvePartner(t *testing.T) {
+	partnerID := "3b6c2a3e-6f0e-4f0b-8f59-7d4f0f6c1a01"
+	otherPartnerID := "9d1f5e2b-2c7a-4b8e-a3f1-5e6d7c8b9a02"
+
+	member := entities.JwtValidateResponse{Valid: true, PartnerID: partnerID, MemberType: "member"}
+	partnerAdmin := entities.JwtValidateResponse{Valid: true, PartnerID: partnerID, Roles: []string{consts.RolePartnerAdmin}}
+	admin := entities.JwtValidateResponse{Valid: true, PartnerID: partnerID, Roles: []string{consts.RoleAdmin}}
+	noPartner := entities.JwtValidateResponse{Valid: true, MemberType: "member"}
+
+	tests := []struct {
+		name   string
+		claims entities.JwtValidateResponse
+		header string
+		want   string
+		wantOK bool
+	}{
+		{"member without header", member, "", partnerID, true},
+		{"member with own partner", member, strings.ToUpper(partnerID), partnerID, true},
+		{"member with other partner", member, otherPartnerID, "", false},
+		{"partner admin with other partner", partnerAdmin, otherPartnerID, "", false},
+		{"token without partner", noPartner, "", "", false},
+		{"admin with other partner", admin, otherPartnerID, otherPartnerID, true},
+		{"admin without header", admin, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resolvePartner(tt.claims, tt.header)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"member/internal/entities"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MemoryNotifier keeps rendered notifications in memory. It is meant for tests.
+type MemoryNotifier struct {
+	mu       sync.Mutex
+	messages []entities.Message
+}
+
+// NewMemoryNotifier creates an empty in-memory notifier.
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+// Notify renders the notification and records it.
+func (m *MemoryNotifier) Notify(ctx context.Context, notification entities.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns the notifications recorded so far.
+func (m *MemoryNotifier) Messages() []entities.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]entities.Message(nil), m.messages...)
+}
+
+// FileNotifier appends rendered notifications to a file. It is used in local
+// environments where no SMTP server is configured.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileNotifier creates a notifier writing to the file at path.
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{
+		path: path,
+	}
+}
+
+// Notify renders the notification and appends it to the file.
+func (f *FileNotifier) Notify(ctx context.Context, notification entities.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().UTC().Format(time.RFC3339), message.To, message.Subject, message.Body)
+	return err
+}
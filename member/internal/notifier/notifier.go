@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"member/internal/consts"
+	"member/internal/entities"
+	"strings"
+	"sync"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Notifier delivers notifications to members.
+type Notifier interface {
+	// Notify renders the template of the notification event in the member's language
+	// and delivers it to the recipient.
+	Notify(ctx context.Context, notification entities.Notification) error
+}
+
+// NewNotifier returns the notifier configured for the environment. The SMTP notifier is
+// used when an SMTP host is configured, otherwise messages are written to the notification file.
+func NewNotifier(cfg *entities.EnvConfig) Notifier {
+	if cfg.Smtp.Host != "" {
+		return NewSMTPNotifier(cfg.Smtp)
+	}
+	return NewFileNotifier(cfg.NotificationFile)
+}
+
+// templates caches parsed templates by "<language>/<event>".
+var templates sync.Map
+
+// Render renders the subject and body of the notification. The template is looked up in
+// templates/<language>/<event>.tmpl and falls back to the default language when the member's
+// language has no translation.
+func Render(notification entities.Notification) (entities.Message, error) {
+	if notification.To == "" {
+		return entities.Message{}, fmt.Errorf("notification %s has no recipient", notification.Event)
+	}
+
+	tmpl, err := lookupTemplate(notification.Language, notification.Event)
+	if err != nil {
+		return entities.Message{}, err
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", notification.Data); err != nil {
+		return entities.Message{}, fmt.Errorf("rendering subject of %s: %w", notification.Event, err)
+	}
+	if err := tmpl.ExecuteTemplate(&body, "body", notification.Data); err != nil {
+		return entities.Message{}, fmt.Errorf("rendering body of %s: %w", notification.Event, err)
+	}
+
+	return entities.Message{
+		To:      notification.To,
+		Subject: strings.TrimSpace(html.UnescapeString(subject.String())),
+		Body:    strings.TrimSpace(body.String()),
+	}, nil
+}
+
+// lookupTemplate returns the parsed template for the event in the given language,
+// falling back to consts.DefaultLanguage.
+func lookupTemplate(language, event string) (*template.Template, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	for _, lang := range []string{language, consts.DefaultLanguage} {
+		if lang == "" {
+			continue
+		}
+		key := lang + "/" + event
+		if cached, ok := templates.Load(key); ok {
+			return cached.(*template.Template), nil
+		}
+		path := "templates/" + key + ".tmpl"
+		if _, err := fs.Stat(templateFS, path); err != nil {
+			continue
+		}
+		tmpl, err := template.ParseFS(templateFS, path)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template %s: %w", path, err)
+		}
+		templates.Store(key, tmpl)
+		return tmpl, nil
+	}
+	return nil, fmt.Errorf("no template found for notification %s", event)
+}
@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/mail"
+	"testing"
+
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRender checks the localized templates and the fallback to the default language.
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		subject  string
+	}{
+		{"default language", "", "Reset your password"},
+		{"translated language", "es", "Restablece tu contraseña"},
+		{"untranslated language falls back", "de", "Reset your password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := Render(entities.Notification{
+				Event:    consts.EventPasswordReset,
+				To:       "john.doe@example.com",
+				Language: tt.language,
+				Data:     map[string]interface{}{"Name": "John", "Key": "abc123", "ExpiresIn": 50},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.subject, message.Subject)
+			assert.Contains(t, message.Body, "abc123")
+		})
+	}
+}
+
+// TestRenderErrors checks notifications that cannot be rendered.
+func TestRenderErrors(t *testing.T) {
+	_, err := Render(entities.Notification{Event: consts.EventPasswordReset})
+	assert.Error(t, err)
+
+	_, err = Render(entities.Notification{Event: "unknown_event", To: "john.doe@example.com"})
+	assert.Error(t, err)
+}
+
+// TestMemoryNotifier checks that sent notifications are recorded.
+func TestMemoryNotifier(t *testing.T) {
+	memoryNotifier := NewMemoryNotifier()
+	err := memoryNotifier.Notify(context.Background(), entities.Notification{
+		Event: consts.EventMemberRegistered,
+		To:    "john.doe@example.com",
+		Data:  map[string]interface{}{"Name": "John", "Email": "john.doe@example.com"},
+	})
+	require.NoError(t, err)
+	require.Len(t, memoryNotifier.Messages(), 1)
+	assert.Equal(t, "Welcome to Tuneverse", memoryNotifier.Messages()[0].Subject)
+}
+
+// TestComposeMail checks localized subjects are encoded into a valid header.
+func TestComposeMail(t *testing.T) {
+	raw := composeMail("noreply@tuneverse.com", entities.Message{
+		To:      "juan@example.com",
+		Subject: "Restablece tu contraseña",
+		Body:    "<p>Hola</p>",
+	})
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.NotContains(t, msg.Header.Get("Subject"), "ñ")
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Restablece tu contraseña", subject)
+	assert.Equal(t, "1.0", msg.Header.Get("MIME-Version"))
+	assert.Equal(t, "text/html; charset=UTF-8", msg.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(msg.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Hola</p>", string(body))
+}
@@ -0,0 +1,54 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"member/internal/entities"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier sends notifications as HTML emails through an SMTP server.
+type SMTPNotifier struct {
+	cfg entities.SMTPConfig
+}
+
+// NewSMTPNotifier creates a notifier sending mails through the configured SMTP server.
+func NewSMTPNotifier(cfg entities.SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		cfg: cfg,
+	}
+}
+
+// Notify renders the notification and sends it to the recipient.
+func (s *SMTPNotifier) Notify(ctx context.Context, notification entities.Notification) error {
+	message, err := Render(notification)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{message.To}, composeMail(s.cfg.From, message)); err != nil {
+		return fmt.Errorf("sending %s notification: %w", notification.Event, err)
+	}
+	return nil
+}
+
+// composeMail builds the HTML mail of the message. Headers only carry ASCII, the subject of
+// localized templates is encoded.
+func composeMail(from string, message entities.Message) []byte {
+	var mail strings.Builder
+	fmt.Fprintf(&mail, "From: %s\r\n", from)
+	fmt.Fprintf(&mail, "To: %s\r\n", message.To)
+	fmt.Fprintf(&mail, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	mail.WriteString("MIME-Version: 1.0\r\n")
+	mail.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	mail.WriteString(message.Body)
+	return []byte(mail.String())
+}
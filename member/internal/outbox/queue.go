@@ -0,0 +1,37 @@
+package outbox
+
+import (
+	"fmt"
+	"member/internal/consts"
+	"member/internal/entities"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"gitlab.com/tuneverse/toolkit/core/awsmanager"
+	"gitlab.com/tuneverse/toolkit/core/queue"
+)
+
+// NewQueue connects to the queue the outbox events are published to.
+func NewQueue(cfg entities.OutboxConfig) (queue.Queue, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case consts.QueueProviderRabbitMQ:
+		return queue.NewRabbitMQQueue(&queue.RabbitMQConfig{
+			URL:     cfg.URL,
+			Name:    cfg.QueueName,
+			Durable: true,
+		})
+	case consts.QueueProviderSQS:
+		awsConfig, err := awsmanager.CreateAwsSession(awsmanager.WithRegion(cfg.Region))
+		if err != nil {
+			return nil, err
+		}
+		return queue.NewSQSQueue(awsConfig, &queue.SQSConfig{
+			QueueInfo: &sqs.CreateQueueInput{
+				QueueName: aws.String(cfg.QueueName),
+			},
+		})
+	default:
+		return nil, fmt.Errorf("unknown queue provider %q", cfg.Provider)
+	}
+}
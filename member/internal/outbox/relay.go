@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"member/internal/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.com/tuneverse/toolkit/core/logger"
+	"gitlab.com/tuneverse/toolkit/core/queue"
+)
+
+// Store is the outbox the relay publishes from.
+type Store interface {
+	// ClaimOutboxEvents returns up to limit events due for publishing and hides them from
+	// other relays for lease.
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error)
+	// MarkOutboxEventPublished records that the event has been published.
+	MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID) error
+	// MarkOutboxEventFailed records a failed attempt, the event is retried after retryIn.
+	MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryIn time.Duration, reason string) error
+}
+
+// Relay publishes the outbox events to a queue. Events are delivered at least once: an event whose
+// publication succeeded but could not be marked as published is sent again, consumers deduplicate
+// on the event ID.
+type Relay struct {
+	store Store
+	queue queue.Queue
+	cfg   entities.OutboxConfig
+}
+
+// NewRelay creates a relay publishing the events of store to q.
+func NewRelay(store Store, q queue.Queue, cfg entities.OutboxConfig) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &Relay{
+		store: store,
+		queue: q,
+		cfg:   cfg,
+	}
+}
+
+// Publish publishes the due outbox events batch by batch until none is left. Failed events are
+// rescheduled with an exponential backoff and do not stop the other events from being published.
+func (r *Relay) Publish(ctx context.Context) error {
+	for {
+		events, err := r.store.ClaimOutboxEvents(ctx, r.cfg.BatchSize, r.cfg.Lease)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := r.publish(ctx, event); err != nil {
+				logger.Log().WithContext(ctx).Errorf("Failed to publish outbox event %s (%s), attempt %d: %s",
+					event.ID, event.Type, event.Attempts+1, err.Error())
+				retryIn := Backoff(event.Attempts+1, r.cfg.RetryBase, r.cfg.RetryMax)
+				if markErr := r.store.MarkOutboxEventFailed(ctx, event.ID, retryIn, err.Error()); markErr != nil {
+					return markErr
+				}
+				continue
+			}
+			if err := r.store.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < r.cfg.BatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// publish sends an event to the queue. The message body is the event itself, with its type
+// and schema version next to the data.
+func (r *Relay) publish(ctx context.Context, event entities.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	message, err := r.queue.ComposeMessage(ctx, body)
+	if err != nil {
+		return err
+	}
+	return r.queue.Send(ctx, message)
+}
+
+// Backoff returns the delay before the given attempt to publish an event: base doubled on every
+// failed attempt, up to max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	return min(delay, max)
+}
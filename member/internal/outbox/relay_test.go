@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/tuneverse/toolkit/core/logger"
+)
+
+func init() {
+	logger.InitLogger(&logger.ClientOptions{
+		Service:  consts.AppName,
+		LogLevel: "info",
+	})
+}
+
+// memoryStore is an outbox kept in memory, claimed events are not hidden from later claims.
+type memoryStore struct {
+	events    []entities.OutboxEvent
+	published []uuid.UUID
+	retries   map[uuid.UUID]time.Duration
+}
+
+func (s *memoryStore) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	var due []entities.OutboxEvent
+	for _, event := range s.events {
+		if _, retried := s.retries[event.ID]; retried {
+			continue
+		}
+		if len(due) == limit {
+			break
+		}
+		due = append(due, event)
+	}
+	s.events = s.events[len(due):]
+	return due, nil
+}
+
+func (s *memoryStore) MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID) error {
+	s.published = append(s.published, eventID)
+	return nil
+}
+
+func (s *memoryStore) MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryIn time.Duration, reason string) error {
+	s.retries[eventID] = retryIn
+	return nil
+}
+
+// memoryQueue records the sent messages and rejects the bodies listed in fail.
+type memoryQueue struct {
+	sent [][]byte
+	fail map[string]bool
+}
+
+func (q *memoryQueue) ComposeMessage(ctx context.Context, message []byte) (interface{}, error) {
+	return message, nil
+}
+
+func (q *memoryQueue) Send(ctx context.Context, message interface{}) error {
+	body := message.([]byte)
+	var event entities.OutboxEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+	if q.fail[event.AggregateID] {
+		return errors.New("queue unavailable")
+	}
+	q.sent = append(q.sent, body)
+	return nil
+}
+
+func (q *memoryQueue) Receive(ctx context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+func (q *memoryQueue) Delete(ctx context.Context, receiptHandle string) error {
+	return nil
+}
+
+func (q *memoryQueue) Close() error {
+	return nil
+}
+
+// TestRelayPublish checks that events are published in batches and failed ones are retried later.
+func TestRelayPublish(t *testing.T) {
+	store := &memoryStore{retries: map[uuid.UUID]time.Duration{}}
+	for i := 0; i < 5; i++ {
+		store.events = append(store.events, entities.OutboxEvent{
+			ID:            uuid.New(),
+			Type:          consts.DomainEventMemberRegistered,
+			SchemaVersion: consts.DomainEventSchemaVersions[consts.DomainEventMemberRegistered],
+			AggregateType: consts.AggregateMember,
+			AggregateID:   uuid.NewString(),
+			Data:          json.RawMessage(`{"member_id":"x"}`),
+		})
+	}
+	failing := store.events[3]
+	failing.Attempts = 2
+	store.events[3] = failing
+
+	q := &memoryQueue{fail: map[string]bool{failing.AggregateID: true}}
+	relay := NewRelay(store, q, entities.OutboxConfig{
+		BatchSize: 2,
+		RetryBase: time.Second,
+		RetryMax:  time.Minute,
+	})
+
+	require.NoError(t, relay.Publish(context.Background()))
+
+	assert.Len(t, store.published, 4)
+	assert.NotContains(t, store.published, failing.ID)
+	assert.Equal(t, 4*time.Second, store.retries[failing.ID])
+	require.Len(t, q.sent, 4)
+
+	var published entities.OutboxEvent
+	require.NoError(t, json.Unmarshal(q.sent[0], &published))
+	assert.Equal(t, consts.DomainEventMemberRegistered, published.Type)
+	assert.Equal(t, 1, published.SchemaVersion)
+	assert.JSONEq(t, `{"member_id":"x"}`, string(published.Data))
+}
+
+// TestBackoff checks that the retry delay doubles per attempt up to the maximum.
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 5 * time.Second},
+		{attempt: 2, want: 10 * time.Second},
+		{attempt: 4, want: 40 * time.Second},
+		{attempt: 8, want: time.Minute},
+		{attempt: 100, want: time.Minute},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Backoff(tt.attempt, 5*time.Second, time.Minute), "attempt %d", tt.attempt)
+	}
+}
@@ -0,0 +1,92 @@
+package payment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"member/internal/consts"
+	"member/internal/entities"
+	"strings"
+)
+
+// Amounts whose cents select the outcome of the fake gateway.
+const (
+	// FakeDeclineCents makes the authorization fail, e.g. 10.01.
+	FakeDeclineCents = 1
+	// FakeCaptureFailureCents makes the capture fail, e.g. 10.02.
+	FakeCaptureFailureCents = 2
+	// FakeRefundFailureCents makes the refund fail, e.g. 10.03.
+	FakeRefundFailureCents = 3
+)
+
+// FakeGateway is a deterministic gateway for local environments and tests. It never
+// reaches a provider, the outcome of an operation only depends on the cents of the amount
+// and the identifiers it returns only depend on the request.
+type FakeGateway struct{}
+
+// NewFakeGateway creates the fake gateway.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{}
+}
+
+// Name returns the gateway name.
+func (f *FakeGateway) Name() string {
+	return "fake"
+}
+
+// Authorize authorizes the request unless its cents are FakeDeclineCents.
+func (f *FakeGateway) Authorize(ctx context.Context, request entities.PaymentRequest) (entities.PaymentResult, error) {
+	if cents(request.Amount) == FakeDeclineCents {
+		return f.result(request, "", consts.PaymentStatusFailed, "card_declined"), nil
+	}
+	return f.result(request, "auth", consts.PaymentStatusAuthorized, ""), nil
+}
+
+// Capture captures the authorization unless the cents of the request are FakeCaptureFailureCents.
+func (f *FakeGateway) Capture(ctx context.Context, request entities.PaymentRequest, authorizationID string) (entities.PaymentResult, error) {
+	if !strings.HasPrefix(authorizationID, "fake_auth_") {
+		return entities.PaymentResult{}, fmt.Errorf("unknown authorization %q", authorizationID)
+	}
+	if cents(request.Amount) == FakeCaptureFailureCents {
+		return f.result(request, "", consts.PaymentStatusFailed, "capture_failed"), nil
+	}
+	return f.result(request, "pay", consts.PaymentStatusCaptured, ""), nil
+}
+
+// Refund refunds the payment unless the cents of the request are FakeRefundFailureCents.
+func (f *FakeGateway) Refund(ctx context.Context, request entities.PaymentRequest, paymentID string) (entities.PaymentResult, error) {
+	if !strings.HasPrefix(paymentID, "fake_pay_") {
+		return entities.PaymentResult{}, fmt.Errorf("unknown payment %q", paymentID)
+	}
+	if cents(request.Amount) == FakeRefundFailureCents {
+		return f.result(request, "", consts.PaymentStatusFailed, "refund_failed"), nil
+	}
+	return f.result(request, "refund", consts.PaymentStatusRefunded, ""), nil
+}
+
+// VerifyWebhook checks the HMAC-SHA256 signature of the payload.
+func (f *FakeGateway) VerifyWebhook(payload []byte, signature string, secret string) error {
+	return VerifyHMACSignature(payload, signature, secret)
+}
+
+// result builds the result of an operation, kind prefixes the deterministic identifier.
+func (f *FakeGateway) result(request entities.PaymentRequest, kind, status, reason string) entities.PaymentResult {
+	result := entities.PaymentResult{
+		Status:        status,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+		FailureReason: reason,
+	}
+	if kind != "" {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%.2f|%s", kind, request.Reference, request.Amount, request.Currency)))
+		result.ID = "fake_" + kind + "_" + hex.EncodeToString(sum[:8])
+	}
+	return result
+}
+
+// cents returns the cents of amount.
+func cents(amount float64) int {
+	return int(math.Round(amount*100)) % 100
+}
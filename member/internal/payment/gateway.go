@@ -0,0 +1,94 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"member/internal/entities"
+	"strings"
+)
+
+// ErrInvalidSignature is returned when a webhook signature does not match its payload.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Gateway is a payment provider used to charge members for their subscriptions.
+// Declined operations are reported through the result status, errors are reserved
+// for failures to reach or understand the provider.
+type Gateway interface {
+	// Name returns the gateway name, as stored in the partner payment details.
+	Name() string
+	// Authorize reserves the amount of the request on the member's payment method.
+	Authorize(ctx context.Context, request entities.PaymentRequest) (entities.PaymentResult, error)
+	// Capture collects a previously authorized payment.
+	Capture(ctx context.Context, request entities.PaymentRequest, authorizationID string) (entities.PaymentResult, error)
+	// Refund returns the amount of the request on a captured payment.
+	Refund(ctx context.Context, request entities.PaymentRequest, paymentID string) (entities.PaymentResult, error)
+	// VerifyWebhook checks the signature of a webhook payload sent by the gateway.
+	VerifyWebhook(payload []byte, signature string, secret string) error
+}
+
+// Registry resolves the gateway configured for a partner.
+type Registry struct {
+	gateways map[string]Gateway
+	fallback Gateway
+}
+
+// NewRegistry creates a registry of the given gateways.
+func NewRegistry(gateways ...Gateway) *Registry {
+	registry := &Registry{
+		gateways: map[string]Gateway{},
+	}
+	for _, gateway := range gateways {
+		registry.gateways[strings.ToLower(gateway.Name())] = gateway
+	}
+	return registry
+}
+
+// WithFallback makes the registry resolve unknown gateway names to gateway.
+// It is used to run every partner gateway against the fake gateway in local environments.
+func (r *Registry) WithFallback(gateway Gateway) *Registry {
+	r.fallback = gateway
+	return r
+}
+
+// Empty reports whether the registry resolves no gateway at all.
+func (r *Registry) Empty() bool {
+	return len(r.gateways) == 0 && r.fallback == nil
+}
+
+// Get returns the gateway registered under name.
+func (r *Registry) Get(name string) (Gateway, error) {
+	if gateway, ok := r.gateways[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return gateway, nil
+	}
+	if r.fallback != nil {
+		return r.fallback, nil
+	}
+	return nil, fmt.Errorf("payment gateway %q is not supported", name)
+}
+
+// VerifyHMACSignature checks that signature is the hex encoded HMAC-SHA256 of payload with secret.
+// A "sha256=" prefix on the signature is accepted.
+func VerifyHMACSignature(payload []byte, signature string, secret string) error {
+	if secret == "" {
+		return errors.New("webhook secret is empty")
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal(expected, SignHMAC(payload, secret)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignHMAC returns the HMAC-SHA256 of payload with secret.
+func SignHMAC(payload []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,81 @@
+package payment
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"member/internal/consts"
+	"member/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFakeGateway checks the deterministic outcomes of the fake gateway.
+func TestFakeGateway(t *testing.T) {
+	ctx := context.Background()
+	gateway := NewFakeGateway()
+	request := entities.PaymentRequest{Reference: "sub-1", Amount: 10.00, Currency: "USD"}
+
+	auth, err := gateway.Authorize(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, consts.PaymentStatusAuthorized, auth.Status)
+
+	again, err := gateway.Authorize(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, auth.ID, again.ID)
+
+	captured, err := gateway.Capture(ctx, request, auth.ID)
+	require.NoError(t, err)
+	assert.Equal(t, consts.PaymentStatusCaptured, captured.Status)
+
+	refunded, err := gateway.Refund(ctx, request, captured.ID)
+	require.NoError(t, err)
+	assert.Equal(t, consts.PaymentStatusRefunded, refunded.Status)
+
+	declined, err := gateway.Authorize(ctx, entities.PaymentRequest{Reference: "sub-1", Amount: 10.01})
+	require.NoError(t, err)
+	assert.Equal(t, consts.PaymentStatusFailed, declined.Status)
+	assert.Equal(t, "card_declined", declined.FailureReason)
+
+	failed, err := gateway.Capture(ctx, entities.PaymentRequest{Reference: "sub-1", Amount: 10.02}, auth.ID)
+	require.NoError(t, err)
+	assert.Equal(t, consts.PaymentStatusFailed, failed.Status)
+
+	_, err = gateway.Capture(ctx, request, "unknown")
+	assert.Error(t, err)
+}
+
+// TestRegistry checks gateway resolution and the fallback.
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(NewFakeGateway())
+
+	gateway, err := registry.Get("Fake")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", gateway.Name())
+
+	_, err = registry.Get("paypal")
+	assert.Error(t, err)
+
+	gateway, err = registry.WithFallback(NewFakeGateway()).Get("paypal")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", gateway.Name())
+
+	assert.False(t, registry.Empty())
+	assert.True(t, NewRegistry().Empty())
+	assert.False(t, NewRegistry().WithFallback(NewFakeGateway()).Empty())
+}
+
+// TestVerifyHMACSignature checks webhook signature verification.
+func TestVerifyHMACSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	signature := hex.EncodeToString(SignHMAC(payload, "secret"))
+
+	assert.NoError(t, VerifyHMACSignature(payload, signature, "secret"))
+	assert.NoError(t, VerifyHMACSignature(payload, "sha256="+signature, "secret"))
+	assert.ErrorIs(t, VerifyHMACSignature(payload, signature, "other"), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyHMACSignature([]byte(`{"id":"evt_2"}`), signature, "secret"), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyHMACSignature(payload, "not-hex", "secret"), ErrInvalidSignature)
+	assert.Error(t, VerifyHMACSignature(payload, signature, ""))
+}
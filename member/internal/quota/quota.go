@@ -0,0 +1,61 @@
+// Package quota computes the capacity left on member subscriptions for the products, tracks
+// and artists their plan allows. Capacity is taken by the items added to the subscription
+// and by the reservations other services hold while they create items, so concurrent
+// creations cannot exceed the limits of the plan.
+package quota
+
+import (
+	"member/internal/consts"
+	"member/internal/entities"
+	"slices"
+)
+
+// Resources lists the resources limited by subscription plans.
+var Resources = []string{consts.QuotaProducts, consts.QuotaTracks, consts.QuotaArtists}
+
+// IsResource reports whether the resource is limited by subscription plans.
+func IsResource(resource string) bool {
+	return slices.Contains(Resources, resource)
+}
+
+// Available returns the capacity left of a resource, never negative: plans changed to lower
+// limits leave subscriptions over their limit.
+func Available(usage entities.QuotaUsage) int {
+	return max(usage.Limit-usage.Used-usage.Reserved, 0)
+}
+
+// Allows reports whether quantity more items of the resource fit in the capacity left.
+func Allows(usage entities.QuotaUsage, quantity int) bool {
+	return quantity <= Available(usage)
+}
+
+// Usages returns the usage of every resource, in the order of Resources, with the capacity left.
+func Usages(limits, used, reserved map[string]int) []entities.QuotaUsage {
+	usages := make([]entities.QuotaUsage, 0, len(Resources))
+	for _, resource := range Resources {
+		usage := entities.QuotaUsage{
+			Resource: resource,
+			Limit:    limits[resource],
+			Used:     used[resource],
+			Reserved: reserved[resource],
+		}
+		usage.Available = Available(usage)
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+// Find returns the usage of a resource.
+func Find(usages []entities.QuotaUsage, resource string) (entities.QuotaUsage, bool) {
+	for _, usage := range usages {
+		if usage.Resource == resource {
+			return usage, true
+		}
+	}
+	return entities.QuotaUsage{Resource: resource}, false
+}
+
+// Open reports whether items can be added to a subscription in the status.
+func Open(status string) bool {
+	return slices.Contains(consts.QuotaOpenStatuses, status)
+}
@@ -0,0 +1,46 @@
+package quota
+
+import (
+	"member/internal/consts"
+	"member/internal/entities"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUsages checks the capacity left accounts for items added and reserved.
+func TestUsages(t *testing.T) {
+	usages := Usages(
+		map[string]int{consts.QuotaProducts: 5, consts.QuotaTracks: 50, consts.QuotaArtists: 5},
+		map[string]int{consts.QuotaProducts: 2, consts.QuotaTracks: 40, consts.QuotaArtists: 7},
+		map[string]int{consts.QuotaTracks: 6},
+	)
+
+	assert.Equal(t, []entities.QuotaUsage{
+		{Resource: consts.QuotaProducts, Limit: 5, Used: 2, Available: 3},
+		{Resource: consts.QuotaTracks, Limit: 50, Used: 40, Reserved: 6, Available: 4},
+		{Resource: consts.QuotaArtists, Limit: 5, Used: 7, Available: 0},
+	}, usages)
+
+	tracks, ok := Find(usages, consts.QuotaTracks)
+	assert.True(t, ok)
+	assert.True(t, Allows(tracks, 4))
+	assert.False(t, Allows(tracks, 5))
+
+	artists, _ := Find(usages, consts.QuotaArtists)
+	assert.True(t, Allows(artists, 0))
+	assert.False(t, Allows(artists, 1))
+
+	_, ok = Find(usages, "videos")
+	assert.False(t, ok)
+	assert.False(t, IsResource("videos"))
+	assert.True(t, IsResource(consts.QuotaArtists))
+}
+
+// TestOpen checks items can only be added to running subscriptions.
+func TestOpen(t *testing.T) {
+	assert.True(t, Open(consts.SubscriptionStatusActive))
+	assert.True(t, Open(consts.SubscriptionStatusInGrace))
+	assert.False(t, Open(consts.SubscriptionStatusExpired))
+	assert.False(t, Open(consts.SubscriptionStatusPaymentFailed))
+}
@@ -0,0 +1,24 @@
+// Package renewal schedules the retries of failed automatic subscription renewals. Retries follow
+// a dunning schedule of delays counted from the expiration date and stop when the grace period of
+// the subscription ends.
+package renewal
+
+import (
+	"slices"
+	"time"
+)
+
+// Next returns when a renewal that failed at now is retried: at the first step of the schedule
+// falling after now and before the end of the grace period. It returns false when the schedule
+// is exhausted.
+func Next(schedule []time.Duration, expiration, graceEnd, now time.Time) (time.Time, bool) {
+	steps := slices.Clone(schedule)
+	slices.Sort(steps)
+	for _, step := range steps {
+		next := expiration.Add(step)
+		if next.After(now) && next.Before(graceEnd) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
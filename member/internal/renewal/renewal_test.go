@@ -0,0 +1,38 @@
+package renewal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNext checks retries follow the schedule from the expiration date within the grace period.
+func TestNext(t *testing.T) {
+	day := 24 * time.Hour
+	schedule := []time.Duration{7 * day, day, 3 * day}
+	expiration := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	graceEnd := expiration.Add(14 * day)
+
+	// A renewal failing before expiration is retried on the first step
+	next, ok := Next(schedule, expiration, graceEnd, expiration.Add(-2*day))
+	assert.True(t, ok)
+	assert.Equal(t, expiration.Add(day), next)
+
+	next, ok = Next(schedule, expiration, graceEnd, expiration.Add(day+time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, expiration.Add(3*day), next)
+
+	next, ok = Next(schedule, expiration, graceEnd, expiration.Add(3*day))
+	assert.True(t, ok)
+	assert.Equal(t, expiration.Add(7*day), next)
+
+	_, ok = Next(schedule, expiration, graceEnd, expiration.Add(7*day))
+	assert.False(t, ok)
+
+	// Steps past the grace period are skipped
+	_, ok = Next(schedule, expiration, expiration.Add(5*day), expiration.Add(3*day))
+	assert.False(t, ok)
+	_, ok = Next(nil, expiration, graceEnd, expiration)
+	assert.False(t, ok)
+}
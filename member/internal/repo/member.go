@@ -2,22 +2,34 @@ package repo
 
 import (
 	"context"
+	cryptoRand "crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"member/internal/entities"
+	"member/internal/hashing"
+	"member/internal/lockout"
+	"member/internal/quota"
+	"member/internal/renewal"
 	"member/utilities"
+	"slices"
 	"strings"
 	"time"
 
+	"member/internal/audit"
 	"member/internal/consts"
+	"member/internal/tenant"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"gitlab.com/tuneverse/toolkit/core/logger"
 	log "gitlab.com/tuneverse/toolkit/core/logger"
@@ -28,21 +40,22 @@ import (
 
 // MemberRepo defines a repository for member-related operations.
 type MemberRepo struct {
-	db  *sql.DB
-	Cfg *entities.EnvConfig
+	db     *sql.DB
+	Cfg    *entities.EnvConfig
+	hasher hashing.PasswordHasher
 }
 
 // MemberRepoImply represents the interface for interacting with the Member repository.
 type MemberRepoImply interface {
 	// Member Registration and Profile Management
-	RegisterMember(ctx context.Context, args entities.Member, partnerID string) error
+	RegisterMember(ctx context.Context, args entities.Member, partnerID string) (uuid.UUID, error)
 	IsMemberExists(memberID uuid.UUID, ctx context.Context) (bool, error)
-	UpdateMember(ctx context.Context, memberID uuid.UUID, args entities.Member) error
+	UpdateMember(ctx context.Context, memberID uuid.UUID, args entities.Member, version int64) (int64, error)
 	ViewMemberProfile(memberId uuid.UUID, ctx context.Context) (entities.MemberProfile, error)
 	GetMemberByID(ctx context.Context, memberID uuid.UUID) (entities.MemberByID, error)
 	ViewMembers(ctx context.Context, params entities.Params) ([]entities.ViewMembers, error)
 	GetBasicMemberDetailsByEmail(partnerID string, args entities.MemberPayload, ctx context.Context) (entities.BasicMemberData, error)
-	DeleteMember(ctx *gin.Context, MemberID uuid.UUID) error
+	DeleteMember(ctx *gin.Context, MemberID uuid.UUID, version int64) error
 	IsDeleted(ctx *gin.Context, MemberID uuid.UUID) (bool, error)
 	IsActive(ctx *gin.Context, MemberID uuid.UUID) (bool, error)
 	IsMemberExist(context.Context, uuid.UUID) (bool, error)
@@ -59,22 +72,53 @@ type MemberRepoImply interface {
 	CheckStoreNameExistsAndReturnIDs(ctx context.Context, storeNames []string) (bool, []uuid.UUID, error)
 	CheckPartnerStores(ctx context.Context, partnerID uuid.UUID, storeIDs []uuid.UUID) (bool, error)
 	StorePartnerRelation(ctx context.Context, partnerID uuid.UUID, relatedStoreIDs []uuid.UUID) (map[string]bool, error)
+	GetMemberStores(ctx context.Context, memberID uuid.UUID) ([]entities.MemberStore, error)
+	UpdateMemberStore(ctx context.Context, memberID uuid.UUID, storeID uuid.UUID, update entities.MemberStoreUpdate) error
+	RemoveMemberStores(ctx context.Context, memberID uuid.UUID, storeIDs []uuid.UUID) (int64, error)
+	ReorderMemberStores(ctx context.Context, memberID uuid.UUID, storeIDs []uuid.UUID) error
+	GetPartnerStores(ctx context.Context, partnerID uuid.UUID) ([]entities.PartnerStore, error)
 
 	// Password and Security
 
-	UpdatePassword(ctx context.Context, memberID uuid.UUID, key string, newPasswordHash string) error
+	UpdatePassword(ctx context.Context, memberID uuid.UUID, key string, newPassword string) error
 	GetPasswordHash(ctx context.Context, memberID uuid.UUID) (string, error)
-	InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, error)
+	InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, time.Time, error)
 	CheckResetKeyMatch(ctx context.Context, memberID uuid.UUID, key string) (bool, error)
+	ClaimResetKeyAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) (bool, error)
+	ReleaseResetKeyAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) error
+	GetMemberIDByEmail(ctx context.Context, partnerID, email string) (uuid.UUID, error)
+	GetLoginLockouts(ctx context.Context, memberID uuid.UUID, ipAddress string) (entities.LoginLockout, entities.LoginLockout, error)
+	ClaimLoginAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) (time.Time, bool, error)
+	RecordLoginFailure(ctx context.Context, memberID uuid.UUID, ipAddress string) error
+	ReleaseLoginAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) error
+	ClearLoginLockout(ctx context.Context, memberID uuid.UUID) error
+
+	// Two-Factor Authentication
+
+	GetBasicMemberDetailsByID(ctx context.Context, memberID uuid.UUID) (entities.BasicMemberData, error)
+	GetTwoFactor(ctx context.Context, memberID uuid.UUID) (entities.TwoFactor, error)
+	SaveTwoFactorSecret(ctx context.Context, memberID uuid.UUID, secret string) error
+	EnableTwoFactor(ctx context.Context, memberID uuid.UUID, step int64, recoveryCodeHashes []string) (bool, error)
+	UseTwoFactorStep(ctx context.Context, memberID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, memberID uuid.UUID, codeHash string) (bool, error)
+	DisableTwoFactor(ctx context.Context, memberID uuid.UUID) error
+
+	// Email Verification
+
+	GetEmailVerification(ctx context.Context, memberID uuid.UUID) (entities.EmailVerification, error)
+	GetEmailVerificationByEmail(ctx context.Context, partnerID, email string) (entities.EmailVerification, error)
+	MarkEmailVerified(ctx context.Context, memberID uuid.UUID, email string) error
+	GetEmailVerificationPolicy(ctx context.Context, partnerID uuid.UUID) (entities.EmailVerificationPolicy, error)
+	UpdateEmailVerificationPolicy(ctx context.Context, partnerID uuid.UUID, policy entities.EmailVerificationPolicy) error
 
 	// Billing Address Management
 
 	AddBillingAddress(ctx *gin.Context, memberID uuid.UUID, billingAddress entities.BillingAddress) error
-	UpdateBillingAddress(ctx context.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress) error
+	UpdateBillingAddress(ctx context.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress, version int64) (int64, error)
 	GetAllBillingAddresses(ctx context.Context, memberID uuid.UUID) ([]entities.BillingAddress, error)
 	CheckBillingAddressRelation(ctx context.Context, memberID, billingAddressID uuid.UUID) (bool, error)
 	GetBillingAddressCountForMember(ctx *gin.Context, memberID uuid.UUID) (int, error)
-	BillingAddressExists(ctx *gin.Context, memberID uuid.UUID, billingAddress entities.BillingAddress) (bool, error)
+	BillingAddressExists(ctx *gin.Context, memberID uuid.UUID, billingAddress entities.BillingAddress, exceptID uuid.UUID) (bool, error)
 	CountPrimaryBillingAddresses(ctx *gin.Context, memberID uuid.UUID) (int, error)
 	HasPrimaryBilling(ctx *gin.Context, memberID uuid.UUID) (bool, error)
 	GetBillingAddressByID(ctx context.Context, memberBillingID uuid.UUID) (*entities.BillingAddress, error)
@@ -94,30 +138,76 @@ type MemberRepoImply interface {
 
 	CheckEmailExists(ctx context.Context, partnerID, email string) (bool, error)
 	GetMemberRecordCount(context.Context) (int64, error)
+	ListMembers(ctx context.Context, filter entities.MemberListFilter) ([]entities.ViewMembers, *entities.MemberCursor, error)
+	CountMembers(ctx context.Context, filter entities.MemberListFilter) (int64, error)
 	GetResetKey(ctx context.Context, memberID uuid.UUID) string
 	CheckEmailForMemberID(ctx *gin.Context, memberID uuid.UUID, email string) (bool, error)
+	GetMemberContact(ctx context.Context, memberID uuid.UUID) (entities.MemberContact, error)
 	CheckEmailProviderRelation(ctx *gin.Context, email string, provider string) (bool, error)
-	PasswordMemberRelation(ctx *gin.Context, memberID uuid.UUID, hashedPassword string) (bool, error)
+	PasswordMemberRelation(ctx *gin.Context, memberID uuid.UUID, password string) (bool, error)
 	CheckMemberPartner(ctx *gin.Context, memberID uuid.UUID, partnerIDStr string) (bool, error)
 
 	// Subscription Handling
 
-	HandleSubscriptionCheckout(ctx context.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription) error
+	HandleSubscriptionCheckout(ctx context.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription) (string, error)
 	GetSubscriptionStatusName(ctx *gin.Context, subscriptionID string) (string, error)
 	GetSubscriptionCountForLastYear(ctx *gin.Context, memberID uuid.UUID, subscriptionID string) (int, error)
 	GetMaxSubscriptionLimitForID(ctx *gin.Context, subscriptionID string) (int, error)
 	IsFreeSubscription(ctx context.Context, subscriptionID string) (bool, error)
+	HasPaidSubscriptionPlans(ctx context.Context) (bool, error)
 	CheckIfPayoutGatewayExists(ctx *gin.Context, paymentGatewayID int) (bool, error)
 	CheckIfMemberSubscribedToFreePlan(ctx *gin.Context, memberID uuid.UUID, subscriptionID string) (bool, error)
 	HasSubscribedToOneTimePlan(ctx *gin.Context, memberID uuid.UUID, subscriptionID string) (bool, error)
 	IsSubscriptionFree(ctx context.Context, subscriptionID string) (bool, error)
+	IsOneTimeSubscription(ctx context.Context, subscriptionID string) (bool, error)
 	IsMemberSubscribedToPlan(ctx *gin.Context, memberID uuid.UUID, subscriptionID string) (bool, error)
 	IsMemberSubscribedToFreePlan(ctx *gin.Context, memberID uuid.UUID, MemberSubscriptionID string) (bool, error)
-	HandleSubscriptionRenewal(ctx context.Context, memberID uuid.UUID, checkoutData entities.SubscriptionRenewal) error
+	SetAutoRenewal(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string, enabled bool, paymentGatewayID int) error
+	ClaimDueRenewals(ctx context.Context, limit int, lease time.Duration) ([]entities.RenewalCandidate, error)
+	RecordRenewalAttempt(ctx context.Context, attempt entities.RenewalAttempt) error
+	GetRenewalAttempts(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) ([]entities.RenewalAttempt, error)
 	CheckCancellationEnabled(ctx *gin.Context, subscriptionID string) (bool, error)
 	HandleSubscriptionCancellation(ctx context.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription) error
 	GetPaymentDetailsByPartnerAndGateway(ctx context.Context, partnerID string, paymentGatewayID int) (string, error)
-	DecryptPaymentData(ctx *gin.Context, data string) (string, error)
+	GetPaymentDetailsByPartner(ctx context.Context, partnerID string) (map[int]string, error)
+	DecryptPaymentData(ctx context.Context, data string) (string, error)
+	RecordSubscriptionPayment(ctx context.Context, payment entities.SubscriptionPayment) (uuid.UUID, error)
+	GetMemberBillingProfile(ctx context.Context, memberID uuid.UUID) (entities.BillingProfile, error)
+	CreateInvoice(ctx context.Context, invoice entities.Invoice) (entities.Invoice, error)
+	GetMemberInvoiceCount(ctx context.Context, memberID uuid.UUID) (int64, error)
+	GetMemberInvoices(ctx context.Context, memberID uuid.UUID, page int32, limit int32) ([]entities.Invoice, error)
+	GetMemberInvoice(ctx context.Context, memberID uuid.UUID, invoiceID uuid.UUID) (entities.Invoice, error)
+	GetMemberAuditCount(ctx context.Context, memberID uuid.UUID, action string) (int64, error)
+	GetMemberAudit(ctx context.Context, memberID uuid.UUID, action string, page int32, limit int32) ([]entities.AuditEntry, error)
+	GetLatestCapturedPayment(ctx context.Context, memberSubscriptionID string) (entities.SubscriptionPayment, error)
+	RecordRenewalPayment(ctx context.Context, payment entities.SubscriptionPayment, credit *entities.SubscriptionLedgerEntry) (uuid.UUID, error)
+	RecordCheckoutPayment(ctx context.Context, payment entities.SubscriptionPayment) (uuid.UUID, error)
+	RecordSubscriptionRefund(ctx context.Context, refund entities.SubscriptionPayment, refundedPaymentID uuid.UUID, entry entities.SubscriptionLedgerEntry) error
+	UpdateSubscriptionPaymentStatus(ctx context.Context, paymentID uuid.UUID, status string) error
+	UpdateSubscriptionStatus(ctx context.Context, memberSubscriptionID string, status string) error
+	GetSubscriptionPaymentByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (entities.SubscriptionPayment, error)
+	GetSubscriptionPlanTerms(ctx context.Context, subscriptionID string, currency string) (entities.SubscriptionPlanTerms, error)
+	ListSubscriptionPlans(ctx context.Context, retired bool) ([]entities.SubscriptionPlan, error)
+	GetSubscriptionPlanVersions(ctx context.Context, planID uuid.UUID) ([]entities.SubscriptionPlan, error)
+	SubscriptionDurationExists(ctx context.Context, durationID int) (bool, error)
+	CreateSubscriptionPlan(ctx context.Context, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, error)
+	AddSubscriptionPlanVersion(ctx context.Context, planID uuid.UUID, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, error)
+	RetireSubscriptionPlan(ctx context.Context, planID uuid.UUID) error
+	GetPromoCode(ctx context.Context, partnerID string, code string) (entities.PromoCode, error)
+	CreatePromoCode(ctx context.Context, request entities.PromoCodeRequest) (entities.PromoCode, error)
+	ListPromoCodes(ctx context.Context) ([]entities.PromoCode, error)
+	GetPromoCodeRedemptionCount(ctx context.Context, promoCodeID uuid.UUID) (int64, error)
+	GetPromoCodeRedemptions(ctx context.Context, promoCodeID uuid.UUID, page int32, limit int32) ([]entities.PromoRedemption, error)
+	GetMemberSubscriptionState(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.MemberSubscriptionState, error)
+	ChangeSubscriptionPlan(ctx context.Context, entry entities.SubscriptionLedgerEntry) (entities.SubscriptionLedgerEntry, error)
+	RecordPlanChangePayment(ctx context.Context, payment entities.SubscriptionPayment, entry entities.SubscriptionLedgerEntry) (uuid.UUID, entities.SubscriptionLedgerEntry, error)
+	GetSubscriptionBalance(ctx context.Context, memberSubscriptionID string) (float64, error)
+	ApplyPaymentWebhookEvent(ctx context.Context, gateway string, partnerID string, event entities.PaymentWebhookEvent, payload []byte,
+		payment entities.SubscriptionPayment, change entities.PaymentStateChange) (bool, error)
+	GetSubscriptionQuota(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.SubscriptionQuota, error)
+	ReserveQuota(ctx context.Context, reservation entities.QuotaReservation, ttl time.Duration) (entities.QuotaReservation, error)
+	FinishQuotaReservation(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID uuid.UUID, status string) error
+	ExpireQuotaReservations(ctx context.Context) (int64, error)
 
 	// Address Updates and Switching
 
@@ -130,7 +220,7 @@ type MemberRepoImply interface {
 
 	// Existence and Relation Checks
 
-	DeleteBillingAddress(*gin.Context, uuid.UUID, uuid.UUID) error
+	DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, version int64) error
 	IsPartnerIdCorrespondsToGateway(ctx context.Context, partnerID string, paymentGatewayID int) (bool, error)
 	HasProductsReleaseEndDateGreaterThanToday(ctx *gin.Context, memberSubscriptionID string) (bool, error)
 	IsMemberRelatedToSubscription(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string) (bool, error)
@@ -138,15 +228,37 @@ type MemberRepoImply interface {
 	IsSubscriptionAboutInWarning(ctx context.Context, memberSubscriptionID string) (bool, string, error)
 	IsSubscriptionInGracePeriod(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) (bool, time.Time, time.Time, time.Duration, bool, error)
 	CheckSubscriptionExistenceAndStatusForCheckout(ctx *gin.Context, SubscriptionID string) (exists bool, isActive bool, err error)
-	CheckSubscriptionExistenceAndStatusForRenewal(ctx *gin.Context, memberSubscriptionID string) (bool, bool, error)
+	CheckMemberSubscriptionExists(ctx *gin.Context, memberSubscriptionID string) (bool, error)
+
+	// Subscription Lifecycle
+
+	TransitionSubscriptionStatuses(ctx context.Context, now time.Time) ([]entities.SubscriptionTransition, error)
+
+	// Data Export and Erasure
+
+	GetMemberExport(ctx context.Context, memberID uuid.UUID) (entities.MemberExport, error)
+	RequestMemberErasure(ctx context.Context, memberID uuid.UUID, requestedBy string) (bool, error)
+	GetPendingMemberErasures(ctx context.Context, limit int) ([]entities.MemberErasureRequest, error)
+	EraseMember(ctx context.Context, request entities.MemberErasureRequest) (bool, error)
+
+	// Bulk Import
+
+	CreateMemberImport(ctx context.Context, memberImport entities.MemberImport, importRows []entities.MemberImportRow) (entities.MemberImport, error)
+	ClaimMemberImports(ctx context.Context, limit int, lease time.Duration) ([]entities.MemberImport, error)
+	GetPendingMemberImportRows(ctx context.Context, importID uuid.UUID) ([]entities.MemberImportRow, error)
+	ImportMember(ctx context.Context, importID uuid.UUID, rowNumber int, args entities.Member, partnerID string) (uuid.UUID, error)
+	CompleteMemberImportRow(ctx context.Context, importID uuid.UUID, rowNumber int, validationErrors map[string][]string) error
+	CompleteMemberImport(ctx context.Context, importID uuid.UUID) error
+	GetMemberImport(ctx context.Context, importID uuid.UUID) (entities.MemberImport, error)
 }
 
 // NewMemberRepo creates a new instance of MemberRepo.
 // NewMemberRepo creates a new instance of MemberRepo.
 func NewMemberRepo(db *sql.DB, cfg *entities.EnvConfig) *MemberRepo {
 	return &MemberRepo{
-		db:  db,
-		Cfg: cfg,
+		db:     db,
+		Cfg:    cfg,
+		hasher: hashing.NewPasswordHasher(cfg.PasswordHash),
 	}
 }
 
@@ -162,7 +274,8 @@ func (member *MemberRepo) IsMemberExists(memberId uuid.UUID, ctxt context.Contex
 	var exists int
 	//Checking if member with the passed ID exists
 	isMemberExistsQ := `select 1 from member where id = $1`
-	row := member.db.QueryRowContext(ctxt, isMemberExistsQ, memberId)
+	scope, params := tenant.Condition(ctxt, "partner_id", []any{memberId})
+	row := member.db.QueryRowContext(ctxt, isMemberExistsQ+scope, params...)
 	err := row.Scan(&exists)
 
 	if err != nil {
@@ -199,17 +312,47 @@ func (member *MemberRepo) AddBillingAddress(ctx *gin.Context, memberID uuid.UUID
 		return errors.New("member already has the maximum allowed number of billing addresses")
 	}
 
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = checkMemberInScope(ctx, tx, memberID); err != nil {
+		return err
+	}
+
 	// If no errors so far, proceed with inserting the new billing address.
-	_, err = member.db.ExecContext(ctx, `
+	var billingAddressID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
 							INSERT INTO member_billing_address (member_id, address, zip, country_code, state_code, is_primary_billing)
 							VALUES ($1, $2, $3, $4, $5, $6)
-						`, memberID, billingAddress.Address, billingAddress.Zipcode, billingAddress.Country, billingAddress.State, billingAddress.Primary)
+							RETURNING id
+						`, memberID, billingAddress.Address, billingAddress.Zipcode, billingAddress.Country, billingAddress.State, billingAddress.Primary).
+		Scan(&billingAddressID)
 
 	if err != nil {
 		return fmt.Errorf("failed to insert billing address: %v", err)
 	}
-	// If insertion is successful, return nil to indicate success.
-	return nil
+
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventBillingAddressAdded, consts.AggregateMember, memberID.String(),
+		entities.BillingAddressEventData{
+			MemberID:         memberID,
+			BillingAddressID: billingAddressID,
+			Country:          billingAddress.Country,
+			State:            billingAddress.State,
+			Primary:          billingAddress.Primary,
+		})
+	if err != nil {
+		return err
+	}
+	// If insertion is successful, commit to make the address and its event visible together.
+	err = tx.Commit()
+	return err
 }
 
 // UpdateBillingAddress updates an existing billing address for a member in the database.
@@ -218,23 +361,61 @@ func (member *MemberRepo) AddBillingAddress(ctx *gin.Context, memberID uuid.UUID
 //   - ctx: The context for the database operation.
 //   - memberID: The UUID of the member for whom the billing address is being updated.
 //   - billingAddress: The updated billing address details.
+//   - version: The version the update was made against, 0 to update any version.
 //
 // Returns:
-//   - error: An error, if any, during the database operation.
-func (member *MemberRepo) UpdateBillingAddress(ctx context.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress) error {
+//   - int64: The version of the updated billing address.
+//   - error: An error, if any, during the database operation. consts.ErrVersionMismatch when
+//     the billing address is no longer at version.
+func (member *MemberRepo) UpdateBillingAddress(ctx context.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress, version int64) (updatedVersion int64, err error) {
 	// Check if such a member exists
 	memberExists, err := member.IsMemberExists(memberID, ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if !memberExists {
-		return errors.New("member does not exist")
+		err = errors.New("member does not exist")
+		return 0, err
+	}
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Read the values the audit trail compares the update with
+	var oldAddress, oldZip, oldCountry, oldState sql.NullString
+	var oldPrimary bool
+	scope, scopeParams := tenant.MemberCondition(ctx, "member_id", []any{memberBillingID, memberID})
+	err = tx.QueryRowContext(ctx, `
+		SELECT address, zip, country_code, state_code, is_primary_billing
+		FROM member_billing_address
+		WHERE id = $1 AND member_id = $2`+scope+`
+		FOR UPDATE
+	`, scopeParams...).Scan(&oldAddress, &oldZip, &oldCountry, &oldState, &oldPrimary)
+	if err != nil {
+		return 0, err
+	}
+	oldValues := map[string]any{
+		"address":            oldAddress.String,
+		"zip":                oldZip.String,
+		"country_code":       oldCountry.String,
+		"state_code":         oldState.String,
+		"is_primary_billing": oldPrimary,
 	}
 
 	// Prepare the dynamic update query and parameters
 	updateQry := "UPDATE member_billing_address SET "
 	var params []interface{}
 	paramCount := 1
+	var changes audit.Changes
 
 	// Helper function to append fields to the update query
 	appendField := func(field string, value interface{}) {
@@ -244,6 +425,7 @@ func (member *MemberRepo) UpdateBillingAddress(ctx context.Context, memberID uui
 		updateQry += fmt.Sprintf("%s = $%d", field, paramCount)
 		params = append(params, value)
 		paramCount++
+		changes = changes.Set(field, oldValues[field], value)
 	}
 
 	// Append fields to the update query if they are not empty
@@ -261,18 +443,47 @@ func (member *MemberRepo) UpdateBillingAddress(ctx context.Context, memberID uui
 	}
 	// Handle the Primary field, default to false if not provided
 	appendField("is_primary_billing", billingAddress.Primary)
+	updateQry += ", version = version + 1"
 
 	// Add the WHERE clause
 	updateQry += fmt.Sprintf(" WHERE id = $%d", paramCount)
 	params = append(params, memberBillingID)
+	if version > 0 {
+		updateQry += fmt.Sprintf(" AND version = $%d", len(params)+1)
+		params = append(params, version)
+	}
+	scope, params = tenant.MemberCondition(ctx, "member_id", params)
+	updateQry += scope + " RETURNING country_code, state_code, version"
 
 	// Execute the dynamic update query
-	_, err = member.db.ExecContext(ctx, updateQry, params...)
+	var country, state sql.NullString
+	err = tx.QueryRowContext(ctx, updateQry, params...).Scan(&country, &state, &updatedVersion)
+	if errors.Is(err, sql.ErrNoRows) && version > 0 {
+		err = consts.ErrVersionMismatch
+	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventBillingAddressUpdated, consts.AggregateMember, memberID.String(),
+		entities.BillingAddressEventData{
+			MemberID:         memberID,
+			BillingAddressID: memberBillingID,
+			Country:          country.String,
+			State:            state.String,
+			Primary:          billingAddress.Primary,
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID:   memberID,
+		ResourceID: memberBillingID.String(),
+		Action:     consts.AuditBillingAddressUpdated,
+		Changes:    changes,
+	})
+	return updatedVersion, err
 }
 
 // UpdateMember updates a member's information in the repository.
@@ -281,24 +492,49 @@ func (member *MemberRepo) UpdateBillingAddress(ctx context.Context, memberID uui
 //   - ctx: The context for the database operation.
 //   - memberID: The UUID of the member whose information is being updated.
 //   - args: The updated member information.
+//   - version: The version the update was made against, 0 to update any version.
 
 // Returns:
-//   - error: An error, if any, during the database operation.
-func (member *MemberRepo) UpdateMember(ctx context.Context, memberID uuid.UUID, args entities.Member) error {
-	// Check if the member with the given memberID exists
-	checkMemberQry := `SELECT 1 FROM member WHERE id = $1`
-	var memberExists int
-	row := member.db.QueryRowContext(ctx, checkMemberQry, memberID)
-	if err := row.Scan(&memberExists); err != nil {
-		if err == sql.ErrNoRows {
-			return err
+//   - int64: The version of the updated member.
+//   - error: An error, if any, during the database operation. consts.ErrVersionMismatch when
+//     the member is no longer at version.
+func (member *MemberRepo) UpdateMember(ctx context.Context, memberID uuid.UUID, args entities.Member, version int64) (updatedVersion int64, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
 		}
-		return err // Handle other database errors if needed
+		err = tx.Commit()
+	}()
+
+	// Check if the member with the given memberID exists, and read the values the audit trail
+	// compares the update with
+	columns := []string{"title", "firstname", "lastname", "gender", "language_code", "country_code",
+		"state_code", "address1", "city", "zip", "mobile", "address2"}
+	current := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range current {
+		dest[i] = &current[i]
+	}
+	scope, scopeParams := tenant.Condition(ctx, "partner_id", []any{memberID})
+	err = tx.QueryRowContext(ctx, `SELECT `+strings.Join(columns, ", ")+` FROM member WHERE id = $1`+scope+` FOR UPDATE`,
+		scopeParams...).Scan(dest...)
+	if err != nil {
+		return 0, err
+	}
+	oldValues := make(map[string]string, len(columns))
+	for i, column := range columns {
+		oldValues[column] = current[i].String
 	}
 
 	// Prepare the dynamic update query and parameters
 	updateQry := "UPDATE member"
 	var params []interface{}
+	var changes audit.Changes
 
 	// Helper function to append fields to the update query
 	appendField := func(field string, value interface{}) {
@@ -309,6 +545,7 @@ func (member *MemberRepo) UpdateMember(ctx context.Context, memberID uuid.UUID,
 		}
 		updateQry += fmt.Sprintf("%s = $%d", field, len(params)+1)
 		params = append(params, value)
+		changes = changes.Set(field, oldValues[field], value)
 	}
 
 	// Append fields to the update query if they are not empty
@@ -351,20 +588,37 @@ func (member *MemberRepo) UpdateMember(ctx context.Context, memberID uuid.UUID,
 
 	// Check if there are any fields to update
 	if len(params) == 0 {
-		return errors.New("No fields to update")
+		err = errors.New("No fields to update")
+		return 0, err
 	}
+	updateQry += ", version = version + 1"
 
 	// Add the WHERE clause
 	updateQry += fmt.Sprintf(" WHERE id = $%d", len(params)+1)
 	params = append(params, memberID)
+	if version > 0 {
+		updateQry += fmt.Sprintf(" AND version = $%d", len(params)+1)
+		params = append(params, version)
+	}
+	scope, params = tenant.Condition(ctx, "partner_id", params)
+	updateQry += scope + " RETURNING version"
 
-	// Execute the dynamic update query
-	_, err := member.db.ExecContext(ctx, updateQry, params...)
+	// Execute the dynamic update query, the member exists so no row means another version
+	err = tx.QueryRowContext(ctx, updateQry, params...).Scan(&updatedVersion)
+	if errors.Is(err, sql.ErrNoRows) && version > 0 {
+		err = consts.ErrVersionMismatch
+		return 0, err
+	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: memberID,
+		Action:   consts.AuditMemberUpdated,
+		Changes:  changes,
+	})
+	return updatedVersion, err
 }
 
 // GetPasswordHash retrieves the password hash for a member.
@@ -387,171 +641,657 @@ func (m *MemberRepo) GetPasswordHash(ctx context.Context, memberID uuid.UUID) (s
 	return passwordHash, nil
 }
 
-// CheckResetKeyMatch checks if the key in the database matches the entered key and verifies the timestamp.
+// CheckResetKeyMatch checks if the hash of the entered key matches the stored hash and verifies the timestamp.
+// It returns consts.ErrResetKeyInvalid when no key is pending, consts.ErrResetKeyMismatch when the key
+// does not match and consts.ErrResetKeyExpired when the key is no longer valid.
 func (m *MemberRepo) CheckResetKeyMatch(ctx context.Context, memberID uuid.UUID, key string) (bool, error) {
-	var storedKey string
-	var expirationTimestamp time.Time
+	var storedHash sql.NullString
+	var expirationTimestamp sql.NullTime
 
-	// Fetch stored reset key and its associated expiration timestamp from the database
+	// Fetch stored reset key hash and its associated expiration timestamp from the database
 	err := m.db.QueryRowContext(ctx, `
 					SELECT reset_password_key, password_expiry
 					FROM member 
 					WHERE id = $1;
-				`, memberID).Scan(&storedKey, &expirationTimestamp)
+				`, memberID).Scan(&storedHash, &expirationTimestamp)
 
 	if err != nil {
 		return false, err
 	}
 
-	// Check if the stored key matches the entered key
-	if storedKey != key {
-		return false, errors.New("reset key does not match")
+	// A key that was used or never generated cannot match
+	if !storedHash.Valid || !expirationTimestamp.Valid || storedHash.String == "" {
+		return false, consts.ErrResetKeyInvalid
+	}
+
+	// Compare the hashes in constant time
+	if subtle.ConstantTimeCompare([]byte(storedHash.String), []byte(HashResetKey(key))) != 1 {
+		return false, consts.ErrResetKeyMismatch
 	}
 
 	// Verify if the reset key has expired based on its expiration timestamp
-	currentTimestamp := time.Now()
-	if currentTimestamp.After(expirationTimestamp) {
-		return false, fmt.Errorf("timeout: reset key has expired, please try resetting again")
+	if time.Now().After(expirationTimestamp.Time) {
+		return false, consts.ErrResetKeyExpired
 	}
 
 	// If everything is valid, return true indicating the key matches and is not expired
 	return true, nil
 }
 
-// UpdatePassword updates the password hash for a member.
-// This function updates the password hash in the database for the specified member.
-// Parameters:
-//   - ctx: The context for the operation.
-//   - memberID: The UUID of the member whose password hash is being updated.
-//   - newPasswordHash: The new password hash to be set for the member.
-//
-// Returns:
-//   - If successful, it returns nil (no error).
-//   - If there's an error in the database operation, it returns an error.
-func (m *MemberRepo) UpdatePassword(ctx context.Context, memberID uuid.UUID, key string, newPasswordHash string) error {
-	// Begin a new transaction
+// ClaimResetKeyAttempt counts a reset key check of the member from the client IP before the key is
+// compared, so concurrent checks cannot all pass the lockout. The client IP and the member are each
+// checked and counted in one statement, the client IP first so concurrent checks lock the rows in
+// the same order. The check is refused when the attempts of the lockout window reach the configured
+// limit of either, a refused check counts nothing. A check whose key matches is given back with
+// ReleaseResetKeyAttempt, so only failed checks lock out.
+func (m *MemberRepo) ClaimResetKeyAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) (blocked bool, err error) {
 	tx, err := m.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer func() {
-		if err != nil {
-			tx.Rollback()
+		// A refused check gives back what the client IP counted
+		if err != nil || blocked {
+			_ = tx.Rollback()
 			return
 		}
 		err = tx.Commit()
 	}()
 
-	// First, update the password for the member
-	_, err = tx.ExecContext(ctx, `
-        UPDATE member SET password = $1 WHERE id = $2`, newPasswordHash, memberID)
-	if err != nil {
-		return err
-	}
+	now := time.Now()
+	windowStart := now.Add(-m.Cfg.PasswordReset.LockoutWindow)
+	for _, s := range []struct {
+		scope, subject string
+		maxAttempts    int
+	}{
+		{consts.LockoutScopeIP, ipAddress, m.Cfg.PasswordReset.MaxIPAttempts},
+		{consts.LockoutScopeMember, memberID.String(), m.Cfg.PasswordReset.MaxMemberAttempts},
+	} {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO password_reset_lockout (scope, subject, window_started_on) VALUES ($1, $2, $3)
+			ON CONFLICT (scope, subject) DO NOTHING
+		`, s.scope, s.subject, now)
+		if err != nil {
+			return false, err
+		}
 
-	// Then, set the reset_password_key to 'invalidated'
-	_, err = tx.ExecContext(ctx, `
-        UPDATE member SET reset_password_key = 'invalidated' WHERE id = $1`, memberID)
-	if err != nil {
-		return err
+		var attempts int
+		err = tx.QueryRowContext(ctx, `
+			UPDATE password_reset_lockout
+			SET attempts = CASE WHEN window_started_on <= $4 THEN 1 ELSE attempts + 1 END,
+				window_started_on = CASE WHEN window_started_on <= $4 THEN $3 ELSE window_started_on END
+			WHERE scope = $1 AND subject = $2
+			AND ($5 <= 0 OR attempts < $5 OR window_started_on <= $4)
+			RETURNING attempts
+		`, s.scope, s.subject, now, windowStart, s.maxAttempts).Scan(&attempts)
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
 	}
-
-	return nil
+	return false, nil
 }
 
-// GetAllBillingAddresses retrieves all billing addresses associated with a member.
-// If no billing addresses are found for the member, it returns an error "No record found."
+// ReleaseResetKeyAttempt gives back the check claimed by ClaimResetKeyAttempt when the key matched or
+// could not be checked.
+func (m *MemberRepo) ReleaseResetKeyAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) error {
+	_, err := m.db.ExecContext(ctx, `
+		UPDATE password_reset_lockout
+		SET attempts = attempts - 1
+		WHERE attempts > 0
+		AND ((scope = $1 AND subject = $2) OR (scope = $3 AND subject = $4))
+	`, consts.LockoutScopeMember, memberID.String(), consts.LockoutScopeIP, ipAddress)
+	return err
+}
 
-// Parameters:
-//   - ctx: The context for the database operation.
-//   - memberID: The UUID of the member for whom billing addresses are being retrieved.
-//
-// Returns:
-//
-//   - []entities.BillingAddress: A slice of billing addresses associated with the member.
-//   - error: An error, if any, during the retrieval process.
-func (member *MemberRepo) GetAllBillingAddresses(ctx context.Context, memberID uuid.UUID) ([]entities.BillingAddress, error) {
-	// Check if given memberID exists
-	memberExists, err := member.IsMemberExists(memberID, ctx)
-	if err != nil {
-		return nil, err
-	}
-	if !memberExists {
-		return nil, errors.New("Member not found") // Return custom error message
+// GetMemberIDByEmail returns the id of the partner's member registered with the email, or
+// uuid.Nil when there is no such member.
+func (m *MemberRepo) GetMemberIDByEmail(ctx context.Context, partnerID, email string) (uuid.UUID, error) {
+	var memberID uuid.UUID
+	err := m.db.QueryRowContext(ctx, `
+		SELECT id FROM member WHERE partner_id = $1 AND email = $2 AND is_deleted = false
+	`, partnerID, email).Scan(&memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, nil
 	}
+	return memberID, err
+}
 
-	// Initialize a slice to store billing addresses
-	var billingAddresses []entities.BillingAddress
+// loginLockoutPolicies returns the throttling policies of the failed logins of members and
+// of client IPs.
+func (m *MemberRepo) loginLockoutPolicies() (memberPolicy, ipPolicy lockout.Policy) {
+	memberPolicy = lockout.Policy{
+		MaxFailures: m.Cfg.Login.MaxMemberAttempts,
+		Window:      m.Cfg.Login.AttemptWindow,
+		BaseDelay:   m.Cfg.Login.BaseDelay,
+		MaxDelay:    m.Cfg.Login.MaxDelay,
+		Duration:    m.Cfg.Login.LockoutDuration,
+	}
+	ipPolicy = memberPolicy
+	ipPolicy.MaxFailures = m.Cfg.Login.MaxIPAttempts
+	return memberPolicy, ipPolicy
+}
 
-	// SQL query to retrieve billing addresses for a specific member_id
-	query := `
-       SELECT COALESCE(address, ''), COALESCE(zip, ''), 
-       COALESCE(country_code, ''), COALESCE(state_code, ''), is_primary_billing
-       FROM member_billing_address
-       WHERE member_id = $1
-    `
+// scanLoginLockout reads the state columns of a login_lockout row.
+func scanLoginLockout(scan func(dest ...any) error, dest ...any) (entities.LoginLockout, error) {
+	var (
+		state                                entities.LoginLockout
+		lastFailed, nextAttempt, lockedUntil sql.NullTime
+	)
+	err := scan(append(dest, &state.Failures, &lastFailed, &nextAttempt, &lockedUntil)...)
+	if err != nil {
+		return state, err
+	}
+	for _, column := range []struct {
+		value sql.NullTime
+		field **time.Time
+	}{{lastFailed, &state.LastFailedAt}, {nextAttempt, &state.NextAttemptAt}, {lockedUntil, &state.LockedUntil}} {
+		if column.value.Valid {
+			value := column.value.Time
+			*column.field = &value
+		}
+	}
+	return state, nil
+}
 
-	rows, err := member.db.QueryContext(ctx, query, memberID)
+// GetLoginLockouts returns the login throttling state of the member and of the client IP.
+// The member state is empty for uuid.Nil, the IP state for an empty address.
+func (m *MemberRepo) GetLoginLockouts(ctx context.Context, memberID uuid.UUID, ipAddress string) (entities.LoginLockout, entities.LoginLockout, error) {
+	var memberState, ipState entities.LoginLockout
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT scope, failures, last_failed_on, next_attempt_on, locked_until
+		FROM login_lockout
+		WHERE (scope = $1 AND subject = $2) OR (scope = $3 AND subject = $4)
+	`, consts.LockoutScopeMember, memberID.String(), consts.LockoutScopeIP, ipAddress)
 	if err != nil {
-		return nil, err
+		return memberState, ipState, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var billingAddress entities.BillingAddress
-		err := rows.Scan(
-			&billingAddress.Address,
-			&billingAddress.Zipcode,
-			&billingAddress.Country,
-			&billingAddress.State,
-			&billingAddress.Primary,
-		)
+		var scope string
+		state, err := scanLoginLockout(rows.Scan, &scope)
 		if err != nil {
-			return nil, err
+			return memberState, ipState, err
 		}
+		if scope == consts.LockoutScopeMember {
+			memberState = state
+		} else {
+			ipState = state
+		}
+	}
+	return memberState, ipState, rows.Err()
+}
 
-		// Append the retrieved billing address to the slice
-		billingAddresses = append(billingAddresses, billingAddress)
+// loginLockoutSubject is a member or client IP whose logins are throttled.
+type loginLockoutSubject struct {
+	scope, subject string
+	policy         lockout.Policy
+}
+
+// loginLockoutSubjects returns the throttled subjects of a login attempt of the member from the
+// client IP, the client IP first so concurrent attempts lock the rows in the same order.
+// Attempts for unknown members only count towards the client IP.
+func (m *MemberRepo) loginLockoutSubjects(memberID uuid.UUID, ipAddress string) []loginLockoutSubject {
+	memberPolicy, ipPolicy := m.loginLockoutPolicies()
+	subjects := []loginLockoutSubject{{consts.LockoutScopeIP, ipAddress, ipPolicy}}
+	if memberID != uuid.Nil {
+		subjects = append(subjects, loginLockoutSubject{consts.LockoutScopeMember, memberID.String(), memberPolicy})
 	}
+	return subjects
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+// ClaimLoginAttempt counts a login attempt of the member from the client IP before its
+// credentials are checked, so concurrent attempts cannot all pass the throttling. Each subject
+// is checked and counted in one statement: the attempt is refused while the delay or the lockout
+// of a failed login runs, or when the attempts in flight and the failures of the window reach the
+// lockout limit. A refused attempt counts nothing and the time to retry is returned.
+//
+// A claimed attempt delays nothing. It is settled with RecordLoginFailure when the credentials
+// are wrong and given back with ReleaseLoginAttempt otherwise. Failures after a quiet window or
+// a served lockout start counting again.
+func (m *MemberRepo) ClaimLoginAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) (retryAt time.Time, blocked bool, err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return retryAt, false, err
 	}
+	defer func() {
+		// A refused attempt gives back what the other subject counted
+		if err != nil || blocked {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
 
-	return billingAddresses, nil
+	now := time.Now()
+	for _, s := range m.loginLockoutSubjects(memberID, ipAddress) {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO login_lockout (scope, subject) VALUES ($1, $2)
+			ON CONFLICT (scope, subject) DO NOTHING
+		`, s.scope, s.subject)
+		if err != nil {
+			return retryAt, false, err
+		}
+
+		var failures int
+		err = tx.QueryRowContext(ctx, `
+			UPDATE login_lockout
+			SET failures = CASE WHEN last_failed_on IS NULL OR last_failed_on < $4 OR locked_until IS NOT NULL
+					THEN 1 ELSE failures + 1 END,
+				last_failed_on = $3,
+				locked_until = NULL
+			WHERE scope = $1 AND subject = $2
+			AND (next_attempt_on IS NULL OR next_attempt_on <= $3)
+			AND (locked_until IS NULL OR locked_until <= $3)
+			AND ($5 <= 0 OR failures < $5 OR last_failed_on < $4 OR locked_until IS NOT NULL)
+			RETURNING failures
+		`, s.scope, s.subject, now, now.Add(-s.policy.Window), s.policy.MaxFailures).Scan(&failures)
+		if errors.Is(err, sql.ErrNoRows) {
+			state, err := scanLoginLockout(tx.QueryRowContext(ctx, `
+				SELECT failures, last_failed_on, next_attempt_on, locked_until
+				FROM login_lockout WHERE scope = $1 AND subject = $2
+			`, s.scope, s.subject).Scan)
+			if err != nil {
+				return retryAt, false, err
+			}
+			// Attempts in flight hold no delay of their own
+			retryAt, blocked = lockout.RetryAt(state, now)
+			if !blocked {
+				retryAt = now.Add(s.policy.BaseDelay)
+			}
+			return retryAt, true, nil
+		}
+		if err != nil {
+			return retryAt, false, err
+		}
+	}
+	return retryAt, false, nil
 }
 
-// CheckEmailExists checks if an email address already exists in the database.
+// RecordLoginFailure settles an attempt claimed by ClaimLoginAttempt whose credentials were
+// wrong: the next attempts of the member and from the client IP are delayed, and locked out
+// once the failures reach the configured limits.
+func (m *MemberRepo) RecordLoginFailure(ctx context.Context, memberID uuid.UUID, ipAddress string) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
 
-// Parameters:
+	now := time.Now()
+	for _, s := range m.loginLockoutSubjects(memberID, ipAddress) {
+		var failures int
+		err = tx.QueryRowContext(ctx, `
+			SELECT failures FROM login_lockout WHERE scope = $1 AND subject = $2 FOR UPDATE
+		`, s.scope, s.subject).Scan(&failures)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Cleared by an admin meanwhile
+			err = nil
+			continue
+		}
+		if err != nil {
+			return err
+		}
 
-//		@ ctx:   The context for the database operation.
-//		@ email: The email string to be checked.
+		state := s.policy.Throttle(max(failures, 1), now)
+		_, err = tx.ExecContext(ctx, `
+			UPDATE login_lockout
+			SET last_failed_on = $3, next_attempt_on = $4, locked_until = $5
+			WHERE scope = $1 AND subject = $2
+		`, s.scope, s.subject, state.LastFailedAt, state.NextAttemptAt, state.LockedUntil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-// Returns:
+// ReleaseLoginAttempt gives back the attempt claimed by ClaimLoginAttempt when the credentials
+// were right or could not be checked. The delay of a member or client IP without other failures
+// is lifted.
+func (m *MemberRepo) ReleaseLoginAttempt(ctx context.Context, memberID uuid.UUID, ipAddress string) error {
+	_, err := m.db.ExecContext(ctx, `
+		UPDATE login_lockout
+		SET failures = failures - 1,
+			next_attempt_on = CASE WHEN failures = 1 THEN NULL ELSE next_attempt_on END
+		WHERE failures > 0
+		AND ((scope = $1 AND subject = $2) OR (scope = $3 AND subject = $4))
+	`, consts.LockoutScopeMember, memberID.String(), consts.LockoutScopeIP, ipAddress)
+	return err
+}
 
-// @ emailVal: The email value found in the database (if it exists).
-// @ err:      An error, if any, during the database operation.
-func (member *MemberRepo) CheckEmailExists(ctx context.Context, partnerID, email string) (bool, error) {
-	// var ctxt *gin.Context
-	var exists bool
+// ClearLoginLockout resets the login throttling of the member, after a successful login or
+// when an admin unlocks the member.
+func (m *MemberRepo) ClearLoginLockout(ctx context.Context, memberID uuid.UUID) error {
+	_, err := m.db.ExecContext(ctx, `
+		DELETE FROM login_lockout WHERE scope = $1 AND subject = $2
+	`, consts.LockoutScopeMember, memberID.String())
+	return err
+}
 
-	// SQL query for checks if an email address already exists.
-	checkEmailExistsQ := `SELECT EXISTS (SELECT 1 FROM member WHERE email = $1 AND partner_id = $2)`
+// twoFactorKey returns the key encrypting the TOTP secrets at rest.
+func (m *MemberRepo) twoFactorKey() ([]byte, error) {
+	if m.Cfg == nil || m.Cfg.DecryptionKey == "" {
+		return nil, errors.New("decryption key is empty")
+	}
+	return []byte(m.Cfg.DecryptionKey), nil
+}
 
-	row := member.db.QueryRowContext(ctx, checkEmailExistsQ, email, partnerID)
+// GetTwoFactor returns the two-factor authentication state of a member, with an empty secret
+// when the member never enrolled. It returns sql.ErrNoRows when the member does not exist.
+func (m *MemberRepo) GetTwoFactor(ctx context.Context, memberID uuid.UUID) (entities.TwoFactor, error) {
+	var (
+		state     = entities.TwoFactor{MemberID: memberID}
+		secret    sql.NullString
+		enabledOn sql.NullTime
+	)
+	err := m.db.QueryRowContext(ctx, `
+		SELECT m.email, tf.secret, tf.enabled_on, COALESCE(tf.last_used_step, 0)
+		FROM member m
+		LEFT JOIN member_two_factor tf ON tf.member_id = m.id
+		WHERE m.id = $1
+	`, memberID).Scan(&state.Email, &secret, &enabledOn, &state.LastUsedStep)
+	if err != nil {
+		return state, err
+	}
+	if enabledOn.Valid {
+		state.EnabledAt = &enabledOn.Time
+	}
+	if !secret.Valid {
+		return state, nil
+	}
 
-	err := row.Scan(&exists)
+	key, err := m.twoFactorKey()
+	if err != nil {
+		return state, err
+	}
+	state.Secret, err = crypto.Decrypt(secret.String, key)
+	if err != nil {
+		return state, fmt.Errorf("decrypting two-factor secret: %w", err)
+	}
+	return state, nil
+}
 
+// SaveTwoFactorSecret stores the secret of a pending enrollment, replacing the secret of an
+// earlier enrollment that was never verified. The secret of an enabled two-factor
+// authentication is kept.
+func (m *MemberRepo) SaveTwoFactorSecret(ctx context.Context, memberID uuid.UUID, secret string) error {
+	key, err := m.twoFactorKey()
 	if err != nil {
-		return false, err
+		return err
+	}
+	encrypted, err := crypto.Encrypt(secret, key)
+	if err != nil {
+		return err
 	}
 
-	return exists, nil
+	_, err = m.db.ExecContext(ctx, `
+		INSERT INTO member_two_factor (member_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (member_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_on = NOW()
+		WHERE member_two_factor.enabled_on IS NULL
+	`, memberID, encrypted)
+	return err
 }
 
-// RegisterMember registers a new member in the repository.
-
+// EnableTwoFactor enables the pending two-factor authentication of a member once its first code
+// was verified, and replaces the recovery codes. It returns false when no enrollment is pending
+// or the step was already used.
+func (m *MemberRepo) EnableTwoFactor(ctx context.Context, memberID uuid.UUID, step int64, recoveryCodeHashes []string) (enabled bool, err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !enabled {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE member_two_factor
+		SET enabled_on = NOW(), last_used_step = $2
+		WHERE member_id = $1 AND enabled_on IS NULL AND last_used_step < $2
+	`, memberID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM member_recovery_code WHERE member_id = $1`, memberID); err != nil {
+		return false, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO member_recovery_code (member_id, code_hash)
+		SELECT $1, UNNEST($2::TEXT[])
+	`, memberID, pq.Array(recoveryCodeHashes))
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UseTwoFactorStep records the time step of an accepted code. It returns false when the step or
+// a later one was already used, the code is then a replay.
+func (m *MemberRepo) UseTwoFactorStep(ctx context.Context, memberID uuid.UUID, step int64) (bool, error) {
+	result, err := m.db.ExecContext(ctx, `
+		UPDATE member_two_factor
+		SET last_used_step = $2
+		WHERE member_id = $1 AND enabled_on IS NOT NULL AND last_used_step < $2
+	`, memberID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// UseRecoveryCode marks the member's recovery code with the hash as used. It returns false when
+// the code is unknown or was already used.
+func (m *MemberRepo) UseRecoveryCode(ctx context.Context, memberID uuid.UUID, codeHash string) (bool, error) {
+	result, err := m.db.ExecContext(ctx, `
+		UPDATE member_recovery_code
+		SET used_on = NOW()
+		WHERE member_id = $1 AND code_hash = $2 AND used_on IS NULL
+	`, memberID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// DisableTwoFactor removes the two-factor secret and the recovery codes of a member.
+func (m *MemberRepo) DisableTwoFactor(ctx context.Context, memberID uuid.UUID) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM member_recovery_code WHERE member_id = $1`, memberID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM member_two_factor WHERE member_id = $1`, memberID)
+	return err
+}
+
+// UpdatePassword sets the password of a member with the reset key and uses up the key.
+// This function hashes the new password with the configured algorithm and stores it for the specified member.
+// The key and its expiry are checked by the statement writing the password, so a key is used once
+// even by concurrent requests.
+// Parameters:
+//   - ctx: The context for the operation.
+//   - memberID: The UUID of the member whose password hash is being updated.
+//   - key: The reset key of the member.
+//   - newPassword: The new password to be set for the member.
+//
+// Returns:
+//   - If successful, it returns nil (no error).
+//   - consts.ErrResetKeyInvalid when the key does not match, expired or was already used.
+//   - If there's an error in the database operation, it returns an error.
+func (m *MemberRepo) UpdatePassword(ctx context.Context, memberID uuid.UUID, key string, newPassword string) (err error) {
+	newPasswordHash, err := m.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Begin a new transaction
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE member
+		SET password = $1, reset_password_key = NULL, password_expiry = NULL
+		WHERE id = $2
+		AND reset_password_key = $3
+		AND password_expiry > NOW()
+	`, newPasswordHash, memberID, HashResetKey(key))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return consts.ErrResetKeyInvalid
+	}
+
+	err = m.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: memberID,
+		Action:   consts.AuditPasswordUpdated,
+		Changes:  audit.Changes{}.Redact("password").Redact("reset_password_key"),
+	})
+	return err
+}
+
+// GetAllBillingAddresses retrieves all billing addresses associated with a member.
+// If no billing addresses are found for the member, it returns an error "No record found."
+
+// Parameters:
+//   - ctx: The context for the database operation.
+//   - memberID: The UUID of the member for whom billing addresses are being retrieved.
+//
+// Returns:
+//
+//   - []entities.BillingAddress: A slice of billing addresses associated with the member.
+//   - error: An error, if any, during the retrieval process.
+func (member *MemberRepo) GetAllBillingAddresses(ctx context.Context, memberID uuid.UUID) ([]entities.BillingAddress, error) {
+	// Check if given memberID exists
+	memberExists, err := member.IsMemberExists(memberID, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !memberExists {
+		return nil, errors.New("Member not found") // Return custom error message
+	}
+
+	// Initialize a slice to store billing addresses
+	var billingAddresses []entities.BillingAddress
+
+	// SQL query to retrieve billing addresses for a specific member_id
+	query := `
+       SELECT id, COALESCE(address, ''), COALESCE(zip, ''), 
+       COALESCE(country_code, ''), COALESCE(state_code, ''), is_primary_billing, version
+       FROM member_billing_address
+       WHERE member_id = $1
+    `
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
+
+	rows, err := member.db.QueryContext(ctx, query+scope, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var billingAddress entities.BillingAddress
+		err := rows.Scan(
+			&billingAddress.ID,
+			&billingAddress.Address,
+			&billingAddress.Zipcode,
+			&billingAddress.Country,
+			&billingAddress.State,
+			&billingAddress.Primary,
+			&billingAddress.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Append the retrieved billing address to the slice
+		billingAddresses = append(billingAddresses, billingAddress)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return billingAddresses, nil
+}
+
+// CheckEmailExists checks if an email address already exists in the database.
+
+// Parameters:
+
+//		@ ctx:   The context for the database operation.
+//		@ email: The email string to be checked.
+
+// Returns:
+
+// @ emailVal: The email value found in the database (if it exists).
+// @ err:      An error, if any, during the database operation.
+func (member *MemberRepo) CheckEmailExists(ctx context.Context, partnerID, email string) (bool, error) {
+	// var ctxt *gin.Context
+	var exists bool
+
+	// SQL query for checks if an email address already exists.
+	checkEmailExistsQ := `SELECT EXISTS (SELECT 1 FROM member WHERE email = $1 AND partner_id = $2)`
+
+	row := member.db.QueryRowContext(ctx, checkEmailExistsQ, email, partnerID)
+
+	err := row.Scan(&exists)
+
+	if err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// RegisterMember registers a new member in the repository.
+
 // Parameters:
 //
 //	@ ctx: The context for the database operation.
@@ -563,25 +1303,86 @@ func (member *MemberRepo) CheckEmailExists(ctx context.Context, partnerID, email
 //
 //	@ err: An error, if any, during the database operation.
 //
-// RegisterMember registers a new member after checking the existence of the provider.
-func (member *MemberRepo) RegisterMember(ctx context.Context, args entities.Member, partnerID string) (err error) {
+// RegisterMember registers a new member after checking the existence of the provider. It returns
+// consts.ErrMemberRejected when the member cannot be stored, like an unknown provider or an email
+// registered meanwhile.
+func (member *MemberRepo) RegisterMember(ctx context.Context, args entities.Member, partnerID string) (memberID uuid.UUID, err error) {
+	args, err = member.hashMemberPassword(args)
+	if err != nil {
+		return
+	}
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
 
-	// Hash the password before storing it in the database.
-	var hashedPassword string
-	if args.Provider == consts.ProviderInternal {
-		hashedPassword, err = crypto.Hash(args.Password)
+	return member.insertMember(ctx, tx, args, partnerID)
+}
+
+// ImportMember registers the member of a bulk import row and marks the row imported in the same
+// transaction, so a row is never registered without being completed. Like RegisterMember it
+// returns consts.ErrMemberRejected when the member cannot be stored, the row is left pending then.
+func (member *MemberRepo) ImportMember(ctx context.Context, importID uuid.UUID, rowNumber int, args entities.Member,
+	partnerID string) (memberID uuid.UUID, err error) {
+	args, err = member.hashMemberPassword(args)
+	if err != nil {
+		return
+	}
 
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
 		if err != nil {
+			_ = tx.Rollback()
 			return
 		}
+		err = tx.Commit()
+	}()
+
+	memberID, err = member.insertMember(ctx, tx, args, partnerID)
+	if err != nil {
+		return
 	}
+	err = completeMemberImportRow(ctx, tx, importID, rowNumber, nil)
+	return
+}
+
+// hashMemberPassword hashes the password of a new member before storing it in the database,
+// unless it was hashed beforehand.
+func (member *MemberRepo) hashMemberPassword(args entities.Member) (entities.Member, error) {
+	if args.Provider != consts.ProviderInternal || args.PasswordHash != "" {
+		return args, nil
+	}
+	hashedPassword, err := member.hasher.Hash(args.Password)
+	if err != nil {
+		return args, err
+	}
+	args.PasswordHash = hashedPassword
+	return args, nil
+}
 
+// insertMember stores a new member with its hashed password within tx, after checking the
+// existence of the provider, and writes its registered event to the outbox.
+func (member *MemberRepo) insertMember(ctx context.Context, tx *sql.Tx, args entities.Member, partnerID string) (memberID uuid.UUID, err error) {
 	getOauthProviderID := `SELECT id FROM oauth_provider WHERE name = $1`
-	row := member.db.QueryRowContext(ctx, getOauthProviderID, args.Provider)
+	row := tx.QueryRowContext(ctx, getOauthProviderID, args.Provider)
 
 	var providerId uuid.UUID
 	err = row.Scan(&providerId)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("%w: unknown provider %s", consts.ErrMemberRejected, args.Provider)
+	}
 	if err != nil {
 		return
 	}
@@ -589,19 +1390,32 @@ func (member *MemberRepo) RegisterMember(ctx context.Context, args entities.Memb
 	// SQL query for inserting a new member record.
 	insertQry := fmt.Sprintf(`INSERT INTO member 
 				(firstname,lastname,email,password,is_terms_condition_checked,is_paying_tax,partner_id,oauth_provider_id)
-				values(%s)`, utils.PreparePlaceholders(8))
+				values(%s)
+				RETURNING id`, utils.PreparePlaceholders(8))
 
-	_, err = member.db.Exec(insertQry, args.FirstName,
-		args.LastName, args.Email, hashedPassword,
+	err = tx.QueryRowContext(ctx, insertQry, args.FirstName,
+		args.LastName, args.Email, args.PasswordHash,
 		args.TermsConditionChecked, args.PayingTax,
 		partnerID, providerId,
-	)
+	).Scan(&memberID)
 
 	// Return any error encountered during the database operation.
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code.Class() == "22" || pqErr.Code.Class() == "23") {
+		// Data exceptions and constraint violations fail the same way on every attempt
+		err = fmt.Errorf("%w: %s", consts.ErrMemberRejected, err.Error())
+	}
 	if err != nil {
 		return
 	}
 
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventMemberRegistered, consts.AggregateMember, memberID.String(),
+		entities.MemberEventData{
+			MemberID:  memberID,
+			PartnerID: partnerID,
+			Email:     args.Email,
+			Provider:  args.Provider,
+		})
 	return
 }
 
@@ -650,21 +1464,26 @@ func (member *MemberRepo) ViewMemberProfile(memberId uuid.UUID, ctx context.Cont
 			COALESCE(m.city, ''),
 			COALESCE(m.zip, ''),
 			COALESCE(m.language_code, ''),
-			m.is_mail_subscribed
+			m.is_mail_subscribed,
+			m.version
 		FROM
 			member m
 		WHERE
 			m.id = $1
 	`
+	scope, scopeParams := tenant.Condition(ctx, "m.partner_id", []any{memberId})
+	getMemberProfileQ += scope
 
 	// Query to fetch billing address details
 	getBillingAddressQ := `
 		SELECT
+			id,
 			COALESCE(address, ''),
 			COALESCE(zip, ''),
 			COALESCE(country_code, ''),
 			COALESCE(state_code, ''),
-			is_primary_billing
+			is_primary_billing,
+			version
 		FROM
 			member_billing_address
 		WHERE
@@ -674,7 +1493,7 @@ func (member *MemberRepo) ViewMemberProfile(memberId uuid.UUID, ctx context.Cont
 	var memberProfile entities.MemberProfile
 
 	// Fetch member profile details
-	err := member.db.QueryRowContext(ctx, getMemberProfileQ, memberId).Scan(
+	err := member.db.QueryRowContext(ctx, getMemberProfileQ, scopeParams...).Scan(
 		&memberProfile.MemberDetails.Title,
 		&memberProfile.MemberDetails.FirstName,
 		&memberProfile.MemberDetails.LastName,
@@ -689,6 +1508,7 @@ func (member *MemberRepo) ViewMemberProfile(memberId uuid.UUID, ctx context.Cont
 		&memberProfile.MemberDetails.Zipcode,
 		&memberProfile.MemberDetails.Language,
 		&memberProfile.EmailSubscribed,
+		&memberProfile.Version,
 	)
 
 	if err != nil {
@@ -706,11 +1526,13 @@ func (member *MemberRepo) ViewMemberProfile(memberId uuid.UUID, ctx context.Cont
 	for rows.Next() {
 		var billingAddress entities.BillingAddress
 		err := rows.Scan(
+			&billingAddress.ID,
 			&billingAddress.Address,
 			&billingAddress.Zipcode,
 			&billingAddress.Country,
 			&billingAddress.State,
 			&billingAddress.Primary,
+			&billingAddress.Version,
 		)
 
 		if err != nil {
@@ -799,6 +1621,8 @@ func (member *MemberRepo) ViewMembers(ctx context.Context, params entities.Param
 	// Build the query based on the provided parameters
 	conditions := []string{"1 = 1"}
 	parameters := []interface{}{true, false, false, true, false, false}
+	scope, parameters := tenant.Condition(ctx, "m.partner_id", parameters)
+	viewMembersQ += scope
 	// Check if params.Limit is empty
 	if params.Limit == 0 {
 		logger.Log().Error("Limit parameter is empty")
@@ -936,6 +1760,8 @@ func (member *MemberRepo) GetFilteredRecordCount(ctx context.Context, params ent
 	// Build the query based on the provided parameters
 	conditions := []string{"1 = 1"}
 	parameters := []interface{}{false}
+	scope, parameters := tenant.Condition(ctx, "m.partner_id", parameters)
+	viewMembersCountQ += scope
 
 	// Add conditions based on params
 	if params.Status != "" {
@@ -1009,9 +1835,47 @@ func (member *MemberRepo) GetFilteredRecordCount(ctx context.Context, params ent
 
 func (member *MemberRepo) GetBasicMemberDetailsByEmail(partnerID string, args entities.MemberPayload, ctx context.Context) (entities.BasicMemberData, error) {
 
-	var basicMemberData entities.BasicMemberData
+	getBasicMemberDataQ := basicMemberDataQuery + `
+		WHERE
+			m.partner_id = $1
+		AND 
+			m.email = $2
+	`
+
+	basicMemberData, storedPassword, err := member.scanBasicMemberData(ctx, getBasicMemberDataQ, partnerID, args.Email)
+	if err != nil {
+		return basicMemberData, err
+	}
+
+	// Password logins must match the stored hash, a mismatch is reported as an unknown member
+	if args.Provider == consts.ProviderInternal && basicMemberData.MemberID != uuid.Nil {
+		match, err := member.verifyPassword(ctx, basicMemberData.MemberID, args.Password, storedPassword)
+		if err != nil {
+			return entities.BasicMemberData{}, err
+		}
+		if !match {
+			return entities.BasicMemberData{}, nil
+		}
+	}
+
+	return basicMemberData, nil
+}
+
+// GetBasicMemberDetailsByID retrieves the basic details of a member, for logins completed after
+// the password was checked. It returns sql.ErrNoRows when the member does not exist.
+func (member *MemberRepo) GetBasicMemberDetailsByID(ctx context.Context, memberID uuid.UUID) (entities.BasicMemberData, error) {
+	basicMemberData, _, err := member.scanBasicMemberData(ctx, basicMemberDataQuery+`
+		WHERE
+			m.id = $1
+	`, memberID)
+	if err == nil && basicMemberData.MemberID == uuid.Nil {
+		err = sql.ErrNoRows
+	}
+	return basicMemberData, err
+}
 
-	getBasicMemberDataQ := `
+// basicMemberDataQuery selects the basic details of members, one row per role.
+const basicMemberDataQuery = `
 		SELECT
 			m.id,
 			CONCAT(m.firstname, ' ', m.lastname) AS memberName,
@@ -1020,7 +1884,8 @@ func (member *MemberRepo) GetBasicMemberDetailsByEmail(partnerID string, args en
 			m.email,
 			m.oauth_provider_id,
 			l.name AS user_type,
-			ar.name AS user_roles
+			ar.name AS user_roles,
+			m.password
 		FROM
 			member m
 		INNER JOIN
@@ -1034,28 +1899,20 @@ func (member *MemberRepo) GetBasicMemberDetailsByEmail(partnerID string, args en
 			ON mar.member_id = m.id
 		INNER JOIN
 			access_role ar
-			ON ar.id = mar.role_id
-		WHERE
-			m.partner_id = $1
-		AND 
-			m.email = $2
-	`
-
-	hashedPassword, err := crypto.Hash(args.Password)
-	if err != nil {
-		return basicMemberData, err
-	}
-
-	if args.Provider == consts.ProviderInternal {
-		getBasicMemberDataQ = fmt.Sprintf("%s AND m.password = '%s'", getBasicMemberDataQ, hashedPassword)
-	}
+			ON ar.id = mar.role_id`
 
-	rows, err := member.db.QueryContext(ctx, getBasicMemberDataQ, partnerID, args.Email)
+// scanBasicMemberData runs a basicMemberDataQuery and collects the roles of the member. It also
+// returns the stored password hash.
+func (member *MemberRepo) scanBasicMemberData(ctx context.Context, query string, args ...any) (entities.BasicMemberData, string, error) {
+	var (
+		basicMemberData entities.BasicMemberData
+		storedPassword  sql.NullString
+	)
 
+	rows, err := member.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return basicMemberData, err
+		return basicMemberData, "", err
 	}
-
 	defer rows.Close()
 
 	for rows.Next() {
@@ -1070,9 +1927,10 @@ func (member *MemberRepo) GetBasicMemberDetailsByEmail(partnerID string, args en
 			&basicMemberData.ProviderID,
 			&basicMemberData.MemberType,
 			&roles,
+			&storedPassword,
 		)
 		if err != nil {
-			return basicMemberData, err
+			return basicMemberData, "", err
 		}
 
 		// Check for NULL roles and append them if they are not NULL
@@ -1082,10 +1940,37 @@ func (member *MemberRepo) GetBasicMemberDetailsByEmail(partnerID string, args en
 	}
 
 	if err := rows.Err(); err != nil {
-		return basicMemberData, err
+		return basicMemberData, "", err
 	}
+	return basicMemberData, storedPassword.String, nil
+}
 
-	return basicMemberData, nil
+// verifyPassword checks the password against the stored hash. Hashes of a legacy algorithm or
+// with outdated parameters are replaced by a hash with the configured ones once the password
+// matched. Only an unchanged hash is replaced, so a concurrent password change wins.
+func (member *MemberRepo) verifyPassword(ctx context.Context, memberID uuid.UUID, password, storedHash string) (bool, error) {
+	match, rehash, err := member.hasher.Verify(password, storedHash)
+	if errors.Is(err, hashing.ErrUnknownHash) {
+		// Members without a usable hash, such as OAuth members, cannot log in with a password
+		return false, nil
+	}
+	if err != nil || !match {
+		return false, err
+	}
+
+	if rehash {
+		newHash, err := member.hasher.Hash(password)
+		if err == nil {
+			_, err = member.db.ExecContext(ctx, `
+				UPDATE member SET password = $1 WHERE id = $2 AND password = $3
+			`, newHash, memberID, storedHash)
+		}
+		if err != nil {
+			// The login succeeds with the old hash, the upgrade is retried on the next one
+			log.Log().WithContext(ctx).Errorf("Password rehash failed for member %s: %s", memberID, err.Error())
+		}
+	}
+	return true, nil
 }
 
 func (member *MemberRepo) Middleware(ctx context.Context, token string) (string, error) {
@@ -1164,10 +2049,12 @@ FROM (
         country c ON c.iso = m.country_code
     WHERE
         m.is_deleted = $6
+`
+	scope, params := tenant.Condition(ctx, "m.partner_id", []any{true, false, false, true, false, false})
+	query += scope + `
 ) AS subquery;
-
 `
-	row := member.db.QueryRowContext(ctx, query, true, false, false, true, false, false)
+	row := member.db.QueryRowContext(ctx, query, params...)
 
 	if err := row.Scan(&totalCount); err != nil {
 		logger.Log().WithContext(ctx).Errorf("Getting Member count failed, QueryRowContext failed, err=%s", err.Error())
@@ -1177,16 +2064,218 @@ FROM (
 	return totalCount, nil
 }
 
+// memberListColumns are the columns of a member in the listing, scanned by scanMemberListRow.
+const memberListColumns = `
+		m.id,
+		COALESCE(CONCAT(m.firstname, ' ', m.lastname), ''),
+		COALESCE(m.gender, ''),
+		m.member_role_id,
+		l.name,
+		COALESCE(p.name, ''),
+		m.email,
+		COALESCE(m.country_code, ''),
+		COALESCE(c.name, ''),
+		m.is_active,
+		m.created_on`
+
+// memberListFrom joins the tables of the member listing.
+const memberListFrom = `
+	FROM member m
+	INNER JOIN lookup l ON l.id = m.member_role_id
+	INNER JOIN partner p ON p.id = m.partner_id
+	LEFT JOIN country c ON c.iso = m.country_code
+	WHERE m.is_deleted = false`
+
+// memberListConditions returns the conditions of the filter, to be appended to memberListFrom,
+// and their arguments. The listing position is not part of the conditions.
+func memberListConditions(ctx context.Context, filter entities.MemberListFilter) (string, []any) {
+	var (
+		conditions strings.Builder
+		args       []any
+	)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		fmt.Fprintf(&conditions, " AND "+condition, len(args))
+	}
+
+	if filter.Active != nil {
+		add("m.is_active = $%d", *filter.Active)
+	}
+	if len(filter.Roles) != 0 {
+		add("m.member_role_id = ANY($%d)", pq.Array(filter.Roles))
+	}
+	if len(filter.Countries) != 0 {
+		add("m.country_code = ANY($%d)", pq.Array(filter.Countries))
+	}
+	if filter.Partner != uuid.Nil {
+		add("m.partner_id = $%d", filter.Partner)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		add("m.created_on >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		add("m.created_on < $%d", filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+filter.Search+"%")
+		fmt.Fprintf(&conditions, " AND (p.name ILIKE $%[1]d OR CONCAT(m.firstname, ' ', m.lastname) ILIKE $%[1]d OR m.email ILIKE $%[1]d)", len(args))
+	}
+
+	scope, args := tenant.Condition(ctx, "m.partner_id", args)
+	conditions.WriteString(scope)
+	return conditions.String(), args
+}
+
+// ListMembers returns a page of the members matching the filter in the keyset order of
+// filter.Sort, starting after filter.After. The page is read with one query and the album,
+// track and artist counts of its members with another one. The returned cursor is the
+// position of the last member when there are more members to list, nil otherwise.
+func (member *MemberRepo) ListMembers(ctx context.Context, filter entities.MemberListFilter) ([]entities.ViewMembers, *entities.MemberCursor, error) {
+	conditions, args := memberListConditions(ctx, filter)
+
+	sortColumn := "m.created_on"
+	if filter.Sort == consts.MemberSortEmail {
+		sortColumn = "m.email"
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Order == consts.OrderDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		var after any = filter.After.Value
+		if filter.Sort == consts.MemberSortCreatedOn {
+			createdOn, err := time.Parse(time.RFC3339Nano, filter.After.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			after = createdOn
+		}
+		args = append(args, after, filter.After.ID)
+		conditions += fmt.Sprintf(" AND (%s, m.id) %s ($%d, $%d)", sortColumn, comparison, len(args)-1, len(args))
+	}
+
+	// One more member than the page holds tells whether there is a next page.
+	args = append(args, filter.Limit+1)
+	query := `SELECT` + memberListColumns + memberListFrom + conditions +
+		fmt.Sprintf(" ORDER BY %[1]s %[2]s, m.id %[2]s LIMIT $%[3]d", sortColumn, direction, len(args))
+
+	rows, err := member.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	members := make([]entities.ViewMembers, 0, filter.Limit+1)
+	createdOn := make([]time.Time, 0, filter.Limit+1)
+	for rows.Next() {
+		var (
+			row     entities.ViewMembers
+			created time.Time
+		)
+		err := rows.Scan(&row.MemberId, &row.Name, &row.Gender, &row.Role.Id, &row.Role.Name, &row.PartnerName,
+			&row.Email, &row.Country.Code, &row.Country.Name, &row.Active, &created)
+		if err != nil {
+			return nil, nil, err
+		}
+		members = append(members, row)
+		createdOn = append(createdOn, created)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *entities.MemberCursor
+	if len(members) > int(filter.Limit) {
+		members = members[:filter.Limit]
+		last := members[len(members)-1]
+		next = &entities.MemberCursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: createdOn[len(members)-1].Format(time.RFC3339Nano),
+			ID:    last.MemberId,
+		}
+		if filter.Sort == consts.MemberSortEmail {
+			next.Value = last.Email
+		}
+	}
+
+	return members, next, member.attachMemberCounts(ctx, members)
+}
+
+// CountMembers returns the number of members matching the filter.
+func (member *MemberRepo) CountMembers(ctx context.Context, filter entities.MemberListFilter) (int64, error) {
+	conditions, args := memberListConditions(ctx, filter)
+
+	var count int64
+	err := member.db.QueryRowContext(ctx, `SELECT COUNT(*)`+memberListFrom+conditions, args...).Scan(&count)
+	return count, err
+}
+
+// attachMemberCounts loads the album, track and artist counts of the members in one query.
+func (member *MemberRepo) attachMemberCounts(ctx context.Context, members []entities.ViewMembers) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(members))
+	index := make(map[uuid.UUID]int, len(members))
+	for i, m := range members {
+		ids = append(ids, m.MemberId.String())
+		index[m.MemberId] = i
+	}
+
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT member_id, 'album', COUNT(id) FROM product
+		WHERE member_id = ANY($1::uuid[]) AND is_active = true AND is_deleted = false
+		GROUP BY member_id
+		UNION ALL
+		SELECT member_id, 'track', COUNT(id) FROM track
+		WHERE member_id = ANY($1::uuid[]) AND is_deleted = false
+		GROUP BY member_id
+		UNION ALL
+		SELECT member_id, 'artist', COUNT(id) FROM artist
+		WHERE member_id = ANY($1::uuid[]) AND is_active = true AND is_deleted = false
+		GROUP BY member_id
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			memberID uuid.UUID
+			kind     string
+			count    int
+		)
+		if err := rows.Scan(&memberID, &kind, &count); err != nil {
+			return err
+		}
+		m := &members[index[memberID]]
+		switch kind {
+		case "album":
+			m.AlbumCount = count
+		case "track":
+			m.TrackCount = count
+		case "artist":
+			m.ArtistCount = count
+		}
+	}
+	return rows.Err()
+}
+
 // Get Count of Billing Address
 // getBillingAddressCountForMember fetches the count of billing addresses for a given member ID.
 func (member *MemberRepo) GetBillingAddressCountForMember(ctx *gin.Context, memberID uuid.UUID) (int, error) {
 	var billingAddressCount int
 
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
 	err := member.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM member_billing_address
 		WHERE member_id = $1
-	`, memberID).Scan(&billingAddressCount)
+	`+scope, params...).Scan(&billingAddressCount)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to check billing address count: %v", err)
@@ -1196,7 +2285,8 @@ func (member *MemberRepo) GetBillingAddressCountForMember(ctx *gin.Context, memb
 }
 
 // InitiatePasswordReset initiates the password reset process for a member.
-func (member *MemberRepo) InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, error) {
+// It stores the hash of a new random reset key and returns the key with its expiration time.
+func (member *MemberRepo) InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, time.Time, error) {
 	var dbEmail string
 
 	// Execute the SQL query to fetch the email for the given memberID
@@ -1205,59 +2295,125 @@ func (member *MemberRepo) InitiatePasswordReset(ctx *gin.Context, memberID uuid.
 				`, memberID).Scan(&dbEmail)
 
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
 	// Check if the fetched email from the database matches the incoming email
 	if dbEmail != email {
-		return "", fmt.Errorf("email mismatch: provided email does not match with current email ")
+		return "", time.Time{}, fmt.Errorf("email mismatch: provided email does not match with current email ")
 	}
 
 	// If the emails match, proceed to generate a reset key
-	NewResetKey, err := GenerateResetKey(ctx, dbEmail) // Implement the logic to generate a reset key
-
+	newResetKey, err := GenerateResetKey()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate reset key: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to generate reset key: %v", err)
 	}
 
-	// Update the member table to set the reset password key and its expiration timestamp for the row with matching email
-	expirationTime := time.Now().Add(50 * time.Minute) // Calculate expiration time: current time + 10 minutes
+	// Only the hash of the key is stored, along with its expiration timestamp
+	expirationTime := time.Now().Add(member.Cfg.PasswordReset.TTL)
 	_, err = member.db.ExecContext(ctx, `
 					UPDATE public.member 
 					SET reset_password_key = $1, 
 					password_expiry = $2 
-					WHERE email = $3;
-				`, NewResetKey, expirationTime, dbEmail)
+					WHERE id = $3;
+				`, HashResetKey(newResetKey), expirationTime, memberID)
 
 	if err != nil {
-		panic(fmt.Errorf("failed to update reset key and expiration timestamp in member table: %v", err))
+		return "", time.Time{}, fmt.Errorf("failed to update reset key and expiration timestamp in member table: %v", err)
 	}
 
 	// Return the generated reset key
-	return NewResetKey, nil
+	return newResetKey, expirationTime, nil
 }
 
-// generateResetKey generates a reset key using the user's email and current timestamp.
-// the length of reset key is 6 digits
+// GenerateResetKey generates a cryptographically random reset key, hex encoded.
+func GenerateResetKey() (string, error) {
+	buf := make([]byte, consts.ResetKeyBytes)
+	if _, err := cryptoRand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
 
-func GenerateResetKey(ctx *gin.Context, email string) (string, error) {
-	// Generate a unique string using the email and current timestamp
-	uniqueString := fmt.Sprintf("%s-%d", email, time.Now().UnixNano())
+// HashResetKey returns the hex encoded SHA-256 hash under which a reset key is stored.
+func HashResetKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
 
-	// Hash the unique string using SHA-256 to create a secure reset key
-	hash := sha256.New()
-	_, err := hash.Write([]byte(uniqueString))
+// emailVerificationQuery selects the verification state of members along with the policy of
+// their partner.
+const emailVerificationQuery = `
+	SELECT m.id, COALESCE(m.firstname, ''), m.email, COALESCE(m.language_code, ''), m.email_verified_at,
+		COALESCE(p.block_login, false), COALESCE(p.block_checkout, false)
+	FROM member m
+	LEFT JOIN partner_email_verification_policy p ON p.partner_id = m.partner_id
+	WHERE m.is_deleted = false`
+
+// scanEmailVerification reads a row selected by emailVerificationQuery.
+func scanEmailVerification(row *sql.Row) (entities.EmailVerification, error) {
+	var (
+		verification entities.EmailVerification
+		verifiedAt   sql.NullTime
+	)
+	err := row.Scan(&verification.MemberID, &verification.Name, &verification.Email, &verification.Language,
+		&verifiedAt, &verification.Policy.BlockLogin, &verification.Policy.BlockCheckout)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate hash: %v", err)
+		return verification, err
+	}
+	if verifiedAt.Valid {
+		verification.VerifiedAt = &verifiedAt.Time
+	}
+	return verification, nil
+}
+
+// GetEmailVerification returns the email verification state of the member. It returns
+// sql.ErrNoRows when the member does not exist.
+func (member *MemberRepo) GetEmailVerification(ctx context.Context, memberID uuid.UUID) (entities.EmailVerification, error) {
+	scope, args := tenant.Condition(ctx, "m.partner_id", []any{memberID})
+	return scanEmailVerification(member.db.QueryRowContext(ctx, emailVerificationQuery+" AND m.id = $1"+scope, args...))
+}
+
+// GetEmailVerificationByEmail returns the email verification state of the partner's member
+// registered with the email. It returns sql.ErrNoRows when there is no such member.
+func (member *MemberRepo) GetEmailVerificationByEmail(ctx context.Context, partnerID, email string) (entities.EmailVerification, error) {
+	scope, args := tenant.Condition(ctx, "m.partner_id", []any{partnerID, email})
+	return scanEmailVerification(member.db.QueryRowContext(ctx,
+		emailVerificationQuery+" AND m.partner_id = $1 AND LOWER(m.email) = LOWER($2)"+scope, args...))
+}
+
+// MarkEmailVerified records the verification of the member's email. The email is matched
+// again so a verification racing an email change has no effect.
+func (member *MemberRepo) MarkEmailVerified(ctx context.Context, memberID uuid.UUID, email string) error {
+	scope, args := tenant.Condition(ctx, "partner_id", []any{memberID, email})
+	_, err := member.db.ExecContext(ctx, `
+		UPDATE member SET email_verified_at = NOW()
+		WHERE id = $1 AND email = $2 AND email_verified_at IS NULL`+scope, args...)
+	return err
+}
+
+// GetEmailVerificationPolicy returns the email verification policy of the partner. Partners
+// without a policy block nothing.
+func (member *MemberRepo) GetEmailVerificationPolicy(ctx context.Context, partnerID uuid.UUID) (entities.EmailVerificationPolicy, error) {
+	var policy entities.EmailVerificationPolicy
+	err := member.db.QueryRowContext(ctx, `
+		SELECT block_login, block_checkout FROM partner_email_verification_policy WHERE partner_id = $1`,
+		partnerID).Scan(&policy.BlockLogin, &policy.BlockCheckout)
+	if errors.Is(err, sql.ErrNoRows) {
+		return policy, nil
 	}
+	return policy, err
+}
 
-	// Convert the hashed bytes to a hexadecimal string (reset key)
-	resetKey := hex.EncodeToString(hash.Sum(nil))
-	hasher := sha256.New()
-	hasher.Write([]byte(resetKey))
-	NewKey := hex.EncodeToString(hasher.Sum(nil))
-	NewResetKey := NewKey[:16]
-	return NewResetKey, nil
+// UpdateEmailVerificationPolicy stores the email verification policy of the partner.
+func (member *MemberRepo) UpdateEmailVerificationPolicy(ctx context.Context, partnerID uuid.UUID, policy entities.EmailVerificationPolicy) error {
+	_, err := member.db.ExecContext(ctx, `
+		INSERT INTO partner_email_verification_policy (partner_id, block_login, block_checkout)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (partner_id) DO UPDATE
+		SET block_login = EXCLUDED.block_login, block_checkout = EXCLUDED.block_checkout, updated_on = NOW()`,
+		partnerID, policy.BlockLogin, policy.BlockCheckout)
+	return err
 }
 
 // Function to check if country exists
@@ -1294,14 +2450,23 @@ func (member *MemberRepo) StateExists(stateName string, countryCode string) (boo
 	return exists, nil
 }
 
-// Function to check if the billing address already exists for the member.
-func (member *MemberRepo) BillingAddressExists(ctx *gin.Context, memberID uuid.UUID, billingAddress entities.BillingAddress) (bool, error) {
+// BillingAddressExists checks if the member has another billing address than exceptID equal to the
+// normalized billing address. Stored addresses are compared ignoring case and spacing, they may
+// predate the normalization.
+func (member *MemberRepo) BillingAddressExists(ctx *gin.Context, memberID uuid.UUID, billingAddress entities.BillingAddress, exceptID uuid.UUID) (bool, error) {
 	var addressExists int
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, billingAddress.Address,
+		billingAddress.Zipcode, billingAddress.Country, billingAddress.State, exceptID})
 	err := member.db.QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM member_billing_address
-		WHERE member_id = $1 AND address = $2 AND zip = $3 AND country_code = $4 AND state_code = $5
-	`, memberID, billingAddress.Address, billingAddress.Zipcode, billingAddress.Country, billingAddress.State).Scan(&addressExists)
+		WHERE member_id = $1
+			AND lower(regexp_replace(btrim(address), '\s+', ' ', 'g')) = lower($2)
+			AND upper(replace(zip, ' ', '')) = replace($3, ' ', '')
+			AND upper(country_code) = $4
+			AND upper(coalesce(state_code, '')) = $5
+			AND id <> $6`+scope+`
+	`, params...).Scan(&addressExists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check address existence: %v", err)
 	}
@@ -1311,13 +2476,14 @@ func (member *MemberRepo) BillingAddressExists(ctx *gin.Context, memberID uuid.U
 // Function to ensure there isn't already a primary billing address for the member.
 func (member *MemberRepo) HasPrimaryBilling(ctx *gin.Context, memberID uuid.UUID) (bool, error) {
 	var hasPrimaryBilling bool
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
 	err := member.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT 1 
 			FROM public.member_billing_address 
-			WHERE member_id = $1 AND is_primary_billing = TRUE
+			WHERE member_id = $1 AND is_primary_billing = TRUE`+scope+`
 		)
-	`, memberID).Scan(&hasPrimaryBilling)
+	`, params...).Scan(&hasPrimaryBilling)
 	if err != nil {
 		return false, fmt.Errorf("failed to check primary billing address existence: %v", err)
 	}
@@ -1332,13 +2498,14 @@ func (member *MemberRepo) CheckBillingAddressRelation(ctx context.Context, membe
 	var billingExists bool
 
 	// Check if the billing address exists by its ID.
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{billingAddressID})
 	err := member.db.QueryRowContext(ctx, `
         SELECT EXISTS (
             SELECT 1 
             FROM public.member_billing_address 
-            WHERE id = $1
+            WHERE id = $1`+scope+`
         )
-    `, billingAddressID).Scan(&billingExists)
+    `, params...).Scan(&billingExists)
 
 	if err != nil {
 		return false, fmt.Errorf("failed to check if billing address exists: %v", err)
@@ -1350,13 +2517,14 @@ func (member *MemberRepo) CheckBillingAddressRelation(ctx context.Context, membe
 
 	// Check if the billing address ID is associated with the given member ID.
 	var validRelation bool
+	scope, params = tenant.MemberCondition(ctx, "member_id", []any{billingAddressID, memberID})
 	err = member.db.QueryRowContext(ctx, `
         SELECT EXISTS (
             SELECT 1
             FROM public.member_billing_address 
-            WHERE id = $1 AND member_id = $2
+            WHERE id = $1 AND member_id = $2`+scope+`
         )
-    `, billingAddressID, memberID).Scan(&validRelation)
+    `, params...).Scan(&validRelation)
 
 	if err != nil {
 		return false, fmt.Errorf("failed to verify relationship between billing address and member: %v", err)
@@ -1372,13 +2540,16 @@ func (member *MemberRepo) GetBillingAddressByID(ctx context.Context, memberBilli
 
 	// Example: Fetch from a database
 	var billingAddress entities.BillingAddress
-	query := "SELECT address, zip, country_code, state_code, is_primary_billing FROM member_billing_address WHERE id = $1"
-	err := member.db.QueryRowContext(ctx, query, memberBillingID).Scan(
+	query := "SELECT id, address, zip, country_code, state_code, is_primary_billing, version FROM member_billing_address WHERE id = $1"
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberBillingID})
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(
+		&billingAddress.ID,
 		&billingAddress.Address,
 		&billingAddress.Zipcode,
 		&billingAddress.Country, // Assuming you have a corresponding field in your entities.BillingAddress struct
 		&billingAddress.State,   // Assuming you have a corresponding field in your entities.BillingAddress struct
 		&billingAddress.Primary, // Assuming you have a corresponding field in your entities.BillingAddress struct
+		&billingAddress.Version,
 	)
 
 	if err != nil {
@@ -1393,12 +2564,13 @@ func (member *MemberRepo) GetBillingAddressByID(ctx context.Context, memberBilli
 
 // GetMemberByID fetches a member by their ID from the database
 func (member *MemberRepo) GetMemberByID(ctx context.Context, memberID uuid.UUID) (entities.MemberByID, error) {
-	query := `SELECT title, firstname, lastname, country_code, state_code, zip, mobile, city, address1, address2 
+	query := `SELECT title, firstname, lastname, country_code, state_code, zip, mobile, city, address1, address2, version
               FROM member 
               WHERE id = $1`
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
 
 	var memberInfo entities.MemberByID
-	err := member.db.QueryRowContext(ctx, query, memberID).Scan(
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(
 		&memberInfo.Title,
 		&memberInfo.FirstName,
 		&memberInfo.LastName,
@@ -1409,6 +2581,7 @@ func (member *MemberRepo) GetMemberByID(ctx context.Context, memberID uuid.UUID)
 		&memberInfo.City,
 		&memberInfo.Address1,
 		&memberInfo.Address2,
+		&memberInfo.Version,
 	)
 
 	if err != nil {
@@ -1443,9 +2616,10 @@ func (member *MemberRepo) GetResetKey(ctx context.Context, memberID uuid.UUID) s
 // Returns true if the email is associated with the memberID, otherwise false.
 func (member *MemberRepo) CheckEmailForMemberID(ctx *gin.Context, memberID uuid.UUID, email string) (bool, error) {
 	query := `SELECT id FROM member WHERE id = $1 AND email = $2`
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID, email})
 
 	var foundID uuid.UUID
-	err := member.db.QueryRowContext(ctx, query, memberID, email).Scan(&foundID)
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&foundID)
 	if err == sql.ErrNoRows {
 		// No matching record found
 		return false, nil
@@ -1459,6 +2633,19 @@ func (member *MemberRepo) CheckEmailForMemberID(ctx *gin.Context, memberID uuid.
 	return foundID == memberID, nil
 }
 
+// GetMemberContact returns the name, email and preferred language used to notify a member.
+func (member *MemberRepo) GetMemberContact(ctx context.Context, memberID uuid.UUID) (entities.MemberContact, error) {
+	var contact entities.MemberContact
+	query := `SELECT COALESCE(firstname, ''), email, COALESCE(language_code, '') FROM member WHERE id = $1`
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
+
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&contact.Name, &contact.Email, &contact.Language)
+	if err != nil {
+		return contact, err
+	}
+	return contact, nil
+}
+
 // CheckEmailProviderRelation checks if the provided email is associated with the given provider.
 func (member *MemberRepo) CheckEmailProviderRelation(ctx *gin.Context, email string, provider string) (bool, error) {
 	var exists bool
@@ -1485,13 +2672,15 @@ func (member *MemberRepo) CheckEmailProviderRelation(ctx *gin.Context, email str
 	return exists, nil
 }
 
-// PasswordMemberRelation checks if the hashedPassword matches the password for a given memberID
-func (member *MemberRepo) PasswordMemberRelation(ctx *gin.Context, memberID uuid.UUID, hashedPassword string) (bool, error) {
+// PasswordMemberRelation checks if the password matches the stored password hash for a given memberID,
+// upgrading a legacy hash when it does
+func (member *MemberRepo) PasswordMemberRelation(ctx *gin.Context, memberID uuid.UUID, password string) (bool, error) {
 	var storedPassword string
 	query := `SELECT password FROM public.member WHERE id = $1`
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
 
 	// Query the database to get the stored password for the memberID
-	err := member.db.QueryRowContext(ctx, query, memberID).Scan(&storedPassword)
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&storedPassword)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Handle the case where no rows are returned (memberID not found)
@@ -1501,21 +2690,18 @@ func (member *MemberRepo) PasswordMemberRelation(ctx *gin.Context, memberID uuid
 		return false, fmt.Errorf("error querying database: %v", err)
 	}
 
-	// Check if the stored password matches the hashedPassword
-	if storedPassword == hashedPassword {
-		return true, nil // Passwords match
-	}
-
-	return false, nil // Passwords do not match
+	// Check if the password matches the stored hash
+	return member.verifyPassword(ctx, memberID, password, storedPassword)
 }
 
 // CountPrimaryBillingAddresses returns the count of records with is_primary_billing set to true for a given memberID
 func (member *MemberRepo) CountPrimaryBillingAddresses(ctx *gin.Context, memberID uuid.UUID) (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM public.member_billing_address WHERE member_id = $1 AND is_primary_billing = true`
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
 
 	// Query the database to get the count of primary billing addresses for the memberID
-	err := member.db.QueryRowContext(ctx, query, memberID).Scan(&count)
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&count)
 	if err != nil {
 		// Handle errors
 		return 0, fmt.Errorf("error querying database: %v", err)
@@ -1530,9 +2716,10 @@ func (member *MemberRepo) CountTotalAddressesForMember(ctx *gin.Context, memberI
 
 	// SQL query to count the total addresses for the given member_id
 	query := `SELECT COUNT(*) FROM public.member_billing_address WHERE member_id = $1`
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
 
 	// Execute the SQL query and scan the result into the count variable
-	err := member.db.QueryRowContext(ctx, query, memberID).Scan(&count)
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&count)
 	if err != nil {
 		// Handle the error if any
 		return 0, fmt.Errorf("failed to fetch total addresses for member: %v", err)
@@ -1557,12 +2744,17 @@ func (member *MemberRepo) CountTotalAddressesForMember(ctx *gin.Context, memberI
 //   - checkoutData (entities.CheckoutSubscription): The checkout data, including SubscriptionID and PaymentGatewayID.
 //
 // Returns:
+//   - string: The ID of the new member subscription.
 //   - error: An error if any database operation fails.
-func (member *MemberRepo) HandleSubscriptionCheckout(ctx context.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription) error {
+//
+// Free subscriptions are active right away, paid ones stay in processing until their payment is captured.
+// The promo code of a discounted checkout is redeemed along with the subscription, it returns
+// consts.ErrPromoCodeLimitReached when the code cannot be redeemed any more.
+func (member *MemberRepo) HandleSubscriptionCheckout(ctx context.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription) (string, error) {
 	// Start a transaction.
 	tx, err := member.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer func() {
 		if err != nil {
@@ -1570,6 +2762,10 @@ func (member *MemberRepo) HandleSubscriptionCheckout(ctx context.Context, member
 		}
 	}()
 
+	if err = checkMemberInScope(ctx, tx, memberID); err != nil {
+		return "", err
+	}
+
 	var currencyID, subscriptionDurationValue int
 
 	// Query to fetch currency_id
@@ -1579,9 +2775,9 @@ func (member *MemberRepo) HandleSubscriptionCheckout(ctx context.Context, member
 				WHERE id = $1
 			`, checkoutData.SubscriptionID)
 
-	if err := row.Scan(&currencyID); err != nil {
+	if err = row.Scan(&currencyID); err != nil {
 
-		return err
+		return "", err
 	}
 
 	// Fetch the subscription duration value from the database
@@ -1598,66 +2794,89 @@ func (member *MemberRepo) HandleSubscriptionCheckout(ctx context.Context, member
 	err = member.db.QueryRowContext(ctx, query, checkoutData.SubscriptionID).Scan(&subscriptionDurationValue)
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Check if the subscription is free
 	isFree, err := member.IsFreeSubscription(ctx, checkoutData.SubscriptionID)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Calculate the expiration date
 	expirationDate := time.Now().Add(time.Duration(subscriptionDurationValue) * 24 * time.Hour)
 
+	// Paid subscriptions become active once the payment is captured
+	status := consts.SubscriptionStatusActive
+	if !isFree {
+		status = consts.SubscriptionStatusProcessing
+	}
+
 	combinedQuery := `
 		INSERT INTO member_subscription 
 		(member_id, subscription_id, expiration_date, member_subscription_status_id, custom_name)
 		VALUES 
-		($1, $2, $3, (SELECT id FROM member_subscription_status WHERE name = 'active'), $4)
-		RETURNING member_subscription_status_id
+		($1, $2, $3, (SELECT id FROM member_subscription_status WHERE name = $5), $4)
+		RETURNING id
 	`
 
 	// Execute the combined query within the transaction.
-	result, err := tx.ExecContext(ctx, combinedQuery, memberID, checkoutData.SubscriptionID, expirationDate, checkoutData.CustomName)
+	var memberSubscriptionID string
+	err = tx.QueryRowContext(ctx, combinedQuery, memberID, checkoutData.SubscriptionID, expirationDate, checkoutData.CustomName, status).
+		Scan(&memberSubscriptionID)
 	if err != nil {
-		return err
+		return "", err
 	}
-	rowsAffected, err := result.RowsAffected()
 
-	if err != nil {
-		return err
+	// Promo code details are stored along with the payment details of the checkout
+	discountDetails := []byte("{}")
+	if checkoutData.Discount != nil {
+		err = redeemPromoCode(ctx, tx, memberID, memberSubscriptionID, *checkoutData.Discount)
+		if err != nil {
+			return "", err
+		}
+		discountDetails, err = json.Marshal(map[string]interface{}{
+			"promo_code":      checkoutData.Discount.Code,
+			"discount_amount": checkoutData.Discount.Amount,
+		})
+		if err != nil {
+			return "", err
+		}
 	}
 
-	if rowsAffected == 1 {
-		// Insertion was successful
-		// You can perform additional actions or return a success message if needed
-		fmt.Println("Insertion successful")
-	} else {
-		// No rows were affected, indicating the insertion failed
-		return errors.New("Insertion failed")
-	}
 	// If the subscription is not free, insert into member_payout_gateway
 	if !isFree {
-		_, err = member.db.ExecContext(ctx, `
+		_, err = tx.ExecContext(ctx, `
 			INSERT INTO member_payout_gateway (member_id, payment_gateway_id, currency_id, payment_details)
 			SELECT $1, $2, sp.currency_id, 
-			('{"payment_amount": ' || sp.amount || ', "tax_percentage": ' || sp.tax_percentage || ' }')::jsonb
+			('{"payment_amount": ' || sp.amount || ', "tax_percentage": ' || sp.tax_percentage || ' }')::jsonb || $4::jsonb
 			FROM subscription_plan sp
 			WHERE sp.id = $3
-		`, memberID, checkoutData.PaymentGatewayID, checkoutData.SubscriptionID)
+		`, memberID, checkoutData.PaymentGatewayID, checkoutData.SubscriptionID, string(discountDetails))
 
 		if err != nil {
-			return err
+			return "", err
 		}
 	}
 
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionCheckedOut, consts.AggregateMemberSubscription, memberSubscriptionID,
+		entities.MemberSubscriptionEventData{
+			MemberSubscriptionID: memberSubscriptionID,
+			MemberID:             memberID,
+			SubscriptionID:       checkoutData.SubscriptionID,
+			Status:               status,
+			ExpirationDate:       &expirationDate,
+		})
+	if err != nil {
+		return "", err
+	}
+
 	// Commit the transaction.
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return memberSubscriptionID, nil
 }
 
 // CheckSubscriptionExistenceAndStatus checks if the given subscription ID exists in the database
@@ -1730,19 +2949,24 @@ func (member *MemberRepo) GetSubscriptionCountForLastYear(ctx *gin.Context, memb
 	currentYearStart := time.Now().AddDate(-1, 0, 0).Format("2006-01-02 15:04:05")
 	currentYearEnd := time.Now().Format("2006-01-02 15:04:05")
 
-	// SQL query to count subscriptions for the given memberID and subscriptionID within the last year
+	// SQL query to count subscriptions for the given memberID and subscriptionID within the last year.
+	// Checkouts whose payment failed never became a subscription and do not use up the limit.
 	query := `
 		SELECT COUNT(*) 
-		FROM public.member_subscription 
-		WHERE member_id = $1 
-		AND subscription_id = $2 
-		AND created_on BETWEEN $3 AND $4;
+		FROM public.member_subscription AS ms
+		INNER JOIN public.member_subscription_status AS mss ON mss.id = ms.member_subscription_status_id
+		WHERE ms.member_id = $1 
+		AND ms.subscription_id = $2 
+		AND ms.created_on BETWEEN $3 AND $4
+		AND mss.name <> $5
 	`
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberID, subscriptionID, currentYearStart,
+		currentYearEnd, consts.SubscriptionStatusPaymentFailed})
 
 	var count int
 
 	// Execute the SQL query and scan the result into the count variable
-	err := member.db.QueryRowContext(ctx, query, memberID, subscriptionID, currentYearStart, currentYearEnd).Scan(&count)
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch subscription count: %v", err)
 	}
@@ -1782,6 +3006,16 @@ func (member *MemberRepo) GetMaxSubscriptionLimitForID(ctx *gin.Context, subscri
 	return subscriptionLimit, nil
 }
 
+// HasPaidSubscriptionPlans reports whether any plan version, retired ones included, is charged
+// through a payment gateway.
+func (member *MemberRepo) HasPaidSubscriptionPlans(ctx context.Context) (bool, error) {
+	var hasPaidPlans bool
+	err := member.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM subscription_plan WHERE NOT COALESCE(is_free_subscription, false))
+	`).Scan(&hasPaidPlans)
+	return hasPaidPlans, err
+}
+
 // IsFreeSubscription checks if a subscription is free.
 // Parameters:
 //   - ctx (context.Context): The context for the database operation.
@@ -1852,14 +3086,15 @@ func (member *MemberRepo) CheckIfMemberSubscribedToFreePlan(ctx *gin.Context, me
 
 	// Check if the member is subscribed to this free subscription
 	var hasSubscribed bool
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, subscriptionID})
 	err = member.db.QueryRowContext(ctx, `
         SELECT EXISTS (
             SELECT 1
             FROM public.member_subscription
             WHERE member_id = $1
-            AND subscription_id = $2
+            AND subscription_id = $2`+scope+`
         );
-    `, memberID, subscriptionID).Scan(&hasSubscribed)
+    `, params...).Scan(&hasSubscribed)
 
 	if err != nil {
 		return false, fmt.Errorf("error checking member subscription: %s", err)
@@ -1883,16 +3118,20 @@ func (member *MemberRepo) HasSubscribedToOneTimePlan(ctx *gin.Context, memberID
 		return false, nil
 	}
 
-	// Check if the member is subscribed to this one-time subscription
+	// Check if the member is subscribed to this one-time subscription, ignoring checkouts whose payment failed
 	var hasSubscribed bool
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberID, subscriptionID,
+		consts.SubscriptionStatusPaymentFailed})
 	err = member.db.QueryRowContext(ctx, `
         SELECT EXISTS (
             SELECT 1
-            FROM public.member_subscription
-            WHERE member_id = $1
-            AND subscription_id = $2
+            FROM public.member_subscription AS ms
+            INNER JOIN public.member_subscription_status AS mss ON mss.id = ms.member_subscription_status_id
+            WHERE ms.member_id = $1
+            AND ms.subscription_id = $2
+            AND mss.name <> $3`+scope+`
         );
-    `, memberID, subscriptionID).Scan(&hasSubscribed)
+    `, params...).Scan(&hasSubscribed)
 
 	if err != nil {
 		return false, fmt.Errorf("error checking member subscription: %s", err)
@@ -1938,19 +3177,20 @@ func (member *MemberRepo) IsSubscriptionFree(ctx context.Context, subscriptionID
 // Function to check if a member is subscribed to a specified plan and if the plan is free.
 func (member *MemberRepo) IsMemberSubscribedToFreePlan(ctx *gin.Context, memberID uuid.UUID, MemberSubscriptionID string) (bool, error) {
 	// Query to check if the member is subscribed to the specified plan and get the corresponding subscription plan.
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberID, MemberSubscriptionID})
 	checkMemberSubscriptionQuery := `
         SELECT EXISTS (
             SELECT 1
             FROM member_subscription ms
             INNER JOIN subscription_plan sp ON ms.subscription_id = sp.id
-            WHERE ms.member_id = $1 AND ms.id = $2 AND sp.is_free_subscription = true
+            WHERE ms.member_id = $1 AND ms.id = $2 AND sp.is_free_subscription = true` + scope + `
         ) AS subscribed_to_free_plan;
     `
 
 	var subscribedToFreePlan bool
 
 	// Execute the query and scan the result into the subscribedToFreePlan variable.
-	err := member.db.QueryRowContext(ctx, checkMemberSubscriptionQuery, memberID, MemberSubscriptionID).Scan(&subscribedToFreePlan)
+	err := member.db.QueryRowContext(ctx, checkMemberSubscriptionQuery, params...).Scan(&subscribedToFreePlan)
 
 	if err != nil {
 		return false, fmt.Errorf("error checking subscription status: %w", err)
@@ -1962,14 +3202,16 @@ func (member *MemberRepo) IsMemberSubscribedToFreePlan(ctx *gin.Context, memberI
 // Function to check if a member is subscribed to a specified plan.
 func (member *MemberRepo) IsMemberSubscribedToPlan(ctx *gin.Context, memberID uuid.UUID, MemberSubscriptionID string) (bool, error) {
 	// Query to check if the member is subscribed to the specified plan.
+	// Members of another partner than the one the request is scoped to are not subscribed.
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, MemberSubscriptionID})
 	checkMemberSubscriptionQuery := `
-        SELECT EXISTS (SELECT 1 FROM member_subscription WHERE member_id = $1 AND id = $2) AS subscribed;
+        SELECT EXISTS (SELECT 1 FROM member_subscription WHERE member_id = $1 AND id = $2` + scope + `) AS subscribed;
     `
 
 	var subscribed bool
 
 	// Execute the query and scan the result into the subscribed variable.
-	err := member.db.QueryRowContext(ctx, checkMemberSubscriptionQuery, memberID, MemberSubscriptionID).Scan(&subscribed)
+	err := member.db.QueryRowContext(ctx, checkMemberSubscriptionQuery, params...).Scan(&subscribed)
 
 	if err != nil {
 		return false, fmt.Errorf("error checking subscription status: %w", err)
@@ -1978,35 +3220,16 @@ func (member *MemberRepo) IsMemberSubscribedToPlan(ctx *gin.Context, memberID uu
 	return subscribed, nil
 }
 
-// HandleSubscriptionRenewal handles the renewal of a subscription for a member.
-// Parameters:
-//   - ctx (context.Context): The context for the database operation.
-//   - memberID (uuid.UUID): The unique identifier of the member renewing the subscription.
-//   - renewal (entities.SubscriptionRenewal): The data related to subscription renewal.
-// Returns:
-//   - (error): An error if the renewal process fails.
-
-// HandleSubscriptionRenewal handles the renewal of a subscription for a member.
-func (member *MemberRepo) HandleSubscriptionRenewal(ctx context.Context, memberID uuid.UUID, checkoutData entities.SubscriptionRenewal) error {
-	tx, err := member.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		if err != nil {
-			_ = tx.Rollback()
-			return
-		}
-		err = tx.Commit()
-	}()
-
-	SubscriptionID, err := member.GetSubscriptionIDByMemberSubscriptionID(ctx, checkoutData.MemberSubscriptionID)
+// renewSubscription sets the new expiration date of a member subscription within the transaction,
+// records the renewal date and activates the subscription.
+func (member *MemberRepo) renewSubscription(ctx context.Context, tx *sql.Tx, memberID uuid.UUID, memberSubscriptionID string) error {
+	SubscriptionID, err := member.GetSubscriptionIDByMemberSubscriptionID(ctx, memberSubscriptionID)
 	if err != nil {
 		return err
 	}
 
 	// Fetch grace period details.
-	inGracePeriod, _, graceEnd, _, _, graceErr := member.IsSubscriptionInGracePeriod(ctx, memberID, checkoutData.MemberSubscriptionID)
+	inGracePeriod, _, graceEnd, _, _, graceErr := member.IsSubscriptionInGracePeriod(ctx, memberID, memberSubscriptionID)
 	if graceErr != nil {
 		return graceErr
 	}
@@ -2036,23 +3259,203 @@ func (member *MemberRepo) HandleSubscriptionRenewal(ctx context.Context, memberI
 		expirationDate = time.Now().Add(time.Duration(subscriptionDurationValue) * 7 * 24 * time.Hour)
 	}
 
-	// Insert today's date into the 'renewed_on' field.
+	// Insert today's date into the 'renewed_on' field and schedule the next automatic renewal.
 	renewedOnQuery := `
 		UPDATE member_subscription
 		SET expiration_date = $1,
-		    renewed_on = current_date
+		    renewed_on = current_date,
+		    member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = 'active'),
+		    renewal_due_on = CASE WHEN auto_renew THEN $1 - MAKE_INTERVAL(secs => $3) END,
+		    renewal_failures = 0
 		WHERE id = $2;
 	`
 
 	// Execute the update query to set the new expiration date and update 'renewed_on'.
-	_, err = tx.ExecContext(ctx, renewedOnQuery, expirationDate, checkoutData.MemberSubscriptionID)
+	_, err = tx.ExecContext(ctx, renewedOnQuery, expirationDate, memberSubscriptionID, member.Cfg.Scheduler.RenewalLead.Seconds())
 	if err != nil {
 		return err
 	}
 
+	return member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionRenewed, consts.AggregateMemberSubscription, memberSubscriptionID,
+		entities.MemberSubscriptionEventData{
+			MemberSubscriptionID: memberSubscriptionID,
+			MemberID:             memberID,
+			SubscriptionID:       SubscriptionID.String(),
+			Status:               consts.SubscriptionStatusActive,
+			ExpirationDate:       &expirationDate,
+		})
+}
+
+// SetAutoRenewal opts a member subscription in or out of automatic renewal. Opting in schedules the
+// renewal ahead of the expiration date and charges the payment gateway for it. It returns
+// sql.ErrNoRows when the member has no such subscription.
+func (member *MemberRepo) SetAutoRenewal(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string, enabled bool, paymentGatewayID int) error {
+	scope, params := tenant.MemberCondition(ctx, "member_id",
+		[]any{memberSubscriptionID, memberID, enabled, paymentGatewayID, member.Cfg.Scheduler.RenewalLead.Seconds()})
+	result, err := member.db.ExecContext(ctx, `
+		UPDATE member_subscription
+		SET auto_renew = $3,
+		    auto_renew_gateway_id = CASE WHEN $3 THEN $4::INTEGER END,
+		    renewal_due_on = CASE WHEN $3 THEN expiration_date - MAKE_INTERVAL(secs => $5) END,
+		    renewal_failures = 0
+		WHERE id = $1 AND member_id = $2`+scope, params...)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
+// ClaimDueRenewals returns up to limit subscriptions whose automatic renewal is due, earliest first,
+// and postpones their renewal by the lease so concurrent runs do not charge them twice. A renewal
+// interrupted by a replica that stopped is attempted again once the lease ends.
+func (member *MemberRepo) ClaimDueRenewals(ctx context.Context, limit int, lease time.Duration) ([]entities.RenewalCandidate, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		UPDATE member_subscription ms
+		SET renewal_due_on = NOW() + MAKE_INTERVAL(secs => $1)
+		FROM (
+			SELECT s.id, m.partner_id
+			FROM member_subscription s
+			INNER JOIN member m ON m.id = s.member_id
+			INNER JOIN member_subscription_status mss ON mss.id = s.member_subscription_status_id
+			WHERE s.auto_renew = true
+			AND s.renewal_due_on <= NOW()
+			AND mss.name = ANY($2)
+			ORDER BY s.renewal_due_on
+			LIMIT $3
+			FOR UPDATE OF s SKIP LOCKED
+		) due
+		WHERE ms.id = due.id
+		RETURNING ms.id, ms.subscription_id, ms.member_id, due.partner_id, COALESCE(ms.custom_name, ''),
+			COALESCE(ms.auto_renew_gateway_id, 0), ms.expiration_date, ms.renewal_failures
+	`, lease.Seconds(), pq.Array(consts.RenewableStatuses), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []entities.RenewalCandidate
+	for rows.Next() {
+		var candidate entities.RenewalCandidate
+		err := rows.Scan(&candidate.MemberSubscriptionID, &candidate.SubscriptionID, &candidate.MemberID, &candidate.PartnerID,
+			&candidate.CustomName, &candidate.PaymentGatewayID, &candidate.ExpirationDate, &candidate.Failures)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+// RecordRenewalAttempt records an automatic renewal attempt. A failed attempt schedules the next one
+// on NextAttemptOn, none when it is not set. A pending attempt keeps the renewal claimed until the
+// lease ends: the payment webhook renews the subscription or schedules the next attempt meanwhile,
+// and the renewal is attempted again when no webhook arrives. A succeeded one was scheduled again by
+// the renewal itself.
+func (member *MemberRepo) RecordRenewalAttempt(ctx context.Context, attempt entities.RenewalAttempt) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return recordRenewalAttempt(ctx, tx, attempt)
+}
+
+// recordRenewalAttempt records an automatic renewal attempt within the transaction, see RecordRenewalAttempt.
+func recordRenewalAttempt(ctx context.Context, tx *sql.Tx, attempt entities.RenewalAttempt) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO member_subscription_renewal_attempt
+			(member_subscription_id, member_id, attempt, status, failure_reason, next_attempt_on)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, attempt.MemberSubscriptionID, attempt.MemberID, attempt.Attempt, attempt.Status, attempt.FailureReason, attempt.NextAttemptOn)
+	if err != nil || attempt.Status != consts.RenewalFailed {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE member_subscription
+		SET renewal_due_on = $2, renewal_failures = renewal_failures + 1
+		WHERE id = $1
+	`, attempt.MemberSubscriptionID, attempt.NextAttemptOn)
+	return err
+}
+
+// scheduleRenewalRetry records the failed attempt of an automatic renewal whose payment failed after
+// it was reported pending, and schedules the next attempt on the dunning schedule.
+func (member *MemberRepo) scheduleRenewalRetry(ctx context.Context, tx *sql.Tx, payment entities.SubscriptionPayment, reason string) error {
+	var (
+		autoRenew  bool
+		failures   int
+		expiration time.Time
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT auto_renew, renewal_failures, expiration_date
+		FROM member_subscription
+		WHERE id = $1
+	`, payment.MemberSubscriptionID).Scan(&autoRenew, &failures, &expiration)
+	if err != nil || !autoRenew {
+		return err
+	}
+
+	_, _, graceEnd, _, _, err := member.IsSubscriptionInGracePeriod(ctx, payment.MemberID, payment.MemberSubscriptionID)
+	if err != nil {
+		return err
+	}
+	attempt := entities.RenewalAttempt{
+		MemberSubscriptionID: payment.MemberSubscriptionID,
+		MemberID:             payment.MemberID,
+		Attempt:              failures + 1,
+		Status:               consts.RenewalFailed,
+		FailureReason:        consts.PaymentFailed,
+	}
+	if reason != "" {
+		attempt.FailureReason = reason
+	}
+	if next, ok := renewal.Next(member.Cfg.Scheduler.RenewalDunning, expiration, graceEnd, time.Now()); ok {
+		attempt.NextAttemptOn = &next
+	}
+	return recordRenewalAttempt(ctx, tx, attempt)
+}
+
+// GetRenewalAttempts lists the automatic renewal attempts of a member subscription, the latest first.
+func (member *MemberRepo) GetRenewalAttempts(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) ([]entities.RenewalAttempt, error) {
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberSubscriptionID, memberID})
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT id, member_subscription_id, member_id, attempt, status, failure_reason, next_attempt_on, created_on
+		FROM member_subscription_renewal_attempt
+		WHERE member_subscription_id = $1 AND member_id = $2`+scope+`
+		ORDER BY created_on DESC`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []entities.RenewalAttempt{}
+	for rows.Next() {
+		var attempt entities.RenewalAttempt
+		err := rows.Scan(&attempt.ID, &attempt.MemberSubscriptionID, &attempt.MemberID, &attempt.Attempt, &attempt.Status,
+			&attempt.FailureReason, &attempt.NextAttemptOn, &attempt.CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, rows.Err()
+}
+
 // IsSubscriptionAboutToExpire checks if the subscription is about to expire.
 func (member *MemberRepo) IsSubscriptionAboutInWarning(ctx context.Context, memberSubscriptionID string) (bool, string, error) {
 	var subscriptionID uuid.UUID
@@ -2126,10 +3529,11 @@ func (member *MemberRepo) IsSubscriptionInGracePeriod(ctx context.Context, membe
         SELECT sp.can_renewable_within, ms.expiration_date
         FROM member_subscription ms
         INNER JOIN subscription_plan sp ON ms.subscription_id = sp.id
-        WHERE ms.id = $1 AND ms.member_id = $2;
+        WHERE ms.id = $1 AND ms.member_id = $2
     `
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberSubscriptionID, memberID})
 
-	err := member.db.QueryRowContext(ctx, checkGracePeriodQuery, memberSubscriptionID, memberID).Scan(&canRenewableWithin, &expirationDate)
+	err := member.db.QueryRowContext(ctx, checkGracePeriodQuery+scope, params...).Scan(&canRenewableWithin, &expirationDate)
 	if err != nil {
 		return false, time.Time{}, time.Time{}, 0, false, err
 	}
@@ -2149,31 +3553,162 @@ func (member *MemberRepo) IsSubscriptionInGracePeriod(ctx context.Context, membe
 	return false, graceStart, graceEnd, 0, false, nil
 }
 
-// CheckCancellationEnabled checks if a subscription plan allows cancellations.
-// It returns true if cancellation is enabled, otherwise false.
-func (member *MemberRepo) CheckCancellationEnabled(ctx *gin.Context, MemberSubscriptionID string) (bool, error) {
-	// SQL query to fetch the SubscriptionID column for the given MemberSubscriptionID.
-	query := `SELECT subscription_id FROM public.member_subscription WHERE id = $1`
-	var SubscriptionID string
-	err := member.db.QueryRowContext(ctx, query, MemberSubscriptionID).Scan(&SubscriptionID)
+// subscriptionLifecycleRules lists the status moves of the subscription lifecycle job. Each rule moves the
+// subscriptions in one of the from statuses whose dates satisfy the condition, p.now being the sweep time
+// and p.warning_until the end of the warning window. The grace period is can_renewable_within weeks after expiration.
+var subscriptionLifecycleRules = []struct {
+	from      []string
+	to        string
+	condition string
+}{
+	{
+		from:      []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning, consts.SubscriptionStatusInGrace},
+		to:        consts.SubscriptionStatusExpired,
+		condition: `ms.expiration_date + make_interval(weeks => COALESCE(sp.can_renewable_within, 0)) <= p.now`,
+	},
+	{
+		from: []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning},
+		to:   consts.SubscriptionStatusInGrace,
+		condition: `ms.expiration_date <= p.now
+			AND ms.expiration_date + make_interval(weeks => COALESCE(sp.can_renewable_within, 0)) > p.now`,
+	},
+	{
+		from:      []string{consts.SubscriptionStatusActive},
+		to:        consts.SubscriptionStatusWarning,
+		condition: `ms.expiration_date > p.now AND ms.expiration_date <= p.warning_until`,
+	},
+}
+
+// TransitionSubscriptionStatuses moves member subscriptions between the active, warning, grace and
+// expired statuses according to their expiration date and the plan's can_renewable_within, and records
+// an event for each move in member_subscription_event.
+//
+// The sweep runs in a single transaction guarded by a transaction level advisory lock, when another
+// replica holds the lock nothing is done. Rows are only moved from the expected statuses, so running
+// the sweep again does not produce new transitions.
+func (member *MemberRepo) TransitionSubscriptionStatuses(ctx context.Context, now time.Time) (transitions []entities.SubscriptionTransition, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch subscription ID: %v", err)
+		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
 
-	// SQL query to fetch the is_cancellation_enabled column for the retrieved SubscriptionID.
-	query = `SELECT is_cancellation_enabled FROM public.subscription_plan WHERE id = $1`
-	var isCancellationEnabled bool
-
-	// Execute the SQL query and scan the result into the isCancellationEnabled variable.
-	err = member.db.QueryRowContext(ctx, query, SubscriptionID).Scan(&isCancellationEnabled)
+	var acquired bool
+	err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock($1)`, consts.SubscriptionLifecycleLockID).Scan(&acquired)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch cancellation status: %v", err)
+		return nil, err
+	}
+	if !acquired {
+		// Another replica is sweeping.
+		return nil, nil
 	}
 
-	return isCancellationEnabled, nil
-}
-
-// HandleSubscriptionCancellation handles the cancellation process for a subscription.
+	warningUntil := now.Add(member.Cfg.Scheduler.WarningPeriod)
+	for _, rule := range subscriptionLifecycleRules {
+		moved, err := member.transitionSubscriptions(ctx, tx, rule.from, rule.to, rule.condition, now, warningUntil)
+		if err != nil {
+			return nil, fmt.Errorf("moving subscriptions to %s: %w", rule.to, err)
+		}
+		transitions = append(transitions, moved...)
+	}
+
+	for _, transition := range transitions {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO member_subscription_event (member_subscription_id, member_id, from_status, to_status, source)
+			VALUES ($1, $2, $3, $4, $5)
+		`, transition.MemberSubscriptionID, transition.MemberID, transition.FromStatus, transition.ToStatus,
+			consts.SubscriptionEventSourceLifecycle)
+		if err != nil {
+			return nil, err
+		}
+		err = member.addStatusChangedEvent(ctx, tx, transition.MemberSubscriptionID.String(), transition.MemberID,
+			transition.FromStatus, transition.ToStatus)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return transitions, nil
+}
+
+// transitionSubscriptions moves at most LifecycleBatchSize subscriptions matching the condition from
+// one of the from statuses to the to status and returns the moves.
+func (member *MemberRepo) transitionSubscriptions(ctx context.Context, tx *sql.Tx, from []string, to, condition string,
+	now, warningUntil time.Time) ([]entities.SubscriptionTransition, error) {
+
+	query := fmt.Sprintf(`
+		WITH p AS (
+			SELECT $1::timestamp AS now, $2::timestamp AS warning_until
+		),
+		candidates AS (
+			SELECT ms.id, mss.name AS from_status,
+				ms.expiration_date + make_interval(weeks => COALESCE(sp.can_renewable_within, 0)) AS grace_end
+			FROM p, member_subscription ms
+			INNER JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+			INNER JOIN subscription_plan sp ON sp.id = ms.subscription_id
+			WHERE mss.name = ANY($3)
+			AND %s
+			ORDER BY ms.expiration_date
+			LIMIT $4
+			FOR UPDATE OF ms SKIP LOCKED
+		)
+		UPDATE member_subscription ms
+		SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = $5)
+		FROM candidates c
+		WHERE ms.id = c.id
+		RETURNING ms.id, ms.member_id, COALESCE(ms.custom_name, ''), c.from_status, ms.expiration_date, c.grace_end
+	`, condition)
+
+	rows, err := tx.QueryContext(ctx, query, now, warningUntil, pq.Array(from), member.Cfg.Scheduler.LifecycleBatchSize, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []entities.SubscriptionTransition
+	for rows.Next() {
+		transition := entities.SubscriptionTransition{ToStatus: to}
+		err := rows.Scan(&transition.MemberSubscriptionID, &transition.MemberID, &transition.CustomName,
+			&transition.FromStatus, &transition.ExpirationDate, &transition.GraceEnd)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, rows.Err()
+}
+
+// CheckCancellationEnabled checks if a subscription plan allows cancellations.
+// It returns true if cancellation is enabled, otherwise false.
+func (member *MemberRepo) CheckCancellationEnabled(ctx *gin.Context, MemberSubscriptionID string) (bool, error) {
+	// SQL query to fetch the SubscriptionID column for the given MemberSubscriptionID.
+	query := `SELECT subscription_id FROM public.member_subscription WHERE id = $1`
+	var SubscriptionID string
+	err := member.db.QueryRowContext(ctx, query, MemberSubscriptionID).Scan(&SubscriptionID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch subscription ID: %v", err)
+	}
+
+	// SQL query to fetch the is_cancellation_enabled column for the retrieved SubscriptionID.
+	query = `SELECT is_cancellation_enabled FROM public.subscription_plan WHERE id = $1`
+	var isCancellationEnabled bool
+
+	// Execute the SQL query and scan the result into the isCancellationEnabled variable.
+	err = member.db.QueryRowContext(ctx, query, SubscriptionID).Scan(&isCancellationEnabled)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch cancellation status: %v", err)
+	}
+
+	return isCancellationEnabled, nil
+}
+
+// HandleSubscriptionCancellation handles the cancellation process for a subscription.
 // It performs the following steps:
 //  1. Checks if the member has subscribed to the plan.
 //  2. Checks if the associated subscription plan is active/on hold.
@@ -2187,22 +3722,39 @@ func (member *MemberRepo) CheckCancellationEnabled(ctx *gin.Context, MemberSubsc
 // Returns:
 //   - error: An error if any database operation fails.
 
-func (member *MemberRepo) HandleSubscriptionCancellation(ctx context.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription) error {
+func (member *MemberRepo) HandleSubscriptionCancellation(ctx context.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
 
 	// If all conditions are met, update the member_subscription_status to "cancelled".
 	updateStatusQuery := `
    			 UPDATE member_subscription
     		 SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = 'cancelled') 
-			 WHERE id = $1 ;
+			 WHERE id = $1 AND member_id = $2
 `
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{checkoutData.MemberSubscriptionID, memberID})
 
-	_, err := member.db.ExecContext(ctx, updateStatusQuery, checkoutData.MemberSubscriptionID)
+	_, err = tx.ExecContext(ctx, updateStatusQuery+scope, params...)
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionCancelled, consts.AggregateMemberSubscription, checkoutData.MemberSubscriptionID,
+		entities.MemberSubscriptionEventData{
+			MemberSubscriptionID: checkoutData.MemberSubscriptionID,
+			MemberID:             memberID,
+			Status:               consts.Cancelled,
+		})
 }
 
 func (member *MemberRepo) UpdatePrimaryBillingAddressToFalseAndRandom(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID) error {
@@ -2220,13 +3772,16 @@ func (member *MemberRepo) UpdatePrimaryBillingAddressToFalseAndRandom(ctx *gin.C
 	}()
 
 	// Step 1: Update the primary status of the current primary billing address to false
-	_, err = tx.ExecContext(ctx, `UPDATE member_billing_address SET is_primary_billing = false WHERE id = $1`, memberBillingID)
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberBillingID, memberID})
+	_, err = tx.ExecContext(ctx, `UPDATE member_billing_address SET is_primary_billing = false WHERE id = $1 AND member_id = $2`+scope,
+		params...)
 	if err != nil {
 		return err
 	}
 
 	// Step 2: Fetch all billing addresses for the given member except memberBillingID
-	rows, err := tx.QueryContext(ctx, `SELECT id FROM member_billing_address WHERE member_id = $1 AND id != $2`, memberID, memberBillingID)
+	scope, params = tenant.MemberCondition(ctx, "member_id", []any{memberID, memberBillingID})
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM member_billing_address WHERE member_id = $1 AND id != $2`+scope, params...)
 	if err != nil {
 		return err
 	}
@@ -2303,12 +3858,13 @@ func (member *MemberRepo) SubscriptionProductSwitch(ctx context.Context, memberI
 	)
 
 	// Check the product is already in new given subscription
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{data.ProductReferenceID, memberID})
 	err = tx.QueryRowContext(ctx, `
 	SELECT p.member_subscription_id
 	FROM product p
 	JOIN member_subscription ms ON p.member_subscription_id = ms.id
-	WHERE p.id = $1 AND ms.member_id = $2
-		`, data.ProductReferenceID, memberID).Scan(&newSubscriptionID)
+	WHERE p.id = $1 AND ms.member_id = $2`+scope+`
+		`, params...).Scan(&newSubscriptionID)
 
 	if err != nil {
 		logger.Log().WithContext(ctx).Errorf("Invalid new subscription id err=%s", err.Error())
@@ -2332,8 +3888,8 @@ func (member *MemberRepo) SubscriptionProductSwitch(ctx context.Context, memberI
 	SELECT p.member_subscription_id
 	FROM product p
 	JOIN member_subscription ms ON p.member_subscription_id = ms.id
-	WHERE p.id = $1 AND ms.member_id = $2
-		`, data.ProductReferenceID, memberID).Scan(&currentSubscriptionID)
+	WHERE p.id = $1 AND ms.member_id = $2`+scope+`
+		`, params...).Scan(&currentSubscriptionID)
 
 	if err != nil {
 		logger.Log().WithContext(ctx).Errorf("Invalid current subscription id err=%s", err.Error())
@@ -2510,6 +4066,8 @@ func (member *MemberRepo) ViewAllSubscriptions(ctx context.Context, memberID uui
         WHERE
             ms.member_id = $1
     `
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberID})
+	query += scope
 
 	if reqParam.Status != "" {
 		if reqParam.Status == consts.Active {
@@ -2564,7 +4122,7 @@ func (member *MemberRepo) ViewAllSubscriptions(ctx context.Context, memberID uui
 		query += fmt.Sprintf(" LIMIT %d OFFSET %d", reqParam.Limit, offset)
 	}
 
-	rows, err := member.db.QueryContext(ctx, query, memberID)
+	rows, err := member.db.QueryContext(ctx, query, params...)
 
 	if err != nil {
 		return nil, err
@@ -2607,171 +4165,1035 @@ func (member *MemberRepo) ViewAllSubscriptions(ctx context.Context, memberID uui
 		fmt.Println("-----------slice", subscriptions)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := member.attachSubscriptionLedgers(ctx, subscriptions); err != nil {
+		return nil, err
+	}
+
 	return subscriptions, nil
 }
 
-// GetSubscriptionRecordCount function is used to calculate and return total count of subscriptions
-func (member *MemberRepo) GetSubscriptionRecordCount(ctx context.Context, memberID uuid.UUID) (int64, error) {
+// attachSubscriptionLedgers loads the ledger entries of the listed member subscriptions, newest first,
+// and sets their balance.
+func (member *MemberRepo) attachSubscriptionLedgers(ctx context.Context, subscriptions []entities.ListAllSubscriptions) error {
+	if len(subscriptions) == 0 {
+		return nil
+	}
 
-	var totalCount int64
+	ids := make([]string, 0, len(subscriptions))
+	index := make(map[uuid.UUID]int, len(subscriptions))
+	for i, subscription := range subscriptions {
+		ids = append(ids, subscription.ID.String())
+		index[subscription.ID] = i
+	}
 
-	query := `
-		SELECT COUNT(subscription_id) AS total_count
-		FROM member_subscription 
-		WHERE member_subscription.member_id = $1
-	`
-	row := member.db.QueryRowContext(ctx, query, memberID)
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT id, member_subscription_id, member_id, kind, COALESCE(from_subscription_id::text, ''),
+			COALESCE(to_subscription_id::text, ''), credit, charge, amount, currency_id, created_on
+		FROM member_subscription_ledger
+		WHERE member_subscription_id = ANY($1::uuid[])
+		ORDER BY created_on DESC
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-	if err := row.Scan(&totalCount); err != nil {
-		logger.Log().WithContext(ctx).Errorf("GetSubscriptionRecordCount failed, QueryRowContext failed, err=%s", err.Error())
-		return 0, err
+	for rows.Next() {
+		var entry entities.SubscriptionLedgerEntry
+		var memberSubscriptionID uuid.UUID
+		err := rows.Scan(&entry.ID, &memberSubscriptionID, &entry.MemberID, &entry.Kind, &entry.FromSubscriptionID,
+			&entry.ToSubscriptionID, &entry.Credit, &entry.Charge, &entry.Amount, &entry.CurrencyID, &entry.CreatedOn)
+		if err != nil {
+			return err
+		}
+		entry.MemberSubscriptionID = memberSubscriptionID.String()
+
+		i := index[memberSubscriptionID]
+		subscriptions[i].Ledger = append(subscriptions[i].Ledger, entry)
+		subscriptions[i].Balance = math.Round((subscriptions[i].Balance+entry.Amount)*100) / 100
+	}
+	return rows.Err()
+}
+
+// GetSubscriptionPlanTerms returns the price in the currency with the given ISO code, duration and
+// limits of a subscription plan. An empty currency returns the default price of the plan. It returns
+// sql.ErrNoRows when the plan does not exist, and consts.ErrPlanPriceNotFound when it has no price in
+// the currency.
+func (member *MemberRepo) GetSubscriptionPlanTerms(ctx context.Context, subscriptionID string, currency string) (entities.SubscriptionPlanTerms, error) {
+	terms := entities.SubscriptionPlanTerms{SubscriptionID: subscriptionID}
+	var (
+		amount, taxPercentage sql.NullFloat64
+		currencyID            sql.NullInt64
+	)
+	err := member.db.QueryRowContext(ctx, `
+		SELECT sp.plan_key, COALESCE(sp.name, ''), COALESCE(sp.amount, 0), COALESCE(sp.tax_percentage, 0), COALESCE(sp.currency_id, 0),
+			spp.amount, spp.tax_percentage, spp.currency_id, COALESCE(cc.code, ''),
+			COALESCE(sd.value, 0), COALESCE(sp.product_count, 0), COALESCE(sp.track_count, 0), COALESCE(sp.artist_count, 0),
+			sp.is_active
+		FROM subscription_plan AS sp
+		LEFT JOIN subscription_duration AS sd ON sd.id = sp.subscription_duration_id
+		LEFT JOIN subscription_plan_price AS spp ON spp.subscription_plan_id = sp.id
+			AND spp.currency_id = CASE WHEN $2::text = '' THEN sp.currency_id
+				ELSE (SELECT currency_id FROM currency_code WHERE UPPER(code) = UPPER($2::text)) END
+		LEFT JOIN currency_code AS cc ON cc.currency_id = COALESCE(spp.currency_id, sp.currency_id)
+		WHERE sp.id = $1
+	`, subscriptionID, currency).Scan(&terms.PlanID, &terms.Name, &terms.Amount, &terms.TaxPercentage, &terms.CurrencyID,
+		&amount, &taxPercentage, &currencyID, &terms.Currency, &terms.DurationWeeks,
+		&terms.MaximumProducts, &terms.MaximumTracks, &terms.MaximumArtists, &terms.IsActive)
+	if err != nil {
+		return terms, err
 	}
 
-	return totalCount, nil
+	// Plans without a price row, such as free plans, only have the default price
+	if !currencyID.Valid {
+		if currency != "" {
+			return terms, consts.ErrPlanPriceNotFound
+		}
+		return terms, nil
+	}
+	terms.Amount, terms.TaxPercentage, terms.CurrencyID = amount.Float64, taxPercentage.Float64, int(currencyID.Int64)
+	return terms, nil
 }
 
-// IsMemberExists checks if a member exists in the database.
-// Parameters:
-//	- memberID: The UUID of the member to check for existence.
-//	- ctx: The context for the database operation.
+// planColumns are the columns of a subscription plan version read by scanSubscriptionPlans, for
+// subscription_plan sp joined with subscription_duration sd.
+const planColumns = `sp.id, sp.plan_key, sp.version, COALESCE(sd.name, ''), COALESCE(sp.name, ''), COALESCE(sp.sku, ''),
+	COALESCE(sp.subscription_duration_id, 0), COALESCE(sp.is_one_time_subscription, false), COALESCE(sp.is_free_subscription, false),
+	COALESCE(sp.is_cancellation_enabled, false), COALESCE(sp.can_renewable_within, 0), COALESCE(sp.subscription_limit_per_year, 0),
+	COALESCE(sp.product_count, 0), COALESCE(sp.track_count, 0), COALESCE(sp.artist_count, 0),
+	COALESCE(sp.max_tracks_per_product, 0), COALESCE(sp.max_artists_per_product, 0), sp.is_active, sp.retired_on, sp.created_on`
+
+// ListSubscriptionPlans lists the latest version of every plan of the catalogue, retired plans only
+// when retired is set.
+func (member *MemberRepo) ListSubscriptionPlans(ctx context.Context, retired bool) ([]entities.SubscriptionPlan, error) {
+	return member.getSubscriptionPlans(ctx, `
+		WHERE sp.version = (SELECT MAX(version) FROM subscription_plan WHERE plan_key = sp.plan_key)
+		AND (sp.retired_on IS NULL) <> $1
+		ORDER BY sp.name, sp.plan_key
+	`, retired)
+}
 
-// Returns:
-// - bool: A boolean indicating whether the member exists.
-// - error: An error, if any, during the database operation.
+// GetSubscriptionPlanVersions lists the versions of a plan, the latest first. The list is empty
+// when the plan does not exist.
+func (member *MemberRepo) GetSubscriptionPlanVersions(ctx context.Context, planID uuid.UUID) ([]entities.SubscriptionPlan, error) {
+	return member.getSubscriptionPlans(ctx, `
+		WHERE sp.plan_key = $1
+		ORDER BY sp.version DESC
+	`, planID)
+}
 
-func (member *MemberRepo) IsMemberExist(ctx context.Context, memberID uuid.UUID) (bool, error) {
+// getSubscriptionPlans reads the plan versions matching the condition along with their prices.
+func (member *MemberRepo) getSubscriptionPlans(ctx context.Context, condition string, args ...any) ([]entities.SubscriptionPlan, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT `+planColumns+`
+		FROM subscription_plan AS sp
+		LEFT JOIN subscription_duration AS sd ON sd.id = sp.subscription_duration_id
+	`+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	var exists int
-	isMemberExistsQ := `SELECT 1 FROM member WHERE id = $1`
-	row := member.db.QueryRowContext(ctx, isMemberExistsQ, memberID)
-	err := row.Scan(&exists)
+	plans := []entities.SubscriptionPlan{}
+	positions := map[uuid.UUID]int{}
+	ids := []string{}
+	for rows.Next() {
+		var plan entities.SubscriptionPlan
+		err = rows.Scan(&plan.ID, &plan.PlanID, &plan.Version, &plan.Duration, &plan.Name, &plan.SKU,
+			&plan.SubscriptionDurationID, &plan.IsOneTimeSubscription, &plan.IsFreeSubscription,
+			&plan.IsCancellationEnabled, &plan.CanRenewableWithin, &plan.SubscriptionLimitPerYear,
+			&plan.ProductCount, &plan.TrackCount, &plan.ArtistCount,
+			&plan.MaxTracksPerProduct, &plan.MaxArtistsPerProduct, &plan.IsActive, &plan.RetiredOn, &plan.CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		plan.Prices = []entities.PlanPrice{}
+		positions[plan.ID] = len(plans)
+		ids = append(ids, plan.ID.String())
+		plans = append(plans, plan)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return plans, nil
+	}
 
+	// The default price of a plan comes first
+	priceRows, err := member.db.QueryContext(ctx, `
+		SELECT spp.subscription_plan_id, spp.currency_id, spp.amount, spp.tax_percentage
+		FROM subscription_plan_price AS spp
+		INNER JOIN subscription_plan AS sp ON sp.id = spp.subscription_plan_id
+		WHERE spp.subscription_plan_id = ANY($1::uuid[])
+		ORDER BY spp.subscription_plan_id, spp.currency_id IS DISTINCT FROM sp.currency_id, spp.currency_id
+	`, pq.Array(ids))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return false, nil
+		return nil, err
+	}
+	defer priceRows.Close()
+
+	for priceRows.Next() {
+		var (
+			id    uuid.UUID
+			price entities.PlanPrice
+		)
+		if err = priceRows.Scan(&id, &price.CurrencyID, &price.Amount, &price.TaxPercentage); err != nil {
+			return nil, err
 		}
-		return false, err
+		plan := &plans[positions[id]]
+		plan.Prices = append(plan.Prices, price)
 	}
-	return true, nil
+	return plans, priceRows.Err()
 }
-func (member *MemberRepo) UpdateRandomBillingAddressToPrimary(ctx *gin.Context, memberID, memberBillingID uuid.UUID) error {
-	// Begin a transaction
+
+// SubscriptionDurationExists reports whether the subscription duration exists.
+func (member *MemberRepo) SubscriptionDurationExists(ctx context.Context, durationID int) (bool, error) {
+	var exists bool
+	err := member.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM subscription_duration WHERE id = $1)`, durationID).Scan(&exists)
+	return exists, err
+}
+
+// CreateSubscriptionPlan adds a plan to the catalogue, as the first version of the plan.
+func (member *MemberRepo) CreateSubscriptionPlan(ctx context.Context, request entities.SubscriptionPlanRequest) (plan entities.SubscriptionPlan, err error) {
 	tx, err := member.db.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return plan, err
 	}
 	defer func() {
-		if p := recover(); p != nil {
-			// If there was a panic, rollback the transaction and re-panic
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			// If there was an error, rollback the transaction
+		if err != nil {
 			tx.Rollback()
-		} else {
-			// Commit the transaction if everything went well
-			err = tx.Commit()
+			return
 		}
+		err = tx.Commit()
 	}()
 
-	// Fetch all billing addresses for the given member except memberBillingID
-	rows, err := tx.QueryContext(ctx, `SELECT id FROM member_billing_address WHERE member_id = $1 AND id != $2`, memberID, memberBillingID)
+	planID := uuid.New()
+	plan.ID, err = insertPlanVersion(ctx, tx, planID, 1, request)
 	if err != nil {
-		return err
+		return plan, err
 	}
-	defer rows.Close()
-
-	var shuffledAddresses []uuid.UUID
+	plan.PlanID, plan.Version = planID, 1
+	return plan, nil
+}
 
-	for rows.Next() {
-		var addressID uuid.UUID
-		if err := rows.Scan(&addressID); err != nil {
-			return err
+// AddSubscriptionPlanVersion publishes a new version of a plan with the terms of the request. The
+// previous versions can no longer be bought, member subscriptions keep referencing them. It returns
+// sql.ErrNoRows when the plan does not exist and consts.ErrPlanRetired when it is retired.
+func (member *MemberRepo) AddSubscriptionPlanVersion(ctx context.Context, planID uuid.UUID, request entities.SubscriptionPlanRequest) (plan entities.SubscriptionPlan, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return plan, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
 		}
-		shuffledAddresses = append(shuffledAddresses, addressID)
+		err = tx.Commit()
+	}()
+
+	// Lock the latest version so concurrent versions of the plan are numbered one after the other
+	var (
+		version   int
+		retiredOn *time.Time
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT version, retired_on
+		FROM subscription_plan
+		WHERE plan_key = $1
+		ORDER BY version DESC
+		LIMIT 1
+		FOR UPDATE
+	`, planID).Scan(&version, &retiredOn)
+	if err != nil {
+		return plan, err
+	}
+	if retiredOn != nil {
+		return plan, consts.ErrPlanRetired
 	}
 
-	// Check if there are any addresses available
-	if len(shuffledAddresses) == 0 {
-		return errors.New("no other billing addresses found for the given member")
+	_, err = tx.ExecContext(ctx, `UPDATE subscription_plan SET is_active = false WHERE plan_key = $1 AND is_active = true`, planID)
+	if err != nil {
+		return plan, err
+	}
+	plan.ID, err = insertPlanVersion(ctx, tx, planID, version+1, request)
+	if err != nil {
+		return plan, err
 	}
+	plan.PlanID, plan.Version = planID, version+1
+	return plan, nil
+}
 
-	// Shuffle the addresses
-	rand.Shuffle(len(shuffledAddresses), func(i, j int) {
-		shuffledAddresses[i], shuffledAddresses[j] = shuffledAddresses[j], shuffledAddresses[i]
-	})
+// insertPlanVersion inserts an active version of a plan along with its prices. The first price is
+// the default price of the version.
+func insertPlanVersion(ctx context.Context, tx *sql.Tx, planID uuid.UUID, version int, request entities.SubscriptionPlanRequest) (uuid.UUID, error) {
+	var defaultPrice entities.PlanPrice
+	if len(request.Prices) != 0 {
+		defaultPrice = request.Prices[0]
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO subscription_plan (plan_key, version, name, sku, subscription_duration_id, is_one_time_subscription,
+			is_free_subscription, is_cancellation_enabled, can_renewable_within, subscription_limit_per_year,
+			product_count, track_count, artist_count, max_tracks_per_product, max_artists_per_product,
+			amount, tax_percentage, currency_id, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, true)
+		RETURNING id
+	`, planID, version, request.Name, request.SKU, request.SubscriptionDurationID, request.IsOneTimeSubscription,
+		request.IsFreeSubscription, request.IsCancellationEnabled, request.CanRenewableWithin, request.SubscriptionLimitPerYear,
+		request.ProductCount, request.TrackCount, request.ArtistCount, request.MaxTracksPerProduct, request.MaxArtistsPerProduct,
+		defaultPrice.Amount, defaultPrice.TaxPercentage, defaultPrice.CurrencyID).Scan(&id)
+	if err != nil {
+		return id, err
+	}
 
-	// Get the first address after shuffling (randomly selected)
-	randomAddressID := shuffledAddresses[0]
+	for _, price := range request.Prices {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO subscription_plan_price (subscription_plan_id, currency_id, amount, tax_percentage)
+			VALUES ($1, $2, $3, $4)
+		`, id, price.CurrencyID, price.Amount, price.TaxPercentage)
+		if err != nil {
+			return id, err
+		}
+	}
+	return id, nil
+}
 
-	// Update the primary status of the randomly selected billing address to true
-	_, err = tx.ExecContext(ctx, `UPDATE member_billing_address SET is_primary_billing = true WHERE id = $1`, randomAddressID)
+// RetireSubscriptionPlan withdraws a plan from the catalogue: none of its versions can be bought
+// any more, member subscriptions keep referencing them. It returns sql.ErrNoRows when the plan does
+// not exist or is already retired.
+func (member *MemberRepo) RetireSubscriptionPlan(ctx context.Context, planID uuid.UUID) error {
+	result, err := member.db.ExecContext(ctx, `
+		UPDATE subscription_plan
+		SET is_active = false, retired_on = NOW()
+		WHERE plan_key = $1 AND retired_on IS NULL
+	`, planID)
 	if err != nil {
 		return err
 	}
-
+	retired, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if retired == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
-// DeleteBillingAddress deletes a billing address entry based on memberID and memberBillingID.
-func (member *MemberRepo) DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID) error {
-	// Execute the DELETE query
-	query := "DELETE FROM public.member_billing_address WHERE member_id = $1 AND id = $2"
-	result, err := member.db.ExecContext(ctx, query, memberID, memberBillingID)
+// redeemPromoCode records the redemption of a promo code by a checkout. The code is locked while its
+// redemptions are counted so concurrent checkouts cannot redeem it past its limits. Redemptions of
+// checkouts whose payment failed do not count.
+func redeemPromoCode(ctx context.Context, tx *sql.Tx, memberID uuid.UUID, memberSubscriptionID string, discount entities.PromoDiscount) error {
+	var maxRedemptions, maxRedemptionsPerMember int
+	err := tx.QueryRowContext(ctx, `
+		SELECT max_redemptions, max_redemptions_per_member
+		FROM promo_code
+		WHERE id = $1
+		FOR UPDATE
+	`, discount.PromoCodeID).Scan(&maxRedemptions, &maxRedemptionsPerMember)
 	if err != nil {
 		return err
 	}
 
-	// Check the affected rows to determine if the entry was deleted
-	rowsAffected, err := result.RowsAffected()
+	var redemptions, memberRedemptions int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE r.member_id = $2)
+		FROM promo_code_redemption AS r
+		INNER JOIN member_subscription AS ms ON ms.id = r.member_subscription_id
+		INNER JOIN member_subscription_status AS mss ON mss.id = ms.member_subscription_status_id
+		WHERE r.promo_code_id = $1 AND mss.name <> $3
+	`, discount.PromoCodeID, memberID, consts.SubscriptionStatusPaymentFailed).Scan(&redemptions, &memberRedemptions)
 	if err != nil {
 		return err
 	}
-
-	if rowsAffected == 0 {
-		// The entry was not found
-		return fmt.Errorf("billing address not found for memberID: %s and billingID: %s", memberID, memberBillingID)
+	if (maxRedemptions > 0 && redemptions >= maxRedemptions) ||
+		(maxRedemptionsPerMember > 0 && memberRedemptions >= maxRedemptionsPerMember) {
+		return consts.ErrPromoCodeLimitReached
 	}
 
-	return nil
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO promo_code_redemption (promo_code_id, member_id, member_subscription_id, discount_amount)
+		VALUES ($1, $2, $3, $4)
+	`, discount.PromoCodeID, memberID, memberSubscriptionID, discount.Amount)
+	return err
 }
 
-// GetPaymentDetailsByPartnerAndGateway retrieves payment details based on partner ID and payment gateway ID.
-// It returns the payment details and any error encountered during the retrieval.
-
-func (member *MemberRepo) GetPaymentDetailsByPartnerAndGateway(ctx context.Context, partnerID string, paymentGatewayID int) (string, error) {
-	var paymentDetails sql.NullString
-
-	// Query to fetch payment details based on partner ID and payment gateway ID.
-	err := member.db.QueryRowContext(ctx, `
-        SELECT payment_details
-        FROM public.partner_payment_gateway
-        WHERE partner_id = $1
-          AND payment_gateway_id = $2
-    `, partnerID, paymentGatewayID).Scan(&paymentDetails)
-
+// promoCodeColumns are the columns of a promo code read by scanPromoCodes, for promo_code pc with
+// the redemptions r of checkouts whose payment did not fail.
+const promoCodeColumns = `pc.id, pc.code, pc.discount_type, pc.discount_value, COALESCE(pc.currency_id, 0),
+	pc.partner_ids::text[], pc.plan_ids::text[], pc.valid_from, pc.valid_until, pc.max_redemptions,
+	pc.max_redemptions_per_member, pc.is_active, pc.created_on, COUNT(r.id), COALESCE(SUM(r.discount_amount), 0)`
+
+// promoCodeRedemptionRows selects the redemptions r of checkouts whose payment did not fail, with
+// the status mss of the redeeming subscription.
+const promoCodeRedemptionRows = `
+		FROM promo_code_redemption AS r
+		INNER JOIN member_subscription AS ms ON ms.id = r.member_subscription_id
+		INNER JOIN member_subscription_status AS mss ON mss.id = ms.member_subscription_status_id
+		WHERE mss.name <> '` + consts.SubscriptionStatusPaymentFailed + `'`
+
+// promoCodeRedemptions joins the redemptions of checkouts whose payment did not fail to promo_code pc.
+const promoCodeRedemptions = `
+		LEFT JOIN (
+			SELECT r.id, r.promo_code_id, r.discount_amount` + promoCodeRedemptionRows + `
+		) AS r ON r.promo_code_id = pc.id`
+
+// GetPromoCode returns a promo code of the partner or of the platform, looked up case-insensitively,
+// with its redemptions so far. The code of the partner wins over a platform code, an empty partner
+// only looks up platform codes. It returns sql.ErrNoRows when the code does not exist.
+func (member *MemberRepo) GetPromoCode(ctx context.Context, partnerID string, code string) (entities.PromoCode, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT `+promoCodeColumns+`
+		FROM promo_code AS pc`+promoCodeRedemptions+`
+		WHERE UPPER(pc.code) = UPPER($1)
+		AND (pc.owner_partner_id IS NULL OR pc.owner_partner_id = NULLIF($2, '')::uuid)
+		GROUP BY pc.id
+		ORDER BY pc.owner_partner_id NULLS LAST
+		LIMIT 1
+	`, code, partnerID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// Handle the case where no rows were returned (partner/payment gateway not found)
-			return "", fmt.Errorf("no payment details found for partnerID %s and paymentGatewayID %d", partnerID, paymentGatewayID)
-		}
-		return "", err
+		return entities.PromoCode{}, err
 	}
-
-	// Check if paymentDetails is NULL
-	if !paymentDetails.Valid {
-		return "", fmt.Errorf("payment details are NULL for partnerID %s and paymentGatewayID %d", partnerID, paymentGatewayID)
+	promoCodes, err := scanPromoCodes(rows)
+	if err != nil {
+		return entities.PromoCode{}, err
 	}
+	if len(promoCodes) == 0 {
+		return entities.PromoCode{}, sql.ErrNoRows
+	}
+	return promoCodes[0], nil
+}
 
-	return paymentDetails.String, nil
+// ListPromoCodes lists the promo codes with their redemptions so far and the total discount they
+// were given, the latest first. Requests scoped to a partner list the codes of the partner.
+func (member *MemberRepo) ListPromoCodes(ctx context.Context) ([]entities.PromoCode, error) {
+	var scope string
+	var params []any
+	if partnerID, ok := tenant.PartnerFrom(ctx); ok {
+		scope, params = `
+		WHERE $1 = ANY(pc.partner_ids)`, []any{partnerID}
+	}
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT `+promoCodeColumns+`
+		FROM promo_code AS pc`+promoCodeRedemptions+scope+`
+		GROUP BY pc.id
+		ORDER BY pc.created_on DESC, pc.code
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	return scanPromoCodes(rows)
 }
 
-// IsPartnerIdCorrespondsToGateway checks if the payment gateway passed corresponds to given partner.
-func (member *MemberRepo) IsPartnerIdCorrespondsToGateway(ctx context.Context, partnerID string, paymentGatewayID int) (bool, error) {
-	var exists bool
+// scanPromoCodes reads the promo codes selected with promoCodeColumns and closes rows.
+func scanPromoCodes(rows *sql.Rows) ([]entities.PromoCode, error) {
+	defer rows.Close()
 
-	// Query to check if the partner ID corresponds to the given payment gateway ID in the partner_payin_gateway table.
-	err := member.db.QueryRowContext(ctx, `
+	promoCodes := []entities.PromoCode{}
+	for rows.Next() {
+		var promoCode entities.PromoCode
+		err := rows.Scan(&promoCode.ID, &promoCode.Code, &promoCode.DiscountType, &promoCode.DiscountValue, &promoCode.CurrencyID,
+			pq.Array(&promoCode.PartnerIDs), pq.Array(&promoCode.PlanIDs), &promoCode.ValidFrom, &promoCode.ValidUntil,
+			&promoCode.MaxRedemptions, &promoCode.MaxRedemptionsPerMember, &promoCode.IsActive, &promoCode.CreatedOn,
+			&promoCode.Redemptions, &promoCode.DiscountTotal)
+		if err != nil {
+			return nil, err
+		}
+		promoCodes = append(promoCodes, promoCode)
+	}
+	return promoCodes, rows.Err()
+}
+
+// CreatePromoCode adds an active promo code. Codes added in requests scoped to a partner are owned
+// by the partner, others are platform codes.
+func (member *MemberRepo) CreatePromoCode(ctx context.Context, request entities.PromoCodeRequest) (entities.PromoCode, error) {
+	promoCode := entities.PromoCode{PromoCodeRequest: request, IsActive: true}
+	var currencyID *int
+	if request.CurrencyID != 0 {
+		currencyID = &request.CurrencyID
+	}
+	var ownerPartnerID *uuid.UUID
+	if partnerID, ok := tenant.PartnerFrom(ctx); ok {
+		ownerPartnerID = &partnerID
+	}
+	err := member.db.QueryRowContext(ctx, `
+		INSERT INTO promo_code (code, discount_type, discount_value, currency_id, partner_ids, plan_ids,
+			valid_from, valid_until, max_redemptions, max_redemptions_per_member, owner_partner_id)
+		VALUES ($1, $2, $3, $4, $5::uuid[], $6::uuid[], $7, $8, $9, $10, $11)
+		RETURNING id, created_on
+	`, request.Code, request.DiscountType, request.DiscountValue, currencyID, pq.Array(request.PartnerIDs), pq.Array(request.PlanIDs),
+		request.ValidFrom, request.ValidUntil, request.MaxRedemptions, request.MaxRedemptionsPerMember, ownerPartnerID).
+		Scan(&promoCode.ID, &promoCode.CreatedOn)
+	if err != nil {
+		return entities.PromoCode{}, err
+	}
+	return promoCode, nil
+}
+
+// GetPromoCodeRedemptionCount returns the number of redemptions of a promo code listed by
+// GetPromoCodeRedemptions.
+func (member *MemberRepo) GetPromoCodeRedemptionCount(ctx context.Context, promoCodeID uuid.UUID) (int64, error) {
+	var count int64
+	scope, params := tenant.MemberCondition(ctx, "r.member_id", []any{promoCodeID})
+	err := member.db.QueryRowContext(ctx, `SELECT COUNT(*)`+promoCodeRedemptionRows+`
+		AND r.promo_code_id = $1`+scope, params...).Scan(&count)
+	return count, err
+}
+
+// GetPromoCodeRedemptions lists the redemptions of a promo code with the status of the redeeming
+// subscription, the latest first. Redemptions of checkouts whose payment failed are left out, as in
+// the redemptions of the code. Requests scoped to a partner list the redemptions of its members.
+func (member *MemberRepo) GetPromoCodeRedemptions(ctx context.Context, promoCodeID uuid.UUID, page int32, limit int32) ([]entities.PromoRedemption, error) {
+	scope, params := tenant.MemberCondition(ctx, "r.member_id", []any{promoCodeID, limit, (page - 1) * limit})
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT r.id, r.promo_code_id, r.member_id, r.member_subscription_id, mss.name, r.discount_amount, r.redeemed_on`+
+		promoCodeRedemptionRows+`
+		AND r.promo_code_id = $1`+scope+`
+		ORDER BY r.redeemed_on DESC, r.id
+		LIMIT $2 OFFSET $3`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redemptions := []entities.PromoRedemption{}
+	for rows.Next() {
+		var redemption entities.PromoRedemption
+		err := rows.Scan(&redemption.ID, &redemption.PromoCodeID, &redemption.MemberID, &redemption.MemberSubscriptionID,
+			&redemption.SubscriptionStatus, &redemption.DiscountAmount, &redemption.RedeemedOn)
+		if err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, redemption)
+	}
+	return redemptions, rows.Err()
+}
+
+// GetMemberSubscriptionState returns the plan, status, expiration date and the products, tracks and
+// artists added to a member subscription of the member. It returns sql.ErrNoRows when the member has
+// no such subscription.
+func (member *MemberRepo) GetMemberSubscriptionState(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.MemberSubscriptionState, error) {
+	state := entities.MemberSubscriptionState{MemberSubscriptionID: memberSubscriptionID}
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberSubscriptionID, memberID})
+	err := member.db.QueryRowContext(ctx, `
+		SELECT ms.subscription_id, COALESCE(ms.custom_name, ''), mss.name, ms.expiration_date,
+			(SELECT COUNT(*) FROM product p WHERE p.member_subscription_id = ms.id),
+			(SELECT COUNT(*) FROM product_track pt INNER JOIN product p ON p.id = pt.product_id WHERE p.member_subscription_id = ms.id),
+			(SELECT COUNT(DISTINCT pa.artist_id) FROM product_artist pa INNER JOIN product p ON p.id = pa.product_id WHERE p.member_subscription_id = ms.id)
+		FROM member_subscription ms
+		INNER JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+		WHERE ms.id = $1
+		AND ms.member_id = $2`+scope+`
+	`, params...).Scan(&state.SubscriptionID, &state.CustomName, &state.Status, &state.ExpirationDate,
+		&state.ProductsAdded, &state.TracksAdded, &state.ArtistsAdded)
+	if err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// rowQuerier runs single row queries on the database or within a transaction.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// subscriptionQuota reads the limits, usage and pending reservations of a member subscription.
+// Within a transaction, lock holds the subscription until the transaction ends so reservations
+// of the subscription are made one after the other. It returns sql.ErrNoRows when the member
+// has no such subscription or belongs to another partner than the one of ctx.
+func subscriptionQuota(ctx context.Context, q rowQuerier, memberID uuid.UUID, memberSubscriptionID string, lock bool) (entities.SubscriptionQuota, error) {
+	subscriptionQuota := entities.SubscriptionQuota{MemberSubscriptionID: memberSubscriptionID}
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberSubscriptionID, memberID,
+		consts.QuotaProducts, consts.QuotaTracks, consts.QuotaArtists, consts.QuotaReserved})
+	query := `
+		SELECT mss.name,
+			COALESCE(sp.product_count, 0), COALESCE(sp.track_count, 0), COALESCE(sp.artist_count, 0),
+			(SELECT COUNT(*) FROM product p WHERE p.member_subscription_id = ms.id),
+			(SELECT COUNT(*) FROM product_track pt INNER JOIN product p ON p.id = pt.product_id WHERE p.member_subscription_id = ms.id),
+			(SELECT COUNT(DISTINCT pa.artist_id) FROM product_artist pa INNER JOIN product p ON p.id = pa.product_id WHERE p.member_subscription_id = ms.id),
+			COALESCE(r.products, 0), COALESCE(r.tracks, 0), COALESCE(r.artists, 0)
+		FROM member_subscription ms
+		INNER JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+		INNER JOIN subscription_plan sp ON sp.id = ms.subscription_id
+		LEFT JOIN LATERAL (
+			SELECT SUM(quantity) FILTER (WHERE resource = $3) AS products,
+				SUM(quantity) FILTER (WHERE resource = $4) AS tracks,
+				SUM(quantity) FILTER (WHERE resource = $5) AS artists
+			FROM member_quota_reservation
+			WHERE member_subscription_id = ms.id
+			AND status = $6
+			AND expires_on > NOW()
+		) r ON true
+		WHERE ms.id = $1
+		AND ms.member_id = $2` + scope
+	if lock {
+		query += `
+		FOR UPDATE OF ms`
+	}
+
+	var limits, used, reserved [3]int
+	err := q.QueryRowContext(ctx, query, params...).Scan(
+		&subscriptionQuota.Status,
+		&limits[0], &limits[1], &limits[2],
+		&used[0], &used[1], &used[2],
+		&reserved[0], &reserved[1], &reserved[2])
+	if err != nil {
+		return subscriptionQuota, err
+	}
+	byResource := func(counts [3]int) map[string]int {
+		return map[string]int{consts.QuotaProducts: counts[0], consts.QuotaTracks: counts[1], consts.QuotaArtists: counts[2]}
+	}
+	subscriptionQuota.Usage = quota.Usages(byResource(limits), byResource(used), byResource(reserved))
+	return subscriptionQuota, nil
+}
+
+// GetSubscriptionQuota returns the limits, usage and pending reservations of a member subscription.
+// It returns sql.ErrNoRows when the member has no such subscription.
+func (member *MemberRepo) GetSubscriptionQuota(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.SubscriptionQuota, error) {
+	return subscriptionQuota(ctx, member.db, memberID, memberSubscriptionID, false)
+}
+
+// ReserveQuota reserves capacity of a member subscription for ttl. The subscription is locked while
+// its capacity is checked, so concurrent reservations cannot exceed the limits of the plan. It
+// returns consts.ErrSubscriptionClosed when items cannot be added to the subscription,
+// consts.ErrQuotaExceeded when the capacity left is too small and sql.ErrNoRows when the member
+// has no such subscription.
+func (member *MemberRepo) ReserveQuota(ctx context.Context, reservation entities.QuotaReservation, ttl time.Duration) (_ entities.QuotaReservation, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return reservation, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	current, err := subscriptionQuota(ctx, tx, reservation.MemberID, reservation.MemberSubscriptionID, true)
+	if err != nil {
+		return reservation, err
+	}
+	if !quota.Open(current.Status) {
+		err = consts.ErrSubscriptionClosed
+		return reservation, err
+	}
+	if usage, _ := quota.Find(current.Usage, reservation.Resource); !quota.Allows(usage, reservation.Quantity) {
+		err = consts.ErrQuotaExceeded
+		return reservation, err
+	}
+
+	reservation.Status = consts.QuotaReserved
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO member_quota_reservation (member_subscription_id, member_id, resource, quantity, status, expires_on)
+		VALUES ($1, $2, $3, $4, $5, NOW() + $6 * INTERVAL '1 second')
+		RETURNING id, expires_on, created_on
+	`, reservation.MemberSubscriptionID, reservation.MemberID, reservation.Resource, reservation.Quantity, reservation.Status,
+		int64(ttl/time.Second)).Scan(&reservation.ID, &reservation.ExpiresOn, &reservation.CreatedOn)
+	return reservation, err
+}
+
+// FinishQuotaReservation moves a pending reservation of a member subscription to the committed
+// or released status, its capacity is no longer held. It returns sql.ErrNoRows when the
+// subscription has no such pending reservation, expired reservations included.
+func (member *MemberRepo) FinishQuotaReservation(ctx context.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID uuid.UUID, status string) error {
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{reservationID, memberID, memberSubscriptionID, status, consts.QuotaReserved})
+	result, err := member.db.ExecContext(ctx, `
+		UPDATE member_quota_reservation
+		SET status = $4, updated_on = NOW()
+		WHERE id = $1
+		AND member_id = $2
+		AND member_subscription_id = $3
+		AND status = $5
+		AND expires_on > NOW()`+scope, params...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ExpireQuotaReservations moves the pending reservations past their expiry to the expired status
+// and returns their number. Expired reservations no longer hold capacity either way, this keeps
+// the reservation statuses accurate.
+func (member *MemberRepo) ExpireQuotaReservations(ctx context.Context) (int64, error) {
+	result, err := member.db.ExecContext(ctx, `
+		UPDATE member_quota_reservation
+		SET status = $1, updated_on = NOW()
+		WHERE status = $2
+		AND expires_on <= NOW()
+	`, consts.QuotaExpired, consts.QuotaReserved)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ChangeSubscriptionPlan switches the member subscription of the ledger entry from its FromSubscriptionID
+// plan to its ToSubscriptionID plan, records the entry and writes the plan change to the outbox, in one
+// transaction. The expiration date is kept. It returns sql.ErrNoRows when the member subscription is no
+// longer on the FromSubscriptionID plan.
+func (member *MemberRepo) ChangeSubscriptionPlan(ctx context.Context, entry entities.SubscriptionLedgerEntry) (_ entities.SubscriptionLedgerEntry, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entry, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return member.changeSubscriptionPlan(ctx, tx, entry)
+}
+
+// changeSubscriptionPlan switches the plan of a member subscription within a transaction, records the ledger
+// entry of the change and writes the member_subscription.plan_changed event.
+func (member *MemberRepo) changeSubscriptionPlan(ctx context.Context, tx *sql.Tx, entry entities.SubscriptionLedgerEntry) (entities.SubscriptionLedgerEntry, error) {
+	var status string
+	err := tx.QueryRowContext(ctx, `
+		UPDATE member_subscription ms
+		SET subscription_id = $3
+		FROM member_subscription_status mss
+		WHERE ms.id = $1
+		AND ms.subscription_id = $2
+		AND mss.id = ms.member_subscription_status_id
+		RETURNING mss.name
+	`, entry.MemberSubscriptionID, entry.FromSubscriptionID, entry.ToSubscriptionID).Scan(&status)
+	if err != nil {
+		return entry, err
+	}
+
+	entry, err = member.addLedgerEntry(ctx, tx, entry)
+	if err != nil {
+		return entry, err
+	}
+	return entry, member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionPlanChanged, consts.AggregateMemberSubscription,
+		entry.MemberSubscriptionID, entities.MemberSubscriptionEventData{
+			MemberSubscriptionID:   entry.MemberSubscriptionID,
+			MemberID:               entry.MemberID,
+			SubscriptionID:         entry.ToSubscriptionID,
+			PreviousSubscriptionID: entry.FromSubscriptionID,
+			Status:                 status,
+		})
+}
+
+// RecordPlanChangePayment records the payment charging the ledger entry of an upgrade and, unless the payment
+// failed, changes the plan along with the entry and the payment settling it, in one transaction. A pending
+// payment changes the plan, the payment webhook reports when it fails. It returns sql.ErrNoRows when the member
+// subscription is no longer on the FromSubscriptionID plan.
+func (member *MemberRepo) RecordPlanChangePayment(ctx context.Context, payment entities.SubscriptionPayment,
+	entry entities.SubscriptionLedgerEntry) (paymentID uuid.UUID, _ entities.SubscriptionLedgerEntry, err error) {
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return paymentID, entry, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx, insertSubscriptionPaymentQuery, payment.MemberSubscriptionID, payment.MemberID,
+		payment.PaymentGatewayID, payment.GatewayPaymentID, payment.Kind, payment.Amount, payment.Currency, payment.Status,
+		payment.FailureReason).Scan(&paymentID)
+	if err != nil || (payment.Status != consts.PaymentStatusCaptured && payment.Status != consts.PaymentStatusPending) {
+		return paymentID, entry, err
+	}
+
+	entry, err = member.changeSubscriptionPlan(ctx, tx, entry)
+	if err != nil {
+		return paymentID, entry, err
+	}
+	_, err = member.addLedgerEntry(ctx, tx, entities.SubscriptionLedgerEntry{
+		MemberSubscriptionID: entry.MemberSubscriptionID,
+		MemberID:             entry.MemberID,
+		Kind:                 consts.LedgerKindPayment,
+		FromSubscriptionID:   entry.ToSubscriptionID,
+		Credit:               entry.Amount,
+		Amount:               -entry.Amount,
+		CurrencyID:           entry.CurrencyID,
+	})
+	return paymentID, entry, err
+}
+
+// GetSubscriptionBalance returns the sum of the ledger amounts of a member subscription, negative when the
+// member has credit left.
+func (member *MemberRepo) GetSubscriptionBalance(ctx context.Context, memberSubscriptionID string) (float64, error) {
+	var balance float64
+	err := member.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM member_subscription_ledger
+		WHERE member_subscription_id = $1
+	`, memberSubscriptionID).Scan(&balance)
+	return balance, err
+}
+
+// addLedgerEntry records a ledger entry within a transaction and returns it with its ID and creation time.
+// Entries without a ToSubscriptionID, such as refunds, leave it NULL.
+func (member *MemberRepo) addLedgerEntry(ctx context.Context, tx *sql.Tx, entry entities.SubscriptionLedgerEntry) (entities.SubscriptionLedgerEntry, error) {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO member_subscription_ledger
+		(member_subscription_id, member_id, kind, from_subscription_id, to_subscription_id, credit, charge, amount, currency_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6, $7, $8, $9)
+		RETURNING id, created_on
+	`, entry.MemberSubscriptionID, entry.MemberID, entry.Kind, entry.FromSubscriptionID, entry.ToSubscriptionID,
+		entry.Credit, entry.Charge, entry.Amount, entry.CurrencyID).Scan(&entry.ID, &entry.CreatedOn)
+	return entry, err
+}
+
+// GetSubscriptionRecordCount function is used to calculate and return total count of subscriptions
+func (member *MemberRepo) GetSubscriptionRecordCount(ctx context.Context, memberID uuid.UUID) (int64, error) {
+
+	var totalCount int64
+
+	query := `
+		SELECT COUNT(subscription_id) AS total_count
+		FROM member_subscription 
+		WHERE member_subscription.member_id = $1
+	`
+	scope, params := tenant.MemberCondition(ctx, "member_subscription.member_id", []any{memberID})
+	row := member.db.QueryRowContext(ctx, query+scope, params...)
+
+	if err := row.Scan(&totalCount); err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetSubscriptionRecordCount failed, QueryRowContext failed, err=%s", err.Error())
+		return 0, err
+	}
+
+	return totalCount, nil
+}
+
+// IsMemberExists checks if a member exists in the database.
+// Parameters:
+//	- memberID: The UUID of the member to check for existence.
+//	- ctx: The context for the database operation.
+
+// Returns:
+// - bool: A boolean indicating whether the member exists.
+// - error: An error, if any, during the database operation.
+
+func (member *MemberRepo) IsMemberExist(ctx context.Context, memberID uuid.UUID) (bool, error) {
+
+	var exists int
+	isMemberExistsQ := `SELECT 1 FROM member WHERE id = $1`
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
+	row := member.db.QueryRowContext(ctx, isMemberExistsQ+scope, params...)
+	err := row.Scan(&exists)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+func (member *MemberRepo) UpdateRandomBillingAddressToPrimary(ctx *gin.Context, memberID, memberBillingID uuid.UUID) error {
+	// Begin a transaction
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			// If there was a panic, rollback the transaction and re-panic
+			tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			// If there was an error, rollback the transaction
+			tx.Rollback()
+		} else {
+			// Commit the transaction if everything went well
+			err = tx.Commit()
+		}
+	}()
+
+	// Fetch all billing addresses for the given member except memberBillingID
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, memberBillingID})
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM member_billing_address WHERE member_id = $1 AND id != $2`+scope, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var shuffledAddresses []uuid.UUID
+
+	for rows.Next() {
+		var addressID uuid.UUID
+		if err := rows.Scan(&addressID); err != nil {
+			return err
+		}
+		shuffledAddresses = append(shuffledAddresses, addressID)
+	}
+
+	// Check if there are any addresses available
+	if len(shuffledAddresses) == 0 {
+		return errors.New("no other billing addresses found for the given member")
+	}
+
+	// Shuffle the addresses
+	rand.Shuffle(len(shuffledAddresses), func(i, j int) {
+		shuffledAddresses[i], shuffledAddresses[j] = shuffledAddresses[j], shuffledAddresses[i]
+	})
+
+	// Get the first address after shuffling (randomly selected)
+	randomAddressID := shuffledAddresses[0]
+
+	// Update the primary status of the randomly selected billing address to true
+	_, err = tx.ExecContext(ctx, `UPDATE member_billing_address SET is_primary_billing = true WHERE id = $1`, randomAddressID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBillingAddress deletes a billing address entry based on memberID and memberBillingID. A
+// version other than 0 has to match the version of the billing address.
+func (member *MemberRepo) DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, version int64) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	// Execute the DELETE query
+	query := "DELETE FROM public.member_billing_address WHERE member_id = $1 AND id = $2"
+	params := []any{memberID, memberBillingID}
+	if version > 0 {
+		query += " AND version = $3"
+		params = append(params, version)
+	}
+	scope, params := tenant.MemberCondition(ctx, "member_id", params)
+	query += scope + " RETURNING address, zip, country_code, state_code, is_primary_billing"
+	var address, zip, country, state sql.NullString
+	var primary bool
+	err = tx.QueryRowContext(ctx, query, params...).Scan(&address, &zip, &country, &state, &primary)
+
+	// No deleted row means the entry was not found
+	if errors.Is(err, sql.ErrNoRows) && version > 0 {
+		// The entry was updated since the version the deletion was made against
+		err = consts.ErrVersionMismatch
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		err = fmt.Errorf("billing address not found for memberID: %s and billingID: %s", memberID, memberBillingID)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventBillingAddressDeleted, consts.AggregateMember, memberID.String(),
+		entities.BillingAddressEventData{
+			MemberID:         memberID,
+			BillingAddressID: memberBillingID,
+		})
+	if err != nil {
+		return err
+	}
+
+	return member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID:   memberID,
+		ResourceID: memberBillingID.String(),
+		Action:     consts.AuditBillingAddressDeleted,
+		Changes: audit.Changes{}.
+			Set("address", address.String, nil).
+			Set("zip", zip.String, nil).
+			Set("country_code", country.String, nil).
+			Set("state_code", state.String, nil).
+			Set("is_primary_billing", primary, nil),
+	})
+}
+
+// GetPaymentDetailsByPartnerAndGateway retrieves payment details based on partner ID and payment gateway ID.
+// It returns the payment details and any error encountered during the retrieval.
+
+func (member *MemberRepo) GetPaymentDetailsByPartnerAndGateway(ctx context.Context, partnerID string, paymentGatewayID int) (string, error) {
+	var paymentDetails sql.NullString
+
+	// Query to fetch payment details based on partner ID and payment gateway ID.
+	err := member.db.QueryRowContext(ctx, `
+        SELECT payment_details
+        FROM public.partner_payment_gateway
+        WHERE partner_id = $1
+          AND payment_gateway_id = $2
+    `, partnerID, paymentGatewayID).Scan(&paymentDetails)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Handle the case where no rows were returned (partner/payment gateway not found)
+			return "", fmt.Errorf("no payment details found for partnerID %s and paymentGatewayID %d", partnerID, paymentGatewayID)
+		}
+		return "", err
+	}
+
+	// Check if paymentDetails is NULL
+	if !paymentDetails.Valid {
+		return "", fmt.Errorf("payment details are NULL for partnerID %s and paymentGatewayID %d", partnerID, paymentGatewayID)
+	}
+
+	return paymentDetails.String, nil
+}
+
+// GetPaymentDetailsByPartner returns the encrypted payment details of each payment gateway of the
+// partner, keyed by payment gateway ID. Gateways without payment details are left out.
+func (member *MemberRepo) GetPaymentDetailsByPartner(ctx context.Context, partnerID string) (map[int]string, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT payment_gateway_id, payment_details
+		FROM public.partner_payment_gateway
+		WHERE partner_id = $1
+		  AND payment_details IS NOT NULL
+	`, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	paymentDetails := map[int]string{}
+	for rows.Next() {
+		var (
+			paymentGatewayID int
+			details          string
+		)
+		if err := rows.Scan(&paymentGatewayID, &details); err != nil {
+			return nil, err
+		}
+		paymentDetails[paymentGatewayID] = details
+	}
+	return paymentDetails, rows.Err()
+}
+
+// IsPartnerIdCorrespondsToGateway checks if the payment gateway passed corresponds to given partner.
+func (member *MemberRepo) IsPartnerIdCorrespondsToGateway(ctx context.Context, partnerID string, paymentGatewayID int) (bool, error) {
+	var exists bool
+
+	// Query to check if the partner ID corresponds to the given payment gateway ID in the partner_payin_gateway table.
+	err := member.db.QueryRowContext(ctx, `
 		SELECT EXISTS (
 			SELECT 1
 			FROM public.partner_payment_gateway
@@ -2784,34 +5206,603 @@ func (member *MemberRepo) IsPartnerIdCorrespondsToGateway(ctx context.Context, p
 		return false, err
 	}
 
-	return exists, nil
-}
+	return exists, nil
+}
+
+// DecryptPaymentData decrypts the given encrypted data using a decryption key
+// specified in the MemberRepo configuration.
+//
+// The function returns the decrypted string and any error encountered during the decryption process.
+func (member *MemberRepo) DecryptPaymentData(ctx context.Context, data string) (string, error) {
+	if member.Cfg == nil {
+		return "", errors.New("configuration is nil")
+	}
+
+	decryptionKey := member.Cfg.DecryptionKey
+	if decryptionKey == "" {
+		return "", errors.New("decryption key is empty")
+	}
+
+	key := []byte(decryptionKey)
+	if len(key) == 0 {
+		return "", errors.New("decryption key is empty after conversion to byte slice")
+	}
+
+	decryptedString, err := crypto.Decrypt(data, key)
+	if err != nil {
+		return "", fmt.Errorf("decryption failed: %v", err)
+	}
+
+	return decryptedString, nil
+}
+
+// RecordSubscriptionPayment records a payment gateway operation made for a member subscription and returns its ID.
+func (member *MemberRepo) RecordSubscriptionPayment(ctx context.Context, payment entities.SubscriptionPayment) (uuid.UUID, error) {
+	var paymentID uuid.UUID
+	err := member.db.QueryRowContext(ctx, insertSubscriptionPaymentQuery, payment.MemberSubscriptionID, payment.MemberID,
+		payment.PaymentGatewayID, payment.GatewayPaymentID, payment.Kind, payment.Amount, payment.Currency, payment.Status,
+		payment.FailureReason).Scan(&paymentID)
+	return paymentID, err
+}
+
+// insertSubscriptionPaymentQuery records a subscription payment and returns its ID.
+const insertSubscriptionPaymentQuery = `
+		INSERT INTO member_subscription_payment
+		(member_subscription_id, member_id, payment_gateway_id, gateway_payment_id, kind, amount, currency, status, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+// RecordRenewalPayment records a renewal payment and, when it was captured, renews the member subscription
+// in the same transaction, so a captured renewal is never left without its renewal and charged again. The
+// ledger credit used up by the renewal, if any, is recorded with captured and pending payments.
+func (member *MemberRepo) RecordRenewalPayment(ctx context.Context, payment entities.SubscriptionPayment,
+	credit *entities.SubscriptionLedgerEntry) (paymentID uuid.UUID, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return paymentID, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx, insertSubscriptionPaymentQuery, payment.MemberSubscriptionID, payment.MemberID,
+		payment.PaymentGatewayID, payment.GatewayPaymentID, payment.Kind, payment.Amount, payment.Currency, payment.Status,
+		payment.FailureReason).Scan(&paymentID)
+	if err != nil {
+		return paymentID, err
+	}
+	if credit != nil && (payment.Status == consts.PaymentStatusCaptured || payment.Status == consts.PaymentStatusPending) {
+		if _, err = member.addLedgerEntry(ctx, tx, *credit); err != nil {
+			return paymentID, err
+		}
+	}
+	if payment.Status == consts.PaymentStatusCaptured {
+		err = member.renewSubscription(ctx, tx, payment.MemberID, payment.MemberSubscriptionID)
+	}
+	return paymentID, err
+}
+
+// RecordCheckoutPayment records a checkout payment and moves the member subscription in the same transaction:
+// a captured payment activates it and a failed one moves it to payment_failed. Pending payments leave it to the
+// payment webhook. A captured checkout is never left recorded on an inactive subscription.
+func (member *MemberRepo) RecordCheckoutPayment(ctx context.Context, payment entities.SubscriptionPayment) (paymentID uuid.UUID, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return paymentID, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	err = tx.QueryRowContext(ctx, insertSubscriptionPaymentQuery, payment.MemberSubscriptionID, payment.MemberID,
+		payment.PaymentGatewayID, payment.GatewayPaymentID, payment.Kind, payment.Amount, payment.Currency, payment.Status,
+		payment.FailureReason).Scan(&paymentID)
+	if err != nil {
+		return paymentID, err
+	}
+	switch payment.Status {
+	case consts.PaymentStatusCaptured:
+		err = member.setSubscriptionStatus(ctx, tx, payment.MemberSubscriptionID, consts.SubscriptionStatusActive)
+	case consts.PaymentStatusPending:
+	default:
+		err = member.setSubscriptionStatus(ctx, tx, payment.MemberSubscriptionID, consts.SubscriptionStatusPaymentFailed)
+	}
+	return paymentID, err
+}
+
+// RecordSubscriptionRefund records a refund made on a captured payment and, when the gateway refunded it,
+// marks the payment refunded and records the refunded credit in the ledger, in one transaction.
+func (member *MemberRepo) RecordSubscriptionRefund(ctx context.Context, refund entities.SubscriptionPayment, refundedPaymentID uuid.UUID,
+	entry entities.SubscriptionLedgerEntry) (err error) {
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var refundID uuid.UUID
+	err = tx.QueryRowContext(ctx, insertSubscriptionPaymentQuery, refund.MemberSubscriptionID, refund.MemberID,
+		refund.PaymentGatewayID, refund.GatewayPaymentID, refund.Kind, refund.Amount, refund.Currency, refund.Status,
+		refund.FailureReason).Scan(&refundID)
+	if err != nil || refund.Status != consts.PaymentStatusRefunded {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE member_subscription_payment SET status = $2 WHERE id = $1`, refundedPaymentID,
+		consts.PaymentStatusRefunded)
+	if err != nil {
+		return err
+	}
+	_, err = member.addLedgerEntry(ctx, tx, entry)
+	return err
+}
+
+// GetMemberBillingProfile returns the name, email, tax flag and primary billing address of a member.
+func (member *MemberRepo) GetMemberBillingProfile(ctx context.Context, memberID uuid.UUID) (entities.BillingProfile, error) {
+	var (
+		profile                      entities.BillingProfile
+		address, zip, country, state sql.NullString
+		hasAddress                   bool
+	)
+	query := `
+		SELECT TRIM(COALESCE(m.firstname, '') || ' ' || COALESCE(m.lastname, '')), m.email, COALESCE(m.is_paying_tax, false),
+			ba.id IS NOT NULL, ba.address, ba.zip, ba.country_code, ba.state_code
+		FROM member m
+		LEFT JOIN LATERAL (
+			SELECT id, address, zip, country_code, state_code
+			FROM member_billing_address
+			WHERE member_id = m.id
+			AND is_primary_billing = TRUE
+			LIMIT 1
+		) AS ba ON true
+		WHERE m.id = $1
+	`
+	scope, params := tenant.Condition(ctx, "m.partner_id", []any{memberID})
+	err := member.db.QueryRowContext(ctx, query+scope, params...).Scan(&profile.Name, &profile.Email, &profile.PayingTax, &hasAddress, &address, &zip, &country, &state)
+	if err != nil {
+		return profile, err
+	}
+	if hasAddress {
+		profile.BillingAddress = &entities.BillingAddress{
+			Address: address.String,
+			Zipcode: zip.String,
+			Country: country.String,
+			State:   state.String,
+			Primary: true,
+		}
+	}
+	return profile, nil
+}
+
+// CreateInvoice stores an invoice with its line items and returns it with its ID, number and issue date.
+func (member *MemberRepo) CreateInvoice(ctx context.Context, invoice entities.Invoice) (_ entities.Invoice, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return invoice, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var paymentID interface{}
+	if invoice.PaymentID != uuid.Nil {
+		paymentID = invoice.PaymentID
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO member_invoice
+		(member_id, member_subscription_id, member_subscription_payment_id, kind, currency, subtotal, tax_percentage,
+			tax_amount, total, billing_name, billing_email, billing_address, billing_zip, billing_country, billing_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id, invoice_number, issued_on
+	`, invoice.MemberID, invoice.MemberSubscriptionID, paymentID, invoice.Kind, invoice.Currency, invoice.Subtotal,
+		invoice.TaxPercentage, invoice.TaxAmount, invoice.Total, invoice.BillingName, invoice.BillingEmail,
+		invoice.BillingAddress.Address, invoice.BillingAddress.Zipcode, invoice.BillingAddress.Country,
+		invoice.BillingAddress.State).Scan(&invoice.ID, &invoice.Number, &invoice.IssuedOn)
+	if err != nil {
+		return invoice, err
+	}
+
+	for position, line := range invoice.Lines {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO member_invoice_line (invoice_id, position, description, quantity, unit_amount, amount)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, invoice.ID, position, line.Description, line.Quantity, line.UnitAmount, line.Amount)
+		if err != nil {
+			return invoice, err
+		}
+	}
+
+	return invoice, nil
+}
+
+// invoiceColumns are the member_invoice columns scanned by scanInvoice.
+const invoiceColumns = `i.id, i.invoice_number, i.member_id, i.member_subscription_id, i.kind, i.currency, i.subtotal,
+	i.tax_percentage, i.tax_amount, i.total, i.billing_name, i.billing_email, i.billing_address, i.billing_zip,
+	i.billing_country, i.billing_state, i.issued_on, i.voided_on`
+
+// scanInvoice scans a row selected with invoiceColumns.
+func scanInvoice(row interface{ Scan(...any) error }) (entities.Invoice, error) {
+	var invoice entities.Invoice
+	err := row.Scan(&invoice.ID, &invoice.Number, &invoice.MemberID, &invoice.MemberSubscriptionID, &invoice.Kind,
+		&invoice.Currency, &invoice.Subtotal, &invoice.TaxPercentage, &invoice.TaxAmount, &invoice.Total,
+		&invoice.BillingName, &invoice.BillingEmail, &invoice.BillingAddress.Address, &invoice.BillingAddress.Zipcode,
+		&invoice.BillingAddress.Country, &invoice.BillingAddress.State, &invoice.IssuedOn, &invoice.VoidedOn)
+	invoice.BillingAddress.Primary = invoice.BillingAddress.Address != ""
+	return invoice, err
+}
+
+// GetMemberInvoiceCount returns the number of invoices issued to a member.
+func (member *MemberRepo) GetMemberInvoiceCount(ctx context.Context, memberID uuid.UUID) (int64, error) {
+	var count int64
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
+	err := member.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM member_invoice WHERE member_id = $1`+scope, params...).Scan(&count)
+	return count, err
+}
+
+// GetMemberInvoices returns a page of the member's invoices with their line items, newest first.
+func (member *MemberRepo) GetMemberInvoices(ctx context.Context, memberID uuid.UUID, page int32, limit int32) ([]entities.Invoice, error) {
+	scope, params := tenant.MemberCondition(ctx, "i.member_id", []any{memberID, limit, (page - 1) * limit})
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM member_invoice i
+		WHERE i.member_id = $1`+scope+`
+		ORDER BY i.issued_on DESC, i.invoice_number DESC
+		LIMIT $2 OFFSET $3
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []entities.Invoice{}
+	for rows.Next() {
+		invoice, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invoices, member.attachInvoiceLines(ctx, invoices)
+}
+
+// GetMemberInvoice returns an invoice of the member with its line items.
+// It returns sql.ErrNoRows when the member has no such invoice.
+func (member *MemberRepo) GetMemberInvoice(ctx context.Context, memberID uuid.UUID, invoiceID uuid.UUID) (entities.Invoice, error) {
+	scope, params := tenant.MemberCondition(ctx, "i.member_id", []any{invoiceID, memberID})
+	invoice, err := scanInvoice(member.db.QueryRowContext(ctx, `
+		SELECT `+invoiceColumns+`
+		FROM member_invoice i
+		WHERE i.id = $1
+		AND i.member_id = $2
+	`+scope, params...))
+	if err != nil {
+		return invoice, err
+	}
+
+	invoices := []entities.Invoice{invoice}
+	if err := member.attachInvoiceLines(ctx, invoices); err != nil {
+		return invoice, err
+	}
+	return invoices[0], nil
+}
+
+// attachInvoiceLines loads the line items of the invoices in one query.
+func (member *MemberRepo) attachInvoiceLines(ctx context.Context, invoices []entities.Invoice) error {
+	if len(invoices) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(invoices))
+	index := make(map[uuid.UUID]int, len(invoices))
+	for i, invoice := range invoices {
+		ids = append(ids, invoice.ID.String())
+		index[invoice.ID] = i
+		invoices[i].Lines = []entities.InvoiceLine{}
+	}
+
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT invoice_id, description, quantity, unit_amount, amount
+		FROM member_invoice_line
+		WHERE invoice_id = ANY($1::uuid[])
+		ORDER BY invoice_id, position
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			invoiceID uuid.UUID
+			line      entities.InvoiceLine
+		)
+		if err := rows.Scan(&invoiceID, &line.Description, &line.Quantity, &line.UnitAmount, &line.Amount); err != nil {
+			return err
+		}
+		i := index[invoiceID]
+		invoices[i].Lines = append(invoices[i].Lines, line)
+	}
+	return rows.Err()
+}
+
+// GetLatestCapturedPayment returns the most recent captured payment of a member subscription.
+// Refunded payments are no longer captured. It returns sql.ErrNoRows when there is none.
+func (member *MemberRepo) GetLatestCapturedPayment(ctx context.Context, memberSubscriptionID string) (entities.SubscriptionPayment, error) {
+	var payment entities.SubscriptionPayment
+	err := member.db.QueryRowContext(ctx, `
+		SELECT p.id, p.member_subscription_id, p.member_id, p.payment_gateway_id, p.gateway_payment_id, p.kind,
+			p.amount, p.currency, p.status, p.failure_reason
+		FROM member_subscription_payment p
+		WHERE p.member_subscription_id = $1
+		AND p.status = $2
+		ORDER BY p.created_on DESC
+		LIMIT 1
+	`, memberSubscriptionID, consts.PaymentStatusCaptured).Scan(
+		&payment.ID, &payment.MemberSubscriptionID, &payment.MemberID, &payment.PaymentGatewayID, &payment.GatewayPaymentID,
+		&payment.Kind, &payment.Amount, &payment.Currency, &payment.Status, &payment.FailureReason)
+	if err != nil {
+		return payment, err
+	}
+	return payment, nil
+}
+
+// UpdateSubscriptionPaymentStatus sets the status of a recorded subscription payment.
+func (member *MemberRepo) UpdateSubscriptionPaymentStatus(ctx context.Context, paymentID uuid.UUID, status string) error {
+	_, err := member.db.ExecContext(ctx, `UPDATE member_subscription_payment SET status = $2 WHERE id = $1`, paymentID, status)
+	return err
+}
+
+// UpdateSubscriptionStatus sets the status of a member subscription and writes the status change to the outbox
+// in the same transaction.
+func (member *MemberRepo) UpdateSubscriptionStatus(ctx context.Context, memberSubscriptionID string, status string) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return member.setSubscriptionStatus(ctx, tx, memberSubscriptionID, status)
+}
+
+// setSubscriptionStatus sets the status of a member subscription within a transaction and writes the status
+// change to the outbox. A missing subscription is not an error.
+func (member *MemberRepo) setSubscriptionStatus(ctx context.Context, tx *sql.Tx, memberSubscriptionID string, status string) error {
+	var (
+		memberID       uuid.UUID
+		previousStatus string
+	)
+	err := tx.QueryRowContext(ctx, `
+		UPDATE member_subscription
+		SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = $2)
+		FROM (
+			SELECT ms.id AS old_id, mss.name AS old_status
+			FROM member_subscription ms
+			INNER JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+			WHERE ms.id = $1
+			FOR UPDATE OF ms
+		) old
+		WHERE id = old.old_id
+		RETURNING member_id, old.old_status
+	`, memberSubscriptionID, status).Scan(&memberID, &previousStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil || previousStatus == status {
+		return err
+	}
+	return member.addStatusChangedEvent(ctx, tx, memberSubscriptionID, memberID, previousStatus, status)
+}
+
+// addStatusChangedEvent writes the member_subscription.status_changed event of a member subscription moved
+// between two statuses within the transaction of the move.
+func (member *MemberRepo) addStatusChangedEvent(ctx context.Context, tx *sql.Tx, memberSubscriptionID string, memberID uuid.UUID,
+	fromStatus string, toStatus string) error {
+
+	return member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionStatusChanged, consts.AggregateMemberSubscription, memberSubscriptionID,
+		entities.MemberSubscriptionEventData{
+			MemberSubscriptionID: memberSubscriptionID,
+			MemberID:             memberID,
+			Status:               toStatus,
+			PreviousStatus:       fromStatus,
+		})
+}
+
+// GetSubscriptionPaymentByGatewayPaymentID returns the checkout or renewal payment with the given gateway payment ID.
+// It returns sql.ErrNoRows when there is none.
+func (member *MemberRepo) GetSubscriptionPaymentByGatewayPaymentID(ctx context.Context, gatewayPaymentID string) (entities.SubscriptionPayment, error) {
+	var payment entities.SubscriptionPayment
+	err := member.db.QueryRowContext(ctx, `
+		SELECT p.id, p.member_subscription_id, p.member_id, p.payment_gateway_id, p.gateway_payment_id, p.kind,
+			p.amount, p.currency, p.status, p.failure_reason
+		FROM member_subscription_payment p
+		WHERE p.gateway_payment_id = $1
+		AND p.kind <> $2
+		ORDER BY p.created_on DESC
+		LIMIT 1
+	`, gatewayPaymentID, consts.PaymentKindRefund).Scan(
+		&payment.ID, &payment.MemberSubscriptionID, &payment.MemberID, &payment.PaymentGatewayID, &payment.GatewayPaymentID,
+		&payment.Kind, &payment.Amount, &payment.Currency, &payment.Status, &payment.FailureReason)
+	if err != nil {
+		return payment, err
+	}
+	return payment, nil
+}
+
+// ApplyPaymentWebhookEvent records a verified payment webhook event in payment_webhook_event and applies its
+// state change to the payment and the member subscription, all in one transaction.
+//
+// Events are deduplicated on the gateway and event ID: when the event was already received nothing is
+// changed and false is returned, so replays are safe. Payments only move forward: an event for a payment
+// that is not in one of the change's from payment statuses, like a late captured event of a refunded
+// payment or the webhook of a renewal captured synchronously, changes nothing and returns
+// consts.ErrPaymentEventOutOfOrder. The subscription status only changes when the subscription is in one
+// of the change's from statuses. The move is recorded in member_subscription_event and the statuses
+// before and after are kept with the event for auditing. A failed payment voids its invoice.
+func (member *MemberRepo) ApplyPaymentWebhookEvent(ctx context.Context, gateway string, partnerID string, event entities.PaymentWebhookEvent,
+	payload []byte, payment entities.SubscriptionPayment, change entities.PaymentStateChange) (applied bool, err error) {
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var webhookEventID uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payment_webhook_event
+		(gateway, event_id, event_type, partner_id, member_subscription_payment_id, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (gateway, event_id) DO NOTHING
+		RETURNING id
+	`, gateway, event.ID, event.Type, partnerID, payment.ID, string(payload)).Scan(&webhookEventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Already received.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
 
-// DecryptPaymentData decrypts the given encrypted data using a decryption key
-// specified in the MemberRepo configuration.
-//
-// The function returns the decrypted string and any error encountered during the decryption process.
-func (member *MemberRepo) DecryptPaymentData(ctx *gin.Context, data string) (string, error) {
-	if member.Cfg == nil {
-		return "", errors.New("configuration is nil")
+	result, err := tx.ExecContext(ctx, `
+		UPDATE member_subscription_payment
+		SET status = $2,
+			failure_reason = $3
+		WHERE id = $1
+		AND status = ANY($4)
+	`, payment.ID, change.PaymentStatus, event.Reason, pq.Array(change.FromPaymentStatuses))
+	if err != nil {
+		return false, err
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if moved == 0 {
+		return false, consts.ErrPaymentEventOutOfOrder
 	}
 
-	decryptionKey := member.Cfg.DecryptionKey
-	if decryptionKey == "" {
-		return "", errors.New("decryption key is empty")
+	var fromStatus string
+	err = tx.QueryRowContext(ctx, `
+		SELECT mss.name
+		FROM member_subscription ms
+		INNER JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+		WHERE ms.id = $1
+		FOR UPDATE OF ms
+	`, payment.MemberSubscriptionID).Scan(&fromStatus)
+	if err != nil {
+		return false, err
 	}
 
-	key := []byte(decryptionKey)
-	if len(key) == 0 {
-		return "", errors.New("decryption key is empty after conversion to byte slice")
+	toStatus := fromStatus
+	if change.SubscriptionStatus != "" && slices.Contains(change.FromStatuses, fromStatus) {
+		if change.Renew {
+			err = member.renewSubscription(ctx, tx, payment.MemberID, payment.MemberSubscriptionID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE member_subscription
+				SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = $2)
+				WHERE id = $1
+			`, payment.MemberSubscriptionID, change.SubscriptionStatus)
+		}
+		if err != nil {
+			return false, err
+		}
+		toStatus = change.SubscriptionStatus
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO member_subscription_event (member_subscription_id, member_id, from_status, to_status, source)
+			VALUES ($1, $2, $3, $4, $5)
+		`, payment.MemberSubscriptionID, payment.MemberID, fromStatus, toStatus, consts.SubscriptionEventSourcePaymentWebhook)
+		if err != nil {
+			return false, err
+		}
+
+		// Renewals write their renewed event along with the renewal
+		switch {
+		case toStatus == consts.Cancelled:
+			err = member.addOutboxEvent(ctx, tx, consts.DomainEventSubscriptionCancelled, consts.AggregateMemberSubscription, payment.MemberSubscriptionID,
+				entities.MemberSubscriptionEventData{
+					MemberSubscriptionID: payment.MemberSubscriptionID,
+					MemberID:             payment.MemberID,
+					Status:               toStatus,
+				})
+		case !change.Renew && toStatus != fromStatus:
+			err = member.addStatusChangedEvent(ctx, tx, payment.MemberSubscriptionID, payment.MemberID, fromStatus, toStatus)
+		}
+		if err != nil {
+			return false, err
+		}
 	}
 
-	decryptedString, err := crypto.Decrypt(data, key)
+	// Pending payments are invoiced when charged, the invoice of a payment that failed is void.
+	if change.PaymentStatus == consts.PaymentStatusFailed {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE member_invoice
+			SET voided_on = NOW()
+			WHERE member_subscription_payment_id = $1
+			AND voided_on IS NULL
+		`, payment.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if change.RetryRenewal {
+		err = member.scheduleRenewalRetry(ctx, tx, payment, event.Reason)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE payment_webhook_event
+		SET payment_status = $2,
+			from_status = $3,
+			to_status = $4,
+			applied_on = NOW()
+		WHERE id = $1
+	`, webhookEventID, change.PaymentStatus, fromStatus, toStatus)
 	if err != nil {
-		return "", fmt.Errorf("decryption failed: %v", err)
+		return false, err
 	}
 
-	return decryptedString, nil
+	return true, nil
 }
 
 // HasProductsReleaseEndDateGreaterThanToday checks if there are products associated with the given member subscription
@@ -2842,10 +5833,10 @@ func (member *MemberRepo) IsMemberRelatedToSubscription(ctx *gin.Context, member
 	var isRelated bool
 
 	// Query the database to check if the member is related to the specified MemberSubscriptionID.
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, memberSubscriptionID})
 	err := member.db.QueryRowContext(ctx, ` SELECT 1  FROM member_subscription
             WHERE member_id = $1 AND id = $2
-        
-    `, memberID, memberSubscriptionID).Scan(&isRelated)
+        `+scope, params...).Scan(&isRelated)
 
 	if err != nil {
 		// If there was an error querying the database, return an error.
@@ -2903,60 +5894,92 @@ func (member *MemberRepo) CheckMemberPartner(ctx *gin.Context, memberID uuid.UUI
 	return false, nil
 }
 
-// CheckSubscriptionExistenceAndStatusForRenewal checks if the provided memberSubscriptionID exists,
-// if the associated subscription plan exists, and if the plan is currently active.
-func (member *MemberRepo) CheckSubscriptionExistenceAndStatusForRenewal(ctx *gin.Context, memberSubscriptionID string) (bool, bool, error) {
-	var subscriptionExists, isPlanActive bool
-
-	// Check if the member subscription exists
-	query := `SELECT EXISTS(SELECT 1 FROM public.member_subscription WHERE id = $1)`
-
-	err := member.db.QueryRowContext(ctx, query, memberSubscriptionID).Scan(&subscriptionExists)
-	if err != nil {
-		return false, false, fmt.Errorf("error checking member subscription existence: %v", err)
-	}
-
-	// If the member subscription does not exist, no need to proceed with further checks
-	if !subscriptionExists {
-		return false, false, nil
-	}
-
-	// Check if the associated subscription plan exists and if it is currently active
-	planQuery := `
+// CheckMemberSubscriptionExists checks if the provided memberSubscriptionID exists along with the
+// plan version it was bought on. Superseded and retired plan versions are no longer active but their
+// subscriptions can still be renewed and cancelled.
+func (member *MemberRepo) CheckMemberSubscriptionExists(ctx *gin.Context, memberSubscriptionID string) (bool, error) {
+	var exists bool
+	err := member.db.QueryRowContext(ctx, `
 		SELECT EXISTS(
 			SELECT 1
-			FROM public.subscription_plan sp
-			JOIN public.member_subscription ms ON sp.id = ms.subscription_id
+			FROM public.member_subscription ms
+			JOIN public.subscription_plan sp ON sp.id = ms.subscription_id
 			WHERE ms.id = $1
-			AND sp.is_active = true
-		)`
-	err = member.db.QueryRowContext(ctx, planQuery, memberSubscriptionID).Scan(&isPlanActive)
+		)`, memberSubscriptionID).Scan(&exists)
 	if err != nil {
-		return false, false, fmt.Errorf("error checking subscription plan existence and status: %v", err)
+		return false, fmt.Errorf("error checking member subscription existence: %v", err)
 	}
-
-	return true, isPlanActive, nil
+	return exists, nil
 }
 
-// DeleteMember deletes a member
-func (member *MemberRepo) DeleteMember(ctx *gin.Context, MemberID uuid.UUID) error {
+// DeleteMember deletes a member. A version other than 0 has to match the version of the member.
+func (member *MemberRepo) DeleteMember(ctx *gin.Context, MemberID uuid.UUID, version int64) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
 	// Construct the SQL query to update the is_deleted field
 	// Get the current date
 	currentDate := time.Now().UTC().Format("2006-01-02")
 
 	query := `
         UPDATE public.member
-        SET is_deleted = true,is_active = false, deleted_on = $2
-        WHERE id = $1 AND is_deleted = false
+        SET is_deleted = true,is_active = false, deleted_on = $2, version = version + 1
+        FROM (SELECT id AS old_id, is_active AS was_active FROM public.member WHERE id = $1 FOR UPDATE) old
+        WHERE id = old_id AND is_deleted = false`
+	params := []any{MemberID, currentDate}
+	if version > 0 {
+		query += " AND version = $3"
+		params = append(params, version)
+	}
+	scope, params := tenant.Condition(ctx, "partner_id", params)
+	query += scope + `
+        RETURNING partner_id, old.was_active
     `
 
-	// Execute the SQL query
-	_, err := member.db.Exec(query, MemberID, currentDate)
+	// Execute the SQL query, a member deleted before or belonging to another partner is left as is,
+	// the deletion of a member changed since the expected version fails
+	var partnerID sql.NullString
+	var wasActive bool
+	err = tx.QueryRowContext(ctx, query, params...).Scan(&partnerID, &wasActive)
+	if errors.Is(err, sql.ErrNoRows) && version > 0 {
+		err = consts.ErrVersionMismatch
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+		return nil
+	}
 	if err != nil {
+		return err
+	}
 
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventMemberDeleted, consts.AggregateMember, MemberID.String(),
+		entities.MemberEventData{
+			MemberID:  MemberID,
+			PartnerID: partnerID.String,
+		})
+	if err != nil {
 		return err
 	}
-	return nil
+
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: MemberID,
+		Action:   consts.AuditMemberDeleted,
+		Changes: audit.Changes{}.
+			Set("is_deleted", false, true).
+			Set("is_active", wasActive, false).
+			Set("deleted_on", nil, currentDate),
+	})
+	return err
 }
 
 // IsActive Checks if the member is currently active or not.
@@ -2966,9 +5989,10 @@ func (member *MemberRepo) IsActive(ctx *gin.Context, MemberID uuid.UUID) (bool,
 	query := `
     SELECT 1 FROM public.member WHERE is_active = true AND id = $1
 `
+	scope, params := tenant.Condition(ctx, "partner_id", []any{MemberID})
 
 	// Execute the SQL query
-	_, err := member.db.Exec(query, MemberID)
+	_, err := member.db.Exec(query+scope, params...)
 	if err != nil {
 
 		return false, err
@@ -2983,9 +6007,10 @@ func (member *MemberRepo) IsDeleted(ctx *gin.Context, MemberID uuid.UUID) (bool,
 	query := `
     SELECT 1 FROM public.member WHERE is_deleted = true AND id = $1
 `
+	scope, params := tenant.Condition(ctx, "partner_id", []any{MemberID})
 
 	// Execute the SQL query
-	_, err := member.db.Exec(query, MemberID)
+	_, err := member.db.Exec(query+scope, params...)
 	if err != nil {
 
 		return false, err
@@ -2994,7 +6019,22 @@ func (member *MemberRepo) IsDeleted(ctx *gin.Context, MemberID uuid.UUID) (bool,
 }
 
 // AddMemberStores adds stores related to a member
-func (member *MemberRepo) AddMemberStoresById(ctx *gin.Context, memberID uuid.UUID, stores []uuid.UUID) error {
+func (member *MemberRepo) AddMemberStoresById(ctx *gin.Context, memberID uuid.UUID, stores []uuid.UUID) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = checkMemberInScope(ctx, tx, memberID); err != nil {
+		return err
+	}
 
 	// Insert the member_id and store_id into the member_store table
 	for _, storeID := range stores {
@@ -3009,25 +6049,32 @@ func (member *MemberRepo) AddMemberStoresById(ctx *gin.Context, memberID uuid.UU
 		var isActive bool
 		var customName string
 
-		err := member.db.QueryRow(fetchQuery, storeID).Scan(&isStore, &isActive)
+		err = tx.QueryRowContext(ctx, fetchQuery, storeID).Scan(&isStore, &isActive)
 		if err != nil {
 			fmt.Println("Error fetching values from partner_store:", err)
 			return err
 		}
 
-		// Now, you can use these fetched values to insert into the member_store table
+		// Now, you can use these fetched values to insert into the member_store table, after the
+		// stores the member already has
 		insertQuery := `
-			INSERT INTO public.member_store (member_id, store_id, is_store, is_active, custom_store_name)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO public.member_store (member_id, store_id, is_store, is_active, custom_store_name, position)
+			VALUES ($1, $2, $3, $4, $5,
+				(SELECT COALESCE(MAX(position), 0) + 1 FROM public.member_store WHERE member_id = $1))
 		`
-		_, err = member.db.Exec(insertQuery, memberID, storeID, isStore, isActive, customName)
+		_, err = tx.ExecContext(ctx, insertQuery, memberID, storeID, isStore, isActive, customName)
 		if err != nil {
 			fmt.Println("Error inserting into member_store:", err)
 			return err
 		}
 	}
 
-	return nil
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: memberID,
+		Action:   consts.AuditStoresAdded,
+		Changes:  audit.Changes{}.Set("stores", nil, stores),
+	})
+	return err
 }
 
 // GetStoreIDsByPartnerID retrieves all store IDs related to the provided partner ID
@@ -3141,31 +6188,249 @@ func (member *MemberRepo) CheckStoreNameExistsAndReturnIDs(ctx context.Context,
 		storeIDs = append(storeIDs, storeID)
 	}
 
-	return allExist, storeIDs, nil
+	return allExist, storeIDs, nil
+}
+
+// StorePartnerRelation checks if given store is related to members designated partner
+func (member *MemberRepo) StorePartnerRelation(ctx context.Context, partnerID uuid.UUID, relatedStoreIDs []uuid.UUID) (map[string]bool, error) {
+	existingStoreRelations := make(map[string]bool)
+
+	for _, storeId := range relatedStoreIDs {
+		query := `
+            SELECT EXISTS (
+                SELECT 1
+                FROM public.partner_store
+                WHERE partner_id = $1 AND store_id = $2
+            )
+        `
+		var exists bool
+		err := member.db.QueryRowContext(ctx, query, partnerID, storeId).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+
+		existingStoreRelations[storeId.String()] = exists
+	}
+
+	return existingStoreRelations, nil
+}
+
+// GetMemberStores returns the stores of a member in the member's order.
+func (member *MemberRepo) GetMemberStores(ctx context.Context, memberID uuid.UUID) ([]entities.MemberStore, error) {
+	scope, params := tenant.MemberCondition(ctx, "ms.member_id", []any{memberID})
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT ms.store_id, COALESCE(s.name, ''), COALESCE(ms.custom_store_name, ''), ms.is_active, ms.position
+		FROM public.member_store ms
+		LEFT JOIN public.store s ON s.id = ms.store_id
+		WHERE ms.member_id = $1`+scope+`
+		ORDER BY ms.position, s.name
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stores := []entities.MemberStore{}
+	for rows.Next() {
+		var store entities.MemberStore
+		if err := rows.Scan(&store.StoreID, &store.Name, &store.CustomName, &store.IsActive, &store.Position); err != nil {
+			return nil, err
+		}
+		stores = append(stores, store)
+	}
+	return stores, rows.Err()
+}
+
+// UpdateMemberStore updates the custom name and the active state of a member store.
+// It returns sql.ErrNoRows when the member does not have the store.
+func (member *MemberRepo) UpdateMemberStore(ctx context.Context, memberID uuid.UUID, storeID uuid.UUID, update entities.MemberStoreUpdate) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var oldName sql.NullString
+	var oldActive bool
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, storeID})
+	err = tx.QueryRowContext(ctx, `
+		SELECT custom_store_name, is_active
+		FROM public.member_store
+		WHERE member_id = $1 AND store_id = $2`+scope+`
+		FOR UPDATE
+	`, params...).Scan(&oldName, &oldActive)
+	if err != nil {
+		return err
+	}
+
+	customName, isActive := oldName.String, oldActive
+	if update.CustomName != nil {
+		customName = *update.CustomName
+	}
+	if update.IsActive != nil {
+		isActive = *update.IsActive
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE public.member_store
+		SET custom_store_name = $3, is_active = $4
+		WHERE member_id = $1 AND store_id = $2
+	`, memberID, storeID, customName, isActive)
+	if err != nil {
+		return err
+	}
+
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID:   memberID,
+		ResourceID: storeID.String(),
+		Action:     consts.AuditStoreUpdated,
+		Changes: audit.Changes{}.
+			Set("custom_store_name", oldName.String, customName).
+			Set("is_active", oldActive, isActive),
+	})
+	return err
+}
+
+// RemoveMemberStores removes stores from a member and returns the number of stores removed.
+// Stores the member does not have are skipped.
+func (member *MemberRepo) RemoveMemberStores(ctx context.Context, memberID uuid.UUID, storeIDs []uuid.UUID) (removed int64, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, pq.Array(storeIDs)})
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM public.member_store
+		WHERE member_id = $1 AND store_id = ANY($2)`+scope+`
+		RETURNING store_id
+	`, params...)
+	if err != nil {
+		return 0, err
+	}
+	var removedIDs []uuid.UUID
+	for rows.Next() {
+		var storeID uuid.UUID
+		if err = rows.Scan(&storeID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		removedIDs = append(removedIDs, storeID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(removedIDs) == 0 {
+		return 0, nil
+	}
+
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: memberID,
+		Action:   consts.AuditStoresRemoved,
+		Changes:  audit.Changes{}.Set("stores", removedIDs, nil),
+	})
+	return int64(len(removedIDs)), err
+}
+
+// ReorderMemberStores numbers the stores of a member in the order of storeIDs, which lists
+// every store of the member.
+func (member *MemberRepo) ReorderMemberStores(ctx context.Context, memberID uuid.UUID, storeIDs []uuid.UUID) (err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = checkMemberInScope(ctx, tx, memberID); err != nil {
+		return err
+	}
+
+	var oldOrder []uuid.UUID
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID})
+	rows, err := tx.QueryContext(ctx, `
+		SELECT store_id
+		FROM public.member_store
+		WHERE member_id = $1`+scope+`
+		ORDER BY position
+		FOR UPDATE
+	`, params...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var storeID uuid.UUID
+		if err = rows.Scan(&storeID); err != nil {
+			rows.Close()
+			return err
+		}
+		oldOrder = append(oldOrder, storeID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	scope, params = tenant.MemberCondition(ctx, "ms.member_id", []any{memberID, pq.Array(storeIDs)})
+	_, err = tx.ExecContext(ctx, `
+		UPDATE public.member_store ms
+		SET position = ordered.position
+		FROM UNNEST($2::uuid[]) WITH ORDINALITY AS ordered(store_id, position)
+		WHERE ms.member_id = $1 AND ms.store_id = ordered.store_id`+scope+`
+	`, params...)
+	if err != nil {
+		return err
+	}
+
+	err = member.addAuditEntry(ctx, tx, entities.AuditEntry{
+		MemberID: memberID,
+		Action:   consts.AuditStoresReordered,
+		Changes:  audit.Changes{}.Set("stores", oldOrder, storeIDs),
+	})
+	return err
 }
 
-// StorePartnerRelation checks if given store is related to members designated partner
-func (member *MemberRepo) StorePartnerRelation(ctx context.Context, partnerID uuid.UUID, relatedStoreIDs []uuid.UUID) (map[string]bool, error) {
-	existingStoreRelations := make(map[string]bool)
+// GetPartnerStores returns the catalogue of stores the partner offers to its members, by name.
+func (member *MemberRepo) GetPartnerStores(ctx context.Context, partnerID uuid.UUID) ([]entities.PartnerStore, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT ps.store_id, COALESCE(s.name, ''), COALESCE(ps.custom_name, ''), ps.is_active
+		FROM public.partner_store ps
+		LEFT JOIN public.store s ON s.id = ps.store_id
+		WHERE ps.partner_id = $1
+		ORDER BY s.name, ps.store_id
+	`, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	for _, storeId := range relatedStoreIDs {
-		query := `
-            SELECT EXISTS (
-                SELECT 1
-                FROM public.partner_store
-                WHERE partner_id = $1 AND store_id = $2
-            )
-        `
-		var exists bool
-		err := member.db.QueryRowContext(ctx, query, partnerID, storeId).Scan(&exists)
-		if err != nil {
+	stores := []entities.PartnerStore{}
+	for rows.Next() {
+		var store entities.PartnerStore
+		if err := rows.Scan(&store.StoreID, &store.Name, &store.CustomName, &store.IsActive); err != nil {
 			return nil, err
 		}
-
-		existingStoreRelations[storeId.String()] = exists
+		stores = append(stores, store)
 	}
-
-	return existingStoreRelations, nil
+	return stores, rows.Err()
 }
 
 // CheckMemberStoreExists checks if the specified stores exist for a member
@@ -3181,9 +6446,10 @@ func (member *MemberRepo) CheckNonExistingMemberStores(ctx *gin.Context, memberI
 			FROM public.member_store
 			WHERE member_id = $1 AND store_id = $2
 		`
+		scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, storeID})
 
 		var count int
-		err := member.db.QueryRow(fetchQuery, memberID, storeID).Scan(&count)
+		err := member.db.QueryRowContext(ctx, fetchQuery+scope, params...).Scan(&count)
 		if err != nil {
 			fmt.Println("Error fetching values from member_store:", err)
 			return nil, err
@@ -3262,3 +6528,636 @@ func (member *MemberRepo) CheckPartnerIDExists(ctx *gin.Context, partnerID strin
 
 	return exists, nil
 }
+
+// checkMemberInScope returns sql.ErrNoRows when the member does not exist or belongs to another
+// partner than the one of ctx, for writes keyed by a member that do not read it first.
+func checkMemberInScope(ctx context.Context, q rowQuerier, memberID uuid.UUID) error {
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
+	var id uuid.UUID
+	return q.QueryRowContext(ctx, `SELECT id FROM member WHERE id = $1`+scope, params...).Scan(&id)
+}
+
+// addOutboxEvent writes a domain event to the outbox within the transaction of the change it describes,
+// so the event is published if and only if the change is committed.
+func (member *MemberRepo) addOutboxEvent(ctx context.Context, tx *sql.Tx, eventType string, aggregateType string, aggregateID string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO member_outbox (event_type, schema_version, aggregate_type, aggregate_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+	`, eventType, consts.DomainEventSchemaVersions[eventType], aggregateType, aggregateID, string(payload))
+	return err
+}
+
+// addAuditEntry records an operation on a member in the audit trail within the transaction of
+// the operation. The actor and the request are taken from ctx.
+func (member *MemberRepo) addAuditEntry(ctx context.Context, tx *sql.Tx, entry entities.AuditEntry) error {
+	if entry.Changes == nil {
+		entry.Changes = []entities.AuditChange{}
+	}
+	payload, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO member_audit (member_id, resource_id, actor_id, action, changes, request_id)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, NULLIF($6, ''))
+	`, entry.MemberID, entry.ResourceID, audit.Actor(ctx), entry.Action, string(payload), audit.RequestID(ctx))
+	return err
+}
+
+// ClaimOutboxEvents returns up to limit outbox events due for publishing, oldest first. The claimed events
+// are pushed back by lease so other replicas of the relay skip them while they are being published.
+func (member *MemberRepo) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]entities.OutboxEvent, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM member_outbox
+			WHERE published_on IS NULL
+			AND next_attempt_on <= NOW()
+			ORDER BY occurred_on
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE member_outbox mo
+		SET next_attempt_on = NOW() + $2 * INTERVAL '1 second'
+		FROM due
+		WHERE mo.id = due.id
+		RETURNING mo.id, mo.event_type, mo.schema_version, mo.aggregate_type, mo.aggregate_id, mo.payload, mo.occurred_on, mo.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entities.OutboxEvent
+	for rows.Next() {
+		var (
+			event   entities.OutboxEvent
+			payload []byte
+		)
+		err := rows.Scan(&event.ID, &event.Type, &event.SchemaVersion, &event.AggregateType, &event.AggregateID,
+			&payload, &event.OccurredOn, &event.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		event.Data = payload
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// UPDATE ... RETURNING does not keep the order of the claiming query.
+	slices.SortStableFunc(events, func(a, b entities.OutboxEvent) int {
+		return a.OccurredOn.Compare(b.OccurredOn)
+	})
+	return events, nil
+}
+
+// MarkOutboxEventPublished records that an outbox event has been published.
+func (member *MemberRepo) MarkOutboxEventPublished(ctx context.Context, eventID uuid.UUID) error {
+	_, err := member.db.ExecContext(ctx, `
+		UPDATE member_outbox
+		SET published_on = NOW(),
+			last_error = NULL
+		WHERE id = $1
+	`, eventID)
+	return err
+}
+
+// MarkOutboxEventFailed records a failed attempt to publish an outbox event, which is retried after retryIn.
+func (member *MemberRepo) MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, retryIn time.Duration, reason string) error {
+	_, err := member.db.ExecContext(ctx, `
+		UPDATE member_outbox
+		SET attempts = attempts + 1,
+			next_attempt_on = NOW() + $2 * INTERVAL '1 second',
+			last_error = $3
+		WHERE id = $1
+	`, eventID, retryIn.Seconds(), reason)
+	return err
+}
+
+// GetMemberExport collects the data held about a member: profile, billing addresses, subscriptions,
+// stores and login sessions.
+func (member *MemberRepo) GetMemberExport(ctx context.Context, memberID uuid.UUID) (entities.MemberExport, error) {
+	export := entities.MemberExport{
+		MemberID:         memberID,
+		BillingAddresses: []entities.BillingAddress{},
+		Subscriptions:    []entities.MemberExportSubscription{},
+		Stores:           []entities.MemberExportStore{},
+		RefreshTokens:    []entities.MemberExportRefreshToken{},
+	}
+
+	profile := &export.Profile
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID})
+	err := member.db.QueryRowContext(ctx, `
+		SELECT COALESCE(title, ''), COALESCE(firstname, ''), COALESCE(lastname, ''), COALESCE(gender, ''),
+			email, COALESCE(mobile, ''), COALESCE(address1, ''), COALESCE(address2, ''), COALESCE(city, ''),
+			COALESCE(state_code, ''), COALESCE(country_code, ''), COALESCE(zip, ''), COALESCE(language_code, ''),
+			COALESCE(is_paying_tax, false), COALESCE(is_mail_subscribed, false), COALESCE(partner_id::TEXT, '')
+		FROM member
+		WHERE id = $1`+scope, params...).Scan(&profile.Title, &profile.FirstName, &profile.LastName, &profile.Gender,
+		&profile.Email, &profile.Phone, &profile.Address1, &profile.Address2, &profile.City,
+		&profile.State, &profile.Country, &profile.Zipcode, &profile.Language,
+		&profile.PayingTax, &profile.EmailSubscribed, &profile.PartnerID)
+	if err != nil {
+		return export, err
+	}
+
+	export.BillingAddresses, err = member.GetAllBillingAddresses(ctx, memberID)
+	if err != nil {
+		return export, err
+	}
+	if export.BillingAddresses == nil {
+		export.BillingAddresses = []entities.BillingAddress{}
+	}
+
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT ms.id, ms.subscription_id, COALESCE(sp.name, ''), COALESCE(ms.custom_name, ''),
+			COALESCE(mss.name, ''), ms.expiration_date, ms.renewed_on
+		FROM member_subscription ms
+		LEFT JOIN subscription_plan sp ON sp.id = ms.subscription_id
+		LEFT JOIN member_subscription_status mss ON mss.id = ms.member_subscription_status_id
+		WHERE ms.member_id = $1
+		ORDER BY ms.expiration_date
+	`, memberID)
+	if err != nil {
+		return export, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			subscription entities.MemberExportSubscription
+			renewedOn    sql.NullTime
+		)
+		err := rows.Scan(&subscription.MemberSubscriptionID, &subscription.SubscriptionID, &subscription.PlanName,
+			&subscription.CustomName, &subscription.Status, &subscription.ExpirationDate, &renewedOn)
+		if err != nil {
+			return export, err
+		}
+		if renewedOn.Valid {
+			subscription.RenewedOn = &renewedOn.Time
+		}
+		export.Subscriptions = append(export.Subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return export, err
+	}
+
+	storeRows, err := member.db.QueryContext(ctx, `
+		SELECT store_id, COALESCE(custom_store_name, ''), COALESCE(is_active, false)
+		FROM member_store
+		WHERE member_id = $1
+	`, memberID)
+	if err != nil {
+		return export, err
+	}
+	defer storeRows.Close()
+	for storeRows.Next() {
+		var store entities.MemberExportStore
+		if err := storeRows.Scan(&store.StoreID, &store.CustomName, &store.IsActive); err != nil {
+			return export, err
+		}
+		export.Stores = append(export.Stores, store)
+	}
+	if err := storeRows.Err(); err != nil {
+		return export, err
+	}
+
+	tokenRows, err := member.db.QueryContext(ctx, `
+		SELECT COALESCE(partner_id::TEXT, ''), COALESCE(is_revoked, false), created_on
+		FROM refresh_token
+		WHERE member_id = $1
+		ORDER BY created_on
+	`, memberID)
+	if err != nil {
+		return export, err
+	}
+	defer tokenRows.Close()
+	for tokenRows.Next() {
+		var token entities.MemberExportRefreshToken
+		if err := tokenRows.Scan(&token.PartnerID, &token.Revoked, &token.CreatedOn); err != nil {
+			return export, err
+		}
+		export.RefreshTokens = append(export.RefreshTokens, token)
+	}
+	return export, tokenRows.Err()
+}
+
+// RequestMemberErasure queues the erasure of a member's personal data. It returns false when an
+// erasure of the member is already pending or the member is not one of the partner of ctx.
+func (member *MemberRepo) RequestMemberErasure(ctx context.Context, memberID uuid.UUID, requestedBy string) (bool, error) {
+	scope, params := tenant.Condition(ctx, "partner_id", []any{memberID, requestedBy})
+	result, err := member.db.ExecContext(ctx, `
+		INSERT INTO member_erasure_request (member_id, requested_by)
+		SELECT id, $2 FROM member WHERE id = $1`+scope+`
+		ON CONFLICT (member_id) WHERE completed_on IS NULL DO NOTHING
+	`, params...)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted > 0, nil
+}
+
+// GetPendingMemberErasures returns up to limit pending erasure requests, oldest first.
+func (member *MemberRepo) GetPendingMemberErasures(ctx context.Context, limit int) ([]entities.MemberErasureRequest, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT id, member_id, requested_by, requested_on
+		FROM member_erasure_request
+		WHERE completed_on IS NULL
+		ORDER BY requested_on
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []entities.MemberErasureRequest
+	for rows.Next() {
+		var request entities.MemberErasureRequest
+		if err := rows.Scan(&request.ID, &request.MemberID, &request.RequestedBy, &request.RequestedOn); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, rows.Err()
+}
+
+// EraseMember anonymizes the personal data of a member in one transaction. The member row is kept,
+// so the products and tracks referencing it stay valid, but every personal column is cleared, the
+// email is replaced by a unique placeholder and the member is deleted. Billing addresses and invoices
+// lose their address lines, login sessions, password reset keys and attempts and two-factor secrets are removed and the email is
+// dropped from all its outbox events, published or not. The audit trail keeps which fields changed but not their
+// values, and the bulk import rows of the member lose their email and payload. It returns false when the
+// request was already completed.
+func (member *MemberRepo) EraseMember(ctx context.Context, request entities.MemberErasureRequest) (erased bool, err error) {
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var pending bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT completed_on IS NULL
+		FROM member_erasure_request
+		WHERE id = $1
+		FOR UPDATE
+	`, request.ID).Scan(&pending)
+	if err != nil {
+		return false, err
+	}
+	if !pending {
+		return false, nil
+	}
+
+	placeholderEmail := fmt.Sprintf("erased-%s@%s", request.MemberID, consts.ErasedEmailDomain)
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		// Import rows are matched on the email, before it is replaced. Pending rows lose their
+		// payload and cannot be imported any more.
+		{`UPDATE member_import_row AS r
+			SET email = '', payload = NULL,
+				status = CASE WHEN r.status = $2 THEN $3 ELSE r.status END,
+				processed_on = COALESCE(r.processed_on, NOW())
+			FROM member_import AS i, member AS m
+			WHERE i.id = r.import_id
+			AND m.id = $1
+			AND i.partner_id = m.partner_id
+			AND LOWER(r.email) = LOWER(m.email)`, []any{request.MemberID, consts.ImportStatusPending, consts.ImportRowFailed}},
+		{`UPDATE member
+			SET title = NULL, firstname = NULL, lastname = NULL, gender = NULL, mobile = NULL,
+				address1 = NULL, address2 = NULL, city = NULL, zip = NULL,
+				email = $2, password = '', reset_password_key = NULL, password_expiry = NULL, is_mail_subscribed = false,
+				is_deleted = true, is_active = false, deleted_on = COALESCE(deleted_on, current_date),
+				erased_on = NOW()
+			WHERE id = $1`, []any{request.MemberID, placeholderEmail}},
+		{`UPDATE member_billing_address SET address = '', zip = '' WHERE member_id = $1`, []any{request.MemberID}},
+		{`UPDATE member_invoice
+			SET billing_name = '', billing_email = '', billing_address = '', billing_zip = ''
+			WHERE member_id = $1`, []any{request.MemberID}},
+		{`UPDATE member_audit
+			SET changes = (
+				SELECT COALESCE(jsonb_agg(jsonb_set(jsonb_set(c, '{old}', to_jsonb($2::TEXT)), '{new}', to_jsonb($2::TEXT))), '[]')
+				FROM jsonb_array_elements(changes) AS c
+			)
+			WHERE member_id = $1`, []any{request.MemberID, audit.Redacted}},
+		{`DELETE FROM refresh_token WHERE member_id = $1`, []any{request.MemberID}},
+		{`DELETE FROM password_reset_lockout WHERE subject = $1 AND scope = $2`, []any{request.MemberID.String(), consts.LockoutScopeMember}},
+		{`DELETE FROM member_recovery_code WHERE member_id = $1`, []any{request.MemberID}},
+		{`DELETE FROM member_two_factor WHERE member_id = $1`, []any{request.MemberID}},
+		{`UPDATE member_outbox
+			SET payload = payload - 'email'
+			WHERE aggregate_id = $1 AND payload ? 'email'`, []any{request.MemberID}},
+		{`UPDATE member_erasure_request SET completed_on = NOW() WHERE id = $1`, []any{request.ID}},
+	}
+	for _, statement := range statements {
+		if _, err = tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return false, err
+		}
+	}
+
+	err = member.addOutboxEvent(ctx, tx, consts.DomainEventMemberErased, consts.AggregateMember, request.MemberID.String(),
+		entities.MemberEventData{
+			MemberID: request.MemberID,
+		})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// memberImportPayload is the payload of a member import row. The password of the member is not
+// stored, its hash and password policy errors are.
+type memberImportPayload struct {
+	entities.Member
+	PasswordHash   string              `json:"password_hash,omitempty"`
+	PasswordErrors map[string][]string `json:"password_errors,omitempty"`
+}
+
+// CreateMemberImport stores a bulk import with its rows, pending for the import job. The passwords
+// of the rows are hashed, rows with password policy errors keep no password.
+func (member *MemberRepo) CreateMemberImport(ctx context.Context, memberImport entities.MemberImport, importRows []entities.MemberImportRow) (_ entities.MemberImport, err error) {
+	rowNumbers := make([]int, len(importRows))
+	emails := make([]string, len(importRows))
+	payloads := make([]string, len(importRows))
+	for i, row := range importRows {
+		rowPayload := memberImportPayload{Member: row.Member, PasswordErrors: row.PasswordErrors}
+		if row.Member.Provider == consts.ProviderInternal && len(row.PasswordErrors) == 0 {
+			rowPayload.PasswordHash, err = member.hasher.Hash(row.Member.Password)
+			if err != nil {
+				return memberImport, err
+			}
+		}
+		rowPayload.Password = ""
+		payload, err := json.Marshal(rowPayload)
+		if err != nil {
+			return memberImport, err
+		}
+		rowNumbers[i] = row.RowNumber
+		emails[i] = row.Member.Email
+		payloads[i] = string(payload)
+	}
+
+	tx, err := member.db.BeginTx(ctx, nil)
+	if err != nil {
+		return memberImport, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	memberImport.Status = consts.ImportStatusPending
+	memberImport.TotalRows = len(importRows)
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO member_import (partner_id, format, status, total_rows, requested_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_on
+	`, memberImport.PartnerID, memberImport.Format, memberImport.Status, memberImport.TotalRows, memberImport.RequestedBy).
+		Scan(&memberImport.ID, &memberImport.CreatedOn)
+	if err != nil {
+		return memberImport, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO member_import_row (import_id, row_number, email, payload)
+		SELECT $1, r.row_number, r.email, r.payload::JSONB
+		FROM UNNEST($2::INTEGER[], $3::TEXT[], $4::TEXT[]) AS r(row_number, email, payload)
+	`, memberImport.ID, pq.Array(rowNumbers), pq.Array(emails), pq.Array(payloads))
+	return memberImport, err
+}
+
+// ClaimMemberImports marks up to limit open imports as processing and returns them, oldest first.
+// Imports left processing for longer than the lease, by a replica that stopped, are claimed again.
+func (member *MemberRepo) ClaimMemberImports(ctx context.Context, limit int, lease time.Duration) ([]entities.MemberImport, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		UPDATE member_import
+		SET status = $1, started_on = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM member_import
+			WHERE completed_on IS NULL
+			AND (status = $2 OR started_on < NOW() - MAKE_INTERVAL(secs => $3))
+			ORDER BY created_on
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, partner_id, format, status, total_rows, requested_by, created_on
+	`, consts.ImportStatusProcessing, consts.ImportStatusPending, lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var imports []entities.MemberImport
+	for rows.Next() {
+		var memberImport entities.MemberImport
+		err := rows.Scan(&memberImport.ID, &memberImport.PartnerID, &memberImport.Format, &memberImport.Status,
+			&memberImport.TotalRows, &memberImport.RequestedBy, &memberImport.CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, memberImport)
+	}
+	return imports, rows.Err()
+}
+
+// GetPendingMemberImportRows returns the rows of an import that were not processed yet, in upload order.
+func (member *MemberRepo) GetPendingMemberImportRows(ctx context.Context, importID uuid.UUID) ([]entities.MemberImportRow, error) {
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT row_number, payload
+		FROM member_import_row
+		WHERE import_id = $1
+		AND status = $2
+		ORDER BY row_number
+	`, importID, consts.ImportStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var importRows []entities.MemberImportRow
+	for rows.Next() {
+		var (
+			row        entities.MemberImportRow
+			payload    []byte
+			rowPayload memberImportPayload
+		)
+		if err := rows.Scan(&row.RowNumber, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &rowPayload); err != nil {
+			return nil, err
+		}
+		row.Member, row.PasswordErrors = rowPayload.Member, rowPayload.PasswordErrors
+		row.Member.PasswordHash = rowPayload.PasswordHash
+		importRows = append(importRows, row)
+	}
+	return importRows, rows.Err()
+}
+
+// CompleteMemberImportRow records the outcome of a row: imported without validation errors,
+// failed with them. The payload of the row is cleared.
+func (member *MemberRepo) CompleteMemberImportRow(ctx context.Context, importID uuid.UUID, rowNumber int, validationErrors map[string][]string) error {
+	return completeMemberImportRow(ctx, member.db, importID, rowNumber, validationErrors)
+}
+
+// execer runs statements on the database or within a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// completeMemberImportRow is CompleteMemberImportRow on the database or within a transaction.
+func completeMemberImportRow(ctx context.Context, q execer, importID uuid.UUID, rowNumber int, validationErrors map[string][]string) error {
+	status := consts.ImportRowImported
+	var errorsJSON any
+	if len(validationErrors) != 0 {
+		data, err := json.Marshal(validationErrors)
+		if err != nil {
+			return err
+		}
+		status, errorsJSON = consts.ImportRowFailed, string(data)
+	}
+
+	_, err := q.ExecContext(ctx, `
+		UPDATE member_import_row
+		SET status = $3, errors = $4, payload = NULL, processed_on = NOW()
+		WHERE import_id = $1
+		AND row_number = $2
+	`, importID, rowNumber, status, errorsJSON)
+	return err
+}
+
+// CompleteMemberImport marks an import as completed with the counts of its imported and failed rows.
+func (member *MemberRepo) CompleteMemberImport(ctx context.Context, importID uuid.UUID) error {
+	_, err := member.db.ExecContext(ctx, `
+		UPDATE member_import i
+		SET status = $2,
+			completed_on = NOW(),
+			imported_rows = (SELECT COUNT(*) FROM member_import_row WHERE import_id = i.id AND status = $3),
+			failed_rows = (SELECT COUNT(*) FROM member_import_row WHERE import_id = i.id AND status = $4)
+		WHERE i.id = $1
+	`, importID, consts.ImportStatusCompleted, consts.ImportRowImported, consts.ImportRowFailed)
+	return err
+}
+
+// GetMemberImport returns an import with its progress and the errors of its failed rows.
+// It returns sql.ErrNoRows when there is no such import for the partner of the request.
+func (member *MemberRepo) GetMemberImport(ctx context.Context, importID uuid.UUID) (entities.MemberImport, error) {
+	memberImport := entities.MemberImport{
+		Errors: []entities.MemberImportRowError{},
+	}
+	scope, params := tenant.Condition(ctx, "i.partner_id", []any{importID, consts.ImportRowImported, consts.ImportRowFailed})
+	err := member.db.QueryRowContext(ctx, `
+		SELECT i.id, i.partner_id, i.format, i.status, i.total_rows,
+			(SELECT COUNT(*) FROM member_import_row WHERE import_id = i.id AND status = $2),
+			(SELECT COUNT(*) FROM member_import_row WHERE import_id = i.id AND status = $3),
+			i.requested_by, i.created_on, i.started_on, i.completed_on
+		FROM member_import i
+		WHERE i.id = $1`+scope, params...).
+		Scan(&memberImport.ID, &memberImport.PartnerID, &memberImport.Format, &memberImport.Status, &memberImport.TotalRows,
+			&memberImport.ImportedRows, &memberImport.FailedRows,
+			&memberImport.RequestedBy, &memberImport.CreatedOn, &memberImport.StartedOn, &memberImport.CompletedOn)
+	if err != nil {
+		return memberImport, err
+	}
+
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT row_number, email, errors
+		FROM member_import_row
+		WHERE import_id = $1
+		AND status = $2
+		ORDER BY row_number
+	`, importID, consts.ImportRowFailed)
+	if err != nil {
+		return memberImport, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			rowError   entities.MemberImportRowError
+			errorsJSON []byte
+		)
+		if err := rows.Scan(&rowError.RowNumber, &rowError.Email, &errorsJSON); err != nil {
+			return memberImport, err
+		}
+		if err := json.Unmarshal(errorsJSON, &rowError.Errors); err != nil {
+			return memberImport, err
+		}
+		memberImport.Errors = append(memberImport.Errors, rowError)
+	}
+	return memberImport, rows.Err()
+}
+
+// GetMemberAuditCount returns the number of audit entries of a member, restricted to an action
+// unless action is empty.
+func (member *MemberRepo) GetMemberAuditCount(ctx context.Context, memberID uuid.UUID, action string) (int64, error) {
+	var count int64
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, action})
+	err := member.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM member_audit
+		WHERE member_id = $1
+		AND ($2 = '' OR action = $2)`+scope, params...).Scan(&count)
+	return count, err
+}
+
+// GetMemberAudit returns a page of the audit entries of a member, newest first, restricted to an
+// action unless action is empty.
+func (member *MemberRepo) GetMemberAudit(ctx context.Context, memberID uuid.UUID, action string, page int32, limit int32) ([]entities.AuditEntry, error) {
+	scope, params := tenant.MemberCondition(ctx, "member_id", []any{memberID, action, limit, (page - 1) * limit})
+	rows, err := member.db.QueryContext(ctx, `
+		SELECT id, member_id, COALESCE(resource_id, ''), COALESCE(actor_id, ''), action, changes,
+			COALESCE(request_id, ''), created_on
+		FROM member_audit
+		WHERE member_id = $1
+		AND ($2 = '' OR action = $2)`+scope+`
+		ORDER BY created_on DESC, id
+		LIMIT $3 OFFSET $4
+	`, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []entities.AuditEntry{}
+	for rows.Next() {
+		var (
+			entry       entities.AuditEntry
+			changesJSON []byte
+		)
+		err := rows.Scan(&entry.ID, &entry.MemberID, &entry.ResourceID, &entry.ActorID, &entry.Action, &changesJSON,
+			&entry.RequestID, &entry.CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changesJSON, &entry.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,261 @@
+package repo_test
+
+import (
+	"database/sql"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"member/internal/consts"
+	"member/internal/entities"
+	"member/internal/repo"
+	"member/internal/tenant"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMemberRepo returns a member repository on a mocked database and the mock to set its expectations.
+func newMemberRepo(t *testing.T) (*repo.MemberRepo, sqlmock.Sqlmock) {
+	t.Helper()
+	return newMemberRepoWithConfig(t, &entities.EnvConfig{})
+}
+
+func newMemberRepoWithConfig(t *testing.T, cfg *entities.EnvConfig) (*repo.MemberRepo, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return repo.NewMemberRepo(db, cfg), mock
+}
+
+func newGinContext() *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("POST", "/", nil)
+	return ctx
+}
+
+func TestCheckoutRetryAfterFailedPayment(t *testing.T) {
+	memberRepo, mock := newMemberRepo(t)
+	ctx := newGinContext()
+	memberID := uuid.New()
+	planID := uuid.NewString()
+	failedSubscriptionID := uuid.NewString()
+	retrySubscriptionID := uuid.NewString()
+
+	// The checks before a checkout only see subscriptions whose payment did not fail.
+	expectCheckoutChecks := func() {
+		mock.ExpectQuery(`SELECT is_one_time_subscription`).WithArgs(planID).
+			WillReturnRows(sqlmock.NewRows([]string{"is_one_time_subscription"}).AddRow(true))
+		mock.ExpectQuery(`SELECT EXISTS \(\s*SELECT 1\s*FROM public.member_subscription AS ms`).
+			WithArgs(memberID, planID, consts.SubscriptionStatusPaymentFailed).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`SELECT COUNT\(\*\)\s*FROM public.member_subscription AS ms`).
+			WithArgs(memberID, planID, sqlmock.AnyArg(), sqlmock.AnyArg(), consts.SubscriptionStatusPaymentFailed).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(`SELECT subscription_limit_per_year`).WithArgs(planID).
+			WillReturnRows(sqlmock.NewRows([]string{"subscription_limit_per_year"}).AddRow(1))
+	}
+	expectCheckoutPayment := func(memberSubscriptionID string, paymentStatus string, subscriptionStatus string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO member_subscription_payment`).
+			WithArgs(memberSubscriptionID, memberID, 1, sqlmock.AnyArg(), consts.PaymentKindCheckout, 9.99, "USD",
+				paymentStatus, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(uuid.New()))
+		mock.ExpectQuery(`UPDATE member_subscription`).WithArgs(memberSubscriptionID, subscriptionStatus).
+			WillReturnRows(sqlmock.NewRows([]string{"member_id", "old_status"}).
+				AddRow(memberID, consts.SubscriptionStatusProcessing))
+		mock.ExpectExec(`INSERT INTO member_outbox`).
+			WithArgs(consts.DomainEventSubscriptionStatusChanged, sqlmock.AnyArg(), consts.AggregateMemberSubscription,
+				memberSubscriptionID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	checkout := func(memberSubscriptionID string, paymentStatus string) {
+		subscribed, err := memberRepo.HasSubscribedToOneTimePlan(ctx, memberID, planID)
+		require.NoError(t, err)
+		require.False(t, subscribed)
+		count, err := memberRepo.GetSubscriptionCountForLastYear(ctx, memberID, planID)
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		_, err = memberRepo.RecordCheckoutPayment(ctx, entities.SubscriptionPayment{
+			MemberSubscriptionID: memberSubscriptionID,
+			MemberID:             memberID,
+			PaymentGatewayID:     1,
+			GatewayPaymentID:     uuid.NewString(),
+			Kind:                 consts.PaymentKindCheckout,
+			Amount:               9.99,
+			Currency:             "USD",
+			Status:               paymentStatus,
+		})
+		require.NoError(t, err)
+	}
+
+	expectCheckoutChecks()
+	expectCheckoutPayment(failedSubscriptionID, consts.PaymentStatusFailed, consts.SubscriptionStatusPaymentFailed)
+	checkout(failedSubscriptionID, consts.PaymentStatusFailed)
+
+	expectCheckoutChecks()
+	expectCheckoutPayment(retrySubscriptionID, consts.PaymentStatusCaptured, consts.SubscriptionStatusActive)
+	checkout(retrySubscriptionID, consts.PaymentStatusCaptured)
+}
+
+func TestEraseMemberScrubsPublishedOutboxEvents(t *testing.T) {
+	memberRepo, mock := newMemberRepo(t)
+	request := entities.MemberErasureRequest{ID: uuid.New(), MemberID: uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT completed_on IS NULL FROM member_erasure_request`).WithArgs(request.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"pending"}).AddRow(true))
+	for _, statement := range []string{
+		`UPDATE member_import_row`,
+		`UPDATE member SET`,
+		`UPDATE member_billing_address`,
+		`UPDATE member_invoice`,
+		`UPDATE member_audit`,
+		`DELETE FROM refresh_token`,
+		`DELETE FROM password_reset_lockout`,
+		`DELETE FROM member_recovery_code`,
+		`DELETE FROM member_two_factor`,
+		// Delivered events, such as member.registered, lose the email as well as the pending ones.
+		`UPDATE member_outbox SET payload = payload - 'email' WHERE aggregate_id = \$1 AND payload \? 'email'$`,
+		`UPDATE member_erasure_request SET completed_on`,
+	} {
+		mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`INSERT INTO member_outbox`).
+		WithArgs(consts.DomainEventMemberErased, sqlmock.AnyArg(), consts.AggregateMember, request.MemberID.String(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	erased, err := memberRepo.EraseMember(newGinContext(), request)
+	require.NoError(t, err)
+	assert.True(t, erased)
+}
+
+func TestClaimResetKeyAttemptRefusedAtLimit(t *testing.T) {
+	memberRepo, mock := newMemberRepoWithConfig(t, &entities.EnvConfig{
+		PasswordReset: entities.PasswordResetConfig{MaxMemberAttempts: 5, MaxIPAttempts: 20, LockoutWindow: 15 * time.Minute},
+	})
+	memberID := uuid.New()
+	ipAddress := "203.0.113.7"
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO password_reset_lockout`).
+		WithArgs(consts.LockoutScopeIP, ipAddress, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`UPDATE password_reset_lockout\s+SET attempts = .+ AND \(\$5 <= 0 OR attempts < \$5 OR window_started_on <= \$4\)\s+RETURNING attempts`).
+		WithArgs(consts.LockoutScopeIP, ipAddress, sqlmock.AnyArg(), sqlmock.AnyArg(), 20).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO password_reset_lockout`).
+		WithArgs(consts.LockoutScopeMember, memberID.String(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// The member already used up its attempts, the guarded update leaves the row alone.
+	mock.ExpectQuery(`UPDATE password_reset_lockout`).
+		WithArgs(consts.LockoutScopeMember, memberID.String(), sqlmock.AnyArg(), sqlmock.AnyArg(), 5).
+		WillReturnRows(sqlmock.NewRows([]string{"attempts"}))
+	// What the client IP counted is given back with the refused check.
+	mock.ExpectRollback()
+
+	blocked, err := memberRepo.ClaimResetKeyAttempt(newGinContext(), memberID, ipAddress)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+}
+
+func TestGetSubscriptionPlanTermsInCurrency(t *testing.T) {
+	memberRepo, mock := newMemberRepo(t)
+	subscriptionID := uuid.NewString()
+	planID := uuid.New()
+	columns := []string{"plan_key", "name", "amount", "tax_percentage", "currency_id", "price_amount", "price_tax_percentage",
+		"price_currency_id", "code", "duration", "product_count", "track_count", "artist_count", "is_active"}
+
+	t.Run("price of the currency", func(t *testing.T) {
+		mock.ExpectQuery(`LEFT JOIN subscription_plan_price AS spp`).WithArgs(subscriptionID, "eur").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(planID, "Gold", 10.0, 10.0, 1, 9.0, 20.0, 2, "EUR", 52, 5, 50, 5, true))
+
+		terms, err := memberRepo.GetSubscriptionPlanTerms(newGinContext(), subscriptionID, "eur")
+		require.NoError(t, err)
+		assert.Equal(t, 9.0, terms.Amount)
+		assert.Equal(t, 20.0, terms.TaxPercentage)
+		assert.Equal(t, 2, terms.CurrencyID)
+		assert.Equal(t, "EUR", terms.Currency)
+	})
+
+	t.Run("no price in the currency", func(t *testing.T) {
+		mock.ExpectQuery(`LEFT JOIN subscription_plan_price AS spp`).WithArgs(subscriptionID, "GBP").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(planID, "Gold", 10.0, 10.0, 1, nil, nil, nil, "USD", 52, 5, 50, 5, true))
+
+		_, err := memberRepo.GetSubscriptionPlanTerms(newGinContext(), subscriptionID, "GBP")
+		assert.ErrorIs(t, err, consts.ErrPlanPriceNotFound)
+	})
+
+	t.Run("default price of a plan without price rows", func(t *testing.T) {
+		mock.ExpectQuery(`LEFT JOIN subscription_plan_price AS spp`).WithArgs(subscriptionID, "").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(planID, "Free", 0.0, 0.0, 0, nil, nil, nil, "", 52, 1, 10, 1, true))
+
+		terms, err := memberRepo.GetSubscriptionPlanTerms(newGinContext(), subscriptionID, "")
+		require.NoError(t, err)
+		assert.Zero(t, terms.Amount)
+		assert.Equal(t, "Free", terms.Name)
+	})
+}
+
+func TestMemberQueriesOfAnotherPartnerReturnNothing(t *testing.T) {
+	memberRepo, mock := newMemberRepo(t)
+	ctx := newGinContext()
+	partnerID := uuid.New()
+	tenant.Scope(ctx, partnerID)
+	// The member and its billing address belong to another partner than the request.
+	memberID := uuid.New()
+	billingAddressID := uuid.New()
+
+	t.Run("billing address by id", func(t *testing.T) {
+		mock.ExpectQuery(`FROM member_billing_address WHERE id = \$1 AND member_id IN \(SELECT id FROM member WHERE partner_id = \$2\)`).
+			WithArgs(billingAddressID, partnerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := memberRepo.GetBillingAddressByID(ctx, billingAddressID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("stores are not added", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM member WHERE id = \$1 AND partner_id = \$2`).WithArgs(memberID, partnerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := memberRepo.AddMemberStoresById(ctx, memberID, []uuid.UUID{uuid.New()})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("stores are not reordered", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM member WHERE id = \$1 AND partner_id = \$2`).WithArgs(memberID, partnerID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		err := memberRepo.ReorderMemberStores(ctx, memberID, []uuid.UUID{uuid.New()})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestGetPromoCodeWithinPartner(t *testing.T) {
+	memberRepo, mock := newMemberRepo(t)
+	partnerID := uuid.NewString()
+
+	// Codes of other partners are not looked up, the partner's own code wins over a platform code.
+	mock.ExpectQuery(`WHERE UPPER\(pc.code\) = UPPER\(\$1\)\s+AND \(pc.owner_partner_id IS NULL OR pc.owner_partner_id = NULLIF\(\$2, ''\)::uuid\)`+
+		`\s+GROUP BY pc.id\s+ORDER BY pc.owner_partner_id NULLS LAST`).
+		WithArgs("SUMMER24", partnerID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := memberRepo.GetPromoCode(newGinContext(), partnerID, "SUMMER24")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -66,19 +66,64 @@ func (mr *MockMemberRepoImplyMockRecorder) AddMemberStoresById(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMemberStoresById", reflect.TypeOf((*MockMemberRepoImply)(nil).AddMemberStoresById), arg0, arg1, arg2)
 }
 
+// AddSubscriptionPlanVersion mocks base method.
+func (m *MockMemberRepoImply) AddSubscriptionPlanVersion(arg0 context.Context, arg1 uuid.UUID, arg2 entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubscriptionPlanVersion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.SubscriptionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSubscriptionPlanVersion indicates an expected call of AddSubscriptionPlanVersion.
+func (mr *MockMemberRepoImplyMockRecorder) AddSubscriptionPlanVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubscriptionPlanVersion", reflect.TypeOf((*MockMemberRepoImply)(nil).AddSubscriptionPlanVersion), arg0, arg1, arg2)
+}
+
+// ApplyPaymentWebhookEvent mocks base method.
+func (m *MockMemberRepoImply) ApplyPaymentWebhookEvent(arg0 context.Context, arg1, arg2 string, arg3 entities.PaymentWebhookEvent, arg4 []byte, arg5 entities.SubscriptionPayment, arg6 entities.PaymentStateChange) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyPaymentWebhookEvent", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyPaymentWebhookEvent indicates an expected call of ApplyPaymentWebhookEvent.
+func (mr *MockMemberRepoImplyMockRecorder) ApplyPaymentWebhookEvent(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyPaymentWebhookEvent", reflect.TypeOf((*MockMemberRepoImply)(nil).ApplyPaymentWebhookEvent), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 // BillingAddressExists mocks base method.
-func (m *MockMemberRepoImply) BillingAddressExists(arg0 *gin.Context, arg1 uuid.UUID, arg2 entities.BillingAddress) (bool, error) {
+func (m *MockMemberRepoImply) BillingAddressExists(arg0 *gin.Context, arg1 uuid.UUID, arg2 entities.BillingAddress, arg3 uuid.UUID) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BillingAddressExists", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "BillingAddressExists", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BillingAddressExists indicates an expected call of BillingAddressExists.
-func (mr *MockMemberRepoImplyMockRecorder) BillingAddressExists(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockMemberRepoImplyMockRecorder) BillingAddressExists(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BillingAddressExists", reflect.TypeOf((*MockMemberRepoImply)(nil).BillingAddressExists), arg0, arg1, arg2, arg3)
+}
+
+// ChangeSubscriptionPlan mocks base method.
+func (m *MockMemberRepoImply) ChangeSubscriptionPlan(arg0 context.Context, arg1 entities.SubscriptionLedgerEntry) (entities.SubscriptionLedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeSubscriptionPlan", arg0, arg1)
+	ret0, _ := ret[0].(entities.SubscriptionLedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeSubscriptionPlan indicates an expected call of ChangeSubscriptionPlan.
+func (mr *MockMemberRepoImplyMockRecorder) ChangeSubscriptionPlan(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BillingAddressExists", reflect.TypeOf((*MockMemberRepoImply)(nil).BillingAddressExists), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeSubscriptionPlan", reflect.TypeOf((*MockMemberRepoImply)(nil).ChangeSubscriptionPlan), arg0, arg1)
 }
 
 // CheckBillingAddressRelation mocks base method.
@@ -216,6 +261,21 @@ func (mr *MockMemberRepoImplyMockRecorder) CheckMemberPartner(arg0, arg1, arg2 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMemberPartner", reflect.TypeOf((*MockMemberRepoImply)(nil).CheckMemberPartner), arg0, arg1, arg2)
 }
 
+// CheckMemberSubscriptionExists mocks base method.
+func (m *MockMemberRepoImply) CheckMemberSubscriptionExists(arg0 *gin.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckMemberSubscriptionExists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckMemberSubscriptionExists indicates an expected call of CheckMemberSubscriptionExists.
+func (mr *MockMemberRepoImplyMockRecorder) CheckMemberSubscriptionExists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckMemberSubscriptionExists", reflect.TypeOf((*MockMemberRepoImply)(nil).CheckMemberSubscriptionExists), arg0, arg1)
+}
+
 // CheckNonExistingMemberStores mocks base method.
 func (m *MockMemberRepoImply) CheckNonExistingMemberStores(arg0 *gin.Context, arg1 uuid.UUID, arg2 []uuid.UUID) ([]uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -308,20 +368,122 @@ func (mr *MockMemberRepoImplyMockRecorder) CheckSubscriptionExistenceAndStatusFo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSubscriptionExistenceAndStatusForCheckout", reflect.TypeOf((*MockMemberRepoImply)(nil).CheckSubscriptionExistenceAndStatusForCheckout), arg0, arg1)
 }
 
-// CheckSubscriptionExistenceAndStatusForRenewal mocks base method.
-func (m *MockMemberRepoImply) CheckSubscriptionExistenceAndStatusForRenewal(arg0 *gin.Context, arg1 string) (bool, bool, error) {
+// ClaimDueRenewals mocks base method.
+func (m *MockMemberRepoImply) ClaimDueRenewals(arg0 context.Context, arg1 int, arg2 time.Duration) ([]entities.RenewalCandidate, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckSubscriptionExistenceAndStatusForRenewal", arg0, arg1)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "ClaimDueRenewals", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entities.RenewalCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueRenewals indicates an expected call of ClaimDueRenewals.
+func (mr *MockMemberRepoImplyMockRecorder) ClaimDueRenewals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueRenewals", reflect.TypeOf((*MockMemberRepoImply)(nil).ClaimDueRenewals), arg0, arg1, arg2)
+}
+
+// ClaimLoginAttempt mocks base method.
+func (m *MockMemberRepoImply) ClaimLoginAttempt(arg0 context.Context, arg1 uuid.UUID, arg2 string) (time.Time, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimLoginAttempt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(time.Time)
 	ret1, _ := ret[1].(bool)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// CheckSubscriptionExistenceAndStatusForRenewal indicates an expected call of CheckSubscriptionExistenceAndStatusForRenewal.
-func (mr *MockMemberRepoImplyMockRecorder) CheckSubscriptionExistenceAndStatusForRenewal(arg0, arg1 interface{}) *gomock.Call {
+// ClaimLoginAttempt indicates an expected call of ClaimLoginAttempt.
+func (mr *MockMemberRepoImplyMockRecorder) ClaimLoginAttempt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimLoginAttempt", reflect.TypeOf((*MockMemberRepoImply)(nil).ClaimLoginAttempt), arg0, arg1, arg2)
+}
+
+// ClaimMemberImports mocks base method.
+func (m *MockMemberRepoImply) ClaimMemberImports(arg0 context.Context, arg1 int, arg2 time.Duration) ([]entities.MemberImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMemberImports", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entities.MemberImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimMemberImports indicates an expected call of ClaimMemberImports.
+func (mr *MockMemberRepoImplyMockRecorder) ClaimMemberImports(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMemberImports", reflect.TypeOf((*MockMemberRepoImply)(nil).ClaimMemberImports), arg0, arg1, arg2)
+}
+
+// ClaimResetKeyAttempt mocks base method.
+func (m *MockMemberRepoImply) ClaimResetKeyAttempt(arg0 context.Context, arg1 uuid.UUID, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimResetKeyAttempt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimResetKeyAttempt indicates an expected call of ClaimResetKeyAttempt.
+func (mr *MockMemberRepoImplyMockRecorder) ClaimResetKeyAttempt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimResetKeyAttempt", reflect.TypeOf((*MockMemberRepoImply)(nil).ClaimResetKeyAttempt), arg0, arg1, arg2)
+}
+
+// ClearLoginLockout mocks base method.
+func (m *MockMemberRepoImply) ClearLoginLockout(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearLoginLockout", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearLoginLockout indicates an expected call of ClearLoginLockout.
+func (mr *MockMemberRepoImplyMockRecorder) ClearLoginLockout(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginLockout", reflect.TypeOf((*MockMemberRepoImply)(nil).ClearLoginLockout), arg0, arg1)
+}
+
+// CompleteMemberImport mocks base method.
+func (m *MockMemberRepoImply) CompleteMemberImport(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteMemberImport", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteMemberImport indicates an expected call of CompleteMemberImport.
+func (mr *MockMemberRepoImplyMockRecorder) CompleteMemberImport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteMemberImport", reflect.TypeOf((*MockMemberRepoImply)(nil).CompleteMemberImport), arg0, arg1)
+}
+
+// CompleteMemberImportRow mocks base method.
+func (m *MockMemberRepoImply) CompleteMemberImportRow(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 map[string][]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteMemberImportRow", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteMemberImportRow indicates an expected call of CompleteMemberImportRow.
+func (mr *MockMemberRepoImplyMockRecorder) CompleteMemberImportRow(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteMemberImportRow", reflect.TypeOf((*MockMemberRepoImply)(nil).CompleteMemberImportRow), arg0, arg1, arg2, arg3)
+}
+
+// CountMembers mocks base method.
+func (m *MockMemberRepoImply) CountMembers(arg0 context.Context, arg1 entities.MemberListFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountMembers", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountMembers indicates an expected call of CountMembers.
+func (mr *MockMemberRepoImplyMockRecorder) CountMembers(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSubscriptionExistenceAndStatusForRenewal", reflect.TypeOf((*MockMemberRepoImply)(nil).CheckSubscriptionExistenceAndStatusForRenewal), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountMembers", reflect.TypeOf((*MockMemberRepoImply)(nil).CountMembers), arg0, arg1)
 }
 
 // CountPrimaryBillingAddresses mocks base method.
@@ -369,8 +531,68 @@ func (mr *MockMemberRepoImplyMockRecorder) CountryExists(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountryExists", reflect.TypeOf((*MockMemberRepoImply)(nil).CountryExists), arg0)
 }
 
+// CreateInvoice mocks base method.
+func (m *MockMemberRepoImply) CreateInvoice(arg0 context.Context, arg1 entities.Invoice) (entities.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvoice", arg0, arg1)
+	ret0, _ := ret[0].(entities.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvoice indicates an expected call of CreateInvoice.
+func (mr *MockMemberRepoImplyMockRecorder) CreateInvoice(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockMemberRepoImply)(nil).CreateInvoice), arg0, arg1)
+}
+
+// CreateMemberImport mocks base method.
+func (m *MockMemberRepoImply) CreateMemberImport(arg0 context.Context, arg1 entities.MemberImport, arg2 []entities.MemberImportRow) (entities.MemberImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMemberImport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.MemberImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMemberImport indicates an expected call of CreateMemberImport.
+func (mr *MockMemberRepoImplyMockRecorder) CreateMemberImport(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMemberImport", reflect.TypeOf((*MockMemberRepoImply)(nil).CreateMemberImport), arg0, arg1, arg2)
+}
+
+// CreatePromoCode mocks base method.
+func (m *MockMemberRepoImply) CreatePromoCode(arg0 context.Context, arg1 entities.PromoCodeRequest) (entities.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromoCode", arg0, arg1)
+	ret0, _ := ret[0].(entities.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromoCode indicates an expected call of CreatePromoCode.
+func (mr *MockMemberRepoImplyMockRecorder) CreatePromoCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockMemberRepoImply)(nil).CreatePromoCode), arg0, arg1)
+}
+
+// CreateSubscriptionPlan mocks base method.
+func (m *MockMemberRepoImply) CreateSubscriptionPlan(arg0 context.Context, arg1 entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscriptionPlan", arg0, arg1)
+	ret0, _ := ret[0].(entities.SubscriptionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscriptionPlan indicates an expected call of CreateSubscriptionPlan.
+func (mr *MockMemberRepoImplyMockRecorder) CreateSubscriptionPlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscriptionPlan", reflect.TypeOf((*MockMemberRepoImply)(nil).CreateSubscriptionPlan), arg0, arg1)
+}
+
 // DecryptPaymentData mocks base method.
-func (m *MockMemberRepoImply) DecryptPaymentData(arg0 *gin.Context, arg1 string) (string, error) {
+func (m *MockMemberRepoImply) DecryptPaymentData(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DecryptPaymentData", arg0, arg1)
 	ret0, _ := ret[0].(string)
@@ -385,31 +607,104 @@ func (mr *MockMemberRepoImplyMockRecorder) DecryptPaymentData(arg0, arg1 interfa
 }
 
 // DeleteBillingAddress mocks base method.
-func (m *MockMemberRepoImply) DeleteBillingAddress(arg0 *gin.Context, arg1, arg2 uuid.UUID) error {
+func (m *MockMemberRepoImply) DeleteBillingAddress(arg0 *gin.Context, arg1, arg2 uuid.UUID, arg3 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBillingAddress", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "DeleteBillingAddress", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteBillingAddress indicates an expected call of DeleteBillingAddress.
-func (mr *MockMemberRepoImplyMockRecorder) DeleteBillingAddress(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockMemberRepoImplyMockRecorder) DeleteBillingAddress(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBillingAddress", reflect.TypeOf((*MockMemberRepoImply)(nil).DeleteBillingAddress), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBillingAddress", reflect.TypeOf((*MockMemberRepoImply)(nil).DeleteBillingAddress), arg0, arg1, arg2, arg3)
 }
 
 // DeleteMember mocks base method.
-func (m *MockMemberRepoImply) DeleteMember(arg0 *gin.Context, arg1 uuid.UUID) error {
+func (m *MockMemberRepoImply) DeleteMember(arg0 *gin.Context, arg1 uuid.UUID, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteMember", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteMember", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteMember indicates an expected call of DeleteMember.
-func (mr *MockMemberRepoImplyMockRecorder) DeleteMember(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockMemberRepoImplyMockRecorder) DeleteMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockMemberRepoImply)(nil).DeleteMember), arg0, arg1, arg2)
+}
+
+// DisableTwoFactor mocks base method.
+func (m *MockMemberRepoImply) DisableTwoFactor(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTwoFactor indicates an expected call of DisableTwoFactor.
+func (mr *MockMemberRepoImplyMockRecorder) DisableTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTwoFactor", reflect.TypeOf((*MockMemberRepoImply)(nil).DisableTwoFactor), arg0, arg1)
+}
+
+// EnableTwoFactor mocks base method.
+func (m *MockMemberRepoImply) EnableTwoFactor(arg0 context.Context, arg1 uuid.UUID, arg2 int64, arg3 []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTwoFactor", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTwoFactor indicates an expected call of EnableTwoFactor.
+func (mr *MockMemberRepoImplyMockRecorder) EnableTwoFactor(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTwoFactor", reflect.TypeOf((*MockMemberRepoImply)(nil).EnableTwoFactor), arg0, arg1, arg2, arg3)
+}
+
+// EraseMember mocks base method.
+func (m *MockMemberRepoImply) EraseMember(arg0 context.Context, arg1 entities.MemberErasureRequest) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseMember", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EraseMember indicates an expected call of EraseMember.
+func (mr *MockMemberRepoImplyMockRecorder) EraseMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseMember", reflect.TypeOf((*MockMemberRepoImply)(nil).EraseMember), arg0, arg1)
+}
+
+// ExpireQuotaReservations mocks base method.
+func (m *MockMemberRepoImply) ExpireQuotaReservations(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireQuotaReservations", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireQuotaReservations indicates an expected call of ExpireQuotaReservations.
+func (mr *MockMemberRepoImplyMockRecorder) ExpireQuotaReservations(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockMemberRepoImply)(nil).DeleteMember), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireQuotaReservations", reflect.TypeOf((*MockMemberRepoImply)(nil).ExpireQuotaReservations), arg0)
+}
+
+// FinishQuotaReservation mocks base method.
+func (m *MockMemberRepoImply) FinishQuotaReservation(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 uuid.UUID, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishQuotaReservation", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishQuotaReservation indicates an expected call of FinishQuotaReservation.
+func (mr *MockMemberRepoImplyMockRecorder) FinishQuotaReservation(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishQuotaReservation", reflect.TypeOf((*MockMemberRepoImply)(nil).FinishQuotaReservation), arg0, arg1, arg2, arg3, arg4)
 }
 
 // GetAllBillingAddresses mocks base method.
@@ -442,6 +737,21 @@ func (mr *MockMemberRepoImplyMockRecorder) GetBasicMemberDetailsByEmail(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBasicMemberDetailsByEmail", reflect.TypeOf((*MockMemberRepoImply)(nil).GetBasicMemberDetailsByEmail), arg0, arg1, arg2)
 }
 
+// GetBasicMemberDetailsByID mocks base method.
+func (m *MockMemberRepoImply) GetBasicMemberDetailsByID(arg0 context.Context, arg1 uuid.UUID) (entities.BasicMemberData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBasicMemberDetailsByID", arg0, arg1)
+	ret0, _ := ret[0].(entities.BasicMemberData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBasicMemberDetailsByID indicates an expected call of GetBasicMemberDetailsByID.
+func (mr *MockMemberRepoImplyMockRecorder) GetBasicMemberDetailsByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBasicMemberDetailsByID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetBasicMemberDetailsByID), arg0, arg1)
+}
+
 // GetBillingAddressByID mocks base method.
 func (m *MockMemberRepoImply) GetBillingAddressByID(arg0 context.Context, arg1 uuid.UUID) (*entities.BillingAddress, error) {
 	m.ctrl.T.Helper()
@@ -472,6 +782,51 @@ func (mr *MockMemberRepoImplyMockRecorder) GetBillingAddressCountForMember(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillingAddressCountForMember", reflect.TypeOf((*MockMemberRepoImply)(nil).GetBillingAddressCountForMember), arg0, arg1)
 }
 
+// GetEmailVerification mocks base method.
+func (m *MockMemberRepoImply) GetEmailVerification(arg0 context.Context, arg1 uuid.UUID) (entities.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerification", arg0, arg1)
+	ret0, _ := ret[0].(entities.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerification indicates an expected call of GetEmailVerification.
+func (mr *MockMemberRepoImplyMockRecorder) GetEmailVerification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerification", reflect.TypeOf((*MockMemberRepoImply)(nil).GetEmailVerification), arg0, arg1)
+}
+
+// GetEmailVerificationByEmail mocks base method.
+func (m *MockMemberRepoImply) GetEmailVerificationByEmail(arg0 context.Context, arg1, arg2 string) (entities.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationByEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationByEmail indicates an expected call of GetEmailVerificationByEmail.
+func (mr *MockMemberRepoImplyMockRecorder) GetEmailVerificationByEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationByEmail", reflect.TypeOf((*MockMemberRepoImply)(nil).GetEmailVerificationByEmail), arg0, arg1, arg2)
+}
+
+// GetEmailVerificationPolicy mocks base method.
+func (m *MockMemberRepoImply) GetEmailVerificationPolicy(arg0 context.Context, arg1 uuid.UUID) (entities.EmailVerificationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailVerificationPolicy", arg0, arg1)
+	ret0, _ := ret[0].(entities.EmailVerificationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailVerificationPolicy indicates an expected call of GetEmailVerificationPolicy.
+func (mr *MockMemberRepoImplyMockRecorder) GetEmailVerificationPolicy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailVerificationPolicy", reflect.TypeOf((*MockMemberRepoImply)(nil).GetEmailVerificationPolicy), arg0, arg1)
+}
+
 // GetFilteredRecordCount mocks base method.
 func (m *MockMemberRepoImply) GetFilteredRecordCount(arg0 context.Context, arg1 entities.Params) (int64, error) {
 	m.ctrl.T.Helper()
@@ -487,6 +842,37 @@ func (mr *MockMemberRepoImplyMockRecorder) GetFilteredRecordCount(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilteredRecordCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetFilteredRecordCount), arg0, arg1)
 }
 
+// GetLatestCapturedPayment mocks base method.
+func (m *MockMemberRepoImply) GetLatestCapturedPayment(arg0 context.Context, arg1 string) (entities.SubscriptionPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestCapturedPayment", arg0, arg1)
+	ret0, _ := ret[0].(entities.SubscriptionPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestCapturedPayment indicates an expected call of GetLatestCapturedPayment.
+func (mr *MockMemberRepoImplyMockRecorder) GetLatestCapturedPayment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestCapturedPayment", reflect.TypeOf((*MockMemberRepoImply)(nil).GetLatestCapturedPayment), arg0, arg1)
+}
+
+// GetLoginLockouts mocks base method.
+func (m *MockMemberRepoImply) GetLoginLockouts(arg0 context.Context, arg1 uuid.UUID, arg2 string) (entities.LoginLockout, entities.LoginLockout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginLockouts", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.LoginLockout)
+	ret1, _ := ret[1].(entities.LoginLockout)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLoginLockouts indicates an expected call of GetLoginLockouts.
+func (mr *MockMemberRepoImplyMockRecorder) GetLoginLockouts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLockouts", reflect.TypeOf((*MockMemberRepoImply)(nil).GetLoginLockouts), arg0, arg1, arg2)
+}
+
 // GetMaxSubscriptionLimitForID mocks base method.
 func (m *MockMemberRepoImply) GetMaxSubscriptionLimitForID(arg0 *gin.Context, arg1 string) (int, error) {
 	m.ctrl.T.Helper()
@@ -502,81 +888,381 @@ func (mr *MockMemberRepoImplyMockRecorder) GetMaxSubscriptionLimitForID(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxSubscriptionLimitForID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMaxSubscriptionLimitForID), arg0, arg1)
 }
 
-// GetMemberByID mocks base method.
-func (m *MockMemberRepoImply) GetMemberByID(arg0 context.Context, arg1 uuid.UUID) (entities.MemberByID, error) {
+// GetMemberAudit mocks base method.
+func (m *MockMemberRepoImply) GetMemberAudit(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3, arg4 int32) ([]entities.AuditEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMemberByID", arg0, arg1)
-	ret0, _ := ret[0].(entities.MemberByID)
+	ret := m.ctrl.Call(m, "GetMemberAudit", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]entities.AuditEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetMemberByID indicates an expected call of GetMemberByID.
-func (mr *MockMemberRepoImplyMockRecorder) GetMemberByID(arg0, arg1 interface{}) *gomock.Call {
+// GetMemberAudit indicates an expected call of GetMemberAudit.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberAudit(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberByID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberAudit", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberAudit), arg0, arg1, arg2, arg3, arg4)
 }
 
-// GetMemberRecordCount mocks base method.
-func (m *MockMemberRepoImply) GetMemberRecordCount(arg0 context.Context) (int64, error) {
+// GetMemberAuditCount mocks base method.
+func (m *MockMemberRepoImply) GetMemberAuditCount(arg0 context.Context, arg1 uuid.UUID, arg2 string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMemberRecordCount", arg0)
+	ret := m.ctrl.Call(m, "GetMemberAuditCount", arg0, arg1, arg2)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetMemberRecordCount indicates an expected call of GetMemberRecordCount.
-func (mr *MockMemberRepoImplyMockRecorder) GetMemberRecordCount(arg0 interface{}) *gomock.Call {
+// GetMemberAuditCount indicates an expected call of GetMemberAuditCount.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberAuditCount(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberRecordCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberRecordCount), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberAuditCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberAuditCount), arg0, arg1, arg2)
 }
 
-// GetPartnerIDByMemberID mocks base method.
-func (m *MockMemberRepoImply) GetPartnerIDByMemberID(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+// GetMemberBillingProfile mocks base method.
+func (m *MockMemberRepoImply) GetMemberBillingProfile(arg0 context.Context, arg1 uuid.UUID) (entities.BillingProfile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPartnerIDByMemberID", arg0, arg1)
-	ret0, _ := ret[0].(uuid.UUID)
+	ret := m.ctrl.Call(m, "GetMemberBillingProfile", arg0, arg1)
+	ret0, _ := ret[0].(entities.BillingProfile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPartnerIDByMemberID indicates an expected call of GetPartnerIDByMemberID.
-func (mr *MockMemberRepoImplyMockRecorder) GetPartnerIDByMemberID(arg0, arg1 interface{}) *gomock.Call {
+// GetMemberBillingProfile indicates an expected call of GetMemberBillingProfile.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberBillingProfile(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartnerIDByMemberID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPartnerIDByMemberID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberBillingProfile", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberBillingProfile), arg0, arg1)
 }
 
-// GetPasswordHash mocks base method.
-func (m *MockMemberRepoImply) GetPasswordHash(arg0 context.Context, arg1 uuid.UUID) (string, error) {
+// GetMemberByID mocks base method.
+func (m *MockMemberRepoImply) GetMemberByID(arg0 context.Context, arg1 uuid.UUID) (entities.MemberByID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPasswordHash", arg0, arg1)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetMemberByID", arg0, arg1)
+	ret0, _ := ret[0].(entities.MemberByID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPasswordHash indicates an expected call of GetPasswordHash.
-func (mr *MockMemberRepoImplyMockRecorder) GetPasswordHash(arg0, arg1 interface{}) *gomock.Call {
+// GetMemberByID indicates an expected call of GetMemberByID.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberByID(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHash", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPasswordHash), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberByID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberByID), arg0, arg1)
 }
 
-// GetPaymentDetailsByPartnerAndGateway mocks base method.
-func (m *MockMemberRepoImply) GetPaymentDetailsByPartnerAndGateway(arg0 context.Context, arg1 string, arg2 int) (string, error) {
+// GetMemberContact mocks base method.
+func (m *MockMemberRepoImply) GetMemberContact(arg0 context.Context, arg1 uuid.UUID) (entities.MemberContact, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPaymentDetailsByPartnerAndGateway", arg0, arg1, arg2)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetMemberContact", arg0, arg1)
+	ret0, _ := ret[0].(entities.MemberContact)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPaymentDetailsByPartnerAndGateway indicates an expected call of GetPaymentDetailsByPartnerAndGateway.
+// GetMemberContact indicates an expected call of GetMemberContact.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberContact(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberContact", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberContact), arg0, arg1)
+}
+
+// GetMemberExport mocks base method.
+func (m *MockMemberRepoImply) GetMemberExport(arg0 context.Context, arg1 uuid.UUID) (entities.MemberExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberExport", arg0, arg1)
+	ret0, _ := ret[0].(entities.MemberExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberExport indicates an expected call of GetMemberExport.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberExport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberExport", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberExport), arg0, arg1)
+}
+
+// GetMemberIDByEmail mocks base method.
+func (m *MockMemberRepoImply) GetMemberIDByEmail(arg0 context.Context, arg1, arg2 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberIDByEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberIDByEmail indicates an expected call of GetMemberIDByEmail.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberIDByEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberIDByEmail", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberIDByEmail), arg0, arg1, arg2)
+}
+
+// GetMemberImport mocks base method.
+func (m *MockMemberRepoImply) GetMemberImport(arg0 context.Context, arg1 uuid.UUID) (entities.MemberImport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberImport", arg0, arg1)
+	ret0, _ := ret[0].(entities.MemberImport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberImport indicates an expected call of GetMemberImport.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberImport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberImport", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberImport), arg0, arg1)
+}
+
+// GetMemberInvoice mocks base method.
+func (m *MockMemberRepoImply) GetMemberInvoice(arg0 context.Context, arg1, arg2 uuid.UUID) (entities.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberInvoice", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberInvoice indicates an expected call of GetMemberInvoice.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberInvoice(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberInvoice", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberInvoice), arg0, arg1, arg2)
+}
+
+// GetMemberInvoiceCount mocks base method.
+func (m *MockMemberRepoImply) GetMemberInvoiceCount(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberInvoiceCount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberInvoiceCount indicates an expected call of GetMemberInvoiceCount.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberInvoiceCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberInvoiceCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberInvoiceCount), arg0, arg1)
+}
+
+// GetMemberInvoices mocks base method.
+func (m *MockMemberRepoImply) GetMemberInvoices(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]entities.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberInvoices", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]entities.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberInvoices indicates an expected call of GetMemberInvoices.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberInvoices(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberInvoices", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberInvoices), arg0, arg1, arg2, arg3)
+}
+
+// GetMemberRecordCount mocks base method.
+func (m *MockMemberRepoImply) GetMemberRecordCount(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberRecordCount", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberRecordCount indicates an expected call of GetMemberRecordCount.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberRecordCount(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberRecordCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberRecordCount), arg0)
+}
+
+// GetMemberStores mocks base method.
+func (m *MockMemberRepoImply) GetMemberStores(arg0 context.Context, arg1 uuid.UUID) ([]entities.MemberStore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberStores", arg0, arg1)
+	ret0, _ := ret[0].([]entities.MemberStore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberStores indicates an expected call of GetMemberStores.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberStores(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberStores", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberStores), arg0, arg1)
+}
+
+// GetMemberSubscriptionState mocks base method.
+func (m *MockMemberRepoImply) GetMemberSubscriptionState(arg0 context.Context, arg1 uuid.UUID, arg2 string) (entities.MemberSubscriptionState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberSubscriptionState", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.MemberSubscriptionState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberSubscriptionState indicates an expected call of GetMemberSubscriptionState.
+func (mr *MockMemberRepoImplyMockRecorder) GetMemberSubscriptionState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberSubscriptionState", reflect.TypeOf((*MockMemberRepoImply)(nil).GetMemberSubscriptionState), arg0, arg1, arg2)
+}
+
+// GetPartnerIDByMemberID mocks base method.
+func (m *MockMemberRepoImply) GetPartnerIDByMemberID(arg0 context.Context, arg1 uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartnerIDByMemberID", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPartnerIDByMemberID indicates an expected call of GetPartnerIDByMemberID.
+func (mr *MockMemberRepoImplyMockRecorder) GetPartnerIDByMemberID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartnerIDByMemberID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPartnerIDByMemberID), arg0, arg1)
+}
+
+// GetPartnerStores mocks base method.
+func (m *MockMemberRepoImply) GetPartnerStores(arg0 context.Context, arg1 uuid.UUID) ([]entities.PartnerStore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartnerStores", arg0, arg1)
+	ret0, _ := ret[0].([]entities.PartnerStore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPartnerStores indicates an expected call of GetPartnerStores.
+func (mr *MockMemberRepoImplyMockRecorder) GetPartnerStores(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartnerStores", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPartnerStores), arg0, arg1)
+}
+
+// GetPasswordHash mocks base method.
+func (m *MockMemberRepoImply) GetPasswordHash(arg0 context.Context, arg1 uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHash", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHash indicates an expected call of GetPasswordHash.
+func (mr *MockMemberRepoImplyMockRecorder) GetPasswordHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHash", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPasswordHash), arg0, arg1)
+}
+
+// GetPaymentDetailsByPartner mocks base method.
+func (m *MockMemberRepoImply) GetPaymentDetailsByPartner(arg0 context.Context, arg1 string) (map[int]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentDetailsByPartner", arg0, arg1)
+	ret0, _ := ret[0].(map[int]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentDetailsByPartner indicates an expected call of GetPaymentDetailsByPartner.
+func (mr *MockMemberRepoImplyMockRecorder) GetPaymentDetailsByPartner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentDetailsByPartner", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPaymentDetailsByPartner), arg0, arg1)
+}
+
+// GetPaymentDetailsByPartnerAndGateway mocks base method.
+func (m *MockMemberRepoImply) GetPaymentDetailsByPartnerAndGateway(arg0 context.Context, arg1 string, arg2 int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentDetailsByPartnerAndGateway", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentDetailsByPartnerAndGateway indicates an expected call of GetPaymentDetailsByPartnerAndGateway.
 func (mr *MockMemberRepoImplyMockRecorder) GetPaymentDetailsByPartnerAndGateway(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentDetailsByPartnerAndGateway", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPaymentDetailsByPartnerAndGateway), arg0, arg1, arg2)
 }
 
+// GetPendingMemberErasures mocks base method.
+func (m *MockMemberRepoImply) GetPendingMemberErasures(arg0 context.Context, arg1 int) ([]entities.MemberErasureRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingMemberErasures", arg0, arg1)
+	ret0, _ := ret[0].([]entities.MemberErasureRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingMemberErasures indicates an expected call of GetPendingMemberErasures.
+func (mr *MockMemberRepoImplyMockRecorder) GetPendingMemberErasures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMemberErasures", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPendingMemberErasures), arg0, arg1)
+}
+
+// GetPendingMemberImportRows mocks base method.
+func (m *MockMemberRepoImply) GetPendingMemberImportRows(arg0 context.Context, arg1 uuid.UUID) ([]entities.MemberImportRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingMemberImportRows", arg0, arg1)
+	ret0, _ := ret[0].([]entities.MemberImportRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingMemberImportRows indicates an expected call of GetPendingMemberImportRows.
+func (mr *MockMemberRepoImplyMockRecorder) GetPendingMemberImportRows(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMemberImportRows", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPendingMemberImportRows), arg0, arg1)
+}
+
+// GetPromoCode mocks base method.
+func (m *MockMemberRepoImply) GetPromoCode(arg0 context.Context, arg1, arg2 string) (entities.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoCode indicates an expected call of GetPromoCode.
+func (mr *MockMemberRepoImplyMockRecorder) GetPromoCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCode", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPromoCode), arg0, arg1, arg2)
+}
+
+// GetPromoCodeRedemptionCount mocks base method.
+func (m *MockMemberRepoImply) GetPromoCodeRedemptionCount(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCodeRedemptionCount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoCodeRedemptionCount indicates an expected call of GetPromoCodeRedemptionCount.
+func (mr *MockMemberRepoImplyMockRecorder) GetPromoCodeRedemptionCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCodeRedemptionCount", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPromoCodeRedemptionCount), arg0, arg1)
+}
+
+// GetPromoCodeRedemptions mocks base method.
+func (m *MockMemberRepoImply) GetPromoCodeRedemptions(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int32) ([]entities.PromoRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromoCodeRedemptions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]entities.PromoRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromoCodeRedemptions indicates an expected call of GetPromoCodeRedemptions.
+func (mr *MockMemberRepoImplyMockRecorder) GetPromoCodeRedemptions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromoCodeRedemptions", reflect.TypeOf((*MockMemberRepoImply)(nil).GetPromoCodeRedemptions), arg0, arg1, arg2, arg3)
+}
+
+// GetRenewalAttempts mocks base method.
+func (m *MockMemberRepoImply) GetRenewalAttempts(arg0 context.Context, arg1 uuid.UUID, arg2 string) ([]entities.RenewalAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRenewalAttempts", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]entities.RenewalAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRenewalAttempts indicates an expected call of GetRenewalAttempts.
+func (mr *MockMemberRepoImplyMockRecorder) GetRenewalAttempts(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRenewalAttempts", reflect.TypeOf((*MockMemberRepoImply)(nil).GetRenewalAttempts), arg0, arg1, arg2)
+}
+
 // GetResetKey mocks base method.
 func (m *MockMemberRepoImply) GetResetKey(arg0 context.Context, arg1 uuid.UUID) string {
 	m.ctrl.T.Helper()
@@ -621,6 +1307,21 @@ func (mr *MockMemberRepoImplyMockRecorder) GetStoreIDsByPartnerID(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStoreIDsByPartnerID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetStoreIDsByPartnerID), arg0, arg1)
 }
 
+// GetSubscriptionBalance mocks base method.
+func (m *MockMemberRepoImply) GetSubscriptionBalance(arg0 context.Context, arg1 string) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionBalance", arg0, arg1)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionBalance indicates an expected call of GetSubscriptionBalance.
+func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionBalance", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionBalance), arg0, arg1)
+}
+
 // GetSubscriptionCountForLastYear mocks base method.
 func (m *MockMemberRepoImply) GetSubscriptionCountForLastYear(arg0 *gin.Context, arg1 uuid.UUID, arg2 string) (int, error) {
 	m.ctrl.T.Helper()
@@ -651,6 +1352,66 @@ func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionIDByMemberSubscription
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionIDByMemberSubscriptionID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionIDByMemberSubscriptionID), arg0, arg1)
 }
 
+// GetSubscriptionPaymentByGatewayPaymentID mocks base method.
+func (m *MockMemberRepoImply) GetSubscriptionPaymentByGatewayPaymentID(arg0 context.Context, arg1 string) (entities.SubscriptionPayment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionPaymentByGatewayPaymentID", arg0, arg1)
+	ret0, _ := ret[0].(entities.SubscriptionPayment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionPaymentByGatewayPaymentID indicates an expected call of GetSubscriptionPaymentByGatewayPaymentID.
+func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionPaymentByGatewayPaymentID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionPaymentByGatewayPaymentID", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionPaymentByGatewayPaymentID), arg0, arg1)
+}
+
+// GetSubscriptionPlanTerms mocks base method.
+func (m *MockMemberRepoImply) GetSubscriptionPlanTerms(arg0 context.Context, arg1, arg2 string) (entities.SubscriptionPlanTerms, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionPlanTerms", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.SubscriptionPlanTerms)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionPlanTerms indicates an expected call of GetSubscriptionPlanTerms.
+func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionPlanTerms(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionPlanTerms", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionPlanTerms), arg0, arg1, arg2)
+}
+
+// GetSubscriptionPlanVersions mocks base method.
+func (m *MockMemberRepoImply) GetSubscriptionPlanVersions(arg0 context.Context, arg1 uuid.UUID) ([]entities.SubscriptionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionPlanVersions", arg0, arg1)
+	ret0, _ := ret[0].([]entities.SubscriptionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionPlanVersions indicates an expected call of GetSubscriptionPlanVersions.
+func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionPlanVersions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionPlanVersions", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionPlanVersions), arg0, arg1)
+}
+
+// GetSubscriptionQuota mocks base method.
+func (m *MockMemberRepoImply) GetSubscriptionQuota(arg0 context.Context, arg1 uuid.UUID, arg2 string) (entities.SubscriptionQuota, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionQuota", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.SubscriptionQuota)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionQuota indicates an expected call of GetSubscriptionQuota.
+func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionQuota(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionQuota", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionQuota), arg0, arg1, arg2)
+}
+
 // GetSubscriptionRecordCount mocks base method.
 func (m *MockMemberRepoImply) GetSubscriptionRecordCount(arg0 context.Context, arg1 uuid.UUID) (int64, error) {
 	m.ctrl.T.Helper()
@@ -681,6 +1442,21 @@ func (mr *MockMemberRepoImplyMockRecorder) GetSubscriptionStatusName(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionStatusName", reflect.TypeOf((*MockMemberRepoImply)(nil).GetSubscriptionStatusName), arg0, arg1)
 }
 
+// GetTwoFactor mocks base method.
+func (m *MockMemberRepoImply) GetTwoFactor(arg0 context.Context, arg1 uuid.UUID) (entities.TwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(entities.TwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTwoFactor indicates an expected call of GetTwoFactor.
+func (mr *MockMemberRepoImplyMockRecorder) GetTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTwoFactor", reflect.TypeOf((*MockMemberRepoImply)(nil).GetTwoFactor), arg0, arg1)
+}
+
 // HandleSubscriptionCancellation mocks base method.
 func (m *MockMemberRepoImply) HandleSubscriptionCancellation(arg0 context.Context, arg1 uuid.UUID, arg2 entities.CancelSubscription) error {
 	m.ctrl.T.Helper()
@@ -696,11 +1472,12 @@ func (mr *MockMemberRepoImplyMockRecorder) HandleSubscriptionCancellation(arg0,
 }
 
 // HandleSubscriptionCheckout mocks base method.
-func (m *MockMemberRepoImply) HandleSubscriptionCheckout(arg0 context.Context, arg1 uuid.UUID, arg2 entities.CheckoutSubscription) error {
+func (m *MockMemberRepoImply) HandleSubscriptionCheckout(arg0 context.Context, arg1 uuid.UUID, arg2 entities.CheckoutSubscription) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HandleSubscriptionCheckout", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // HandleSubscriptionCheckout indicates an expected call of HandleSubscriptionCheckout.
@@ -709,18 +1486,19 @@ func (mr *MockMemberRepoImplyMockRecorder) HandleSubscriptionCheckout(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleSubscriptionCheckout", reflect.TypeOf((*MockMemberRepoImply)(nil).HandleSubscriptionCheckout), arg0, arg1, arg2)
 }
 
-// HandleSubscriptionRenewal mocks base method.
-func (m *MockMemberRepoImply) HandleSubscriptionRenewal(arg0 context.Context, arg1 uuid.UUID, arg2 entities.SubscriptionRenewal) error {
+// HasPaidSubscriptionPlans mocks base method.
+func (m *MockMemberRepoImply) HasPaidSubscriptionPlans(arg0 context.Context) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandleSubscriptionRenewal", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "HasPaidSubscriptionPlans", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// HandleSubscriptionRenewal indicates an expected call of HandleSubscriptionRenewal.
-func (mr *MockMemberRepoImplyMockRecorder) HandleSubscriptionRenewal(arg0, arg1, arg2 interface{}) *gomock.Call {
+// HasPaidSubscriptionPlans indicates an expected call of HasPaidSubscriptionPlans.
+func (mr *MockMemberRepoImplyMockRecorder) HasPaidSubscriptionPlans(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleSubscriptionRenewal", reflect.TypeOf((*MockMemberRepoImply)(nil).HandleSubscriptionRenewal), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPaidSubscriptionPlans", reflect.TypeOf((*MockMemberRepoImply)(nil).HasPaidSubscriptionPlans), arg0)
 }
 
 // HasPrimaryBilling mocks base method.
@@ -768,13 +1546,29 @@ func (mr *MockMemberRepoImplyMockRecorder) HasSubscribedToOneTimePlan(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasSubscribedToOneTimePlan", reflect.TypeOf((*MockMemberRepoImply)(nil).HasSubscribedToOneTimePlan), arg0, arg1, arg2)
 }
 
+// ImportMember mocks base method.
+func (m *MockMemberRepoImply) ImportMember(arg0 context.Context, arg1 uuid.UUID, arg2 int, arg3 entities.Member, arg4 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportMember", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportMember indicates an expected call of ImportMember.
+func (mr *MockMemberRepoImplyMockRecorder) ImportMember(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportMember", reflect.TypeOf((*MockMemberRepoImply)(nil).ImportMember), arg0, arg1, arg2, arg3, arg4)
+}
+
 // InitiatePasswordReset mocks base method.
-func (m *MockMemberRepoImply) InitiatePasswordReset(arg0 *gin.Context, arg1 uuid.UUID, arg2 string) (string, error) {
+func (m *MockMemberRepoImply) InitiatePasswordReset(arg0 *gin.Context, arg1 uuid.UUID, arg2 string) (string, time.Time, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "InitiatePasswordReset", arg0, arg1, arg2)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // InitiatePasswordReset indicates an expected call of InitiatePasswordReset.
@@ -903,6 +1697,21 @@ func (mr *MockMemberRepoImplyMockRecorder) IsMemberSubscribedToPlan(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMemberSubscribedToPlan", reflect.TypeOf((*MockMemberRepoImply)(nil).IsMemberSubscribedToPlan), arg0, arg1, arg2)
 }
 
+// IsOneTimeSubscription mocks base method.
+func (m *MockMemberRepoImply) IsOneTimeSubscription(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsOneTimeSubscription", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsOneTimeSubscription indicates an expected call of IsOneTimeSubscription.
+func (mr *MockMemberRepoImplyMockRecorder) IsOneTimeSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsOneTimeSubscription", reflect.TypeOf((*MockMemberRepoImply)(nil).IsOneTimeSubscription), arg0, arg1)
+}
+
 // IsPartnerIdCorrespondsToGateway mocks base method.
 func (m *MockMemberRepoImply) IsPartnerIdCorrespondsToGateway(arg0 context.Context, arg1 string, arg2 int) (bool, error) {
 	m.ctrl.T.Helper()
@@ -968,6 +1777,66 @@ func (mr *MockMemberRepoImplyMockRecorder) IsSubscriptionInGracePeriod(arg0, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSubscriptionInGracePeriod", reflect.TypeOf((*MockMemberRepoImply)(nil).IsSubscriptionInGracePeriod), arg0, arg1, arg2)
 }
 
+// ListMembers mocks base method.
+func (m *MockMemberRepoImply) ListMembers(arg0 context.Context, arg1 entities.MemberListFilter) ([]entities.ViewMembers, *entities.MemberCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", arg0, arg1)
+	ret0, _ := ret[0].([]entities.ViewMembers)
+	ret1, _ := ret[1].(*entities.MemberCursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockMemberRepoImplyMockRecorder) ListMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockMemberRepoImply)(nil).ListMembers), arg0, arg1)
+}
+
+// ListPromoCodes mocks base method.
+func (m *MockMemberRepoImply) ListPromoCodes(arg0 context.Context) ([]entities.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPromoCodes", arg0)
+	ret0, _ := ret[0].([]entities.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPromoCodes indicates an expected call of ListPromoCodes.
+func (mr *MockMemberRepoImplyMockRecorder) ListPromoCodes(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPromoCodes", reflect.TypeOf((*MockMemberRepoImply)(nil).ListPromoCodes), arg0)
+}
+
+// ListSubscriptionPlans mocks base method.
+func (m *MockMemberRepoImply) ListSubscriptionPlans(arg0 context.Context, arg1 bool) ([]entities.SubscriptionPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionPlans", arg0, arg1)
+	ret0, _ := ret[0].([]entities.SubscriptionPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptionPlans indicates an expected call of ListSubscriptionPlans.
+func (mr *MockMemberRepoImplyMockRecorder) ListSubscriptionPlans(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionPlans", reflect.TypeOf((*MockMemberRepoImply)(nil).ListSubscriptionPlans), arg0, arg1)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockMemberRepoImply) MarkEmailVerified(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockMemberRepoImplyMockRecorder) MarkEmailVerified(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockMemberRepoImply)(nil).MarkEmailVerified), arg0, arg1, arg2)
+}
+
 // Middleware mocks base method.
 func (m *MockMemberRepoImply) Middleware(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -1013,20 +1882,253 @@ func (mr *MockMemberRepoImplyMockRecorder) ProviderExists(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProviderExists", reflect.TypeOf((*MockMemberRepoImply)(nil).ProviderExists), arg0, arg1)
 }
 
-// RegisterMember mocks base method.
-func (m *MockMemberRepoImply) RegisterMember(arg0 context.Context, arg1 entities.Member, arg2 string) error {
+// RecordCheckoutPayment mocks base method.
+func (m *MockMemberRepoImply) RecordCheckoutPayment(arg0 context.Context, arg1 entities.SubscriptionPayment) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterMember", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "RecordCheckoutPayment", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordCheckoutPayment indicates an expected call of RecordCheckoutPayment.
+func (mr *MockMemberRepoImplyMockRecorder) RecordCheckoutPayment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCheckoutPayment", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordCheckoutPayment), arg0, arg1)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockMemberRepoImply) RecordLoginFailure(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockMemberRepoImplyMockRecorder) RecordLoginFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordLoginFailure), arg0, arg1, arg2)
+}
+
+// RecordPlanChangePayment mocks base method.
+func (m *MockMemberRepoImply) RecordPlanChangePayment(arg0 context.Context, arg1 entities.SubscriptionPayment, arg2 entities.SubscriptionLedgerEntry) (uuid.UUID, entities.SubscriptionLedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPlanChangePayment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(entities.SubscriptionLedgerEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RecordPlanChangePayment indicates an expected call of RecordPlanChangePayment.
+func (mr *MockMemberRepoImplyMockRecorder) RecordPlanChangePayment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPlanChangePayment", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordPlanChangePayment), arg0, arg1, arg2)
+}
+
+// RecordRenewalAttempt mocks base method.
+func (m *MockMemberRepoImply) RecordRenewalAttempt(arg0 context.Context, arg1 entities.RenewalAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRenewalAttempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordRenewalAttempt indicates an expected call of RecordRenewalAttempt.
+func (mr *MockMemberRepoImplyMockRecorder) RecordRenewalAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRenewalAttempt", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordRenewalAttempt), arg0, arg1)
+}
+
+// RecordRenewalPayment mocks base method.
+func (m *MockMemberRepoImply) RecordRenewalPayment(arg0 context.Context, arg1 entities.SubscriptionPayment, arg2 *entities.SubscriptionLedgerEntry) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRenewalPayment", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordRenewalPayment indicates an expected call of RecordRenewalPayment.
+func (mr *MockMemberRepoImplyMockRecorder) RecordRenewalPayment(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRenewalPayment", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordRenewalPayment), arg0, arg1, arg2)
+}
+
+// RecordSubscriptionPayment mocks base method.
+func (m *MockMemberRepoImply) RecordSubscriptionPayment(arg0 context.Context, arg1 entities.SubscriptionPayment) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSubscriptionPayment", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordSubscriptionPayment indicates an expected call of RecordSubscriptionPayment.
+func (mr *MockMemberRepoImplyMockRecorder) RecordSubscriptionPayment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSubscriptionPayment", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordSubscriptionPayment), arg0, arg1)
+}
+
+// RecordSubscriptionRefund mocks base method.
+func (m *MockMemberRepoImply) RecordSubscriptionRefund(arg0 context.Context, arg1 entities.SubscriptionPayment, arg2 uuid.UUID, arg3 entities.SubscriptionLedgerEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSubscriptionRefund", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// RecordSubscriptionRefund indicates an expected call of RecordSubscriptionRefund.
+func (mr *MockMemberRepoImplyMockRecorder) RecordSubscriptionRefund(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSubscriptionRefund", reflect.TypeOf((*MockMemberRepoImply)(nil).RecordSubscriptionRefund), arg0, arg1, arg2, arg3)
+}
+
+// RegisterMember mocks base method.
+func (m *MockMemberRepoImply) RegisterMember(arg0 context.Context, arg1 entities.Member, arg2 string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // RegisterMember indicates an expected call of RegisterMember.
 func (mr *MockMemberRepoImplyMockRecorder) RegisterMember(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterMember", reflect.TypeOf((*MockMemberRepoImply)(nil).RegisterMember), arg0, arg1, arg2)
 }
 
+// ReleaseLoginAttempt mocks base method.
+func (m *MockMemberRepoImply) ReleaseLoginAttempt(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockMemberRepoImplyMockRecorder) ReleaseLoginAttempt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockMemberRepoImply)(nil).ReleaseLoginAttempt), arg0, arg1, arg2)
+}
+
+// ReleaseResetKeyAttempt mocks base method.
+func (m *MockMemberRepoImply) ReleaseResetKeyAttempt(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseResetKeyAttempt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseResetKeyAttempt indicates an expected call of ReleaseResetKeyAttempt.
+func (mr *MockMemberRepoImplyMockRecorder) ReleaseResetKeyAttempt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseResetKeyAttempt", reflect.TypeOf((*MockMemberRepoImply)(nil).ReleaseResetKeyAttempt), arg0, arg1, arg2)
+}
+
+// RemoveMemberStores mocks base method.
+func (m *MockMemberRepoImply) RemoveMemberStores(arg0 context.Context, arg1 uuid.UUID, arg2 []uuid.UUID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMemberStores", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMemberStores indicates an expected call of RemoveMemberStores.
+func (mr *MockMemberRepoImplyMockRecorder) RemoveMemberStores(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMemberStores", reflect.TypeOf((*MockMemberRepoImply)(nil).RemoveMemberStores), arg0, arg1, arg2)
+}
+
+// ReorderMemberStores mocks base method.
+func (m *MockMemberRepoImply) ReorderMemberStores(arg0 context.Context, arg1 uuid.UUID, arg2 []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderMemberStores", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderMemberStores indicates an expected call of ReorderMemberStores.
+func (mr *MockMemberRepoImplyMockRecorder) ReorderMemberStores(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderMemberStores", reflect.TypeOf((*MockMemberRepoImply)(nil).ReorderMemberStores), arg0, arg1, arg2)
+}
+
+// RequestMemberErasure mocks base method.
+func (m *MockMemberRepoImply) RequestMemberErasure(arg0 context.Context, arg1 uuid.UUID, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestMemberErasure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestMemberErasure indicates an expected call of RequestMemberErasure.
+func (mr *MockMemberRepoImplyMockRecorder) RequestMemberErasure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestMemberErasure", reflect.TypeOf((*MockMemberRepoImply)(nil).RequestMemberErasure), arg0, arg1, arg2)
+}
+
+// ReserveQuota mocks base method.
+func (m *MockMemberRepoImply) ReserveQuota(arg0 context.Context, arg1 entities.QuotaReservation, arg2 time.Duration) (entities.QuotaReservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveQuota", arg0, arg1, arg2)
+	ret0, _ := ret[0].(entities.QuotaReservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveQuota indicates an expected call of ReserveQuota.
+func (mr *MockMemberRepoImplyMockRecorder) ReserveQuota(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveQuota", reflect.TypeOf((*MockMemberRepoImply)(nil).ReserveQuota), arg0, arg1, arg2)
+}
+
+// RetireSubscriptionPlan mocks base method.
+func (m *MockMemberRepoImply) RetireSubscriptionPlan(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireSubscriptionPlan", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireSubscriptionPlan indicates an expected call of RetireSubscriptionPlan.
+func (mr *MockMemberRepoImplyMockRecorder) RetireSubscriptionPlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireSubscriptionPlan", reflect.TypeOf((*MockMemberRepoImply)(nil).RetireSubscriptionPlan), arg0, arg1)
+}
+
+// SaveTwoFactorSecret mocks base method.
+func (m *MockMemberRepoImply) SaveTwoFactorSecret(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTwoFactorSecret", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTwoFactorSecret indicates an expected call of SaveTwoFactorSecret.
+func (mr *MockMemberRepoImplyMockRecorder) SaveTwoFactorSecret(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTwoFactorSecret", reflect.TypeOf((*MockMemberRepoImply)(nil).SaveTwoFactorSecret), arg0, arg1, arg2)
+}
+
+// SetAutoRenewal mocks base method.
+func (m *MockMemberRepoImply) SetAutoRenewal(arg0 context.Context, arg1 uuid.UUID, arg2 string, arg3 bool, arg4 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAutoRenewal", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAutoRenewal indicates an expected call of SetAutoRenewal.
+func (mr *MockMemberRepoImplyMockRecorder) SetAutoRenewal(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAutoRenewal", reflect.TypeOf((*MockMemberRepoImply)(nil).SetAutoRenewal), arg0, arg1, arg2, arg3, arg4)
+}
+
 // StateExists mocks base method.
 func (m *MockMemberRepoImply) StateExists(arg0, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1057,6 +2159,21 @@ func (mr *MockMemberRepoImplyMockRecorder) StorePartnerRelation(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorePartnerRelation", reflect.TypeOf((*MockMemberRepoImply)(nil).StorePartnerRelation), arg0, arg1, arg2)
 }
 
+// SubscriptionDurationExists mocks base method.
+func (m *MockMemberRepoImply) SubscriptionDurationExists(arg0 context.Context, arg1 int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscriptionDurationExists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscriptionDurationExists indicates an expected call of SubscriptionDurationExists.
+func (mr *MockMemberRepoImplyMockRecorder) SubscriptionDurationExists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionDurationExists", reflect.TypeOf((*MockMemberRepoImply)(nil).SubscriptionDurationExists), arg0, arg1)
+}
+
 // SubscriptionProductSwitch mocks base method.
 func (m *MockMemberRepoImply) SubscriptionProductSwitch(arg0 context.Context, arg1 uuid.UUID, arg2 entities.SwitchSubscriptions, arg3 *map[string][]string) (map[string][]string, error) {
 	m.ctrl.T.Helper()
@@ -1072,32 +2189,77 @@ func (mr *MockMemberRepoImplyMockRecorder) SubscriptionProductSwitch(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionProductSwitch", reflect.TypeOf((*MockMemberRepoImply)(nil).SubscriptionProductSwitch), arg0, arg1, arg2, arg3)
 }
 
+// TransitionSubscriptionStatuses mocks base method.
+func (m *MockMemberRepoImply) TransitionSubscriptionStatuses(arg0 context.Context, arg1 time.Time) ([]entities.SubscriptionTransition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionSubscriptionStatuses", arg0, arg1)
+	ret0, _ := ret[0].([]entities.SubscriptionTransition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionSubscriptionStatuses indicates an expected call of TransitionSubscriptionStatuses.
+func (mr *MockMemberRepoImplyMockRecorder) TransitionSubscriptionStatuses(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionSubscriptionStatuses", reflect.TypeOf((*MockMemberRepoImply)(nil).TransitionSubscriptionStatuses), arg0, arg1)
+}
+
 // UpdateBillingAddress mocks base method.
-func (m *MockMemberRepoImply) UpdateBillingAddress(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 entities.BillingAddress) error {
+func (m *MockMemberRepoImply) UpdateBillingAddress(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 entities.BillingAddress, arg4 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBillingAddress", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBillingAddress indicates an expected call of UpdateBillingAddress.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateBillingAddress(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBillingAddress", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateBillingAddress), arg0, arg1, arg2, arg3, arg4)
+}
+
+// UpdateEmailVerificationPolicy mocks base method.
+func (m *MockMemberRepoImply) UpdateEmailVerificationPolicy(arg0 context.Context, arg1 uuid.UUID, arg2 entities.EmailVerificationPolicy) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBillingAddress", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "UpdateEmailVerificationPolicy", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateBillingAddress indicates an expected call of UpdateBillingAddress.
-func (mr *MockMemberRepoImplyMockRecorder) UpdateBillingAddress(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// UpdateEmailVerificationPolicy indicates an expected call of UpdateEmailVerificationPolicy.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateEmailVerificationPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBillingAddress", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateBillingAddress), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmailVerificationPolicy", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateEmailVerificationPolicy), arg0, arg1, arg2)
 }
 
 // UpdateMember mocks base method.
-func (m *MockMemberRepoImply) UpdateMember(arg0 context.Context, arg1 uuid.UUID, arg2 entities.Member) error {
+func (m *MockMemberRepoImply) UpdateMember(arg0 context.Context, arg1 uuid.UUID, arg2 entities.Member, arg3 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMember", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMember indicates an expected call of UpdateMember.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateMember(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateMember), arg0, arg1, arg2, arg3)
+}
+
+// UpdateMemberStore mocks base method.
+func (m *MockMemberRepoImply) UpdateMemberStore(arg0 context.Context, arg1, arg2 uuid.UUID, arg3 entities.MemberStoreUpdate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMember", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateMemberStore", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateMember indicates an expected call of UpdateMember.
-func (mr *MockMemberRepoImplyMockRecorder) UpdateMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+// UpdateMemberStore indicates an expected call of UpdateMemberStore.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateMemberStore(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateMember), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberStore", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateMemberStore), arg0, arg1, arg2, arg3)
 }
 
 // UpdatePassword mocks base method.
@@ -1142,6 +2304,64 @@ func (mr *MockMemberRepoImplyMockRecorder) UpdateRandomBillingAddressToPrimary(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRandomBillingAddressToPrimary", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateRandomBillingAddressToPrimary), arg0, arg1, arg2)
 }
 
+// UpdateSubscriptionPaymentStatus mocks base method.
+func (m *MockMemberRepoImply) UpdateSubscriptionPaymentStatus(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionPaymentStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionPaymentStatus indicates an expected call of UpdateSubscriptionPaymentStatus.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateSubscriptionPaymentStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionPaymentStatus", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateSubscriptionPaymentStatus), arg0, arg1, arg2)
+}
+
+// UpdateSubscriptionStatus mocks base method.
+func (m *MockMemberRepoImply) UpdateSubscriptionStatus(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionStatus indicates an expected call of UpdateSubscriptionStatus.
+func (mr *MockMemberRepoImplyMockRecorder) UpdateSubscriptionStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionStatus", reflect.TypeOf((*MockMemberRepoImply)(nil).UpdateSubscriptionStatus), arg0, arg1, arg2)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockMemberRepoImply) UseRecoveryCode(arg0 context.Context, arg1 uuid.UUID, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockMemberRepoImplyMockRecorder) UseRecoveryCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockMemberRepoImply)(nil).UseRecoveryCode), arg0, arg1, arg2)
+}
+
+// UseTwoFactorStep mocks base method.
+func (m *MockMemberRepoImply) UseTwoFactorStep(arg0 context.Context, arg1 uuid.UUID, arg2 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTwoFactorStep", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTwoFactorStep indicates an expected call of UseTwoFactorStep.
+func (mr *MockMemberRepoImplyMockRecorder) UseTwoFactorStep(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTwoFactorStep", reflect.TypeOf((*MockMemberRepoImply)(nil).UseTwoFactorStep), arg0, arg1, arg2)
+}
+
 // ViewAllSubscriptions mocks base method.
 func (m *MockMemberRepoImply) ViewAllSubscriptions(arg0 context.Context, arg1 uuid.UUID, arg2 entities.ReqParams, arg3 *map[string][]string) ([]entities.ListAllSubscriptions, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlab.com/tuneverse/toolkit/core/logger"
+)
+
+// Job is a task run periodically by the scheduler.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs of the member service. Every replica runs its own
+// scheduler, jobs that must only run once per tick have to guard themselves, e.g. with
+// a database advisory lock.
+type Scheduler struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+// New creates a scheduler for the given jobs. Every job needs a positive interval.
+func New(jobs ...Job) (*Scheduler, error) {
+	for _, job := range jobs {
+		if job.Interval <= 0 {
+			return nil, fmt.Errorf("job %s: interval %s is not positive", job.Name, job.Interval)
+		}
+	}
+	return &Scheduler{
+		jobs: jobs,
+	}, nil
+}
+
+// Start runs every job immediately and then on its interval until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Wait blocks until all jobs have stopped.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// loop runs the job until ctx is cancelled.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run executes the job once, a failing or panicking job does not stop the scheduler.
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Log().WithContext(ctx).Errorf("Scheduled job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Scheduled job %s failed: %s", job.Name, err.Error())
+	}
+}
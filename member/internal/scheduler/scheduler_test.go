@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"member/internal/consts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/tuneverse/toolkit/core/logger"
+)
+
+func init() {
+	logger.InitLogger(&logger.ClientOptions{
+		Service:  consts.AppName,
+		LogLevel: "info",
+	})
+}
+
+// TestScheduler checks that jobs run repeatedly, survive failures and stop on cancellation.
+func TestScheduler(t *testing.T) {
+	var runs, failures int32
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err := New(
+		Job{Name: "count", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		}},
+		Job{Name: "fail", Interval: 5 * time.Millisecond, Run: func(ctx context.Context) error {
+			if atomic.AddInt32(&failures, 1) == 1 {
+				panic("first run panics")
+			}
+			return errors.New("failed")
+		}},
+	)
+	require.NoError(t, err)
+	s.Start(ctx)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 3 && atomic.LoadInt32(&failures) >= 3
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	s.Wait()
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs))
+}
+
+// TestSchedulerInterval checks jobs without a positive interval are refused.
+func TestSchedulerInterval(t *testing.T) {
+	run := func(ctx context.Context) error { return nil }
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		_, err := New(Job{Name: "count", Interval: time.Minute, Run: run}, Job{Name: "broken", Interval: interval, Run: run})
+		assert.ErrorContains(t, err, "broken")
+	}
+}
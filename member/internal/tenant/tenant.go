@@ -0,0 +1,96 @@
+// Package tenant carries the partner a request is scoped to and restricts member
+// queries to that partner.
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// partnerKey is the context key of the partner a request is scoped to.
+type partnerKey struct{}
+
+// allPartnersKey is the context key marking a context that acts across partners.
+type allPartnersKey struct{}
+
+// WithPartner returns a copy of ctx scoped to the partner.
+func WithPartner(ctx context.Context, partnerID uuid.UUID) context.Context {
+	return context.WithValue(ctx, partnerKey{}, partnerID)
+}
+
+// Scope scopes the request of a gin context to the partner. Handlers and the
+// repository pick the partner up from the request context.
+func Scope(ctx *gin.Context, partnerID uuid.UUID) {
+	ctx.Request = ctx.Request.WithContext(WithPartner(ctx.Request.Context(), partnerID))
+}
+
+// AllPartners returns a copy of ctx that acts across partners, for callers that are
+// trusted with every partner such as platform admins and background jobs.
+func AllPartners(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allPartnersKey{}, true)
+}
+
+// ScopeAll lets the request of a gin context act across partners.
+func ScopeAll(ctx *gin.Context) {
+	ctx.Request = ctx.Request.WithContext(AllPartners(ctx.Request.Context()))
+}
+
+// PartnerFrom returns the partner ctx is scoped to. A gin context is looked up
+// through its request context.
+func PartnerFrom(ctx context.Context) (uuid.UUID, bool) {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return uuid.Nil, false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	partnerID, ok := ctx.Value(partnerKey{}).(uuid.UUID)
+	return partnerID, ok
+}
+
+// isAllPartners reports whether ctx acts across partners.
+func isAllPartners(ctx context.Context) bool {
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if ginCtx.Request == nil {
+			return false
+		}
+		ctx = ginCtx.Request.Context()
+	}
+	all, _ := ctx.Value(allPartnersKey{}).(bool)
+	return all
+}
+
+// Condition returns the condition restricting column to the partner of ctx, to be
+// appended to a WHERE clause, with the partner appended to args. Contexts acting across
+// partners get no condition, any other unscoped context matches no rows.
+func Condition(ctx context.Context, column string, args []any) (string, []any) {
+	partnerID, ok := PartnerFrom(ctx)
+	if !ok {
+		return unpartneredCondition(ctx), args
+	}
+	args = append(args, partnerID)
+	return fmt.Sprintf(" AND %s = $%d", column, len(args)), args
+}
+
+// MemberCondition is the Condition of tables referencing a member: the member in
+// memberColumn must belong to the partner of ctx.
+func MemberCondition(ctx context.Context, memberColumn string, args []any) (string, []any) {
+	partnerID, ok := PartnerFrom(ctx)
+	if !ok {
+		return unpartneredCondition(ctx), args
+	}
+	args = append(args, partnerID)
+	return fmt.Sprintf(" AND %s IN (SELECT id FROM member WHERE partner_id = $%d)", memberColumn, len(args)), args
+}
+
+// unpartneredCondition is the condition of a context without a partner: none when it
+// acts across partners, otherwise one matching no rows.
+func unpartneredCondition(ctx context.Context) string {
+	if isAllPartners(ctx) {
+		return ""
+	}
+	return " AND FALSE"
+}
@@ -0,0 +1,83 @@
+package tenant
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPartnerFrom checks the partner is carried by plain and gin contexts.
+func TestPartnerFrom(t *testing.T) {
+	partnerID := uuid.New()
+
+	_, ok := PartnerFrom(context.Background())
+	assert.False(t, ok)
+
+	got, ok := PartnerFrom(WithPartner(context.Background(), partnerID))
+	assert.True(t, ok)
+	assert.Equal(t, partnerID, got)
+
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("GET", "/", nil)
+	_, ok = PartnerFrom(ginCtx)
+	assert.False(t, ok)
+
+	Scope(ginCtx, partnerID)
+	got, ok = PartnerFrom(ginCtx)
+	assert.True(t, ok)
+	assert.Equal(t, partnerID, got)
+}
+
+// TestCondition checks the conditions are numbered after the existing arguments,
+// left out for contexts acting across partners and match nothing for unscoped contexts.
+func TestCondition(t *testing.T) {
+	partnerID := uuid.New()
+	memberID := uuid.New()
+	ctx := WithPartner(context.Background(), partnerID)
+
+	cond, args := Condition(ctx, "m.partner_id", []any{memberID})
+	assert.Equal(t, " AND m.partner_id = $2", cond)
+	assert.Equal(t, []any{memberID, partnerID}, args)
+
+	cond, args = MemberCondition(ctx, "member_id", []any{memberID})
+	assert.Equal(t, " AND member_id IN (SELECT id FROM member WHERE partner_id = $2)", cond)
+	assert.Equal(t, []any{memberID, partnerID}, args)
+
+	cond, args = Condition(AllPartners(context.Background()), "partner_id", []any{memberID})
+	assert.Empty(t, cond)
+	assert.Equal(t, []any{memberID}, args)
+
+	cond, args = MemberCondition(AllPartners(context.Background()), "member_id", []any{memberID})
+	assert.Empty(t, cond)
+	assert.Equal(t, []any{memberID}, args)
+
+	cond, args = Condition(context.Background(), "partner_id", []any{memberID})
+	assert.Equal(t, " AND FALSE", cond)
+	assert.Equal(t, []any{memberID}, args)
+
+	cond, args = MemberCondition(context.Background(), "member_id", []any{memberID})
+	assert.Equal(t, " AND FALSE", cond)
+	assert.Equal(t, []any{memberID}, args)
+}
+
+// TestScopeAll checks gin requests may act across partners, and a partner scope
+// still restricts them.
+func TestScopeAll(t *testing.T) {
+	ginCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginCtx.Request = httptest.NewRequest("GET", "/", nil)
+
+	cond, _ := Condition(ginCtx, "partner_id", nil)
+	assert.Equal(t, " AND FALSE", cond)
+
+	ScopeAll(ginCtx)
+	cond, _ = Condition(ginCtx, "partner_id", nil)
+	assert.Empty(t, cond)
+
+	Scope(ginCtx, uuid.New())
+	cond, _ = Condition(ginCtx, "partner_id", nil)
+	assert.Equal(t, " AND partner_id = $1", cond)
+}
@@ -0,0 +1,149 @@
+// Package totp implements the time-based one-time passwords (RFC 6238) of the two-factor
+// authentication, along with the recovery codes members use when they lose their device.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the length of the generated codes.
+	Digits = 6
+	// Period is the time a code is valid for.
+	Period = 30 * time.Second
+	// RecoveryCodeCount is the number of recovery codes generated when 2FA is enabled.
+	RecoveryCodeCount = 10
+
+	// codeModulo is 10^Digits.
+	codeModulo = 1000000
+
+	secretLength       = 20
+	recoveryCodeLength = 10
+)
+
+// encoding is the base32 alphabet authenticator apps expect secrets in.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Authenticator validates the codes of the members' authenticator apps.
+type Authenticator struct {
+	// Issuer names the service in the authenticator apps.
+	Issuer string
+	// Skew is the number of periods before and after the current one whose codes are
+	// still accepted, to absorb clock drift.
+	Skew int
+}
+
+// NewAuthenticator creates an authenticator for the issuer.
+func NewAuthenticator(issuer string, skew int) *Authenticator {
+	return &Authenticator{
+		Issuer: issuer,
+		Skew:   max(skew, 0),
+	}
+}
+
+// GenerateSecret returns a new random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth URI authenticator apps enroll the secret from, usually shown as a QR code.
+func (a *Authenticator) URI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", a.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(a.Issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Step returns the time step of t.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// Code returns the code of the secret for the time step.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, value%codeModulo), nil
+}
+
+// Validate checks the code against the codes of the steps around now and returns the step it
+// matched. Callers must reject steps that were already used to prevent replays.
+func (a *Authenticator) Validate(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	current := Step(now)
+	for offset := -a.Skew; offset <= a.Skew; offset++ {
+		expected, err := Code(secret, current+int64(offset))
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + int64(offset), true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns n random single-use recovery codes, formatted as two dash
+// separated groups for readability.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	raw := make([]byte, recoveryCodeLength)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw))[:recoveryCodeLength]
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash a recovery code is stored as. The code is normalized
+// first, so it can be typed in any case and with or without the dash.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsRecoveryCode reports whether the code looks like a recovery code rather than an
+// authenticator app code.
+func IsRecoveryCode(code string) bool {
+	return len(strings.TrimSpace(code)) > Digits
+}
@@ -0,0 +1,87 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the base32 encoding of the SHA1 secret of the RFC 6238 test vectors.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// TestCode checks the codes against the RFC 6238 test vectors, truncated to six digits.
+func TestCode(t *testing.T) {
+	for unix, expected := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+
+	_, err := Code("not base32!", 1)
+	assert.Error(t, err)
+}
+
+// TestValidate checks codes are accepted within the skew and report the step they matched.
+func TestValidate(t *testing.T) {
+	authenticator := NewAuthenticator("Tuneverse", 1)
+	now := time.Unix(1234567890, 0)
+
+	previous, err := Code(rfcSecret, Step(now)-1)
+	require.NoError(t, err)
+	step, ok := authenticator.Validate(rfcSecret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now)-1, step)
+
+	stale, err := Code(rfcSecret, Step(now)-2)
+	require.NoError(t, err)
+	_, ok = authenticator.Validate(rfcSecret, stale, now)
+	assert.False(t, ok)
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		_, ok = authenticator.Validate(rfcSecret, code, now)
+		assert.False(t, ok, code)
+	}
+}
+
+// TestURI checks the enrollment URI carries the secret and the issuer.
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	uri, err := url.Parse(NewAuthenticator("Tuneverse", 1).URI("john@example.com", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Tuneverse:john@example.com", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Tuneverse", uri.Query().Get("issuer"))
+}
+
+// TestRecoveryCodes checks recovery codes are unique and hash the same however they are typed.
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, recoveryCodeLength+1)
+		assert.True(t, IsRecoveryCode(code))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	code := codes[0]
+	assert.Equal(t, HashRecoveryCode(code), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	assert.NotEqual(t, HashRecoveryCode(code), HashRecoveryCode(codes[1]))
+	assert.False(t, IsRecoveryCode("123456"))
+}
@@ -2,14 +2,30 @@ package usecases
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"member/internal/activity"
+	"member/internal/address"
+	"member/internal/bulk"
 	"member/internal/consts"
+	"member/internal/cursor"
 	"member/internal/entities"
+	"member/internal/etag"
+	"member/internal/notifier"
+	"member/internal/payment"
+	"member/internal/quota"
+	"member/internal/renewal"
 	"member/internal/repo"
+	"member/internal/tenant"
+	"member/internal/totp"
+	"member/internal/verification"
 	"member/utilities"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -19,12 +35,18 @@ import (
 	"gitlab.com/tuneverse/toolkit/core/logger"
 	"gitlab.com/tuneverse/toolkit/models"
 	"gitlab.com/tuneverse/toolkit/utils"
-	cryptoHash "gitlab.com/tuneverse/toolkit/utils/crypto"
 )
 
 // MemberUseCases defines use cases related to member operations.
 type MemberUseCases struct {
-	repo repo.MemberRepoImply
+	repo       repo.MemberRepoImply
+	notifier   notifier.Notifier
+	payments   *payment.Registry
+	activities activity.Recorder
+	verifier   *verification.Signer
+	twoFactor  *totp.Authenticator
+	challenges *verification.Signer
+	addresses  *address.Rules
 }
 
 // MemberUseCaseImply interface
@@ -35,12 +57,14 @@ type MemberUseCaseImply interface {
 
 	// UpdateBillingAddress updates an existing billing address in a member's profile.
 	// It takes the context, memberID, and billingAddress as input, and returns a map of validation error messages and an error, if any.
-	UpdateBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress) (map[string][]string, error)
+	// A version other than 0 has to match the current version of the billing address, consts.ErrVersionMismatch is returned otherwise.
+	UpdateBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress, version int64) (map[string][]string, error)
 	//DeleteBillingAddress deletes a members billing address
-	DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID) (map[string][]string, error)
+	DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, version int64) (map[string][]string, error)
 	// UpdateMember updates a member's profile.
 	// It takes the context, memberID, and updated member profile details as input, and returns a map of validation error messages and an error, if any.
-	UpdateMember(ctx *gin.Context, memberID uuid.UUID, args entities.Member) (map[string][]string, error)
+	// A version other than 0 has to match the current version of the member, consts.ErrVersionMismatch is returned otherwise.
+	UpdateMember(ctx *gin.Context, memberID uuid.UUID, args entities.Member, version int64) (map[string][]string, error)
 	// GetAllBillingAddresses retrieves all billing addresses associated with a member.
 	// It takes the context and memberID as input, and returns a slice of BillingAddress entities and an error, if any.
 	GetAllBillingAddresses(ctx *gin.Context, memberID uuid.UUID, params entities.Params) (map[string][]string, []entities.BillingAddress, models.MetaData, error)
@@ -50,8 +74,33 @@ type MemberUseCaseImply interface {
 	ChangePassword(ctx *gin.Context, memberID uuid.UUID, key string, newPassword string, currentPassword string) (map[string][]string, error)
 
 	// InitiatePasswordReset initiates a password reset for a member.
-	// It takes the context, memberID, and email as input, and returns a reset token, a map of validation error messages, and an error, if any.
-	InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, map[string][]string, error)
+	// It takes the context, memberID, and email as input, sends the reset key to the member's email
+	// and returns a map of validation error messages and an error, if any.
+	InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (map[string][]string, error)
+
+	// UnlockMember lifts the login lockout of the member.
+	UnlockMember(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, error)
+	// EnrollTwoFactor starts the two-factor enrollment of the member and returns the secret to add to an authenticator app.
+	EnrollTwoFactor(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, entities.TwoFactorEnrollment, error)
+	// EnableTwoFactor enables the pending two-factor authentication with its first code and returns the recovery codes.
+	EnableTwoFactor(ctx *gin.Context, memberID uuid.UUID, code string) (map[string][]string, entities.TwoFactorRecoveryCodes, error)
+	// DisableTwoFactor disables the two-factor authentication of the member.
+	DisableTwoFactor(ctx *gin.Context, memberID uuid.UUID, code string) (map[string][]string, error)
+	// CompleteTwoFactorChallenge completes the login challenge and returns the basic member details.
+	CompleteTwoFactorChallenge(ctx *gin.Context, partnerID string, challenge entities.TwoFactorChallenge) (map[string][]string, entities.BasicMemberData, error)
+
+	// VerifyEmail verifies the email address of the member the token was issued to.
+	VerifyEmail(ctx *gin.Context, token string) (map[string][]string, error)
+
+	// ResendEmailVerification sends a new verification token to the partner's unverified member
+	// registered with the email.
+	ResendEmailVerification(ctx *gin.Context, partnerID string, email string) (map[string][]string, error)
+
+	// GetEmailVerificationPolicy returns the email verification policy of the partner.
+	GetEmailVerificationPolicy(ctx *gin.Context, partnerID uuid.UUID) (entities.EmailVerificationPolicy, map[string][]string, error)
+
+	// UpdateEmailVerificationPolicy stores the email verification policy of the partner.
+	UpdateEmailVerificationPolicy(ctx *gin.Context, partnerID uuid.UUID, policy entities.EmailVerificationPolicy) (map[string][]string, error)
 
 	// IsMemberExists checks if a member with the specified memberId exists.
 	// It takes the memberId and context as input, and returns true if the member exists, false otherwise, and an error if one occurs.
@@ -68,6 +117,8 @@ type MemberUseCaseImply interface {
 	// ViewMembers retrieves a list of members.
 	// It takes the context and parameters as input, and returns a slice of ViewMembers and metadata, along with an error if any.
 	ViewMembers(ctx *gin.Context, params entities.Params) ([]entities.ViewMembers, models.MetaData, error)
+	// ListMembers returns a page of the cursor-paginated member listing.
+	ListMembers(ctx *gin.Context, params entities.MemberListParams) ([]entities.ViewMembers, entities.MemberListMetaData, map[string][]string, error)
 
 	// GetBasicMemberDetailsByEmail retrieves basic member details by email.
 	// It takes the context, partnerID, member payload, contextError, endpoint, and method as input, and returns headers, basic member data, and an error.
@@ -82,6 +133,50 @@ type MemberUseCaseImply interface {
 
 	// HandleSubscriptionCancellation handles the cancellation process for an active subscription.
 	HandleSubscriptionCancellation(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription, partnerIDStr string) (map[string][]string, error)
+	// HandleSubscriptionPlanChange switches a member subscription to another plan and records the prorated adjustment.
+	HandleSubscriptionPlanChange(ctx *gin.Context, memberID uuid.UUID, data entities.SubscriptionPlanChange, partnerIDStr string) (entities.SubscriptionLedgerEntry, map[string][]string, error)
+	// ListInvoices lists the invoices issued to a member.
+	ListInvoices(ctx *gin.Context, memberID uuid.UUID, reqParam entities.ReqParams) ([]entities.Invoice, models.MetaData, map[string][]string, error)
+	// GetInvoice returns an invoice issued to a member.
+	GetInvoice(ctx *gin.Context, memberID uuid.UUID, invoiceID string) (entities.Invoice, map[string][]string, error)
+	// ListMemberAudit lists the audit trail of a member.
+	ListMemberAudit(ctx *gin.Context, memberID uuid.UUID, params entities.AuditParams) ([]entities.AuditEntry, models.MetaData, map[string][]string, error)
+	// SetAutoRenewal opts a member subscription in or out of automatic renewal.
+	SetAutoRenewal(ctx *gin.Context, memberID uuid.UUID, request entities.AutoRenewal) (map[string][]string, error)
+	// GetRenewalAttempts lists the automatic renewal attempts of a member subscription.
+	GetRenewalAttempts(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string) ([]entities.RenewalAttempt, map[string][]string, error)
+	// ProcessSubscriptionRenewals charges the subscriptions whose automatic renewal is due.
+	ProcessSubscriptionRenewals(ctx context.Context, limit int, lease time.Duration, dunning []time.Duration) error
+	// ListSubscriptionPlans lists the latest version of every plan of the catalogue.
+	ListSubscriptionPlans(ctx *gin.Context, params entities.SubscriptionPlanParams) ([]entities.SubscriptionPlan, error)
+	// GetSubscriptionPlanVersions lists the versions of a plan of the catalogue.
+	GetSubscriptionPlanVersions(ctx *gin.Context, planID string) ([]entities.SubscriptionPlan, map[string][]string, error)
+	// CreateSubscriptionPlan adds a plan to the catalogue.
+	CreateSubscriptionPlan(ctx *gin.Context, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, map[string][]string, error)
+	// AddSubscriptionPlanVersion publishes a new version of a plan.
+	AddSubscriptionPlanVersion(ctx *gin.Context, planID string, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, map[string][]string, error)
+	// RetireSubscriptionPlan withdraws a plan from the catalogue.
+	RetireSubscriptionPlan(ctx *gin.Context, planID string) (map[string][]string, error)
+	// CreatePromoCode adds a promo code members can apply at subscription checkout.
+	CreatePromoCode(ctx *gin.Context, request entities.PromoCodeRequest) (entities.PromoCode, map[string][]string, error)
+	// ListPromoCodes lists the promo codes with their redemptions so far.
+	ListPromoCodes(ctx *gin.Context) ([]entities.PromoCode, error)
+	// ListPromoCodeRedemptions lists the redemptions of a promo code.
+	ListPromoCodeRedemptions(ctx *gin.Context, promoCodeID string, params entities.PromoRedemptionParams) ([]entities.PromoRedemption, models.MetaData, map[string][]string, error)
+	// GetSubscriptionQuota returns the limits, usage and pending reservations of a member subscription.
+	GetSubscriptionQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.SubscriptionQuota, map[string][]string, error)
+	// CheckQuota answers whether items can be added to a member subscription.
+	CheckQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, request entities.QuotaRequest) (entities.QuotaCheck, map[string][]string, error)
+	// ReserveQuota holds capacity of a member subscription while items are created.
+	ReserveQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, request entities.QuotaRequest) (entities.QuotaReservation, map[string][]string, error)
+	// CommitQuotaReservation confirms the items of a reservation were created.
+	CommitQuotaReservation(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID string) (map[string][]string, error)
+	// ReleaseQuotaReservation gives the capacity of a reservation back.
+	ReleaseQuotaReservation(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID string) (map[string][]string, error)
+	// ExpireQuotaReservations expires the pending quota reservations past their expiry.
+	ExpireQuotaReservations(ctx context.Context) error
+	// HandlePaymentWebhook verifies a payment gateway event and applies it to the payment and its subscription.
+	HandlePaymentWebhook(ctx *gin.Context, gatewayName string, partnerID string, payload []byte, signature string) (map[string][]string, error)
 	//SubscriptionProductSwitch switches a product from one active subscription plan to another(based on criterias)
 	SubscriptionProductSwitch(context.Context, uuid.UUID, entities.SwitchSubscriptions) (map[string][]string, error)
 	//ViewAllSubscriptions list all subscriptions of a member
@@ -90,8 +185,36 @@ type MemberUseCaseImply interface {
 	GetSubscriptionRecordCount(context.Context, uuid.UUID) (int64, error)
 	// IsMemberExists checks if a member with the specified memberId exists.
 	IsMemberExist(context.Context, uuid.UUID) (bool, error)
-	DeleteMember(ctx *gin.Context, memberID string) (map[string][]string, error)
+	DeleteMember(ctx *gin.Context, memberID string, version int64) (map[string][]string, error)
 	AddMemberStores(ctx *gin.Context, memberID uuid.UUID, stores []string) (map[string][]string, error)
+	// ListMemberStores returns the stores of a member in the member's order.
+	ListMemberStores(ctx *gin.Context, memberID uuid.UUID) ([]entities.MemberStore, map[string][]string, error)
+	// UpdateMemberStore renames or (de)activates a store of a member.
+	UpdateMemberStore(ctx *gin.Context, memberID uuid.UUID, storeID string, update entities.MemberStoreUpdate) (map[string][]string, error)
+	// RemoveMemberStore removes a store from a member.
+	RemoveMemberStore(ctx *gin.Context, memberID uuid.UUID, storeID string) (map[string][]string, error)
+	// ReorderMemberStores orders the stores of a member.
+	ReorderMemberStores(ctx *gin.Context, memberID uuid.UUID, order entities.MemberStoreOrder) (map[string][]string, error)
+	// GetPartnerStores returns the catalogue of stores a partner offers to its members.
+	GetPartnerStores(ctx *gin.Context, partnerID uuid.UUID) ([]entities.PartnerStore, map[string][]string, error)
+	// BulkMemberStores applies stores to, or removes stores from, many members.
+	BulkMemberStores(ctx *gin.Context, request entities.BulkMemberStores) ([]entities.BulkMemberStoresResult, map[string][]string, error)
+	// ProcessSubscriptionLifecycle moves subscriptions to warning, grace and expired statuses and notifies the members.
+	ProcessSubscriptionLifecycle(ctx context.Context) error
+	// ExportMember returns the data held about a member and records the export in the activity log.
+	ExportMember(ctx *gin.Context, memberID uuid.UUID) (entities.MemberExport, map[string][]string, error)
+	// RequestMemberErasure queues the erasure of a member's personal data.
+	RequestMemberErasure(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, error)
+	// ProcessMemberErasures anonymizes the members whose erasure was requested.
+	ProcessMemberErasures(ctx context.Context, limit int) error
+	// ImportMembers queues a bulk import of the uploaded members.
+	ImportMembers(ctx *gin.Context, format string, upload io.Reader) (entities.MemberImport, map[string][]string, error)
+	// GetMemberImport returns the progress and row errors of a bulk import.
+	GetMemberImport(ctx *gin.Context, importID uuid.UUID) (entities.MemberImport, map[string][]string, error)
+	// ProcessMemberImports imports the rows of the open bulk imports.
+	ProcessMemberImports(ctx context.Context, limit int, lease time.Duration) error
+	// ExportMembers streams the filtered member list as CSV.
+	ExportMembers(ctx *gin.Context, params entities.MemberListParams, w io.Writer) (map[string][]string, error)
 }
 
 // GracePeriodError represents an error indicating that the subscription is in the grace period.
@@ -105,9 +228,63 @@ func (e GracePeriodError) Error() string {
 }
 
 // NewMemberUseCases is a constructor for creating an instance of MemberUseCases.
-func NewMemberUseCases(memberRepo repo.MemberRepoImply) MemberUseCaseImply {
+func NewMemberUseCases(memberRepo repo.MemberRepoImply, memberNotifier notifier.Notifier, paymentGateways *payment.Registry,
+	activities activity.Recorder, verifier *verification.Signer, twoFactor *totp.Authenticator, challenges *verification.Signer,
+	addressRules *address.Rules) MemberUseCaseImply {
 	return &MemberUseCases{
-		repo: memberRepo,
+		repo:       memberRepo,
+		notifier:   memberNotifier,
+		payments:   paymentGateways,
+		activities: activities,
+		verifier:   verifier,
+		twoFactor:  twoFactor,
+		challenges: challenges,
+		addresses:  addressRules,
+	}
+}
+
+// recordActivity adds an activity of the member to the activity log. Failures are only logged,
+// the recorded operation has already succeeded.
+func (member *MemberUseCases) recordActivity(ctx context.Context, memberID uuid.UUID, action string, data map[string]interface{}) {
+	err := member.activities.Record(ctx, models.ActivityLog{
+		MemberID: memberID.String(),
+		Action:   action,
+		Data:     data,
+	})
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to record activity %s of member %s: %s", action, memberID, err.Error())
+	}
+}
+
+// isAdmin reports whether the authenticated caller is a platform or partner admin.
+func isAdmin(ctx *gin.Context) bool {
+	memberType := ctx.GetString(consts.ContextMemberType)
+	roles := ctx.GetStringSlice(consts.ContextRoles)
+	for _, role := range []string{consts.RoleAdmin, consts.RolePartnerAdmin} {
+		if memberType == role || slices.Contains(roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyMember sends the notification event to the member. Delivery failures are only
+// logged, the operation that triggered the notification has already succeeded.
+func (member *MemberUseCases) notifyMember(ctx context.Context, memberID uuid.UUID, event string, data map[string]interface{}) {
+	contact, err := member.repo.GetMemberContact(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to load contact of member %s for %s notification: %s", memberID, event, err.Error())
+		return
+	}
+	data["Name"] = contact.Name
+	err = member.notifier.Notify(ctx, entities.Notification{
+		Event:    event,
+		To:       contact.Email,
+		Language: contact.Language,
+		Data:     data,
+	})
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to send %s notification to member %s: %s", event, memberID, err.Error())
 	}
 }
 
@@ -154,10 +331,14 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 		return fieldsMap, nil
 	}
 
+	// Normalize the address first, so that addresses only differing in spacing or casing are
+	// validated the same way and caught as duplicates
+	billingAddress = member.addresses.Normalize(billingAddress)
+
 	// Validate the required fields in the billing address
 
 	// Check if address is empty
-	if billingAddress.Address == "" || len(billingAddress.Address) == 0 {
+	if billingAddress.Address == "" {
 		utils.AppendValuesToMap(fieldsMap, consts.Address, consts.Required)
 		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Missing required fields")
 		return fieldsMap, nil
@@ -167,24 +348,6 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Address exceeds maximum length of 150 characters")
 		return fieldsMap, nil
 	}
-	// Check if zipcode is empty
-	if len(billingAddress.Zipcode) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Missing required fields")
-		return fieldsMap, nil
-	}
-
-	// Validate the ZIP code
-	// Check if ZIP code length is not between 4-10 characters
-	if len(billingAddress.Zipcode) < 4 || len(billingAddress.Zipcode) > 10 {
-		utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.ZipFormat)
-		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Invalid ZIP code length")
-	} else {
-		// Validate ZIP code format using utility function
-		if isValidZip := utilities.IsValidZIPCode(billingAddress.Zipcode); !isValidZip {
-			utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.Invalid)
-		}
-	}
 
 	// Validate country
 	if len(billingAddress.Country) == 0 {
@@ -204,20 +367,21 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 		return fieldsMap, nil
 	}
 
+	// Validate the postal code and the state against the rules of the country
+	member.addresses.Validate(billingAddress, fieldsMap)
+
 	// Check if the state exists within the provided country
-	stateExists, err := member.repo.StateExists(billingAddress.State, billingAddress.Country)
-	if len(billingAddress.State) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.State, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Country cannot be empty")
-	}
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check state existence: %v", err)
-		return nil, err
-	}
-	if !stateExists {
-		utils.AppendValuesToMap(fieldsMap, consts.State, consts.StateNotExist)
-		logger.Log().WithContext(ctx).Errorf("Failed to add billing address: State does not exist")
-		return fieldsMap, nil
+	if len(billingAddress.State) != 0 {
+		stateExists, err := member.repo.StateExists(billingAddress.State, billingAddress.Country)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to check state existence: %v", err)
+			return nil, err
+		}
+		if !stateExists {
+			utils.AppendValuesToMap(fieldsMap, consts.State, consts.StateNotExist)
+			logger.Log().WithContext(ctx).Errorf("Failed to add billing address: State does not exist")
+			return fieldsMap, nil
+		}
 	}
 	// If any validation errors are found, return the error map
 	if len(fieldsMap) != 0 {
@@ -226,7 +390,7 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 	}
 
 	// Check if the billing address already exists for the member
-	exists, err = member.repo.BillingAddressExists(ctx, memberID, billingAddress)
+	exists, err = member.repo.BillingAddressExists(ctx, memberID, billingAddress, uuid.Nil)
 	if err != nil {
 		logger.Log().WithContext(ctx).Errorf("Failed to check billing address existence: %v", err)
 		return nil, err
@@ -286,6 +450,7 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 //	@ ctx: The context for the database operation.
 //	@ memberID: The UUID of the member whose profile is being updated.
 //	@ args: The updated member profile details.
+//	@ version: The version the update was made against, 0 for the version read before the update.
 //
 // Returns:
 //
@@ -293,7 +458,7 @@ func (member *MemberUseCases) AddBillingAddress(ctx *gin.Context, memberID uuid.
 //	@ error: An error, if any, during the database operation.
 //
 // UpdateMember updates a member's profile.
-func (member *MemberUseCases) UpdateMember(ctx *gin.Context, memberID uuid.UUID, args entities.Member) (map[string][]string, error) {
+func (member *MemberUseCases) UpdateMember(ctx *gin.Context, memberID uuid.UUID, args entities.Member, version int64) (map[string][]string, error) {
 	// Initialize a map to store validation errors
 	fieldsMap := map[string][]string{}
 	// Check if the member exists
@@ -310,6 +475,10 @@ func (member *MemberUseCases) UpdateMember(ctx *gin.Context, memberID uuid.UUID,
 		logger.Log().WithContext(ctx).Errorf("Failed to fetch current member details: %v", err)
 		return nil, err
 	}
+	if version != 0 && version != currentMember.Version {
+		logger.Log().WithContext(ctx).Errorf("Profile updation failed, member %s is at version %d instead of %d", memberID, currentMember.Version, version)
+		return nil, consts.ErrVersionMismatch
+	}
 
 	// Flag to track if Country has been updated
 	countryUpdated := args.Country != currentMember.Country.String
@@ -429,13 +598,14 @@ func (member *MemberUseCases) UpdateMember(ctx *gin.Context, memberID uuid.UUID,
 		return fieldsMap, nil
 	}
 
-	// Perform the update operation
-	err = member.repo.UpdateMember(ctx, memberID, args)
+	// Perform the update operation, the validation above relies on the version read before
+	updatedVersion, err := member.repo.UpdateMember(ctx, memberID, args, currentMember.Version)
 
 	if err != nil {
 		logger.Log().WithContext(ctx).Errorf("Profile Updation failed, internal server error: %s", err.Error())
 		return nil, err
 	}
+	ctx.Header(consts.ETag, etag.Format(updatedVersion))
 
 	// If everything is successful, return nil
 	return nil, nil
@@ -524,15 +694,13 @@ func (member *MemberUseCases) GetAllBillingAddresses(ctx *gin.Context, memberID
 //	@ ctxt: The context for the operation.
 //	@ memberID: The UUID of the member for whom the billing address is being updated.
 //	@ billingAddress: The updated billing address details.
-//	@ contextError: A map used to collect error messages related to context validation.
-//	@ endpoint: A string representing the endpoint used for logging purposes.
-//	@ method: A string representing the HTTP method used for logging purposes.
+//	@ version: The version the update was made against, 0 for the version read before the update.
 //
 // Returns:
 //   - If successful, it returns nil (no error).
 //   - If any required fields are missing or there's an error in updating the billing address,
 //     it returns an error with an appropriate error message.
-func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress) (map[string][]string, error) {
+func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, billingAddress entities.BillingAddress, version int64) (map[string][]string, error) {
 
 	// Validate the required fields in the billing address
 	fieldsMap := map[string][]string{}
@@ -570,6 +738,10 @@ func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uu
 		logger.Log().WithContext(ctx).Errorf("Failed to fetch current billing address: %s", err.Error())
 		return nil, err
 	}
+	if version != 0 && version != currentBillingAddress.Version {
+		logger.Log().WithContext(ctx).Errorf("Failed to update billing address, %s is at version %d instead of %d", memberBillingID, currentBillingAddress.Version, version)
+		return nil, consts.ErrVersionMismatch
+	}
 	totalAddress, err := member.repo.CountTotalAddressesForMember(ctx, memberID)
 	if totalAddress == 1 {
 		if currentBillingAddress.Primary && !billingAddress.Primary {
@@ -599,39 +771,38 @@ func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uu
 	// 	}
 	// }
 
-	// Validate the ZIP code , Check if ZIP code length is not between 4-10 characters
+	// Merge the changes into the current address and normalize the result, the postal code and the
+	// state are checked against the rules of the resulting country
+	updatedAddress := *currentBillingAddress
+	if len(billingAddress.Address) != 0 {
+		updatedAddress.Address = billingAddress.Address
+	}
 	if len(billingAddress.Zipcode) != 0 {
-		if len(billingAddress.Zipcode) < 4 || len(billingAddress.Zipcode) > 10 {
-			utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.ZipFormat)
-			logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Invalid ZIP code length")
-		} else {
-			// Validate ZIP code format using utility function
-			if isValidZip := utilities.IsValidZIPCode(billingAddress.Zipcode); !isValidZip {
-				utils.AppendValuesToMap(fieldsMap, consts.Zipcode, consts.Invalid)
-			}
-		}
+		updatedAddress.Zipcode = billingAddress.Zipcode
 	}
 	if len(billingAddress.Country) != 0 {
-		//  Validate country and state
-		countryExists, err := member.repo.CountryExists(billingAddress.Country)
-		if len(billingAddress.Country) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.Country, consts.Required)
-			logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Country cannot be empty")
-			return fieldsMap, nil
-		}
+		updatedAddress.Country = billingAddress.Country
+	}
+	if len(billingAddress.State) != 0 {
+		updatedAddress.State = billingAddress.State
+	}
+	updatedAddress.Primary = billingAddress.Primary
+	updatedAddress = member.addresses.Normalize(updatedAddress)
+
+	if len(billingAddress.Country) != 0 {
+		//  Validate country
+		countryExists, err := member.repo.CountryExists(updatedAddress.Country)
 		if err != nil || !countryExists {
 			utils.AppendValuesToMap(fieldsMap, consts.Country, consts.CountryNotExist)
 			logger.Log().WithContext(ctx).Errorf("Failed to update billing address: Country does not exist")
 			return fieldsMap, nil
 		}
 	}
-	if len(billingAddress.State) != 0 {
-		stateExists, err := member.repo.StateExists(billingAddress.State, billingAddress.Country)
-		if len(billingAddress.State) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.State, consts.Required)
-			logger.Log().WithContext(ctx).Errorf("Failed to add billing address: Country cannot be empty")
-			return fieldsMap, nil
-		}
+	if len(billingAddress.Zipcode) != 0 || len(billingAddress.Country) != 0 || len(billingAddress.State) != 0 {
+		member.addresses.Validate(updatedAddress, fieldsMap)
+	}
+	if len(updatedAddress.State) != 0 && (len(billingAddress.Country) != 0 || len(billingAddress.State) != 0) {
+		stateExists, err := member.repo.StateExists(updatedAddress.State, updatedAddress.Country)
 		if err != nil || !stateExists {
 			utils.AppendValuesToMap(fieldsMap, consts.State, consts.StateNotExist)
 			logger.Log().WithContext(ctx).Errorf("Failed to update billing address: State does not exist")
@@ -640,11 +811,25 @@ func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uu
 	}
 
 	if len(fieldsMap) != 0 {
-		logger.Log().WithContext(ctx).Errorf("Failed to update billing address")
+		logger.Log().WithContext(ctx).Errorf("Failed to update billing address due to validation errors")
+		return fieldsMap, nil
+	}
+
+	// Check if the member already has the updated billing address
+	exists, err = member.repo.BillingAddressExists(ctx, memberID, updatedAddress, memberBillingID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check billing address existence: %v", err)
+		return nil, err
+	}
+	if exists {
+		utils.AppendValuesToMap(fieldsMap, consts.BillingAddress, consts.AlreadyExists)
+		logger.Log().WithContext(ctx).Errorf("Failed to update billing address: This billing address already exists for the member")
+		return fieldsMap, nil
 	}
 
-	// Call the repository function to add the billing address
-	err = member.repo.UpdateBillingAddress(ctx, memberID, memberBillingID, billingAddress)
+	// Call the repository function to update the billing address, the validation above relies on
+	// the version read before
+	updatedVersion, err := member.repo.UpdateBillingAddress(ctx, memberID, memberBillingID, updatedAddress, currentBillingAddress.Version)
 
 	// Check if there was an error
 	if err != nil {
@@ -653,20 +838,23 @@ func (member *MemberUseCases) UpdateBillingAddress(ctx *gin.Context, memberID uu
 
 		return nil, err
 	}
+	ctx.Header(consts.ETag, etag.Format(updatedVersion))
 
 	return fieldsMap, nil
 }
 
 // ChangePassword updates a member's password.
-// This function validates the new password, checks if it meets the minimum length requirement,
-// and verifies that it doesn't match the current password. It then hashes the new password,
-// compares it with the stored hash of the current password, and updates the password in the database.
+// This function checks the reset key, validates the new password against the password policy,
+// and verifies that it doesn't match the current password. The reset key proves the member, the
+// current password is only checked when it is supplied. The repository hashes the new password
+// and updates it in the database.
 //
 // Parameters:
 //   - ctx: The context for the operation.
 //   - memberID: The UUID identifying the member.
+//   - key: The reset key emailed to the member.
 //   - newPassword: The new password to set.
-//   - currentPassword: The current password for validation.
+//   - currentPassword: The current password, optional.
 //
 // Returns:
 //   - A map of field names to error messages, if any validation errors occur.
@@ -685,79 +873,97 @@ func (member *MemberUseCases) ChangePassword(ctx *gin.Context, memberID uuid.UUI
 	if len(key) == 0 {
 		utils.AppendValuesToMap(fieldsMap, consts.Key, consts.Required)
 		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Not long enough")
+		return fieldsMap, nil
+	}
 
+	// Claim the check before the key is compared, claims are refused while the member or the
+	// client IP is locked out
+	clientIP := ctx.ClientIP()
+	locked, err := member.repo.ClaimResetKeyAttempt(ctx, memberID, clientIP)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, unable to claim reset key attempt: %s", err.Error())
+		return nil, err
 	}
-	if len(key) != 16 {
-		utils.AppendValuesToMap(fieldsMap, consts.Key, consts.Match)
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:check key length")
+	if locked {
+		utils.AppendValuesToMap(fieldsMap, consts.Key, consts.Locked)
+		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, too many reset key attempts for member %s from %s", memberID, clientIP)
 		return fieldsMap, nil
-
 	}
+
 	// Use checkResetKeyMatch to verify if the provided key matches the stored key
-	matches, err := member.repo.CheckResetKeyMatch(ctx, memberID, key)
-	// Fetch the reset key value for the member ID
-	keyValue := member.repo.GetResetKey(ctx, memberID)
-	// Check if the keys do not match or if the key is invalidated
-	if !matches || keyValue == "invalidated" {
-		if keyValue == "invalidated" {
-			utils.AppendValuesToMap(fieldsMap, consts.Key, consts.InvalidKey)
-			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, The key is invalid/timeout")
-			return fieldsMap, nil
-		} else {
-			utils.AppendValuesToMap(fieldsMap, consts.Key, consts.Match)
-			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error: Wrong key")
-			return fieldsMap, nil
+	matches := false
+	if len(key) == consts.ResetKeyBytes*2 {
+		matches, err = member.repo.CheckResetKeyMatch(ctx, memberID, key)
+		if err != nil && !errors.Is(err, consts.ErrResetKeyMismatch) && !errors.Is(err, consts.ErrResetKeyExpired) &&
+			!errors.Is(err, consts.ErrResetKeyInvalid) {
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, unable to check reset key: %s", err.Error())
+			_ = member.releaseResetKeyAttempt(ctx, memberID, clientIP)
+			return nil, err
 		}
 	}
-	// Retrieve the hashed current password from the repository
-	// Fetch the hashed current password from the repository
-	hashedCurrentPassword, err := member.repo.GetPasswordHash(ctx, memberID)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, failed to fetch password hash")
-		return nil, fmt.Errorf("failed to fetch hashed password: %w", err)
-	}
 
-	// Hash the provided current password using your cryptoHash package
-	hashedPassword, err := cryptoHash.Hash(currentPassword)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Problem with current password hash")
-		return nil, fmt.Errorf("failed to hash provided password: %w", err)
+	// Only failed checks count towards the lockout
+	if matches {
+		if releaseErr := member.releaseResetKeyAttempt(ctx, memberID, clientIP); releaseErr != nil {
+			return nil, releaseErr
+		}
 	}
 
-	if len(currentPassword) == 0 || currentPassword == " " {
-		utils.AppendValuesToMap(fieldsMap, consts.CurrentPassword, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Current password is empty")
+	if !matches {
+		if errors.Is(err, consts.ErrResetKeyExpired) || errors.Is(err, consts.ErrResetKeyInvalid) {
+			utils.AppendValuesToMap(fieldsMap, consts.Key, consts.InvalidKey)
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, The key is invalid/timeout")
+			return fieldsMap, nil
+		}
+		utils.AppendValuesToMap(fieldsMap, consts.Key, consts.Match)
+		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error: Wrong key")
 		return fieldsMap, nil
 	}
-	if hashedPassword != hashedCurrentPassword {
-		utils.AppendValuesToMap(fieldsMap, consts.CurrentPassword, consts.Incorrect)
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error: Current password is incorrect")
+	// The key proves the member, members who forgot their password leave the current one out
+	if strings.TrimSpace(currentPassword) != "" {
+		// Verify the current password against the stored hash
+		currentMatches, err := member.repo.PasswordMemberRelation(ctx, memberID, currentPassword)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, failed to verify current password: %s", err.Error())
+			return nil, fmt.Errorf("failed to verify current password: %w", err)
+		}
+		if !currentMatches {
+			utils.AppendValuesToMap(fieldsMap, consts.CurrentPassword, consts.Incorrect)
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error: Current password is incorrect")
 
-		// Return the fields map without logging the error (already logged)
-		return fieldsMap, nil
-	}
-	if err != nil {
-		// Log the error if password retrieval fails
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, internal server error: %s", err.Error())
-		return nil, err
+			// Return the fields map without logging the error (already logged)
+			return fieldsMap, nil
+		}
 	}
 
 	// Check if the new password is the same as the current password
-	if newPassword == currentPassword {
+	sameAsCurrent := newPassword == currentPassword
+	if !sameAsCurrent && strings.TrimSpace(currentPassword) == "" {
+		sameAsCurrent, err = member.repo.PasswordMemberRelation(ctx, memberID, newPassword)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, failed to compare with the current password: %s", err.Error())
+			return nil, fmt.Errorf("failed to compare with the current password: %w", err)
+		}
+	}
+	if sameAsCurrent {
 		utils.AppendValuesToMap(fieldsMap, consts.NewPassword, consts.InvalidPassword)
 		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error: New password is the same as the current password")
 
 		// Return the fields map without logging the error (already logged)
 		return fieldsMap, nil
 	}
-	// Check if the new password is empty or its length is less than 8
-	if len(newPassword) < 8 || newPassword == "" {
-		utils.AppendValuesToMap(fieldsMap, consts.NewPassword, consts.MinLength)
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Not long enough")
-		return fieldsMap, nil
-	}
-	// Additional password format validation (you can customize this)
+	// Validate the new password against the password policy
 	if err := utilities.ValidatePassword(newPassword); err != nil {
+		if errors.Is(err, utilities.ErrPasswordTooShort) {
+			utils.AppendValuesToMap(fieldsMap, consts.NewPassword, consts.MinLength)
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Not long enough")
+			return fieldsMap, nil
+		}
+		if errors.Is(err, utilities.ErrPasswordBreached) {
+			utils.AppendValuesToMap(fieldsMap, consts.NewPassword, consts.Breached)
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Password is breached")
+			return fieldsMap, nil
+		}
 		utils.AppendValuesToMap(fieldsMap, consts.NewPassword, consts.Format)
 		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, validation error:Doesnot satisfy required format")
 		return fieldsMap, nil
@@ -765,13 +971,14 @@ func (member *MemberUseCases) ChangePassword(ctx *gin.Context, memberID uuid.UUI
 	}
 
 	if len(fieldsMap) == 0 {
-		// Hash the new password
-		hashedNewPassword, err := cryptoHash.Hash(newPassword)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, Failed to update password: %s", err.Error())
-			return nil, err
+		// The repository hashes the new password with the configured algorithm
+		err = member.repo.UpdatePassword(ctx, memberID, key, newPassword)
+		// The key was used by a concurrent request or expired since it was checked
+		if errors.Is(err, consts.ErrResetKeyInvalid) {
+			utils.AppendValuesToMap(fieldsMap, consts.Key, consts.InvalidKey)
+			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, The key is invalid/timeout")
+			return fieldsMap, nil
 		}
-		err = member.repo.UpdatePassword(ctx, memberID, key, hashedNewPassword)
 		if err != nil {
 			logger.Log().WithContext(ctx).Errorf("ChangePassword failed, internal server error: Failed to update password: %s", err.Error())
 			return nil, err
@@ -781,6 +988,16 @@ func (member *MemberUseCases) ChangePassword(ctx *gin.Context, memberID uuid.UUI
 	return fieldsMap, nil
 }
 
+// releaseResetKeyAttempt gives back a reset key check claimed by ChangePassword whose key
+// matched or could not be checked.
+func (member *MemberUseCases) releaseResetKeyAttempt(ctx *gin.Context, memberID uuid.UUID, clientIP string) error {
+	err := member.repo.ReleaseResetKeyAttempt(ctx, memberID, clientIP)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, unable to release reset key attempt of member %s from %s: %s", memberID, clientIP, err.Error())
+	}
+	return err
+}
+
 // IsMemberExists checks if a member with the given memberId exists.
 // Parameters:
 //
@@ -814,6 +1031,62 @@ func (member *MemberUseCases) IsMemberExists(memberId uuid.UUID, ctx context.Con
 func (member *MemberUseCases) RegisterMember(ctxt context.Context, args entities.Member, contextError map[string]any, partnerID, endpoint,
 	method string) (map[string][]string, error) {
 
+	fieldsMap, err := member.validateRegistration(ctxt, args, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(fieldsMap) == 0 {
+		err = member.registerMember(ctxt, args, partnerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fieldsMap, nil
+}
+
+// validateRegistration applies the registration rules to a new member of the partner: names,
+// email and its uniqueness within the partner, password policy, country and state existence,
+// phone number format and provider.
+func (member *MemberUseCases) validateRegistration(ctxt context.Context, args entities.Member, partnerID string) (map[string][]string, error) {
+	fieldsMap, err := member.validateMemberDetails(ctxt, args, partnerID)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range passwordErrors(args) {
+		fieldsMap[key] = append(fieldsMap[key], values...)
+	}
+	return fieldsMap, nil
+}
+
+// passwordErrors applies the password policy to the password of a new member of the internal
+// provider.
+func passwordErrors(args entities.Member) map[string][]string {
+	fieldsMap := map[string][]string{}
+	if args.Provider != consts.ProviderInternal {
+		return fieldsMap
+	}
+
+	if args.Password == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Required)
+	}
+
+	// Validate the password.
+	passwordErr := utilities.ValidatePassword(args.Password)
+	if errors.Is(passwordErr, utilities.ErrPasswordTooShort) {
+		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.MinLengthPassword)
+	} else if errors.Is(passwordErr, utilities.ErrPasswordBreached) {
+		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Breached)
+	} else if passwordErr != nil {
+		// Append values to the map based on the password error.
+		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Format)
+	}
+	return fieldsMap
+}
+
+// validateMemberDetails applies the registration rules but the password policy. Bulk imports
+// validate every row with it, the password of a row was checked at upload.
+func (member *MemberUseCases) validateMemberDetails(ctxt context.Context, args entities.Member, partnerID string) (map[string][]string, error) {
 	fieldsMap := map[string][]string{}
 
 	if args.FirstName != "" && !utilities.ValidateMaximumNameLength(args.FirstName) {
@@ -857,25 +1130,6 @@ func (member *MemberUseCases) RegisterMember(ctxt context.Context, args entities
 
 	}
 
-	if args.Provider == consts.ProviderInternal && args.Password == "" {
-		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Required)
-	}
-
-	if args.Provider == consts.ProviderInternal && len(args.Password) < 8 {
-		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.MinLengthPassword)
-	}
-
-	// Validate the password.
-	passwordErr := utilities.ValidatePassword(args.Password)
-	if args.Provider == consts.ProviderInternal {
-		// Check if the provider is internal and there is a password validation error.
-		if passwordErr != nil {
-			// Append values to the map based on the password error.
-			utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Format)
-
-		}
-	}
-
 	if args.Provider == consts.ProviderInternal && !args.TermsConditionChecked {
 		utils.AppendValuesToMap(fieldsMap, consts.TermsAndConditions, consts.Required)
 	}
@@ -885,6 +1139,30 @@ func (member *MemberUseCases) RegisterMember(ctxt context.Context, args entities
 
 	}
 
+	if args.Country != "" {
+		countryExists, err := member.repo.CountryExists(args.Country)
+		if err != nil {
+			logger.Log().WithContext(ctxt).Errorf("Failed to check country existence: %s", err.Error())
+			return nil, err
+		}
+		if !countryExists {
+			utils.AppendValuesToMap(fieldsMap, consts.Country, consts.CountryNotExist)
+		} else if args.State != "" {
+			stateExists, err := member.repo.StateExists(args.State, args.Country)
+			if err != nil {
+				logger.Log().WithContext(ctxt).Errorf("Failed to check state existence: %s", err.Error())
+				return nil, err
+			}
+			if !stateExists {
+				utils.AppendValuesToMap(fieldsMap, consts.State, consts.StateNotExist)
+			}
+		}
+	}
+
+	if args.Phone != "" && !utilities.IsValidPhoneNumber(args.Phone, args.Country) {
+		utils.AppendValuesToMap(fieldsMap, consts.PhoneNumber, consts.Format)
+	}
+
 	existsOrNot, err := member.repo.ProviderExists(ctxt, args.Provider)
 
 	if !existsOrNot {
@@ -895,18 +1173,65 @@ func (member *MemberUseCases) RegisterMember(ctxt context.Context, args entities
 		logger.Log().WithContext(ctxt).Errorf("Failed to check if provider exists: %s", err.Error())
 		return nil, err
 	}
-	if len(fieldsMap) == 0 {
-		err := member.repo.RegisterMember(ctxt, args, partnerID)
-		if err != nil {
-			// Convert the repository error to a string for better handling.
-			repoErrorStr := err.Error()
+	return fieldsMap, nil
+}
 
-			logger.Log().WithContext(ctxt).Errorf("Failed to register a member: err=%s", err.Error())
-			return nil, fmt.Errorf("registration failed: %s", repoErrorStr)
-		}
+// registerMember stores a validated member and sends the welcome notification along with
+// the verification token of the email address.
+func (member *MemberUseCases) registerMember(ctxt context.Context, args entities.Member, partnerID string) error {
+	memberID, err := member.repo.RegisterMember(ctxt, args, partnerID)
+	if err != nil {
+		logger.Log().WithContext(ctxt).Errorf("Failed to register a member: err=%s", err.Error())
+		return fmt.Errorf("registration failed: %w", err)
 	}
+	member.welcomeMember(ctxt, memberID, args)
+	return nil
+}
 
-	return fieldsMap, nil
+// welcomeMember notifies a registered member and sends the email verification. Failures are
+// only logged, the member is registered.
+func (member *MemberUseCases) welcomeMember(ctxt context.Context, memberID uuid.UUID, args entities.Member) {
+	err := member.notifier.Notify(ctxt, entities.Notification{
+		Event:    consts.EventMemberRegistered,
+		To:       args.Email,
+		Language: args.Language,
+		Data: map[string]interface{}{
+			"Name":  args.FirstName,
+			"Email": args.Email,
+		},
+	})
+	if err != nil {
+		logger.Log().WithContext(ctxt).Errorf("Failed to send registration notification: %s", err.Error())
+	}
+
+	err = member.sendEmailVerification(ctxt, entities.EmailVerification{
+		MemberID: memberID,
+		Name:     args.FirstName,
+		Email:    args.Email,
+		Language: args.Language,
+	})
+	if err != nil {
+		logger.Log().WithContext(ctxt).Errorf("Failed to send email verification to member %s: %s", memberID, err.Error())
+	}
+}
+
+// sendEmailVerification issues a verification token for the member's email and sends it to
+// that address.
+func (member *MemberUseCases) sendEmailVerification(ctx context.Context, state entities.EmailVerification) error {
+	token, _, err := member.verifier.Issue(state.MemberID, state.Email, time.Now())
+	if err != nil {
+		return err
+	}
+	return member.notifier.Notify(ctx, entities.Notification{
+		Event:    consts.EventEmailVerification,
+		To:       state.Email,
+		Language: state.Language,
+		Data: map[string]interface{}{
+			"Name":      state.Name,
+			"Token":     token,
+			"ExpiresIn": int(member.verifier.TTL().Hours()),
+		},
+	})
 }
 
 // ViewMemberProfile retrieves a member's profile and checks if the member exists.
@@ -930,16 +1255,16 @@ func (member *MemberUseCases) ViewMemberProfile(ctx *gin.Context, ctxt context.C
 
 	exists, err := member.repo.IsMemberExists(memberId, ctx)
 
-	if !exists {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Exists)
-		return fieldsMap, memberProfile, nil
-	}
-
 	if err != nil {
 		logger.Log().WithContext(ctxt).Errorf("Checking member exists failed: err=%s", err.Error())
 		return nil, memberProfile, err
 	}
 
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Exists)
+		return fieldsMap, memberProfile, nil
+	}
+
 	if len(fieldsMap) == 0 {
 		memberProfile, err = member.repo.ViewMemberProfile(memberId, ctxt)
 
@@ -949,6 +1274,16 @@ func (member *MemberUseCases) ViewMemberProfile(ctx *gin.Context, ctxt context.C
 			return nil, memberProfile, err
 		}
 
+		// Admins also see whether the member is locked out of password logins
+		if isAdmin(ctx) {
+			loginLockout, _, err := member.repo.GetLoginLockouts(ctxt, memberId, "")
+			if err != nil {
+				logger.Log().WithContext(ctxt).Errorf("View member profile failed, unable to load login lockout: %s", err.Error())
+				return nil, memberProfile, err
+			}
+			memberProfile.LoginLockout = &loginLockout
+		}
+
 	}
 
 	return fieldsMap, memberProfile, nil
@@ -1106,883 +1441,3669 @@ func (member *MemberUseCases) ViewMembers(ctx *gin.Context, params entities.Para
 	return memberData, *metadata, nil
 }
 
-// GetBasicMemberDetailsByEmail retrieves basic details of a member based on their email.
-//
-// This function performs validation on the input arguments and returns any validation errors
-// in a map, along with the basic member details and an error if the database operation fails.
-//
-// Parameters:
-//   - ctxt (context.Context): The context for the database operation.
-//   - args (entities.MemberPayload): An instance of entities.MemberPayload containing member information.
-//   - contextError (map[string]any): A map to collect validation errors.
-//   - endpoint (string): The endpoint for the request.
-//   - method (string): The HTTP method used for the request.
-//
-// Returns:
-//   - fieldsMap (map[string][]string): A map containing validation errors, if any, for different fields.
-//   - memberBasic (entities.BasicMemberData): An instance of entities.BasicMemberData representing basic member details.
-//   - error: An error, if any, encountered during the database operation.
-func (member *MemberUseCases) GetBasicMemberDetailsByEmail(ctx *gin.Context, partnerID string, args entities.MemberPayload, contextError map[string]any, endpoint string,
-	method string) (map[string][]string, entities.BasicMemberData, error) {
-	fieldsMap := map[string][]string{}
-	var (
-		memberBasic entities.BasicMemberData
-		err         error
-	)
-	validEmailProvider, err := member.repo.CheckEmailProviderRelation(ctx, args.Email, args.Provider)
+// ListMembers returns a page of the members matching the listing parameters, in keyset order.
+// The page starts after the position of params.Cursor, and the returned metadata holds the
+// cursor of the next page when there is one. Invalid parameters are reported as validation errors.
+func (member *MemberUseCases) ListMembers(ctx *gin.Context, params entities.MemberListParams) ([]entities.ViewMembers, entities.MemberListMetaData, map[string][]string, error) {
+	filter, validationErrors := memberListFilter(params)
+	if len(validationErrors) != 0 {
+		return nil, entities.MemberListMetaData{}, validationErrors, nil
+	}
 
-	if !validEmailProvider {
-		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.InValidProvider)
+	total, err := member.repo.CountMembers(ctx, filter)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListMembers failed, CountMembers failed, err=%s", err.Error())
+		return nil, entities.MemberListMetaData{}, nil, err
 	}
+
+	members, next, err := member.repo.ListMembers(ctx, filter)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("View basic member details failed: %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("ListMembers failed, err=%s", err.Error())
+		return nil, entities.MemberListMetaData{}, nil, err
 	}
-	if args.Email == "" {
-		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Required)
+
+	metadata := entities.MemberListMetaData{
+		PerPage: filter.Limit,
+		Total:   total,
 	}
-	if args.Provider != consts.ProviderSpotify {
-		if !utilities.ValidateEmail(args.Email) {
-			utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Valid)
+	if next != nil {
+		metadata.NextCursor, err = cursor.Encode(next)
+		if err != nil {
+			return nil, entities.MemberListMetaData{}, nil, err
 		}
 	}
-	if args.Provider != consts.ProviderSpotify {
-		if args.Provider == consts.ProviderInternal && args.Password == "" {
-			utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Required)
-		}
 
-	}
-	if args.Provider == consts.ProviderInternal && len(args.Password) < 8 {
-		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.MinLengthPassword)
-	}
+	return members, metadata, nil, nil
+}
 
-	if passwordErr := utilities.ValidatePassword(args.Password); args.Provider == consts.ProviderInternal && passwordErr != nil {
-		utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Format)
+// memberListFilter validates the member listing parameters and returns them as a filter.
+func memberListFilter(params entities.MemberListParams) (entities.MemberListFilter, map[string][]string) {
+	validationErrors := make(map[string][]string)
+	filter := entities.MemberListFilter{
+		Limit:  params.Limit,
+		Sort:   strings.ToLower(strings.TrimSpace(params.Sort)),
+		Order:  strings.ToLower(strings.TrimSpace(params.Order)),
+		Search: strings.TrimSpace(params.Search),
+	}
+
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = consts.DefaultLimit
+	case filter.Limit < 0 || filter.Limit > consts.MaximumLimit:
+		utils.AppendValuesToMap(validationErrors, consts.Limit, consts.Invalid)
+	}
+
+	switch filter.Sort {
+	case "":
+		filter.Sort = consts.MemberSortCreatedOn
+	case consts.MemberSortCreatedOn, consts.MemberSortEmail:
+	default:
+		utils.AppendValuesToMap(validationErrors, consts.Sort, consts.Invalid)
+	}
+
+	switch filter.Order {
+	case "":
+		// Newest members first, emails alphabetically.
+		filter.Order = consts.OrderDesc
+		if filter.Sort == consts.MemberSortEmail {
+			filter.Order = consts.OrderAsc
+		}
+	case consts.OrderAsc, consts.OrderDesc:
+	default:
+		utils.AppendValuesToMap(validationErrors, consts.Order, consts.Invalid)
+	}
+
+	if params.Cursor != "" {
+		var after entities.MemberCursor
+		valid := cursor.Decode(params.Cursor, &after) == nil &&
+			after.Sort == filter.Sort && after.Order == filter.Order && after.ID != uuid.Nil
+		if valid && after.Sort == consts.MemberSortCreatedOn {
+			_, err := time.Parse(time.RFC3339Nano, after.Value)
+			valid = err == nil
+		}
+		if valid {
+			filter.After = &after
+		} else {
+			utils.AppendValuesToMap(validationErrors, consts.Cursor, consts.Invalid)
+		}
 	}
 
-	if len(fieldsMap) == 0 {
-		memberBasic, err = member.repo.GetBasicMemberDetailsByEmail(partnerID, args, ctx)
-
+	if params.Active != "" {
+		active, err := strconv.ParseBool(params.Active)
 		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("View basic member details failed: %s", err.Error())
-			return nil, memberBasic, err
+			utils.AppendValuesToMap(validationErrors, consts.Active, consts.Invalid)
+		} else {
+			filter.Active = &active
 		}
-
 	}
 
-	return fieldsMap, memberBasic, nil
-}
-
-//Reset Password initiation
-
-// InitiatePasswordReset initiates the password reset process for a member.
-func (member *MemberUseCases) InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (string, map[string][]string, error) {
-	fieldsMap := make(map[string][]string)
-	var key string
-	var err error // Declare the err variable here
-	exists, err := member.repo.IsMemberExists(memberID, ctx)
-	if !exists {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Exists)
-		logger.Log().WithContext(ctx).Errorf("ChangePassword failed, Member not found")
-		return "", fieldsMap, nil
-	}
-	validEmail, err := member.repo.CheckEmailForMemberID(ctx, memberID, email)
-	if !validEmail {
-		utils.AppendValuesToMap(fieldsMap, consts.ResetEmail, consts.InvalidEmail)
-		logger.Log().WithContext(ctx).Errorf("Reset Password failed, validation error: No email found")
-		return "", fieldsMap, nil
-	}
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Reset Password failed due to database error: %s", err.Error())
-		return "", nil, err
+	for _, role := range splitListParam(params.Roles) {
+		roleID, err := strconv.Atoi(role)
+		if err != nil {
+			utils.AppendValuesToMap(validationErrors, consts.Role, consts.Invalid)
+			break
+		}
+		filter.Roles = append(filter.Roles, roleID)
 	}
-	// Validate email presence
-	if len(email) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.ResetEmail, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Reset Password failed, validation error: No email found")
-		return "", fieldsMap, nil // Return early if email is empty
+
+	for _, country := range splitListParam(params.Countries) {
+		country = strings.ToUpper(country)
+		if !countryCodePattern.MatchString(country) {
+			utils.AppendValuesToMap(validationErrors, consts.Country, consts.Invalid)
+			break
+		}
+		filter.Countries = append(filter.Countries, country)
 	}
 
-	// Validate email format
-	if !utilities.ValidateEmail(email) {
-		utils.AppendValuesToMap(fieldsMap, consts.ResetEmail, consts.Format)
-		logger.Log().WithContext(ctx).Errorf("Reset Password failed, validation error: Invalid email format")
-		return "", fieldsMap, nil // Return early if email format is invalid
+	if params.Partner != "" {
+		partnerID, err := uuid.Parse(params.Partner)
+		if err != nil {
+			utils.AppendValuesToMap(validationErrors, consts.Partner, consts.Invalid)
+		} else {
+			filter.Partner = partnerID
+		}
 	}
 
-	// If no validation errors, proceed to reset password
-	key, err = member.repo.InitiatePasswordReset(ctx, memberID, email)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Resetting password failed: %s", err.Error())
-		return key, fieldsMap, err // Ensure you return the error here
+	var err error
+	if params.CreatedFrom != "" {
+		if filter.CreatedFrom, err = parseListTime(params.CreatedFrom, false); err != nil {
+			utils.AppendValuesToMap(validationErrors, consts.CreatedFrom, consts.Invalid)
+		}
+	}
+	if params.CreatedTo != "" {
+		if filter.CreatedTo, err = parseListTime(params.CreatedTo, true); err != nil {
+			utils.AppendValuesToMap(validationErrors, consts.CreatedTo, consts.Invalid)
+		}
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		utils.AppendValuesToMap(validationErrors, consts.CreatedTo, consts.Invalid)
 	}
 
-	// Return the generated key and no validation errors
-	return key, nil, nil
+	return filter, validationErrors
 }
 
-// HandleSubscriptionCheckout handles the checkout of a subscription for a member.
-// It takes the
-//   - context
-//   - memberID
-//   - checkoutData
-//     as parameters.
-//
-// It returns a map with subscription details and any encountered errors.
-func (member *MemberUseCases) HandleSubscriptionCheckout(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription, partnerIDStr string) (map[string][]string, error) {
-	fieldsMap := map[string][]string{}
-	// Create a map to store the result
-	resultMap := make(map[string][]string)
+// countryCodePattern matches ISO 3166-1 alpha-2 country codes.
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
 
-	subExists, isActive, err := member.repo.CheckSubscriptionExistenceAndStatusForCheckout(ctx, checkoutData.SubscriptionID)
-	// Ensure the provided partnerID matches the partnerID associated with the member
-	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
-		return nil, err
-	}
+// promoCodePattern matches upper case promo codes of letters, digits, dashes and underscores.
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
 
-	if !isPartnerValid {
-		// Partner authentication failed
-		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
-		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
-		return fieldsMap, nil
-	}
-	if !subExists {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid subscription plan")
-		return fieldsMap, nil
+// splitListParam flattens repeated and comma separated query values.
+func splitListParam(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
 	}
-	if !isActive {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Inactive)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Inactive subscription plan")
-		return fieldsMap, nil
+	return items
+}
+
+// parseListTime parses an RFC 3339 time or a date. A date used as the exclusive end of a
+// range covers the whole day.
+func parseListTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
 	}
+	t, err := time.Parse(time.DateOnly, value)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: %s", err.Error())
-	}
-	// Check if SubscriptionID is empty
-	if checkoutData.SubscriptionID == "" || len(checkoutData.SubscriptionID) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Missing required fields")
-		return fieldsMap, nil
-	}
-	if len(checkoutData.SubscriptionID) > 36 {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.TooLong)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Subscription Id too long/exceeds limit")
-	}
-	if len(checkoutData.CustomName) > 60 {
-		utils.AppendValuesToMap(fieldsMap, consts.CustonName, consts.TooLong)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Custom name too long")
-		return fieldsMap, nil
+		return time.Time{}, err
 	}
-	// Fetch the count of subscriptions for the member within the last year
-	count, err := member.repo.GetSubscriptionCountForLastYear(ctx, memberID, checkoutData.SubscriptionID)
-
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Failed to get members subscription count in last year")
+	if end {
+		t = t.AddDate(0, 0, 1)
 	}
+	return t, nil
+}
 
-	// Fetch the maximum subscription limit for the given subscription ID
-	maxCount, err := member.repo.GetMaxSubscriptionLimitForID(ctx, checkoutData.SubscriptionID)
+// GetBasicMemberDetailsByEmail retrieves basic details of a member based on their email.
+//
+// This function performs validation on the input arguments and returns any validation errors
+// in a map, along with the basic member details and an error if the database operation fails.
+//
+// Parameters:
+//   - ctxt (context.Context): The context for the database operation.
+//   - args (entities.MemberPayload): An instance of entities.MemberPayload containing member information.
+//   - contextError (map[string]any): A map to collect validation errors.
+//   - endpoint (string): The endpoint for the request.
+//   - method (string): The HTTP method used for the request.
+//
+// Returns:
+//   - fieldsMap (map[string][]string): A map containing validation errors, if any, for different fields.
+//   - memberBasic (entities.BasicMemberData): An instance of entities.BasicMemberData representing basic member details.
+//   - error: An error, if any, encountered during the database operation.
+func (member *MemberUseCases) GetBasicMemberDetailsByEmail(ctx *gin.Context, partnerID string, args entities.MemberPayload, contextError map[string]any, endpoint string,
+	method string) (map[string][]string, entities.BasicMemberData, error) {
+	fieldsMap := map[string][]string{}
+	var (
+		memberBasic entities.BasicMemberData
+		err         error
+	)
+	validEmailProvider, err := member.repo.CheckEmailProviderRelation(ctx, args.Email, args.Provider)
 
+	if !validEmailProvider {
+		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.InValidProvider)
+	}
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Failed to get maximum allowed count per yearr")
-
+		logger.Log().WithContext(ctx).Errorf("View basic member details failed: %s", err.Error())
 	}
-	if count == maxCount {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.LimitReached)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Reached maximum allowed subscription in an year")
-		return fieldsMap, nil
+	if args.Email == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Required)
 	}
-	// If the subscription is free, skip the PaymentGatewayID check
-	isFree, err := member.repo.IsFreeSubscription(ctx, checkoutData.SubscriptionID)
-
-	if err != nil {
-		if err.Error() == "subscription does not exist" {
-			logger.Log().WithContext(ctx).Errorf("Invalid Subscription Id : %s", err.Error())
-			return nil, err
-		} else {
-			logger.Log().WithContext(ctx).Errorf("Failed to check if the subscription is free: %s", err.Error())
-			return nil, err
+	if args.Provider != consts.ProviderSpotify {
+		if !utilities.ValidateEmail(args.Email) {
+			utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Valid)
 		}
 	}
-	// Continue with the rest of the code, including PaymentGatewayID check, if it's not free
-	if !isFree {
-		if checkoutData.PaymentGatewayID == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Required)
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Missing required fields")
-			return fieldsMap, nil
+	if args.Provider != consts.ProviderSpotify {
+		if args.Provider == consts.ProviderInternal && args.Password == "" {
+			utils.AppendValuesToMap(fieldsMap, consts.Password, consts.Required)
 		}
 
-		gatewayExists, _ := member.repo.CheckIfPayoutGatewayExists(ctx, checkoutData.PaymentGatewayID)
+	}
 
-		if !gatewayExists {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Invalid)
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid gateway")
-			return fieldsMap, nil
+	if len(fieldsMap) == 0 {
+		// Password logins are throttled per member and per client IP
+		var (
+			loginMemberID uuid.UUID
+			clientIP      = ctx.ClientIP()
+		)
+		if args.Provider == consts.ProviderInternal {
+			loginMemberID, err = member.repo.GetMemberIDByEmail(ctx, partnerID, args.Email)
+			if err != nil {
+				logger.Log().WithContext(ctx).Errorf("View basic member details failed, unable to look up member: %s", err.Error())
+				return nil, memberBasic, err
+			}
+			if blocked, err := member.claimLoginAttempt(ctx, loginMemberID, clientIP); err != nil || blocked {
+				if blocked {
+					utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Locked)
+				}
+				return fieldsMap, memberBasic, err
+			}
 		}
-		PartnerGatewayExists, err := member.repo.IsPartnerIdCorrespondsToGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
 
-		if !PartnerGatewayExists {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NoRelation)
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: This payment gateway is not endorsed by partner")
-			return fieldsMap, nil
-		}
+		memberBasic, err = member.repo.GetBasicMemberDetailsByEmail(partnerID, args, ctx)
 
 		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan:error checking if member subscribed free plan")
+			logger.Log().WithContext(ctx).Errorf("View basic member details failed: %s", err.Error())
+			if args.Provider == consts.ProviderInternal {
+				_ = member.releaseLoginAttempt(ctx, loginMemberID, clientIP)
+			}
+			return nil, memberBasic, err
 		}
-		if PartnerGatewayExists {
-			// If the partner ID corresponds to the payment gateway, retrieve payment details.
-			paymentDetails, err := member.repo.GetPaymentDetailsByPartnerAndGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
-			if err != nil {
-				logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan:payment details are NULL for partnerID")
-				return nil, err
+
+		if args.Provider == consts.ProviderInternal {
+			if memberBasic.MemberID == uuid.Nil {
+				if err := member.repo.RecordLoginFailure(ctx, loginMemberID, clientIP); err != nil {
+					logger.Log().WithContext(ctx).Errorf("View basic member details failed, unable to record login attempt: %s", err.Error())
+					return nil, entities.BasicMemberData{}, err
+				}
+				return fieldsMap, memberBasic, nil
 			}
-			detailsString, err := member.repo.DecryptPaymentData(ctx, paymentDetails)
-			if err != nil {
-				return nil, err
+			if err := member.releaseLoginAttempt(ctx, loginMemberID, clientIP); err != nil {
+				return nil, entities.BasicMemberData{}, err
 			}
+		}
 
-			var paymentInfo entities.PaymentGatewayDetails
-
-			// Unmarshal JSON string into the PaymentGatewayDetails struct
-			err = json.Unmarshal([]byte(detailsString), &paymentInfo)
+		// The partner may require a verified email before the member can log in
+		if memberBasic.MemberID != uuid.Nil {
+			state, err := member.repo.GetEmailVerification(ctx, memberBasic.MemberID)
 			if err != nil {
-				return nil, err
+				logger.Log().WithContext(ctx).Errorf("View basic member details failed, unable to load email verification: %s", err.Error())
+				return nil, entities.BasicMemberData{}, err
 			}
-			if !paymentInfo.Payin {
-				utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NoPayin)
-				logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: pay-in transaction is not supported by this payment gateway.")
-				return fieldsMap, nil
+			if state.BlocksLogin() {
+				utils.AppendValuesToMap(fieldsMap, consts.Email, consts.NotVerified)
+				return fieldsMap, entities.BasicMemberData{}, nil
 			}
-
 		}
-		if checkoutData.PaymentGatewayID > consts.MaxInt {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.TooLong)
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid gateway id")
-			return fieldsMap, nil
+
+		if args.Provider == consts.ProviderInternal {
+			// Members with two-factor authentication get a challenge instead of their details,
+			// the lockout is only cleared once the challenge is completed
+			challenge, required, err := member.twoFactorChallenge(ctx, memberBasic)
+			if err != nil || required {
+				return nil, challenge, err
+			}
+			if err := member.repo.ClearLoginLockout(ctx, memberBasic.MemberID); err != nil {
+				logger.Log().WithContext(ctx).Errorf("View basic member details failed, unable to record login attempt: %s", err.Error())
+				return nil, entities.BasicMemberData{}, err
+			}
 		}
 	}
 
-	subscribedOnetime, err := member.repo.HasSubscribedToOneTimePlan(ctx, memberID, checkoutData.SubscriptionID)
+	return fieldsMap, memberBasic, nil
+}
 
-	if subscribedOnetime {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.SubscribedOnce)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: This is is one time plan")
+// VerifyEmail verifies the email address of the member the token was issued to. Tokens
+// issued for an email the member no longer uses are rejected, verifying an already verified
+// email succeeds.
+func (member *MemberUseCases) VerifyEmail(ctx *gin.Context, token string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	if strings.TrimSpace(token) == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Token, consts.Required)
 		return fieldsMap, nil
 	}
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan:Error checking This is is one time plan")
+
+	claims, err := member.verifier.Verify(token, time.Now())
+	if errors.Is(err, verification.ErrExpired) {
+		utils.AppendValuesToMap(fieldsMap, consts.Token, consts.Expired)
+		return fieldsMap, nil
 	}
-	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
-	if len(fieldsMap) > 0 {
+	if err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.Token, consts.Invalid)
 		return fieldsMap, nil
 	}
 
-	// Handle the subscription checkout by calling the HandleSubscriptionCheckout method from the repository.
-	err = member.repo.HandleSubscriptionCheckout(ctx, memberID, checkoutData)
-
-	// Check if there was an error during the checkout process.
+	// The signed token names the member, links opened without a partner look it up across partners
+	if _, ok := tenant.PartnerFrom(ctx); !ok {
+		tenant.ScopeAll(ctx)
+	}
+	state, err := member.repo.GetEmailVerification(ctx, claims.MemberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.Token, consts.Invalid)
+		return fieldsMap, nil
+	}
 	if err != nil {
-		resultMap["error"] = []string{err.Error()}
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout Subscription: %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("Verify email failed, unable to load email verification: %s", err.Error())
 		return nil, err
 	}
+	if !claims.Matches(state.Email) {
+		utils.AppendValuesToMap(fieldsMap, consts.Token, consts.Invalid)
+		return fieldsMap, nil
+	}
+	if state.VerifiedAt != nil {
+		return nil, nil
+	}
 
-	return fieldsMap, nil
+	if err := member.repo.MarkEmailVerified(ctx, state.MemberID, state.Email); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Verify email failed for member %s: %s", state.MemberID, err.Error())
+		return nil, err
+	}
+	return nil, nil
 }
 
-// HandleSubscriptionRenewal handles the checkout of a subscription for a member.
-// It takes the
-//   - context
-//   - memberID
-//   - checkoutData
-//     as parameters.
-//
-// It returns a map with subscription details and any encountered errors.
-func (member *MemberUseCases) HandleSubscriptionRenewal(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.SubscriptionRenewal, partnerIDStr string) (map[string][]string, error) {
+// ResendEmailVerification sends a new verification token to the partner's member registered
+// with the email. Nothing is sent for unknown or already verified addresses, and the caller
+// is not told so, the endpoint must not reveal which addresses are registered.
+func (member *MemberUseCases) ResendEmailVerification(ctx *gin.Context, partnerID string, email string) (map[string][]string, error) {
 	fieldsMap := map[string][]string{}
-
-	// Ensure the provided partnerID matches the partnerID associated with the member.
-	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
-		return nil, err
+	if email == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Required)
+	} else if !utilities.ValidateEmail(email) {
+		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Format)
 	}
-	if !isPartnerValid {
-		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
-		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
+	if _, err := uuid.Parse(partnerID); err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.Invalid)
+	}
+	if len(fieldsMap) != 0 {
 		return fieldsMap, nil
 	}
 
-	//CheckSubscriptionExistenceAndStatusForRenewal fetches the subscription plan from member subscription  id and check
-	//if such a plan exists and is currently active
-	subExists, isActive, err := member.repo.CheckSubscriptionExistenceAndStatusForRenewal(ctx, checkoutData.MemberSubscriptionID)
-	if !subExists {
+	state, err := member.repo.GetEmailVerificationByEmail(ctx, partnerID, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed: %s", err.Error())
+		return nil, err
+	}
+	if state.VerifiedAt != nil {
+		return nil, nil
+	}
+
+	if err := member.sendEmailVerification(ctx, state); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resend email verification failed for member %s: %s", state.MemberID, err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// checkPartnerAccess validates that the caller may manage the partner, like its email
+// verification policy. Partner admins are scoped to their own partner.
+func (member *MemberUseCases) checkPartnerAccess(ctx *gin.Context, partnerID uuid.UUID) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	if scoped, ok := tenant.PartnerFrom(ctx); ok && scoped != partnerID {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.NotFound)
+		return fieldsMap, nil
+	}
+	exists, err := member.repo.CheckPartnerIDExists(ctx, partnerID.String())
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Partner access check failed, unable to check partner %s: %s", partnerID, err.Error())
+		return nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.NotFound)
+	}
+	return fieldsMap, nil
+}
+
+// GetEmailVerificationPolicy returns the email verification policy of the partner.
+func (member *MemberUseCases) GetEmailVerificationPolicy(ctx *gin.Context, partnerID uuid.UUID) (entities.EmailVerificationPolicy, map[string][]string, error) {
+	fieldsMap, err := member.checkPartnerAccess(ctx, partnerID)
+	if err != nil || len(fieldsMap) != 0 {
+		return entities.EmailVerificationPolicy{}, fieldsMap, err
+	}
+	policy, err := member.repo.GetEmailVerificationPolicy(ctx, partnerID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Get email verification policy failed for partner %s: %s", partnerID, err.Error())
+		return policy, nil, err
+	}
+	return policy, nil, nil
+}
+
+// UpdateEmailVerificationPolicy stores the email verification policy of the partner.
+func (member *MemberUseCases) UpdateEmailVerificationPolicy(ctx *gin.Context, partnerID uuid.UUID, policy entities.EmailVerificationPolicy) (map[string][]string, error) {
+	fieldsMap, err := member.checkPartnerAccess(ctx, partnerID)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, err
+	}
+	if err := member.repo.UpdateEmailVerificationPolicy(ctx, partnerID, policy); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Update email verification policy failed for partner %s: %s", partnerID, err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// claimLoginAttempt counts a password or two-factor login attempt of the member from the client
+// IP before its credentials are checked, and reports whether the attempt has to wait for a
+// progressive delay, a lockout or the attempts in flight to pass. Claimed attempts are settled
+// with RecordLoginFailure or given back with releaseLoginAttempt. The Retry-After header of blocked requests tells the
+// client when to try again.
+func (member *MemberUseCases) claimLoginAttempt(ctx *gin.Context, memberID uuid.UUID, clientIP string) (bool, error) {
+	retryAt, blocked, err := member.repo.ClaimLoginAttempt(ctx, memberID, clientIP)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Login failed, unable to record login attempt: %s", err.Error())
+		return false, err
+	}
+	if !blocked {
+		return false, nil
+	}
+	logger.Log().WithContext(ctx).Errorf("Login failed, too many failed logins for member %s from %s", memberID, clientIP)
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(retryAt).Seconds()))))
+	return true, nil
+}
+
+// releaseLoginAttempt gives back a login attempt claimed with claimLoginAttempt whose credentials
+// were right or could not be checked.
+func (member *MemberUseCases) releaseLoginAttempt(ctx *gin.Context, memberID uuid.UUID, clientIP string) error {
+	err := member.repo.ReleaseLoginAttempt(ctx, memberID, clientIP)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Login failed, unable to release login attempt of member %s from %s: %s", memberID, clientIP, err.Error())
+	}
+	return err
+}
+
+// twoFactorChallenge returns the login challenge of a member whose password was verified. It
+// returns false when the member has no two-factor authentication enabled.
+func (member *MemberUseCases) twoFactorChallenge(ctx *gin.Context, memberBasic entities.BasicMemberData) (entities.BasicMemberData, bool, error) {
+	state, err := member.repo.GetTwoFactor(ctx, memberBasic.MemberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Login failed, unable to load two-factor state of member %s: %s", memberBasic.MemberID, err.Error())
+		return entities.BasicMemberData{}, false, err
+	}
+	if !state.Enabled() {
+		return entities.BasicMemberData{}, false, nil
+	}
+
+	token, expiresAt, err := member.challenges.Issue(memberBasic.MemberID, memberBasic.Email, time.Now())
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Login failed, unable to issue two-factor challenge for member %s: %s", memberBasic.MemberID, err.Error())
+		return entities.BasicMemberData{}, false, err
+	}
+	return entities.BasicMemberData{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: &expiresAt,
+	}, true, nil
+}
+
+// CompleteTwoFactorChallenge completes the login challenge with a code of the member's
+// authenticator app or an unused recovery code. Wrong codes count as failed logins.
+func (member *MemberUseCases) CompleteTwoFactorChallenge(ctx *gin.Context, partnerID string, challenge entities.TwoFactorChallenge) (map[string][]string, entities.BasicMemberData, error) {
+	fieldsMap := map[string][]string{}
+	if strings.TrimSpace(challenge.ChallengeToken) == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Required)
+	}
+	if strings.TrimSpace(challenge.Code) == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Required)
+	}
+	if len(fieldsMap) != 0 {
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+
+	claims, err := member.challenges.Verify(challenge.ChallengeToken, time.Now())
+	if errors.Is(err, verification.ErrExpired) {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Expired)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+	if err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Invalid)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+
+	state, err := member.repo.GetTwoFactor(ctx, claims.MemberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Invalid)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, unable to load two-factor state: %s", err.Error())
+		return nil, entities.BasicMemberData{}, err
+	}
+	// The challenge is void once the email changed or two-factor authentication was disabled
+	if !claims.Matches(state.Email) || !state.Enabled() {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Invalid)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+
+	clientIP := ctx.ClientIP()
+	if blocked, err := member.claimLoginAttempt(ctx, claims.MemberID, clientIP); err != nil || blocked {
+		if blocked {
+			utils.AppendValuesToMap(fieldsMap, consts.Email, consts.Locked)
+		}
+		return fieldsMap, entities.BasicMemberData{}, err
+	}
+	valid, err := member.checkSecondFactor(ctx, state, challenge.Code)
+	if err != nil {
+		_ = member.releaseLoginAttempt(ctx, claims.MemberID, clientIP)
+		return nil, entities.BasicMemberData{}, err
+	}
+	if !valid {
+		if err := member.repo.RecordLoginFailure(ctx, claims.MemberID, clientIP); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, unable to record login attempt: %s", err.Error())
+			return nil, entities.BasicMemberData{}, err
+		}
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Invalid)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+	if err := member.releaseLoginAttempt(ctx, claims.MemberID, clientIP); err != nil {
+		return nil, entities.BasicMemberData{}, err
+	}
+
+	memberBasic, err := member.repo.GetBasicMemberDetailsByID(ctx, claims.MemberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, unable to load member %s: %s", claims.MemberID, err.Error())
+		return nil, entities.BasicMemberData{}, err
+	}
+	if partnerID != "" && !strings.EqualFold(memberBasic.PartnerID.String(), partnerID) {
+		utils.AppendValuesToMap(fieldsMap, consts.ChallengeToken, consts.Invalid)
+		return fieldsMap, entities.BasicMemberData{}, nil
+	}
+
+	if err := member.repo.ClearLoginLockout(ctx, claims.MemberID); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor challenge failed, unable to record login attempt: %s", err.Error())
+		return nil, entities.BasicMemberData{}, err
+	}
+	return nil, memberBasic, nil
+}
+
+// checkSecondFactor checks an authenticator app code or a recovery code of the member. Used
+// recovery codes and the steps of accepted codes are recorded, so neither can be replayed.
+func (member *MemberUseCases) checkSecondFactor(ctx *gin.Context, state entities.TwoFactor, code string) (bool, error) {
+	var (
+		valid bool
+		err   error
+	)
+	if totp.IsRecoveryCode(code) {
+		valid, err = member.repo.UseRecoveryCode(ctx, state.MemberID, totp.HashRecoveryCode(code))
+	} else if step, ok := member.twoFactor.Validate(state.Secret, code, time.Now()); ok {
+		valid, err = member.repo.UseTwoFactorStep(ctx, state.MemberID, step)
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor check failed for member %s: %s", state.MemberID, err.Error())
+		return false, err
+	}
+	return valid, nil
+}
+
+// loadTwoFactor returns the two-factor state of the member, adding a validation error when the
+// member does not exist.
+func (member *MemberUseCases) loadTwoFactor(ctx *gin.Context, memberID uuid.UUID, fieldsMap map[string][]string) (entities.TwoFactor, error) {
+	state, err := member.repo.GetTwoFactor(ctx, memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.NotFound)
+		return state, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor failed, unable to load two-factor state of member %s: %s", memberID, err.Error())
+	}
+	return state, err
+}
+
+// EnrollTwoFactor creates a new secret for the member. The enrollment stays pending, and logins
+// are not challenged, until EnableTwoFactor verified a first code. Enrolling again replaces a
+// pending secret.
+func (member *MemberUseCases) EnrollTwoFactor(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, entities.TwoFactorEnrollment, error) {
+	fieldsMap := map[string][]string{}
+	state, err := member.loadTwoFactor(ctx, memberID, fieldsMap)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, entities.TwoFactorEnrollment{}, err
+	}
+	if state.Enabled() {
+		utils.AppendValuesToMap(fieldsMap, consts.TwoFactor, consts.Enabled)
+		return fieldsMap, entities.TwoFactorEnrollment{}, nil
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor enrollment failed for member %s: %s", memberID, err.Error())
+		return nil, entities.TwoFactorEnrollment{}, err
+	}
+	if err := member.repo.SaveTwoFactorSecret(ctx, memberID, secret); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Two-factor enrollment failed for member %s: %s", memberID, err.Error())
+		return nil, entities.TwoFactorEnrollment{}, err
+	}
+	return nil, entities.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    member.twoFactor.URI(state.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor enables the pending two-factor authentication once the first code of the
+// authenticator app is verified, and returns the recovery codes. Only their hashes are stored,
+// the codes cannot be shown again.
+func (member *MemberUseCases) EnableTwoFactor(ctx *gin.Context, memberID uuid.UUID, code string) (map[string][]string, entities.TwoFactorRecoveryCodes, error) {
+	fieldsMap := map[string][]string{}
+	if strings.TrimSpace(code) == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Required)
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, nil
+	}
+
+	state, err := member.loadTwoFactor(ctx, memberID, fieldsMap)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, err
+	}
+	if state.Enabled() {
+		utils.AppendValuesToMap(fieldsMap, consts.TwoFactor, consts.Enabled)
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, nil
+	}
+	if state.Secret == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.TwoFactor, consts.NotEnrolled)
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, nil
+	}
+
+	step, ok := member.twoFactor.Validate(state.Secret, code, time.Now())
+	if !ok {
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Invalid)
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, nil
+	}
+
+	codes, err := totp.GenerateRecoveryCodes(totp.RecoveryCodeCount)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed for member %s: %s", memberID, err.Error())
+		return nil, entities.TwoFactorRecoveryCodes{}, err
+	}
+	hashes := make([]string, len(codes))
+	for i, recoveryCode := range codes {
+		hashes[i] = totp.HashRecoveryCode(recoveryCode)
+	}
+
+	enabled, err := member.repo.EnableTwoFactor(ctx, memberID, step, hashes)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Enable two-factor failed for member %s: %s", memberID, err.Error())
+		return nil, entities.TwoFactorRecoveryCodes{}, err
+	}
+	if !enabled {
+		// A concurrent request enabled it or used the code first
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Invalid)
+		return fieldsMap, entities.TwoFactorRecoveryCodes{}, nil
+	}
+
+	member.recordActivity(ctx, memberID, consts.ActivityTwoFactorEnabled, nil)
+	return nil, entities.TwoFactorRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor removes the two-factor authentication of the member. Members confirm it with
+// a code or a recovery code, admins may disable it for other members who lost their device.
+func (member *MemberUseCases) DisableTwoFactor(ctx *gin.Context, memberID uuid.UUID, code string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	state, err := member.loadTwoFactor(ctx, memberID, fieldsMap)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, err
+	}
+	if state.Secret == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.TwoFactor, consts.NotEnrolled)
+		return fieldsMap, nil
+	}
+
+	callerID := ctx.GetString(consts.ContextMemberID)
+	adminReset := isAdmin(ctx) && !strings.EqualFold(callerID, memberID.String())
+	if state.Enabled() && !adminReset {
+		if strings.TrimSpace(code) == "" {
+			utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Required)
+			return fieldsMap, nil
+		}
+		valid, err := member.checkSecondFactor(ctx, state, code)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Invalid)
+			return fieldsMap, nil
+		}
+	}
+
+	if err := member.repo.DisableTwoFactor(ctx, memberID); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Disable two-factor failed for member %s: %s", memberID, err.Error())
+		return nil, err
+	}
+	if state.Enabled() {
+		member.recordActivity(ctx, memberID, consts.ActivityTwoFactorDisabled, map[string]interface{}{
+			"disabled_by": callerID,
+		})
+	}
+	return nil, nil
+}
+
+// UnlockMember lifts the login lockout of the member.
+func (member *MemberUseCases) UnlockMember(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	exists, err := member.repo.IsMemberExists(memberID, ctx)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed, unable to check member %s: %s", memberID, err.Error())
+		return nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.NotFound)
+		return fieldsMap, nil
+	}
+
+	if err := member.repo.ClearLoginLockout(ctx, memberID); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Unlock member failed for member %s: %s", memberID, err.Error())
+		return nil, err
+	}
+	member.recordActivity(ctx, memberID, consts.ActivityMemberUnlocked, map[string]interface{}{
+		"unlocked_by": ctx.GetString(consts.ContextMemberID),
+	})
+	return nil, nil
+}
+
+//Reset Password initiation
+
+// InitiatePasswordReset initiates the password reset process for a member.
+// The generated key is only delivered to the member's email, it is never returned to the caller.
+// Nothing is sent for unknown members or emails that are not the member's, and the caller is
+// not told so, the endpoint must not reveal which members and emails are registered.
+func (member *MemberUseCases) InitiatePasswordReset(ctx *gin.Context, memberID uuid.UUID, email string) (map[string][]string, error) {
+	fieldsMap := make(map[string][]string)
+	// Validate email presence
+	if len(email) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.ResetEmail, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Reset Password failed, validation error: No email found")
+		return fieldsMap, nil // Return early if email is empty
+	}
+
+	// Validate email format
+	if !utilities.ValidateEmail(email) {
+		utils.AppendValuesToMap(fieldsMap, consts.ResetEmail, consts.Format)
+		logger.Log().WithContext(ctx).Errorf("Reset Password failed, validation error: Invalid email format")
+		return fieldsMap, nil // Return early if email format is invalid
+	}
+
+	validEmail, err := member.repo.CheckEmailForMemberID(ctx, memberID, email)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Reset Password failed due to database error: %s", err.Error())
+		return nil, err
+	}
+	if !validEmail {
+		logger.Log().WithContext(ctx).Errorf("Reset Password skipped, the email is not the one of member %s", memberID)
+		return nil, nil
+	}
+
+	// If no validation errors, proceed to reset password
+	key, expiresAt, err := member.repo.InitiatePasswordReset(ctx, memberID, email)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resetting password failed: %s", err.Error())
+		return fieldsMap, err // Ensure you return the error here
+	}
+
+	contact, err := member.repo.GetMemberContact(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resetting password failed, unable to load member contact: %s", err.Error())
+		return nil, err
+	}
+
+	// Send the key out of band, the member has to read it from the email
+	err = member.notifier.Notify(ctx, entities.Notification{
+		Event:    consts.EventPasswordReset,
+		To:       email,
+		Language: contact.Language,
+		Data: map[string]interface{}{
+			"Name":      contact.Name,
+			"Key":       key,
+			"ExpiresIn": int(time.Until(expiresAt).Round(time.Minute).Minutes()),
+		},
+	})
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Resetting password failed, unable to send reset key: %s", err.Error())
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// HandleSubscriptionCheckout handles the checkout of a subscription for a member.
+// It takes the
+//   - context
+//   - memberID
+//   - checkoutData
+//     as parameters.
+//
+// It returns a map with subscription details and any encountered errors.
+func (member *MemberUseCases) HandleSubscriptionCheckout(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.CheckoutSubscription, partnerIDStr string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	// Create a map to store the result
+	resultMap := make(map[string][]string)
+
+	subExists, isActive, err := member.repo.CheckSubscriptionExistenceAndStatusForCheckout(ctx, checkoutData.SubscriptionID)
+	// Ensure the provided partnerID matches the partnerID associated with the member
+	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
+		return nil, err
+	}
+
+	if !isPartnerValid {
+		// Partner authentication failed
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
+		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
+		return fieldsMap, nil
+	}
+	// The partner may require a verified email before the member can check out
+	verificationState, err := member.repo.GetEmailVerification(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: unable to load email verification: %s", err.Error())
+		return nil, err
+	}
+	if verificationState.BlocksCheckout() {
+		utils.AppendValuesToMap(fieldsMap, consts.Email, consts.NotVerified)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: email of member %s is not verified", memberID)
+		return fieldsMap, nil
+	}
+	if !subExists {
 		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid subscription plan")
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid subscription plan")
 		return fieldsMap, nil
 	}
 	if !isActive {
 		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Inactive)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Inactive subscription plan")
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Inactive subscription plan")
 		return fieldsMap, nil
 	}
-
-	//IsMemberSubscribedToPlan checks if member subscribed to the plan, before proceeding with renewal
-	yesSubscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, checkoutData.MemberSubscriptionID)
-	if !yesSubscribed {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotSubscribedYet)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Not yet subscribed this plan")
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: %s", err.Error())
+	}
+	// Check if SubscriptionID is empty
+	if checkoutData.SubscriptionID == "" || len(checkoutData.SubscriptionID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Missing required fields")
 		return fieldsMap, nil
 	}
+	if len(checkoutData.SubscriptionID) > 36 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.TooLong)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Subscription Id too long/exceeds limit")
+	}
+	if len(checkoutData.CustomName) > 60 {
+		utils.AppendValuesToMap(fieldsMap, consts.CustonName, consts.TooLong)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Custom name too long")
+		return fieldsMap, nil
+	}
+	// Fetch the count of subscriptions for the member within the last year
+	count, err := member.repo.GetSubscriptionCountForLastYear(ctx, memberID, checkoutData.SubscriptionID)
+
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Failed to get members subscription count in last year")
+	}
+
+	// Fetch the maximum subscription limit for the given subscription ID
+	maxCount, err := member.repo.GetMaxSubscriptionLimitForID(ctx, checkoutData.SubscriptionID)
+
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Failed to get maximum allowed count per yearr")
+
+	}
+	if count == maxCount {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.LimitReached)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Reached maximum allowed subscription in an year")
+		return fieldsMap, nil
+	}
+	// If the subscription is free, skip the PaymentGatewayID check
+	isFree, err := member.repo.IsFreeSubscription(ctx, checkoutData.SubscriptionID)
+
+	if err != nil {
+		if err.Error() == "subscription does not exist" {
+			logger.Log().WithContext(ctx).Errorf("Invalid Subscription Id : %s", err.Error())
+			return nil, err
+		} else {
+			logger.Log().WithContext(ctx).Errorf("Failed to check if the subscription is free: %s", err.Error())
+			return nil, err
+		}
+	}
+	// Continue with the rest of the code, including PaymentGatewayID check, if it's not free
+	var paymentInfo entities.PaymentGatewayDetails
+	var gateway payment.Gateway
+	if !isFree {
+		if checkoutData.PaymentGatewayID == 0 {
+			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Required)
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Missing required fields")
+			return fieldsMap, nil
+		}
+
+		gatewayExists, _ := member.repo.CheckIfPayoutGatewayExists(ctx, checkoutData.PaymentGatewayID)
+
+		if !gatewayExists {
+			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Invalid)
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid gateway")
+			return fieldsMap, nil
+		}
+
+		var gatewayErrors map[string][]string
+		gateway, paymentInfo, gatewayErrors, err = member.partnerGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
+		if err != nil || len(gatewayErrors) > 0 {
+			return gatewayErrors, err
+		}
+
+		// The plan must have a price in the currency the gateway charges in
+		_, err = member.repo.GetSubscriptionPlanTerms(ctx, checkoutData.SubscriptionID, paymentInfo.DefaultPayinCurrency)
+		if errors.Is(err, consts.ErrPlanPriceNotFound) {
+			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.CurrencyMismatch)
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: no price in %s", paymentInfo.DefaultPayinCurrency)
+			return fieldsMap, nil
+		}
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: %s", err.Error())
+			return nil, err
+		}
+		if checkoutData.PaymentGatewayID > consts.MaxInt {
+			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.TooLong)
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Invalid gateway id")
+			return fieldsMap, nil
+		}
+	}
+
+	subscribedOnetime, err := member.repo.HasSubscribedToOneTimePlan(ctx, memberID, checkoutData.SubscriptionID)
+
+	if subscribedOnetime {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.SubscribedOnce)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: This is is one time plan")
+		return fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan:Error checking This is is one time plan")
+	}
+	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
+	if len(fieldsMap) > 0 {
+		return fieldsMap, nil
+	}
+
+	// Promo codes only discount paid plans
+	checkoutData.Discount = nil
+	if strings.TrimSpace(checkoutData.PromoCode) != "" {
+		if isFree {
+			utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.NotSupported)
+			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: promo codes do not apply to free plans")
+			return fieldsMap, nil
+		}
+		var promoErrors map[string][]string
+		checkoutData.Discount, promoErrors, err = member.applyPromoCode(ctx, checkoutData.PromoCode, partnerIDStr, checkoutData.SubscriptionID,
+			paymentInfo.DefaultPayinCurrency)
+		if err != nil || len(promoErrors) > 0 {
+			return promoErrors, err
+		}
+	}
+
+	// Handle the subscription checkout by calling the HandleSubscriptionCheckout method from the repository.
+	memberSubscriptionID, err := member.repo.HandleSubscriptionCheckout(ctx, memberID, checkoutData)
+	if errors.Is(err, consts.ErrPromoCodeLimitReached) {
+		utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.LimitReached)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: promo code %s cannot be redeemed any more", checkoutData.PromoCode)
+		return fieldsMap, nil
+	}
+
+	// Check if there was an error during the checkout process.
+	if err != nil {
+		resultMap["error"] = []string{err.Error()}
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout Subscription: %s", err.Error())
+		return nil, err
+	}
+
+	// Paid subscriptions are activated along with the record of their captured payment, pending
+	// payments are activated by the payment webhook.
+	if !isFree {
+		_, paymentErrors, err := member.chargeSubscription(ctx, gateway, paymentInfo, checkoutData.SubscriptionID, entities.SubscriptionPayment{
+			MemberSubscriptionID: memberSubscriptionID,
+			MemberID:             memberID,
+			PaymentGatewayID:     checkoutData.PaymentGatewayID,
+			Kind:                 consts.PaymentKindCheckout,
+		}, checkoutData.Discount, nil)
+		if err != nil || len(paymentErrors) > 0 {
+			return paymentErrors, err
+		}
+	}
+
+	subscription := checkoutData.CustomName
+	if subscription == "" {
+		subscription = checkoutData.SubscriptionID
+	}
+	member.notifyMember(ctx, memberID, consts.EventSubscriptionCheckout, map[string]interface{}{
+		"Subscription": subscription,
+	})
+
+	return fieldsMap, nil
+}
+
+// HandleSubscriptionRenewal handles the checkout of a subscription for a member.
+// It takes the
+//   - context
+//   - memberID
+//   - checkoutData
+//     as parameters.
+//
+// It returns a map with subscription details and any encountered errors.
+func (member *MemberUseCases) HandleSubscriptionRenewal(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.SubscriptionRenewal, partnerIDStr string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+
+	// Ensure the provided partnerID matches the partnerID associated with the member.
+	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
+		return nil, err
+	}
+	if !isPartnerValid {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
+		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
+		return fieldsMap, nil
+	}
+
+	//CheckMemberSubscriptionExists checks the member subscription and the plan version it was bought on exist.
+	//Subscriptions keep their plan version when it is superseded or retired, and can still be renewed.
+	subExists, err := member.repo.CheckMemberSubscriptionExists(ctx, checkoutData.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: %s", err.Error())
+		return nil, err
+	}
+	if !subExists {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid subscription plan")
+		return fieldsMap, nil
+	}
+
+	//IsMemberSubscribedToPlan checks if member subscribed to the plan, before proceeding with renewal
+	yesSubscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, checkoutData.MemberSubscriptionID)
+	if !yesSubscribed {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotSubscribedYet)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Not yet subscribed this plan")
+		return fieldsMap, nil
+	}
+
+	//IsSubscriptionAboutInWarning checks if the subscription is in warning period ,
+	// also notifies member about payment to proceed with renewal
+	aboutToExpire, _, err := member.repo.IsSubscriptionAboutInWarning(ctx, checkoutData.MemberSubscriptionID)
+	if aboutToExpire {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.AboutToExpire)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan is about to expire,Make Payment to renew")
+		return fieldsMap, nil
+	}
+
+	//IsSubscriptionInGracePeriod checks if the subscription is in grace period ,
+	// also notifies member about payment to proceed with renewal before expiration.
+	inGrace, _, graceEnd, _, _, err := member.repo.IsSubscriptionInGracePeriod(ctx, memberID, checkoutData.MemberSubscriptionID)
+	if inGrace {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.InGrace)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan is in grace period,Make Payment to renew")
+		return fieldsMap, nil
+	}
+	//checks if subscription plan expired
+	isExpired := time.Now().After(graceEnd)
+	if isExpired {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Expired)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan expired")
+		return fieldsMap, nil
+	}
+
+	//GetSubscriptionStatusName checks the status of current subscription
+	currentStatus, err := member.repo.GetSubscriptionStatusName(ctx, checkoutData.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to renew current subscription plan, error in checking if the subscription was free: %s", err.Error())
+		return nil, err
+	}
+	if currentStatus != "active" && currentStatus != "on_hold" && currentStatus != "payment_failed" &&
+		currentStatus != consts.SubscriptionStatusWarning && currentStatus != consts.SubscriptionStatusInGrace {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CheckStatus)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Check the current status of the plan")
+		return fieldsMap, nil
+	}
+
+	//IsMemberSubscribedToFreePlan checks if the plan is free, if so it cannot be renewed
+	subscribedFree, err := member.repo.IsMemberSubscribedToFreePlan(ctx, memberID, checkoutData.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to renew current subscription plan, error in checking if the subscription was free: %s", err.Error())
+		return nil, err
+	}
+	if subscribedFree {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Subscribed)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: cannot renew free plan")
+		return fieldsMap, nil
+
+	}
+
+	//Check if PaymentGatewayID is empty
+	if checkoutData.PaymentGatewayID == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Missing required fields")
+	}
+	if len(checkoutData.MemberSubscriptionID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Member Subscription Id is mandatory")
+	}
+	if len(checkoutData.MemberSubscriptionID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Missing required fields")
+		return fieldsMap, nil
+	}
+	if len(checkoutData.MemberSubscriptionID) > 36 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.TooLong)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Subscription Id too long/exceeds limit")
+		return fieldsMap, nil
+	}
+
+	//CheckIfPayoutGatewayExists checks if the payment gateway entered is valid or not
+	gatewayExists, err := member.repo.CheckIfPayoutGatewayExists(ctx, checkoutData.PaymentGatewayID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to renew Subscription:Error in checking gateway existance %s", err.Error())
+		return nil, err
+	}
+
+	if !gatewayExists {
+		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan:Invalid gateway")
+		return fieldsMap, nil
+	}
+
+	// The partner must offer the payment gateway and take payments on it
+	gateway, paymentInfo, gatewayErrors, err := member.partnerGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
+	if err != nil || len(gatewayErrors) > 0 {
+		return gatewayErrors, err
+	}
+
+	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
+	if len(fieldsMap) > 0 {
+		return fieldsMap, nil
+	}
+
+	// Charge the renewal before extending the subscription.
+	subscriptionID, err := member.repo.GetSubscriptionIDByMemberSubscriptionID(ctx, checkoutData.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to renew Subscription: %s", err.Error())
+		return nil, err
+	}
+	paymentStatus, paymentErrors, err := member.chargeSubscription(ctx, gateway, paymentInfo, subscriptionID.String(), entities.SubscriptionPayment{
+		MemberSubscriptionID: checkoutData.MemberSubscriptionID,
+		MemberID:             memberID,
+		PaymentGatewayID:     checkoutData.PaymentGatewayID,
+		Kind:                 consts.PaymentKindRenewal,
+	}, nil, nil)
+	if err != nil || len(paymentErrors) > 0 {
+		return paymentErrors, err
+	}
+	if paymentStatus == consts.PaymentStatusPending {
+		// The payment webhook renews the subscription once the gateway confirms the payment.
+		logger.Log().WithContext(ctx).Infof("Renewal of subscription %s waits for the payment confirmation", checkoutData.MemberSubscriptionID)
+		return fieldsMap, nil
+	}
+
+	// The captured payment was recorded along with the new expiration date and renewed on date.
+	member.notifyMember(ctx, memberID, consts.EventSubscriptionRenewed, map[string]interface{}{
+		"Subscription": checkoutData.MemberSubscriptionID,
+	})
+
+	return fieldsMap, nil
+}
+
+// SetAutoRenewal opts a member subscription in or out of automatic renewal. Only recurring paid
+// subscriptions renew automatically, on a payment gateway the member's partner takes payments on.
+func (member *MemberUseCases) SetAutoRenewal(ctx *gin.Context, memberID uuid.UUID, request entities.AutoRenewal) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	if _, err := uuid.Parse(request.MemberSubscriptionID); err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.Invalid)
+		return fieldsMap, nil
+	}
+	subscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, request.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+		return nil, err
+	}
+	if !subscribed {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.NotFound)
+		return fieldsMap, nil
+	}
+
+	if request.Enabled {
+		subscriptionID, err := member.repo.GetSubscriptionIDByMemberSubscriptionID(ctx, request.MemberSubscriptionID)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+			return nil, err
+		}
+		oneTime, err := member.repo.IsOneTimeSubscription(ctx, subscriptionID.String())
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+			return nil, err
+		}
+		free, err := member.repo.IsSubscriptionFree(ctx, subscriptionID.String())
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+			return nil, err
+		}
+		if oneTime || free {
+			utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.NotRecurring)
+		}
+
+		if request.PaymentGatewayID == 0 {
+			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Required)
+		} else {
+			partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
+			if err != nil {
+				logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+				return nil, err
+			}
+			_, _, gatewayErrors, err := member.partnerGateway(ctx, partnerID.String(), request.PaymentGatewayID)
+			if err != nil {
+				return nil, err
+			}
+			for key, values := range gatewayErrors {
+				for _, value := range values {
+					utils.AppendValuesToMap(fieldsMap, key, value)
+				}
+			}
+		}
+		if len(fieldsMap) != 0 {
+			return fieldsMap, nil
+		}
+	}
+
+	err = member.repo.SetAutoRenewal(ctx, memberID, request.MemberSubscriptionID, request.Enabled, request.PaymentGatewayID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.NotFound)
+		return fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("SetAutoRenewal failed, err=%s", err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// GetRenewalAttempts lists the automatic renewal attempts of a member subscription, the latest first.
+func (member *MemberUseCases) GetRenewalAttempts(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string) ([]entities.RenewalAttempt, map[string][]string, error) {
+	if _, err := uuid.Parse(memberSubscriptionID); err != nil {
+		return nil, map[string][]string{consts.MemberSubscriptionID: {consts.Invalid}}, nil
+	}
+	subscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, memberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetRenewalAttempts failed, err=%s", err.Error())
+		return nil, nil, err
+	}
+	if !subscribed {
+		return nil, map[string][]string{consts.MemberSubscriptionID: {consts.NotFound}}, nil
+	}
+	attempts, err := member.repo.GetRenewalAttempts(ctx, memberID, memberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetRenewalAttempts failed, err=%s", err.Error())
+		return nil, nil, err
+	}
+	return attempts, nil, nil
+}
+
+// ProcessSubscriptionRenewals is run periodically by the scheduler. It charges up to limit subscriptions
+// whose automatic renewal is due and renews those paid for. Failed renewals are retried on the dunning
+// schedule within the grace period. Every attempt is recorded and the member notified of its outcome.
+func (member *MemberUseCases) ProcessSubscriptionRenewals(ctx context.Context, limit int, lease time.Duration, dunning []time.Duration) error {
+	candidates, err := member.repo.ClaimDueRenewals(ctx, limit, lease)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Subscription renewal failed: %s", err.Error())
+		return err
+	}
+
+	var failed error
+	for _, candidate := range candidates {
+		if err := member.renewAutomatically(ctx, candidate, dunning); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to renew subscription %s: %s", candidate.MemberSubscriptionID, err.Error())
+			failed = err
+		}
+	}
+	return failed
+}
+
+// renewAutomatically makes an automatic renewal attempt. Errors other than a refused payment leave the
+// renewal claimed until the lease ends, it is attempted again then.
+func (member *MemberUseCases) renewAutomatically(ctx context.Context, candidate entities.RenewalCandidate, dunning []time.Duration) error {
+	attempt := entities.RenewalAttempt{
+		MemberSubscriptionID: candidate.MemberSubscriptionID,
+		MemberID:             candidate.MemberID,
+		Attempt:              candidate.Failures + 1,
+		Status:               consts.RenewalFailed,
+	}
+
+	gateway, credentials, fieldsMap, err := member.partnerGateway(ctx, candidate.PartnerID.String(), candidate.PaymentGatewayID)
+	if err != nil {
+		return err
+	}
+	if len(fieldsMap) == 0 {
+		var status string
+		status, fieldsMap, err = member.chargeSubscription(ctx, gateway, credentials, candidate.SubscriptionID, entities.SubscriptionPayment{
+			MemberSubscriptionID: candidate.MemberSubscriptionID,
+			MemberID:             candidate.MemberID,
+			PaymentGatewayID:     candidate.PaymentGatewayID,
+			Kind:                 consts.PaymentKindRenewal,
+		}, nil, nil)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case consts.PaymentStatusCaptured:
+			// The subscription was renewed along with the payment record
+			attempt.Status = consts.RenewalSucceeded
+		case consts.PaymentStatusPending:
+			// The payment webhook renews the subscription once the gateway confirms the payment.
+			attempt.Status = consts.RenewalPending
+		}
+	}
+
+	subscription := candidate.CustomName
+	if subscription == "" {
+		subscription = candidate.MemberSubscriptionID
+	}
+	data := map[string]interface{}{"Subscription": subscription}
+
+	if attempt.Status == consts.RenewalFailed {
+		attempt.FailureReason = consts.PaymentFailed
+		if reasons := fieldsMap[consts.PaymentGatewayID]; len(reasons) != 0 {
+			attempt.FailureReason = reasons[0]
+		}
+		_, _, graceEnd, _, _, err := member.repo.IsSubscriptionInGracePeriod(ctx, candidate.MemberID, candidate.MemberSubscriptionID)
+		if err != nil {
+			return err
+		}
+		if next, ok := renewal.Next(dunning, candidate.ExpirationDate, graceEnd, time.Now()); ok {
+			attempt.NextAttemptOn = &next
+			data["NextAttempt"] = next.Format("2006-01-02")
+		}
+		data["GraceEnd"] = graceEnd.Format("2006-01-02")
+	}
+
+	if err := member.repo.RecordRenewalAttempt(ctx, attempt); err != nil {
+		return err
+	}
+	logger.Log().WithContext(ctx).Infof("Renewal attempt %d of subscription %s %s", attempt.Attempt, candidate.MemberSubscriptionID, attempt.Status)
+
+	switch attempt.Status {
+	case consts.RenewalSucceeded:
+		member.notifyMember(ctx, candidate.MemberID, consts.EventSubscriptionRenewed, data)
+	case consts.RenewalFailed:
+		member.notifyMember(ctx, candidate.MemberID, consts.EventSubscriptionRenewalFailed, data)
+	}
+	return nil
+}
+
+// partnerGateway returns the payment gateway with the given ID and the partner's credentials for it.
+// Gateways the partner does not offer, has no credentials for, does not take payments on or that are
+// not supported are reported as payment_gateway_id errors.
+func (member *MemberUseCases) partnerGateway(ctx context.Context, partnerID string, paymentGatewayID int) (payment.Gateway, entities.PaymentGatewayDetails, map[string][]string, error) {
+	var credentials entities.PaymentGatewayDetails
+	related, err := member.repo.IsPartnerIdCorrespondsToGateway(ctx, partnerID, paymentGatewayID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check gateway %d of partner %s: %s", paymentGatewayID, partnerID, err.Error())
+		return nil, credentials, nil, err
+	}
+	if !related {
+		return nil, credentials, map[string][]string{consts.PaymentGatewayID: {consts.NoRelation}}, nil
+	}
+
+	paymentDetails, err := member.repo.GetPaymentDetailsByPartnerAndGateway(ctx, partnerID, paymentGatewayID)
+	if err != nil || len(paymentDetails) == 0 {
+		logger.Log().WithContext(ctx).Errorf("No payment details found for gateway %d of partner %s", paymentGatewayID, partnerID)
+		return nil, credentials, map[string][]string{consts.PaymentGatewayID: {consts.NoDetails}}, nil
+	}
+	detailsString, err := member.repo.DecryptPaymentData(ctx, paymentDetails)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to decrypt gateway %d credentials of partner %s: %s", paymentGatewayID, partnerID, err.Error())
+		return nil, credentials, nil, err
+	}
+	if err := json.Unmarshal([]byte(detailsString), &credentials); err != nil {
+		return nil, credentials, nil, err
+	}
+	if !credentials.Payin {
+		return nil, credentials, map[string][]string{consts.PaymentGatewayID: {consts.NoPayin}}, nil
+	}
+
+	gateway, err := member.payments.Get(credentials.Gateway)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Gateway %d of partner %s: %s", paymentGatewayID, partnerID, err.Error())
+		return nil, credentials, map[string][]string{consts.PaymentGatewayID: {consts.NotSupported}}, nil
+	}
+	return gateway, credentials, nil, nil
+}
+
+// HandleSubscriptionCancellation handles the checkout of a subscription for a member.
+// It takes the
+//   - context
+//   - memberID
+//     as parameters.
+//
+// It returns a map with subscription details and any encountered errors.
+func (member *MemberUseCases) HandleSubscriptionCancellation(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription, partnerIDStr string) (map[string][]string, error) {
+
+	fieldsMap := map[string][]string{}
+
+	// Check if SubscriptionID is empty
+	// Ensure the provided partnerID matches the partnerID associated with the member.
+	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
+		return nil, err
+	}
+	if !isPartnerValid {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
+		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
+		return fieldsMap, nil
+	}
+
+	//CheckMemberSubscriptionExists checks the member subscription and the plan version it was bought on exist.
+	//Subscriptions keep their plan version when it is superseded or retired, and can still be cancelled.
+	subExists, err := member.repo.CheckMemberSubscriptionExists(ctx, checkoutData.MemberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to cancel this plan: %s", err.Error())
+		return nil, err
+	}
+	if !subExists {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid subscription plan")
+		return fieldsMap, nil
+	}
+
+	//IsMemberSubscribedToPlan checks if member subscribed to the plan, before proceeding with renewal
+	yesSubscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, checkoutData.MemberSubscriptionID)
+	if !yesSubscribed {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotSubscribedYet)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Not yet subscribed this plan")
+		return fieldsMap, nil
+	}
+
+	//HasProductsReleaseEndDateGreaterThanToday checks if  there are released products that havenot yet reached end date.
+	yesProduct, err := member.repo.HasProductsReleaseEndDateGreaterThanToday(ctx, checkoutData.MemberSubscriptionID)
+	if yesProduct {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CannotCancel)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: There are released products under this subscription")
+		return fieldsMap, nil
+	}
+	isRelated, err := member.repo.IsMemberRelatedToSubscription(ctx, memberID, checkoutData.MemberSubscriptionID)
+	if !isRelated {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid member subscription id")
+	}
+
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check subscription status: %v", err)
+		return fieldsMap, err
+	}
+
+	//CheckCancellationEnabled checks if this subscription is cancellation enabled.
+	enabled, err := member.repo.CheckCancellationEnabled(ctx, checkoutData.MemberSubscriptionID)
+	if !enabled {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotEnabled)
+		logger.Log().WithContext(ctx).Errorf("Failed to cancel this subscription: This is not cancellation enabled")
+		return fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check subscription status: %v", err)
+		return fieldsMap, err
+	}
+
+	//GetSubscriptionStatusName gets the current status of the subscription to be cancelled.
+	currentStatus, err := member.repo.GetSubscriptionStatusName(ctx, checkoutData.MemberSubscriptionID)
+
+	if currentStatus != "active" && currentStatus != "on_hold" && currentStatus != consts.SubscriptionStatusWarning {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CheckStatus)
+		logger.Log().WithContext(ctx).Errorf("Failed to cancel this plan: Check the current status of the plan")
+		return fieldsMap, nil
+	}
+	if err != nil {
+		return nil, err
+
+	}
+	if checkoutData.MemberSubscriptionID == "" {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
+		logger.Log().WithContext(ctx).Errorf("Failed to cancel this subscription: Missing required fields")
+		return fieldsMap, nil
+	}
+	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
+	if len(fieldsMap) > 0 {
+		return fieldsMap, nil
+	}
+
+	// Handle the subscription cancellation by calling the HandleSubscriptionCancellation method from the repository.
+	// The subscription is cancelled before its payment is refunded, so a refund is never given for a
+	// subscription that stays active.
+	err = member.repo.HandleSubscriptionCancellation(ctx, memberID, checkoutData)
+
+	// Check if there was an error during the checkout process.
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to cancel Subscription: %s", err.Error())
+		return nil, err
+	}
+
+	member.notifyMember(ctx, memberID, consts.EventSubscriptionCancelled, map[string]interface{}{
+		"Subscription": checkoutData.MemberSubscriptionID,
+	})
+
+	// Refund the unused share of the last captured payment. A failed refund is recorded with the cancelled subscription
+	// and reported, the subscription stays cancelled.
+	refundErrors, err := member.refundSubscription(ctx, partnerIDStr, memberID, checkoutData.MemberSubscriptionID)
+	if err != nil || len(refundErrors) > 0 {
+		return refundErrors, err
+	}
+
+	return fieldsMap, nil
+}
+
+// SubscriptionProductSwitch function
+func (member *MemberUseCases) SubscriptionProductSwitch(ctx context.Context, memberID uuid.UUID, data entities.SwitchSubscriptions) (map[string][]string, error) {
+
+	validationErrors := make(map[string][]string)
+
+	if utilities.IsEmpty(data.CurrentSubscriptionID) {
+		utils.AppendValuesToMap(validationErrors, consts.CurrentSubscriptionID, consts.Required)
+	}
+
+	if utilities.IsEmpty(data.NewSubscriptionID) {
+		utils.AppendValuesToMap(validationErrors, consts.NewSubscriptionID, consts.Required)
+	}
+
+	if utilities.IsEmpty(data.ProductReferenceID) {
+		utils.AppendValuesToMap(validationErrors, consts.ProductReferenceID, consts.Required)
+	}
+
+	_, err := member.repo.SubscriptionProductSwitch(ctx, memberID, data, &validationErrors)
+
+	if len(validationErrors) != 0 {
+		return validationErrors, nil
+	}
+
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Switch products between subscriptions failed err=%s", err.Error())
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// ListInvoices returns a page of the member's invoices, newest first, with the pagination metadata.
+func (member *MemberUseCases) ListInvoices(ctx *gin.Context, memberID uuid.UUID, reqParam entities.ReqParams) ([]entities.Invoice, models.MetaData, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
+	memberExists, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListInvoices failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+	if !memberExists {
+		utils.AppendValuesToMap(validationErrors, consts.MemberrID, consts.NotFound)
+		return nil, models.MetaData{}, validationErrors, nil
+	}
+
+	if reqParam.Limit > consts.MaximumLimit {
+		utils.AppendValuesToMap(validationErrors, consts.Limit, consts.Invalid)
+		return nil, models.MetaData{}, validationErrors, nil
+	}
+
+	recordCount, err := member.repo.GetMemberInvoiceCount(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListInvoices failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	//call Paginate function
+	reqParam.Page, reqParam.Limit = utils.Paginate(reqParam.Page, reqParam.Limit, consts.LimitDefault)
+
+	invoices, err := member.repo.GetMemberInvoices(ctx, memberID, reqParam.Page, reqParam.Limit)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListInvoices failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	metadata := &models.MetaData{
+		CurrentPage: reqParam.Page,
+		PerPage:     reqParam.Limit,
+		Total:       recordCount,
+	}
+	metadata = utils.MetaDataInfo(metadata)
+
+	return invoices, *metadata, nil, nil
+}
+
+// GetInvoice returns an invoice of the member.
+func (member *MemberUseCases) GetInvoice(ctx *gin.Context, memberID uuid.UUID, invoiceID string) (entities.Invoice, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
+	id, err := uuid.Parse(invoiceID)
+	if err != nil {
+		utils.AppendValuesToMap(validationErrors, consts.InvoiceID, consts.Invalid)
+		return entities.Invoice{}, validationErrors, nil
+	}
+
+	invoice, err := member.repo.GetMemberInvoice(ctx, memberID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(validationErrors, consts.InvoiceID, consts.NotFound)
+		return entities.Invoice{}, validationErrors, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetInvoice failed, err=%s", err.Error())
+		return entities.Invoice{}, nil, err
+	}
+	return invoice, nil, nil
+}
+
+// ListSubscriptionPlans lists the latest version of every plan of the catalogue, or of every
+// retired plan.
+func (member *MemberUseCases) ListSubscriptionPlans(ctx *gin.Context, params entities.SubscriptionPlanParams) ([]entities.SubscriptionPlan, error) {
+	plans, err := member.repo.ListSubscriptionPlans(ctx, params.Retired)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListSubscriptionPlans failed, err=%s", err.Error())
+		return nil, err
+	}
+	return plans, nil
+}
+
+// GetSubscriptionPlanVersions lists the versions of a plan of the catalogue, the latest first.
+func (member *MemberUseCases) GetSubscriptionPlanVersions(ctx *gin.Context, planID string) ([]entities.SubscriptionPlan, map[string][]string, error) {
+	id, err := uuid.Parse(planID)
+	if err != nil {
+		return nil, map[string][]string{consts.PlanID: {consts.Invalid}}, nil
+	}
+	versions, err := member.repo.GetSubscriptionPlanVersions(ctx, id)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetSubscriptionPlanVersions failed, err=%s", err.Error())
+		return nil, nil, err
+	}
+	if len(versions) == 0 {
+		return nil, map[string][]string{consts.PlanID: {consts.NotFound}}, nil
+	}
+	return versions, nil, nil
+}
+
+// CreateSubscriptionPlan adds a plan to the catalogue and returns its first version.
+func (member *MemberUseCases) CreateSubscriptionPlan(ctx *gin.Context, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, map[string][]string, error) {
+	request, fieldsMap, err := member.validatePlanRequest(ctx, request)
+	if err != nil || len(fieldsMap) != 0 {
+		return entities.SubscriptionPlan{}, fieldsMap, err
+	}
+	plan, err := member.repo.CreateSubscriptionPlan(ctx, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("CreateSubscriptionPlan failed, err=%s", err.Error())
+		return entities.SubscriptionPlan{}, nil, err
+	}
+	return member.latestPlanVersion(ctx, plan.PlanID)
+}
+
+// AddSubscriptionPlanVersion publishes a new version of a plan with new terms. Only the new version
+// can be bought from now on, member subscriptions keep the version they bought.
+func (member *MemberUseCases) AddSubscriptionPlanVersion(ctx *gin.Context, planID string, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlan, map[string][]string, error) {
+	request, fieldsMap, err := member.validatePlanRequest(ctx, request)
+	if err != nil {
+		return entities.SubscriptionPlan{}, nil, err
+	}
+	id, err := uuid.Parse(planID)
+	if err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.PlanID, consts.Invalid)
+	}
+	if len(fieldsMap) != 0 {
+		return entities.SubscriptionPlan{}, fieldsMap, nil
+	}
+
+	_, err = member.repo.AddSubscriptionPlanVersion(ctx, id, request)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return entities.SubscriptionPlan{}, map[string][]string{consts.PlanID: {consts.NotFound}}, nil
+	case errors.Is(err, consts.ErrPlanRetired):
+		return entities.SubscriptionPlan{}, map[string][]string{consts.PlanID: {consts.Retired}}, nil
+	case err != nil:
+		logger.Log().WithContext(ctx).Errorf("AddSubscriptionPlanVersion failed, err=%s", err.Error())
+		return entities.SubscriptionPlan{}, nil, err
+	}
+	return member.latestPlanVersion(ctx, id)
+}
+
+// RetireSubscriptionPlan withdraws a plan from the catalogue of every partner, only platform admins may.
+// Member subscriptions keep their plan version but no new subscription can buy the plan.
+func (member *MemberUseCases) RetireSubscriptionPlan(ctx *gin.Context, planID string) (map[string][]string, error) {
+	id, err := uuid.Parse(planID)
+	if err != nil {
+		return map[string][]string{consts.PlanID: {consts.Invalid}}, nil
+	}
+	err = member.repo.RetireSubscriptionPlan(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return map[string][]string{consts.PlanID: {consts.NotFound}}, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("RetireSubscriptionPlan failed, err=%s", err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// latestPlanVersion returns the latest version of a plan just written.
+func (member *MemberUseCases) latestPlanVersion(ctx *gin.Context, planID uuid.UUID) (entities.SubscriptionPlan, map[string][]string, error) {
+	versions, err := member.repo.GetSubscriptionPlanVersions(ctx, planID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to read subscription plan %s, err=%s", planID, err.Error())
+		return entities.SubscriptionPlan{}, nil, err
+	}
+	if len(versions) == 0 {
+		return entities.SubscriptionPlan{}, nil, sql.ErrNoRows
+	}
+	return versions[0], nil, nil
+}
+
+// validatePlanRequest trims and validates the terms of a subscription plan. Free plans are priced
+// at zero and paid plans above zero, in distinct currencies.
+func (member *MemberUseCases) validatePlanRequest(ctx *gin.Context, request entities.SubscriptionPlanRequest) (entities.SubscriptionPlanRequest, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	request.Name = strings.TrimSpace(request.Name)
+	request.SKU = strings.TrimSpace(request.SKU)
+
+	switch {
+	case request.Name == "":
+		utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Required)
+	case len(request.Name) > consts.MaxPlanNameLength:
+		utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Invalid)
+	}
+	switch {
+	case request.SKU == "":
+		utils.AppendValuesToMap(fieldsMap, consts.SKU, consts.Required)
+	case len(request.SKU) > consts.MaxPlanNameLength:
+		utils.AppendValuesToMap(fieldsMap, consts.SKU, consts.Invalid)
+	}
+	if request.CanRenewableWithin < 0 || request.CanRenewableWithin > consts.MaxPlanGraceWeeks {
+		utils.AppendValuesToMap(fieldsMap, consts.CanRenewableWithin, consts.Invalid)
+	}
+	if request.ProductCount < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.ProductCount, consts.Invalid)
+	}
+	if request.TrackCount < 0 || request.MaxTracksPerProduct < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.TrackCount, consts.Invalid)
+	}
+	if request.ArtistCount < 0 || request.MaxArtistsPerProduct < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.ArtistCount, consts.Invalid)
+	}
+	if request.SubscriptionLimitPerYear < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionLimitPerYear, consts.Invalid)
+	}
+
+	if len(request.Prices) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.Prices, consts.Required)
+	}
+	currencies := map[int]bool{}
+	for _, price := range request.Prices {
+		validAmount := price.Amount > 0
+		if request.IsFreeSubscription {
+			validAmount = price.Amount == 0
+		}
+		if price.CurrencyID <= 0 || currencies[price.CurrencyID] || !validAmount || price.TaxPercentage < 0 || price.TaxPercentage > 100 {
+			utils.AppendValuesToMap(fieldsMap, consts.Prices, consts.Invalid)
+			break
+		}
+		currencies[price.CurrencyID] = true
+	}
+
+	if request.SubscriptionDurationID <= 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionDurationID, consts.Invalid)
+		return request, fieldsMap, nil
+	}
+	exists, err := member.repo.SubscriptionDurationExists(ctx, request.SubscriptionDurationID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check subscription duration %d, err=%s", request.SubscriptionDurationID, err.Error())
+		return request, nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionDurationID, consts.NotFound)
+	}
+	return request, fieldsMap, nil
+}
+
+// applyPromoCode validates the promo code of a checkout for the partner of the member and the plan, and
+// returns the discount it takes off the plan price in the currency charged. Redemption limits are checked
+// when the code is redeemed along with the checkout.
+func (member *MemberUseCases) applyPromoCode(ctx context.Context, code string, partnerID string, subscriptionID string,
+	currency string) (*entities.PromoDiscount, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+
+	promoCode, err := member.repo.GetPromoCode(ctx, partnerID, strings.ToUpper(strings.TrimSpace(code)))
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.NotFound)
+		return nil, fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to load promo code %s: %s", code, err.Error())
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !promoCode.IsActive || (promoCode.ValidFrom != nil && now.Before(*promoCode.ValidFrom)) ||
+		(promoCode.ValidUntil != nil && !now.Before(*promoCode.ValidUntil)) {
+		utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.Expired)
+		return nil, fieldsMap, nil
+	}
+	if len(promoCode.PartnerIDs) != 0 && !slices.Contains(promoCode.PartnerIDs, partnerID) {
+		utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.NoRelation)
+		return nil, fieldsMap, nil
+	}
+
+	terms, err := member.repo.GetSubscriptionPlanTerms(ctx, subscriptionID, currency)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to load the price of subscription plan %s: %s", subscriptionID, err.Error())
+		return nil, nil, err
+	}
+	if len(promoCode.PlanIDs) != 0 && !slices.Contains(promoCode.PlanIDs, terms.PlanID.String()) {
+		utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.NoRelation)
+		return nil, fieldsMap, nil
+	}
+
+	discount := &entities.PromoDiscount{PromoCodeID: promoCode.ID, Code: promoCode.Code}
+	switch promoCode.DiscountType {
+	case consts.DiscountPercentage:
+		discount.Amount = math.Round(terms.Amount*promoCode.DiscountValue) / 100
+	case consts.DiscountFixed:
+		// Fixed discounts are only given in their currency
+		if promoCode.CurrencyID != terms.CurrencyID {
+			utils.AppendValuesToMap(fieldsMap, consts.PromoCode, consts.NotSupported)
+			return nil, fieldsMap, nil
+		}
+		discount.Amount = min(promoCode.DiscountValue, terms.Amount)
+	}
+	return discount, nil, nil
+}
+
+// CreatePromoCode adds a promo code. Codes are case-insensitive and stored upper case. Codes added by
+// partner admins are owned by and apply to their own partner, they are unique within the partner.
+func (member *MemberUseCases) CreatePromoCode(ctx *gin.Context, request entities.PromoCodeRequest) (entities.PromoCode, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+
+	request.Code = strings.ToUpper(strings.TrimSpace(request.Code))
+	switch {
+	case request.Code == "":
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Required)
+	case len(request.Code) > consts.MaxPromoCodeLength:
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.TooLong)
+	case !promoCodePattern.MatchString(request.Code):
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.Invalid)
+	}
+
+	request.DiscountType = strings.ToLower(strings.TrimSpace(request.DiscountType))
+	if !slices.Contains(consts.DiscountTypes, request.DiscountType) {
+		utils.AppendValuesToMap(fieldsMap, consts.DiscountType, consts.Invalid)
+	}
+	if request.DiscountValue <= 0 || (request.DiscountType == consts.DiscountPercentage && request.DiscountValue > 100) {
+		utils.AppendValuesToMap(fieldsMap, consts.DiscountValue, consts.Invalid)
+	}
+	switch {
+	case request.DiscountType == consts.DiscountPercentage:
+		request.CurrencyID = 0
+	case request.CurrencyID <= 0:
+		utils.AppendValuesToMap(fieldsMap, consts.CurrencyID, consts.Required)
+	}
+
+	// Partner admins add codes for the members of their own partner only
+	var ownerPartnerID string
+	if scoped, ok := tenant.PartnerFrom(ctx); ok {
+		ownerPartnerID = scoped.String()
+		request.PartnerIDs = []string{ownerPartnerID}
+	}
+	for _, partnerID := range request.PartnerIDs {
+		if _, err := uuid.Parse(partnerID); err != nil {
+			utils.AppendValuesToMap(fieldsMap, consts.PartnerIDs, consts.Invalid)
+			break
+		}
+	}
+	for _, planID := range request.PlanIDs {
+		if _, err := uuid.Parse(planID); err != nil {
+			utils.AppendValuesToMap(fieldsMap, consts.PlanIDs, consts.Invalid)
+			break
+		}
+	}
+	if request.ValidUntil != nil && ((request.ValidFrom != nil && !request.ValidUntil.After(*request.ValidFrom)) ||
+		!request.ValidUntil.After(time.Now())) {
+		utils.AppendValuesToMap(fieldsMap, consts.ValidUntil, consts.Invalid)
+	}
+	if request.MaxRedemptions < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.MaxRedemptions, consts.Invalid)
+	}
+	if request.MaxRedemptionsPerMember < 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.MaxRedemptionsPerMember, consts.Invalid)
+	}
+	if len(fieldsMap) != 0 {
+		return entities.PromoCode{}, fieldsMap, nil
+	}
+
+	_, err := member.repo.GetPromoCode(ctx, ownerPartnerID, request.Code)
+	switch {
+	case err == nil:
+		utils.AppendValuesToMap(fieldsMap, consts.Code, consts.AlreadyExists)
+		return entities.PromoCode{}, fieldsMap, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		logger.Log().WithContext(ctx).Errorf("CreatePromoCode failed, err=%s", err.Error())
+		return entities.PromoCode{}, nil, err
+	}
+
+	promoCode, err := member.repo.CreatePromoCode(ctx, request)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("CreatePromoCode failed, err=%s", err.Error())
+		return entities.PromoCode{}, nil, err
+	}
+	return promoCode, nil, nil
+}
+
+// ListPromoCodes lists the promo codes with their redemptions so far and the total discount they
+// were given, the latest first.
+func (member *MemberUseCases) ListPromoCodes(ctx *gin.Context) ([]entities.PromoCode, error) {
+	promoCodes, err := member.repo.ListPromoCodes(ctx)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListPromoCodes failed, err=%s", err.Error())
+		return nil, err
+	}
+	return promoCodes, nil
+}
+
+// ListPromoCodeRedemptions lists the redemptions of a promo code, the latest first, with the status of
+// the redeeming subscriptions.
+func (member *MemberUseCases) ListPromoCodeRedemptions(ctx *gin.Context, promoCodeID string, params entities.PromoRedemptionParams) ([]entities.PromoRedemption, models.MetaData, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
+	id, err := uuid.Parse(promoCodeID)
+	if err != nil {
+		utils.AppendValuesToMap(validationErrors, consts.PromoCodeID, consts.Invalid)
+	}
+	if params.Limit > consts.MaximumLimit {
+		utils.AppendValuesToMap(validationErrors, consts.Limit, consts.Invalid)
+	}
+	if len(validationErrors) != 0 {
+		return nil, models.MetaData{}, validationErrors, nil
+	}
+
+	recordCount, err := member.repo.GetPromoCodeRedemptionCount(ctx, id)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListPromoCodeRedemptions failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	params.Page, params.Limit = utils.Paginate(params.Page, params.Limit, consts.LimitDefault)
+
+	redemptions, err := member.repo.GetPromoCodeRedemptions(ctx, id, params.Page, params.Limit)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListPromoCodeRedemptions failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	metadata := &models.MetaData{
+		CurrentPage: params.Page,
+		PerPage:     params.Limit,
+		Total:       recordCount,
+	}
+	metadata = utils.MetaDataInfo(metadata)
+
+	return redemptions, *metadata, nil, nil
+}
+
+// GetSubscriptionQuota returns the limits, usage and pending reservations of a member subscription.
+func (member *MemberUseCases) GetSubscriptionQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string) (entities.SubscriptionQuota, map[string][]string, error) {
+	if _, err := uuid.Parse(memberSubscriptionID); err != nil {
+		return entities.SubscriptionQuota{}, map[string][]string{consts.MemberSubscriptionID: {consts.Invalid}}, nil
+	}
+	subscriptionQuota, err := member.repo.GetSubscriptionQuota(ctx, memberID, memberSubscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entities.SubscriptionQuota{}, map[string][]string{consts.MemberSubscriptionID: {consts.NotFound}}, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetSubscriptionQuota failed, err=%s", err.Error())
+		return entities.SubscriptionQuota{}, nil, err
+	}
+	return subscriptionQuota, nil, nil
+}
+
+// CheckQuota answers whether the quantity of items of the resource can be added to a member
+// subscription now. The answer is not binding, ReserveQuota holds the capacity.
+func (member *MemberUseCases) CheckQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, request entities.QuotaRequest) (entities.QuotaCheck, map[string][]string, error) {
+	request, fieldsMap := quotaRequest(request)
+	if len(fieldsMap) != 0 {
+		return entities.QuotaCheck{}, fieldsMap, nil
+	}
+	subscriptionQuota, fieldsMap, err := member.GetSubscriptionQuota(ctx, memberID, memberSubscriptionID)
+	if err != nil || len(fieldsMap) != 0 {
+		return entities.QuotaCheck{}, fieldsMap, err
+	}
+
+	usage, _ := quota.Find(subscriptionQuota.Usage, request.Resource)
+	check := entities.QuotaCheck{
+		Resource: request.Resource,
+		Quantity: request.Quantity,
+		Allowed:  quota.Open(subscriptionQuota.Status) && quota.Allows(usage, request.Quantity),
+	}
+	if quota.Open(subscriptionQuota.Status) {
+		check.Available = usage.Available
+	}
+	return check, nil, nil
+}
+
+// ReserveQuota holds capacity of a member subscription for items of the resource the caller is
+// about to create. The caller commits the reservation once the items are created, or releases it
+// when their creation is rolled back. Reservations neither committed nor released expire.
+func (member *MemberUseCases) ReserveQuota(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, request entities.QuotaRequest) (entities.QuotaReservation, map[string][]string, error) {
+	request, fieldsMap := quotaRequest(request)
+	if _, err := uuid.Parse(memberSubscriptionID); err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.Invalid)
+	}
+	if len(fieldsMap) != 0 {
+		return entities.QuotaReservation{}, fieldsMap, nil
+	}
+
+	reservation, err := member.repo.ReserveQuota(ctx, entities.QuotaReservation{
+		MemberID:             memberID,
+		MemberSubscriptionID: memberSubscriptionID,
+		Resource:             request.Resource,
+		Quantity:             request.Quantity,
+	}, time.Duration(request.TTLSeconds)*time.Second)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.NotFound)
+	case errors.Is(err, consts.ErrSubscriptionClosed):
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.Closed)
+	case errors.Is(err, consts.ErrQuotaExceeded):
+		utils.AppendValuesToMap(fieldsMap, consts.Quantity, consts.LimitExceeds)
+	case err != nil:
+		logger.Log().WithContext(ctx).Errorf("ReserveQuota failed, err=%s", err.Error())
+		return entities.QuotaReservation{}, nil, err
+	}
+	if len(fieldsMap) != 0 {
+		return entities.QuotaReservation{}, fieldsMap, nil
+	}
+	return reservation, nil, nil
+}
+
+// CommitQuotaReservation confirms the items of a reservation were created, they now count as used.
+func (member *MemberUseCases) CommitQuotaReservation(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID string) (map[string][]string, error) {
+	return member.finishQuotaReservation(ctx, memberID, memberSubscriptionID, reservationID, consts.QuotaCommitted)
+}
+
+// ReleaseQuotaReservation gives the capacity of a reservation back, its items were not created.
+func (member *MemberUseCases) ReleaseQuotaReservation(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID string) (map[string][]string, error) {
+	return member.finishQuotaReservation(ctx, memberID, memberSubscriptionID, reservationID, consts.QuotaReleased)
+}
+
+// finishQuotaReservation moves a pending reservation to the committed or released status.
+func (member *MemberUseCases) finishQuotaReservation(ctx *gin.Context, memberID uuid.UUID, memberSubscriptionID string, reservationID string, status string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	if _, err := uuid.Parse(memberSubscriptionID); err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberSubscriptionID, consts.Invalid)
+	}
+	id, err := uuid.Parse(reservationID)
+	if err != nil {
+		utils.AppendValuesToMap(fieldsMap, consts.ReservationID, consts.Invalid)
+	}
+	if len(fieldsMap) != 0 {
+		return fieldsMap, nil
+	}
+
+	err = member.repo.FinishQuotaReservation(ctx, memberID, memberSubscriptionID, id, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.ReservationID, consts.NotFound)
+		return fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to move quota reservation %s to %s: %s", reservationID, status, err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ExpireQuotaReservations moves the pending quota reservations past their expiry to the expired status.
+func (member *MemberUseCases) ExpireQuotaReservations(ctx context.Context) error {
+	expired, err := member.repo.ExpireQuotaReservations(ctx)
+	if err != nil {
+		return err
+	}
+	if expired != 0 {
+		logger.Log().WithContext(ctx).Infof("Expired %d quota reservations", expired)
+	}
+	return nil
+}
+
+// quotaRequest validates a quota request and applies the default reservation time.
+func quotaRequest(request entities.QuotaRequest) (entities.QuotaRequest, map[string][]string) {
+	fieldsMap := map[string][]string{}
+	request.Resource = strings.ToLower(strings.TrimSpace(request.Resource))
+	if !quota.IsResource(request.Resource) {
+		utils.AppendValuesToMap(fieldsMap, consts.Resource, consts.Invalid)
+	}
+	if request.Quantity < 1 {
+		utils.AppendValuesToMap(fieldsMap, consts.Quantity, consts.Invalid)
+	}
+	switch {
+	case request.TTLSeconds == 0:
+		request.TTLSeconds = consts.DefaultQuotaReservationSeconds
+	case request.TTLSeconds < 0 || request.TTLSeconds > consts.MaxQuotaReservationSeconds:
+		utils.AppendValuesToMap(fieldsMap, consts.TTLSeconds, consts.Invalid)
+	}
+	return request, fieldsMap
+}
+
+// ListMemberAudit lists the audit trail of a member, newest first, optionally restricted to an action.
+func (member *MemberUseCases) ListMemberAudit(ctx *gin.Context, memberID uuid.UUID, params entities.AuditParams) ([]entities.AuditEntry, models.MetaData, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
+	memberExists, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListMemberAudit failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+	if !memberExists {
+		utils.AppendValuesToMap(validationErrors, consts.MemberrID, consts.NotFound)
+		return nil, models.MetaData{}, validationErrors, nil
+	}
+
+	if params.Limit > consts.MaximumLimit {
+		utils.AppendValuesToMap(validationErrors, consts.Limit, consts.Invalid)
+	}
+	params.Action = strings.ToLower(strings.TrimSpace(params.Action))
+	if params.Action != "" && !slices.Contains(consts.AuditActions, params.Action) {
+		utils.AppendValuesToMap(validationErrors, consts.Action, consts.Invalid)
+	}
+	if len(validationErrors) != 0 {
+		return nil, models.MetaData{}, validationErrors, nil
+	}
+
+	recordCount, err := member.repo.GetMemberAuditCount(ctx, memberID, params.Action)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListMemberAudit failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	params.Page, params.Limit = utils.Paginate(params.Page, params.Limit, consts.LimitDefault)
+
+	entries, err := member.repo.GetMemberAudit(ctx, memberID, params.Action, params.Page, params.Limit)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("ListMemberAudit failed, err=%s", err.Error())
+		return nil, models.MetaData{}, nil, err
+	}
+
+	metadata := &models.MetaData{
+		CurrentPage: params.Page,
+		PerPage:     params.Limit,
+		Total:       recordCount,
+	}
+	metadata = utils.MetaDataInfo(metadata)
+
+	return entries, *metadata, nil, nil
+}
+
+// HandleSubscriptionPlanChange upgrades or downgrades an active member subscription to another subscription plan.
+//
+// The expiration date is kept. The unused value of the current plan is credited and the new plan is
+// charged for the same remaining time, both prorated on the plan amounts and durations; the adjustment
+// is recorded as a ledger entry of the member subscription and returned. Upgrades are charged on the
+// payment gateway of the request, downgrades leave their credit on the ledger for the next renewal. The
+// new plan must be active, use the same currency and have room for the products, tracks and artists
+// already added.
+func (member *MemberUseCases) HandleSubscriptionPlanChange(ctx *gin.Context, memberID uuid.UUID, data entities.SubscriptionPlanChange,
+	partnerIDStr string) (entities.SubscriptionLedgerEntry, map[string][]string, error) {
+
+	fieldsMap := map[string][]string{}
+
+	if utilities.IsEmpty(data.MemberSubscriptionID) {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
+	} else if len(data.MemberSubscriptionID) > 36 {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.TooLong)
+	}
+	if utilities.IsEmpty(data.NewSubscriptionID) {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.Required)
+	} else if len(data.NewSubscriptionID) > 36 {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.TooLong)
+	}
+	if len(fieldsMap) > 0 {
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+
+	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: %s", err.Error())
+		return entities.SubscriptionLedgerEntry{}, nil, err
+	}
+	if !isPartnerValid {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.NoRelation)
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: member does not belong to partner")
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+
+	state, err := member.repo.GetMemberSubscriptionState(ctx, memberID, data.MemberSubscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotFound)
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: %s", err.Error())
+		return entities.SubscriptionLedgerEntry{}, nil, err
+	}
+	if state.Status != consts.SubscriptionStatusActive && state.Status != consts.SubscriptionStatusWarning {
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Inactive)
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: subscription is %s", state.Status)
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+	if state.SubscriptionID == data.NewSubscriptionID {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.AlreadyExist)
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+
+	// Plans are prorated at their default price, the upgrade is charged in it
+	current, err := member.repo.GetSubscriptionPlanTerms(ctx, state.SubscriptionID, "")
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan, loading current plan: %s", err.Error())
+		return entities.SubscriptionLedgerEntry{}, nil, err
+	}
+	next, err := member.repo.GetSubscriptionPlanTerms(ctx, data.NewSubscriptionID, "")
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.NotFound)
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan, loading new plan: %s", err.Error())
+		return entities.SubscriptionLedgerEntry{}, nil, err
+	}
+
+	if !next.IsActive {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.Inactive)
+	}
+	if next.CurrencyID != current.CurrencyID {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.CurrencyMismatch)
+	}
+	if state.ProductsAdded > next.MaximumProducts || state.TracksAdded > next.MaximumTracks || state.ArtistsAdded > next.MaximumArtists {
+		utils.AppendValuesToMap(fieldsMap, consts.NewSubscriptionID, consts.LimitExceeds)
+	}
+	if len(fieldsMap) > 0 {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: validation errors")
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+
+	credit, charge := prorate(current, next, state.ExpirationDate, time.Now())
+	entry := entities.SubscriptionLedgerEntry{
+		MemberSubscriptionID: data.MemberSubscriptionID,
+		MemberID:             memberID,
+		Kind:                 consts.LedgerKindPlanChange,
+		FromSubscriptionID:   current.SubscriptionID,
+		ToSubscriptionID:     next.SubscriptionID,
+		Credit:               credit,
+		Charge:               charge,
+		Amount:               math.Round((charge-credit)*100) / 100,
+		CurrencyID:           current.CurrencyID,
+	}
+	var paymentErrors map[string][]string
+	if entry.Amount > 0 {
+		// Upgrades are charged first, the plan changes with the payment record
+		paymentErrors, err = member.chargePlanChange(ctx, partnerIDStr, data.PaymentGatewayID, &entry)
+	} else {
+		// Downgrades are credited to the ledger, the next renewal uses the credit up
+		entry, err = member.repo.ChangeSubscriptionPlan(ctx, entry)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		// The plan was changed by a concurrent request.
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
+		return entities.SubscriptionLedgerEntry{}, fieldsMap, nil
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to change subscription plan: %s", err.Error())
+		return entities.SubscriptionLedgerEntry{}, nil, err
+	}
+	if len(paymentErrors) > 0 {
+		return entities.SubscriptionLedgerEntry{}, paymentErrors, nil
+	}
+
+	subscription := state.CustomName
+	if subscription == "" {
+		subscription = data.MemberSubscriptionID
+	}
+	member.notifyMember(ctx, memberID, consts.EventSubscriptionPlanChanged, map[string]interface{}{
+		"Subscription": subscription,
+		"Amount":       fmt.Sprintf("%.2f", math.Abs(entry.Amount)),
+		"Credited":     entry.Amount < 0,
+	})
+
+	return entry, nil, nil
+}
+
+// chargePlanChange charges the prorated amount of an upgrade on a payment gateway of the member's partner and
+// changes the plan with the payment record. The recorded ledger entry is set on entry.
+func (member *MemberUseCases) chargePlanChange(ctx context.Context, partnerID string, paymentGatewayID int,
+	entry *entities.SubscriptionLedgerEntry) (map[string][]string, error) {
+
+	if paymentGatewayID == 0 {
+		return map[string][]string{consts.PaymentGatewayID: {consts.Required}}, nil
+	}
+	gateway, credentials, fieldsMap, err := member.partnerGateway(ctx, partnerID, paymentGatewayID)
+	if err != nil || len(fieldsMap) > 0 {
+		return fieldsMap, err
+	}
+	_, fieldsMap, err = member.chargeSubscription(ctx, gateway, credentials, entry.ToSubscriptionID, entities.SubscriptionPayment{
+		MemberSubscriptionID: entry.MemberSubscriptionID,
+		MemberID:             entry.MemberID,
+		PaymentGatewayID:     paymentGatewayID,
+		Kind:                 consts.PaymentKindPlanChange,
+	}, nil, entry)
+	return fieldsMap, err
+}
+
+// prorate returns the unused value of the current plan and the value of the next plan over the time left
+// until expiration. Each plan is valued at its amount per duration; plans without a duration are worth nothing.
+func prorate(current, next entities.SubscriptionPlanTerms, expiration, now time.Time) (credit float64, charge float64) {
+	remaining := expiration.Sub(now)
+	if remaining <= 0 {
+		return 0, 0
+	}
+	value := func(terms entities.SubscriptionPlanTerms) float64 {
+		period := time.Duration(terms.DurationWeeks) * 7 * 24 * time.Hour
+		if period <= 0 {
+			return 0
+		}
+		share := math.Min(float64(remaining)/float64(period), 1)
+		return math.Round(terms.Amount*share*100) / 100
+	}
+	return value(current), value(next)
+}
+
+// ViewAllSubscriptions function
+func (member *MemberUseCases) ViewAllSubscriptions(ctx *gin.Context, memberID uuid.UUID, reqParam entities.ReqParams) ([]entities.ListAllSubscriptions, models.MetaData, map[string][]string, error) {
+
+	validationErrors := make(map[string][]string)
+
+	if status, exists := ctx.GetQuery("status"); exists && status != "" {
+		reqParam.Status = status
+	}
+
+	if search, exists := ctx.GetQuery("search"); exists && search != "" {
+		reqParam.Search = search
+	}
+
+	// Fetching sortBy from query parameter
+	if sortBy, exists := ctx.GetQuery("sort"); exists && sortBy != "" {
+		reqParam.Sort = sortBy
+	}
+
+	// Call GetSubscriptionRecordCount function in repo
+	recordCount, err := member.repo.GetSubscriptionRecordCount(ctx, memberID)
+
+	if err != nil {
+		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+	}
+
+	if reqParam.Limit > consts.MaximumLimit {
+		err := fmt.Errorf("Cannot exceed maximum page limit")
+		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+	}
+
+	if int64(reqParam.Page)*int64(reqParam.Limit)-recordCount >= int64(reqParam.Limit) { //check whether page parameters are valid
+		validationErrors := make(map[string][]string)
+		utils.AppendValuesToMap(validationErrors, consts.Page, consts.Invalid)
+		//return []entities.ListAllSubscriptions{}, models.MetaData{}, validationErrors, nil
+	}
+
+	//call Paginate function
+	reqParam.Page, reqParam.Limit = utils.Paginate(reqParam.Page, reqParam.Limit, consts.LimitDefault)
+
+	//call ViewAllSubscriptions function in repo
+	memberSubscriptions, err := member.repo.ViewAllSubscriptions(ctx, memberID, reqParam, &validationErrors)
+
+	if len(validationErrors) != 0 {
+		return []entities.ListAllSubscriptions{}, models.MetaData{}, validationErrors, nil
+	}
+
+	if err != nil {
+		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+	}
+
+	metadata := &models.MetaData{
+		CurrentPage: reqParam.Page,
+		PerPage:     reqParam.Limit,
+		Total:       recordCount,
+	}
+
+	metadata = utils.MetaDataInfo(metadata)
+
+	return memberSubscriptions, *metadata, nil, nil
+}
+
+// GetSubscriptionRecordCount function
+func (member *MemberUseCases) GetSubscriptionRecordCount(ctx context.Context, memberID uuid.UUID) (int64, error) {
+
+	//GetSubscriptionRecordCount function call to repo
+	totalCount, err := member.repo.GetSubscriptionRecordCount(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetSubscriptionRecordCount failed, err=%s", err.Error())
+		return 0, err
+	}
+	return totalCount, nil
+}
+
+// IsMemberExists checks if a member with the given memberId exists.
+// Parameters:
+//
+//	@ memberId: The UUID of the member to check for existence.
+//	@ ctx: The context for the database operation.
+//
+// Returns:
+//
+//	@ bool: True if the member exists, false otherwise.
+//	@ error: An error, if any, during the check.
+func (member *MemberUseCases) IsMemberExist(ctx context.Context, memberID uuid.UUID) (bool, error) {
+
+	_, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DeleteBillingAddress to delete a members billing address. A version other than 0 has to match the
+// version of the billing address.
+func (member *MemberUseCases) DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID, version int64) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	exists, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to delete this billing address: Invalid member")
+		return fieldsMap, nil
+	}
+	IsValid, err := member.repo.CheckBillingAddressRelation(ctx, memberID, memberBillingID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to delete billing address %s", err.Error())
+		return nil, err
+	}
+	if !IsValid {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberBilling, consts.InvalidAddress)
+		logger.Log().WithContext(ctx).Errorf("Failed to delete billing address")
+		return fieldsMap, nil
+	}
+
+	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
+	if len(fieldsMap) > 0 {
+		return fieldsMap, nil
+	}
+	err = member.repo.DeleteBillingAddress(ctx, memberID, memberBillingID, version)
+	return nil, err
+}
+
+// DeleteMember deletes a member by their ID. It first validates the member's existence,
+// checks if the member has already been deleted, and then performs the deletion operation.
+// A version other than 0 has to match the version of the member.
+func (member *MemberUseCases) DeleteMember(ctx *gin.Context, memberID string, version int64) (map[string][]string, error) {
+	// Initialize a map to store validation error messages and proceed with the deletion process.
+	fieldsMap := map[string][]string{}
+	// Parse the memberID string to a UUID.
+	MemberID, err := uuid.Parse(memberID)
+	// Check if there was an error parsing the memberID.
+	if err != nil {
+		return nil, err
+	}
+	// Check if the member with the given ID exists.
+	exists, err := member.repo.IsMemberExist(ctx, MemberID)
+	if err != nil {
+		return nil, err
+	}
+	// If the member does not exist, add an error message to the map.
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to delete this member: Invalid member")
+		return fieldsMap, nil
+	}
+	// Check if the member has already been deleted.
+	deleted, err := member.repo.IsDeleted(ctx, MemberID)
+	if deleted {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Deleted)
+		logger.Log().WithContext(ctx).Errorf("Member already deleted")
+		return fieldsMap, nil
+	}
+	// Delete the member.
+	err = member.repo.DeleteMember(ctx, MemberID, version)
+	if errors.Is(err, consts.ErrVersionMismatch) {
+		logger.Log().WithContext(ctx).Errorf("Failed to delete member %s: %s", MemberID, err.Error())
+		return nil, err
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to delete member %s", err.Error())
+	}
+	// Return nil for success (no validation errors).
+	return nil, nil
+}
+
+// AddMemberStores adds stores to a member, based on their partner
+func (member *MemberUseCases) AddMemberStores(ctx *gin.Context, memberID uuid.UUID, stores []string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	exists, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to add stores: %s", err.Error())
+		return nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Exists)
+		logger.Log().WithContext(ctx).Errorf("Failed to add member store: Member not found")
+		return fieldsMap, nil
+	}
+	memberStatus, err := member.repo.IsActive(ctx, memberID)
+	if !memberStatus {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.NotActive)
+		logger.Log().WithContext(ctx).Errorf("Failed to add member store: Member not Active")
+		return fieldsMap, nil
+	}
+
+	// Check if payload(store names) is present
+	if ctx.Request.ContentLength == 0 {
+		partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
+
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add stores: %s", err.Error())
+			return nil, err
+
+		}
+		storeID, err := member.repo.GetStoreIDsByPartnerID(ctx, partnerID)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting store ids!: %s", err.Error())
+			return nil, err
+		}
+		newIdList, err := member.repo.CheckNonExistingMemberStores(ctx, memberID, storeID)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting non redunatant store ids!: %s", err.Error())
+			return nil, err
+		}
+		if len(newIdList) == 0 {
+			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.StoresExists)
+			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Stores already exists for this member")
+			return fieldsMap, nil
+		}
+		err = member.repo.AddMemberStoresById(ctx, memberID, newIdList)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add stores!!: %s", err.Error())
+			return nil, err
+		}
+	} else {
+		partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add member stores: %s", err.Error())
+			return nil, err
+		}
+		relatedStoreIDs, fieldsMap, err := member.partnerStoreIDs(ctx, partnerID, stores)
+		if err != nil || len(fieldsMap) != 0 {
+			return fieldsMap, err
+		}
+		newIdList, err := member.repo.CheckNonExistingMemberStores(ctx, memberID, relatedStoreIDs)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting non redunatant store ids!: %s", err.Error())
+			return nil, err
+		}
+		if len(newIdList) == 0 {
+			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.StoresExists)
+			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Stores already exists for this member")
+			return fieldsMap, nil
+		}
+		//AddMemberStoresById addes stores under given member
+		err = member.repo.AddMemberStoresById(ctx, memberID, newIdList)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to add member stores: %s", err.Error())
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// partnerStoreIDs resolves store names to store IDs, the stores have to be offered by the partner.
+// Empty lists, unknown names and stores of other partners are reported as validation errors.
+func (member *MemberUseCases) partnerStoreIDs(ctx *gin.Context, partnerID uuid.UUID, stores []string) ([]uuid.UUID, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	if len(stores) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Empty)
+		logger.Log().WithContext(ctx).Errorf("Failed to resolve member stores: Empty store name list")
+		return nil, fieldsMap, nil
+	}
+	storeExist, storeIDs, err := member.repo.CheckStoreNameExistsAndReturnIDs(ctx, stores)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to resolve member stores: %s", err.Error())
+		return nil, nil, err
+	}
+	if !storeExist {
+		utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Invalid)
+		logger.Log().WithContext(ctx).Errorf("Failed to resolve member stores: Invalid store name")
+		return nil, fieldsMap, nil
+	}
+	fieldsMap, err = member.checkStoreRelation(ctx, partnerID, storeIDs, consts.Name)
+	return storeIDs, fieldsMap, err
+}
+
+// checkStoreRelation checks the partner offers the stores to its members. Stores of other partners
+// are reported as no_relation validation errors of key.
+func (member *MemberUseCases) checkStoreRelation(ctx *gin.Context, partnerID uuid.UUID, storeIDs []uuid.UUID, key string) (map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	related, err := member.repo.StorePartnerRelation(ctx, partnerID, storeIDs)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check the partner of member stores: %s", err.Error())
+		return nil, err
+	}
+	for _, value := range related {
+		if !value {
+			utils.AppendValuesToMap(fieldsMap, key, consts.NoRelation)
+			logger.Log().WithContext(ctx).Errorf("Failed to check the partner of member stores: Store not related to partner")
+			break
+		}
+	}
+	return fieldsMap, nil
+}
+
+// checkStoreMember checks the member exists and returns its partner.
+func (member *MemberUseCases) checkStoreMember(ctx *gin.Context, memberID uuid.UUID) (uuid.UUID, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	exists, err := member.repo.IsMemberExist(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to check member of stores: %s", err.Error())
+		return uuid.Nil, nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.NotFound)
+		return uuid.Nil, fieldsMap, nil
+	}
+	partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to get partner of member %s: %s", memberID, err.Error())
+		return uuid.Nil, nil, err
+	}
+	return partnerID, fieldsMap, nil
+}
+
+// ListMemberStores returns the stores of a member in the member's order.
+func (member *MemberUseCases) ListMemberStores(ctx *gin.Context, memberID uuid.UUID) ([]entities.MemberStore, map[string][]string, error) {
+	_, fieldsMap, err := member.checkStoreMember(ctx, memberID)
+	if err != nil || len(fieldsMap) != 0 {
+		return nil, fieldsMap, err
+	}
+	stores, err := member.repo.GetMemberStores(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to list member stores: %s", err.Error())
+		return nil, nil, err
+	}
+	return stores, nil, nil
+}
 
-	//IsSubscriptionAboutInWarning checks if the subscription is in warning period ,
-	// also notifies member about payment to proceed with renewal
-	aboutToExpire, _, err := member.repo.IsSubscriptionAboutInWarning(ctx, checkoutData.MemberSubscriptionID)
-	if aboutToExpire {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.AboutToExpire)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan is about to expire,Make Payment to renew")
-		return fieldsMap, nil
+// UpdateMemberStore renames or (de)activates a store of a member. A store can only be activated
+// while the partner of the member offers it.
+func (member *MemberUseCases) UpdateMemberStore(ctx *gin.Context, memberID uuid.UUID, storeID string, update entities.MemberStoreUpdate) (map[string][]string, error) {
+	id, err := uuid.Parse(storeID)
+	if err != nil {
+		return map[string][]string{consts.StoreID: {consts.Invalid}}, nil
+	}
+	partnerID, fieldsMap, err := member.checkStoreMember(ctx, memberID)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, err
 	}
 
-	//IsSubscriptionInGracePeriod checks if the subscription is in grace period ,
-	// also notifies member about payment to proceed with renewal before expiration.
-	inGrace, _, graceEnd, _, _, err := member.repo.IsSubscriptionInGracePeriod(ctx, memberID, checkoutData.MemberSubscriptionID)
-	if inGrace {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.InGrace)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan is in grace period,Make Payment to renew")
+	if update.CustomName == nil && update.IsActive == nil {
+		utils.AppendValuesToMap(fieldsMap, consts.StoreID, consts.Empty)
 		return fieldsMap, nil
 	}
-	//checks if subscription plan expired
-	isExpired := time.Now().After(graceEnd)
-	if isExpired {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Expired)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Plan expired")
-		return fieldsMap, nil
+	if update.CustomName != nil {
+		name := strings.TrimSpace(*update.CustomName)
+		if len(name) > consts.MaxStoreNameLength {
+			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Maximum)
+			return fieldsMap, nil
+		}
+		update.CustomName = &name
+	}
+	if update.IsActive != nil && *update.IsActive {
+		fieldsMap, err = member.checkStoreRelation(ctx, partnerID, []uuid.UUID{id}, consts.StoreID)
+		if err != nil || len(fieldsMap) != 0 {
+			return fieldsMap, err
+		}
 	}
 
-	//GetSubscriptionStatusName checks the status of current subscription
-	currentStatus, err := member.repo.GetSubscriptionStatusName(ctx, checkoutData.MemberSubscriptionID)
+	err = member.repo.UpdateMemberStore(ctx, memberID, id, update)
+	if errors.Is(err, sql.ErrNoRows) {
+		return map[string][]string{consts.StoreID: {consts.NotFound}}, nil
+	}
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to renew current subscription plan, error in checking if the subscription was free: %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("Failed to update member store: %s", err.Error())
 		return nil, err
 	}
-	if currentStatus != "active" && currentStatus != "on_hold" && currentStatus != "payment_failed" {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CheckStatus)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Check the current status of the plan")
-		return fieldsMap, nil
+	return nil, nil
+}
+
+// RemoveMemberStore removes a store from a member.
+func (member *MemberUseCases) RemoveMemberStore(ctx *gin.Context, memberID uuid.UUID, storeID string) (map[string][]string, error) {
+	id, err := uuid.Parse(storeID)
+	if err != nil {
+		return map[string][]string{consts.StoreID: {consts.Invalid}}, nil
+	}
+	_, fieldsMap, err := member.checkStoreMember(ctx, memberID)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, err
 	}
 
-	//IsMemberSubscribedToFreePlan checks if the plan is free, if so it cannot be renewed
-	subscribedFree, err := member.repo.IsMemberSubscribedToFreePlan(ctx, memberID, checkoutData.MemberSubscriptionID)
+	removed, err := member.repo.RemoveMemberStores(ctx, memberID, []uuid.UUID{id})
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to renew current subscription plan, error in checking if the subscription was free: %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("Failed to remove member store: %s", err.Error())
 		return nil, err
 	}
-	if subscribedFree {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Subscribed)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: cannot renew free plan")
+	if removed == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.StoreID, consts.NotFound)
 		return fieldsMap, nil
+	}
+	return nil, nil
+}
 
+// ReorderMemberStores orders the stores of a member. The order has to list every store of the
+// member exactly once.
+func (member *MemberUseCases) ReorderMemberStores(ctx *gin.Context, memberID uuid.UUID, order entities.MemberStoreOrder) (map[string][]string, error) {
+	_, fieldsMap, err := member.checkStoreMember(ctx, memberID)
+	if err != nil || len(fieldsMap) != 0 {
+		return fieldsMap, err
 	}
 
-	//Check if PaymentGatewayID is empty
-	if checkoutData.PaymentGatewayID == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Missing required fields")
+	stores, err := member.repo.GetMemberStores(ctx, memberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to reorder member stores: %s", err.Error())
+		return nil, err
 	}
-	if len(checkoutData.MemberSubscriptionID) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: Member Subscription Id is mandatory")
+	current := make(map[uuid.UUID]bool, len(stores))
+	for _, store := range stores {
+		current[store.StoreID] = true
 	}
-	if len(checkoutData.MemberSubscriptionID) == 0 {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Missing required fields")
-		return fieldsMap, nil
+	seen := make(map[uuid.UUID]bool, len(order.Stores))
+	for _, storeID := range order.Stores {
+		if !current[storeID] || seen[storeID] {
+			utils.AppendValuesToMap(fieldsMap, consts.Stores, consts.Invalid)
+			return fieldsMap, nil
+		}
+		seen[storeID] = true
 	}
-	if len(checkoutData.MemberSubscriptionID) > 36 {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.TooLong)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Subscription Id too long/exceeds limit")
+	if len(seen) != len(current) {
+		utils.AppendValuesToMap(fieldsMap, consts.Stores, consts.Invalid)
 		return fieldsMap, nil
 	}
 
-	//CheckIfPayoutGatewayExists checks if the payment gateway entered is valid or not
-	gatewayExists, err := member.repo.CheckIfPayoutGatewayExists(ctx, checkoutData.PaymentGatewayID)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to renew Subscription:Error in checking gateway existance %s", err.Error())
+	if err := member.repo.ReorderMemberStores(ctx, memberID, order.Stores); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to reorder member stores: %s", err.Error())
 		return nil, err
 	}
+	return nil, nil
+}
 
-	if !gatewayExists {
-		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan:Invalid gateway")
-		return fieldsMap, nil
+// GetPartnerStores returns the catalogue of stores the partner offers to its members.
+func (member *MemberUseCases) GetPartnerStores(ctx *gin.Context, partnerID uuid.UUID) ([]entities.PartnerStore, map[string][]string, error) {
+	fieldsMap, err := member.checkPartnerAccess(ctx, partnerID)
+	if err != nil || len(fieldsMap) != 0 {
+		return nil, fieldsMap, err
+	}
+	stores, err := member.repo.GetPartnerStores(ctx, partnerID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to list stores of partner %s: %s", partnerID, err.Error())
+		return nil, nil, err
 	}
+	return stores, nil, nil
+}
 
-	//IsPartnerIdCorrespondsToGateway checks if the entred payment gateway is approved/endorsed by the corresponding partner
-	PartnerGatewayExists, err := member.repo.IsPartnerIdCorrespondsToGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
-	if !PartnerGatewayExists {
-		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NoRelation)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: This payment gateway is not endorsed by partner")
+// BulkMemberStores applies stores to, or removes stores from, many members. The stores are
+// validated against the partner of every member, members failing validation are reported in
+// their result without stopping the others.
+func (member *MemberUseCases) BulkMemberStores(ctx *gin.Context, request entities.BulkMemberStores) ([]entities.BulkMemberStoresResult, map[string][]string, error) {
+	fieldsMap := map[string][]string{}
+	request.Action = strings.ToLower(strings.TrimSpace(request.Action))
+	if request.Action != consts.BulkStoresApply && request.Action != consts.BulkStoresRemove {
+		utils.AppendValuesToMap(fieldsMap, consts.Action, consts.Invalid)
+	}
+	switch {
+	case len(request.MemberIDs) == 0:
+		utils.AppendValuesToMap(fieldsMap, consts.MemberIDs, consts.Required)
+	case len(request.MemberIDs) > consts.MaxBulkStoreMembers:
+		utils.AppendValuesToMap(fieldsMap, consts.MemberIDs, consts.Maximum)
+	}
+	if len(request.Stores) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.Stores, consts.Required)
+	}
+	if len(fieldsMap) != 0 {
+		return nil, fieldsMap, nil
 	}
-	if PartnerGatewayExists {
-		// If the partner ID corresponds to the payment gateway, retrieve payment details.
-		paymentDetails, err := member.repo.GetPaymentDetailsByPartnerAndGateway(ctx, partnerIDStr, checkoutData.PaymentGatewayID)
 
-		if len(paymentDetails) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NoDetails)
-			logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: No payment details found for the partner and payment gateway")
-			return fieldsMap, nil
-		}
+	// Members of the same partner share the resolved stores
+	type partnerStores struct {
+		storeIDs  []uuid.UUID
+		fieldsMap map[string][]string
+	}
+	resolved := map[uuid.UUID]partnerStores{}
 
-		detailsString, err := member.repo.DecryptPaymentData(ctx, paymentDetails)
+	results := make([]entities.BulkMemberStoresResult, 0, len(request.MemberIDs))
+	for _, rawID := range request.MemberIDs {
+		result := entities.BulkMemberStoresResult{MemberID: rawID, Status: consts.BulkStoresFailed}
+		memberID, err := uuid.Parse(rawID)
 		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to renew Subscription:Error in decrypting key %s", err.Error())
-			return nil, err
+			result.Errors = map[string][]string{consts.MemberID: {consts.Invalid}}
+			results = append(results, result)
+			continue
 		}
 
-		var paymentInfo entities.PaymentGatewayDetails
-
-		// Unmarshal JSON string into the PaymentGatewayDetails struct
-		err = json.Unmarshal([]byte(detailsString), &paymentInfo)
+		partnerID, memberErrors, err := member.checkStoreMember(ctx, memberID)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		if !paymentInfo.Payin {
-			utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NoPayin)
-			logger.Log().WithContext(ctx).Errorf("Failed to checkout this plan: pay-in transaction is not supported by this payment gateway.")
-			return fieldsMap, nil
+		if len(memberErrors) != 0 {
+			result.Errors = memberErrors
+			results = append(results, result)
+			continue
+		}
+
+		stores, ok := resolved[partnerID]
+		if !ok {
+			stores.storeIDs, stores.fieldsMap, err = member.partnerStoreIDs(ctx, partnerID, request.Stores)
+			if err != nil {
+				return nil, nil, err
+			}
+			resolved[partnerID] = stores
+		}
+		if len(stores.fieldsMap) != 0 {
+			result.Errors = stores.fieldsMap
+			results = append(results, result)
+			continue
 		}
 
+		result.Status = consts.BulkStoresUnchanged
+		if request.Action == consts.BulkStoresApply {
+			newStoreIDs, err := member.repo.CheckNonExistingMemberStores(ctx, memberID, stores.storeIDs)
+			if err != nil {
+				logger.Log().WithContext(ctx).Errorf("Bulk member stores failed for member %s: %s", memberID, err.Error())
+				return nil, nil, err
+			}
+			if len(newStoreIDs) != 0 {
+				if err := member.repo.AddMemberStoresById(ctx, memberID, newStoreIDs); err != nil {
+					logger.Log().WithContext(ctx).Errorf("Bulk member stores failed for member %s: %s", memberID, err.Error())
+					return nil, nil, err
+				}
+				result.Status = consts.BulkStoresApplied
+			}
+		} else {
+			removed, err := member.repo.RemoveMemberStores(ctx, memberID, stores.storeIDs)
+			if err != nil {
+				logger.Log().WithContext(ctx).Errorf("Bulk member stores failed for member %s: %s", memberID, err.Error())
+				return nil, nil, err
+			}
+			if removed != 0 {
+				result.Status = consts.BulkStoresRemoved
+			}
+		}
+		results = append(results, result)
 	}
-	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
-	if len(fieldsMap) > 0 {
-		return fieldsMap, nil
+	return results, nil, nil
+}
+
+// chargeSubscription charges the invoiced price of a subscription payment on the partner's gateway, records the
+// payment and returns its status. Gateways charging asynchronously report the payment as pending and confirm it
+// through the payment webhook. Captured and pending payments are invoiced, a payment that does not succeed is
+// returned as a payment_gateway_id validation error. A failed renewal is left to the lifecycle sweep.
+func (member *MemberUseCases) chargeSubscription(ctx context.Context, gateway payment.Gateway, credentials entities.PaymentGatewayDetails,
+	subscriptionID string, record entities.SubscriptionPayment, discount *entities.PromoDiscount,
+	change *entities.SubscriptionLedgerEntry) (string, map[string][]string, error) {
+
+	invoice, credit, fieldsMap, err := member.priceSubscription(ctx, credentials, subscriptionID, record, discount, change)
+	if err != nil || len(fieldsMap) > 0 {
+		return "", fieldsMap, err
+	}
+
+	request := entities.PaymentRequest{
+		Reference:   record.MemberSubscriptionID,
+		Amount:      invoice.Total,
+		Currency:    invoice.Currency,
+		Credentials: credentials,
+	}
+	record.Amount = request.Amount
+	record.Currency = request.Currency
+
+	var result entities.PaymentResult
+	if request.Amount > 0 {
+		result, err = gateway.Authorize(ctx, request)
+		if err == nil && result.Status == consts.PaymentStatusAuthorized {
+			result, err = gateway.Capture(ctx, request, result.ID)
+		}
+	} else {
+		// Paid in full with the ledger credit, nothing to charge on the gateway
+		result.Status = consts.PaymentStatusCaptured
+	}
+	if err != nil {
+		record.Status = consts.PaymentStatusFailed
+		record.FailureReason = err.Error()
+	} else {
+		record.Status = result.Status
+		record.GatewayPaymentID = result.ID
+		record.FailureReason = result.FailureReason
 	}
 
-	// Handle the subscription checkout by calling the HandleSubscriptionCheckout method from the repository.
-	//HandleSubscriptionRenewal  sets a new expiration time and updates the renewed on date .
-	err = member.repo.HandleSubscriptionRenewal(ctx, memberID, checkoutData)
+	paymentID, recordErr := member.recordSubscriptionPayment(ctx, gateway, request, record, credit, change)
+	if recordErr != nil {
+		return "", nil, recordErr
+	}
 
-	// Check if there was an error during the checkout process.
+	if record.Status == consts.PaymentStatusCaptured || record.Status == consts.PaymentStatusPending {
+		// The member has been charged, a missing invoice must not fail the checkout or renewal.
+		invoice.PaymentID = paymentID
+		if _, err := member.repo.CreateInvoice(ctx, invoice); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to invoice payment %s of subscription %s: %s", paymentID, record.MemberSubscriptionID, err.Error())
+		}
+		return record.Status, nil, nil
+	}
+
+	logger.Log().WithContext(ctx).Errorf("Payment of subscription %s failed on %s: %s", record.MemberSubscriptionID, gateway.Name(), record.FailureReason)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to renew Subscription: %s", err.Error())
-		return nil, err
+		return "", nil, err
 	}
 
-	return fieldsMap, nil
+	fieldsMap = map[string][]string{}
+	utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.PaymentFailed)
+	return record.Status, fieldsMap, nil
 }
 
-// HandleSubscriptionCancellation handles the checkout of a subscription for a member.
-// It takes the
-//   - context
-//   - memberID
-//     as parameters.
-//
-// It returns a map with subscription details and any encountered errors.
-func (member *MemberUseCases) HandleSubscriptionCancellation(ctx *gin.Context, memberID uuid.UUID, checkoutData entities.CancelSubscription, partnerIDStr string) (map[string][]string, error) {
-
-	fieldsMap := map[string][]string{}
+// priceSubscription returns the invoice of a subscription payment in the currency of the gateway and the ledger
+// credit it uses up. Renewals use up the credit of the subscription first, plan changes are charged the prorated
+// amount of their ledger entry instead of the plan price.
+func (member *MemberUseCases) priceSubscription(ctx context.Context, credentials entities.PaymentGatewayDetails, subscriptionID string,
+	record entities.SubscriptionPayment, discount *entities.PromoDiscount,
+	change *entities.SubscriptionLedgerEntry) (entities.Invoice, *entities.SubscriptionLedgerEntry, map[string][]string, error) {
 
-	// Check if SubscriptionID is empty
-	// Ensure the provided partnerID matches the partnerID associated with the member.
-	isPartnerValid, err := member.repo.CheckMemberPartner(ctx, memberID, partnerIDStr)
+	terms, err := member.repo.GetSubscriptionPlanTerms(ctx, subscriptionID, credentials.DefaultPayinCurrency)
+	if errors.Is(err, consts.ErrPlanPriceNotFound) {
+		logger.Log().WithContext(ctx).Errorf("Subscription plan %s has no price in %s", subscriptionID, credentials.DefaultPayinCurrency)
+		return entities.Invoice{}, nil, map[string][]string{consts.PaymentGatewayID: {consts.CurrencyMismatch}}, nil
+	}
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check member partner: %s", err.Error())
-		return nil, err
+		logger.Log().WithContext(ctx).Errorf("Failed to load the price of subscription plan %s: %s", subscriptionID, err.Error())
+		return entities.Invoice{}, nil, nil, err
 	}
-	if !isPartnerValid {
-		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.AuthenticationFailed)
-		logger.Log().WithContext(ctx).Errorf("Partner authentication failed for member: %s", memberID)
-		return fieldsMap, nil
+	profile, err := member.repo.GetMemberBillingProfile(ctx, record.MemberID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to load the billing profile of member %s: %s", record.MemberID, err.Error())
+		return entities.Invoice{}, nil, nil, err
+	}
+	var credit *entities.SubscriptionLedgerEntry
+	switch record.Kind {
+	case consts.PaymentKindPlanChange:
+		// The prorated amount is in the currency of the ledger entry
+		if change.CurrencyID != terms.CurrencyID {
+			logger.Log().WithContext(ctx).Errorf("Plan change of subscription %s cannot be charged in %s", record.MemberSubscriptionID, credentials.DefaultPayinCurrency)
+			return entities.Invoice{}, nil, map[string][]string{consts.PaymentGatewayID: {consts.CurrencyMismatch}}, nil
+		}
+		terms.Amount = change.Amount
+	case consts.PaymentKindRenewal:
+		credit, err = member.renewalCredit(ctx, terms, record)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to load the credit of subscription %s: %s", record.MemberSubscriptionID, err.Error())
+			return entities.Invoice{}, nil, nil, err
+		}
 	}
+	return buildInvoice(terms, profile, record, discount, credit), credit, nil, nil
+}
 
-	//CheckSubscriptionExistenceAndStatusForRenewal fetches the subscription plan from member subscription  id and check
-	//if such a plan exists and is currently active(CheckSubscriptionExistenceAndStatusForRenewal , actually for cancellation,repo function reused)
-	subExists, isActive, err := member.repo.CheckSubscriptionExistenceAndStatusForRenewal(ctx, checkoutData.MemberSubscriptionID)
-	if !subExists {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid subscription plan")
-		return fieldsMap, nil
+// recordSubscriptionPayment records a subscription payment and moves the subscription along with it: captured
+// renewals are renewed, checkouts activated or moved to payment_failed, and plan changes applied with change set
+// to the recorded entry. A captured payment that cannot be recorded is refunded.
+func (member *MemberUseCases) recordSubscriptionPayment(ctx context.Context, gateway payment.Gateway, request entities.PaymentRequest,
+	record entities.SubscriptionPayment, credit *entities.SubscriptionLedgerEntry, change *entities.SubscriptionLedgerEntry) (uuid.UUID, error) {
+
+	var (
+		paymentID uuid.UUID
+		err       error
+	)
+	switch record.Kind {
+	case consts.PaymentKindRenewal:
+		paymentID, err = member.repo.RecordRenewalPayment(ctx, record, credit)
+	case consts.PaymentKindPlanChange:
+		paymentID, *change, err = member.repo.RecordPlanChangePayment(ctx, record, *change)
+	case consts.PaymentKindCheckout:
+		paymentID, err = member.repo.RecordCheckoutPayment(ctx, record)
+	default:
+		paymentID, err = member.repo.RecordSubscriptionPayment(ctx, record)
 	}
-	if !isActive {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Inactive)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Inactive subscription plan")
-		return fieldsMap, nil
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to record payment of subscription %s: %s", record.MemberSubscriptionID, err.Error())
+		if record.Status == consts.PaymentStatusCaptured && record.GatewayPaymentID != "" {
+			// The subscription was not updated, give the captured amount back
+			member.refundUnrecordedPayment(ctx, gateway, request, record)
+		}
+		return uuid.Nil, err
 	}
+	return paymentID, nil
+}
 
-	//IsMemberSubscribedToPlan checks if member subscribed to the plan, before proceeding with renewal
-	yesSubscribed, err := member.repo.IsMemberSubscribedToPlan(ctx, memberID, checkoutData.MemberSubscriptionID)
-	if !yesSubscribed {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotSubscribedYet)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Not yet subscribed this plan")
-		return fieldsMap, nil
-	}
+// refundUnrecordedPayment refunds a captured payment whose record failed, the member is not charged for
+// a subscription that was not updated. A failed refund is logged for a manual refund.
+func (member *MemberUseCases) refundUnrecordedPayment(ctx context.Context, gateway payment.Gateway, request entities.PaymentRequest,
+	record entities.SubscriptionPayment) {
 
-	//HasProductsReleaseEndDateGreaterThanToday checks if  there are released products that havenot yet reached end date.
-	yesProduct, err := member.repo.HasProductsReleaseEndDateGreaterThanToday(ctx, checkoutData.MemberSubscriptionID)
-	if yesProduct {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CannotCancel)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: There are released products under this subscription")
-		return fieldsMap, nil
+	result, err := gateway.Refund(ctx, request, record.GatewayPaymentID)
+	if err == nil && result.Status != consts.PaymentStatusRefunded {
+		err = errors.New(result.FailureReason)
 	}
-	isRelated, err := member.repo.IsMemberRelatedToSubscription(ctx, memberID, checkoutData.MemberSubscriptionID)
-	if !isRelated {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to renew this plan: Invalid member subscription id")
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to refund unrecorded payment %s of subscription %s on %s: %s",
+			record.GatewayPaymentID, record.MemberSubscriptionID, gateway.Name(), err.Error())
 	}
+}
 
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check subscription status: %v", err)
-		return fieldsMap, err
+// renewalCredit returns the ledger entry using up the credit left on a member subscription for its renewal,
+// at most the plan price in the currency charged, or nil when there is no credit.
+func (member *MemberUseCases) renewalCredit(ctx context.Context, terms entities.SubscriptionPlanTerms,
+	record entities.SubscriptionPayment) (*entities.SubscriptionLedgerEntry, error) {
+
+	balance, err := member.repo.GetSubscriptionBalance(ctx, record.MemberSubscriptionID)
+	if err != nil || balance >= 0 {
+		return nil, err
 	}
+	used := math.Min(-balance, terms.Amount)
+	return &entities.SubscriptionLedgerEntry{
+		MemberSubscriptionID: record.MemberSubscriptionID,
+		MemberID:             record.MemberID,
+		Kind:                 consts.LedgerKindRenewalCredit,
+		FromSubscriptionID:   terms.SubscriptionID,
+		Charge:               used,
+		Amount:               used,
+		CurrencyID:           terms.CurrencyID,
+	}, nil
+}
 
-	//CheckCancellationEnabled checks if this subscription is cancellation enabled.
-	enabled, err := member.repo.CheckCancellationEnabled(ctx, checkoutData.MemberSubscriptionID)
-	if !enabled {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.NotEnabled)
-		logger.Log().WithContext(ctx).Errorf("Failed to cancel this subscription: This is not cancellation enabled")
-		return fieldsMap, nil
+// buildInvoice returns the invoice of a subscription payment in the currency of the plan price: the plan as
+// line item, the discount of a promo code and the ledger credit used up as negative line items, the plan's tax
+// on the discounted price when the member pays tax, and the member's name, email and primary billing address
+// as they are now.
+func buildInvoice(terms entities.SubscriptionPlanTerms, profile entities.BillingProfile, record entities.SubscriptionPayment,
+	discount *entities.PromoDiscount, credit *entities.SubscriptionLedgerEntry) entities.Invoice {
+
+	description := fmt.Sprintf("%s subscription", terms.Name)
+	if terms.DurationWeeks > 0 {
+		description = fmt.Sprintf("%s subscription, %d weeks", terms.Name, terms.DurationWeeks)
+	}
+	switch record.Kind {
+	case consts.PaymentKindRenewal:
+		description += " (renewal)"
+	case consts.PaymentKindPlanChange:
+		description = fmt.Sprintf("Change to %s subscription, prorated", terms.Name)
+	}
+
+	invoice := entities.Invoice{
+		MemberID:             record.MemberID,
+		MemberSubscriptionID: record.MemberSubscriptionID,
+		Kind:                 record.Kind,
+		Currency:             terms.Currency,
+		Lines: []entities.InvoiceLine{
+			{Description: description, Quantity: 1, UnitAmount: terms.Amount, Amount: terms.Amount},
+		},
+		Subtotal:     terms.Amount,
+		BillingName:  profile.Name,
+		BillingEmail: profile.Email,
+	}
+	if discount != nil && discount.Amount > 0 {
+		invoice.Lines = append(invoice.Lines, entities.InvoiceLine{
+			Description: fmt.Sprintf("Promo code %s", discount.Code), Quantity: 1, UnitAmount: -discount.Amount, Amount: -discount.Amount,
+		})
+		invoice.Subtotal = math.Round((terms.Amount-discount.Amount)*100) / 100
+	}
+	if credit != nil && credit.Charge > 0 {
+		invoice.Lines = append(invoice.Lines, entities.InvoiceLine{
+			Description: "Account credit", Quantity: 1, UnitAmount: -credit.Charge, Amount: -credit.Charge,
+		})
+		invoice.Subtotal = math.Round((invoice.Subtotal-credit.Charge)*100) / 100
+	}
+	if profile.PayingTax {
+		invoice.TaxPercentage = terms.TaxPercentage
+		invoice.TaxAmount = math.Round(invoice.Subtotal*terms.TaxPercentage) / 100
+	}
+	invoice.Total = math.Round((invoice.Subtotal+invoice.TaxAmount)*100) / 100
+	if profile.BillingAddress != nil {
+		invoice.BillingAddress = *profile.BillingAddress
+	}
+	return invoice
+}
+
+// refundSubscription refunds the unused share of the last captured payment of a member subscription, prorated
+// over the time left until its expiration, on the gateway it was made with, and records the refunded credit in
+// the ledger. Subscriptions without captured payments, such as free ones, or without time left have nothing to refund.
+func (member *MemberUseCases) refundSubscription(ctx *gin.Context, partnerID string, memberID uuid.UUID, memberSubscriptionID string) (map[string][]string, error) {
+	captured, err := member.repo.GetLatestCapturedPayment(ctx, memberSubscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to check subscription status: %v", err)
-		return fieldsMap, err
+		logger.Log().WithContext(ctx).Errorf("Failed to load the payment of subscription %s: %s", memberSubscriptionID, err.Error())
+		return nil, err
 	}
 
-	//GetSubscriptionStatusName gets the current status of the subscription to be cancelled.
-	currentStatus, err := member.repo.GetSubscriptionStatusName(ctx, checkoutData.MemberSubscriptionID)
-
-	if currentStatus != "active" && currentStatus != "on_hold" {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.CheckStatus)
-		logger.Log().WithContext(ctx).Errorf("Failed to cancel this plan: Check the current status of the plan")
-		return fieldsMap, nil
+	state, err := member.repo.GetMemberSubscriptionState(ctx, memberID, memberSubscriptionID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to refund subscription %s: %s", memberSubscriptionID, err.Error())
+		return nil, err
 	}
+	terms, err := member.repo.GetSubscriptionPlanTerms(ctx, state.SubscriptionID, captured.Currency)
 	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to refund subscription %s: %s", memberSubscriptionID, err.Error())
 		return nil, err
-
 	}
-	if checkoutData.MemberSubscriptionID == "" {
-		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.Required)
-		logger.Log().WithContext(ctx).Errorf("Failed to cancel this subscription: Missing required fields")
-		return fieldsMap, nil
+	// The captured amount is what the member paid for the plan's duration, after discounts and tax
+	paid := terms
+	paid.Amount = captured.Amount
+	credit, _ := prorate(paid, entities.SubscriptionPlanTerms{}, state.ExpirationDate, time.Now())
+	if credit <= 0 {
+		return nil, nil
 	}
-	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
-	if len(fieldsMap) > 0 {
-		return fieldsMap, nil
+
+	// Refunds go back through the gateway the payment was made with
+	gateway, credentials, gatewayErrors, err := member.partnerGateway(ctx, partnerID, captured.PaymentGatewayID)
+	if err != nil || len(gatewayErrors) > 0 {
+		return gatewayErrors, err
 	}
 
-	// Handle the subscription cancellation by calling the HandleSubscriptionCancellation method from the repository.
-	err = member.repo.HandleSubscriptionCancellation(ctx, memberID, checkoutData)
+	request := entities.PaymentRequest{
+		Reference:   memberSubscriptionID,
+		Amount:      credit,
+		Currency:    captured.Currency,
+		Credentials: credentials,
+	}
+	refund := entities.SubscriptionPayment{
+		MemberSubscriptionID: memberSubscriptionID,
+		MemberID:             captured.MemberID,
+		PaymentGatewayID:     captured.PaymentGatewayID,
+		Kind:                 consts.PaymentKindRefund,
+		Amount:               credit,
+		Currency:             captured.Currency,
+	}
 
-	// Check if there was an error during the checkout process.
+	result, err := gateway.Refund(ctx, request, captured.GatewayPaymentID)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to cancel Subscription: %s", err.Error())
+		refund.Status = consts.PaymentStatusFailed
+		refund.FailureReason = err.Error()
+	} else {
+		refund.Status = result.Status
+		refund.GatewayPaymentID = result.ID
+		refund.FailureReason = result.FailureReason
+	}
+	recordErr := member.repo.RecordSubscriptionRefund(ctx, refund, captured.ID, entities.SubscriptionLedgerEntry{
+		MemberSubscriptionID: memberSubscriptionID,
+		MemberID:             captured.MemberID,
+		Kind:                 consts.LedgerKindRefund,
+		FromSubscriptionID:   state.SubscriptionID,
+		Credit:               credit,
+		Charge:               credit,
+		CurrencyID:           terms.CurrencyID,
+	})
+	if recordErr != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to record refund of subscription %s: %s", memberSubscriptionID, recordErr.Error())
+		return nil, recordErr
+	}
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Failed to refund subscription %s: %s", memberSubscriptionID, err.Error())
 		return nil, err
 	}
+	if refund.Status != consts.PaymentStatusRefunded {
+		fieldsMap := map[string][]string{}
+		utils.AppendValuesToMap(fieldsMap, consts.SubscriptionID, consts.RefundFailed)
+		logger.Log().WithContext(ctx).Errorf("Refund of subscription %s failed: %s", memberSubscriptionID, refund.FailureReason)
+		return fieldsMap, nil
+	}
 
-	return fieldsMap, nil
+	return nil, nil
 }
 
-// SubscriptionProductSwitch function
-func (member *MemberUseCases) SubscriptionProductSwitch(ctx context.Context, memberID uuid.UUID, data entities.SwitchSubscriptions) (map[string][]string, error) {
+// paymentStateChange returns how a payment webhook event of the given type changes a payment of the
+// given kind and its member subscription, and false for unknown event types.
+func paymentStateChange(eventType, kind string) (entities.PaymentStateChange, bool) {
+	switch eventType {
+	case consts.PaymentEventSucceeded:
+		if kind == consts.PaymentKindRenewal {
+			return entities.PaymentStateChange{
+				PaymentStatus:       consts.PaymentStatusCaptured,
+				FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+				SubscriptionStatus:  consts.SubscriptionStatusActive,
+				FromStatuses:        []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning, consts.SubscriptionStatusInGrace},
+				Renew:               true,
+			}, true
+		}
+		return entities.PaymentStateChange{
+			PaymentStatus:       consts.PaymentStatusCaptured,
+			FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+			SubscriptionStatus:  consts.SubscriptionStatusActive,
+			FromStatuses:        []string{consts.SubscriptionStatusProcessing, consts.SubscriptionStatusPaymentFailed},
+		}, true
+	case consts.PaymentEventFailed:
+		if kind == consts.PaymentKindRenewal {
+			// The lifecycle sweep moves unpaid renewals through grace and expiry, automatic renewals
+			// are retried on the dunning schedule meanwhile.
+			return entities.PaymentStateChange{
+				PaymentStatus:       consts.PaymentStatusFailed,
+				FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+				RetryRenewal:        true,
+			}, true
+		}
+		if kind == consts.PaymentKindPlanChange {
+			// The plan was changed along with the pending payment, the unpaid subscription is held back
+			return entities.PaymentStateChange{
+				PaymentStatus:       consts.PaymentStatusFailed,
+				FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+				SubscriptionStatus:  consts.SubscriptionStatusPaymentFailed,
+				FromStatuses:        []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning, consts.SubscriptionStatusInGrace},
+			}, true
+		}
+		return entities.PaymentStateChange{
+			PaymentStatus:       consts.PaymentStatusFailed,
+			FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+			SubscriptionStatus:  consts.SubscriptionStatusPaymentFailed,
+			FromStatuses:        []string{consts.SubscriptionStatusProcessing},
+		}, true
+	case consts.PaymentEventRefunded:
+		return entities.PaymentStateChange{
+			PaymentStatus:       consts.PaymentStatusRefunded,
+			FromPaymentStatuses: []string{consts.PaymentStatusCaptured, consts.PaymentStatusDisputed},
+			SubscriptionStatus:  consts.Cancelled,
+			FromStatuses: []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning, consts.SubscriptionStatusInGrace,
+				consts.SubscriptionStatusProcessing, consts.OnHold},
+		}, true
+	case consts.PaymentEventDisputed:
+		return entities.PaymentStateChange{
+			PaymentStatus:       consts.PaymentStatusDisputed,
+			FromPaymentStatuses: []string{consts.PaymentStatusCaptured},
+			SubscriptionStatus:  consts.OnHold,
+			FromStatuses: []string{consts.SubscriptionStatusActive, consts.SubscriptionStatusWarning, consts.SubscriptionStatusInGrace,
+				consts.SubscriptionStatusProcessing},
+		}, true
+	}
+	return entities.PaymentStateChange{}, false
+}
 
-	validationErrors := make(map[string][]string)
+// HandlePaymentWebhook handles an event sent by a payment gateway about a subscription payment.
+//
+// The signature is verified with the secret of the partner's credentials for the gateway of the
+// route before anything is looked up, then the event is matched to the recorded payment through the
+// gateway payment ID. Events with an invalid signature, for unknown payments, or for payments made
+// on another gateway or by members of another partner all return payment.ErrInvalidSignature, so
+// callers cannot probe which payments exist. Verified events are applied once, replays of an event
+// already received are acknowledged without changes.
+func (member *MemberUseCases) HandlePaymentWebhook(ctx *gin.Context, gatewayName string, partnerID string, payload []byte,
+	signature string) (map[string][]string, error) {
 
-	if utilities.IsEmpty(data.CurrentSubscriptionID) {
-		utils.AppendValuesToMap(validationErrors, consts.CurrentSubscriptionID, consts.Required)
+	fieldsMap := map[string][]string{}
+
+	if len(partnerID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.PartnerID, consts.Required)
+		return fieldsMap, nil
+	}
+
+	var event entities.PaymentWebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, invalid payload: %s", err.Error())
+		utils.AppendValuesToMap(fieldsMap, consts.EventID, consts.InvalidFormat)
+		return fieldsMap, nil
+	}
+	if len(event.ID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.EventID, consts.Required)
+	}
+	if len(event.PaymentID) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.GatewayPaymentID, consts.Required)
+	}
+	if len(event.Type) == 0 {
+		utils.AppendValuesToMap(fieldsMap, consts.EventType, consts.Required)
+	}
+	if len(fieldsMap) > 0 {
+		return fieldsMap, nil
 	}
 
-	if utilities.IsEmpty(data.NewSubscriptionID) {
-		utils.AppendValuesToMap(validationErrors, consts.NewSubscriptionID, consts.Required)
+	gateway, err := member.payments.Get(gatewayName)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed: %s", err.Error())
+		utils.AppendValuesToMap(fieldsMap, consts.PaymentGatewayID, consts.NotSupported)
+		return fieldsMap, nil
 	}
 
-	if utilities.IsEmpty(data.ProductReferenceID) {
-		utils.AppendValuesToMap(validationErrors, consts.ProductReferenceID, consts.Required)
+	paymentGatewayID, err := member.verifyWebhookSignature(ctx, gateway, partnerID, payload, signature)
+	if err != nil {
+		if !errors.Is(err, payment.ErrInvalidSignature) {
+			logger.Log().WithContext(ctx).Errorf("Payment webhook failed, loading gateway credentials: %s", err.Error())
+			return nil, err
+		}
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, event %s: %s", event.ID, err.Error())
+		return nil, payment.ErrInvalidSignature
 	}
 
-	_, err := member.repo.SubscriptionProductSwitch(ctx, memberID, data, &validationErrors)
-
-	if len(validationErrors) != 0 {
-		return validationErrors, nil
+	subscriptionPayment, err := member.repo.GetSubscriptionPaymentByGatewayPaymentID(ctx, event.PaymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, unknown payment %s", event.PaymentID)
+		return nil, payment.ErrInvalidSignature
 	}
-
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Switch products between subscriptions failed err=%s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, loading payment %s: %s", event.PaymentID, err.Error())
 		return nil, err
 	}
-
-	return nil, nil
-}
-
-// ViewAllSubscriptions function
-func (member *MemberUseCases) ViewAllSubscriptions(ctx *gin.Context, memberID uuid.UUID, reqParam entities.ReqParams) ([]entities.ListAllSubscriptions, models.MetaData, map[string][]string, error) {
-
-	validationErrors := make(map[string][]string)
-
-	if status, exists := ctx.GetQuery("status"); exists && status != "" {
-		reqParam.Status = status
+	if subscriptionPayment.PaymentGatewayID != paymentGatewayID {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, payment %s was not made on %s", event.PaymentID, gateway.Name())
+		return nil, payment.ErrInvalidSignature
 	}
 
-	if search, exists := ctx.GetQuery("search"); exists && search != "" {
-		reqParam.Search = search
+	isPartnerMember, err := member.repo.CheckMemberPartner(ctx, subscriptionPayment.MemberID, partnerID)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, checking member partner: %s", err.Error())
+		return nil, err
 	}
-
-	// Fetching sortBy from query parameter
-	if sortBy, exists := ctx.GetQuery("sort"); exists && sortBy != "" {
-		reqParam.Sort = sortBy
+	if !isPartnerMember {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, payment %s does not belong to partner %s", event.PaymentID, partnerID)
+		return nil, payment.ErrInvalidSignature
 	}
 
-	// Call GetSubscriptionRecordCount function in repo
-	recordCount, err := member.repo.GetSubscriptionRecordCount(ctx, memberID)
+	change, ok := paymentStateChange(event.Type, subscriptionPayment.Kind)
+	if !ok {
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, unknown event type %s", event.Type)
+		utils.AppendValuesToMap(fieldsMap, consts.EventType, consts.Unknown)
+		return fieldsMap, nil
+	}
 
+	applied, err := member.repo.ApplyPaymentWebhookEvent(ctx, gateway.Name(), partnerID, event, payload, subscriptionPayment, change)
+	if errors.Is(err, consts.ErrPaymentEventOutOfOrder) {
+		// Acknowledged so the gateway stops sending it
+		logger.Log().WithContext(ctx).Errorf("Payment webhook event %s of %s ignored, payment %s is past %s", event.ID, gateway.Name(),
+			subscriptionPayment.ID, change.PaymentStatus)
+		return nil, nil
+	}
 	if err != nil {
-		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+		logger.Log().WithContext(ctx).Errorf("Payment webhook failed, applying event %s: %s", event.ID, err.Error())
+		return nil, err
 	}
-
-	if reqParam.Limit > consts.MaximumLimit {
-		err := fmt.Errorf("Cannot exceed maximum page limit")
-		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+	if !applied {
+		logger.Log().WithContext(ctx).Infof("Payment webhook event %s of %s was already processed", event.ID, gateway.Name())
+		return nil, nil
 	}
 
-	if int64(reqParam.Page)*int64(reqParam.Limit)-recordCount >= int64(reqParam.Limit) { //check whether page parameters are valid
-		validationErrors := make(map[string][]string)
-		utils.AppendValuesToMap(validationErrors, consts.Page, consts.Invalid)
-		//return []entities.ListAllSubscriptions{}, models.MetaData{}, validationErrors, nil
-	}
+	logger.Log().WithContext(ctx).Infof("Payment webhook event %s of %s applied to payment %s", event.ID, gateway.Name(), subscriptionPayment.ID)
+	return nil, nil
+}
 
-	//call Paginate function
-	reqParam.Page, reqParam.Limit = utils.Paginate(reqParam.Page, reqParam.Limit, consts.LimitDefault)
+// verifyWebhookSignature checks the signature of a webhook payload with the secret of each of the
+// partner's credentials for the gateway, and returns the payment gateway ID of the credentials that
+// signed it. It returns payment.ErrInvalidSignature when the partner has no credentials for the
+// gateway or none of them signed the payload.
+func (member *MemberUseCases) verifyWebhookSignature(ctx *gin.Context, gateway payment.Gateway, partnerID string,
+	payload []byte, signature string) (int, error) {
 
-	//call ViewAllSubscriptions function in repo
-	memberSubscriptions, err := member.repo.ViewAllSubscriptions(ctx, memberID, reqParam, &validationErrors)
+	paymentDetails, err := member.repo.GetPaymentDetailsByPartner(ctx, partnerID)
+	if err != nil {
+		return 0, err
+	}
+	paymentGatewayIDs := make([]int, 0, len(paymentDetails))
+	for paymentGatewayID := range paymentDetails {
+		paymentGatewayIDs = append(paymentGatewayIDs, paymentGatewayID)
+	}
+	slices.Sort(paymentGatewayIDs)
 
-	if len(validationErrors) != 0 {
-		return []entities.ListAllSubscriptions{}, models.MetaData{}, validationErrors, nil
+	for _, paymentGatewayID := range paymentGatewayIDs {
+		detailsString, err := member.repo.DecryptPaymentData(ctx, paymentDetails[paymentGatewayID])
+		if err != nil {
+			return 0, err
+		}
+		var credentials entities.PaymentGatewayDetails
+		if err := json.Unmarshal([]byte(detailsString), &credentials); err != nil {
+			return 0, err
+		}
+		if !strings.EqualFold(credentials.Gateway, gateway.Name()) {
+			continue
+		}
+		if gateway.VerifyWebhook(payload, signature, credentials.ClientSecret) == nil {
+			return paymentGatewayID, nil
+		}
 	}
+	return 0, payment.ErrInvalidSignature
+}
 
+// subscriptionLifecycleEvents maps the status a subscription moved to onto its notification event.
+var subscriptionLifecycleEvents = map[string]string{
+	consts.SubscriptionStatusWarning: consts.EventSubscriptionWarning,
+	consts.SubscriptionStatusInGrace: consts.EventSubscriptionInGrace,
+	consts.SubscriptionStatusExpired: consts.EventSubscriptionExpired,
+}
+
+// ProcessSubscriptionLifecycle is run periodically by the scheduler. It moves the member subscriptions
+// whose warning, grace or expiry date has been reached to the matching status and notifies each member
+// of the transition. Running it again does not repeat transitions.
+func (member *MemberUseCases) ProcessSubscriptionLifecycle(ctx context.Context) error {
+	transitions, err := member.repo.TransitionSubscriptionStatuses(ctx, time.Now())
 	if err != nil {
-		return []entities.ListAllSubscriptions{}, models.MetaData{}, nil, err
+		logger.Log().WithContext(ctx).Errorf("Subscription lifecycle failed: %s", err.Error())
+		return err
 	}
 
-	metadata := &models.MetaData{
-		CurrentPage: reqParam.Page,
-		PerPage:     reqParam.Limit,
-		Total:       recordCount,
-	}
+	for _, transition := range transitions {
+		logger.Log().WithContext(ctx).Infof("Subscription %s of member %s moved from %s to %s",
+			transition.MemberSubscriptionID, transition.MemberID, transition.FromStatus, transition.ToStatus)
 
-	metadata = utils.MetaDataInfo(metadata)
+		event, ok := subscriptionLifecycleEvents[transition.ToStatus]
+		if !ok {
+			continue
+		}
+		subscription := transition.CustomName
+		if subscription == "" {
+			subscription = transition.MemberSubscriptionID.String()
+		}
+		member.notifyMember(ctx, transition.MemberID, event, map[string]interface{}{
+			"Subscription":   subscription,
+			"ExpirationDate": transition.ExpirationDate.Format("2006-01-02"),
+			"GraceEnd":       transition.GraceEnd.Format("2006-01-02"),
+		})
+	}
 
-	return memberSubscriptions, *metadata, nil, nil
+	return nil
 }
 
-// GetSubscriptionRecordCount function
-func (member *MemberUseCases) GetSubscriptionRecordCount(ctx context.Context, memberID uuid.UUID) (int64, error) {
+// ExportMember returns the profile, billing addresses, subscriptions, stores and login sessions of a
+// member. Every export is recorded in the activity log with the member who asked for it.
+func (member *MemberUseCases) ExportMember(ctx *gin.Context, memberID uuid.UUID) (entities.MemberExport, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
 
-	//GetSubscriptionRecordCount function call to repo
-	totalCount, err := member.repo.GetSubscriptionRecordCount(ctx, memberID)
+	exists, err := member.repo.IsMemberExist(ctx, memberID)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("GetSubscriptionRecordCount failed, err=%s", err.Error())
-		return 0, err
+		logger.Log().WithContext(ctx).Errorf("ExportMember failed, err=%s", err.Error())
+		return entities.MemberExport{}, nil, err
+	}
+	if !exists {
+		utils.AppendValuesToMap(validationErrors, consts.MemberrID, consts.NotFound)
+		return entities.MemberExport{}, validationErrors, nil
 	}
-	return totalCount, nil
-}
-
-// IsMemberExists checks if a member with the given memberId exists.
-// Parameters:
-//
-//	@ memberId: The UUID of the member to check for existence.
-//	@ ctx: The context for the database operation.
-//
-// Returns:
-//
-//	@ bool: True if the member exists, false otherwise.
-//	@ error: An error, if any, during the check.
-func (member *MemberUseCases) IsMemberExist(ctx context.Context, memberID uuid.UUID) (bool, error) {
 
-	_, err := member.repo.IsMemberExist(ctx, memberID)
+	export, err := member.repo.GetMemberExport(ctx, memberID)
 	if err != nil {
-		return false, err
+		logger.Log().WithContext(ctx).Errorf("ExportMember failed, err=%s", err.Error())
+		return entities.MemberExport{}, nil, err
 	}
+	export.ExportedOn = time.Now().UTC()
 
-	return true, nil
+	member.recordActivity(ctx, memberID, consts.ActivityMemberDataExported, map[string]interface{}{
+		"requested_by": ctx.GetString(consts.ContextMemberID),
+		"exported_on":  export.ExportedOn,
+	})
+	return export, nil, nil
 }
 
-// DeleteBillingAddress to delete a members billing address
-func (member *MemberUseCases) DeleteBillingAddress(ctx *gin.Context, memberID uuid.UUID, memberBillingID uuid.UUID) (map[string][]string, error) {
-	fieldsMap := map[string][]string{}
+// RequestMemberErasure queues the erasure of a member's personal data, which is carried out by the
+// erasure job. Asking again while an erasure is pending has no effect.
+func (member *MemberUseCases) RequestMemberErasure(ctx *gin.Context, memberID uuid.UUID) (map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
 	exists, err := member.repo.IsMemberExist(ctx, memberID)
 	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("RequestMemberErasure failed, err=%s", err.Error())
 		return nil, err
 	}
 	if !exists {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to delete this billing address: Invalid member")
-		return fieldsMap, nil
+		utils.AppendValuesToMap(validationErrors, consts.MemberrID, consts.NotFound)
+		return validationErrors, nil
 	}
-	IsValid, err := member.repo.CheckBillingAddressRelation(ctx, memberID, memberBillingID)
+
+	requestedBy := ctx.GetString(consts.ContextMemberID)
+	requested, err := member.repo.RequestMemberErasure(ctx, memberID, requestedBy)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to delete billing address %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("RequestMemberErasure failed, err=%s", err.Error())
 		return nil, err
 	}
-	if !IsValid {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberBilling, consts.InvalidAddress)
-		logger.Log().WithContext(ctx).Errorf("Failed to delete billing address")
-		return fieldsMap, nil
+	if requested {
+		member.recordActivity(ctx, memberID, consts.ActivityMemberErasureRequested, map[string]interface{}{
+			"requested_by": requestedBy,
+		})
 	}
+	return nil, nil
+}
 
-	// Return the 'fieldsMap' with the processed data and a nil error, indicating success.
-	if len(fieldsMap) > 0 {
-		return fieldsMap, nil
+// ProcessMemberErasures anonymizes up to limit members whose erasure was requested and records each
+// erasure in the activity log. A failed erasure stays pending and is retried on the next run.
+func (member *MemberUseCases) ProcessMemberErasures(ctx context.Context, limit int) error {
+	requests, err := member.repo.GetPendingMemberErasures(ctx, limit)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Member erasure failed: %s", err.Error())
+		return err
 	}
-	err = member.repo.DeleteBillingAddress(ctx, memberID, memberBillingID)
-	return nil, err
+
+	var failed error
+	for _, request := range requests {
+		erased, err := member.repo.EraseMember(ctx, request)
+		if err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to erase member %s: %s", request.MemberID, err.Error())
+			failed = err
+			continue
+		}
+		if !erased {
+			continue
+		}
+		member.recordActivity(ctx, request.MemberID, consts.ActivityMemberErased, map[string]interface{}{
+			"requested_by": request.RequestedBy,
+			"requested_on": request.RequestedOn,
+		})
+	}
+	return failed
 }
 
-// DeleteMember deletes a member by their ID. It first validates the member's existence,
-// checks if the member has already been deleted, and then performs the deletion operation.
-func (member *MemberUseCases) DeleteMember(ctx *gin.Context, memberID string) (map[string][]string, error) {
-	// Initialize a map to store validation error messages and proceed with the deletion process.
-	fieldsMap := map[string][]string{}
-	// Parse the memberID string to a UUID.
-	MemberID, err := uuid.Parse(memberID)
-	// Check if there was an error parsing the memberID.
-	if err != nil {
-		return nil, err
+// ImportMembers queues a bulk import of the members uploaded in the given format for the
+// partner of the request. The rows are validated and registered by the import job, and their
+// outcome is reported by GetMemberImport. Members without a provider use the internal one.
+func (member *MemberUseCases) ImportMembers(ctx *gin.Context, format string, upload io.Reader) (entities.MemberImport, map[string][]string, error) {
+	validationErrors := make(map[string][]string)
+
+	partnerID := ctx.GetString(consts.ContextPartnerID)
+	if partnerID == "" {
+		utils.AppendValuesToMap(validationErrors, consts.Partner, consts.Required)
+		return entities.MemberImport{}, validationErrors, nil
 	}
-	// Check if the member with the given ID exists.
-	exists, err := member.repo.IsMemberExist(ctx, MemberID)
+
+	members, err := bulk.ReadMembers(upload, format, consts.MaxImportRows)
 	if err != nil {
-		return nil, err
-	}
-	// If the member does not exist, add an error message to the map.
-	if !exists {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Invalid)
-		logger.Log().WithContext(ctx).Errorf("Failed to delete this member: Invalid member")
-		return fieldsMap, nil
+		logger.Log().WithContext(ctx).Errorf("ImportMembers failed, unreadable upload: %s", err.Error())
+		switch {
+		case errors.Is(err, bulk.ErrUnsupportedFormat):
+			utils.AppendValuesToMap(validationErrors, consts.File, consts.InvalidFormat)
+		case errors.Is(err, bulk.ErrNoRows):
+			utils.AppendValuesToMap(validationErrors, consts.File, consts.Empty)
+		case errors.Is(err, bulk.ErrTooManyRows):
+			utils.AppendValuesToMap(validationErrors, consts.File, consts.LimitExceeds)
+		default:
+			utils.AppendValuesToMap(validationErrors, consts.File, consts.Invalid)
+		}
+		return entities.MemberImport{}, validationErrors, nil
 	}
-	// Check if the member has already been deleted.
-	deleted, err := member.repo.IsDeleted(ctx, MemberID)
-	if deleted {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Deleted)
-		logger.Log().WithContext(ctx).Errorf("Member already deleted")
-		return fieldsMap, nil
+	// Passwords are checked now, only their hashes are kept until the rows are imported
+	importRows := make([]entities.MemberImportRow, len(members))
+	for i := range members {
+		if members[i].Provider == "" {
+			members[i].Provider = consts.ProviderInternal
+		}
+		importRows[i] = entities.MemberImportRow{RowNumber: i + 1, Member: members[i], PasswordErrors: passwordErrors(members[i])}
 	}
-	// Delete the member.
-	err = member.repo.DeleteMember(ctx, MemberID)
+
+	memberImport, err := member.repo.CreateMemberImport(ctx, entities.MemberImport{
+		PartnerID:   partnerID,
+		Format:      format,
+		RequestedBy: ctx.GetString(consts.ContextMemberID),
+	}, importRows)
 	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to delete member %s", err.Error())
+		logger.Log().WithContext(ctx).Errorf("ImportMembers failed, err=%s", err.Error())
+		return entities.MemberImport{}, nil, err
 	}
-	// Return nil for success (no validation errors).
-	return nil, nil
+	memberImport.Errors = []entities.MemberImportRowError{}
+	return memberImport, nil, nil
 }
 
-// AddMemberStores adds stores to a member, based on their partner
-func (member *MemberUseCases) AddMemberStores(ctx *gin.Context, memberID uuid.UUID, stores []string) (map[string][]string, error) {
-	fieldsMap := map[string][]string{}
-	exists, err := member.repo.IsMemberExist(ctx, memberID)
-	if err != nil {
-		logger.Log().WithContext(ctx).Errorf("Failed to add stores: %s", err.Error())
-		return nil, err
+// GetMemberImport returns the progress of a bulk import and the validation errors of its failed rows.
+func (member *MemberUseCases) GetMemberImport(ctx *gin.Context, importID uuid.UUID) (entities.MemberImport, map[string][]string, error) {
+	memberImport, err := member.repo.GetMemberImport(ctx, importID)
+	if errors.Is(err, sql.ErrNoRows) {
+		validationErrors := make(map[string][]string)
+		utils.AppendValuesToMap(validationErrors, consts.ImportID, consts.NotFound)
+		return entities.MemberImport{}, validationErrors, nil
 	}
-	if !exists {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.Exists)
-		logger.Log().WithContext(ctx).Errorf("Failed to add member store: Member not found")
-		return fieldsMap, nil
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("GetMemberImport failed, err=%s", err.Error())
+		return entities.MemberImport{}, nil, err
 	}
-	memberStatus, err := member.repo.IsActive(ctx, memberID)
-	if !memberStatus {
-		utils.AppendValuesToMap(fieldsMap, consts.MemberID, consts.NotActive)
-		logger.Log().WithContext(ctx).Errorf("Failed to add member store: Member not Active")
-		return fieldsMap, nil
+	return memberImport, nil, nil
+}
+
+// ProcessMemberImports processes up to limit open bulk imports. Every pending row is validated
+// with the registration rules and registered when valid, and its outcome is recorded. An import
+// interrupted by an error keeps its remaining rows pending and is claimed again after the lease.
+func (member *MemberUseCases) ProcessMemberImports(ctx context.Context, limit int, lease time.Duration) error {
+	imports, err := member.repo.ClaimMemberImports(ctx, limit, lease)
+	if err != nil {
+		logger.Log().WithContext(ctx).Errorf("Member import failed: %s", err.Error())
+		return err
 	}
 
-	// Check if payload(store names) is present
-	if ctx.Request.ContentLength == 0 {
-		partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
+	var failed error
+	for _, memberImport := range imports {
+		if err := member.processMemberImport(ctx, memberImport); err != nil {
+			logger.Log().WithContext(ctx).Errorf("Failed to process member import %s: %s", memberImport.ID, err.Error())
+			failed = err
+		}
+	}
+	return failed
+}
 
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add stores: %s", err.Error())
-			return nil, err
+// processMemberImport imports the pending rows of a bulk import and completes it.
+func (member *MemberUseCases) processMemberImport(ctx context.Context, memberImport entities.MemberImport) error {
+	rows, err := member.repo.GetPendingMemberImportRows(ctx, memberImport.ID)
+	if err != nil {
+		return err
+	}
 
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
-		storeID, err := member.repo.GetStoreIDsByPartnerID(ctx, partnerID)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting store ids!: %s", err.Error())
-			return nil, err
-		}
-		newIdList, err := member.repo.CheckNonExistingMemberStores(ctx, memberID, storeID)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting non redunatant store ids!: %s", err.Error())
-			return nil, err
-		}
-		if len(newIdList) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.StoresExists)
-			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Stores already exists for this member")
-			return fieldsMap, nil
-		}
-		err = member.repo.AddMemberStoresById(ctx, memberID, newIdList)
+		fieldsMap, err := member.validateMemberDetails(ctx, row.Member, memberImport.PartnerID)
 		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add stores!!: %s", err.Error())
-			return nil, err
-		}
-	} else {
-		if len(stores) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Empty)
-			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Empty store name list")
-			return fieldsMap, nil
+			return err
 		}
-		storeExist, relatedStoreIDs, err := member.repo.CheckStoreNameExistsAndReturnIDs(ctx, stores)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add member stores : %s", err.Error())
-			return nil, err
+		for key, values := range row.PasswordErrors {
+			fieldsMap[key] = append(fieldsMap[key], values...)
 		}
-		if !storeExist {
-			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.Invalid)
-			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Invalid store name")
-			return fieldsMap, nil
+		if len(fieldsMap) == 0 {
+			// The member is registered and the row completed together
+			memberID, err := member.repo.ImportMember(ctx, memberImport.ID, row.RowNumber, row.Member, memberImport.PartnerID)
+			if err == nil {
+				member.welcomeMember(ctx, memberID, row.Member)
+				continue
+			}
+			// Rejected rows fail, other errors leave the row pending to be retried
+			if !errors.Is(err, consts.ErrMemberRejected) {
+				logger.Log().WithContext(ctx).Errorf("Failed to import row %d of member import %s: %s", row.RowNumber, memberImport.ID, err.Error())
+				return err
+			}
+			utils.AppendValuesToMap(fieldsMap, consts.Row, consts.Rejected)
 		}
-		partnerID, err := member.repo.GetPartnerIDByMemberID(ctx, memberID)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add member stores: %s", err.Error())
-			return nil, err
+		if err := member.repo.CompleteMemberImportRow(ctx, memberImport.ID, row.RowNumber, fieldsMap); err != nil {
+			return err
 		}
+	}
+
+	return member.repo.CompleteMemberImport(ctx, memberImport.ID)
+}
+
+// ExportMembers writes the members matching the listing parameters to w as CSV. The members
+// are read in keyset order in batches of consts.ExportBatchSize and each batch is written as
+// soon as it is read. Nothing is written when the parameters are invalid.
+func (member *MemberUseCases) ExportMembers(ctx *gin.Context, params entities.MemberListParams, w io.Writer) (map[string][]string, error) {
+	// The export is not paged, the batch size replaces the page size.
+	params.Limit = 0
+	filter, validationErrors := memberListFilter(params)
+	if len(validationErrors) != 0 {
+		return validationErrors, nil
+	}
+	filter.Limit = consts.ExportBatchSize
 
-		related, err := member.repo.StorePartnerRelation(ctx, partnerID, relatedStoreIDs)
+	writer := bulk.NewMemberWriter(w)
+	for {
+		members, next, err := member.repo.ListMembers(ctx, filter)
 		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to  add member stores: %s", err.Error())
+			logger.Log().WithContext(ctx).Errorf("ExportMembers failed, err=%s", err.Error())
 			return nil, err
 		}
-		hasFalse := false
-		for _, value := range related {
-			if value == false {
-				hasFalse = true
-				break
-			}
-		}
-		if hasFalse {
-			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.NoRelation)
-			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Store not related to partner")
-			return fieldsMap, nil
-		}
-		newIdList, err := member.repo.CheckNonExistingMemberStores(ctx, memberID, relatedStoreIDs)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add stores, failed in getting non redunatant store ids!: %s", err.Error())
+		if err := writer.Write(members); err != nil {
+			logger.Log().WithContext(ctx).Errorf("ExportMembers failed, writing the export failed: %s", err.Error())
 			return nil, err
 		}
-		if len(newIdList) == 0 {
-			utils.AppendValuesToMap(fieldsMap, consts.Name, consts.StoresExists)
-			logger.Log().WithContext(ctx).Errorf("Failed to add member store: Stores already exists for this member")
-			return fieldsMap, nil
-		}
-		//AddMemberStoresById addes stores under given member
-		err = member.repo.AddMemberStoresById(ctx, memberID, newIdList)
-		if err != nil {
-			logger.Log().WithContext(ctx).Errorf("Failed to add member stores: %s", err.Error())
-			return nil, err
+		if next == nil {
+			return nil, nil
 		}
+		filter.After = next
 	}
-
-	return nil, nil
 }
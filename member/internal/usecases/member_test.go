@@ -2,18 +2,31 @@ package usecases_test
 
 import (
 	"context"
+	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"member/internal/address"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"member/internal/activity"
 	"member/internal/consts"
+	"member/internal/cursor"
 	"member/internal/entities"
+	"member/internal/etag"
+	"member/internal/notifier"
+	"member/internal/payment"
 	"member/internal/repo/mock"
 
+	"member/internal/tenant"
+	"member/internal/totp"
 	"member/internal/usecases"
+	"member/internal/verification"
+	"member/utilities"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -22,7 +35,6 @@ import (
 	"github.com/stretchr/testify/require"
 	"gitlab.com/tuneverse/toolkit/core/logger"
 	"gitlab.com/tuneverse/toolkit/models"
-	cryptoHash "gitlab.com/tuneverse/toolkit/utils/crypto"
 )
 
 func init() {
@@ -36,26 +48,78 @@ func init() {
 	logger.InitLogger(clientOpt)
 }
 
-// Add Update Billing Address test cases *****************************************
-func TestAddBillingAddress(t *testing.T) {
-	// Create a new gomock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+// useCaseDeps are the collaborators of the use cases under test that a test inspects.
+type useCaseDeps struct {
+	notifier   notifier.Notifier
+	activities activity.Recorder
+	verifier   *verification.Signer
+	payments   *payment.Registry
+}
+
+// useCaseOption replaces a collaborator of the use cases under test.
+type useCaseOption func(*useCaseDeps)
+
+// withNotifier sends the notifications of the use cases to n.
+func withNotifier(n notifier.Notifier) useCaseOption {
+	return func(deps *useCaseDeps) { deps.notifier = n }
+}
+
+// withActivities records the activities of the use cases with recorder.
+func withActivities(recorder activity.Recorder) useCaseOption {
+	return func(deps *useCaseDeps) { deps.activities = recorder }
+}
 
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
+// withVerifier signs the email verification tokens of the use cases with signer.
+func withVerifier(signer *verification.Signer) useCaseOption {
+	return func(deps *useCaseDeps) { deps.verifier = signer }
+}
+
+// withPayments charges the payments of the use cases through registry.
+func withPayments(registry *payment.Registry) useCaseOption {
+	return func(deps *useCaseDeps) { deps.payments = registry }
+}
+
+// newTestUseCases builds the use cases on a mocked repository, the fake payment gateway and
+// in-memory notifications and activities. The expectations of the repository are checked when
+// the test ends.
+func newTestUseCases(t *testing.T, opts ...useCaseOption) (usecases.MemberUseCaseImply, *mock.MockMemberRepoImply) {
+	t.Helper()
+	deps := useCaseDeps{
+		notifier:   notifier.NewMemoryNotifier(),
+		activities: activity.NewMemoryRecorder(),
+		verifier:   verification.NewSigner("secret", time.Hour),
+		payments:   payment.NewRegistry(payment.NewFakeGateway()),
+	}
+	for _, opt := range opts {
+		opt(&deps)
+	}
+
+	mockRepo := mock.NewMockMemberRepoImply(gomock.NewController(t))
+	useCases := usecases.NewMemberUseCases(mockRepo, deps.notifier, deps.payments, deps.activities,
+		deps.verifier, totp.NewAuthenticator("Tuneverse", 1), verification.NewSigner("challenge", time.Minute), address.Default())
+	return useCases, mockRepo
+}
 
-	// Create a new MemberUseCases instance with the mock repository
-	useCases := usecases.NewMemberUseCases(mockRepo)
+// Add Update Billing Address test cases *****************************************
+func TestAddBillingAddress(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
 
 	// Define test data
 	memberID := uuid.New()
 	billingAddress := entities.BillingAddress{
 		Address: "123 Main St",
 		Zipcode: "12345",
+		Country: "IT",
 	}
 	ginCtx := createTestGinContext()
+	mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil).Times(3)
+	mockRepo.EXPECT().GetBillingAddressCountForMember(ginCtx, memberID).Return(1, nil).Times(3)
+	mockRepo.EXPECT().CountryExists("IT").Return(true, nil).Times(2)
+
 	// Test case 1: Valid billing address
+	mockRepo.EXPECT().BillingAddressExists(ginCtx, memberID, billingAddress, uuid.Nil).Return(false, nil)
+	mockRepo.EXPECT().HasPrimaryBilling(ginCtx, memberID).Return(true, nil)
+	mockRepo.EXPECT().CountPrimaryBillingAddresses(ginCtx, memberID).Return(1, nil)
 	mockRepo.EXPECT().AddBillingAddress(ginCtx, memberID, billingAddress).Return(nil)
 	fieldsMap, err := useCases.AddBillingAddress(ginCtx, memberID, billingAddress)
 	if err != nil {
@@ -68,9 +132,8 @@ func TestAddBillingAddress(t *testing.T) {
 	// Test case 2: Missing required fields in billing address
 	invalidAddress := entities.BillingAddress{
 		Address: "",
-	}
-	if invalidAddress.Address == "" {
-		return
+		Zipcode: "12345",
+		Country: "IT",
 	}
 	fieldsMap, err = useCases.AddBillingAddress(ginCtx, memberID, invalidAddress)
 	if err != nil {
@@ -84,6 +147,7 @@ func TestAddBillingAddress(t *testing.T) {
 	invalidZipcode := entities.BillingAddress{
 		Address: "123 Main St",
 		Zipcode: "invalid",
+		Country: "IT",
 	}
 	fieldsMap, err = useCases.AddBillingAddress(ginCtx, memberID, invalidZipcode)
 	if err != nil {
@@ -96,44 +160,48 @@ func TestAddBillingAddress(t *testing.T) {
 
 // TestUpdateBillingAddress is the test case for updating an existing Billing Address
 func TestUpdateBillingAddress(t *testing.T) {
-	// Create a new gomock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a new MemberUseCases instance with the mock repository
-	useCases := usecases.NewMemberUseCases(mockRepo)
+	useCases, mockRepo := newTestUseCases(t)
 
 	// Define test data
 	memberID := uuid.New()
 	memberBillingID := uuid.New()
+	currentAddress := entities.BillingAddress{
+		Address: "1 Main St",
+		Zipcode: "00100",
+		Country: "IT",
+		Version: 1,
+	}
 	billingAddress := entities.BillingAddress{
 		Address: "123 Main St",
 		Zipcode: "12345",
 	}
+	updatedAddress := currentAddress
+	updatedAddress.Address = billingAddress.Address
+	updatedAddress.Zipcode = billingAddress.Zipcode
 
-	// Test case 1: Valid billing address
-	// Convert context.Background() to *gin.Context for testing or specific use cases.
 	ginCtx := createTestGinContext()
-	mockRepo.EXPECT().UpdateBillingAddress(ginCtx, memberID, memberBillingID, billingAddress).Return(nil)
-	fieldsMap, err := useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, billingAddress)
+	mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil).Times(3)
+	mockRepo.EXPECT().CheckBillingAddressRelation(ginCtx, memberID, memberBillingID).Return(true, nil).Times(3)
+	mockRepo.EXPECT().GetBillingAddressByID(ginCtx, memberBillingID).Return(&currentAddress, nil).Times(2)
+	mockRepo.EXPECT().CountTotalAddressesForMember(ginCtx, memberID).Return(2, nil).Times(2)
+	mockRepo.EXPECT().HasPrimaryBilling(ginCtx, memberID).Return(true, nil).Times(2)
+
+	// Test case 1: Valid billing address
+	mockRepo.EXPECT().BillingAddressExists(ginCtx, memberID, updatedAddress, memberBillingID).Return(false, nil)
+	mockRepo.EXPECT().UpdateBillingAddress(ginCtx, memberID, memberBillingID, updatedAddress, currentAddress.Version).Return(int64(2), nil)
+	fieldsMap, err := useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, billingAddress, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 	if len(fieldsMap) != 0 {
 		t.Errorf("Expected empty fieldsMap, got %v", fieldsMap)
 	}
-	// Test case 2: Missing required fields in billing address
+
+	// Test case 2: Address longer than allowed
 	invalidAddress := entities.BillingAddress{
-		Address: "",
-		Zipcode: "",
-	}
-	if invalidAddress.Address == "" || invalidAddress.Zipcode == "" {
-		return
+		Address: strings.Repeat("a", 201),
 	}
-	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, invalidAddress)
+	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, invalidAddress, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -146,7 +214,7 @@ func TestUpdateBillingAddress(t *testing.T) {
 		Address: "123 Main St",
 		Zipcode: "invalid",
 	}
-	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, invalidZipcode)
+	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, invalidZipcode, 0)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -158,15 +226,7 @@ func TestUpdateBillingAddress(t *testing.T) {
 
 // TestIsMemberExists to check if member exists or not
 func TestIsMemberExists(t *testing.T) {
-	// Create a new gomock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a new MemberUseCases instance with the mock repository
-	useCases := usecases.NewMemberUseCases(mockRepo)
+	useCases, mockRepo := newTestUseCases(t)
 
 	// Define a member ID for testing
 	memberID := uuid.New()
@@ -198,36 +258,28 @@ func TestIsMemberExists(t *testing.T) {
 
 func TestChangePassword(t *testing.T) {
 
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a MemberUseCases instance with the mock repository
-	useCase := usecases.NewMemberUseCases(mockRepo)
+	useCase, mockRepo := newTestUseCases(t)
 	ginCtx := createTestGinContext()
 	// Define test parameters
 	memberID := uuid.New()
-	key := "qw34rty677"
+	key := strings.Repeat("4f", consts.ResetKeyBytes)
 	currentPassword := "OldPassword@123"
 	newPassword := "NewPassword@123"
 
-	t.Run("Valid Password Change", func(t *testing.T) {
-		// Setup mock expectations for valid password change
-		var currentPassword string
+	// expectValidKey sets the expectations of a member presenting its valid reset key
+	expectValidKey := func() {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(true, nil)
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+	}
 
-		// Call the Hash function and handle both the result and the error
-		hashedPassword, hashError := cryptoHash.Hash(currentPassword)
-		if hashError != nil {
-			fmt.Fprintf(os.Stderr, " error message: %v\n", hashError)
-		}
-		// Setup mock expectations for valid password change with the hashed password
-		mockRepo.EXPECT().GetPasswordHash(gomock.Any(), memberID).Return(hashedPassword, nil)
+	t.Run("Valid Password Change", func(t *testing.T) {
+		// Setup mock expectations for valid password change with a matching current password
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(true, nil)
 
-		mockRepo.EXPECT().UpdatePassword(gomock.Any(), memberID, key, gomock.Any()).Return(nil)
-		// Convert context.Background() to *gin.Context for testing or specific use cases.
-		ginCtx := createTestGinContext()
+		mockRepo.EXPECT().UpdatePassword(gomock.Any(), memberID, key, newPassword).Return(nil)
 		// Execute the function
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, newPassword, currentPassword)
 
@@ -237,8 +289,11 @@ func TestChangePassword(t *testing.T) {
 	})
 
 	t.Run("Empty New Password", func(t *testing.T) {
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(true, nil)
+
 		// Execute the function with an empty new password
-		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, "", key, currentPassword)
+		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, "", currentPassword)
 
 		// Assertions
 		assert.NotEmpty(t, fieldsMap["new_password"])
@@ -247,7 +302,8 @@ func TestChangePassword(t *testing.T) {
 
 	t.Run("Invalid New Password", func(t *testing.T) {
 		// Setup mock expectations for invalid new password
-		mockRepo.EXPECT().GetPasswordHash(gomock.Any(), memberID).Return("", nil)
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(true, nil)
 
 		// Execute the function
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, "WeakPassword", currentPassword)
@@ -258,6 +314,9 @@ func TestChangePassword(t *testing.T) {
 	})
 
 	t.Run("New Password Same as Current Password", func(t *testing.T) {
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(true, nil)
+
 		// Execute the function with the same new password as the current password
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, currentPassword, currentPassword)
 
@@ -268,7 +327,8 @@ func TestChangePassword(t *testing.T) {
 
 	t.Run("Invalid Current Password", func(t *testing.T) {
 		// Setup mock expectations for an incorrect current password
-		mockRepo.EXPECT().GetPasswordHash(gomock.Any(), memberID).Return("hashed_current_password", nil)
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, "IncorrectPassword").Return(false, nil)
 
 		// Execute the function with an incorrect current password
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, newPassword, "IncorrectPassword")
@@ -278,9 +338,10 @@ func TestChangePassword(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
-	t.Run("Error Retrieving Password Hash", func(t *testing.T) {
-		// Setup mock expectations for an error when retrieving the password hash
-		mockRepo.EXPECT().GetPasswordHash(gomock.Any(), memberID).Return("", errors.New("error retrieving password hash"))
+	t.Run("Error Verifying Current Password", func(t *testing.T) {
+		// Setup mock expectations for an error when verifying the current password
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(false, errors.New("error verifying password"))
 
 		// Execute the function
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, newPassword, currentPassword)
@@ -291,17 +352,10 @@ func TestChangePassword(t *testing.T) {
 	})
 
 	t.Run("Error Updating Password", func(t *testing.T) {
-		var currentPassword string
-
-		// Call the Hash function and handle both the result and the error
-		hashedPassword, hashError := cryptoHash.Hash(currentPassword)
-		if hashError != nil {
-			fmt.Fprintf(os.Stderr, "This is an example error message: %v\n", hashError)
-		}
-
-		// Setup mock expectations for valid password change with the hashed password
-		mockRepo.EXPECT().GetPasswordHash(gomock.Any(), memberID).Return(hashedPassword, nil)
-		mockRepo.EXPECT().UpdatePassword(gomock.Any(), memberID, key, gomock.Any()).Return(errors.New("error updating password"))
+		// Setup mock expectations for valid password change with a matching current password
+		expectValidKey()
+		mockRepo.EXPECT().PasswordMemberRelation(gomock.Any(), memberID, currentPassword).Return(true, nil)
+		mockRepo.EXPECT().UpdatePassword(gomock.Any(), memberID, key, newPassword).Return(errors.New("error updating password"))
 
 		// Execute the function
 		fieldsMap, err := useCase.ChangePassword(ginCtx, memberID, key, newPassword, currentPassword)
@@ -315,15 +369,7 @@ func TestChangePassword(t *testing.T) {
 //View All Billing Address Test case ****************************************
 
 func TestMemberUseCases_GetAllBillingAddresses(t *testing.T) {
-	// Create a new GoMock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a MemberUseCases instance with the mock repository
-	memberUseCases := usecases.NewMemberUseCases(mockRepo)
+	memberUseCases, mockRepo := newTestUseCases(t)
 
 	// Define a memberID for the test
 	memberID := uuid.New()
@@ -348,6 +394,8 @@ func TestMemberUseCases_GetAllBillingAddresses(t *testing.T) {
 		}
 
 		// Set up expectations for GetAllBillingAddresses
+		mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil)
+		mockRepo.EXPECT().GetBillingAddressCountForMember(gomock.Any(), memberID).Return(len(expectedBillingAddresses), nil)
 		mockRepo.EXPECT().GetAllBillingAddresses(gomock.Any(), memberID).Return(expectedBillingAddresses, nil)
 
 		// Convert context.Background() to *gin.Context for testing or specific use cases.
@@ -374,7 +422,7 @@ func TestMemberUseCases_GetAllBillingAddresses(t *testing.T) {
 		// Add additional assertions for metadata if needed
 		assert.NotNil(t, metaData)
 		assert.Equal(t, int32(1), metaData.CurrentPage)
-		assert.Equal(t, int32(10), metaData.PerPage)
+		assert.Equal(t, int32(consts.DefaultLimitAddress), metaData.PerPage)
 		assert.Equal(t, int64(len(expectedBillingAddresses)), metaData.Total)
 	})
 }
@@ -389,15 +437,7 @@ func TestMemberRepo_IsMemberExists(t *testing.T) {
 	}
 	logger.InitLogger(clientOpt)
 
-	// Create a new GoMock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a MemberUseCases instance with the mock repository
-	memberUseCases := usecases.NewMemberUseCases(mockRepo)
+	memberUseCases, mockRepo := newTestUseCases(t)
 	memberID := uuid.New()
 
 	t.Run("Member Exists", func(t *testing.T) {
@@ -433,15 +473,7 @@ func TestMemberUseCases_UpdateMember(t *testing.T) {
 	}
 	logger.InitLogger(clientOpt)
 
-	// Create a new GoMock controller
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	// Create a mock repository
-	mockRepo := mock.NewMockMemberRepoImply(ctrl)
-
-	// Create a MemberUseCases instance with the mock repository
-	memberUseCases := usecases.NewMemberUseCases(mockRepo)
+	memberUseCases, mockRepo := newTestUseCases(t)
 
 	// Define test data with valid member details
 	memberID := uuid.New()
@@ -454,16 +486,26 @@ func TestMemberUseCases_UpdateMember(t *testing.T) {
 		Country:   "IN",
 	}
 
+	currentMember := entities.MemberByID{
+		Country: sql.NullString{String: "IN", Valid: true},
+		State:   sql.NullString{String: "CA", Valid: true},
+		Version: 1,
+	}
+	mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil).Times(3)
+	mockRepo.EXPECT().GetMemberByID(gomock.Any(), memberID).Return(currentMember, nil).Times(3)
+	mockRepo.EXPECT().CountryExists("IN").Return(true, nil).Times(3)
+	mockRepo.EXPECT().StateExists("CA", "IN").Return(true, nil).Times(3)
+
 	// Expectations for the mock repository (valid case)
 	mockRepo.EXPECT().
-		UpdateMember(gomock.Any(), gomock.Eq(memberID), gomock.Eq(validTestMember)).
-		Return(nil).
+		UpdateMember(gomock.Any(), gomock.Eq(memberID), gomock.Eq(validTestMember), currentMember.Version).
+		Return(int64(2), nil).
 		Times(1)
 	// Convert context.Background() to *gin.Context for testing or specific use cases.
 	ginCtx := createTestGinContext()
 	// Perform the actual method call for the valid case
 
-	fieldsMap, err := memberUseCases.UpdateMember(ginCtx, memberID, validTestMember)
+	fieldsMap, err := memberUseCases.UpdateMember(ginCtx, memberID, validTestMember, 0)
 
 	// Check for errors (both general and validation errors)
 	if err != nil {
@@ -475,23 +517,24 @@ func TestMemberUseCases_UpdateMember(t *testing.T) {
 		t.Errorf("Expected no validation errors for valid data, got: %v", fieldsMap)
 	}
 
-	// Define test data with invalid member details (missing first name)
+	// Define test data with invalid member details (invalid first name)
 	invalidTestMember := entities.Member{
-		LastName: "Doe",
-		Zipcode:  "12345",
-		State:    "CA",
-		Phone:    "9497132205",
-		Country:  "IN",
+		FirstName: "1John",
+		LastName:  "Doe",
+		Zipcode:   "12345",
+		State:     "CA",
+		Phone:     "9497132205",
+		Country:   "IN",
 	}
 
-	// Perform the actual method call for the invalid case (missing first name)
-	fieldsMap, _ = memberUseCases.UpdateMember(ginCtx, memberID, invalidTestMember)
+	// Perform the actual method call for the invalid case (invalid first name)
+	fieldsMap, _ = memberUseCases.UpdateMember(ginCtx, memberID, invalidTestMember, 0)
 
-	// Check that the validation error map contains the expected validation errors (missing first name)
+	// Check that the validation error map contains the expected validation errors (invalid first name)
 	expectedValidationErrors := map[string][]string{
-		"firstName": {"required", "valid"},
+		consts.FirstName: {consts.Valid},
 	}
-	if validateFieldsMap(fieldsMap, expectedValidationErrors) {
+	if !validateFieldsMap(fieldsMap, expectedValidationErrors) {
 		t.Errorf("Expected validation errors %v, got %v", expectedValidationErrors, fieldsMap)
 	}
 
@@ -501,16 +544,16 @@ func TestMemberUseCases_UpdateMember(t *testing.T) {
 		LastName:  "123",
 		Zipcode:   "12356",
 		State:     "CA",
-		Phone:     "1234567890",
+		Phone:     "9197132205",
 		Country:   "IN",
 	}
 
 	// Perform the actual method call for another invalid case (invalid last name)
-	fieldsMap, _ = memberUseCases.UpdateMember(ginCtx, memberID, invalidTestMember)
+	fieldsMap, _ = memberUseCases.UpdateMember(ginCtx, memberID, invalidTestMember, 0)
 	expectedValidationErrors = map[string][]string{
-		"firstName": {"required", "valid"},
+		consts.LastName: {consts.Valid},
 	}
-	if validateFieldsMap(fieldsMap, expectedValidationErrors) {
+	if !validateFieldsMap(fieldsMap, expectedValidationErrors) {
 		t.Errorf("Expected validation errors %v, got %v", expectedValidationErrors, fieldsMap)
 	}
 
@@ -581,7 +624,7 @@ func TestRegisterMember(t *testing.T) {
 				mockMemberRepo.EXPECT().CheckEmailExists(gomock.Any(), "147f090b-fc3c-4edb-ad9b-085a6381850e", member1.Email).
 					Times(1).Return(false, nil)
 				mockMemberRepo.EXPECT().RegisterMember(gomock.Any(), member1, "147f090b-fc3c-4edb-ad9b-085a6381850e").
-					Times(1).Return(nil)
+					Times(1).Return(uuid.New(), nil)
 			},
 			checkResponse: func(t *testing.T, fieldsMap map[string][]string, err error) {
 				require.Len(t, fieldsMap, 0)
@@ -609,7 +652,7 @@ func TestRegisterMember(t *testing.T) {
 					Times(1).Return(false, nil)
 
 				mockMemberRepo.EXPECT().RegisterMember(gomock.Any(), member2, partnerID).
-					Times(1).Return(nil)
+					Times(1).Return(uuid.New(), nil)
 			},
 			checkResponse: func(t *testing.T, fieldsMap map[string][]string, err error) {
 				require.Len(t, fieldsMap, 0)
@@ -680,13 +723,10 @@ func TestRegisterMember(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockMemberRepo := mock.NewMockMemberRepoImply(ctrl)
+			memberUseCase, mockMemberRepo := newTestUseCases(t)
+			// The providers of the cases are all known
+			mockMemberRepo.EXPECT().ProviderExists(gomock.Any(), tc.member.Provider).Return(true, nil)
 			tc.buildStubs(mockMemberRepo)
-
-			memberUseCase := usecases.NewMemberUseCases(mockMemberRepo)
 			fieldsMap, err := memberUseCase.RegisterMember(context.Background(), tc.member, map[string]interface{}{}, partnerID, "", "")
 
 			tc.checkResponse(t, fieldsMap, err)
@@ -696,9 +736,6 @@ func TestRegisterMember(t *testing.T) {
 
 // Test case to get basic member details by email
 func TestGetBasicMemberDetailsByEmail(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
 	// Initialize logger outside the loop
 	clientOpt := &logger.ClientOptions{
 		Service:             consts.AppName,
@@ -714,6 +751,8 @@ func TestGetBasicMemberDetailsByEmail(t *testing.T) {
 		Provider: consts.ProviderInternal,
 		Password: "Pass@123",
 	}
+	partnerID := "614608f2-6538-4733-aded-96f902007254"
+	memberID := uuid.New()
 
 	testCases := []struct {
 		name          string
@@ -725,15 +764,23 @@ func TestGetBasicMemberDetailsByEmail(t *testing.T) {
 			name: "Valid Email and Password",
 			args: args,
 			buildStubs: func(mockMemberRepo *mock.MockMemberRepoImply) {
+				mockMemberRepo.EXPECT().CheckEmailProviderRelation(gomock.Any(), args.Email, args.Provider).Return(true, nil)
+				mockMemberRepo.EXPECT().GetMemberIDByEmail(gomock.Any(), partnerID, args.Email).Return(memberID, nil)
+				mockMemberRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(time.Time{}, false, nil)
 				mockMemberRepo.EXPECT().GetBasicMemberDetailsByEmail(
-					gomock.Any(),
-					gomock.Eq("614608f2-6538-4733-aded-96f902007254"),
+					gomock.Eq(partnerID),
 					gomock.Eq(args),
-				).Return(map[string][]string{}, entities.BasicMemberData{}, nil)
+					gomock.Any(),
+				).Return(entities.BasicMemberData{MemberID: memberID, Email: args.Email}, nil)
+				mockMemberRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(nil)
+				mockMemberRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+				mockMemberRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(entities.TwoFactor{MemberID: memberID}, nil)
+				mockMemberRepo.EXPECT().ClearLoginLockout(gomock.Any(), memberID).Return(nil)
 			},
 			checkResponse: func(t *testing.T, fieldsMap map[string][]string, basicData entities.BasicMemberData, err error) {
 				require.Len(t, fieldsMap, 0) // No errors expected
 				require.Nil(t, err)
+				require.Equal(t, memberID, basicData.MemberID)
 			},
 		},
 		{
@@ -744,7 +791,8 @@ func TestGetBasicMemberDetailsByEmail(t *testing.T) {
 				Password: "Pass@123",
 			},
 			buildStubs: func(mockMemberRepo *mock.MockMemberRepoImply) {
-				// No expectations as there should be no repository calls.
+				// No member is looked up without an email
+				mockMemberRepo.EXPECT().CheckEmailProviderRelation(gomock.Any(), "", consts.ProviderInternal).Return(false, nil)
 			},
 			checkResponse: func(t *testing.T, fieldsMap map[string][]string, basicData entities.BasicMemberData, err error) {
 				require.Len(t, fieldsMap, 1)
@@ -756,13 +804,11 @@ func TestGetBasicMemberDetailsByEmail(t *testing.T) {
 	ginCtx := createTestGinContext()
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockMemberRepo := mock.NewMockMemberRepoImply(ctrl)
+			memberUseCase, mockMemberRepo := newTestUseCases(t)
 			tc.buildStubs(mockMemberRepo)
 
-			memberUseCase := usecases.NewMemberUseCases(mockMemberRepo)
-
 			// Use a proper context here, depending on your application requirements
-			fieldsMap, basicData, err := memberUseCase.GetBasicMemberDetailsByEmail(ginCtx, "partnerID_value", tc.args, nil, "expected_endpoint", "expected_method")
+			fieldsMap, basicData, err := memberUseCase.GetBasicMemberDetailsByEmail(ginCtx, partnerID, tc.args, nil, "expected_endpoint", "expected_method")
 
 			tc.checkResponse(t, fieldsMap, basicData, err)
 		})
@@ -852,6 +898,7 @@ func TestViewMembers(t *testing.T) {
 			buildStubs: func(mockMemberRepo *mock.MockMemberRepoImply) {
 				// Expect the GetMemberRecordCount method to be called once and return a mocked record count.
 				mockMemberRepo.EXPECT().GetMemberRecordCount(gomock.Any()).Times(1).Return(int64(50), nil)
+				mockMemberRepo.EXPECT().GetFilteredRecordCount(gomock.Any(), params).Times(1).Return(int64(50), nil)
 
 				// Expect the ViewMembers method to be called with the given params and return sampleMemberData.
 				mockMemberRepo.EXPECT().ViewMembers(gomock.Any(), params).
@@ -864,7 +911,7 @@ func TestViewMembers(t *testing.T) {
 
 				// Validate the metadata values.
 				assert.Equal(t, int32(1), metadata.CurrentPage)
-				assert.Equal(t, int32(10), metadata.PerPage)
+				assert.Equal(t, int32(params.Limit), metadata.PerPage)
 				assert.Equal(t, int32(50), int32(metadata.Total))
 
 			},
@@ -873,6 +920,9 @@ func TestViewMembers(t *testing.T) {
 			name:   "Error Fetching Members",
 			params: params,
 			buildStubs: func(mockMemberRepo *mock.MockMemberRepoImply) {
+				mockMemberRepo.EXPECT().GetMemberRecordCount(gomock.Any()).Times(1).Return(int64(50), nil)
+				mockMemberRepo.EXPECT().GetFilteredRecordCount(gomock.Any(), params).Times(1).Return(int64(50), nil)
+
 				// Expect the ViewMembers method to be called with the given params and return an error.
 				mockMemberRepo.EXPECT().ViewMembers(gomock.Any(), params).
 					Times(1).Return(nil, fmt.Errorf("Failed to fetch members"))
@@ -891,14 +941,9 @@ func TestViewMembers(t *testing.T) {
 	// Iterate over test cases.
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockMemberRepo := mock.NewMockMemberRepoImply(ctrl)
+			memberUseCase, mockMemberRepo := newTestUseCases(t)
 			tc.buildStubs(mockMemberRepo)
 
-			memberUseCase := usecases.NewMemberUseCases(mockMemberRepo)
-
 			memberData, metadata, err := memberUseCase.ViewMembers(ginCtx, tc.params)
 			_ = metadata
 			tc.checkResponse(t, memberData, metadata, err)
@@ -1014,19 +1059,770 @@ func TestViewMemberProfile(t *testing.T) {
 	for i := range testCases {
 		tc := testCases[i]
 		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			mockMemberRepo := mock.NewMockMemberRepoImply(ctrl)
+			memberUseCase, mockMemberRepo := newTestUseCases(t)
 			tc.buildStubs(mockMemberRepo)
-
-			memberUseCase := usecases.NewMemberUseCases(mockMemberRepo)
 			fieldsMap, memberProfile, err := memberUseCase.ViewMemberProfile(ginCtx, context.Background(), tc.memberID, nil, "", "")
 
 			tc.checkResponse(t, fieldsMap, memberProfile, err)
 		})
 	}
 }
+
+// TestInitiatePasswordReset checks that the reset key is delivered by email and not returned.
+func TestInitiatePasswordReset(t *testing.T) {
+	memberNotifier := notifier.NewMemoryNotifier()
+	useCases, mockRepo := newTestUseCases(t, withNotifier(memberNotifier))
+
+	ginCtx := createTestGinContext()
+	memberID := uuid.New()
+	email := "john.doe@example.com"
+
+	t.Run("unknown member or email is answered like a known one", func(t *testing.T) {
+		mockRepo.EXPECT().CheckEmailForMemberID(ginCtx, memberID, "jane@example.com").Return(false, nil)
+		unknownID := uuid.New()
+		mockRepo.EXPECT().CheckEmailForMemberID(ginCtx, unknownID, email).Return(false, nil)
+
+		fieldsMap, err := useCases.InitiatePasswordReset(ginCtx, memberID, "jane@example.com")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		fieldsMap, err = useCases.InitiatePasswordReset(ginCtx, unknownID, email)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Empty(t, memberNotifier.Messages())
+	})
+
+	t.Run("key is emailed to the member", func(t *testing.T) {
+		mockRepo.EXPECT().CheckEmailForMemberID(ginCtx, memberID, email).Return(true, nil)
+		mockRepo.EXPECT().InitiatePasswordReset(ginCtx, memberID, email).Return("5f2b9c1e7a3d4b6c", time.Now().Add(50*time.Minute), nil)
+		mockRepo.EXPECT().GetMemberContact(ginCtx, memberID).Return(entities.MemberContact{Name: "John", Email: email, Language: "es"}, nil)
+
+		fieldsMap, err := useCases.InitiatePasswordReset(ginCtx, memberID, email)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+
+		messages := memberNotifier.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, email, messages[0].To)
+		assert.Equal(t, "Restablece tu contraseña", messages[0].Subject)
+		assert.Contains(t, messages[0].Body, "5f2b9c1e7a3d4b6c")
+		assert.Contains(t, messages[0].Body, "50")
+	})
+}
+
+// TestChangePasswordResetKeyLimits checks the reset key lockout and which claimed attempts are given back.
+func TestChangePasswordResetKeyLimits(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	ginCtx := createTestGinContext()
+	memberID := uuid.New()
+	key := strings.Repeat("ab", consts.ResetKeyBytes)
+
+	t.Run("locked out", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(true, nil)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Locked}, fieldsMap[consts.Key])
+	})
+
+	t.Run("wrong key keeps its claimed attempt", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(false, consts.ErrResetKeyMismatch)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Match}, fieldsMap[consts.Key])
+	})
+
+	t.Run("used key is invalid", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(false, consts.ErrResetKeyInvalid)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.InvalidKey}, fieldsMap[consts.Key])
+	})
+
+	t.Run("key check error gives the attempt back", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(false, errors.New("connection reset"))
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+
+		_, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "OldPassword@123")
+		require.Error(t, err)
+	})
+
+	t.Run("key used up by a concurrent request is invalid", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(true, nil)
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().PasswordMemberRelation(ginCtx, memberID, "OldPassword@123").Return(true, nil)
+		mockRepo.EXPECT().UpdatePassword(ginCtx, memberID, key, "NewPassword@123").Return(consts.ErrResetKeyInvalid)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.InvalidKey}, fieldsMap[consts.Key])
+	})
+
+	t.Run("valid key resets a forgotten password", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(true, nil)
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().PasswordMemberRelation(ginCtx, memberID, "NewPassword@123").Return(false, nil)
+		mockRepo.EXPECT().UpdatePassword(ginCtx, memberID, key, "NewPassword@123").Return(nil)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "NewPassword@123", "")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("forgotten password cannot be reused", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(true, nil)
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().PasswordMemberRelation(ginCtx, memberID, "OldPassword@123").Return(true, nil)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "OldPassword@123", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.InvalidPassword}, fieldsMap[consts.NewPassword])
+	})
+}
+
+// TestChangePasswordPolicy checks new passwords are only held to the configured password policy.
+func TestChangePasswordPolicy(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	utilities.SetPasswordPolicy(utilities.PasswordPolicy{MinLength: 6, RequireLowercase: true})
+	defer utilities.SetPasswordPolicy(utilities.PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireSpecial:   true,
+	})
+
+	ginCtx := createTestGinContext()
+	memberID := uuid.New()
+	key := strings.Repeat("ab", consts.ResetKeyBytes)
+	expectKey := func() {
+		mockRepo.EXPECT().IsMemberExists(memberID, ginCtx).Return(true, nil)
+		mockRepo.EXPECT().ClaimResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().CheckResetKeyMatch(ginCtx, memberID, key).Return(true, nil)
+		mockRepo.EXPECT().ReleaseResetKeyAttempt(ginCtx, memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().PasswordMemberRelation(ginCtx, memberID, "OldPassword@123").Return(true, nil)
+	}
+
+	t.Run("password allowed by a shorter minimum length is set", func(t *testing.T) {
+		expectKey()
+		mockRepo.EXPECT().UpdatePassword(ginCtx, memberID, key, "secret").Return(nil)
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "secret", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("password below the minimum length is refused", func(t *testing.T) {
+		expectKey()
+
+		fieldsMap, err := useCases.ChangePassword(ginCtx, memberID, key, "short", "OldPassword@123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.MinLength}, fieldsMap[consts.NewPassword])
+	})
+}
+
+// TestProcessSubscriptionLifecycle checks that every transition is notified to its member.
+func TestProcessSubscriptionLifecycle(t *testing.T) {
+	memberNotifier := notifier.NewMemoryNotifier()
+	useCases, mockRepo := newTestUseCases(t, withNotifier(memberNotifier))
+
+	ctx := context.Background()
+	memberID := uuid.New()
+	expiration := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	transitions := []entities.SubscriptionTransition{
+		{MemberSubscriptionID: uuid.New(), MemberID: memberID, CustomName: "Gold", FromStatus: consts.SubscriptionStatusActive,
+			ToStatus: consts.SubscriptionStatusWarning, ExpirationDate: expiration, GraceEnd: expiration.AddDate(0, 0, 14)},
+		{MemberSubscriptionID: uuid.New(), MemberID: memberID, FromStatus: consts.SubscriptionStatusInGrace,
+			ToStatus: consts.SubscriptionStatusExpired, ExpirationDate: expiration, GraceEnd: expiration.AddDate(0, 0, 14)},
+	}
+
+	mockRepo.EXPECT().TransitionSubscriptionStatuses(ctx, gomock.Any()).Return(transitions, nil)
+	mockRepo.EXPECT().GetMemberContact(ctx, memberID).Return(entities.MemberContact{Name: "John", Email: "john.doe@example.com"}, nil).Times(2)
+
+	require.NoError(t, useCases.ProcessSubscriptionLifecycle(ctx))
+
+	messages := memberNotifier.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Your subscription expires soon", messages[0].Subject)
+	assert.Contains(t, messages[0].Body, "Gold")
+	assert.Contains(t, messages[0].Body, "2024-03-01")
+	assert.Equal(t, "Your subscription has ended", messages[1].Subject)
+	assert.Contains(t, messages[1].Body, "2024-03-15")
+
+	t.Run("sweep failure", func(t *testing.T) {
+		mockRepo.EXPECT().TransitionSubscriptionStatuses(ctx, gomock.Any()).Return(nil, errors.New("connection refused"))
+		assert.Error(t, useCases.ProcessSubscriptionLifecycle(ctx))
+	})
+}
+
+func TestHandleSubscriptionCheckoutPayment(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	checkoutData := entities.CheckoutSubscription{
+		SubscriptionID:   uuid.New().String(),
+		PaymentGatewayID: 1,
+	}
+	memberSubscriptionID := uuid.New().String()
+	paymentID := uuid.New()
+
+	expectCheckout := func(amount float64) {
+		mockRepo.EXPECT().CheckSubscriptionExistenceAndStatusForCheckout(gomock.Any(), checkoutData.SubscriptionID).Return(true, true, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+		mockRepo.EXPECT().GetSubscriptionCountForLastYear(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(0, nil)
+		mockRepo.EXPECT().GetMaxSubscriptionLimitForID(gomock.Any(), checkoutData.SubscriptionID).Return(5, nil)
+		mockRepo.EXPECT().IsFreeSubscription(gomock.Any(), checkoutData.SubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().CheckIfPayoutGatewayExists(gomock.Any(), 1).Return(true, nil)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().HasSubscribedToOneTimePlan(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().HandleSubscriptionCheckout(gomock.Any(), memberID, checkoutData).Return(memberSubscriptionID, nil)
+		// The price is checked before the subscription is added, and charged after
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "USD").Return(entities.SubscriptionPlanTerms{
+			SubscriptionID: checkoutData.SubscriptionID,
+			Name:           "Gold",
+			Amount:         amount,
+			TaxPercentage:  10,
+			CurrencyID:     1,
+			Currency:       "USD",
+			DurationWeeks:  52,
+		}, nil).Times(2)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{
+			Name:      "John Doe",
+			Email:     "john.doe@example.com",
+			PayingTax: true,
+		}, nil)
+	}
+
+	t.Run("captured payment activates the subscription", func(t *testing.T) {
+		expectCheckout(10)
+		mockRepo.EXPECT().RecordCheckoutPayment(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment) (uuid.UUID, error) {
+				assert.Equal(t, consts.PaymentStatusCaptured, record.Status)
+				assert.Equal(t, consts.PaymentKindCheckout, record.Kind)
+				assert.Equal(t, 11.0, record.Amount)
+				assert.Equal(t, "USD", record.Currency)
+				return paymentID, nil
+			})
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice entities.Invoice) (entities.Invoice, error) {
+				assert.Equal(t, paymentID, invoice.PaymentID)
+				assert.Equal(t, memberID, invoice.MemberID)
+				assert.Equal(t, "USD", invoice.Currency)
+				require.Len(t, invoice.Lines, 1)
+				assert.Equal(t, "Gold subscription, 52 weeks", invoice.Lines[0].Description)
+				assert.Equal(t, 10.0, invoice.Subtotal)
+				assert.Equal(t, 1.0, invoice.TaxAmount)
+				assert.Equal(t, 11.0, invoice.Total)
+				assert.Equal(t, "John Doe", invoice.BillingName)
+				return invoice, nil
+			})
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("captured payment that cannot be recorded leaves the subscription inactive", func(t *testing.T) {
+		expectCheckout(10)
+		recordErr := errors.New("connection reset")
+		mockRepo.EXPECT().RecordCheckoutPayment(gomock.Any(), gomock.Any()).Return(uuid.Nil, recordErr)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.ErrorIs(t, err, recordErr)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("declined payment marks the subscription as failed", func(t *testing.T) {
+		// 9.10 plus 10% tax is 10.01, which the fake gateway declines
+		expectCheckout(9.1)
+		mockRepo.EXPECT().RecordCheckoutPayment(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment) (uuid.UUID, error) {
+				assert.Equal(t, consts.PaymentStatusFailed, record.Status)
+				return paymentID, nil
+			})
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.PaymentFailed}, fieldsMap[consts.PaymentGatewayID])
+	})
+
+	t.Run("plan without a price in the gateway currency is refused", func(t *testing.T) {
+		mockRepo.EXPECT().CheckSubscriptionExistenceAndStatusForCheckout(gomock.Any(), checkoutData.SubscriptionID).Return(true, true, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+		mockRepo.EXPECT().GetSubscriptionCountForLastYear(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(0, nil)
+		mockRepo.EXPECT().GetMaxSubscriptionLimitForID(gomock.Any(), checkoutData.SubscriptionID).Return(5, nil)
+		mockRepo.EXPECT().IsFreeSubscription(gomock.Any(), checkoutData.SubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().CheckIfPayoutGatewayExists(gomock.Any(), 1).Return(true, nil)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"GBP"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "GBP").
+			Return(entities.SubscriptionPlanTerms{}, consts.ErrPlanPriceNotFound)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.CurrencyMismatch}, fieldsMap[consts.PaymentGatewayID])
+	})
+}
+
+func TestHandleSubscriptionCheckoutWithoutGateway(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t, withPayments(payment.NewRegistry()))
+
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	checkoutData := entities.CheckoutSubscription{
+		SubscriptionID:   uuid.New().String(),
+		PaymentGatewayID: 1,
+	}
+	mockRepo.EXPECT().CheckSubscriptionExistenceAndStatusForCheckout(gomock.Any(), checkoutData.SubscriptionID).Return(true, true, nil)
+	mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+	mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+	mockRepo.EXPECT().GetSubscriptionCountForLastYear(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(0, nil)
+	mockRepo.EXPECT().GetMaxSubscriptionLimitForID(gomock.Any(), checkoutData.SubscriptionID).Return(5, nil)
+	mockRepo.EXPECT().IsFreeSubscription(gomock.Any(), checkoutData.SubscriptionID).Return(false, nil)
+	mockRepo.EXPECT().CheckIfPayoutGatewayExists(gomock.Any(), 1).Return(true, nil)
+	mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+	mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+	mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+		Return(`{"gateway":"paypal","payin":true,"default_payin_currency":"USD"}`, nil)
+
+	// Without a registered gateway the paid plan is refused, nothing is subscribed or charged
+	fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotSupported}, fieldsMap[consts.PaymentGatewayID])
+}
+
+func TestHandlePaymentWebhook(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	partnerID := uuid.New().String()
+	subscriptionPayment := entities.SubscriptionPayment{
+		ID:                   uuid.New(),
+		MemberSubscriptionID: uuid.New().String(),
+		MemberID:             uuid.New(),
+		PaymentGatewayID:     1,
+		GatewayPaymentID:     "fake_pay_123",
+		Kind:                 consts.PaymentKindCheckout,
+		Status:               consts.PaymentStatusPending,
+	}
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","payment_id":"fake_pay_123"}`)
+	signature := hex.EncodeToString(payment.SignHMAC(payload, "whsec"))
+
+	// The signature is checked with the partner's credentials before the payment is looked up.
+	expectSignatureCheck := func() {
+		mockRepo.EXPECT().GetPaymentDetailsByPartner(gomock.Any(), partnerID).Return(map[int]string{1: "encrypted"}, nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").Return(`{"gateway":"fake","client_secret":"whsec"}`, nil)
+	}
+	expectVerification := func() {
+		expectSignatureCheck()
+		mockRepo.EXPECT().GetSubscriptionPaymentByGatewayPaymentID(gomock.Any(), "fake_pay_123").Return(subscriptionPayment, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), subscriptionPayment.MemberID, partnerID).Return(true, nil)
+	}
+
+	t.Run("succeeded event activates the subscription", func(t *testing.T) {
+		expectVerification()
+		mockRepo.EXPECT().ApplyPaymentWebhookEvent(gomock.Any(), "fake", partnerID, gomock.Any(), payload, subscriptionPayment, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ string, event entities.PaymentWebhookEvent, _ []byte, _ entities.SubscriptionPayment,
+				change entities.PaymentStateChange) (bool, error) {
+				assert.Equal(t, "evt_1", event.ID)
+				assert.Equal(t, consts.PaymentStatusCaptured, change.PaymentStatus)
+				assert.Equal(t, consts.SubscriptionStatusActive, change.SubscriptionStatus)
+				assert.Contains(t, change.FromStatuses, consts.SubscriptionStatusProcessing)
+				return true, nil
+			})
+
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, "sha256="+signature)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("failed renewal is retried", func(t *testing.T) {
+		renewalPayment := subscriptionPayment
+		renewalPayment.Kind = consts.PaymentKindRenewal
+		failedPayload := []byte(`{"id":"evt_2","type":"payment.failed","payment_id":"fake_pay_123"}`)
+		expectSignatureCheck()
+		mockRepo.EXPECT().GetSubscriptionPaymentByGatewayPaymentID(gomock.Any(), "fake_pay_123").Return(renewalPayment, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), renewalPayment.MemberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().ApplyPaymentWebhookEvent(gomock.Any(), "fake", partnerID, gomock.Any(), failedPayload, renewalPayment,
+			entities.PaymentStateChange{
+				PaymentStatus:       consts.PaymentStatusFailed,
+				FromPaymentStatuses: []string{consts.PaymentStatusPending, consts.PaymentStatusAuthorized},
+				RetryRenewal:        true,
+			}).Return(true, nil)
+
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, failedPayload,
+			hex.EncodeToString(payment.SignHMAC(failedPayload, "whsec")))
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("replayed event is acknowledged", func(t *testing.T) {
+		expectVerification()
+		mockRepo.EXPECT().ApplyPaymentWebhookEvent(gomock.Any(), "fake", partnerID, gomock.Any(), payload, subscriptionPayment, gomock.Any()).Return(false, nil)
+
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("out of order event is acknowledged", func(t *testing.T) {
+		expectVerification()
+		mockRepo.EXPECT().ApplyPaymentWebhookEvent(gomock.Any(), "fake", partnerID, gomock.Any(), payload, subscriptionPayment, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ string, _ entities.PaymentWebhookEvent, _ []byte, _ entities.SubscriptionPayment,
+				change entities.PaymentStateChange) (bool, error) {
+				// A captured event never applies to a refunded payment
+				assert.NotContains(t, change.FromPaymentStatuses, consts.PaymentStatusRefunded)
+				return false, consts.ErrPaymentEventOutOfOrder
+			})
+
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("invalid signature is refused before the payment is looked up", func(t *testing.T) {
+		expectSignatureCheck()
+
+		_, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, hex.EncodeToString(payment.SignHMAC(payload, "other")))
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+
+	t.Run("partner without credentials for the gateway", func(t *testing.T) {
+		mockRepo.EXPECT().GetPaymentDetailsByPartner(gomock.Any(), partnerID).Return(map[int]string{2: "encrypted"}, nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").Return(`{"gateway":"other","client_secret":"whsec"}`, nil)
+
+		_, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+
+	t.Run("payment of another partner", func(t *testing.T) {
+		expectSignatureCheck()
+		mockRepo.EXPECT().GetSubscriptionPaymentByGatewayPaymentID(gomock.Any(), "fake_pay_123").Return(subscriptionPayment, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), subscriptionPayment.MemberID, partnerID).Return(false, nil)
+
+		_, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+
+	t.Run("payment made on another gateway", func(t *testing.T) {
+		otherGatewayPayment := subscriptionPayment
+		otherGatewayPayment.PaymentGatewayID = 2
+		expectSignatureCheck()
+		mockRepo.EXPECT().GetSubscriptionPaymentByGatewayPaymentID(gomock.Any(), "fake_pay_123").Return(otherGatewayPayment, nil)
+
+		_, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+	})
+
+	t.Run("unknown payment is refused like an invalid signature", func(t *testing.T) {
+		expectSignatureCheck()
+		mockRepo.EXPECT().GetSubscriptionPaymentByGatewayPaymentID(gomock.Any(), "fake_pay_123").Return(entities.SubscriptionPayment{}, sql.ErrNoRows)
+
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, payload, signature)
+		assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		fieldsMap, err := useCases.HandlePaymentWebhook(createTestGinContext(), "fake", partnerID, []byte(`{"type":"payment.failed"}`), signature)
+		require.NoError(t, err)
+		assert.Contains(t, fieldsMap, consts.EventID)
+		assert.Contains(t, fieldsMap, consts.GatewayPaymentID)
+	})
+}
+
+func TestHandleSubscriptionPlanChange(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	basic := entities.SubscriptionPlanTerms{SubscriptionID: uuid.New().String(), Amount: 20, CurrencyID: 1, Currency: "USD", DurationWeeks: 4,
+		MaximumProducts: 5, MaximumTracks: 50, MaximumArtists: 5, IsActive: true}
+	pro := entities.SubscriptionPlanTerms{SubscriptionID: uuid.New().String(), Amount: 40, CurrencyID: 1, Currency: "USD", DurationWeeks: 4,
+		MaximumProducts: 20, MaximumTracks: 200, MaximumArtists: 20, IsActive: true}
+	state := entities.MemberSubscriptionState{
+		MemberSubscriptionID: uuid.New().String(),
+		SubscriptionID:       basic.SubscriptionID,
+		Status:               consts.SubscriptionStatusActive,
+		ExpirationDate:       time.Now().Add(14 * 24 * time.Hour),
+		ProductsAdded:        3,
+		TracksAdded:          30,
+		ArtistsAdded:         2,
+	}
+
+	expectChange := func(next entities.SubscriptionPlanTerms) {
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetMemberSubscriptionState(gomock.Any(), memberID, state.MemberSubscriptionID).Return(state, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), basic.SubscriptionID, "").Return(basic, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), next.SubscriptionID, "").Return(next, nil)
+	}
+	expectUpgradeCharge := func() {
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), pro.SubscriptionID, "USD").Return(pro, nil)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{Name: "John Doe"}, nil)
+	}
+
+	t.Run("upgrade halfway through the period is charged", func(t *testing.T) {
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: pro.SubscriptionID,
+			PaymentGatewayID: 1}
+		expectChange(pro)
+		expectUpgradeCharge()
+		mockRepo.EXPECT().RecordPlanChangePayment(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment, entry entities.SubscriptionLedgerEntry) (uuid.UUID, entities.SubscriptionLedgerEntry, error) {
+				assert.Equal(t, consts.PaymentKindPlanChange, record.Kind)
+				assert.Equal(t, consts.PaymentStatusCaptured, record.Status)
+				assert.Equal(t, 10.0, record.Amount)
+				entry.ID = uuid.New()
+				return uuid.New(), entry, nil
+			})
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice entities.Invoice) (entities.Invoice, error) {
+				assert.Equal(t, consts.PaymentKindPlanChange, invoice.Kind)
+				require.Len(t, invoice.Lines, 1)
+				assert.Equal(t, 10.0, invoice.Total)
+				return invoice, nil
+			})
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		entry, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.NotEqual(t, uuid.Nil, entry.ID)
+		assert.Equal(t, consts.LedgerKindPlanChange, entry.Kind)
+		assert.Equal(t, basic.SubscriptionID, entry.FromSubscriptionID)
+		assert.Equal(t, pro.SubscriptionID, entry.ToSubscriptionID)
+		assert.Equal(t, 10.0, entry.Credit)
+		assert.Equal(t, 20.0, entry.Charge)
+		assert.Equal(t, 10.0, entry.Amount)
+	})
+
+	t.Run("upgrade needs a payment gateway", func(t *testing.T) {
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: pro.SubscriptionID}
+		expectChange(pro)
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Required}, fieldsMap[consts.PaymentGatewayID])
+	})
+
+	t.Run("upgrade on a gateway charging in another currency is refused", func(t *testing.T) {
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: pro.SubscriptionID,
+			PaymentGatewayID: 1}
+		proInEuro := pro
+		proInEuro.CurrencyID, proInEuro.Currency = 2, "EUR"
+		expectChange(pro)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"EUR"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), pro.SubscriptionID, "EUR").Return(proInEuro, nil)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{Name: "John Doe"}, nil)
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.CurrencyMismatch}, fieldsMap[consts.PaymentGatewayID])
+	})
+
+	t.Run("declined upgrade keeps the plan", func(t *testing.T) {
+		// 10.01 is declined by the fake gateway
+		declined := pro
+		declined.Amount = 40.02
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: declined.SubscriptionID,
+			PaymentGatewayID: 1}
+		expectChange(declined)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), declined.SubscriptionID, "USD").Return(declined, nil)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{Name: "John Doe"}, nil)
+		mockRepo.EXPECT().RecordPlanChangePayment(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment, entry entities.SubscriptionLedgerEntry) (uuid.UUID, entities.SubscriptionLedgerEntry, error) {
+				assert.Equal(t, consts.PaymentStatusFailed, record.Status)
+				assert.Equal(t, 10.01, record.Amount)
+				return uuid.New(), entry, nil
+			})
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.PaymentFailed}, fieldsMap[consts.PaymentGatewayID])
+	})
+
+	t.Run("downgrade is credited to the ledger", func(t *testing.T) {
+		cheap := basic
+		cheap.SubscriptionID = uuid.New().String()
+		cheap.Amount = 10
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: cheap.SubscriptionID}
+		expectChange(cheap)
+		mockRepo.EXPECT().ChangeSubscriptionPlan(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, entry entities.SubscriptionLedgerEntry) (entities.SubscriptionLedgerEntry, error) {
+				entry.ID = uuid.New()
+				return entry, nil
+			})
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		entry, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, 10.0, entry.Credit)
+		assert.Equal(t, 5.0, entry.Charge)
+		assert.Equal(t, -5.0, entry.Amount)
+	})
+
+	t.Run("plan changed by a concurrent request", func(t *testing.T) {
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: pro.SubscriptionID,
+			PaymentGatewayID: 1}
+		expectChange(pro)
+		expectUpgradeCharge()
+		mockRepo.EXPECT().RecordPlanChangePayment(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(uuid.Nil, entities.SubscriptionLedgerEntry{}, sql.ErrNoRows)
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.SubscriptionID])
+	})
+
+	t.Run("downgrade below current usage", func(t *testing.T) {
+		small := basic
+		small.SubscriptionID = uuid.New().String()
+		small.MaximumTracks = 10
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: small.SubscriptionID}
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetMemberSubscriptionState(gomock.Any(), memberID, state.MemberSubscriptionID).Return(state, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), basic.SubscriptionID, "").Return(basic, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), small.SubscriptionID, "").Return(small, nil)
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.LimitExceeds}, fieldsMap[consts.NewSubscriptionID])
+	})
+
+	t.Run("subscription not active", func(t *testing.T) {
+		expired := state
+		expired.Status = consts.SubscriptionStatusExpired
+		data := entities.SubscriptionPlanChange{MemberSubscriptionID: state.MemberSubscriptionID, NewSubscriptionID: pro.SubscriptionID}
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetMemberSubscriptionState(gomock.Any(), memberID, state.MemberSubscriptionID).Return(expired, nil)
+
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, data, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Inactive}, fieldsMap[consts.SubscriptionID])
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, fieldsMap, err := useCases.HandleSubscriptionPlanChange(createTestGinContext(), memberID, entities.SubscriptionPlanChange{}, partnerID)
+		require.NoError(t, err)
+		assert.Contains(t, fieldsMap, consts.SubscriptionID)
+		assert.Contains(t, fieldsMap, consts.NewSubscriptionID)
+	})
+}
+
+func TestExportMember(t *testing.T) {
+	activities := activity.NewMemoryRecorder()
+	useCases, mockRepo := newTestUseCases(t, withActivities(activities))
+
+	memberID := uuid.New()
+	adminID := uuid.NewString()
+
+	t.Run("export is recorded in the activity log", func(t *testing.T) {
+		ctx := createTestGinContext()
+		ctx.Set(consts.ContextMemberID, adminID)
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil)
+		mockRepo.EXPECT().GetMemberExport(gomock.Any(), memberID).Return(entities.MemberExport{
+			MemberID: memberID,
+			Profile:  entities.MemberExportProfile{Email: "john.doe@example.com"},
+		}, nil)
+
+		export, fieldsMap, err := useCases.ExportMember(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, "john.doe@example.com", export.Profile.Email)
+		assert.False(t, export.ExportedOn.IsZero())
+
+		recorded := activities.Activities()
+		require.Len(t, recorded, 1)
+		assert.Equal(t, memberID.String(), recorded[0].MemberID)
+		assert.Equal(t, consts.ActivityMemberDataExported, recorded[0].Action)
+		assert.Equal(t, adminID, recorded[0].Data["requested_by"])
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(false, nil)
+
+		_, fieldsMap, err := useCases.ExportMember(createTestGinContext(), memberID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.MemberrID])
+	})
+}
+
+func TestMemberErasure(t *testing.T) {
+	activities := activity.NewMemoryRecorder()
+	useCases, mockRepo := newTestUseCases(t, withActivities(activities))
+
+	memberID := uuid.New()
+
+	t.Run("request is queued once", func(t *testing.T) {
+		ctx := createTestGinContext()
+		ctx.Set(consts.ContextMemberID, memberID.String())
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil).Times(2)
+		mockRepo.EXPECT().RequestMemberErasure(gomock.Any(), memberID, memberID.String()).Return(true, nil)
+		mockRepo.EXPECT().RequestMemberErasure(gomock.Any(), memberID, memberID.String()).Return(false, nil)
+
+		fieldsMap, err := useCases.RequestMemberErasure(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		fieldsMap, err = useCases.RequestMemberErasure(ctx, memberID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+
+		recorded := activities.Activities()
+		require.Len(t, recorded, 1)
+		assert.Equal(t, consts.ActivityMemberErasureRequested, recorded[0].Action)
+	})
+
+	t.Run("job erases pending members and keeps failed ones pending", func(t *testing.T) {
+		erased := entities.MemberErasureRequest{ID: uuid.New(), MemberID: memberID}
+		failing := entities.MemberErasureRequest{ID: uuid.New(), MemberID: uuid.New()}
+		mockRepo.EXPECT().GetPendingMemberErasures(gomock.Any(), 10).Return([]entities.MemberErasureRequest{erased, failing}, nil)
+		mockRepo.EXPECT().EraseMember(gomock.Any(), erased).Return(true, nil)
+		mockRepo.EXPECT().EraseMember(gomock.Any(), failing).Return(false, errors.New("deadlock detected"))
+
+		err := useCases.ProcessMemberErasures(context.Background(), 10)
+		assert.Error(t, err)
+
+		recorded := activities.Activities()
+		require.Len(t, recorded, 2)
+		assert.Equal(t, consts.ActivityMemberErased, recorded[1].Action)
+		assert.Equal(t, memberID.String(), recorded[1].MemberID)
+	})
+}
+
 func createTestGinContext() *gin.Context {
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
@@ -1040,3 +1836,1418 @@ func createTestGinContext() *gin.Context {
 
 	return c
 }
+
+func TestListMembers(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	lastMember := uuid.New()
+	next := &entities.MemberCursor{
+		Sort:  consts.MemberSortCreatedOn,
+		Order: consts.OrderDesc,
+		Value: "2024-03-01T10:00:00.123456Z",
+		ID:    lastMember,
+	}
+
+	t.Run("pages follow the cursor", func(t *testing.T) {
+		active := true
+		firstPage := entities.MemberListFilter{
+			Limit:       2,
+			Sort:        consts.MemberSortCreatedOn,
+			Order:       consts.OrderDesc,
+			Active:      &active,
+			Roles:       []int{1, 2, 3},
+			Countries:   []string{"IN", "US"},
+			CreatedFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			CreatedTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+		params := entities.MemberListParams{
+			Limit:       2,
+			Active:      "true",
+			Roles:       []string{"1,2", "3"},
+			Countries:   []string{"in", "US"},
+			CreatedFrom: "2024-01-01",
+			CreatedTo:   "2024-01-31",
+		}
+		mockRepo.EXPECT().CountMembers(gomock.Any(), firstPage).Return(int64(3), nil)
+		mockRepo.EXPECT().ListMembers(gomock.Any(), firstPage).
+			Return([]entities.ViewMembers{{MemberId: uuid.New()}, {MemberId: lastMember}}, next, nil)
+
+		members, metadata, fieldsMap, err := useCases.ListMembers(createTestGinContext(), params)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Len(t, members, 2)
+		assert.Equal(t, int64(3), metadata.Total)
+		require.NotEmpty(t, metadata.NextCursor)
+
+		secondPage := firstPage
+		secondPage.After = next
+		params.Cursor = metadata.NextCursor
+		mockRepo.EXPECT().CountMembers(gomock.Any(), secondPage).Return(int64(3), nil)
+		mockRepo.EXPECT().ListMembers(gomock.Any(), secondPage).
+			Return([]entities.ViewMembers{{MemberId: uuid.New()}}, nil, nil)
+
+		members, metadata, fieldsMap, err = useCases.ListMembers(createTestGinContext(), params)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Len(t, members, 1)
+		assert.Empty(t, metadata.NextCursor)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			params entities.MemberListParams
+			field  string
+		}{
+			{"limit above maximum", entities.MemberListParams{Limit: consts.MaximumLimit + 1}, consts.Limit},
+			{"unknown sort", entities.MemberListParams{Sort: "password"}, consts.Sort},
+			{"malformed cursor", entities.MemberListParams{Cursor: "garbage"}, consts.Cursor},
+			{"cursor of another sort", entities.MemberListParams{Sort: consts.MemberSortEmail, Cursor: encodeCursor(t, next)}, consts.Cursor},
+			{"role is not a number", entities.MemberListParams{Roles: []string{"1,admin"}}, consts.Role},
+			{"country is not a code", entities.MemberListParams{Countries: []string{"India"}}, consts.Country},
+			{"active is not a bool", entities.MemberListParams{Active: "maybe"}, consts.Active},
+			{"empty creation range", entities.MemberListParams{CreatedFrom: "2024-02-01", CreatedTo: "2024-01-01"}, consts.CreatedTo},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, fieldsMap, err := useCases.ListMembers(createTestGinContext(), tt.params)
+				require.NoError(t, err)
+				assert.Equal(t, []string{consts.Invalid}, fieldsMap[tt.field])
+			})
+		}
+	})
+}
+
+func encodeCursor(t *testing.T, position *entities.MemberCursor) string {
+	token, err := cursor.Encode(position)
+	require.NoError(t, err)
+	return token
+}
+
+func TestImportMembers(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	partnerID := uuid.NewString()
+	adminID := uuid.NewString()
+
+	t.Run("upload is queued", func(t *testing.T) {
+		ctx := createTestGinContext()
+		ctx.Set(consts.ContextPartnerID, partnerID)
+		ctx.Set(consts.ContextMemberID, adminID)
+
+		upload := "email,firstname,password\njohn@example.com,John,Secret@123\njane@example.com,Jane,secret\n"
+		importID := uuid.New()
+		mockRepo.EXPECT().CreateMemberImport(gomock.Any(), entities.MemberImport{
+			PartnerID: partnerID, Format: consts.ImportFormatCSV, RequestedBy: adminID,
+		}, gomock.Any()).DoAndReturn(func(_ context.Context, memberImport entities.MemberImport, importRows []entities.MemberImportRow) (entities.MemberImport, error) {
+			require.Len(t, importRows, 2)
+			assert.Equal(t, 1, importRows[0].RowNumber)
+			assert.Equal(t, consts.ProviderInternal, importRows[0].Member.Provider)
+			assert.Empty(t, importRows[0].PasswordErrors)
+			// The password policy is applied at upload
+			assert.Contains(t, importRows[1].PasswordErrors[consts.Password], consts.MinLengthPassword)
+			memberImport.ID = importID
+			memberImport.TotalRows = len(importRows)
+			return memberImport, nil
+		})
+
+		memberImport, fieldsMap, err := useCases.ImportMembers(ctx, consts.ImportFormatCSV, strings.NewReader(upload))
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, importID, memberImport.ID)
+		assert.Equal(t, 2, memberImport.TotalRows)
+	})
+
+	t.Run("unreadable uploads", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			format string
+			upload string
+			want   string
+		}{
+			{"unsupported format", "xml", "<members/>", consts.InvalidFormat},
+			{"no rows", consts.ImportFormatCSV, "email\n", consts.Empty},
+			{"malformed row", consts.ImportFormatNDJSON, "{\"email\":", consts.Invalid},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctx := createTestGinContext()
+				ctx.Set(consts.ContextPartnerID, partnerID)
+
+				_, fieldsMap, err := useCases.ImportMembers(ctx, tt.format, strings.NewReader(tt.upload))
+				require.NoError(t, err)
+				assert.Equal(t, []string{tt.want}, fieldsMap[consts.File])
+			})
+		}
+	})
+}
+
+func TestProcessMemberImports(t *testing.T) {
+	memberNotifier := notifier.NewMemoryNotifier()
+	useCases, mockRepo := newTestUseCases(t, withNotifier(memberNotifier))
+
+	partnerID := uuid.NewString()
+	memberImport := entities.MemberImport{ID: uuid.New(), PartnerID: partnerID, Status: consts.ImportStatusProcessing}
+	valid := entities.Member{
+		FirstName: "John", LastName: "Doe", Email: "john@example.com", Country: "IN", State: "KL",
+		PasswordHash: "$argon2id$hash", Provider: consts.ProviderInternal, TermsConditionChecked: true, PayingTax: true,
+	}
+	invalid := valid
+	invalid.Email = "jane@example.com"
+	invalid.Phone = "12"
+	invalid.State = "XX"
+	invalid.PasswordHash = ""
+	rejected := valid
+	rejected.Email = "joe@example.com"
+
+	mockRepo.EXPECT().ClaimMemberImports(gomock.Any(), 5, time.Minute).Return([]entities.MemberImport{memberImport}, nil)
+	mockRepo.EXPECT().GetPendingMemberImportRows(gomock.Any(), memberImport.ID).Return([]entities.MemberImportRow{
+		{RowNumber: 1, Member: valid},
+		{RowNumber: 2, Member: invalid, PasswordErrors: map[string][]string{consts.Password: {consts.Format}}},
+		{RowNumber: 3, Member: rejected},
+	}, nil)
+	mockRepo.EXPECT().CheckEmailExists(gomock.Any(), partnerID, gomock.Any()).Return(false, nil).Times(3)
+	mockRepo.EXPECT().CountryExists("IN").Return(true, nil).Times(3)
+	mockRepo.EXPECT().StateExists("KL", "IN").Return(true, nil).Times(2)
+	mockRepo.EXPECT().StateExists("XX", "IN").Return(false, nil)
+	mockRepo.EXPECT().ProviderExists(gomock.Any(), consts.ProviderInternal).Return(true, nil).Times(3)
+	// The imported row is completed by the repository along with the registration
+	mockRepo.EXPECT().ImportMember(gomock.Any(), memberImport.ID, 1, valid, partnerID).Return(uuid.New(), nil)
+	// A row the database rejects fails instead of staying pending
+	mockRepo.EXPECT().ImportMember(gomock.Any(), memberImport.ID, 3, rejected, partnerID).Return(uuid.Nil, consts.ErrMemberRejected)
+	mockRepo.EXPECT().CompleteMemberImportRow(gomock.Any(), memberImport.ID, 3, map[string][]string{consts.Row: {consts.Rejected}}).Return(nil)
+	mockRepo.EXPECT().CompleteMemberImportRow(gomock.Any(), memberImport.ID, 2, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ int, fieldsMap map[string][]string) error {
+			assert.Equal(t, []string{consts.StateNotExist}, fieldsMap[consts.State])
+			assert.Equal(t, []string{consts.Format}, fieldsMap[consts.PhoneNumber])
+			assert.Equal(t, []string{consts.Format}, fieldsMap[consts.Password])
+			return nil
+		})
+	mockRepo.EXPECT().CompleteMemberImport(gomock.Any(), memberImport.ID).Return(nil)
+
+	require.NoError(t, useCases.ProcessMemberImports(context.Background(), 5, time.Minute))
+
+	// The imported member is welcomed and asked to verify the email address
+	messages := memberNotifier.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, valid.Email, messages[0].To)
+	assert.Equal(t, valid.Email, messages[1].To)
+
+	t.Run("row that cannot be imported stays pending", func(t *testing.T) {
+		mockRepo.EXPECT().ClaimMemberImports(gomock.Any(), 5, time.Minute).Return([]entities.MemberImport{memberImport}, nil)
+		mockRepo.EXPECT().GetPendingMemberImportRows(gomock.Any(), memberImport.ID).Return([]entities.MemberImportRow{
+			{RowNumber: 1, Member: valid},
+		}, nil)
+		mockRepo.EXPECT().CheckEmailExists(gomock.Any(), partnerID, valid.Email).Return(false, nil)
+		mockRepo.EXPECT().CountryExists("IN").Return(true, nil)
+		mockRepo.EXPECT().StateExists("KL", "IN").Return(true, nil)
+		mockRepo.EXPECT().ProviderExists(gomock.Any(), consts.ProviderInternal).Return(true, nil)
+		mockRepo.EXPECT().ImportMember(gomock.Any(), memberImport.ID, 1, valid, partnerID).Return(uuid.Nil, errors.New("connection reset"))
+
+		require.Error(t, useCases.ProcessMemberImports(context.Background(), 5, time.Minute))
+		assert.Len(t, memberNotifier.Messages(), 2)
+	})
+}
+
+func TestExportMembers(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	first, second := uuid.New(), uuid.New()
+	next := &entities.MemberCursor{Sort: consts.MemberSortEmail, Order: consts.OrderAsc, Value: "a@example.com", ID: first}
+	filter := entities.MemberListFilter{
+		Limit:     consts.ExportBatchSize,
+		Sort:      consts.MemberSortEmail,
+		Order:     consts.OrderAsc,
+		Countries: []string{"IN"},
+	}
+
+	t.Run("batches are streamed", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().ListMembers(gomock.Any(), filter).
+				Return([]entities.ViewMembers{{MemberId: first, Email: "a@example.com"}}, next, nil),
+			mockRepo.EXPECT().ListMembers(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, got entities.MemberListFilter) ([]entities.ViewMembers, *entities.MemberCursor, error) {
+					assert.Equal(t, next, got.After)
+					return []entities.ViewMembers{{MemberId: second, Email: "b@example.com"}}, nil, nil
+				}),
+		)
+
+		var out strings.Builder
+		params := entities.MemberListParams{Sort: consts.MemberSortEmail, Countries: []string{"IN"}, Limit: 1000}
+		fieldsMap, err := useCases.ExportMembers(createTestGinContext(), params, &out)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[1], first.String()+","))
+		assert.True(t, strings.HasPrefix(lines[2], second.String()+","))
+	})
+
+	t.Run("nothing is written for invalid filters", func(t *testing.T) {
+		var out strings.Builder
+		fieldsMap, err := useCases.ExportMembers(createTestGinContext(), entities.MemberListParams{Active: "sometimes"}, &out)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Active])
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	signer := verification.NewSigner("secret", time.Hour)
+	useCases, mockRepo := newTestUseCases(t, withVerifier(signer))
+
+	memberID := uuid.New()
+	token, _, err := signer.Issue(memberID, "john@example.com", time.Now())
+	require.NoError(t, err)
+
+	t.Run("unverified email is verified", func(t *testing.T) {
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).
+			Return(entities.EmailVerification{MemberID: memberID, Email: "john@example.com"}, nil)
+		mockRepo.EXPECT().MarkEmailVerified(gomock.Any(), memberID, "john@example.com").Return(nil)
+
+		fieldsMap, err := useCases.VerifyEmail(createTestGinContext(), token)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("verified email is left untouched", func(t *testing.T) {
+		verifiedAt := time.Now()
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).
+			Return(entities.EmailVerification{MemberID: memberID, Email: "john@example.com", VerifiedAt: &verifiedAt}, nil)
+
+		fieldsMap, err := useCases.VerifyEmail(createTestGinContext(), token)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("token of a previous email is rejected", func(t *testing.T) {
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).
+			Return(entities.EmailVerification{MemberID: memberID, Email: "john.doe@example.com"}, nil)
+
+		fieldsMap, err := useCases.VerifyEmail(createTestGinContext(), token)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Token])
+	})
+
+	t.Run("expired and forged tokens are rejected", func(t *testing.T) {
+		expired, _, err := verification.NewSigner("secret", -time.Minute).Issue(memberID, "john@example.com", time.Now())
+		require.NoError(t, err)
+		fieldsMap, err := useCases.VerifyEmail(createTestGinContext(), expired)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Expired}, fieldsMap[consts.Token])
+
+		forged, _, err := verification.NewSigner("other", time.Hour).Issue(memberID, "john@example.com", time.Now())
+		require.NoError(t, err)
+		fieldsMap, err = useCases.VerifyEmail(createTestGinContext(), forged)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Token])
+
+		fieldsMap, err = useCases.VerifyEmail(createTestGinContext(), "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Required}, fieldsMap[consts.Token])
+	})
+}
+
+// TestForeignPartnerMember checks requests scoped to a partner do not reach the members
+// of another partner: the repository only finds members of the partner of the context.
+func TestForeignPartnerMember(t *testing.T) {
+	signer := verification.NewSigner("secret", time.Hour)
+	useCases, mockRepo := newTestUseCases(t, withVerifier(signer))
+
+	ownerID := uuid.New()
+	memberID := uuid.New()
+	// ownedMember reports whether the member is visible to ctx, as the tenant scoped queries do.
+	ownedMember := func(ctx context.Context) bool {
+		partnerID, ok := tenant.PartnerFrom(ctx)
+		return ok && partnerID == ownerID
+	}
+	foreignCtx := func() *gin.Context {
+		ctx := createTestGinContext()
+		tenant.Scope(ctx, uuid.New())
+		return ctx
+	}
+
+	t.Run("profile update of a foreign member is not found", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).DoAndReturn(func(_ uuid.UUID, ctx context.Context) (bool, error) {
+			return ownedMember(ctx), nil
+		})
+
+		fieldsMap, err := useCases.UpdateMember(foreignCtx(), memberID, entities.Member{FirstName: "John"}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Exists}, fieldsMap[consts.MemberID])
+	})
+
+	t.Run("deletion of a foreign member is not found", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (bool, error) {
+			return ownedMember(ctx), nil
+		})
+
+		fieldsMap, err := useCases.DeleteMember(foreignCtx(), memberID.String(), 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.MemberID])
+	})
+
+	t.Run("store changes of a foreign member are not found", func(t *testing.T) {
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (bool, error) {
+			return ownedMember(ctx), nil
+		})
+
+		fieldsMap, err := useCases.AddMemberStores(foreignCtx(), memberID, []string{uuid.NewString()})
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Exists}, fieldsMap[consts.MemberID])
+	})
+
+	t.Run("email verification of a foreign member is rejected", func(t *testing.T) {
+		token, _, err := signer.Issue(memberID, "john@example.com", time.Now())
+		require.NoError(t, err)
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (entities.EmailVerification, error) {
+			if !ownedMember(ctx) {
+				return entities.EmailVerification{}, sql.ErrNoRows
+			}
+			return entities.EmailVerification{MemberID: memberID, Email: "john@example.com"}, nil
+		})
+
+		fieldsMap, err := useCases.VerifyEmail(foreignCtx(), token)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Token])
+	})
+
+	t.Run("member of the partner is found", func(t *testing.T) {
+		ctx := createTestGinContext()
+		tenant.Scope(ctx, ownerID)
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).DoAndReturn(func(ctx context.Context, _ uuid.UUID) (bool, error) {
+			return ownedMember(ctx), nil
+		})
+		mockRepo.EXPECT().IsDeleted(gomock.Any(), memberID).Return(false, nil)
+		mockRepo.EXPECT().DeleteMember(gomock.Any(), memberID, int64(0)).Return(nil)
+
+		fieldsMap, err := useCases.DeleteMember(ctx, memberID.String(), 0)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+}
+
+func TestResendEmailVerification(t *testing.T) {
+	memberNotifier := notifier.NewMemoryNotifier()
+	signer := verification.NewSigner("secret", time.Hour)
+	useCases, mockRepo := newTestUseCases(t, withNotifier(memberNotifier), withVerifier(signer))
+
+	partnerID := uuid.NewString()
+	memberID := uuid.New()
+	verifiedAt := time.Now()
+
+	mockRepo.EXPECT().GetEmailVerificationByEmail(gomock.Any(), partnerID, "john@example.com").
+		Return(entities.EmailVerification{MemberID: memberID, Name: "John", Email: "john@example.com"}, nil)
+	mockRepo.EXPECT().GetEmailVerificationByEmail(gomock.Any(), partnerID, "jane@example.com").
+		Return(entities.EmailVerification{}, sql.ErrNoRows)
+	mockRepo.EXPECT().GetEmailVerificationByEmail(gomock.Any(), partnerID, "joe@example.com").
+		Return(entities.EmailVerification{Email: "joe@example.com", VerifiedAt: &verifiedAt}, nil)
+
+	for _, email := range []string{"john@example.com", "jane@example.com", "joe@example.com"} {
+		fieldsMap, err := useCases.ResendEmailVerification(createTestGinContext(), partnerID, email)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	}
+
+	// Only the unverified member is sent a token, and it verifies
+	messages := memberNotifier.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "john@example.com", messages[0].To)
+	token := messages[0].Body[strings.Index(messages[0].Body, "<strong>")+len("<strong>") : strings.Index(messages[0].Body, "</strong>")]
+	claims, err := signer.Verify(token, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, memberID, claims.MemberID)
+
+	fieldsMap, err := useCases.ResendEmailVerification(createTestGinContext(), "partner", "john")
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Format}, fieldsMap[consts.Email])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.PartnerID])
+}
+
+func TestEmailVerificationBlocksCheckout(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID := uuid.New()
+	partnerID := uuid.NewString()
+	checkoutData := entities.CheckoutSubscription{SubscriptionID: uuid.NewString(), PaymentGatewayID: 1}
+
+	mockRepo.EXPECT().CheckSubscriptionExistenceAndStatusForCheckout(gomock.Any(), checkoutData.SubscriptionID).Return(true, true, nil)
+	mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+	mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{
+		MemberID: memberID,
+		Policy:   entities.EmailVerificationPolicy{BlockCheckout: true},
+	}, nil)
+
+	fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotVerified}, fieldsMap[consts.Email])
+}
+
+func TestLoginLockout(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	partnerID := uuid.NewString()
+	memberID := uuid.New()
+	args := entities.MemberPayload{Email: "john@example.com", Provider: consts.ProviderInternal, Password: "Secret@123"}
+	expectLogin := func(retryAt time.Time, blocked bool) {
+		mockRepo.EXPECT().CheckEmailProviderRelation(gomock.Any(), args.Email, args.Provider).Return(true, nil)
+		mockRepo.EXPECT().GetMemberIDByEmail(gomock.Any(), partnerID, args.Email).Return(memberID, nil)
+		mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(retryAt, blocked, nil)
+	}
+
+	t.Run("locked out member is refused", func(t *testing.T) {
+		expectLogin(time.Now().Add(10*time.Minute), true)
+
+		ctx := createTestGinContext()
+		fieldsMap, _, err := useCases.GetBasicMemberDetailsByEmail(ctx, partnerID, args, nil, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Locked}, fieldsMap[consts.Email])
+		assert.Equal(t, "600", ctx.Writer.Header().Get("Retry-After"))
+	})
+
+	t.Run("attempt within the delay of the client IP is refused", func(t *testing.T) {
+		expectLogin(time.Now().Add(2*time.Second), true)
+
+		ctx := createTestGinContext()
+		fieldsMap, _, err := useCases.GetBasicMemberDetailsByEmail(ctx, partnerID, args, nil, "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Locked}, fieldsMap[consts.Email])
+		assert.Equal(t, "2", ctx.Writer.Header().Get("Retry-After"))
+	})
+
+	t.Run("wrong password is recorded", func(t *testing.T) {
+		expectLogin(time.Time{}, false)
+		mockRepo.EXPECT().GetBasicMemberDetailsByEmail(partnerID, args, gomock.Any()).Return(entities.BasicMemberData{}, nil)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), memberID, gomock.Any()).Return(nil)
+
+		fieldsMap, basicData, err := useCases.GetBasicMemberDetailsByEmail(createTestGinContext(), partnerID, args, nil, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, uuid.Nil, basicData.MemberID)
+	})
+
+	t.Run("failed lookup gives the attempt back", func(t *testing.T) {
+		expectLogin(time.Time{}, false)
+		mockRepo.EXPECT().GetBasicMemberDetailsByEmail(partnerID, args, gomock.Any()).Return(entities.BasicMemberData{}, errors.New("connection reset"))
+		mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(nil)
+
+		_, _, err := useCases.GetBasicMemberDetailsByEmail(createTestGinContext(), partnerID, args, nil, "", "")
+		require.Error(t, err)
+	})
+
+	t.Run("password shorter than the policy is checked against the stored hash", func(t *testing.T) {
+		short := entities.MemberPayload{Email: args.Email, Provider: consts.ProviderInternal, Password: "abc"}
+		mockRepo.EXPECT().CheckEmailProviderRelation(gomock.Any(), short.Email, short.Provider).Return(true, nil)
+		mockRepo.EXPECT().GetMemberIDByEmail(gomock.Any(), partnerID, short.Email).Return(memberID, nil)
+		mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(time.Time{}, false, nil)
+		mockRepo.EXPECT().GetBasicMemberDetailsByEmail(partnerID, short, gomock.Any()).Return(entities.BasicMemberData{}, nil)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), memberID, gomock.Any()).Return(nil)
+
+		fieldsMap, _, err := useCases.GetBasicMemberDetailsByEmail(createTestGinContext(), partnerID, short, nil, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("successful login clears the failures", func(t *testing.T) {
+		expectLogin(time.Time{}, false)
+		mockRepo.EXPECT().GetBasicMemberDetailsByEmail(partnerID, args, gomock.Any()).Return(entities.BasicMemberData{MemberID: memberID}, nil)
+		mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().ClearLoginLockout(gomock.Any(), memberID).Return(nil)
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(entities.TwoFactor{MemberID: memberID}, nil)
+
+		fieldsMap, basicData, err := useCases.GetBasicMemberDetailsByEmail(createTestGinContext(), partnerID, args, nil, "", "")
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, memberID, basicData.MemberID)
+	})
+}
+
+func TestUnlockMember(t *testing.T) {
+	activities := activity.NewMemoryRecorder()
+	useCases, mockRepo := newTestUseCases(t, withActivities(activities))
+
+	memberID := uuid.New()
+	mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().ClearLoginLockout(gomock.Any(), memberID).Return(nil)
+
+	fieldsMap, err := useCases.UnlockMember(createTestGinContext(), memberID)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	require.Len(t, activities.Activities(), 1)
+	assert.Equal(t, consts.ActivityMemberUnlocked, activities.Activities()[0].Action)
+
+	unknown := uuid.New()
+	mockRepo.EXPECT().IsMemberExists(unknown, gomock.Any()).Return(false, nil)
+	fieldsMap, err = useCases.UnlockMember(createTestGinContext(), unknown)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.MemberID])
+}
+
+func TestTwoFactorEnrollment(t *testing.T) {
+	activities := activity.NewMemoryRecorder()
+	useCases, mockRepo := newTestUseCases(t, withActivities(activities))
+
+	memberID := uuid.New()
+	state := entities.TwoFactor{MemberID: memberID, Email: "john@example.com"}
+
+	// Enrolling stores a new pending secret
+	mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+	mockRepo.EXPECT().SaveTwoFactorSecret(gomock.Any(), memberID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, secret string) error {
+			state.Secret = secret
+			return nil
+		})
+	fieldsMap, enrollment, err := useCases.EnrollTwoFactor(createTestGinContext(), memberID)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.Equal(t, state.Secret, enrollment.Secret)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Tuneverse:john@example.com?"))
+	assert.Contains(t, enrollment.URI, "secret="+state.Secret)
+
+	// A wrong first code keeps the enrollment pending
+	mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+	fieldsMap, _, err = useCases.EnableTwoFactor(createTestGinContext(), memberID, "000000x")
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Code])
+
+	// The first valid code enables it and returns the recovery codes, only their hashes are stored
+	step := totp.Step(time.Now())
+	code, err := totp.Code(state.Secret, step)
+	require.NoError(t, err)
+	var storedHashes []string
+	mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+	mockRepo.EXPECT().EnableTwoFactor(gomock.Any(), memberID, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ uuid.UUID, usedStep int64, hashes []string) (bool, error) {
+			assert.InDelta(t, step, usedStep, 1)
+			storedHashes = hashes
+			return true, nil
+		})
+	fieldsMap, recoveryCodes, err := useCases.EnableTwoFactor(createTestGinContext(), memberID, code)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	require.Len(t, recoveryCodes.RecoveryCodes, totp.RecoveryCodeCount)
+	for i, recoveryCode := range recoveryCodes.RecoveryCodes {
+		assert.Equal(t, totp.HashRecoveryCode(recoveryCode), storedHashes[i])
+	}
+	require.Len(t, activities.Activities(), 1)
+	assert.Equal(t, consts.ActivityTwoFactorEnabled, activities.Activities()[0].Action)
+
+	// An enabled two-factor authentication cannot be enrolled again
+	enabledAt := time.Now()
+	state.EnabledAt = &enabledAt
+	mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+	fieldsMap, _, err = useCases.EnrollTwoFactor(createTestGinContext(), memberID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Enabled}, fieldsMap[consts.TwoFactor])
+}
+
+func TestTwoFactorLoginChallenge(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	partnerID := uuid.New()
+	memberID := uuid.New()
+	enabledAt := time.Now().Add(-time.Hour)
+	state := entities.TwoFactor{MemberID: memberID, Email: "john@example.com", Secret: secret, EnabledAt: &enabledAt}
+	basicData := entities.BasicMemberData{MemberID: memberID, Email: state.Email, PartnerID: partnerID, MemberRoles: []string{"member"}}
+	args := entities.MemberPayload{Email: state.Email, Provider: consts.ProviderInternal, Password: "Secret@123"}
+
+	// The password login returns a challenge instead of the member details
+	mockRepo.EXPECT().CheckEmailProviderRelation(gomock.Any(), args.Email, args.Provider).Return(true, nil)
+	mockRepo.EXPECT().GetMemberIDByEmail(gomock.Any(), partnerID.String(), args.Email).Return(memberID, nil)
+	mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(time.Time{}, false, nil)
+	mockRepo.EXPECT().GetBasicMemberDetailsByEmail(partnerID.String(), args, gomock.Any()).Return(basicData, nil)
+	mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+	mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+
+	fieldsMap, challenge, err := useCases.GetBasicMemberDetailsByEmail(createTestGinContext(), partnerID.String(), args, nil, "", "")
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.NotEmpty(t, challenge.ChallengeToken)
+	assert.Equal(t, uuid.Nil, challenge.MemberID)
+	assert.Empty(t, challenge.MemberRoles)
+
+	expectChallenge := func() {
+		mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(time.Time{}, false, nil)
+		mockRepo.EXPECT().GetTwoFactor(gomock.Any(), memberID).Return(state, nil)
+	}
+	expectLogin := func() {
+		mockRepo.EXPECT().GetBasicMemberDetailsByID(gomock.Any(), memberID).Return(basicData, nil)
+		mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), memberID, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().ClearLoginLockout(gomock.Any(), memberID).Return(nil)
+	}
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	t.Run("wrong code is a failed login", func(t *testing.T) {
+		// A code outside the accepted skew
+		wrong, err := totp.Code(secret, totp.Step(time.Now())-5)
+		require.NoError(t, err)
+		expectChallenge()
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), memberID, gomock.Any()).Return(nil)
+
+		fieldsMap, data, err := useCases.CompleteTwoFactorChallenge(createTestGinContext(), partnerID.String(),
+			entities.TwoFactorChallenge{ChallengeToken: challenge.ChallengeToken, Code: wrong})
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Code])
+		assert.Equal(t, uuid.Nil, data.MemberID)
+	})
+
+	t.Run("valid code completes the login", func(t *testing.T) {
+		expectChallenge()
+		mockRepo.EXPECT().UseTwoFactorStep(gomock.Any(), memberID, gomock.Any()).Return(true, nil)
+		expectLogin()
+
+		fieldsMap, data, err := useCases.CompleteTwoFactorChallenge(createTestGinContext(), partnerID.String(),
+			entities.TwoFactorChallenge{ChallengeToken: challenge.ChallengeToken, Code: code})
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, basicData, data)
+	})
+
+	t.Run("replayed code is refused", func(t *testing.T) {
+		expectChallenge()
+		mockRepo.EXPECT().UseTwoFactorStep(gomock.Any(), memberID, gomock.Any()).Return(false, nil)
+		mockRepo.EXPECT().RecordLoginFailure(gomock.Any(), memberID, gomock.Any()).Return(nil)
+
+		fieldsMap, _, err := useCases.CompleteTwoFactorChallenge(createTestGinContext(), partnerID.String(),
+			entities.TwoFactorChallenge{ChallengeToken: challenge.ChallengeToken, Code: code})
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Code])
+	})
+
+	t.Run("recovery code completes the login", func(t *testing.T) {
+		expectChallenge()
+		mockRepo.EXPECT().UseRecoveryCode(gomock.Any(), memberID, totp.HashRecoveryCode("abcde-fghij")).Return(true, nil)
+		expectLogin()
+
+		fieldsMap, data, err := useCases.CompleteTwoFactorChallenge(createTestGinContext(), partnerID.String(),
+			entities.TwoFactorChallenge{ChallengeToken: challenge.ChallengeToken, Code: "ABCDE-FGHIJ"})
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+		assert.Equal(t, memberID, data.MemberID)
+	})
+
+	t.Run("foreign challenge token is refused", func(t *testing.T) {
+		token, _, err := verification.NewSigner("secret", time.Hour).Issue(memberID, state.Email, time.Now())
+		require.NoError(t, err)
+
+		fieldsMap, _, err := useCases.CompleteTwoFactorChallenge(createTestGinContext(), partnerID.String(),
+			entities.TwoFactorChallenge{ChallengeToken: token, Code: code})
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.ChallengeToken])
+	})
+}
+
+func TestBillingAddressNormalization(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	ginCtx := createTestGinContext()
+	memberID, memberBillingID := uuid.New(), uuid.New()
+	normalized := entities.BillingAddress{Address: "10 Downing Street", Zipcode: "SW1A 2AA", Country: "GB"}
+
+	// A trivially different copy of an existing address is caught as a duplicate
+	mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil).Times(2)
+	mockRepo.EXPECT().GetBillingAddressCountForMember(gomock.Any(), memberID).Return(1, nil)
+	mockRepo.EXPECT().CountryExists("GB").Return(true, nil).Times(2)
+	mockRepo.EXPECT().BillingAddressExists(gomock.Any(), memberID, normalized, uuid.Nil).Return(true, nil)
+	fieldsMap, err := useCases.AddBillingAddress(ginCtx, memberID, entities.BillingAddress{
+		Address: " 10  Downing Street", Zipcode: "sw1a2aa", Country: "gb",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.AlreadyExists}, fieldsMap[consts.BillingAddress])
+
+	// Updates are validated against the rules of the resulting country
+	mockRepo.EXPECT().CheckBillingAddressRelation(gomock.Any(), memberID, memberBillingID).Return(true, nil).Times(2)
+	mockRepo.EXPECT().GetBillingAddressByID(gomock.Any(), memberBillingID).Return(&entities.BillingAddress{
+		Address: "1 Main St", Zipcode: "94105", Country: "US", State: "CA",
+	}, nil).Times(2)
+	mockRepo.EXPECT().CountTotalAddressesForMember(gomock.Any(), memberID).Return(2, nil).Times(2)
+	mockRepo.EXPECT().HasPrimaryBilling(gomock.Any(), memberID).Return(true, nil).Times(2)
+	mockRepo.EXPECT().StateExists("CA", "GB").Return(false, nil)
+	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, entities.BillingAddress{Country: "gb"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Zipcode])
+	assert.Equal(t, []string{consts.StateNotExist}, fieldsMap[consts.State])
+
+	mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil)
+	updated := entities.BillingAddress{Address: "1 Main St", Zipcode: "94105-1804", Country: "US", State: "CA"}
+	mockRepo.EXPECT().BillingAddressExists(gomock.Any(), memberID, updated, memberBillingID).Return(false, nil)
+	mockRepo.EXPECT().UpdateBillingAddress(gomock.Any(), memberID, memberBillingID, updated, int64(0)).Return(int64(1), nil)
+	fieldsMap, err = useCases.UpdateBillingAddress(ginCtx, memberID, memberBillingID, entities.BillingAddress{Zipcode: " 94105-1804 "}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+}
+
+func TestOptimisticConcurrency(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID, memberBillingID := uuid.New(), uuid.New()
+	current := entities.MemberByID{FirstName: sql.NullString{String: "Jon", Valid: true}, Version: 3}
+	args := entities.Member{FirstName: "John"}
+
+	// An update made against an outdated version is refused before anything is written
+	mockRepo.EXPECT().IsMemberExists(memberID, gomock.Any()).Return(true, nil).Times(2)
+	mockRepo.EXPECT().GetMemberByID(gomock.Any(), memberID).Return(current, nil).Times(2)
+	_, err := useCases.UpdateMember(createTestGinContext(), memberID, args, 2)
+	assert.ErrorIs(t, err, consts.ErrVersionMismatch)
+
+	// The update is made against the version read and returns the new version as the ETag
+	ginCtx := createTestGinContext()
+	mockRepo.EXPECT().UpdateMember(gomock.Any(), memberID, args, int64(3)).Return(int64(4), nil)
+	fieldsMap, err := useCases.UpdateMember(ginCtx, memberID, args, 3)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.Equal(t, etag.Format(4), ginCtx.Writer.Header().Get(consts.ETag))
+
+	// A deletion racing with an update fails the same way
+	mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil)
+	mockRepo.EXPECT().CheckBillingAddressRelation(gomock.Any(), memberID, memberBillingID).Return(true, nil)
+	mockRepo.EXPECT().DeleteBillingAddress(gomock.Any(), memberID, memberBillingID, int64(5)).Return(consts.ErrVersionMismatch)
+	_, err = useCases.DeleteBillingAddress(createTestGinContext(), memberID, memberBillingID, 5)
+	assert.ErrorIs(t, err, consts.ErrVersionMismatch)
+}
+
+// TestListMemberAudit checks the audit trail is listed page by page and the action filter is validated.
+func TestListMemberAudit(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID := uuid.New()
+	entries := []entities.AuditEntry{{
+		ID:       uuid.New(),
+		MemberID: memberID,
+		ActorID:  uuid.NewString(),
+		Action:   consts.AuditMemberUpdated,
+		Changes:  []entities.AuditChange{{Field: "firstname", Old: "Jon", New: "John"}},
+	}}
+
+	mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil).Times(2)
+	mockRepo.EXPECT().GetMemberAuditCount(gomock.Any(), memberID, consts.AuditMemberUpdated).Return(int64(1), nil)
+	mockRepo.EXPECT().GetMemberAudit(gomock.Any(), memberID, consts.AuditMemberUpdated, int32(1), int32(consts.LimitDefault)).Return(entries, nil)
+
+	got, metadata, validationErrors, err := useCases.ListMemberAudit(createTestGinContext(), memberID, entities.AuditParams{Action: " Member_Updated "})
+	require.NoError(t, err)
+	assert.Empty(t, validationErrors)
+	assert.Equal(t, entries, got)
+	assert.Equal(t, int64(1), metadata.Total)
+
+	_, _, validationErrors, err = useCases.ListMemberAudit(createTestGinContext(), memberID, entities.AuditParams{Action: "login"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, validationErrors[consts.Action])
+}
+
+// TestMemberStores checks stores of a member are updated, removed and reordered, activating a
+// store the partner no longer offers is refused and orders have to list every store once.
+func TestMemberStores(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID, partnerID := uuid.New(), uuid.New()
+	spotify, deezer := uuid.New(), uuid.New()
+	mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil).AnyTimes()
+	mockRepo.EXPECT().GetPartnerIDByMemberID(gomock.Any(), memberID).Return(partnerID, nil).AnyTimes()
+
+	fieldsMap, err := useCases.UpdateMemberStore(createTestGinContext(), memberID, "spotify", entities.MemberStoreUpdate{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.StoreID])
+
+	active := true
+	mockRepo.EXPECT().StorePartnerRelation(gomock.Any(), partnerID, []uuid.UUID{spotify}).Return(map[string]bool{spotify.String(): false}, nil)
+	fieldsMap, err = useCases.UpdateMemberStore(createTestGinContext(), memberID, spotify.String(), entities.MemberStoreUpdate{IsActive: &active})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NoRelation}, fieldsMap[consts.StoreID])
+
+	name := "  My Spotify "
+	trimmed := "My Spotify"
+	mockRepo.EXPECT().UpdateMemberStore(gomock.Any(), memberID, spotify, entities.MemberStoreUpdate{CustomName: &trimmed}).Return(nil)
+	fieldsMap, err = useCases.UpdateMemberStore(createTestGinContext(), memberID, spotify.String(), entities.MemberStoreUpdate{CustomName: &name})
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+
+	mockRepo.EXPECT().RemoveMemberStores(gomock.Any(), memberID, []uuid.UUID{deezer}).Return(int64(0), nil)
+	fieldsMap, err = useCases.RemoveMemberStore(createTestGinContext(), memberID, deezer.String())
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.StoreID])
+
+	stores := []entities.MemberStore{{StoreID: spotify, Position: 1}, {StoreID: deezer, Position: 2}}
+	mockRepo.EXPECT().GetMemberStores(gomock.Any(), memberID).Return(stores, nil).Times(3)
+	for _, order := range [][]uuid.UUID{{deezer}, {deezer, deezer}} {
+		fieldsMap, err = useCases.ReorderMemberStores(createTestGinContext(), memberID, entities.MemberStoreOrder{Stores: order})
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Stores], order)
+	}
+	mockRepo.EXPECT().ReorderMemberStores(gomock.Any(), memberID, []uuid.UUID{deezer, spotify}).Return(nil)
+	fieldsMap, err = useCases.ReorderMemberStores(createTestGinContext(), memberID, entities.MemberStoreOrder{Stores: []uuid.UUID{deezer, spotify}})
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+}
+
+// TestBulkMemberStores checks stores are resolved once per partner and every member gets its own outcome.
+func TestBulkMemberStores(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	_, fieldsMap, err := useCases.BulkMemberStores(createTestGinContext(), entities.BulkMemberStores{Action: "move"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Action])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.MemberIDs])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.Stores])
+
+	partnerID, otherPartnerID := uuid.New(), uuid.New()
+	first, second, foreign, missing := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	spotify := uuid.New()
+	for memberID, partner := range map[uuid.UUID]uuid.UUID{first: partnerID, second: partnerID, foreign: otherPartnerID} {
+		mockRepo.EXPECT().IsMemberExist(gomock.Any(), memberID).Return(true, nil)
+		mockRepo.EXPECT().GetPartnerIDByMemberID(gomock.Any(), memberID).Return(partner, nil)
+	}
+	mockRepo.EXPECT().IsMemberExist(gomock.Any(), missing).Return(false, nil)
+	mockRepo.EXPECT().CheckStoreNameExistsAndReturnIDs(gomock.Any(), []string{"Spotify"}).Return(true, []uuid.UUID{spotify}, nil).Times(2)
+	mockRepo.EXPECT().StorePartnerRelation(gomock.Any(), partnerID, []uuid.UUID{spotify}).Return(map[string]bool{spotify.String(): true}, nil)
+	mockRepo.EXPECT().StorePartnerRelation(gomock.Any(), otherPartnerID, []uuid.UUID{spotify}).Return(map[string]bool{spotify.String(): false}, nil)
+	mockRepo.EXPECT().CheckNonExistingMemberStores(gomock.Any(), first, []uuid.UUID{spotify}).Return([]uuid.UUID{spotify}, nil)
+	mockRepo.EXPECT().AddMemberStoresById(gomock.Any(), first, []uuid.UUID{spotify}).Return(nil)
+	mockRepo.EXPECT().CheckNonExistingMemberStores(gomock.Any(), second, []uuid.UUID{spotify}).Return([]uuid.UUID{}, nil)
+
+	results, fieldsMap, err := useCases.BulkMemberStores(createTestGinContext(), entities.BulkMemberStores{
+		Action:    " Apply ",
+		MemberIDs: []string{first.String(), second.String(), foreign.String(), missing.String(), "x"},
+		Stores:    []string{"Spotify"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	require.Len(t, results, 5)
+	assert.Equal(t, consts.BulkStoresApplied, results[0].Status)
+	assert.Equal(t, consts.BulkStoresUnchanged, results[1].Status)
+	assert.Equal(t, consts.BulkStoresFailed, results[2].Status)
+	assert.Equal(t, []string{consts.NoRelation}, results[2].Errors[consts.Name])
+	assert.Equal(t, []string{consts.NotFound}, results[3].Errors[consts.MemberID])
+	assert.Equal(t, []string{consts.Invalid}, results[4].Errors[consts.MemberID])
+}
+
+// TestSubscriptionQuota checks quota requests are validated and reservation failures surface as field errors.
+func TestSubscriptionQuota(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID, subscriptionID := uuid.New(), uuid.New().String()
+
+	_, fieldsMap, err := useCases.CheckQuota(createTestGinContext(), memberID, subscriptionID, entities.QuotaRequest{Resource: "albums", Quantity: 0, TTLSeconds: -1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Resource])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Quantity])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.TTLSeconds])
+
+	subscriptionQuota := entities.SubscriptionQuota{
+		MemberSubscriptionID: subscriptionID,
+		Status:               consts.SubscriptionStatusActive,
+		Usage:                []entities.QuotaUsage{{Resource: consts.QuotaTracks, Limit: 10, Used: 6, Reserved: 2, Available: 2}},
+	}
+	mockRepo.EXPECT().GetSubscriptionQuota(gomock.Any(), memberID, subscriptionID).Return(subscriptionQuota, nil).Times(2)
+	check, fieldsMap, err := useCases.CheckQuota(createTestGinContext(), memberID, subscriptionID, entities.QuotaRequest{Resource: " Tracks ", Quantity: 2})
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.Equal(t, entities.QuotaCheck{Resource: consts.QuotaTracks, Quantity: 2, Available: 2, Allowed: true}, check)
+	check, _, err = useCases.CheckQuota(createTestGinContext(), memberID, subscriptionID, entities.QuotaRequest{Resource: consts.QuotaTracks, Quantity: 3})
+	require.NoError(t, err)
+	assert.False(t, check.Allowed)
+
+	reservation := entities.QuotaReservation{MemberID: memberID, MemberSubscriptionID: subscriptionID, Resource: consts.QuotaTracks, Quantity: 3}
+	ttl := time.Duration(consts.DefaultQuotaReservationSeconds) * time.Second
+	mockRepo.EXPECT().ReserveQuota(gomock.Any(), reservation, ttl).Return(entities.QuotaReservation{}, consts.ErrQuotaExceeded)
+	_, fieldsMap, err = useCases.ReserveQuota(createTestGinContext(), memberID, subscriptionID, entities.QuotaRequest{Resource: consts.QuotaTracks, Quantity: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.LimitExceeds}, fieldsMap[consts.Quantity])
+
+	mockRepo.EXPECT().ReserveQuota(gomock.Any(), reservation, ttl).Return(entities.QuotaReservation{}, consts.ErrSubscriptionClosed)
+	_, fieldsMap, err = useCases.ReserveQuota(createTestGinContext(), memberID, subscriptionID, entities.QuotaRequest{Resource: consts.QuotaTracks, Quantity: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Closed}, fieldsMap[consts.MemberSubscriptionID])
+
+	reservationID := uuid.New()
+	mockRepo.EXPECT().FinishQuotaReservation(gomock.Any(), memberID, subscriptionID, reservationID, consts.QuotaReleased).Return(sql.ErrNoRows)
+	fieldsMap, err = useCases.ReleaseQuotaReservation(createTestGinContext(), memberID, subscriptionID, reservationID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.ReservationID])
+
+	mockRepo.EXPECT().FinishQuotaReservation(gomock.Any(), memberID, subscriptionID, reservationID, consts.QuotaCommitted).Return(nil)
+	fieldsMap, err = useCases.CommitQuotaReservation(createTestGinContext(), memberID, subscriptionID, reservationID.String())
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+}
+
+// TestSubscriptionPlans checks plan terms are validated and versions of retired plans are refused.
+func TestSubscriptionPlans(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	_, fieldsMap, err := useCases.CreateSubscriptionPlan(createTestGinContext(), entities.SubscriptionPlanRequest{Name: " ", CanRenewableWithin: -1, TrackCount: -1})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.Name])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.SKU])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.Prices])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.CanRenewableWithin])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.TrackCount])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.SubscriptionDurationID])
+
+	request := entities.SubscriptionPlanRequest{
+		Name:                   " Pro ",
+		SKU:                    "PRO-1Y",
+		SubscriptionDurationID: 2,
+		IsFreeSubscription:     true,
+		CanRenewableWithin:     4,
+		ProductCount:           10,
+		Prices:                 []entities.PlanPrice{{CurrencyID: 1, Amount: 9.99}},
+	}
+	mockRepo.EXPECT().SubscriptionDurationExists(gomock.Any(), 2).Return(false, nil)
+	_, fieldsMap, err = useCases.CreateSubscriptionPlan(createTestGinContext(), request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Prices])
+	assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.SubscriptionDurationID])
+
+	request.IsFreeSubscription = false
+	request.Prices = append(request.Prices, entities.PlanPrice{CurrencyID: 2, Amount: 8.99, TaxPercentage: 20})
+	trimmed := request
+	trimmed.Name = "Pro"
+	planID := uuid.New()
+	version := entities.SubscriptionPlan{ID: uuid.New(), PlanID: planID, Version: 1, SubscriptionPlanRequest: trimmed, IsActive: true}
+	mockRepo.EXPECT().SubscriptionDurationExists(gomock.Any(), 2).Return(true, nil).Times(2)
+	mockRepo.EXPECT().CreateSubscriptionPlan(gomock.Any(), trimmed).Return(entities.SubscriptionPlan{ID: version.ID, PlanID: planID, Version: 1}, nil)
+	mockRepo.EXPECT().GetSubscriptionPlanVersions(gomock.Any(), planID).Return([]entities.SubscriptionPlan{version}, nil)
+	plan, fieldsMap, err := useCases.CreateSubscriptionPlan(createTestGinContext(), request)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.Equal(t, version, plan)
+
+	mockRepo.EXPECT().AddSubscriptionPlanVersion(gomock.Any(), planID, trimmed).Return(entities.SubscriptionPlan{}, consts.ErrPlanRetired)
+	_, fieldsMap, err = useCases.AddSubscriptionPlanVersion(createTestGinContext(), planID.String(), request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Retired}, fieldsMap[consts.PlanID])
+
+	mockRepo.EXPECT().RetireSubscriptionPlan(gomock.Any(), planID).Return(sql.ErrNoRows)
+	fieldsMap, err = useCases.RetireSubscriptionPlan(createTestGinContext(), planID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.NotFound}, fieldsMap[consts.PlanID])
+
+	// Subscriptions keep the version they bought after it is superseded: they still renew and cancel.
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	memberSubscriptionID := uuid.New().String()
+	superseded := entities.SubscriptionPlanTerms{SubscriptionID: version.ID.String(), PlanID: planID, Name: "Pro", Amount: 10, Currency: "USD", IsActive: false}
+
+	t.Run("renew a superseded version", func(t *testing.T) {
+		renewal := entities.SubscriptionRenewal{MemberSubscriptionID: memberSubscriptionID, PaymentGatewayID: 1}
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().CheckMemberSubscriptionExists(gomock.Any(), memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().IsMemberSubscribedToPlan(gomock.Any(), memberID, memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().IsSubscriptionAboutInWarning(gomock.Any(), memberSubscriptionID).Return(false, "", nil)
+		mockRepo.EXPECT().IsSubscriptionInGracePeriod(gomock.Any(), memberID, memberSubscriptionID).
+			Return(false, time.Time{}, time.Now().AddDate(0, 1, 0), time.Duration(0), false, nil)
+		mockRepo.EXPECT().GetSubscriptionStatusName(gomock.Any(), memberSubscriptionID).Return(consts.SubscriptionStatusActive, nil)
+		mockRepo.EXPECT().IsMemberSubscribedToFreePlan(gomock.Any(), memberID, memberSubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().CheckIfPayoutGatewayExists(gomock.Any(), 1).Return(true, nil)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionIDByMemberSubscriptionID(gomock.Any(), memberSubscriptionID).Return(version.ID, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), version.ID.String(), "USD").Return(superseded, nil)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{Name: "John Doe"}, nil)
+		mockRepo.EXPECT().GetSubscriptionBalance(gomock.Any(), memberSubscriptionID).Return(0.0, nil)
+		mockRepo.EXPECT().RecordRenewalPayment(gomock.Any(), gomock.Any(), gomock.Any()).Return(uuid.New(), nil)
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).Return(entities.Invoice{}, nil)
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		fieldsMap, err := useCases.HandleSubscriptionRenewal(createTestGinContext(), memberID, renewal, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("cancel a superseded version", func(t *testing.T) {
+		cancellation := entities.CancelSubscription{MemberSubscriptionID: memberSubscriptionID}
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().CheckMemberSubscriptionExists(gomock.Any(), memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().IsMemberSubscribedToPlan(gomock.Any(), memberID, memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().HasProductsReleaseEndDateGreaterThanToday(gomock.Any(), memberSubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().IsMemberRelatedToSubscription(gomock.Any(), memberID, memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().CheckCancellationEnabled(gomock.Any(), memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().GetSubscriptionStatusName(gomock.Any(), memberSubscriptionID).Return(consts.SubscriptionStatusActive, nil)
+		mockRepo.EXPECT().GetLatestCapturedPayment(gomock.Any(), memberSubscriptionID).Return(entities.SubscriptionPayment{}, sql.ErrNoRows)
+		mockRepo.EXPECT().HandleSubscriptionCancellation(gomock.Any(), memberID, cancellation).Return(nil)
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		fieldsMap, err := useCases.HandleSubscriptionCancellation(createTestGinContext(), memberID, cancellation, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+}
+
+// TestHandleSubscriptionCancellationRefund checks a cancelled subscription is refunded the unused share of its
+// last captured payment and the refund is recorded with its ledger entry.
+func TestHandleSubscriptionCancellationRefund(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	memberSubscriptionID := uuid.New().String()
+	subscriptionID := uuid.New().String()
+	cancellation := entities.CancelSubscription{MemberSubscriptionID: memberSubscriptionID}
+	captured := entities.SubscriptionPayment{
+		ID:                   uuid.New(),
+		MemberSubscriptionID: memberSubscriptionID,
+		MemberID:             memberID,
+		PaymentGatewayID:     1,
+		GatewayPaymentID:     "fake_pay_1",
+		Kind:                 consts.PaymentKindCheckout,
+		Status:               consts.PaymentStatusCaptured,
+		Currency:             "USD",
+	}
+
+	// expectCancellation expects the cancellation of a 52 week subscription paid amount and expiring in days
+	expectCancellation := func(amount float64, days int) {
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().CheckMemberSubscriptionExists(gomock.Any(), memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().IsMemberSubscribedToPlan(gomock.Any(), memberID, memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().HasProductsReleaseEndDateGreaterThanToday(gomock.Any(), memberSubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().IsMemberRelatedToSubscription(gomock.Any(), memberID, memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().CheckCancellationEnabled(gomock.Any(), memberSubscriptionID).Return(true, nil)
+		mockRepo.EXPECT().GetSubscriptionStatusName(gomock.Any(), memberSubscriptionID).Return(consts.SubscriptionStatusActive, nil)
+		mockRepo.EXPECT().HandleSubscriptionCancellation(gomock.Any(), memberID, cancellation).Return(nil)
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		paid := captured
+		paid.Amount = amount
+		mockRepo.EXPECT().GetLatestCapturedPayment(gomock.Any(), memberSubscriptionID).Return(paid, nil)
+		mockRepo.EXPECT().GetMemberSubscriptionState(gomock.Any(), memberID, memberSubscriptionID).Return(entities.MemberSubscriptionState{
+			MemberSubscriptionID: memberSubscriptionID,
+			SubscriptionID:       subscriptionID,
+			Status:               consts.Cancelled,
+			ExpirationDate:       time.Now().AddDate(0, 0, days),
+		}, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), subscriptionID, "USD").Return(entities.SubscriptionPlanTerms{
+			SubscriptionID: subscriptionID,
+			Amount:         30,
+			CurrencyID:     1,
+			Currency:       "USD",
+			DurationWeeks:  52,
+		}, nil)
+	}
+	expectGateway := func() {
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+	}
+
+	t.Run("unused share is refunded", func(t *testing.T) {
+		// Half of the 52 weeks are left, half of the 36.40 paid is refunded
+		expectCancellation(36.4, 182)
+		expectGateway()
+		mockRepo.EXPECT().RecordSubscriptionRefund(gomock.Any(), gomock.Any(), captured.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund entities.SubscriptionPayment, _ uuid.UUID, entry entities.SubscriptionLedgerEntry) error {
+				assert.Equal(t, consts.PaymentKindRefund, refund.Kind)
+				assert.Equal(t, consts.PaymentStatusRefunded, refund.Status)
+				assert.Equal(t, 18.2, refund.Amount)
+				assert.Equal(t, consts.LedgerKindRefund, entry.Kind)
+				assert.Equal(t, subscriptionID, entry.FromSubscriptionID)
+				assert.Equal(t, 18.2, entry.Credit)
+				assert.Equal(t, 18.2, entry.Charge)
+				assert.Zero(t, entry.Amount)
+				assert.Equal(t, 1, entry.CurrencyID)
+				return nil
+			})
+
+		fieldsMap, err := useCases.HandleSubscriptionCancellation(createTestGinContext(), memberID, cancellation, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("expired subscription has nothing to refund", func(t *testing.T) {
+		expectCancellation(36.4, -1)
+
+		fieldsMap, err := useCases.HandleSubscriptionCancellation(createTestGinContext(), memberID, cancellation, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	t.Run("declined refund is recorded and reported", func(t *testing.T) {
+		// 10.03 is declined by the fake gateway
+		expectCancellation(20.06, 182)
+		expectGateway()
+		mockRepo.EXPECT().RecordSubscriptionRefund(gomock.Any(), gomock.Any(), captured.ID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, refund entities.SubscriptionPayment, _ uuid.UUID, _ entities.SubscriptionLedgerEntry) error {
+				assert.Equal(t, consts.PaymentStatusFailed, refund.Status)
+				assert.Equal(t, 10.03, refund.Amount)
+				return nil
+			})
+
+		fieldsMap, err := useCases.HandleSubscriptionCancellation(createTestGinContext(), memberID, cancellation, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.RefundFailed}, fieldsMap[consts.SubscriptionID])
+	})
+}
+
+// TestProcessSubscriptionRenewals checks captured renewals renew the subscription and declined ones are
+// retried on the dunning schedule, each attempt recorded and the member notified.
+func TestProcessSubscriptionRenewals(t *testing.T) {
+	memberNotifier := notifier.NewMemoryNotifier()
+	useCases, mockRepo := newTestUseCases(t, withNotifier(memberNotifier))
+
+	day := 24 * time.Hour
+	dunning := []time.Duration{day, 3 * day, 7 * day}
+	candidate := entities.RenewalCandidate{
+		MemberSubscriptionID: uuid.New().String(),
+		SubscriptionID:       uuid.New().String(),
+		MemberID:             uuid.New(),
+		PartnerID:            uuid.New(),
+		CustomName:           "Gold",
+		PaymentGatewayID:     1,
+		ExpirationDate:       time.Now().Add(-2 * day),
+		Failures:             1,
+	}
+	graceEnd := candidate.ExpirationDate.Add(14 * day)
+
+	expectCharge := func(amount float64, balance float64) {
+		mockRepo.EXPECT().ClaimDueRenewals(gomock.Any(), 10, time.Hour).Return([]entities.RenewalCandidate{candidate}, nil)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), candidate.PartnerID.String(), 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), candidate.PartnerID.String(), 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), candidate.SubscriptionID, "USD").Return(entities.SubscriptionPlanTerms{
+			SubscriptionID: candidate.SubscriptionID,
+			Name:           "Gold",
+			Amount:         amount,
+			CurrencyID:     1,
+			Currency:       "USD",
+		}, nil)
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), candidate.MemberID).Return(entities.BillingProfile{Name: "John Doe"}, nil)
+		mockRepo.EXPECT().GetSubscriptionBalance(gomock.Any(), candidate.MemberSubscriptionID).Return(balance, nil)
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), candidate.MemberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+	}
+	expectRecord := func(amount float64, credit float64) {
+		mockRepo.EXPECT().RecordRenewalPayment(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment, entry *entities.SubscriptionLedgerEntry) (uuid.UUID, error) {
+				assert.Equal(t, consts.PaymentKindRenewal, record.Kind)
+				assert.Equal(t, amount, record.Amount)
+				if credit == 0 {
+					assert.Nil(t, entry)
+					return uuid.New(), nil
+				}
+				require.NotNil(t, entry)
+				assert.Equal(t, consts.LedgerKindRenewalCredit, entry.Kind)
+				assert.Equal(t, candidate.MemberSubscriptionID, entry.MemberSubscriptionID)
+				assert.Equal(t, credit, entry.Amount)
+				assert.Equal(t, 1, entry.CurrencyID)
+				return uuid.New(), nil
+			})
+	}
+
+	t.Run("captured payment renews the subscription", func(t *testing.T) {
+		expectCharge(10, 0)
+		expectRecord(10, 0)
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice entities.Invoice) (entities.Invoice, error) { return invoice, nil })
+		mockRepo.EXPECT().RecordRenewalAttempt(gomock.Any(), entities.RenewalAttempt{
+			MemberSubscriptionID: candidate.MemberSubscriptionID,
+			MemberID:             candidate.MemberID,
+			Attempt:              2,
+			Status:               consts.RenewalSucceeded,
+		}).Return(nil)
+
+		require.NoError(t, useCases.ProcessSubscriptionRenewals(context.Background(), 10, time.Hour, dunning))
+		messages := memberNotifier.Messages()
+		require.NotEmpty(t, messages)
+		assert.Equal(t, "Your subscription has been renewed", messages[len(messages)-1].Subject)
+	})
+
+	t.Run("declined payment is retried on the dunning schedule", func(t *testing.T) {
+		// The fake gateway declines amounts ending in one cent
+		expectCharge(10.01, 0)
+		expectRecord(10.01, 0)
+		mockRepo.EXPECT().IsSubscriptionInGracePeriod(gomock.Any(), candidate.MemberID, candidate.MemberSubscriptionID).
+			Return(true, candidate.ExpirationDate, graceEnd, 14*day, true, nil)
+		mockRepo.EXPECT().RecordRenewalAttempt(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, attempt entities.RenewalAttempt) error {
+				assert.Equal(t, consts.RenewalFailed, attempt.Status)
+				assert.Equal(t, consts.PaymentFailed, attempt.FailureReason)
+				assert.Equal(t, 2, attempt.Attempt)
+				require.NotNil(t, attempt.NextAttemptOn)
+				assert.Equal(t, candidate.ExpirationDate.Add(3*day), *attempt.NextAttemptOn)
+				return nil
+			})
+
+		require.NoError(t, useCases.ProcessSubscriptionRenewals(context.Background(), 10, time.Hour, dunning))
+		messages := memberNotifier.Messages()
+		require.NotEmpty(t, messages)
+		assert.Equal(t, "We could not renew your subscription", messages[len(messages)-1].Subject)
+		assert.Contains(t, messages[len(messages)-1].Body, candidate.ExpirationDate.Add(3*day).Format("2006-01-02"))
+	})
+
+	t.Run("downgrade credit is used up by the renewal", func(t *testing.T) {
+		expectCharge(10, -4)
+		expectRecord(6, 4)
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice entities.Invoice) (entities.Invoice, error) {
+				require.Len(t, invoice.Lines, 2)
+				assert.Equal(t, "Account credit", invoice.Lines[1].Description)
+				assert.Equal(t, -4.0, invoice.Lines[1].Amount)
+				assert.Equal(t, 6.0, invoice.Total)
+				return invoice, nil
+			})
+		mockRepo.EXPECT().RecordRenewalAttempt(gomock.Any(), gomock.Any()).Return(nil)
+
+		require.NoError(t, useCases.ProcessSubscriptionRenewals(context.Background(), 10, time.Hour, dunning))
+	})
+
+	t.Run("credit covering the price is not charged on the gateway", func(t *testing.T) {
+		expectCharge(10, -25)
+		mockRepo.EXPECT().RecordRenewalPayment(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment, entry *entities.SubscriptionLedgerEntry) (uuid.UUID, error) {
+				assert.Equal(t, consts.PaymentStatusCaptured, record.Status)
+				assert.Empty(t, record.GatewayPaymentID)
+				assert.Zero(t, record.Amount)
+				require.NotNil(t, entry)
+				assert.Equal(t, 10.0, entry.Amount)
+				return uuid.New(), nil
+			})
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).Return(entities.Invoice{}, nil)
+		mockRepo.EXPECT().RecordRenewalAttempt(gomock.Any(), gomock.Any()).Return(nil)
+
+		require.NoError(t, useCases.ProcessSubscriptionRenewals(context.Background(), 10, time.Hour, dunning))
+	})
+}
+
+// TestPromoCodes checks promo codes are validated when created and take their discount off the plan
+// price at checkout, before tax, when the code applies to the partner and plan.
+func TestPromoCodes(t *testing.T) {
+	useCases, mockRepo := newTestUseCases(t)
+
+	_, fieldsMap, err := useCases.CreatePromoCode(createTestGinContext(), entities.PromoCodeRequest{
+		Code: "summer sale", DiscountType: "fixed", DiscountValue: 5, PlanIDs: []string{"gold"}, MaxRedemptions: -1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.Code])
+	assert.Equal(t, []string{consts.Required}, fieldsMap[consts.CurrencyID])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.PlanIDs])
+	assert.Equal(t, []string{consts.Invalid}, fieldsMap[consts.MaxRedemptions])
+
+	request := entities.PromoCodeRequest{Code: " summer24 ", DiscountType: "Percentage", DiscountValue: 20, CurrencyID: 1}
+	stored := entities.PromoCodeRequest{Code: "SUMMER24", DiscountType: consts.DiscountPercentage, DiscountValue: 20}
+	mockRepo.EXPECT().GetPromoCode(gomock.Any(), "", "SUMMER24").Return(entities.PromoCode{}, sql.ErrNoRows)
+	mockRepo.EXPECT().CreatePromoCode(gomock.Any(), stored).Return(entities.PromoCode{ID: uuid.New(), PromoCodeRequest: stored, IsActive: true}, nil)
+	promoCode, fieldsMap, err := useCases.CreatePromoCode(createTestGinContext(), request)
+	require.NoError(t, err)
+	assert.Empty(t, fieldsMap)
+	assert.Equal(t, "SUMMER24", promoCode.Code)
+
+	mockRepo.EXPECT().GetPromoCode(gomock.Any(), "", "SUMMER24").Return(promoCode, nil)
+	_, fieldsMap, err = useCases.CreatePromoCode(createTestGinContext(), request)
+	require.NoError(t, err)
+	assert.Equal(t, []string{consts.AlreadyExists}, fieldsMap[consts.Code])
+
+	t.Run("partner admins add codes owned by their partner", func(t *testing.T) {
+		partnerID := uuid.New()
+		ctx := createTestGinContext()
+		tenant.Scope(ctx, partnerID)
+		scoped := stored
+		scoped.PartnerIDs = []string{partnerID.String()}
+		mockRepo.EXPECT().GetPromoCode(gomock.Any(), partnerID.String(), "SUMMER24").Return(entities.PromoCode{}, sql.ErrNoRows)
+		mockRepo.EXPECT().CreatePromoCode(gomock.Any(), scoped).Return(entities.PromoCode{ID: uuid.New(), PromoCodeRequest: scoped, IsActive: true}, nil)
+		_, fieldsMap, err := useCases.CreatePromoCode(ctx, entities.PromoCodeRequest{
+			Code: "summer24", DiscountType: "percentage", DiscountValue: 20, PartnerIDs: []string{uuid.NewString()},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+
+	memberID := uuid.New()
+	partnerID := uuid.New().String()
+	planID := uuid.New()
+	checkoutData := entities.CheckoutSubscription{SubscriptionID: uuid.New().String(), PaymentGatewayID: 1, PromoCode: "summer24"}
+	terms := entities.SubscriptionPlanTerms{SubscriptionID: checkoutData.SubscriptionID, PlanID: planID, Name: "Gold", Amount: 10, TaxPercentage: 10, CurrencyID: 1, Currency: "USD"}
+	memberSubscriptionID := uuid.New().String()
+
+	expectCheckout := func(promoCode entities.PromoCode) {
+		mockRepo.EXPECT().CheckSubscriptionExistenceAndStatusForCheckout(gomock.Any(), checkoutData.SubscriptionID).Return(true, true, nil)
+		mockRepo.EXPECT().CheckMemberPartner(gomock.Any(), memberID, partnerID).Return(true, nil)
+		mockRepo.EXPECT().GetEmailVerification(gomock.Any(), memberID).Return(entities.EmailVerification{MemberID: memberID}, nil)
+		mockRepo.EXPECT().GetSubscriptionCountForLastYear(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(0, nil)
+		mockRepo.EXPECT().GetMaxSubscriptionLimitForID(gomock.Any(), checkoutData.SubscriptionID).Return(5, nil)
+		mockRepo.EXPECT().IsFreeSubscription(gomock.Any(), checkoutData.SubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().CheckIfPayoutGatewayExists(gomock.Any(), 1).Return(true, nil)
+		mockRepo.EXPECT().IsPartnerIdCorrespondsToGateway(gomock.Any(), partnerID, 1).Return(true, nil)
+		mockRepo.EXPECT().GetPaymentDetailsByPartnerAndGateway(gomock.Any(), partnerID, 1).Return("encrypted", nil)
+		mockRepo.EXPECT().DecryptPaymentData(gomock.Any(), "encrypted").
+			Return(`{"gateway":"fake","payin":true,"default_payin_currency":"USD"}`, nil)
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "USD").Return(terms, nil)
+		mockRepo.EXPECT().HasSubscribedToOneTimePlan(gomock.Any(), memberID, checkoutData.SubscriptionID).Return(false, nil)
+		mockRepo.EXPECT().GetPromoCode(gomock.Any(), partnerID, "SUMMER24").Return(promoCode, nil)
+	}
+
+	t.Run("code of another plan", func(t *testing.T) {
+		expectCheckout(entities.PromoCode{ID: promoCode.ID, PromoCodeRequest: entities.PromoCodeRequest{
+			Code: "SUMMER24", DiscountType: consts.DiscountPercentage, DiscountValue: 20, PlanIDs: []string{uuid.New().String()},
+		}, IsActive: true})
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "USD").Return(terms, nil)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.NoRelation}, fieldsMap[consts.PromoCode])
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		validUntil := time.Now().Add(-time.Hour)
+		expectCheckout(entities.PromoCode{ID: promoCode.ID, PromoCodeRequest: entities.PromoCodeRequest{
+			Code: "SUMMER24", DiscountType: consts.DiscountPercentage, DiscountValue: 20, ValidUntil: &validUntil,
+		}, IsActive: true})
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.Expired}, fieldsMap[consts.PromoCode])
+	})
+
+	t.Run("redemption limit reached", func(t *testing.T) {
+		expectCheckout(entities.PromoCode{ID: promoCode.ID, PromoCodeRequest: stored, IsActive: true})
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "USD").Return(terms, nil)
+		mockRepo.EXPECT().HandleSubscriptionCheckout(gomock.Any(), memberID, gomock.Any()).Return("", consts.ErrPromoCodeLimitReached)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{consts.LimitReached}, fieldsMap[consts.PromoCode])
+	})
+
+	t.Run("discount is invoiced", func(t *testing.T) {
+		expectCheckout(entities.PromoCode{ID: promoCode.ID, PromoCodeRequest: entities.PromoCodeRequest{
+			Code: "SUMMER24", DiscountType: consts.DiscountPercentage, DiscountValue: 20, PartnerIDs: []string{partnerID}, PlanIDs: []string{planID.String()},
+		}, IsActive: true})
+		mockRepo.EXPECT().GetSubscriptionPlanTerms(gomock.Any(), checkoutData.SubscriptionID, "USD").Return(terms, nil).Times(2)
+		mockRepo.EXPECT().HandleSubscriptionCheckout(gomock.Any(), memberID, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uuid.UUID, checkout entities.CheckoutSubscription) (string, error) {
+				require.NotNil(t, checkout.Discount)
+				assert.Equal(t, entities.PromoDiscount{PromoCodeID: promoCode.ID, Code: "SUMMER24", Amount: 2}, *checkout.Discount)
+				return memberSubscriptionID, nil
+			})
+		mockRepo.EXPECT().GetMemberBillingProfile(gomock.Any(), memberID).Return(entities.BillingProfile{Name: "John Doe", PayingTax: true}, nil)
+		mockRepo.EXPECT().RecordCheckoutPayment(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, record entities.SubscriptionPayment) (uuid.UUID, error) {
+				assert.Equal(t, consts.PaymentStatusCaptured, record.Status)
+				assert.Equal(t, 8.8, record.Amount)
+				return uuid.New(), nil
+			})
+		mockRepo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, invoice entities.Invoice) (entities.Invoice, error) {
+				require.Len(t, invoice.Lines, 2)
+				assert.Equal(t, "Promo code SUMMER24", invoice.Lines[1].Description)
+				assert.Equal(t, -2.0, invoice.Lines[1].Amount)
+				assert.Equal(t, 8.0, invoice.Subtotal)
+				assert.Equal(t, 0.8, invoice.TaxAmount)
+				assert.Equal(t, 8.8, invoice.Total)
+				return invoice, nil
+			})
+		mockRepo.EXPECT().GetMemberContact(gomock.Any(), memberID).Return(entities.MemberContact{Email: "john.doe@example.com"}, nil)
+
+		fieldsMap, err := useCases.HandleSubscriptionCheckout(createTestGinContext(), memberID, checkoutData, partnerID)
+		require.NoError(t, err)
+		assert.Empty(t, fieldsMap)
+	})
+}
@@ -0,0 +1,98 @@
+// Package verification issues and checks the signed, expiring tokens proving that a
+// member owns the email address they registered with.
+package verification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"member/internal/cursor"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalid is returned when a token was not issued by the signer.
+	ErrInvalid = errors.New("invalid verification token")
+	// ErrExpired is returned when a token was issued by the signer but is no longer valid.
+	ErrExpired = errors.New("expired verification token")
+)
+
+// Claims is the content of a verification token. Only the hash of the email is carried,
+// a token stops matching once the member's email changes.
+type Claims struct {
+	MemberID  uuid.UUID `json:"mid"`
+	EmailHash string    `json:"eh"`
+	ExpiresAt int64     `json:"exp"`
+}
+
+// Matches reports whether the token was issued for the email address.
+func (c Claims) Matches(email string) bool {
+	return hmac.Equal([]byte(c.EmailHash), []byte(HashEmail(email)))
+}
+
+// Signer issues and verifies verification tokens with an HMAC-SHA256 key.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewSigner creates a signer for tokens valid for ttl.
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// Issue returns a token for the member's email along with its expiry.
+func (s *Signer) Issue(memberID uuid.UUID, email string, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(s.ttl)
+	payload, err := cursor.Encode(Claims{
+		MemberID:  memberID,
+		EmailHash: HashEmail(email),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return payload + "." + s.sign(payload), expiresAt, nil
+}
+
+// Verify checks the signature and expiry of the token and returns its claims.
+func (s *Signer) Verify(token string, now time.Time) (Claims, error) {
+	var claims Claims
+	payload, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return claims, ErrInvalid
+	}
+	if err := cursor.Decode(payload, &claims); err != nil {
+		return claims, ErrInvalid
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+// TTL returns the validity of the issued tokens.
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// sign returns the encoded signature of the payload.
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HashEmail returns the hex encoded SHA-256 hash of the normalized email address.
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
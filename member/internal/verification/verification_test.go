@@ -0,0 +1,44 @@
+package verification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSigner checks issued tokens verify until they expire and that tampered or
+// foreign tokens are rejected.
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret", time.Hour)
+	memberID := uuid.New()
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	token, expiresAt, err := signer.Issue(memberID, "John@Example.com", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	claims, err := signer.Verify(token, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, memberID, claims.MemberID)
+	assert.True(t, claims.Matches(" john@example.com"))
+	assert.False(t, claims.Matches("jane@example.com"))
+
+	_, err = signer.Verify(token, expiresAt)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	_, err = NewSigner("other", time.Hour).Verify(token, now)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	_, err = signer.Verify(payload+"x."+signature, now)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	for _, malformed := range []string{"", "abc", "abc.def"} {
+		_, err = signer.Verify(malformed, now)
+		assert.ErrorIs(t, err, ErrInvalid, malformed)
+	}
+}
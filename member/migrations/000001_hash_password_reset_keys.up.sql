@@ -0,0 +1,14 @@
+-- Reset keys are stored as a hex encoded SHA-256 hash of the key sent to the member.
+ALTER TABLE member ALTER COLUMN reset_password_key TYPE TEXT;
+UPDATE member SET reset_password_key = NULL, password_expiry = NULL;
+
+CREATE TABLE IF NOT EXISTS password_reset_attempt (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES member(id),
+    ip_address TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL DEFAULT FALSE,
+    attempted_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_attempt_member ON password_reset_attempt (member_id, attempted_on);
+CREATE INDEX IF NOT EXISTS idx_password_reset_attempt_ip ON password_reset_attempt (ip_address, attempted_on);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_member_subscription_expiration;
+DROP TABLE IF EXISTS member_subscription_event;
+UPDATE member_subscription
+SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = 'active')
+WHERE member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = 'warning');
+DELETE FROM member_subscription_status WHERE name = 'warning';
@@ -0,0 +1,16 @@
+INSERT INTO member_subscription_status (name)
+SELECT 'warning'
+WHERE NOT EXISTS (SELECT 1 FROM member_subscription_status WHERE name = 'warning');
+
+-- Status transitions applied by the subscription lifecycle job.
+CREATE TABLE IF NOT EXISTS member_subscription_event (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_subscription_event_subscription ON member_subscription_event (member_subscription_id, created_on);
+CREATE INDEX IF NOT EXISTS idx_member_subscription_expiration ON member_subscription (expiration_date);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS member_subscription_payment;
+UPDATE member_subscription
+SET member_subscription_status_id = (SELECT id FROM member_subscription_status WHERE name = 'active')
+WHERE member_subscription_status_id IN (SELECT id FROM member_subscription_status WHERE name IN ('processing', 'payment_failed'));
+DELETE FROM member_subscription_status WHERE name IN ('processing', 'payment_failed');
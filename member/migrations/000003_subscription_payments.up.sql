@@ -0,0 +1,21 @@
+INSERT INTO member_subscription_status (name)
+SELECT status FROM (VALUES ('processing'), ('payment_failed')) AS s(status)
+WHERE NOT EXISTS (SELECT 1 FROM member_subscription_status WHERE name = s.status);
+
+-- Payment operations made on the payment gateways for member subscriptions.
+CREATE TABLE IF NOT EXISTS member_subscription_payment (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    payment_gateway_id INTEGER NOT NULL,
+    gateway_payment_id TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_subscription_payment_subscription ON member_subscription_payment (member_subscription_id, created_on);
+CREATE INDEX IF NOT EXISTS idx_member_subscription_payment_gateway_payment ON member_subscription_payment (gateway_payment_id);
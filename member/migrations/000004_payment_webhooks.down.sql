@@ -0,0 +1,2 @@
+ALTER TABLE member_subscription_event DROP COLUMN IF EXISTS source;
+DROP TABLE IF EXISTS payment_webhook_event;
@@ -0,0 +1,22 @@
+-- Payment gateway webhook events, deduplicated on the gateway and the gateway's event ID.
+-- Each row keeps the verified payload and the state change it applied.
+CREATE TABLE IF NOT EXISTS payment_webhook_event (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gateway TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    partner_id UUID NOT NULL,
+    member_subscription_payment_id UUID NOT NULL REFERENCES member_subscription_payment(id),
+    payload JSONB NOT NULL,
+    payment_status TEXT NOT NULL DEFAULT '',
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL DEFAULT '',
+    received_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    applied_on TIMESTAMP,
+    UNIQUE (gateway, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_webhook_event_payment ON payment_webhook_event (member_subscription_payment_id, received_on);
+
+-- Where a subscription status change came from: the lifecycle job or a payment webhook.
+ALTER TABLE member_subscription_event ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'lifecycle';
@@ -0,0 +1,17 @@
+-- Money adjustments made on member subscriptions, such as the prorated credit of a plan change.
+-- Positive amounts are owed by the member, negative amounts are credited to the member.
+CREATE TABLE IF NOT EXISTS member_subscription_ledger (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    kind TEXT NOT NULL,
+    from_subscription_id UUID REFERENCES subscription_plan(id),
+    to_subscription_id UUID REFERENCES subscription_plan(id),
+    credit NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    charge NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    amount NUMERIC(12, 2) NOT NULL,
+    currency_id INTEGER NOT NULL,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_subscription_ledger_subscription ON member_subscription_ledger (member_subscription_id, created_on);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS member_invoice_line;
+DROP TABLE IF EXISTS member_invoice;
+DROP SEQUENCE IF EXISTS member_invoice_number_seq;
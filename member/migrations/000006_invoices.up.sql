@@ -0,0 +1,38 @@
+CREATE SEQUENCE IF NOT EXISTS member_invoice_number_seq;
+
+-- Invoices issued for subscription checkouts and renewals. The billing name, email and
+-- address are copied from the member when the invoice is issued.
+CREATE TABLE IF NOT EXISTS member_invoice (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_number TEXT NOT NULL UNIQUE DEFAULT 'INV-' || LPAD(nextval('member_invoice_number_seq')::TEXT, 8, '0'),
+    member_id UUID NOT NULL REFERENCES member(id),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_subscription_payment_id UUID REFERENCES member_subscription_payment(id),
+    kind TEXT NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    subtotal NUMERIC(12, 2) NOT NULL,
+    tax_percentage NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    total NUMERIC(12, 2) NOT NULL,
+    billing_name TEXT NOT NULL DEFAULT '',
+    billing_email TEXT NOT NULL DEFAULT '',
+    billing_address TEXT NOT NULL DEFAULT '',
+    billing_zip TEXT NOT NULL DEFAULT '',
+    billing_country TEXT NOT NULL DEFAULT '',
+    billing_state TEXT NOT NULL DEFAULT '',
+    issued_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_invoice_member ON member_invoice (member_id, issued_on DESC);
+
+CREATE TABLE IF NOT EXISTS member_invoice_line (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id UUID NOT NULL REFERENCES member_invoice(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    unit_amount NUMERIC(12, 2) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_invoice_line_invoice ON member_invoice_line (invoice_id, position);
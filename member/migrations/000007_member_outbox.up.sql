@@ -0,0 +1,18 @@
+-- Domain events written in the transaction of the change they describe. The outbox relay
+-- publishes pending events to the queue and retries failed ones from next_attempt_on.
+CREATE TABLE IF NOT EXISTS member_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type TEXT NOT NULL,
+    schema_version INTEGER NOT NULL,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_on TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_outbox_pending ON member_outbox (next_attempt_on, occurred_on)
+    WHERE published_on IS NULL;
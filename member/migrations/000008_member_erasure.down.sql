@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS member_erasure_request;
+
+ALTER TABLE refresh_token DROP COLUMN IF EXISTS created_on;
+
+ALTER TABLE member DROP COLUMN IF EXISTS erased_on;
@@ -0,0 +1,16 @@
+ALTER TABLE member ADD COLUMN IF NOT EXISTS erased_on TIMESTAMP;
+
+ALTER TABLE refresh_token ADD COLUMN IF NOT EXISTS created_on TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Requests to erase the personal data of a member, processed by the erasure job. A member
+-- has at most one pending request.
+CREATE TABLE IF NOT EXISTS member_erasure_request (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES member(id),
+    requested_by TEXT NOT NULL DEFAULT '',
+    requested_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_on TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_member_erasure_request_pending ON member_erasure_request (member_id)
+    WHERE completed_on IS NULL;
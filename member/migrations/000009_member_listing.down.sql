@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_member_listing_email;
+DROP INDEX IF EXISTS idx_member_listing_created_on;
@@ -0,0 +1,3 @@
+-- Keyset pagination of the member listing, per partner and sort key.
+CREATE INDEX IF NOT EXISTS idx_member_listing_created_on ON member (partner_id, created_on, id) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_member_listing_email ON member (partner_id, email, id) WHERE is_deleted = false;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS member_import_row;
+DROP TABLE IF EXISTS member_import;
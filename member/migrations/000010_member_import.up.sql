@@ -0,0 +1,29 @@
+-- Bulk member imports, processed row by row by the import job.
+CREATE TABLE IF NOT EXISTS member_import (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    partner_id UUID NOT NULL REFERENCES partner(id),
+    format TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    total_rows INTEGER NOT NULL,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    requested_by TEXT NOT NULL DEFAULT '',
+    created_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_on TIMESTAMP,
+    completed_on TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_import_open ON member_import (created_on)
+    WHERE completed_on IS NULL;
+
+-- The payload of a row may hold a password and is cleared once the row is processed.
+CREATE TABLE IF NOT EXISTS member_import_row (
+    import_id UUID NOT NULL REFERENCES member_import(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    payload JSONB,
+    status TEXT NOT NULL DEFAULT 'pending',
+    errors JSONB,
+    processed_on TIMESTAMP,
+    PRIMARY KEY (import_id, row_number)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS partner_email_verification_policy;
+
+ALTER TABLE member DROP COLUMN IF EXISTS email_verified_at;
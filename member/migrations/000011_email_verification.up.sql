@@ -0,0 +1,13 @@
+ALTER TABLE member ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- Members registered before email verification was introduced are considered verified.
+UPDATE member SET email_verified_at = COALESCE(created_on, NOW()) WHERE email_verified_at IS NULL;
+
+-- Actions a partner blocks until the member's email address is verified. Partners without
+-- a policy block nothing.
+CREATE TABLE IF NOT EXISTS partner_email_verification_policy (
+    partner_id UUID PRIMARY KEY REFERENCES partner(id),
+    block_login BOOLEAN NOT NULL DEFAULT false,
+    block_checkout BOOLEAN NOT NULL DEFAULT false,
+    updated_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
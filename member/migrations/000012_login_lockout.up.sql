@@ -0,0 +1,11 @@
+-- Throttling state of the failed password logins, per member (subject is the member id) and per
+-- client IP (subject is the address).
+CREATE TABLE IF NOT EXISTS login_lockout (
+    scope TEXT NOT NULL CHECK (scope IN ('member', 'ip')),
+    subject TEXT NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_on TIMESTAMP,
+    next_attempt_on TIMESTAMP,
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, subject)
+);
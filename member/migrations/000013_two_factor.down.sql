@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS member_recovery_code;
+DROP TABLE IF EXISTS member_two_factor;
@@ -0,0 +1,18 @@
+-- TOTP secrets of the members' authenticator apps, encrypted with the decryption key. The
+-- secret is pending until the first code is verified. last_used_step holds the time step of
+-- the last accepted code, so a code cannot be replayed.
+CREATE TABLE IF NOT EXISTS member_two_factor (
+    member_id UUID PRIMARY KEY REFERENCES member(id),
+    secret TEXT NOT NULL,
+    enabled_on TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Single-use recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS member_recovery_code (
+    member_id UUID NOT NULL REFERENCES member(id),
+    code_hash TEXT NOT NULL,
+    used_on TIMESTAMP,
+    PRIMARY KEY (member_id, code_hash)
+);
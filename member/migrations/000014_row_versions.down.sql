@@ -0,0 +1,2 @@
+ALTER TABLE member_billing_address DROP COLUMN IF EXISTS version;
+ALTER TABLE member DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Row versions of the optimistic concurrency control. Updates increment the version, which is
+-- returned as the ETag and compared with the If-Match header of later updates and deletions.
+ALTER TABLE member ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE member_billing_address ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,15 @@
+-- Audit trail of the changes made to members and their resources, like billing addresses.
+-- Changes hold the old and new value of every changed field, secrets are recorded without
+-- their values.
+CREATE TABLE IF NOT EXISTS member_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES member (id),
+    resource_id TEXT,
+    actor_id TEXT,
+    action TEXT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    request_id TEXT,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_audit_member ON member_audit (member_id, created_on DESC);
@@ -0,0 +1 @@
+ALTER TABLE member_store DROP COLUMN IF EXISTS position;
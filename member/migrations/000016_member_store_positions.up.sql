@@ -0,0 +1,12 @@
+-- Members order their stores. Existing stores are numbered in the order of their names.
+ALTER TABLE member_store ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+UPDATE member_store ms
+SET position = numbered.position
+FROM (
+    SELECT member_id, store_id,
+        ROW_NUMBER() OVER (PARTITION BY member_id ORDER BY custom_store_name, store_id) AS position
+    FROM member_store
+) numbered
+WHERE ms.member_id = numbered.member_id
+AND ms.store_id = numbered.store_id;
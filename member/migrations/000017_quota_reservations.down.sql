@@ -0,0 +1 @@
+DROP TABLE IF EXISTS member_quota_reservation;
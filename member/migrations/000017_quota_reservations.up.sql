@@ -0,0 +1,17 @@
+-- Capacity of member subscriptions held by other services while they create products, tracks
+-- and artists. Reserved capacity counts against the plan limits until the reservation is
+-- committed, released or expires.
+CREATE TABLE IF NOT EXISTS member_quota_reservation (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    resource TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    status TEXT NOT NULL DEFAULT 'reserved',
+    expires_on TIMESTAMP NOT NULL,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_quota_reservation_pending ON member_quota_reservation (member_subscription_id, resource)
+    WHERE status = 'reserved';
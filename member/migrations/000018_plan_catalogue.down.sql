@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS subscription_plan_price;
+DROP INDEX IF EXISTS idx_subscription_plan_version;
+ALTER TABLE subscription_plan DROP COLUMN IF EXISTS created_on;
+ALTER TABLE subscription_plan DROP COLUMN IF EXISTS retired_on;
+ALTER TABLE subscription_plan DROP COLUMN IF EXISTS version;
+ALTER TABLE subscription_plan DROP COLUMN IF EXISTS plan_key;
@@ -0,0 +1,28 @@
+-- Subscription plans are versioned. Versions of a plan share the plan_key, every existing plan
+-- becomes the first version of its own plan. Member subscriptions keep referencing the version
+-- they bought.
+ALTER TABLE subscription_plan ADD COLUMN IF NOT EXISTS plan_key UUID;
+ALTER TABLE subscription_plan ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE subscription_plan ADD COLUMN IF NOT EXISTS retired_on TIMESTAMP;
+ALTER TABLE subscription_plan ADD COLUMN IF NOT EXISTS created_on TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE subscription_plan SET plan_key = id WHERE plan_key IS NULL;
+ALTER TABLE subscription_plan ALTER COLUMN plan_key SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscription_plan_version ON subscription_plan (plan_key, version);
+
+-- Prices of plan versions per currency. The amount, tax_percentage and currency_id of the plan
+-- stay the default price.
+CREATE TABLE IF NOT EXISTS subscription_plan_price (
+    subscription_plan_id UUID NOT NULL REFERENCES subscription_plan(id),
+    currency_id INTEGER NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL,
+    tax_percentage NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (subscription_plan_id, currency_id)
+);
+
+INSERT INTO subscription_plan_price (subscription_plan_id, currency_id, amount, tax_percentage)
+SELECT id, currency_id, COALESCE(amount, 0), COALESCE(tax_percentage, 0)
+FROM subscription_plan
+WHERE currency_id IS NOT NULL
+ON CONFLICT DO NOTHING;
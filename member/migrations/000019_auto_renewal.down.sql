@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS member_subscription_renewal_attempt;
+DROP INDEX IF EXISTS idx_member_subscription_renewal_due;
+ALTER TABLE member_subscription DROP COLUMN IF EXISTS renewal_failures;
+ALTER TABLE member_subscription DROP COLUMN IF EXISTS renewal_due_on;
+ALTER TABLE member_subscription DROP COLUMN IF EXISTS auto_renew_gateway_id;
+ALTER TABLE member_subscription DROP COLUMN IF EXISTS auto_renew;
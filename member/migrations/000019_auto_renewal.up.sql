@@ -0,0 +1,25 @@
+-- Members opt into the automatic renewal of their recurring subscriptions. renewal_due_on is when the
+-- renewal job charges the subscription next, NULL when no attempt is scheduled, and renewal_failures
+-- counts the failed attempts since the subscription was last renewed.
+ALTER TABLE member_subscription ADD COLUMN IF NOT EXISTS auto_renew BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE member_subscription ADD COLUMN IF NOT EXISTS auto_renew_gateway_id INTEGER;
+ALTER TABLE member_subscription ADD COLUMN IF NOT EXISTS renewal_due_on TIMESTAMP;
+ALTER TABLE member_subscription ADD COLUMN IF NOT EXISTS renewal_failures INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_member_subscription_renewal_due ON member_subscription (renewal_due_on)
+    WHERE auto_renew = true;
+
+-- Automatic renewal attempts and their outcome.
+CREATE TABLE IF NOT EXISTS member_subscription_renewal_attempt (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    attempt INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    next_attempt_on TIMESTAMP,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_member_subscription_renewal_attempt_subscription
+    ON member_subscription_renewal_attempt (member_subscription_id, created_on);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS promo_code_redemption;
+DROP INDEX IF EXISTS idx_promo_code_code;
+DROP TABLE IF EXISTS promo_code;
@@ -0,0 +1,32 @@
+-- Promo codes discount the plan price at subscription checkout. Codes are stored upper case and
+-- looked up case-insensitively. Empty partner_ids and plan_ids apply a code to every partner and
+-- plan, plan_ids are catalogue plan keys, and zero limits do not limit redemptions.
+CREATE TABLE IF NOT EXISTS promo_code (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code TEXT NOT NULL,
+    discount_type TEXT NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value NUMERIC(12, 2) NOT NULL CHECK (discount_value > 0),
+    currency_id INTEGER,
+    partner_ids UUID[] NOT NULL DEFAULT '{}',
+    plan_ids UUID[] NOT NULL DEFAULT '{}',
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    max_redemptions INTEGER NOT NULL DEFAULT 0,
+    max_redemptions_per_member INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_code_code ON promo_code (UPPER(code));
+
+-- Redemptions of promo codes by member subscription checkouts and the discount they were given.
+CREATE TABLE IF NOT EXISTS promo_code_redemption (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    promo_code_id UUID NOT NULL REFERENCES promo_code(id),
+    member_id UUID NOT NULL REFERENCES member(id),
+    member_subscription_id UUID NOT NULL REFERENCES member_subscription(id),
+    discount_amount NUMERIC(12, 2) NOT NULL,
+    redeemed_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promo_code_redemption_code ON promo_code_redemption (promo_code_id, member_id);
@@ -0,0 +1 @@
+ALTER TABLE member_invoice DROP COLUMN IF EXISTS voided_on;
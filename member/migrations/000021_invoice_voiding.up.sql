@@ -0,0 +1,3 @@
+-- The invoice of a pending payment is voided when the gateway reports the payment failed, voided
+-- invoices keep their number so the numbering has no gaps.
+ALTER TABLE member_invoice ADD COLUMN IF NOT EXISTS voided_on TIMESTAMP;
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS password_reset_lockout;
+
+CREATE TABLE IF NOT EXISTS password_reset_attempt (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES member(id),
+    ip_address TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL DEFAULT FALSE,
+    attempted_on TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_attempt_member ON password_reset_attempt (member_id, attempted_on);
+CREATE INDEX IF NOT EXISTS idx_password_reset_attempt_ip ON password_reset_attempt (ip_address, attempted_on);
@@ -0,0 +1,12 @@
+-- Reset key checks in the lockout window, per member (subject is the member id) and per client IP
+-- (subject is the address). A check is counted before the key is compared, so the row replaces the
+-- attempt log that was counted after the fact.
+CREATE TABLE IF NOT EXISTS password_reset_lockout (
+    scope TEXT NOT NULL CHECK (scope IN ('member', 'ip')),
+    subject TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    window_started_on TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject)
+);
+
+DROP TABLE IF EXISTS password_reset_attempt;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_currency_code_code;
+DROP TABLE IF EXISTS currency_code;
@@ -0,0 +1,8 @@
+-- ISO codes of the currencies plan prices are given in. Payment gateways charge in ISO codes, the
+-- price of a plan for a gateway is the subscription_plan_price row of the currency with its code.
+CREATE TABLE IF NOT EXISTS currency_code (
+    currency_id INTEGER PRIMARY KEY,
+    code TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_currency_code_code ON currency_code (UPPER(code));
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_promo_code_platform_code;
+DROP INDEX IF EXISTS idx_promo_code_partner_code;
+ALTER TABLE promo_code DROP COLUMN IF EXISTS owner_partner_id;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_code_code ON promo_code (UPPER(code));
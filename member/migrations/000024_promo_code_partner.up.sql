@@ -0,0 +1,12 @@
+-- Promo codes are unique per partner. Codes added by partner admins are owned by their partner,
+-- codes without an owner are platform codes. Codes of a single partner so far belong to it.
+ALTER TABLE promo_code ADD COLUMN IF NOT EXISTS owner_partner_id UUID REFERENCES partner(id);
+
+UPDATE promo_code SET owner_partner_id = partner_ids[1] WHERE cardinality(partner_ids) = 1;
+
+DROP INDEX IF EXISTS idx_promo_code_code;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_code_partner_code ON promo_code (owner_partner_id, UPPER(code))
+    WHERE owner_partner_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promo_code_platform_code ON promo_code (UPPER(code))
+    WHERE owner_partner_id IS NULL;
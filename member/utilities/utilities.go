@@ -1,12 +1,19 @@
 package utilities
 
 import (
+	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"member/internal/consts"
 	"member/internal/entities"
+	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/badoux/checkmail"
 	"github.com/dgrijalva/jwt-go"
@@ -50,48 +57,124 @@ func IsValidUUID(id string) bool {
 	return true
 }
 
-// ValidatePassword checks the validity of a password.
-func ValidatePassword(password string) error {
-	// Check minimum length
-	if len(password) < 8 {
-		return errors.New("Password must be at least 8 characters long")
+// PasswordPolicy holds the rules ValidatePassword applies to new passwords.
+type PasswordPolicy struct {
+	MinLength        int // Minimum number of characters
+	MaxLength        int // Maximum number of bytes, 0 for no limit
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSpecial   bool
+	// Breached holds the lowercased passwords of the breached password list.
+	Breached map[string]struct{}
+}
+
+// ErrPasswordBreached is returned by ValidatePassword for passwords found in the breached password list.
+var ErrPasswordBreached = errors.New("Password appears in a list of breached passwords")
+
+// ErrPasswordTooShort is returned by ValidatePassword for passwords shorter than the minimum length.
+var ErrPasswordTooShort = errors.New("Password is too short")
+
+var (
+	passwordPolicyMu sync.RWMutex
+	passwordPolicy   = PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireSpecial:   true,
 	}
+	specialCharPattern = regexp.MustCompile(`[!@#$%^&*()_+{}\[\]:;<>,.?~]`)
+	spacePattern       = regexp.MustCompile(`\s`)
+)
 
-	// Check for at least one uppercase letter
-	hasUppercase := false
-	for _, char := range password {
-		if char >= 'A' && char <= 'Z' {
-			hasUppercase = true
-			break
-		}
+// NewPasswordPolicy builds the password policy from the configuration, reading the breached
+// password list when a file is configured.
+func NewPasswordPolicy(cfg entities.PasswordPolicyConfig) (PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:        cfg.MinLength,
+		MaxLength:        cfg.MaxLength,
+		RequireUppercase: cfg.RequireUppercase,
+		RequireLowercase: cfg.RequireLowercase,
+		RequireDigit:     cfg.RequireDigit,
+		RequireSpecial:   cfg.RequireSpecial,
 	}
-	if !hasUppercase {
-		return errors.New("Password must contain at least one uppercase letter")
+	if cfg.BreachedPasswordsFile == "" {
+		return policy, nil
 	}
 
-	// Check for at least one lowercase letter
-	hasLowercase := false
-	for _, char := range password {
-		if char >= 'a' && char <= 'z' {
-			hasLowercase = true
-			break
+	file, err := os.Open(cfg.BreachedPasswordsFile)
+	if err != nil {
+		return policy, fmt.Errorf("opening breached password list: %w", err)
+	}
+	defer file.Close()
+
+	policy.Breached, err = LoadBreachedPasswords(file)
+	return policy, err
+}
+
+// LoadBreachedPasswords reads a breached password list, one password per line. Blank lines
+// and lines starting with # are skipped.
+func LoadBreachedPasswords(r io.Reader) (map[string]struct{}, error) {
+	breached := map[string]struct{}{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
+		breached[strings.ToLower(line)] = struct{}{}
 	}
-	if !hasLowercase {
-		return errors.New("Password must contain at least one lowercase letter")
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading breached password list: %w", err)
+	}
+	return breached, nil
+}
+
+// SetPasswordPolicy replaces the policy applied by ValidatePassword.
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicyMu.Lock()
+	defer passwordPolicyMu.Unlock()
+	passwordPolicy = policy
+}
+
+// ValidatePassword checks the validity of a password against the password policy.
+func ValidatePassword(password string) error {
+	passwordPolicyMu.RLock()
+	policy := passwordPolicy
+	passwordPolicyMu.RUnlock()
+
+	// Check length
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("%w, it must be at least %d characters long", ErrPasswordTooShort, policy.MinLength)
+	}
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		return fmt.Errorf("Password must be at most %d bytes long", policy.MaxLength)
 	}
 
-	// Check for at least one special character
-	specialCharPattern := regexp.MustCompile(`[!@#$%^&*()_+{}\[\]:;<>,.?~]`)
-	if !specialCharPattern.MatchString(password) {
+	// Check the required character classes
+	if policy.RequireUppercase && !strings.ContainsFunc(password, unicode.IsUpper) {
+		return errors.New("Password must contain at least one uppercase letter")
+	}
+	if policy.RequireLowercase && !strings.ContainsFunc(password, unicode.IsLower) {
+		return errors.New("Password must contain at least one lowercase letter")
+	}
+	if policy.RequireDigit && !strings.ContainsFunc(password, unicode.IsDigit) {
+		return errors.New("Password must contain at least one digit")
+	}
+	if policy.RequireSpecial && !specialCharPattern.MatchString(password) {
 		return errors.New("Password must contain at least one special character")
 	}
 
 	// Check for spaces
-	if regexp.MustCompile(`\s`).MatchString(password) {
+	if spacePattern.MatchString(password) {
 		return errors.New("Password cannot contain spaces")
 	}
 
+	// Check the breached password list
+	if _, breached := policy.Breached[strings.ToLower(password)]; breached {
+		return ErrPasswordBreached
+	}
+
 	return nil
 }
 
@@ -170,6 +253,10 @@ func ValidateJwtToken(token string, jwtKey string) (response entities.JwtValidat
 		response.ErrorMsg = "No authorization token passed"
 	} else {
 		tokenParsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+			// Only accept tokens signed with the shared HMAC key.
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+			}
 			return jwtKeyVal, nil
 		})
 		if claims, ok := tokenParsed.Claims.(*entities.Claims); ok && tokenParsed.Valid {
@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"member/internal/entities"
 	"member/utilities"
 	"strings"
 	"testing"
@@ -157,3 +158,56 @@ func TestValidateMaximumNameLength(t *testing.T) {
 		}
 	}
 }
+
+// TestValidatePasswordPolicy is a unit test for the ValidatePassword function in the 'utilities' package.
+// It tests the configurable password policy, including the breached password list.
+func TestValidatePasswordPolicy(t *testing.T) {
+	breached, err := utilities.LoadBreachedPasswords(strings.NewReader("# top passwords\nPassword1!\n\nqwerty\n"))
+	assert.NoError(t, err)
+
+	policy, err := utilities.NewPasswordPolicy(entities.PasswordPolicyConfig{
+		MinLength:        10,
+		MaxLength:        20,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+	})
+	assert.NoError(t, err)
+	policy.Breached = breached
+	utilities.SetPasswordPolicy(policy)
+	defer utilities.SetPasswordPolicy(utilities.PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireSpecial:   true,
+	})
+
+	// Test cases with input passwords and expected validation results
+	testCases := []struct {
+		password        string
+		expectedIsValid bool
+	}{
+		{"Correct1Horse", true},              // Special characters are not required by this policy
+		{"Short1A", false},                   // Below the minimum length
+		{"ThisPasswordIsWayTooLong1", false}, // Above the maximum length
+		{"NoDigitsAtAll", false},             // Missing a digit
+		{"Correct 1Horse", false},            // Spaces are never allowed
+		{"password1!", false},                // Missing an uppercase letter
+		{"PASSWORD1!", false},                // Missing a lowercase letter
+	}
+
+	// Iterate through test cases and run the test for each case
+	for _, tc := range testCases {
+		err := utilities.ValidatePassword(tc.password)
+		if (err == nil) != tc.expectedIsValid {
+			t.Errorf("For password '%s', expected validation result '%v', but got '%v'", tc.password, tc.expectedIsValid, err)
+		}
+	}
+
+	// Short passwords are told apart from other policy violations
+	assert.ErrorIs(t, utilities.ValidatePassword("Short1A"), utilities.ErrPasswordTooShort)
+
+	// Breached passwords are matched case-insensitively
+	assert.NoError(t, utilities.ValidatePassword("PassWord1!x"))
+	assert.ErrorIs(t, utilities.ValidatePassword("pASSWORD1!"), utilities.ErrPasswordBreached)
+}
@@ -26,4 +26,6 @@ const (
 	RefExpTime       = 1440
 	SpotifyProvider  = "spotify"
 	EncryptTest      = "tuneverse-esrevenuttuneverse-tue"
+	// ForwardedFor carries the end user's IP to the member service, which throttles logins on it
+	ForwardedFor = "X-Forwarded-For"
 )
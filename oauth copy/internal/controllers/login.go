@@ -40,12 +40,29 @@ func (oauth *OauthController) OauthLogIn(ctx *gin.Context) {
 		return
 	}
 
+	// a login challenged for the second factor is completed with the challenge token and a code
+	completingChallenge := loginRequest.ChallengeToken != ""
+	if (completingChallenge && loginRequest.Code == "") ||
+		(!completingChallenge && (loginRequest.Email == "" || loginRequest.Password == "")) {
+		log.Errorf("OauthLogIn contoller-request-payload missing required fields")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "please check payload"})
+		return
+	}
+
 	//member get api call request body
 	body := map[string]interface{}{
 		"email":    loginRequest.Email,
 		"provider": provider,
 		"password": loginRequest.Password,
 	}
+	memberMethod, memberURL := http.MethodGet, cfg.MemberServiceURL+"/members/oauth"
+	if completingChallenge {
+		body = map[string]interface{}{
+			"challenge_token": loginRequest.ChallengeToken,
+			"code":            loginRequest.Code,
+		}
+		memberMethod, memberURL = http.MethodPost, cfg.MemberServiceURL+"/members/oauth/two-factor"
+	}
 
 	tokenPayload.PartnerID = partnerID
 	tokenPayload.Email = loginRequest.Email
@@ -57,18 +74,24 @@ func (oauth *OauthController) OauthLogIn(ctx *gin.Context) {
 		log.Errorf("OauthLogIn contoller-dummy token entry in refreshtoken table failed: %v", err)
 	}
 
-	//header to call member api
+	//header to call member api, with the end user's IP the member service throttles logins on
 	header := map[string]interface{}{
-		"Authorization": getMemberToken,
+		"Authorization":     getMemberToken,
+		consts.ForwardedFor: ctx.ClientIP(),
 	}
 
 	//member api call to fetch member informations
-	response, err := utils.APIRequest(http.MethodGet, cfg.MemberServiceURL+"/members/oauth", header, body)
+	response, err := utils.APIRequest(memberMethod, memberURL, header, body)
 	if err != nil {
 		log.Errorf("OauthLogIn contoller-login get member api request failed %v", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"response": "token failure due to member service down"})
 		return
 	}
+	if completingChallenge && response.StatusCode == http.StatusBadRequest {
+		log.Errorf("OauthLogIn contoller-two-factor challenge refused by member service")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"response": "invalid or expired two-factor code"})
+		return
+	}
 	if response.StatusCode == http.StatusBadRequest {
 		log.Errorf("OauthLogIn contoller-member info fetch api error: %v", err)
 		ctx.JSON(http.StatusUnauthorized, gin.H{"response": "user not found,please register"})
@@ -90,6 +113,22 @@ func (oauth *OauthController) OauthLogIn(ctx *gin.Context) {
 		log.Errorf("OauthLogIn contoller-failed to unmarshal member api response error :%v", err)
 	}
 
+	// members with two-factor authentication get no tokens until the challenge is completed
+	if apiResponseLogin.Data.TwoFactorRequired {
+		ctx.JSON(http.StatusOK, gin.H{
+			"data": entities.Response{
+				Message: "two-factor authentication required",
+				Data: []map[string]interface{}{
+					{
+						"two_factor_required":  true,
+						"challenge_token":      apiResponseLogin.Data.ChallengeToken,
+						"challenge_expires_at": apiResponseLogin.Data.ChallengeExpiresAt,
+					},
+				},
+			}})
+		return
+	}
+
 	jwtPayload := entities.OAuthData{}
 	jwtPayload.MemberEmail = apiResponseLogin.Data.Email
 	memberID := apiResponseLogin.Data.MemberID.String()
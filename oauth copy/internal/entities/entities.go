@@ -61,7 +61,11 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// LoginRequest carries the email and password of a login, or the challenge token and the
+// two-factor code completing a login challenged by the member service.
 type LoginRequest struct {
-	Email    string `form:"email" binding:"required"`
-	Password string `form:"password" binding:"required"`
+	Email          string `form:"email"`
+	Password       string `form:"password"`
+	ChallengeToken string `form:"challenge_token"`
+	Code           string `form:"code"`
 }
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -20,6 +21,10 @@ type BasicMemberData struct {
 	MemberType  string    `json:"member_type"`
 	MemberRoles []string  `json:"member_roles"`
 	ProviderID  uuid.UUID `json:"provider_id"`
+	// Set instead of the member details when the member has to complete a two-factor challenge
+	TwoFactorRequired  bool       `json:"two_factor_required"`
+	ChallengeToken     string     `json:"challenge_token"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at"`
 }
 
 func (m *BasicMemberData) UnmarshalJSON(data []byte) error {